@@ -1,23 +1,30 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
 	"github.com/awsl-project/maxx/internal/adapter/client"
+	"github.com/awsl-project/maxx/internal/adapter/provider/antigravity"
 	_ "github.com/awsl-project/maxx/internal/adapter/provider/custom" // Register custom adapter
 	_ "github.com/awsl-project/maxx/internal/adapter/provider/kiro"   // Register kiro adapter
+	"github.com/awsl-project/maxx/internal/adapter/provider/stream"
+	"github.com/awsl-project/maxx/internal/capability"
 	"github.com/awsl-project/maxx/internal/cooldown"
 	"github.com/awsl-project/maxx/internal/core"
 	"github.com/awsl-project/maxx/internal/executor"
 	"github.com/awsl-project/maxx/internal/handler"
-	"github.com/awsl-project/maxx/internal/repository/cached"
-	"github.com/awsl-project/maxx/internal/repository/sqlite"
+	"github.com/awsl-project/maxx/internal/i18n"
+	"github.com/awsl-project/maxx/internal/quota"
+	"github.com/awsl-project/maxx/internal/repository/memory"
 	"github.com/awsl-project/maxx/internal/stats"
 	"github.com/awsl-project/maxx/internal/router"
 	"github.com/awsl-project/maxx/internal/service"
@@ -46,6 +53,8 @@ func main() {
 	addr := flag.String("addr", ":9880", "Server address")
 	dataDir := flag.String("data", "", "Data directory for database and logs (default: ~/.config/maxx)")
 	showVersion := flag.Bool("version", false, "Show version information and exit")
+	console := flag.Bool("console", true, "Serve the web console on the server address (false for API-only deployments)")
+	webDir := flag.String("web-dir", "", "Directory of the built web console (default: web/dist, or MAXX_WEB_DIR)")
 	flag.Parse()
 
 	// Show version and exit if requested
@@ -74,60 +83,57 @@ func main() {
 	logPath := filepath.Join(dataDirPath, "maxx.log")
 
 	// Initialize database (DSN > default SQLite path)
-	var db *sqlite.DB
-	var err error
-	if dsn := os.Getenv("MAXX_DSN"); dsn != "" {
+	// MAXX_DSN=memory:// runs without persistence (benchmarks, CI)
+	dsn := os.Getenv("MAXX_DSN")
+	if dsn != "" {
 		log.Printf("Using database DSN from MAXX_DSN environment variable")
-		db, err = sqlite.NewDBWithDSN(dsn)
-	} else {
-		db, err = sqlite.NewDB(dbPath)
 	}
+	// MAXX_BLOB_DIR overrides where large request/response bodies are stored ("off" keeps them in the database)
+	repos, err := core.InitializeDatabase(&core.DatabaseConfig{
+		DataDir: dataDirPath,
+		DBPath:  dbPath,
+		DSN:     dsn,
+		LogPath: logPath,
+		BlobDir: os.Getenv("MAXX_BLOB_DIR"),
+	})
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
-	// Create repositories
-	providerRepo := sqlite.NewProviderRepository(db)
-	routeRepo := sqlite.NewRouteRepository(db)
-	projectRepo := sqlite.NewProjectRepository(db)
-	sessionRepo := sqlite.NewSessionRepository(db)
-	retryConfigRepo := sqlite.NewRetryConfigRepository(db)
-	routingStrategyRepo := sqlite.NewRoutingStrategyRepository(db)
-	proxyRequestRepo := sqlite.NewProxyRequestRepository(db)
-	attemptRepo := sqlite.NewProxyUpstreamAttemptRepository(db)
-	settingRepo := sqlite.NewSystemSettingRepository(db)
-	antigravityQuotaRepo := sqlite.NewAntigravityQuotaRepository(db)
-	cooldownRepo := sqlite.NewCooldownRepository(db)
-	failureCountRepo := sqlite.NewFailureCountRepository(db)
-	apiTokenRepo := sqlite.NewAPITokenRepository(db)
-	modelMappingRepo := sqlite.NewModelMappingRepository(db)
-	usageStatsRepo := sqlite.NewUsageStatsRepository(db)
-	responseModelRepo := sqlite.NewResponseModelRepository(db)
+	// Repositories
+	proxyRequestRepo := repos.ProxyRequestRepo
+	attemptRepo := repos.AttemptRepo
+	settingRepo := repos.SettingRepo
+	antigravityQuotaRepo := repos.AntigravityQuotaRepo
+	usageStatsRepo := repos.UsageStatsRepo
+	responseModelRepo := repos.ResponseModelRepo
 
 	// Initialize cooldown manager with database persistence
-	cooldown.Default().SetRepository(cooldownRepo)
-	cooldown.Default().SetFailureCountRepository(failureCountRepo)
+	cooldown.Default().SetRepository(repos.CooldownRepo)
+	cooldown.Default().SetFailureCountRepository(repos.FailureCountRepo)
 	if err := cooldown.Default().LoadFromDatabase(); err != nil {
 		log.Printf("Warning: Failed to load cooldowns from database: %v", err)
 	}
 
-	// Generate instance ID and mark stale requests as failed
-	instanceID := generateInstanceID()
-	if count, err := proxyRequestRepo.MarkStaleAsFailed(instanceID); err != nil {
-		log.Printf("Warning: Failed to mark stale requests: %v", err)
-	} else if count > 0 {
-		log.Printf("Marked %d stale requests as failed", count)
+	// Load configured model capabilities
+	capability.Default().SetRepository(repos.ModelCapabilityRepo)
+	if err := capability.Default().Load(); err != nil {
+		log.Printf("Warning: Failed to load model capabilities: %v", err)
 	}
 
-	// Create cached repositories
-	cachedProviderRepo := cached.NewProviderRepository(providerRepo)
-	cachedRouteRepo := cached.NewRouteRepository(routeRepo)
-	cachedRetryConfigRepo := cached.NewRetryConfigRepository(retryConfigRepo)
-	cachedRoutingStrategyRepo := cached.NewRoutingStrategyRepository(routingStrategyRepo)
-	cachedSessionRepo := cached.NewSessionRepository(sessionRepo)
-	cachedProjectRepo := cached.NewProjectRepository(projectRepo)
-	cachedAPITokenRepo := cached.NewAPITokenRepository(apiTokenRepo)
-	cachedModelMappingRepo := cached.NewModelMappingRepository(modelMappingRepo)
+	// Generate instance ID and recover stale requests from previous instances
+	instanceID := generateInstanceID()
+	recoverySummary := core.RecoverStaleRequests(proxyRequestRepo, attemptRepo, instanceID)
+
+	// Cached repositories
+	cachedProviderRepo := repos.CachedProviderRepo
+	cachedRouteRepo := repos.CachedRouteRepo
+	cachedRetryConfigRepo := repos.CachedRetryConfigRepo
+	cachedRoutingStrategyRepo := repos.CachedRoutingStrategyRepo
+	cachedSessionRepo := repos.CachedSessionRepo
+	cachedProjectRepo := repos.CachedProjectRepo
+	cachedAPITokenRepo := repos.CachedAPITokenRepo
+	cachedModelMappingRepo := repos.CachedModelMappingRepo
 
 	// Load cached data
 	if err := cachedProviderRepo.Load(); err != nil {
@@ -149,14 +155,36 @@ func main() {
 		log.Printf("Warning: Failed to load model mappings cache: %v", err)
 	}
 
+	// Restore today's usage of providers with local quotas
+	if providers, err := cachedProviderRepo.List(); err == nil {
+		quota.Default().Seed(providers, usageStatsRepo)
+	}
+
 	// Create router
-	r := router.NewRouter(cachedRouteRepo, cachedProviderRepo, cachedRoutingStrategyRepo, cachedRetryConfigRepo, cachedProjectRepo)
+	r := router.NewRouter(cachedRouteRepo, cachedProviderRepo, cachedRoutingStrategyRepo, cachedRetryConfigRepo, cachedProjectRepo, usageStatsRepo)
 
 	// Initialize provider adapters
 	if err := r.InitAdapters(); err != nil {
 		log.Printf("Warning: Failed to initialize adapters: %v", err)
 	}
 
+	// Stream buffer size and flush interval are read from system settings for each response
+	stream.SetConfigGetter(func() stream.Config {
+		return stream.ConfigFromSettings(settingRepo.Get)
+	})
+
+	// Settings the executor reads per request (e.g. model fallback chains)
+	executor.SetSettingsGetter(settingRepo.Get)
+
+	// Language of the error messages sent to clients and of the OAuth pages
+	i18n.SetSettingsGetter(settingRepo.Get)
+
+	// Antigravity providers sharing a refresh token share (and persist) one access token
+	if err := repos.OAuthTokenRepo.DeleteExpired(); err != nil {
+		log.Printf("Warning: Failed to delete expired access tokens: %v", err)
+	}
+	antigravity.SetTokenStore(repos.OAuthTokenRepo)
+
 	// Start cooldown cleanup goroutine
 	go func() {
 		ticker := time.NewTicker(1 * time.Hour)
@@ -183,6 +211,7 @@ func main() {
 
 	// Create WebSocket hub
 	wsHub := handler.NewWebSocketHub()
+	core.BroadcastRecoverySummary(wsHub, recoverySummary)
 
 	// Setup log output to broadcast via WebSocket
 	logWriter := handler.NewWebSocketLogWriter(wsHub, os.Stdout, logPath)
@@ -208,17 +237,32 @@ func main() {
 		cachedSessionRepo,
 		cachedRetryConfigRepo,
 		cachedRoutingStrategyRepo,
+		repos.RoutingProfileRepo,
+		repos.CostAlertRepo,
+		repos.LatencySLORepo,
 		proxyRequestRepo,
 		attemptRepo,
 		settingRepo,
 		cachedAPITokenRepo,
 		cachedModelMappingRepo,
+		repos.ModelCapabilityRepo,
 		usageStatsRepo,
 		responseModelRepo,
 		*addr,
 		r, // Router implements ProviderAdapterRefresher interface
+		r, // Router implements RouteSimulator interface
 	)
 
+	// Check providers/routes/mappings in the background and report broken setups
+	adminService.SetCredentialValidator(core.ValidateProviderCredentials)
+	adminService.SetProviderProber(core.ProbeProviderRequest)
+	go core.CheckConfigOnStartup(adminService, wsHub)
+	core.StartFailback(adminService, settingRepo, cachedProviderRepo)
+	core.StartRoutingProfileScheduler(adminService)
+	core.StartSessionExpiry(adminService, wsHub)
+	core.StartCostAlerts(adminService, wsHub)
+	core.StartLatencySLOChecks(adminService, wsHub)
+
 	// Create auth middleware
 	authMiddleware := handler.NewAuthMiddleware()
 	if authMiddleware.IsEnabled() {
@@ -234,8 +278,11 @@ func main() {
 	}
 
 	// Create handlers
-	proxyHandler := handler.NewProxyHandler(clientAdapter, exec, cachedSessionRepo, tokenAuthMiddleware)
-	adminHandler := handler.NewAdminHandler(adminService, logPath)
+	requestGuard := handler.NewRequestGuard(settingRepo)
+	proxyHandler := handler.NewProxyHandler(clientAdapter, exec, cachedSessionRepo, tokenAuthMiddleware, requestGuard)
+	adminHandler := handler.NewAdminHandler(adminService, logPath, wsHub)
+	adminHandler.SetExecutor(exec)
+	adminHandler.SetRecoverySummary(recoverySummary)
 	authHandler := handler.NewAuthHandler(authMiddleware)
 	antigravityHandler := handler.NewAntigravityHandler(adminService, antigravityQuotaRepo, wsHub)
 	kiroHandler := handler.NewKiroHandler(adminService)
@@ -252,19 +299,28 @@ func main() {
 	// Admin API routes with authentication middleware
 	mux.Handle("/api/admin/", http.StripPrefix("/api", authMiddleware.Wrap(adminHandler)))
 
-	// Other API routes (no authentication required)
-	mux.Handle("/api/antigravity/", http.StripPrefix("/api", antigravityHandler))
-	mux.Handle("/api/kiro/", http.StripPrefix("/api", kiroHandler))
+	// Provider account APIs share the admin login; the OAuth callback is opened by the
+	// browser redirect and cannot carry a token, so it is checked by its state instead
+	mux.Handle("/api/antigravity/", http.StripPrefix("/api", authMiddleware.Wrap(antigravityHandler)))
+	mux.Handle("/api/antigravity/oauth/callback", http.StripPrefix("/api", antigravityHandler))
+	mux.Handle("/api/kiro/", http.StripPrefix("/api", authMiddleware.Wrap(kiroHandler)))
 
 	// Proxy routes - catch all AI API endpoints
 	// Claude API
 	mux.Handle("/v1/messages", proxyHandler)
 	// OpenAI API
 	mux.Handle("/v1/chat/completions", proxyHandler)
+	// OpenAI legacy completions API (served as chat completions)
+	mux.Handle("/v1/completions", proxyHandler)
 	// Codex API
 	mux.Handle("/responses", proxyHandler)
+	mux.Handle("/v1/responses", proxyHandler)
 	// Gemini API (Google AI Studio style)
 	mux.Handle("/v1beta/models/", proxyHandler)
+	// Model listings and lookups (GET/HEAD, passed through to providers)
+	mux.Handle("/v1/models", proxyHandler)
+	mux.Handle("/v1/models/", proxyHandler)
+	mux.Handle("/v1beta/models", proxyHandler)
 
 	// Health check
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
@@ -273,13 +329,27 @@ func main() {
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
-	// WebSocket endpoint
-	mux.HandleFunc("/ws", wsHub.HandleWebSocket)
+	// Read-only public status page (no authentication required)
+	statusHandler := handler.NewStatusHandler(adminService)
+	mux.Handle("/status", statusHandler)
+	mux.Handle("/status.json", statusHandler)
+
+	// WebSocket endpoint (events include request contents, so it shares the admin login)
+	mux.HandleFunc("/ws", wsHub.HandleWebSocketWithAuth(authMiddleware))
 
 	// Serve static files (Web UI) with project proxy support - must be last (default route)
-	staticHandler := handler.NewStaticHandler()
-	combinedHandler := handler.NewCombinedHandler(projectProxyHandler, staticHandler)
-	mux.Handle("/", combinedHandler)
+	if *console {
+		if *webDir != "" {
+			handler.StaticDir = *webDir
+		} else if envWebDir := os.Getenv("MAXX_WEB_DIR"); envWebDir != "" {
+			handler.StaticDir = envWebDir
+		}
+		staticHandler := handler.NewStaticHandler()
+		combinedHandler := handler.NewCombinedHandler(projectProxyHandler, staticHandler)
+		mux.Handle("/", combinedHandler)
+	} else {
+		mux.Handle("/", projectProxyHandler)
+	}
 
 	// Wrap with logging middleware
 	loggedMux := handler.LoggingMiddleware(mux)
@@ -287,8 +357,20 @@ func main() {
 	// Start server
 	log.Printf("Starting Maxx server %s on %s", version.Info(), *addr)
 	log.Printf("Data directory: %s", dataDirPath)
-	log.Printf("  Database: %s", dbPath)
+	if memory.IsDSN(dsn) {
+		log.Printf("  Database: in-memory (not persisted)")
+	} else {
+		log.Printf("  Database: %s", dbPath)
+	}
 	log.Printf("  Log file: %s", logPath)
+	if *console {
+		log.Printf("Web console: http://localhost%s/ (from %s)", *addr, handler.StaticDir)
+		if _, err := os.Stat(filepath.Join(handler.StaticDir, "index.html")); err != nil {
+			log.Printf("  Web console is not built in %s, run 'task web-build' or set -web-dir", handler.StaticDir)
+		}
+	} else {
+		log.Printf("Web console: disabled")
+	}
 	log.Printf("Admin API: http://localhost%s/api/admin/", *addr)
 	log.Printf("WebSocket: ws://localhost%s/ws", *addr)
 	log.Printf("Proxy endpoints:")
@@ -297,9 +379,38 @@ func main() {
 	log.Printf("  Codex:  http://localhost%s/v1/responses", *addr)
 	log.Printf("  Gemini: http://localhost%s/v1beta/models/{model}:generateContent", *addr)
 	log.Printf("Project proxy: http://localhost%s/{project-slug}/v1/messages (etc.)", *addr)
+	if *console && !handler.IsLoopbackAddr(*addr) && !authMiddleware.IsEnabled() {
+		log.Printf("WARNING: the web console is reachable beyond localhost without a login")
+		log.Printf("  Set %s to require a login, or run with -console=false", handler.AdminPasswordEnvKey)
+	}
+	if !handler.IsLoopbackAddr(*addr) && !tokenAuthMiddleware.IsEnabled() && !tokenAuthMiddleware.LoopbackBypassEnabled() {
+		log.Printf("WARNING: listening beyond localhost without API token authentication, anyone who can reach %s can use the proxy", *addr)
+		log.Printf("  Enable token auth (or the loopback bypass) in the settings, or listen on 127.0.0.1 only")
+	}
 
-	if err := http.ListenAndServe(*addr, loggedMux); err != nil {
+	server := &http.Server{Addr: *addr, Handler: loggedMux}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		sigCh := make(chan os.Signal, 1)
+		signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+		sig := <-sigCh
+		log.Printf("Received %s, shutting down", sig)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			log.Printf("Graceful shutdown failed: %v", err)
+		}
+	}()
+
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		log.Printf("Server error: %v", err)
+		core.CloseDatabase(repos)
 		os.Exit(1)
 	}
+	// Wait for in-flight requests, then write the queued request and attempt updates
+	<-stopped
+	if err := core.CloseDatabase(repos); err != nil {
+		log.Printf("Failed to close database: %v", err)
+	}
 }
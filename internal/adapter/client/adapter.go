@@ -43,7 +43,9 @@ func (a *Adapter) Match(req *http.Request) (domain.ClientType, bool) {
 		return domain.ClientTypeCodex, true
 	case strings.HasPrefix(path, "/v1/chat/completions"):
 		return domain.ClientTypeOpenAI, true
-	case strings.HasPrefix(path, "/v1beta/models/"):
+	case path == "/v1/models" || strings.HasPrefix(path, "/v1/models/"):
+		return modelsClientType(req), true
+	case path == "/v1beta/models" || strings.HasPrefix(path, "/v1beta/models/"):
 		return domain.ClientTypeGemini, true
 	case strings.HasPrefix(path, "/v1internal/models/"):
 		return domain.ClientTypeGemini, true
@@ -211,7 +213,9 @@ func (a *Adapter) DetectClientType(req *http.Request, body []byte) domain.Client
 		return domain.ClientTypeCodex
 	case strings.HasPrefix(path, "/v1/chat/completions"):
 		return domain.ClientTypeOpenAI
-	case strings.HasPrefix(path, "/v1beta/models/"):
+	case path == "/v1/models" || strings.HasPrefix(path, "/v1/models/"):
+		return modelsClientType(req)
+	case path == "/v1beta/models" || strings.HasPrefix(path, "/v1beta/models/"):
 		return domain.ClientTypeGemini
 	case strings.HasPrefix(path, "/v1internal/models/"):
 		return domain.ClientTypeGemini
@@ -221,6 +225,15 @@ func (a *Adapter) DetectClientType(req *http.Request, body []byte) domain.Client
 	return a.detectFromBodyBytes(body)
 }
 
+// modelsClientType tells Claude and OpenAI calls to the shared /v1/models endpoint apart:
+// Anthropic SDKs send anthropic-version and authenticate with x-api-key
+func modelsClientType(req *http.Request) domain.ClientType {
+	if req.Header.Get("anthropic-version") != "" || req.Header.Get("x-api-key") != "" {
+		return domain.ClientTypeClaude
+	}
+	return domain.ClientTypeOpenAI
+}
+
 func (a *Adapter) detectFromBodyBytes(body []byte) domain.ClientType {
 	var data map[string]interface{}
 	if err := json.Unmarshal(body, &data); err != nil {
@@ -258,6 +271,11 @@ func (a *Adapter) detectFromBodyBytes(body []byte) domain.ClientType {
 
 // ExtractModel extracts the model from the request (URL path for Gemini, body for others)
 func (a *Adapter) ExtractModel(req *http.Request, body []byte, clientType domain.ClientType) string {
+	// GET /v1/models/{model}
+	if model, ok := strings.CutPrefix(req.URL.Path, "/v1/models/"); ok && model != "" {
+		return model
+	}
+
 	// For Gemini, try URL path first
 	if clientType == domain.ClientTypeGemini {
 		path := req.URL.Path
@@ -19,6 +19,21 @@ type ProviderAdapter interface {
 	Execute(ctx context.Context, w http.ResponseWriter, req *http.Request, provider *domain.Provider) error
 }
 
+// AuxiliaryAdapter is implemented by adapters that can pass auxiliary requests, such as
+// Gemini countTokens and GET/HEAD model listings and lookups, through to the upstream unchanged.
+// The method comes from req; URI, body and mapped model come from ctx like for Execute.
+// Upstream error responses are returned as ProxyError without being written to w.
+type AuxiliaryAdapter interface {
+	ExecuteAuxiliary(ctx context.Context, w http.ResponseWriter, req *http.Request, provider *domain.Provider) error
+}
+
+// PassthroughAdapter is implemented by adapters that copy the upstream stream to w byte for
+// byte when ctx has passthrough set. The executor only enables passthrough for them, since the
+// response capture is reduced to the status and headers while it is on.
+type PassthroughAdapter interface {
+	SupportsPassthrough() bool
+}
+
 // AdapterFactory creates ProviderAdapter instances
 type AdapterFactory func(provider *domain.Provider) (ProviderAdapter, error)
 
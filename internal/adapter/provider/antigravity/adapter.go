@@ -6,17 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/awsl-project/maxx/internal/adapter/provider"
+	"github.com/awsl-project/maxx/internal/adapter/provider/stream"
 	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/converter"
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/httpclient"
+	"github.com/awsl-project/maxx/internal/thinking"
 	"github.com/awsl-project/maxx/internal/usage"
 )
 
@@ -24,16 +26,8 @@ func init() {
 	provider.RegisterAdapterFactory("antigravity", NewAdapter)
 }
 
-// TokenCache caches access tokens
-type TokenCache struct {
-	AccessToken string
-	ExpiresAt   time.Time
-}
-
 type AntigravityAdapter struct {
 	provider   *domain.Provider
-	tokenCache *TokenCache
-	tokenMu    sync.RWMutex
 	httpClient *http.Client
 }
 
@@ -43,8 +37,7 @@ func NewAdapter(p *domain.Provider) (provider.ProviderAdapter, error) {
 	}
 	return &AntigravityAdapter{
 		provider:   p,
-		tokenCache: &TokenCache{},
-		httpClient: newUpstreamHTTPClient(),
+		httpClient: newUpstreamHTTPClient(p),
 	}, nil
 }
 
@@ -119,14 +112,24 @@ func (a *AntigravityAdapter) Execute(ctx context.Context, w http.ResponseWriter,
 				effectiveMappedModel string
 				hasThinking          bool
 			)
-			geminiBody, effectiveMappedModel, hasThinking, err = TransformClaudeToGemini(requestBody, mappedModel, actualStream, sessionID, GlobalSignatureCache())
+			signatureMode := resolveThoughtSignatureMode(ctxutil.GetThoughtSignatureMode(ctx), config)
+			geminiBody, effectiveMappedModel, hasThinking, err = TransformClaudeToGemini(requestBody, provider.ID, mappedModel, actualStream, sessionID, thinking.GlobalSignatureCache(), signatureMode)
 			if err != nil {
-				return domain.NewProxyErrorWithMessage(err, true, fmt.Sprintf("failed to transform Claude request: %v", err))
+				convErr := &converter.ConversionError{
+					From:   domain.ClientTypeClaude,
+					To:     domain.ClientTypeGemini,
+					Stage:  converter.StageRequest,
+					Reason: err.Error(),
+					Err:    err,
+				}
+				return domain.NewProxyErrorWithMessage(convErr, false, "failed to transform Claude request")
 			}
 			mappedModel = effectiveMappedModel
 
 			// Apply minimal post-processing for features not yet fully integrated
-			geminiBody = applyClaudePostProcess(geminiBody, sessionID, hasThinking, requestBody, mappedModel)
+			streamToolArgs := clientWantsStream && converter.HasAnthropicBeta(
+				ctxutil.GetRequestHeaders(ctx).Get("anthropic-beta"), converter.FineGrainedToolStreamingBeta)
+			geminiBody = applyClaudePostProcess(geminiBody, sessionID, hasThinking, streamToolArgs, mappedModel, signatureMode)
 		} else if clientType == domain.ClientTypeOpenAI {
 			// TODO: Implement OpenAI transformation in the future
 			return domain.NewProxyErrorWithMessage(domain.ErrFormatConversion, true, "OpenAI transformation not yet implemented")
@@ -195,10 +198,8 @@ func (a *AntigravityAdapter) Execute(ctx context.Context, w http.ResponseWriter,
 			if resp.StatusCode == http.StatusUnauthorized {
 				resp.Body.Close()
 
-				// Invalidate token cache
-				a.tokenMu.Lock()
-				a.tokenCache = &TokenCache{}
-				a.tokenMu.Unlock()
+				// Invalidate the shared token (other providers on the same account see the refresh too)
+				InvalidateSharedAccessToken(a.provider.Config.Antigravity.RefreshToken, accessToken)
 
 				// Get new token
 				accessToken, err = a.getAccessToken(ctx)
@@ -279,6 +280,8 @@ func (a *AntigravityAdapter) Execute(ctx context.Context, w http.ResponseWriter,
 				// Set status code and check if it's a server error (5xx)
 				proxyErr.HTTPStatusCode = resp.StatusCode
 				proxyErr.IsServerError = resp.StatusCode >= 500 && resp.StatusCode < 600
+				proxyErr.ResponseBody = body
+				proxyErr.ResponseFormat = domain.ClientTypeGemini
 
 				// Set retry info on error for upstream handling
 				if retryAfter > 0 {
@@ -321,6 +324,12 @@ func (a *AntigravityAdapter) Execute(ctx context.Context, w http.ResponseWriter,
 				return proxyErr
 			}
 
+			// Report how long reading the response body (the whole stream, if streaming) took
+			bodyStart := time.Now()
+			defer func() {
+				ctxutil.GetEventChan(ctx).SendTimings(&domain.UpstreamTimings{Stream: time.Since(bodyStart)})
+			}()
+
 			// Handle response
 			if actualStream && !clientWantsStream {
 				return a.handleCollectedStreamResponse(ctx, w, resp, clientType, requestModel)
@@ -344,31 +353,7 @@ func (a *AntigravityAdapter) Execute(ctx context.Context, w http.ResponseWriter,
 }
 
 func (a *AntigravityAdapter) getAccessToken(ctx context.Context) (string, error) {
-	// Check cache
-	a.tokenMu.RLock()
-	if a.tokenCache.AccessToken != "" && time.Now().Before(a.tokenCache.ExpiresAt) {
-		token := a.tokenCache.AccessToken
-		a.tokenMu.RUnlock()
-		return token, nil
-	}
-	a.tokenMu.RUnlock()
-
-	// Refresh token
-	config := a.provider.Config.Antigravity
-	accessToken, expiresIn, err := refreshGoogleToken(ctx, config.RefreshToken)
-	if err != nil {
-		return "", err
-	}
-
-	// Cache token
-	a.tokenMu.Lock()
-	a.tokenCache = &TokenCache{
-		AccessToken: accessToken,
-		ExpiresAt:   time.Now().Add(time.Duration(expiresIn-60) * time.Second), // 60s buffer
-	}
-	a.tokenMu.Unlock()
-
-	return accessToken, nil
+	return GetSharedAccessToken(ctx, a.provider.Config.Antigravity.RefreshToken)
 }
 
 func refreshGoogleToken(ctx context.Context, refreshToken string) (string, int, error) {
@@ -384,8 +369,7 @@ func refreshGoogleToken(ctx context.Context, refreshToken string) (string, int,
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient().Do(req)
 	if err != nil {
 		return "", 0, err
 	}
@@ -407,33 +391,22 @@ func refreshGoogleToken(ctx context.Context, refreshToken string) (string, int,
 	return result.AccessToken, result.ExpiresIn, nil
 }
 
-func newUpstreamHTTPClient() *http.Client {
+func newUpstreamHTTPClient(p *domain.Provider) *http.Client {
 	// Mirrors Antigravity-Manager's reqwest client settings:
 	// connect_timeout=20s, pool_max_idle_per_host=16, pool_idle_timeout=90s, tcp_keepalive=60s, timeout=600s.
-	dialer := &net.Dialer{
-		Timeout:   20 * time.Second,
-		KeepAlive: 60 * time.Second,
-	}
-
-	transport := &http.Transport{
-		Proxy:                 http.ProxyFromEnvironment,
-		DialContext:           dialer.DialContext,
-		ForceAttemptHTTP2:     true,
-		MaxIdleConnsPerHost:   16,
-		IdleConnTimeout:       90 * time.Second,
-		TLSHandshakeTimeout:   20 * time.Second,
-		ExpectContinueTimeout: 1 * time.Second,
-	}
+	opts := httpclient.DefaultOptions()
+	opts.Timeout = 600 * time.Second
+	return httpclient.ForProvider(p, opts)
+}
 
-	return &http.Client{
-		Transport: transport,
-		Timeout:   600 * time.Second,
-	}
+// apiHTTPClient returns the shared client for OAuth and Cloud Code control-plane calls
+func apiHTTPClient() *http.Client {
+	return httpclient.Get("antigravity-api", httpclient.APIOptions())
 }
 
 // applyClaudePostProcess applies minimal post-processing for advanced features
 // not yet fully integrated into the transform functions
-func applyClaudePostProcess(geminiBody []byte, sessionID string, hasThinking bool, _ []byte, mappedModel string) []byte {
+func applyClaudePostProcess(geminiBody []byte, sessionID string, hasThinking bool, streamToolArgs bool, mappedModel string, signatureMode domain.ThoughtSignatureMode) []byte {
 	var request map[string]interface{}
 	if err := json.Unmarshal(geminiBody, &request); err != nil {
 		return geminiBody
@@ -451,6 +424,10 @@ func applyClaudePostProcess(geminiBody []byte, sessionID string, hasThinking boo
 		if processContentsForSignatures(contents, sessionID, mappedModel) {
 			modified = true
 		}
+		// always_skip: Gemini skips validation instead of rejecting a signature it doesn't accept
+		if signatureMode == domain.ThoughtSignatureAlwaysSkip && applySkipThoughtSignatures(contents) {
+			modified = true
+		}
 	}
 
 	// 3. Clean thinking fields if disabled
@@ -459,6 +436,11 @@ func applyClaudePostProcess(geminiBody []byte, sessionID string, hasThinking boo
 		modified = true
 	}
 
+	// 4. Stream function call args when the client accepts fine-grained tool streaming
+	if streamToolArgs && InjectStreamFunctionCallArgs(request) {
+		modified = true
+	}
+
 	if !modified {
 		return geminiBody
 	}
@@ -542,12 +524,19 @@ func (a *AntigravityAdapter) handleNonStreamResponse(ctx context.Context, w http
 		eventChan.SendResponseModel(modelVersion)
 	}
 
+	// A 200 without any content (e.g. zero candidates) is retried rather than returned
+	if !converter.GeminiHasOutput(unwrappedBody) {
+		return domain.NewProxyErrorWithMessage(domain.ErrEmptyResponse, true, "empty upstream response")
+	}
+
 	var responseBody []byte
 
 	// Transform response based on client type
 	if clientType == domain.ClientTypeClaude {
 		requestModel := ctxutil.GetRequestModel(ctx)
-		responseBody, err = convertGeminiToClaudeResponse(unwrappedBody, requestModel)
+		requestBody := ctxutil.GetRequestBody(ctx)
+		sessionID := extractSessionID(requestBody)
+		responseBody, err = convertGeminiToClaudeResponse(unwrappedBody, requestModel, sessionID, converter.StopSequences(requestBody))
 		if err != nil {
 			return domain.NewProxyErrorWithMessage(domain.ErrFormatConversion, false, "failed to transform response")
 		}
@@ -604,10 +593,12 @@ func (a *AntigravityAdapter) handleStreamResponse(ctx context.Context, w http.Re
 	var claudeState *ClaudeStreamingState
 	if isClaudeClient {
 		claudeState = NewClaudeStreamingStateWithSession(sessionID, requestModel)
+		claudeState.SetStopSequences(converter.StopSequences(requestBody))
 	}
 
-	// Collect all SSE events for response body and token extraction
+	// Collect all SSE events for the response body; usage is extracted as events pass
 	var sseBuffer strings.Builder
+	extractor := usage.NewStreamExtractor()
 
 	// Helper to extract tokens and send events
 	sendFinalEvents := func() {
@@ -619,16 +610,9 @@ func (a *AntigravityAdapter) handleStreamResponse(ctx context.Context, w http.Re
 				Body:    sseBuffer.String(),
 			})
 
-			// Extract and send token usage
-			if metrics := usage.ExtractFromStreamContent(sseBuffer.String()); metrics != nil {
-				eventChan.SendMetrics(&domain.AdapterMetrics{
-					InputTokens:          metrics.InputTokens,
-					OutputTokens:         metrics.OutputTokens,
-					CacheReadCount:       metrics.CacheReadCount,
-					CacheCreationCount:   metrics.CacheCreationCount,
-					Cache5mCreationCount: metrics.Cache5mCreationCount,
-					Cache1hCreationCount: metrics.Cache1hCreationCount,
-				})
+			// Send token usage
+			if metrics := extractor.Metrics(); metrics != nil {
+				eventChan.SendMetrics(metrics.ToAdapterMetrics())
 			}
 
 			// Extract and send response model
@@ -644,10 +628,44 @@ func (a *AntigravityAdapter) handleStreamResponse(ctx context.Context, w http.Re
 		}
 	}
 
-	// Use buffer-based approach like Antigravity-Manager
-	// Read chunks and accumulate until we have complete lines
-	var lineBuffer bytes.Buffer
-	buf := make([]byte, 4096)
+	// Read complete lines through a buffered reader and flush on event boundaries
+	cfg := stream.CurrentConfig()
+	reader := stream.NewLineReader(resp.Body, cfg.ReadBufferSize)
+	sw := stream.NewWriter(w, flusher, cfg)
+	defer sw.Close()
+
+	// Keep Claude clients from treating long thinking phases as a stalled stream
+	var heartbeat *stream.Heartbeat
+	if interval := ctxutil.GetPingInterval(ctx); isClaudeClient && interval > 0 {
+		heartbeat = stream.StartHeartbeat(sw, interval, claudeState.Ping())
+		defer heartbeat.Stop()
+	}
+
+	// Output is held back until upstream sends content, so that an empty stream can still
+	// fail the attempt and be retried instead of reaching the client as an empty message
+	var held []byte
+	hasOutput := false
+	emptyStream := func() bool {
+		if hasOutput || ctx.Err() != nil {
+			return false
+		}
+		heartbeat.Stop()
+		if sw.Written() {
+			_, _ = sw.Write(held)
+			return false
+		}
+		return true
+	}
+
+	// Ensure Claude clients get termination events
+	emitForceStop := func() {
+		if isClaudeClient && claudeState != nil {
+			if forceStop := claudeState.EmitForceStop(); len(forceStop) > 0 {
+				_, _ = sw.Write(forceStop)
+				sw.Flush()
+			}
+		}
+	}
 
 	for {
 		// Check context before reading
@@ -658,83 +676,71 @@ func (a *AntigravityAdapter) handleStreamResponse(ctx context.Context, w http.Re
 		default:
 		}
 
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			lineBuffer.Write(buf[:n])
-
-			// Process complete lines (lines ending with \n)
-			for {
-				line, readErr := lineBuffer.ReadString('\n')
-				if readErr != nil {
-					// No complete line yet, put partial data back
-					lineBuffer.WriteString(line)
-					break
-				}
+		line, err := reader.ReadLine()
+		if len(line) > 0 {
+			// Unwrap v1internal SSE chunk before processing
+			unwrappedLine := unwrapV1InternalSSEChunk(line)
 
-				// Process the complete line
-				lineBytes := []byte(line)
+			// Collect original SSE (the usage extractor handles the v1internal wrapper)
+			sseBuffer.Write(line)
 
-				// Unwrap v1internal SSE chunk before processing
-				unwrappedLine := unwrapV1InternalSSEChunk(lineBytes)
+			// Report usage as soon as an event carries it, for live token counters
+			extractor.Write(line)
+			if metrics, ok := extractor.Poll(); ok {
+				eventChan.SendMetrics(metrics.ToAdapterMetrics())
+			}
 
-				// Collect original SSE for token extraction (extractor handles v1internal wrapper)
-				sseBuffer.WriteString(line)
+			var output []byte
+			if isClaudeClient {
+				// Use specialized Claude SSE transformation
+				output = claudeState.ProcessGeminiSSELine(string(unwrappedLine))
+			} else if clientType == domain.ClientTypeOpenAI {
+				// TODO: Implement OpenAI streaming transformation
+				continue
+			} else {
+				// Gemini native
+				output = unwrappedLine
+			}
 
-				var output []byte
-				if isClaudeClient {
-					// Use specialized Claude SSE transformation
-					output = claudeState.ProcessGeminiSSELine(string(unwrappedLine))
-				} else if clientType == domain.ClientTypeOpenAI {
-					// TODO: Implement OpenAI streaming transformation
-					continue
+			if !hasOutput {
+				if hasOutput = sseChunkHasOutput(unwrappedLine); hasOutput {
+					output = append(held, output...)
+					held = nil
 				} else {
-					// Gemini native
-					output = unwrappedLine
+					held = append(held, output...)
+					output = nil
 				}
+			}
 
-				if len(output) > 0 {
-					_, writeErr := w.Write(output)
-					if writeErr != nil {
-						// Client disconnected
-						sendFinalEvents()
-						return domain.NewProxyErrorWithMessage(writeErr, false, "client disconnected")
-					}
-					flusher.Flush()
+			// Output is always a sequence of complete events (unwrap adds the \n\n terminator)
+			if len(output) > 0 {
+				if _, writeErr := sw.Write(output); writeErr != nil {
+					// Client disconnected
+					sendFinalEvents()
+					return domain.NewProxyErrorWithMessage(writeErr, false, "client disconnected")
 				}
+				sw.EventDone()
 			}
 		}
 
 		if err != nil {
+			if emptyStream() {
+				sendFinalEvents()
+				return domain.NewProxyErrorWithMessage(domain.ErrEmptyResponse, true, "empty upstream stream response")
+			}
 			if err == io.EOF {
-				// Ensure Claude clients get termination events
-				if isClaudeClient && claudeState != nil {
-					if forceStop := claudeState.EmitForceStop(); len(forceStop) > 0 {
-						_, _ = w.Write(forceStop)
-						flusher.Flush()
-					}
-				}
+				emitForceStop()
 				sendFinalEvents()
 				return nil
 			}
 			// Upstream connection closed - check if client is still connected
 			if ctx.Err() != nil {
 				// Try to send termination events for Claude clients
-				if isClaudeClient && claudeState != nil {
-					if forceStop := claudeState.EmitForceStop(); len(forceStop) > 0 {
-						_, _ = w.Write(forceStop)
-						flusher.Flush()
-					}
-				}
+				emitForceStop()
 				sendFinalEvents()
 				return domain.NewProxyErrorWithMessage(ctx.Err(), false, "client disconnected")
 			}
-			// Ensure Claude clients get termination events
-			if isClaudeClient && claudeState != nil {
-				if forceStop := claudeState.EmitForceStop(); len(forceStop) > 0 {
-					_, _ = w.Write(forceStop)
-					flusher.Flush()
-				}
-			}
+			emitForceStop()
 			sendFinalEvents()
 			return nil
 		}
@@ -763,15 +769,17 @@ func (a *AntigravityAdapter) handleCollectedStreamResponse(ctx context.Context,
 		requestBody := ctxutil.GetRequestBody(ctx)
 		sessionID := extractSessionID(requestBody)
 		claudeState = NewClaudeStreamingStateWithSession(sessionID, requestModel)
+		claudeState.SetStopSequences(converter.StopSequences(requestBody))
 	}
 
 	// Collect upstream SSE for attempt/debug and token extraction.
 	var upstreamSSE strings.Builder
+	extractor := usage.NewStreamExtractor()
 	var lastPayload []byte
 	var responseBody []byte
+	hasOutput := false
 
-	var lineBuffer bytes.Buffer
-	buf := make([]byte, 4096)
+	reader := stream.NewLineReader(resp.Body, stream.CurrentConfig().ReadBufferSize)
 
 	for {
 		// Check context before reading
@@ -781,23 +789,14 @@ func (a *AntigravityAdapter) handleCollectedStreamResponse(ctx context.Context,
 		default:
 		}
 
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			lineBuffer.Write(buf[:n])
-
-			for {
-				line, readErr := lineBuffer.ReadString('\n')
-				if readErr != nil {
-					lineBuffer.WriteString(line)
-					break
-				}
+		line, err := reader.ReadLine()
+		if len(line) > 0 {
+			upstreamSSE.Write(line)
+			extractor.Write(line)
 
-				upstreamSSE.WriteString(line)
-
-				unwrappedLine := unwrapV1InternalSSEChunk([]byte(line))
-				if len(unwrappedLine) == 0 {
-					continue
-				}
+			unwrappedLine := unwrapV1InternalSSEChunk(line)
+			if len(unwrappedLine) > 0 {
+				hasOutput = hasOutput || sseChunkHasOutput(unwrappedLine)
 
 				// Track last Gemini payload for non-Claude responses (best-effort)
 				lineStr := strings.TrimSpace(string(unwrappedLine))
@@ -840,16 +839,9 @@ func (a *AntigravityAdapter) handleCollectedStreamResponse(ctx context.Context,
 		Body:    upstreamSSE.String(),
 	})
 
-	// Extract and send token usage
-	if metrics := usage.ExtractFromStreamContent(upstreamSSE.String()); metrics != nil {
-		eventChan.SendMetrics(&domain.AdapterMetrics{
-			InputTokens:          metrics.InputTokens,
-			OutputTokens:         metrics.OutputTokens,
-			CacheReadCount:       metrics.CacheReadCount,
-			CacheCreationCount:   metrics.CacheCreationCount,
-			Cache5mCreationCount: metrics.Cache5mCreationCount,
-			Cache1hCreationCount: metrics.Cache1hCreationCount,
-		})
+	// Send token usage
+	if metrics := extractor.Metrics(); metrics != nil {
+		eventChan.SendMetrics(metrics.ToAdapterMetrics())
 	}
 
 	// Extract and send response model
@@ -863,9 +855,12 @@ func (a *AntigravityAdapter) handleCollectedStreamResponse(ctx context.Context,
 		eventChan.SendResponseModel(modelVersion)
 	}
 
+	if !hasOutput {
+		return domain.NewProxyErrorWithMessage(domain.ErrEmptyResponse, true, "empty upstream stream response")
+	}
 	if isClaudeClient {
 		if claudeSSE.Len() == 0 {
-			return domain.NewProxyErrorWithMessage(domain.ErrUpstreamError, true, "empty upstream stream response")
+			return domain.NewProxyErrorWithMessage(domain.ErrEmptyResponse, true, "empty upstream stream response")
 		}
 		collected, collectErr := collectClaudeSSEToJSON(claudeSSE.String())
 		if collectErr != nil {
@@ -874,7 +869,7 @@ func (a *AntigravityAdapter) handleCollectedStreamResponse(ctx context.Context,
 		responseBody = collected
 	} else {
 		if len(lastPayload) == 0 {
-			return domain.NewProxyErrorWithMessage(domain.ErrUpstreamError, true, "empty upstream stream response")
+			return domain.NewProxyErrorWithMessage(domain.ErrEmptyResponse, true, "empty upstream stream response")
 		}
 		switch clientType {
 		case domain.ClientTypeGemini:
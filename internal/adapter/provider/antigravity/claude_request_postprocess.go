@@ -3,6 +3,8 @@ package antigravity
 import (
 	"encoding/json"
 	"strings"
+
+	"github.com/awsl-project/maxx/internal/thinking"
 )
 
 // antigravityIdentity is the identity instruction injected when user doesn't provide one
@@ -14,15 +16,16 @@ You are pair programming with a USER to solve their coding task. The task may re
 
 // PostProcessClaudeRequest applies post-processing to the converted Gemini request
 // Similar to CLIProxyAPI's request handling logic:
-// 1. Injects Antigravity identity into system instruction (like Antigravity-Manager)
-// 2. Cleans tool input schemas for Gemini compatibility (like Antigravity-Manager)
-// 3. Injects interleaved thinking hint when tools + thinking are enabled
-// 4. Closes broken tool loops by injecting synthetic messages (like Antigravity-Manager)
-// 5. Uses cached signatures for thinking blocks
-// 6. Applies skip_thought_signature_validator for tool calls without valid signatures
-// 7. Merges adjacent messages with same role (like Antigravity-Manager)
-// 8. Injects toolConfig, stopSequences, effortLevel (like Antigravity-Manager)
-// 9. Validates signature model compatibility (like Antigravity-Manager)
+//  1. Injects Antigravity identity into system instruction (like Antigravity-Manager)
+//  2. Cleans tool input schemas for Gemini compatibility (like Antigravity-Manager)
+//  3. Injects interleaved thinking hint when tools + thinking are enabled
+//  4. Closes broken tool loops by injecting synthetic messages (like Antigravity-Manager)
+//  5. Uses cached signatures for thinking blocks
+//  6. Recovers signatures for tool calls; skip_thought_signature_validator is only
+//     applied in the always_skip thought signature mode (see applyClaudePostProcess)
+//  7. Merges adjacent messages with same role (like Antigravity-Manager)
+//  8. Injects toolConfig, stopSequences, effortLevel (like Antigravity-Manager)
+//  9. Validates signature model compatibility (like Antigravity-Manager)
 //
 // Note: cache_control cleaning is now done in adapter.go BEFORE transformation
 func PostProcessClaudeRequest(geminiBody []byte, sessionID string, hasThinking bool, claudeRequest []byte, mappedModel string) []byte {
@@ -263,7 +266,7 @@ func injectInterleavedHint(request map[string]interface{}) bool {
 // 3. Validate cross-model signature compatibility
 func processContentsForSignatures(contents []interface{}, _ string, mappedModel string) bool {
 	modified := false
-	cache := GlobalSignatureCache()
+	cache := thinking.GlobalSignatureCache()
 
 	for _, content := range contents {
 		contentMap, ok := content.(map[string]interface{})
@@ -295,7 +298,7 @@ func processContentsForSignatures(contents []interface{}, _ string, mappedModel
 				existingSig, _ := partMap["thoughtSignature"].(string)
 
 				// [NEW] Check model compatibility for existing signature
-				if hasValidThinkingSignature(text, existingSig) {
+				if thinking.HasValidBlockSignature(text, existingSig) {
 					if cachedFamily := cache.GetSignatureFamily(existingSig); cachedFamily != "" {
 						if !IsModelCompatible(cachedFamily, mappedModel) {
 							// Incompatible signature - downgrade to text
@@ -328,7 +331,7 @@ func processContentsForSignatures(contents []interface{}, _ string, mappedModel
 				existingSig, _ := partMap["thoughtSignature"].(string)
 
 				// [FIX] Try to recover signature from tool_id cache (like Antigravity-Manager)
-				if !HasValidSignature(existingSig) {
+				if !thinking.HasValidSignature(existingSig) {
 					if fcID, ok := fc["id"].(string); ok && fcID != "" {
 						if cachedSig := cache.GetToolSignature(fcID); cachedSig != "" {
 							// [NEW] Check model compatibility
@@ -348,8 +351,8 @@ func processContentsForSignatures(contents []interface{}, _ string, mappedModel
 				// [CRITICAL FIX] Only add thoughtSignature if we have a valid one
 				// Vertex AI v1internal rejects sentinel values like "skip_thought_signature_validator"
 				// Unlike CLIProxyAPI, we must NOT use sentinel values as fallback
-				if !HasValidSignature(existingSig) {
-					if HasValidSignature(currentThinkingSignature) {
+				if !thinking.HasValidSignature(existingSig) {
+					if thinking.HasValidSignature(currentThinkingSignature) {
 						partMap["thoughtSignature"] = currentThinkingSignature
 						modified = true
 					}
@@ -383,7 +386,7 @@ func processContentsForSignatures(contents []interface{}, _ string, mappedModel
 			// For thinking parts, only keep if they have valid signature
 			text, _ := partMap["text"].(string)
 			sig, _ := partMap["thoughtSignature"].(string)
-			if hasValidThinkingSignature(text, sig) {
+			if thinking.HasValidBlockSignature(text, sig) {
 				filteredParts = append(filteredParts, part)
 			}
 			// Drop unsigned thinking blocks (they break API validation)
@@ -692,6 +695,28 @@ func InjectToolConfig(request map[string]interface{}) bool {
 	return true
 }
 
+// InjectStreamFunctionCallArgs asks Gemini to stream function call args as partial args,
+// so tool input reaches clients that enabled fine-grained tool streaming as it is generated
+func InjectStreamFunctionCallArgs(request map[string]interface{}) bool {
+	tools, ok := request["tools"].([]interface{})
+	if !ok || len(tools) == 0 {
+		return false
+	}
+
+	toolConfig, ok := request["toolConfig"].(map[string]interface{})
+	if !ok {
+		toolConfig = map[string]interface{}{}
+		request["toolConfig"] = toolConfig
+	}
+	fcConfig, ok := toolConfig["functionCallingConfig"].(map[string]interface{})
+	if !ok {
+		fcConfig = map[string]interface{}{}
+		toolConfig["functionCallingConfig"] = fcConfig
+	}
+	fcConfig["streamFunctionCallArguments"] = true
+	return true
+}
+
 // InjectStopSequences adds default stop sequences to generationConfig
 // (like Antigravity-Manager's stop sequences injection)
 func InjectStopSequences(request map[string]interface{}) bool {
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/awsl-project/maxx/internal/converter"
 )
 
 type claudeSSEEvent struct {
@@ -142,10 +144,17 @@ func collectClaudeSSEToJSON(sse string) ([]byte, error) {
 
 		case "content_block_stop":
 			if currentText.Len() > 0 {
-				content = append(content, map[string]interface{}{
-					"type": "text",
-					"text": currentText.String(),
-				})
+				// Images were streamed as markdown; return them as image blocks
+				if blocks := converter.SplitInlineImages(currentText.String()); blocks != nil {
+					for _, block := range blocks {
+						content = append(content, block)
+					}
+				} else {
+					content = append(content, map[string]interface{}{
+						"type": "text",
+						"text": currentText.String(),
+					})
+				}
 				currentText.Reset()
 				continue
 			}
@@ -3,27 +3,19 @@ package antigravity
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"strings"
 	"time"
-)
-
-// BlockType represents the type of content block being processed
-type BlockType int
 
-const (
-	BlockTypeNone BlockType = iota
-	BlockTypeText
-	BlockTypeThinking
-	BlockTypeFunction
+	"github.com/awsl-project/maxx/internal/claudesse"
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/thinking"
 )
 
 // ClaudeStreamingState maintains state for Gemini -> Claude SSE conversion
 type ClaudeStreamingState struct {
-	blockType        BlockType
-	blockIndex       int
-	messageStartSent bool
-	messageStopSent  bool
-	usedTool         bool
+	sse      *claudesse.Emitter // Keeps the Claude event sequence valid
+	usedTool bool
 
 	// Signature management
 	pendingSignature  *string
@@ -34,33 +26,66 @@ type ClaudeStreamingState struct {
 	outputTokens    int
 	cacheReadTokens int
 
+	// Session of the request (metadata.user_id), under which tool calls are remembered
+	sessionID string
+
 	// Response metadata
 	requestModel string // Original Claude model from request (for response)
 	modelVersion string // Gemini model version from upstream (for debugging)
 	responseID   string
 
-	// Grounding (web search) captured during streaming, emitted at finish (like Antigravity-Manager)
-	webSearchQuery  string
-	groundingChunks []GeminiGroundingChunk
+	// Grounding (web search) captured during streaming, emitted at finish
+	grounding *GeminiGroundingMetadata
+
+	// Function call whose args are arriving as partial args (fine-grained tool streaming)
+	streamedCall *streamedFunctionCall
+
+	// Stop sequences of the client request: Gemini reports them as a plain STOP, so they are
+	// detected in the text. stopHeld is text that may be the start of one.
+	stopSequences []string
+	stopHeld      string
+	stopSequence  string
+}
+
+// streamedFunctionCall tracks a tool_use block whose input is forwarded as Gemini streams it
+type streamedFunctionCall struct {
+	name    string
+	encoder *converter.PartialArgsEncoder
+	written bool
+	// Tools whose args are remapped can't be forwarded piecemeal: their JSON is buffered and
+	// sent remapped once the call is complete
+	remap  bool
+	buffer strings.Builder
 }
 
 // NewClaudeStreamingState creates a new streaming state
 func NewClaudeStreamingState() *ClaudeStreamingState {
 	return &ClaudeStreamingState{
-		blockType:  BlockTypeNone,
-		blockIndex: 0,
+		sse: claudesse.NewEmitter(),
 	}
 }
 
 // NewClaudeStreamingStateWithSession creates a new streaming state with session ID and request model
-func NewClaudeStreamingStateWithSession(_ string, requestModel string) *ClaudeStreamingState {
+func NewClaudeStreamingStateWithSession(sessionID string, requestModel string) *ClaudeStreamingState {
 	return &ClaudeStreamingState{
-		blockType:    BlockTypeNone,
-		blockIndex:   0,
+		sse:          claudesse.NewEmitter(),
+		sessionID:    sessionID,
 		requestModel: requestModel,
 	}
 }
 
+// SetStopSequences sets the stop sequences of the client request, so the stream can
+// report the one it stopped at
+func (s *ClaudeStreamingState) SetStopSequences(sequences []string) {
+	s.stopSequences = sequences
+}
+
+// Ping returns the ping event sent while the upstream is silent. It doesn't touch the
+// state, so the heartbeat may send it while lines are being processed.
+func (s *ClaudeStreamingState) Ping() []byte {
+	return claudesse.Ping()
+}
+
 // GetModelVersion returns the upstream model version captured during streaming
 func (s *ClaudeStreamingState) GetModelVersion() string {
 	return s.modelVersion
@@ -80,6 +105,26 @@ type GeminiFunctionCall struct {
 	Name string                 `json:"name"`
 	Args map[string]interface{} `json:"args,omitempty"`
 	ID   string                 `json:"id,omitempty"`
+
+	// Set when streamFunctionCallArguments is enabled: args arrive in fragments over several chunks
+	PartialArgs  []converter.GeminiPartialArg `json:"partialArgs,omitempty"`
+	WillContinue bool                         `json:"willContinue,omitempty"`
+}
+
+// UnmarshalJSON repairs malformed or truncated args (Gemini may emit them when a response is
+// cut), so tool inputs sent to the client are always valid JSON. Sanitized tool names are
+// mapped back to the names the client declared.
+func (fc *GeminiFunctionCall) UnmarshalJSON(data []byte) error {
+	call, err := converter.DecodeGeminiFunctionCall(data)
+	if err != nil {
+		return err
+	}
+	fc.Name = converter.RestoreToolName(call.Name)
+	fc.ID = call.ID
+	fc.Args = call.Args
+	fc.PartialArgs = call.PartialArgs
+	fc.WillContinue = call.WillContinue
+	return nil
 }
 
 // GeminiInlineData represents inline data (images) in Gemini response
@@ -110,59 +155,16 @@ type GeminiStreamChunk struct {
 }
 
 // GeminiGroundingMetadata represents grounding/web search metadata from Gemini
-type GeminiGroundingMetadata struct {
-	WebSearchQueries []string                `json:"webSearchQueries,omitempty"`
-	GroundingChunks  []GeminiGroundingChunk  `json:"groundingChunks,omitempty"`
-	SearchEntryPoint *GeminiSearchEntryPoint `json:"searchEntryPoint,omitempty"`
-}
-
-// GeminiGroundingChunk represents a grounding chunk (web search result)
-type GeminiGroundingChunk struct {
-	Web *GeminiGroundingWeb `json:"web,omitempty"`
-}
-
-// GeminiGroundingWeb represents web source in grounding chunk
-type GeminiGroundingWeb struct {
-	URI   string `json:"uri,omitempty"`
-	Title string `json:"title,omitempty"`
-}
-
-// GeminiSearchEntryPoint represents search entry point
-type GeminiSearchEntryPoint struct {
-	RenderedContent string `json:"renderedContent,omitempty"`
-}
-
-// formatSSE formats an SSE event with proper double newline terminator
-func formatSSE(eventType string, data interface{}) []byte {
-	jsonBytes, err := json.Marshal(data)
-	if err != nil {
-		return nil
-	}
-	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", eventType, string(jsonBytes)))
-}
-
-// emit emits a single SSE event
-func (s *ClaudeStreamingState) emit(eventType string, data map[string]interface{}) []byte {
-	return formatSSE(eventType, data)
-}
+type GeminiGroundingMetadata = converter.GeminiGroundingMetadata
 
 // emitDelta emits a content_block_delta event
 func (s *ClaudeStreamingState) emitDelta(deltaType string, deltaContent map[string]interface{}) []byte {
-	delta := map[string]interface{}{"type": deltaType}
-	for k, v := range deltaContent {
-		delta[k] = v
-	}
-
-	return s.emit("content_block_delta", map[string]interface{}{
-		"type":  "content_block_delta",
-		"index": s.blockIndex,
-		"delta": delta,
-	})
+	return s.sse.Delta(deltaType, deltaContent)
 }
 
 // emitMessageStart emits the message_start event
 func (s *ClaudeStreamingState) emitMessageStart(chunk *GeminiStreamChunk) []byte {
-	if s.messageStartSent {
+	if s.sse.Started() {
 		return nil
 	}
 
@@ -205,118 +207,75 @@ func (s *ClaudeStreamingState) emitMessageStart(chunk *GeminiStreamChunk) []byte
 		message["usage"] = usage
 	}
 
-	result := s.emit("message_start", map[string]interface{}{
-		"type":    "message_start",
-		"message": message,
-	})
-
-	s.messageStartSent = true
-	return result
+	return s.sse.MessageStart(message)
 }
 
-// startBlock starts a new content block
-func (s *ClaudeStreamingState) startBlock(blockType BlockType, contentBlock map[string]interface{}) [][]byte {
-	var chunks [][]byte
-
-	// End previous block if any
-	if s.blockType != BlockTypeNone {
-		chunks = append(chunks, s.endBlock()...)
-	}
-
-	// Start new block
-	chunks = append(chunks, s.emit("content_block_start", map[string]interface{}{
-		"type":          "content_block_start",
-		"index":         s.blockIndex,
-		"content_block": contentBlock,
-	}))
-
-	s.blockType = blockType
-	return chunks
+// startBlock starts a new content block, ending the previous one if any
+func (s *ClaudeStreamingState) startBlock(contentBlock map[string]interface{}) [][]byte {
+	chunks := s.endBlock()
+	return append(chunks, s.sse.StartBlock(contentBlock))
 }
 
 // endBlock ends the current content block
 func (s *ClaudeStreamingState) endBlock() [][]byte {
-	if s.blockType == BlockTypeNone {
+	if s.sse.BlockType() == "" {
 		return nil
 	}
 
 	var chunks [][]byte
 
 	// Emit pending signature for thinking blocks
-	if s.blockType == BlockTypeThinking && s.pendingSignature != nil {
-		chunks = append(chunks, s.emitDelta("signature_delta", map[string]interface{}{
-			"signature": *s.pendingSignature,
-		}))
+	if s.sse.BlockType() == claudesse.BlockThinking && s.pendingSignature != nil {
+		chunks = append(chunks, s.sse.Signature(*s.pendingSignature))
 		s.pendingSignature = nil
 	}
 
-	chunks = append(chunks, s.emit("content_block_stop", map[string]interface{}{
-		"type":  "content_block_stop",
-		"index": s.blockIndex,
-	}))
-
-	s.blockIndex++
-	s.blockType = BlockTypeNone
-
-	return chunks
+	return append(chunks, s.sse.EndBlock())
 }
 
 // emitFinish emits the finish events (message_delta and message_stop)
 func (s *ClaudeStreamingState) emitFinish(finishReason string, usage *GeminiUsageMetadata) [][]byte {
 	var chunks [][]byte
 
+	// Text held back for a possible stop sequence that did not come
+	chunks = append(chunks, s.releaseStopHeld()...)
+
 	// End current block
+	chunks = append(chunks, s.finishStreamedCall(nil)...)
 	chunks = append(chunks, s.endBlock()...)
 
-	// Handle trailing signature
+	// Handle trailing signature: create empty thinking block for it
 	if s.trailingSignature != nil {
-		// Create empty thinking block for trailing signature
-		chunks = append(chunks, s.emit("content_block_start", map[string]interface{}{
-			"type":  "content_block_start",
-			"index": s.blockIndex,
-			"content_block": map[string]interface{}{
-				"type":     "thinking",
-				"thinking": "",
-			},
-		}))
-		chunks = append(chunks, s.emitDelta("thinking_delta", map[string]interface{}{"thinking": ""}))
-		chunks = append(chunks, s.emitDelta("signature_delta", map[string]interface{}{"signature": *s.trailingSignature}))
-		chunks = append(chunks, s.emit("content_block_stop", map[string]interface{}{
-			"type":  "content_block_stop",
-			"index": s.blockIndex,
-		}))
-		s.blockIndex++
+		chunks = append(chunks, s.emitEmptyThinkingWithSignature(*s.trailingSignature)...)
 		s.trailingSignature = nil
 	}
 
-	// Grounding (web search) -> emit as a separate Markdown text block at finish (like Antigravity-Manager)
-	if groundingText := s.buildGroundingMarkdown(); groundingText != "" {
-		chunks = append(chunks, s.emit("content_block_start", map[string]interface{}{
-			"type":  "content_block_start",
-			"index": s.blockIndex,
-			"content_block": map[string]interface{}{
-				"type": "text",
-				"text": "",
-			},
-		}))
-		chunks = append(chunks, s.emitDelta("text_delta", map[string]interface{}{"text": groundingText}))
-		chunks = append(chunks, s.emit("content_block_stop", map[string]interface{}{
-			"type":  "content_block_stop",
-			"index": s.blockIndex,
+	// Grounding (web search) -> server_tool_use / web_search_tool_result blocks and citations,
+	// like Anthropic's native web search
+	webSearch := false
+	if s.grounding != nil {
+		grounding := s.grounding
+		before := s.sse.BlockCount()
+		chunks = append(chunks, s.sse.Blocks(func(index int) ([]byte, int) {
+			return converter.WebSearchStreamEvents(grounding, index)
 		}))
-		s.blockIndex++
+		webSearch = s.sse.BlockCount() > before
 
 		// Clear grounding so we don't emit twice
-		s.webSearchQuery = ""
-		s.groundingChunks = nil
+		s.grounding = nil
+	}
+
+	// Nothing was streamed (e.g. image generation blocked): say why instead of an empty message
+	if s.sse.BlockCount() == 0 {
+		if notice := converter.GeminiFinishNotice(finishReason); notice != "" {
+			chunks = append(chunks, s.sse.Text(notice))
+		}
 	}
 
 	// Determine stop reason
-	stopReason := "end_turn"
-	if s.usedTool {
-		stopReason = "tool_use"
-	} else if finishReason == "MAX_TOKENS" {
-		stopReason = "max_tokens"
+	stopReason := converter.ClaudeStopReasonForGemini(finishReason, s.usedTool)
+	if s.stopSequence != "" {
+		stopReason = "stop_sequence"
 	}
 
 	// Build usage with all fields (like Antigravity-Manager's to_claude_usage)
@@ -338,21 +297,28 @@ func (s *ClaudeStreamingState) emitFinish(finishReason string, usage *GeminiUsag
 		// cache_creation_input_tokens: Gemini doesn't provide this, set to 0 (like Antigravity-Manager)
 		usageMap["cache_creation_input_tokens"] = 0
 	}
+	if webSearch {
+		usageMap["server_tool_use"] = map[string]interface{}{"web_search_requests": 1}
+	}
 
-	chunks = append(chunks, s.emit("message_delta", map[string]interface{}{
-		"type": "message_delta",
-		"delta": map[string]interface{}{
-			"stop_reason":   stopReason,
-			"stop_sequence": nil,
-		},
-		"usage": usageMap,
-	}))
+	return append(chunks, s.sse.FinishAtSequence(stopReason, s.stopSequence, usageMap))
+}
 
-	if !s.messageStopSent {
-		chunks = append(chunks, []byte("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n"))
-		s.messageStopSent = true
+// releaseStopHeld sends the text held back for a possible stop sequence, once other
+// content or the end of the message shows it was not one
+func (s *ClaudeStreamingState) releaseStopHeld() [][]byte {
+	if s.stopHeld == "" {
+		return nil
 	}
-
+	var chunks [][]byte
+	if s.sse.BlockType() != claudesse.BlockText {
+		chunks = s.startBlock(map[string]interface{}{
+			"type": "text",
+			"text": "",
+		})
+	}
+	chunks = append(chunks, s.emitDelta("text_delta", map[string]interface{}{"text": s.stopHeld}))
+	s.stopHeld = ""
 	return chunks
 }
 
@@ -363,7 +329,7 @@ func (s *ClaudeStreamingState) storeSignature(signature string) {
 
 		// Cache thinking family for cross-model compatibility (like Antigravity-Manager)
 		if s.modelVersion != "" {
-			GlobalSignatureCache().CacheThinkingFamily(signature, s.modelVersion)
+			thinking.GlobalSignatureCache().CacheThinkingFamily(signature, s.modelVersion)
 		}
 
 		// Best-effort global fallback store
@@ -400,8 +366,8 @@ func (s *ClaudeStreamingState) processThinking(text, signature string) [][]byte
 	}
 
 	// Start thinking block if not already in one
-	if s.blockType != BlockTypeThinking {
-		chunks = append(chunks, s.startBlock(BlockTypeThinking, map[string]interface{}{
+	if s.sse.BlockType() != claudesse.BlockThinking {
+		chunks = append(chunks, s.startBlock(map[string]interface{}{
 			"type":     "thinking",
 			"thinking": "",
 		})...)
@@ -426,6 +392,11 @@ func (s *ClaudeStreamingState) processThinking(text, signature string) [][]byte
 func (s *ClaudeStreamingState) processText(text, signature string) [][]byte {
 	var chunks [][]byte
 
+	// Cut the text at a stop sequence, holding back a tail that may be the start of one
+	if text != "" && len(s.stopSequences) > 0 && !s.usedTool && s.stopSequence == "" {
+		text, s.stopHeld, s.stopSequence = converter.StreamStopText(s.stopHeld+text, s.stopSequences)
+	}
+
 	// Empty text with signature -> trailing signature
 	if text == "" {
 		if signature != "" {
@@ -444,7 +415,7 @@ func (s *ClaudeStreamingState) processText(text, signature string) [][]byte {
 	// Non-empty text with signature -> emit text, then empty thinking with signature
 	if signature != "" {
 		// Start and emit text
-		chunks = append(chunks, s.startBlock(BlockTypeText, map[string]interface{}{
+		chunks = append(chunks, s.startBlock(map[string]interface{}{
 			"type": "text",
 			"text": "",
 		})...)
@@ -457,8 +428,8 @@ func (s *ClaudeStreamingState) processText(text, signature string) [][]byte {
 	}
 
 	// Regular text (no signature)
-	if s.blockType != BlockTypeText {
-		chunks = append(chunks, s.startBlock(BlockTypeText, map[string]interface{}{
+	if s.sse.BlockType() != claudesse.BlockText {
+		chunks = append(chunks, s.startBlock(map[string]interface{}{
 			"type": "text",
 			"text": "",
 		})...)
@@ -471,29 +442,41 @@ func (s *ClaudeStreamingState) processText(text, signature string) [][]byte {
 
 // emitEmptyThinkingWithSignature emits an empty thinking block with signature
 func (s *ClaudeStreamingState) emitEmptyThinkingWithSignature(signature string) [][]byte {
-	var chunks [][]byte
+	chunks := s.startBlock(map[string]interface{}{
+		"type":     "thinking",
+		"thinking": "",
+	})
+	chunks = append(chunks, s.sse.Thinking(""))
+	chunks = append(chunks, s.sse.Signature(signature))
+	return append(chunks, s.sse.EndBlock())
+}
 
-	chunks = append(chunks, s.emit("content_block_start", map[string]interface{}{
-		"type":  "content_block_start",
-		"index": s.blockIndex,
-		"content_block": map[string]interface{}{
-			"type":     "thinking",
-			"thinking": "",
-		},
-	}))
-	chunks = append(chunks, s.emitDelta("thinking_delta", map[string]interface{}{"thinking": ""}))
-	chunks = append(chunks, s.emitDelta("signature_delta", map[string]interface{}{"signature": signature}))
-	chunks = append(chunks, s.emit("content_block_stop", map[string]interface{}{
-		"type":  "content_block_stop",
-		"index": s.blockIndex,
-	}))
-	s.blockIndex++
+// processFunctionCall processes a function call part
+func (s *ClaudeStreamingState) processFunctionCall(fc *GeminiFunctionCall, signature string) [][]byte {
+	if s.streamedCall != nil || fc.WillContinue || len(fc.PartialArgs) > 0 {
+		return s.processStreamedFunctionCall(fc, signature)
+	}
+
+	chunks := s.startFunctionCall(fc, signature)
+
+	// Emit input_json_delta with remapped arguments
+	if fc.Args != nil {
+		args := fc.Args
+		remapFunctionCallArgs(fc.Name, args)
+		argsJSON, _ := json.Marshal(args)
+		chunks = append(chunks, s.emitDelta("input_json_delta", map[string]interface{}{
+			"partial_json": string(argsJSON),
+		}))
+	}
+
+	// End tool block
+	chunks = append(chunks, s.endBlock()...)
 
 	return chunks
 }
 
-// processFunctionCall processes a function call part
-func (s *ClaudeStreamingState) processFunctionCall(fc *GeminiFunctionCall, signature string) [][]byte {
+// startFunctionCall opens the tool_use block of a function call
+func (s *ClaudeStreamingState) startFunctionCall(fc *GeminiFunctionCall, signature string) [][]byte {
 	var chunks [][]byte
 
 	// Handle trailing signature first
@@ -510,11 +493,13 @@ func (s *ClaudeStreamingState) processFunctionCall(fc *GeminiFunctionCall, signa
 	if toolID == "" {
 		toolID = fmt.Sprintf("%s-%d", fc.Name, generateRandomID())
 	}
+	toolID = converter.GlobalToolCallStore().SanitizeID(s.sessionID, toolID)
+	converter.GlobalToolCallStore().Remember(s.sessionID, toolID, converter.RestoreToolName(fc.Name))
 
 	// [FIX] Cache tool_id -> signature mapping (like Antigravity-Manager)
 	// This allows future requests to recover the signature for this tool call
-	if signature != "" && len(signature) >= MinSignatureLength {
-		GlobalSignatureCache().CacheToolSignature(toolID, signature)
+	if thinking.HasValidSignature(signature) {
+		thinking.GlobalSignatureCache().CacheToolSignature(toolID, signature)
 	}
 
 	// Build tool_use content block
@@ -530,43 +515,96 @@ func (s *ClaudeStreamingState) processFunctionCall(fc *GeminiFunctionCall, signa
 	}
 
 	// Start tool_use block
-	chunks = append(chunks, s.startBlock(BlockTypeFunction, toolUse)...)
+	return append(chunks, s.startBlock(toolUse)...)
+}
 
-	// Emit input_json_delta with remapped arguments
-	if fc.Args != nil {
-		args := fc.Args
-		remapFunctionCallArgs(fc.Name, args)
-		argsJSON, _ := json.Marshal(args)
+// processStreamedFunctionCall forwards a function call whose args Gemini streams as partial
+// args: the tool_use block is opened on the first fragment and every fragment is sent as an
+// input_json_delta as soon as it arrives. The call ends with a fragment without willContinue.
+func (s *ClaudeStreamingState) processStreamedFunctionCall(fc *GeminiFunctionCall, signature string) [][]byte {
+	var chunks [][]byte
+
+	// A named call while another one is open starts a new call
+	if s.streamedCall != nil && fc.Name != "" && fc.Name != s.streamedCall.name {
+		chunks = append(chunks, s.finishStreamedCall(nil)...)
+	}
+
+	if s.streamedCall == nil {
+		chunks = append(chunks, s.startFunctionCall(fc, signature)...)
+		s.streamedCall = &streamedFunctionCall{
+			name:    fc.Name,
+			encoder: converter.NewPartialArgsEncoder(),
+			remap:   needsArgsRemap(fc.Name),
+		}
+	}
+
+	call := s.streamedCall
+	for _, arg := range fc.PartialArgs {
+		fragment := call.encoder.Write(arg)
+		if fragment == "" {
+			continue
+		}
+		call.written = true
+		if call.remap {
+			call.buffer.WriteString(fragment)
+			continue
+		}
 		chunks = append(chunks, s.emitDelta("input_json_delta", map[string]interface{}{
-			"partial_json": string(argsJSON),
+			"partial_json": fragment,
 		}))
 	}
 
-	// End tool block
-	chunks = append(chunks, s.endBlock()...)
-
+	if !fc.WillContinue {
+		chunks = append(chunks, s.finishStreamedCall(fc.Args)...)
+	}
 	return chunks
 }
 
+// finishStreamedCall completes the open streamed function call, if any. args are the complete
+// args sent with the final fragment, used when no partial args were streamed.
+func (s *ClaudeStreamingState) finishStreamedCall(args map[string]interface{}) [][]byte {
+	call := s.streamedCall
+	if call == nil {
+		return nil
+	}
+	s.streamedCall = nil
+
+	var argsJSON string
+	switch {
+	case !call.written && args != nil:
+		remapFunctionCallArgs(call.name, args)
+		data, _ := json.Marshal(args)
+		argsJSON = string(data)
+	case call.remap:
+		call.buffer.WriteString(call.encoder.Close())
+		var buffered map[string]interface{}
+		if err := json.Unmarshal([]byte(call.buffer.String()), &buffered); err != nil {
+			buffered = map[string]interface{}{}
+		}
+		remapFunctionCallArgs(call.name, buffered)
+		data, _ := json.Marshal(buffered)
+		argsJSON = string(data)
+	default:
+		argsJSON = call.encoder.Close()
+	}
+
+	chunks := [][]byte{s.emitDelta("input_json_delta", map[string]interface{}{
+		"partial_json": argsJSON,
+	})}
+	return append(chunks, s.endBlock()...)
+}
+
 // EmitForceStop ensures all termination events are sent
 // Called when stream ends (EOF or [DONE])
 func (s *ClaudeStreamingState) EmitForceStop() []byte {
-	if s.messageStopSent {
+	if s.sse.Stopped() {
 		return nil
 	}
 
 	var output []byte
-	finishChunks := s.emitFinish("", nil)
-	for _, c := range finishChunks {
+	for _, c := range s.emitFinish("", nil) {
 		output = append(output, c...)
 	}
-
-	// If emitFinish didn't send message_stop, send it now
-	if !s.messageStopSent {
-		output = append(output, []byte("event: message_stop\ndata: {\"type\":\"message_stop\"}\n\n")...)
-		s.messageStopSent = true
-	}
-
 	return output
 }
 
@@ -600,11 +638,16 @@ func (s *ClaudeStreamingState) ProcessGeminiSSELine(line string) []byte {
 		return s.handleParseError(dataStr, err)
 	}
 
+	return s.processChunk(&chunk)
+}
+
+// processChunk converts one parsed Gemini chunk to Claude SSE events
+func (s *ClaudeStreamingState) processChunk(chunk *GeminiStreamChunk) []byte {
 	var output []byte
 
 	// Send message_start on first chunk
-	if !s.messageStartSent {
-		if data := s.emitMessageStart(&chunk); data != nil {
+	if !s.sse.Started() {
+		if data := s.emitMessageStart(chunk); data != nil {
 			output = append(output, data...)
 		}
 	}
@@ -631,6 +674,17 @@ func (s *ClaudeStreamingState) ProcessGeminiSSELine(line string) []byte {
 			for _, c := range chunks {
 				output = append(output, c...)
 			}
+			if s.stopSequence != "" {
+				break
+			}
+		}
+
+		// The text reached a stop sequence: the rest of the response is dropped
+		if s.stopSequence != "" {
+			for _, c := range s.emitFinish("", chunk.UsageMetadata) {
+				output = append(output, c...)
+			}
+			return output
 		}
 
 		// Process grounding metadata (web search results) - like Antigravity-Manager
@@ -654,80 +708,41 @@ func (s *ClaudeStreamingState) ProcessGeminiSSELine(line string) []byte {
 func (s *ClaudeStreamingState) processPart(part *GeminiPart) [][]byte {
 	signature := part.ThoughtSignature
 
+	// Text held back for a possible stop sequence goes out before any other content
+	var chunks [][]byte
+	if part.FunctionCall != nil || part.Thought {
+		chunks = s.releaseStopHeld()
+	}
+
 	// 1. Handle function call
 	if part.FunctionCall != nil {
-		return s.processFunctionCall(part.FunctionCall, signature)
+		return append(chunks, s.processFunctionCall(part.FunctionCall, signature)...)
 	}
+	// Any other part means a streamed function call is over
+	chunks = append(chunks, s.finishStreamedCall(nil)...)
 
 	// 2. Handle text/thinking
 	if part.Text != "" || signature != "" {
 		if part.Thought {
-			return s.processThinking(part.Text, signature)
+			return append(chunks, s.processThinking(part.Text, signature)...)
 		}
-		return s.processText(part.Text, signature)
+		return append(chunks, s.processText(part.Text, signature)...)
 	}
 
-	// 3. Handle inline data (images)
-	if part.InlineData != nil && part.InlineData.Data != "" {
-		markdownImg := fmt.Sprintf("![image](data:%s;base64,%s)", part.InlineData.MimeType, part.InlineData.Data)
-		return s.processText(markdownImg, "")
+	// 3. Handle inline data (images): Claude streams have no image delta, so send a markdown
+	// image; collected responses and the next request turn it back into an image block.
+	// Thought images are drafts of the final image and are dropped.
+	if part.InlineData != nil && part.InlineData.Data != "" && !part.Thought {
+		markdownImg := converter.InlineImageMarkdown(part.InlineData.MimeType, part.InlineData.Data)
+		return append(chunks, s.processText(markdownImg, "")...)
 	}
 
-	return nil
+	return chunks
 }
 
 // captureGrounding stores grounding metadata during streaming, to be emitted at finish.
 func (s *ClaudeStreamingState) captureGrounding(grounding *GeminiGroundingMetadata) {
-	if grounding == nil {
-		return
-	}
-	if len(grounding.WebSearchQueries) > 0 {
-		s.webSearchQuery = grounding.WebSearchQueries[0]
-	}
-	if len(grounding.GroundingChunks) > 0 {
-		s.groundingChunks = grounding.GroundingChunks
-	}
-}
-
-// buildGroundingMarkdown builds grounding(web search) markdown text (same format as Antigravity-Manager).
-func (s *ClaudeStreamingState) buildGroundingMarkdown() string {
-	if s.webSearchQuery == "" && len(s.groundingChunks) == 0 {
-		return ""
-	}
-
-	var groundingText strings.Builder
-
-	// 1. Search query
-	if strings.TrimSpace(s.webSearchQuery) != "" {
-		groundingText.WriteString("\n\n---\n**🔍 已为您搜索：** ")
-		groundingText.WriteString(s.webSearchQuery)
-	}
-
-	// 2. Source links
-	if len(s.groundingChunks) > 0 {
-		links := make([]string, 0, len(s.groundingChunks))
-		for i, chunk := range s.groundingChunks {
-			if chunk.Web == nil {
-				continue
-			}
-			title := chunk.Web.Title
-			if title == "" {
-				title = "网页来源"
-			}
-			uri := chunk.Web.URI
-			if uri == "" {
-				uri = "#"
-			}
-			links = append(links, fmt.Sprintf("[%d] [%s](%s)", i+1, title, uri))
-		}
-
-		if len(links) > 0 {
-			groundingText.WriteString("\n\n**🌐 来源引文：**\n")
-			groundingText.WriteString(strings.Join(links, "\n"))
-		}
-	}
-
-	return groundingText.String()
+	s.grounding = converter.MergeGroundingMetadata(s.grounding, grounding)
 }
 
 // remapFunctionCallArgs remaps Gemini function call arguments to Claude Code expected format
@@ -794,6 +809,15 @@ func remapFunctionCallArgs(toolName string, args map[string]interface{}) {
 	}
 }
 
+// needsArgsRemap reports whether remapFunctionCallArgs may rewrite the args of a tool
+func needsArgsRemap(toolName string) bool {
+	switch strings.ToLower(toolName) {
+	case "grep", "glob", "read", "ls":
+		return true
+	}
+	return false
+}
+
 // extractFirstPath extracts the first path from various input formats
 func extractFirstPath(paths interface{}) string {
 	switch v := paths.(type) {
@@ -819,6 +843,18 @@ func generateRandomID() int64 {
 // handleParseError handles SSE parse errors gracefully (like Antigravity-Manager's handle_parse_error)
 // Attempts to recover partial data or emits a warning text block
 func (s *ClaudeStreamingState) handleParseError(dataStr string, err error) []byte {
+	// A chunk cut off inside a function call is repaired so the tool call still reaches the
+	// client with parsable input. The raw upstream stream stays in the attempt's response body.
+	if strings.Contains(dataStr, "\"functionCall\"") {
+		if repaired, ok := converter.RepairJSON(dataStr); ok {
+			var chunk GeminiStreamChunk
+			if json.Unmarshal([]byte(repaired), &chunk) == nil {
+				log.Printf("[Antigravity] Repaired truncated stream chunk with a function call: %v", err)
+				return s.processChunk(&chunk)
+			}
+		}
+	}
+
 	// Try to extract error message from the data if it's an error response
 	if strings.Contains(dataStr, "error") {
 		// Attempt to parse as error response
@@ -836,7 +872,7 @@ func (s *ClaudeStreamingState) handleParseError(dataStr string, err error) []byt
 
 			var output []byte
 			// Ensure message_start is sent
-			if !s.messageStartSent {
+			if !s.sse.Started() {
 				startData := s.emitMessageStart(&GeminiStreamChunk{})
 				if startData != nil {
 					output = append(output, startData...)
@@ -867,7 +903,7 @@ func (s *ClaudeStreamingState) handleParseError(dataStr string, err error) []byt
 				partialText = strings.ReplaceAll(partialText, "\\\"", "\"")
 
 				var output []byte
-				if !s.messageStartSent {
+				if !s.sse.Started() {
 					startData := s.emitMessageStart(&GeminiStreamChunk{})
 					if startData != nil {
 						output = append(output, startData...)
@@ -0,0 +1,96 @@
+package antigravity
+
+import (
+	"regexp"
+	"strings"
+)
+
+// imageAspectRatios are the aspect ratios gemini-3-pro-image accepts
+var imageAspectRatios = map[string]bool{
+	"1:1": true, "2:3": true, "3:2": true, "3:4": true, "4:3": true,
+	"4:5": true, "5:4": true, "9:16": true, "16:9": true, "21:9": true,
+}
+
+// aspectRatioPattern matches an aspect ratio given in the prompt: "--ar 16:9",
+// "--aspect 3:4" or "aspect ratio: 9:16"
+var aspectRatioPattern = regexp.MustCompile(`(?i)(?:--ar|--aspect(?:-ratio)?|aspect[ _-]ratio)[\s:=]*(\d{1,2})\s*[:x]\s*(\d{1,2})`)
+
+// aspectRatioFromModel returns the aspect ratio selected by a model name suffix
+// (e.g. "-16x9"), or "" if the name has none
+func aspectRatioFromModel(modelName string) string {
+	switch {
+	case strings.Contains(modelName, "-21x9") || strings.Contains(modelName, "-21-9"):
+		return "21:9"
+	case strings.Contains(modelName, "-16x9") || strings.Contains(modelName, "-16-9"):
+		return "16:9"
+	case strings.Contains(modelName, "-9x16") || strings.Contains(modelName, "-9-16"):
+		return "9:16"
+	case strings.Contains(modelName, "-4x3") || strings.Contains(modelName, "-4-3"):
+		return "4:3"
+	case strings.Contains(modelName, "-3x4") || strings.Contains(modelName, "-3-4"):
+		return "3:4"
+	case strings.Contains(modelName, "-1x1") || strings.Contains(modelName, "-1-1"):
+		return "1:1"
+	}
+	return ""
+}
+
+// aspectRatioFromPrompt returns a supported aspect ratio given in the prompt text, or ""
+func aspectRatioFromPrompt(text string) string {
+	matches := aspectRatioPattern.FindAllStringSubmatch(text, -1)
+	// The last one wins, so a later instruction overrides an earlier one
+	for i := len(matches) - 1; i >= 0; i-- {
+		if ratio := matches[i][1] + ":" + matches[i][2]; imageAspectRatios[ratio] {
+			return ratio
+		}
+	}
+	return ""
+}
+
+// applyRequestImageConfig lets the request choose what the model name left open: the
+// aspect ratio from the client's own generationConfig.imageConfig (Gemini clients) or
+// from the last user message (e.g. "--ar 16:9"), and the image size from the client's
+// imageConfig. Model name suffixes take precedence.
+func applyRequestImageConfig(imageConfig map[string]interface{}, originalModel string, innerRequest map[string]interface{}) {
+	var clientConfig map[string]interface{}
+	if genConfig, ok := innerRequest["generationConfig"].(map[string]interface{}); ok {
+		clientConfig, _ = genConfig["imageConfig"].(map[string]interface{})
+	}
+
+	if aspectRatioFromModel(originalModel) == "" {
+		if ratio, _ := clientConfig["aspectRatio"].(string); imageAspectRatios[ratio] {
+			imageConfig["aspectRatio"] = ratio
+		} else if ratio := aspectRatioFromPrompt(lastUserText(innerRequest)); ratio != "" {
+			imageConfig["aspectRatio"] = ratio
+		}
+	}
+	if _, ok := imageConfig["imageSize"]; !ok {
+		if size, _ := clientConfig["imageSize"].(string); size != "" {
+			imageConfig["imageSize"] = size
+		}
+	}
+}
+
+// lastUserText returns the text of the last user turn of a Gemini request
+func lastUserText(innerRequest map[string]interface{}) string {
+	contents, _ := innerRequest["contents"].([]interface{})
+	for i := len(contents) - 1; i >= 0; i-- {
+		content, ok := contents[i].(map[string]interface{})
+		if !ok || content["role"] != "user" {
+			continue
+		}
+		parts, _ := content["parts"].([]interface{})
+		var texts []string
+		for _, p := range parts {
+			if part, ok := p.(map[string]interface{}); ok {
+				if text, ok := part["text"].(string); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+		if len(texts) > 0 {
+			return strings.Join(texts, "\n")
+		}
+	}
+	return ""
+}
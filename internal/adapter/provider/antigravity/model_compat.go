@@ -0,0 +1,30 @@
+package antigravity
+
+import "strings"
+
+// IsModelCompatible checks if two models are compatible (same family)
+func IsModelCompatible(cached, target string) bool {
+	c := strings.ToLower(cached)
+	t := strings.ToLower(target)
+
+	if c == t {
+		return true
+	}
+
+	// Check specific families
+	if strings.Contains(c, "gemini-1.5") && strings.Contains(t, "gemini-1.5") {
+		return true
+	}
+	if strings.Contains(c, "gemini-2.0") && strings.Contains(t, "gemini-2.0") {
+		return true
+	}
+	if strings.Contains(c, "claude-3-5") && strings.Contains(t, "claude-3-5") {
+		return true
+	}
+	if strings.Contains(c, "claude-3-7") && strings.Contains(t, "claude-3-7") {
+		return true
+	}
+
+	// Fallback: strict match required
+	return false
+}
@@ -177,20 +177,8 @@ func isHaikuModel(model string) bool {
 // ParseImageConfig parses image configuration from model name suffixes
 // Returns imageConfig and cleanModelName
 func ParseImageConfig(modelName string) (map[string]interface{}, string) {
-	aspectRatio := "1:1"
-
-	switch {
-	case strings.Contains(modelName, "-21x9") || strings.Contains(modelName, "-21-9"):
-		aspectRatio = "21:9"
-	case strings.Contains(modelName, "-16x9") || strings.Contains(modelName, "-16-9"):
-		aspectRatio = "16:9"
-	case strings.Contains(modelName, "-9x16") || strings.Contains(modelName, "-9-16"):
-		aspectRatio = "9:16"
-	case strings.Contains(modelName, "-4x3") || strings.Contains(modelName, "-4-3"):
-		aspectRatio = "4:3"
-	case strings.Contains(modelName, "-3x4") || strings.Contains(modelName, "-3-4"):
-		aspectRatio = "3:4"
-	case strings.Contains(modelName, "-1x1") || strings.Contains(modelName, "-1-1"):
+	aspectRatio := aspectRatioFromModel(modelName)
+	if aspectRatio == "" {
 		aspectRatio = "1:1"
 	}
 
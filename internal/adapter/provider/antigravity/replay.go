@@ -0,0 +1,107 @@
+package antigravity
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/google/uuid"
+)
+
+// maxReplayBodySize bounds how much of a replayed response is returned
+const maxReplayBodySize = 4 << 20
+
+// ReplayResult is the outcome of replaying a recorded v1internal request on an account
+type ReplayResult struct {
+	ProviderID   uint64            `json:"providerID"`
+	ProviderName string            `json:"providerName"`
+	URL          string            `json:"url"`
+	RequestBody  string            `json:"requestBody"`
+	Status       int               `json:"status"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Body         string            `json:"body"`
+	Truncated    bool              `json:"truncated,omitempty"`
+	DurationMs   int64             `json:"durationMs"`
+	Error        string            `json:"error,omitempty"` // Connection error; Status is 0
+}
+
+// Replay sends a recorded v1internal request again with another account's credentials.
+// The request is kept as it was (request, model, sessionId, requestType) except for the
+// account's project and a fresh requestId, so comparing the outcomes tells whether a
+// failure is specific to the original account. It is not routed or recorded.
+func Replay(ctx context.Context, p *domain.Provider, upstreamURL string, recordedBody string) (*ReplayResult, error) {
+	if p.Type != "antigravity" || p.Config == nil || p.Config.Antigravity == nil {
+		return nil, fmt.Errorf("%w: not an Antigravity provider", domain.ErrInvalidInput)
+	}
+	if !strings.HasPrefix(upstreamURL, V1InternalBaseURLProd+":") && !strings.HasPrefix(upstreamURL, V1InternalBaseURLDaily+":") {
+		return nil, fmt.Errorf("%w: recorded URL is not a v1internal endpoint: %s", domain.ErrInvalidInput, upstreamURL)
+	}
+
+	var wrapped map[string]interface{}
+	if err := json.Unmarshal([]byte(recordedBody), &wrapped); err != nil {
+		return nil, fmt.Errorf("%w: recorded request body is not available or not a v1internal request", domain.ErrInvalidInput)
+	}
+	if _, ok := wrapped["request"]; !ok {
+		return nil, fmt.Errorf("%w: recorded request body is not a v1internal request", domain.ErrInvalidInput)
+	}
+	config := p.Config.Antigravity
+	accessToken, err := GetSharedAccessToken(ctx, config.RefreshToken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
+	// The recorded project belongs to the original account, so the target's own project is
+	// always used, looked up if the provider has none configured
+	projectID := config.ProjectID
+	if projectID == "" {
+		if projectID, _, err = fetchProjectInfo(ctx, accessToken, config.Email); err != nil {
+			return nil, fmt.Errorf("failed to resolve the project of %s: %w", p.Name, err)
+		}
+	}
+	wrapped["project"] = projectID
+	wrapped["requestId"] = fmt.Sprintf("agent-%s", uuid.New().String())
+	body, err := json.Marshal(wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, upstreamURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("User-Agent", AntigravityUserAgent)
+
+	result := &ReplayResult{
+		ProviderID:   p.ID,
+		ProviderName: p.Name,
+		URL:          upstreamURL,
+		RequestBody:  string(body),
+	}
+	start := time.Now()
+	resp, err := newUpstreamHTTPClient(p).Do(req)
+	if err != nil {
+		result.DurationMs = time.Since(start).Milliseconds()
+		result.Error = err.Error()
+		return result, nil
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxReplayBodySize+1))
+	result.DurationMs = time.Since(start).Milliseconds()
+	if len(respBody) > maxReplayBodySize {
+		respBody = respBody[:maxReplayBodySize]
+		result.Truncated = true
+	}
+	result.Status = resp.StatusCode
+	result.Headers = flattenHeaders(resp.Header)
+	result.Body = string(respBody)
+	return result, nil
+}
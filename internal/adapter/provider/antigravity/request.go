@@ -195,6 +195,8 @@ func wrapV1InternalRequest(body []byte, projectID, originalModel, mappedModel, s
 
 	// Handle imageConfig for image generation models (like Antigravity-Manager)
 	if config.ImageConfig != nil {
+		// 0. Take the aspect ratio and size the request asks for, where the model name left them open
+		applyRequestImageConfig(config.ImageConfig, originalModel, innerRequest)
 		// 1. Remove tools (image generation does not support tools)
 		delete(innerRequest, "tools")
 		// 2. Remove systemInstruction (image generation does not support system prompts)
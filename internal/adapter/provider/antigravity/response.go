@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+
+	"github.com/awsl-project/maxx/internal/converter"
 )
 
 // Response headers to exclude when copying
@@ -72,6 +74,20 @@ func unwrapV1InternalSSEChunk(line []byte) []byte {
 	return []byte(lineStr + "\n\n")
 }
 
+// sseChunkHasOutput reports whether an unwrapped SSE chunk carries content for the client
+// (see converter.GeminiHasOutput). Empty lines and non-data lines carry none.
+func sseChunkHasOutput(chunk []byte) bool {
+	lineStr := strings.TrimSpace(string(chunk))
+	if !strings.HasPrefix(lineStr, "data: ") {
+		return false
+	}
+	payload := strings.TrimSpace(strings.TrimPrefix(lineStr, "data: "))
+	if payload == "" || payload == "[DONE]" {
+		return false
+	}
+	return converter.GeminiHasOutput([]byte(payload))
+}
+
 // copyResponseHeaders copies response headers from upstream, excluding certain headers
 func copyResponseHeaders(dst, src http.Header) {
 	if src == nil {
@@ -115,8 +131,9 @@ func isRetryableStatusCode(code int) bool {
 }
 
 // convertGeminiToClaudeResponse converts a non-streaming Gemini response to Claude format
-// (like Antigravity-Manager's response conversion)
-func convertGeminiToClaudeResponse(geminiBody []byte, requestModel string) ([]byte, error) {
+// (like Antigravity-Manager's response conversion). stopSequences are those of the client
+// request, reported as the stop_sequence the text reached.
+func convertGeminiToClaudeResponse(geminiBody []byte, requestModel, sessionID string, stopSequences []string) ([]byte, error) {
 	var geminiResp struct {
 		Candidates []struct {
 			Content struct {
@@ -149,6 +166,7 @@ func convertGeminiToClaudeResponse(geminiBody []byte, requestModel string) ([]by
 	thinkingSignature := ""
 	trailingSignature := ""
 	hasToolUse := false
+	var grounding *GeminiGroundingMetadata
 
 	flushText := func() {
 		if textBuilder.Len() == 0 {
@@ -204,6 +222,8 @@ func convertGeminiToClaudeResponse(geminiBody []byte, requestModel string) ([]by
 				if toolID == "" {
 					toolID = fmt.Sprintf("%s-%d", part.FunctionCall.Name, generateRandomID())
 				}
+				toolID = converter.GlobalToolCallStore().SanitizeID(sessionID, toolID)
+				converter.GlobalToolCallStore().Remember(sessionID, toolID, converter.RestoreToolName(part.FunctionCall.Name))
 
 				args := part.FunctionCall.Args
 				remapFunctionCallArgs(part.FunctionCall.Name, args)
@@ -275,28 +295,27 @@ func convertGeminiToClaudeResponse(geminiBody []byte, requestModel string) ([]by
 				}
 			}
 
-			// 3) Inline data (images)
-			if part.InlineData != nil && part.InlineData.Data != "" {
-				flushThinking()
-				markdownImg := fmt.Sprintf("![image](data:%s;base64,%s)", part.InlineData.MimeType, part.InlineData.Data)
-				textBuilder.WriteString(markdownImg)
-				flushText()
-			}
-		}
-
-		// Grounding (web search)
-		if candidate.GroundingMetadata != nil {
-			if groundingText := buildGroundingText(candidate.GroundingMetadata); groundingText != "" {
+			// 3) Inline data (images) -> image block; thought images are drafts and dropped
+			if part.InlineData != nil && part.InlineData.Data != "" && !part.Thought {
 				flushThinking()
 				flushText()
-				textBuilder.WriteString(groundingText)
-				flushText()
+				contentBlocks = append(contentBlocks, map[string]interface{}{
+					"type": "image",
+					"source": map[string]interface{}{
+						"type":       "base64",
+						"media_type": part.InlineData.MimeType,
+						"data":       part.InlineData.Data,
+					},
+				})
 			}
 		}
 
 		flushThinking()
 		flushText()
 
+		// Grounding (web search)
+		grounding = candidate.GroundingMetadata
+
 		// Trailing signature at end of response
 		if trailingSignature != "" {
 			contentBlocks = append(contentBlocks, map[string]interface{}{
@@ -308,11 +327,23 @@ func convertGeminiToClaudeResponse(geminiBody []byte, requestModel string) ([]by
 		}
 	}
 
-	stopReason := "end_turn"
-	if hasToolUse {
-		stopReason = "tool_use"
-	} else if len(geminiResp.Candidates) > 0 && geminiResp.Candidates[0].FinishReason == "MAX_TOKENS" {
-		stopReason = "max_tokens"
+	finishReason := ""
+	if len(geminiResp.Candidates) > 0 {
+		finishReason = geminiResp.Candidates[0].FinishReason
+	}
+	stopReason := converter.ClaudeStopReasonForGemini(finishReason, hasToolUse)
+	var stopSequence interface{}
+	if !hasToolUse {
+		var seq string
+		if contentBlocks, seq = cutAtStopSequence(contentBlocks, stopSequences); seq != "" {
+			stopReason = "stop_sequence"
+			stopSequence = seq
+		}
+	}
+	if len(contentBlocks) == 0 {
+		if notice := converter.GeminiFinishNotice(finishReason); notice != "" {
+			contentBlocks = append(contentBlocks, map[string]interface{}{"type": "text", "text": notice})
+		}
 	}
 
 	// Usage (like Antigravity-Manager's to_claude_usage)
@@ -334,57 +365,92 @@ func convertGeminiToClaudeResponse(geminiBody []byte, requestModel string) ([]by
 		usage["cache_creation_input_tokens"] = 0
 	}
 
+	if grounding.HasWebSearch() {
+		contentBlocks = applyGrounding(contentBlocks, grounding)
+		usage["server_tool_use"] = map[string]interface{}{"web_search_requests": 1}
+	}
+
 	respID := geminiResp.ResponseID
 	if respID == "" {
 		respID = fmt.Sprintf("msg_%d", generateRandomID())
 	}
 
 	claudeResp := map[string]interface{}{
-		"id":          respID,
-		"type":        "message",
-		"role":        "assistant",
-		"model":       geminiResp.ModelVersion,
-		"content":     contentBlocks,
-		"stop_reason": stopReason,
-		"usage":       usage,
+		"id":            respID,
+		"type":          "message",
+		"role":          "assistant",
+		"model":         geminiResp.ModelVersion,
+		"content":       contentBlocks,
+		"stop_reason":   stopReason,
+		"stop_sequence": stopSequence,
+		"usage":         usage,
 	}
 
 	return json.Marshal(claudeResp)
 }
 
-// buildGroundingText converts grounding metadata into a markdown text snippet
-func buildGroundingText(grounding *GeminiGroundingMetadata) string {
-	if grounding == nil {
-		return ""
+// cutAtStopSequence truncates the content at the earliest stop sequence in its text
+// blocks, dropping the blocks after it. It returns the sequence, or "" if none occurs.
+func cutAtStopSequence(contentBlocks []map[string]interface{}, sequences []string) ([]map[string]interface{}, string) {
+	if len(sequences) == 0 {
+		return contentBlocks, ""
 	}
-
-	var b strings.Builder
-
-	if len(grounding.WebSearchQueries) > 0 {
-		b.WriteString("\n\n---\n**🔍 已为您搜索：** ")
-		b.WriteString(strings.Join(grounding.WebSearchQueries, ", "))
+	for i, block := range contentBlocks {
+		if block["type"] != "text" {
+			continue
+		}
+		text, _ := block["text"].(string)
+		if idx, seq := converter.FindStopSequence(text, sequences); idx >= 0 {
+			if idx == 0 {
+				return contentBlocks[:i], seq
+			}
+			block["text"] = text[:idx]
+			return contentBlocks[:i+1], seq
+		}
 	}
+	return contentBlocks, ""
+}
 
-	if len(grounding.GroundingChunks) > 0 {
-		var links []string
-		for i, chunk := range grounding.GroundingChunks {
-			if chunk.Web != nil {
-				title := chunk.Web.Title
-				if title == "" {
-					title = "网页来源"
-				}
-				uri := chunk.Web.URI
-				if uri == "" {
-					uri = "#"
-				}
-				links = append(links, fmt.Sprintf("[%d] [%s](%s)", i+1, title, uri))
+// applyGrounding presents grounding like Anthropic's native web search: server_tool_use and
+// web_search_tool_result blocks in front of the content, and citations on the text blocks
+// containing the cited text
+func applyGrounding(contentBlocks []map[string]interface{}, grounding *GeminiGroundingMetadata) []map[string]interface{} {
+	lastText := -1
+	for i, block := range contentBlocks {
+		if block["type"] == "text" {
+			lastText = i
+		}
+	}
+	for _, citation := range converter.GroundingCitations(grounding) {
+		target := lastText
+		for i, block := range contentBlocks {
+			if text, ok := block["text"].(string); ok && block["type"] == "text" && strings.Contains(text, citation.CitedText) {
+				target = i
+				break
 			}
 		}
-		if len(links) > 0 {
-			b.WriteString("\n\n**🌐 来源引文：**\n")
-			b.WriteString(strings.Join(links, "\n"))
+		if target >= 0 {
+			citations, _ := contentBlocks[target]["citations"].([]converter.ClaudeCitation)
+			contentBlocks[target]["citations"] = append(citations, citation)
 		}
 	}
 
-	return b.String()
+	result := make([]map[string]interface{}, 0, len(contentBlocks)+2)
+	for _, block := range converter.WebSearchBlocks(grounding) {
+		if block.Type == "server_tool_use" {
+			result = append(result, map[string]interface{}{
+				"type":  block.Type,
+				"id":    block.ID,
+				"name":  block.Name,
+				"input": block.Input,
+			})
+			continue
+		}
+		result = append(result, map[string]interface{}{
+			"type":        block.Type,
+			"tool_use_id": block.ToolUseID,
+			"content":     block.Content,
+		})
+	}
+	return append(result, contentBlocks...)
 }
@@ -116,8 +116,8 @@ func ValidateRefreshToken(ctx context.Context, refreshToken string) (*TokenValid
 
 // FetchQuotaForProvider 为现有 provider 获取配额信息
 func FetchQuotaForProvider(ctx context.Context, refreshToken, projectID string) (*QuotaData, error) {
-	// 获取 access token
-	accessToken, _, err := refreshGoogleToken(ctx, refreshToken)
+	// 获取 access token（与同账号的 Provider 共享）
+	accessToken, err := GetSharedAccessToken(ctx, refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("token refresh failed: %w", err)
 	}
@@ -154,8 +154,7 @@ func fetchUserInfo(ctx context.Context, accessToken string) (*UserInfo, error) {
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient().Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -195,8 +194,7 @@ func fetchProjectInfo(ctx context.Context, accessToken, email string) (projectID
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("User-Agent", UserAgentLoadCodeAssist)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient().Do(req)
 	if err != nil {
 		return "", "", err
 	}
@@ -255,8 +253,7 @@ func fetchProjectInfoByID(ctx context.Context, accessToken, projectID string) (s
 	// loadCodeAssist 使用不带版本号的 User-Agent
 	req.Header.Set("User-Agent", UserAgentLoadCodeAssist)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient().Do(req)
 	if err != nil {
 		return projectID, "FREE", err
 	}
@@ -310,8 +307,7 @@ func fetchQuota(ctx context.Context, accessToken, projectID string) (*QuotaData,
 	// fetchAvailableModels 使用带版本号的 User-Agent
 	req.Header.Set("User-Agent", UserAgentFetchModels)
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient().Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -473,8 +469,7 @@ func ExchangeCodeForTokens(ctx context.Context, code, redirectURI string) (strin
 	}
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient().Do(req)
 	if err != nil {
 		return "", "", 0, fmt.Errorf("token exchange request failed: %w", err)
 	}
@@ -2,27 +2,9 @@ package antigravity
 
 import (
 	"encoding/json"
-	"strings"
-)
-
-// shouldEnableThinkingByDefault checks if thinking should be enabled by default for a model
-// Reference: Antigravity-Manager's should_enable_thinking_by_default (line 380-398)
-// Claude Code v2.0.67+ enables thinking by default for Opus 4.5 models
-func shouldEnableThinkingByDefault(model string) bool {
-	modelLower := strings.ToLower(model)
-
-	// Enable thinking by default for Opus 4.5 variants
-	if strings.Contains(modelLower, "opus-4-5") || strings.Contains(modelLower, "opus-4.5") {
-		return true
-	}
 
-	// Also enable for explicit thinking model variants
-	if strings.Contains(modelLower, "-thinking") {
-		return true
-	}
-
-	return false
-}
+	"github.com/awsl-project/maxx/internal/thinking"
+)
 
 // HasThinkingEnabledWithModel checks if thinking is enabled, considering model defaults
 // Claude Code v2.0.67+ enables thinking by default for Opus 4.5 models
@@ -32,29 +14,8 @@ func HasThinkingEnabledWithModel(requestBody []byte, model string) bool {
 		return true
 	}
 
-	// Check model defaults using shouldEnableThinkingByDefault
-	return shouldEnableThinkingByDefault(model)
-}
-
-// TargetModelSupportsThinking checks if the target model supports thinking mode
-// (like Antigravity-Manager's target_model_supports_thinking)
-func TargetModelSupportsThinking(mappedModel string) bool {
-	modelLower := strings.ToLower(mappedModel)
-
-	// Models with "-thinking" suffix support thinking
-	if strings.Contains(modelLower, "-thinking") {
-		return true
-	}
-
-	// Claude models support thinking
-	if strings.HasPrefix(modelLower, "claude-") {
-		return true
-	}
-
-	// Regular Gemini 2.5/3 models do NOT support thinking without -thinking suffix
-	// Reference: Antigravity-Manager is more conservative (line 183-184)
-	// Only models with explicit "-thinking" suffix are considered compatible
-	return false
+	// Check model defaults
+	return thinking.EnabledByDefault(model)
 }
 
 // ShouldDisableThinkingDueToHistory checks if thinking should be disabled
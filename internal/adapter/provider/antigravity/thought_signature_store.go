@@ -1,6 +1,11 @@
 package antigravity
 
-import "sync"
+import (
+	"sync"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/thinking"
+)
 
 // Global thought_signature storage (like Antigravity-Manager's signature_store.rs).
 // Used as a last-resort fallback when clients strip thoughtSignature in tool loops.
@@ -36,3 +41,47 @@ func ClearThoughtSignature() {
 	defer globalThoughtSignatureStore.mu.Unlock()
 	globalThoughtSignatureStore.sig = ""
 }
+
+// skipThoughtSignatureValidator is the sentinel signature that tells Gemini to skip
+// thought signature validation for a function call
+const skipThoughtSignatureValidator = "skip_thought_signature_validator"
+
+// resolveThoughtSignatureMode returns the thought signature mode for a request: the
+// route's mode if set, else the provider's, else auto
+func resolveThoughtSignatureMode(routeMode domain.ThoughtSignatureMode, config *domain.ProviderConfigAntigravity) domain.ThoughtSignatureMode {
+	if routeMode != domain.ThoughtSignatureUnset {
+		return routeMode
+	}
+	if config != nil && config.ThoughtSignatureMode != domain.ThoughtSignatureUnset {
+		return config.ThoughtSignatureMode
+	}
+	return domain.ThoughtSignatureAuto
+}
+
+// applySkipThoughtSignatures gives every function call in model turns without a valid
+// signature the skip sentinel (always_skip mode); valid signatures are kept
+func applySkipThoughtSignatures(contents []interface{}) bool {
+	modified := false
+	for _, content := range contents {
+		contentMap, ok := content.(map[string]interface{})
+		if !ok || contentMap["role"] != "model" {
+			continue
+		}
+		parts, _ := contentMap["parts"].([]interface{})
+		for _, part := range parts {
+			partMap, ok := part.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if _, hasFc := partMap["functionCall"]; !hasFc {
+				continue
+			}
+			if sig, _ := partMap["thoughtSignature"].(string); sig == skipThoughtSignatureValidator || thinking.HasValidSignature(sig) {
+				continue
+			}
+			partMap["thoughtSignature"] = skipThoughtSignatureValidator
+			modified = true
+		}
+	}
+	return modified
+}
@@ -0,0 +1,139 @@
+package antigravity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// tokenExpiryBuffer refreshes access tokens slightly before Google expires them
+const tokenExpiryBuffer = 60 * time.Second
+
+// TokenStore persists access tokens shared by providers with the same refresh token
+type TokenStore interface {
+	Get(tokenKey string) (*domain.OAuthAccessToken, error)
+	Upsert(token *domain.OAuthAccessToken) error
+	Delete(tokenKey string) error
+}
+
+// sharedToken is the access token for one refresh token.
+// mu is held while refreshing, so concurrent callers wait for a single refresh.
+type sharedToken struct {
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+	loaded      bool // the persistent store has been consulted
+}
+
+func (t *sharedToken) valid() bool {
+	return t.accessToken != "" && time.Now().Before(t.expiresAt)
+}
+
+// Shared access tokens, keyed by refresh token hash.
+// Multiple providers using one Google account refresh once instead of each on their own,
+// which could otherwise get the refresh token invalidated.
+var sharedTokens struct {
+	mu      sync.Mutex
+	entries map[string]*sharedToken
+	store   TokenStore
+}
+
+// SetTokenStore sets the store used to persist shared access tokens across restarts.
+// This should be called during application initialization.
+func SetTokenStore(store TokenStore) {
+	sharedTokens.mu.Lock()
+	defer sharedTokens.mu.Unlock()
+	sharedTokens.store = store
+}
+
+func tokenKey(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+	return hex.EncodeToString(sum[:])
+}
+
+func sharedTokenEntry(key string) (*sharedToken, TokenStore) {
+	sharedTokens.mu.Lock()
+	defer sharedTokens.mu.Unlock()
+	if sharedTokens.entries == nil {
+		sharedTokens.entries = make(map[string]*sharedToken)
+	}
+	entry, ok := sharedTokens.entries[key]
+	if !ok {
+		entry = &sharedToken{}
+		sharedTokens.entries[key] = entry
+	}
+	return entry, sharedTokens.store
+}
+
+// GetSharedAccessToken returns a valid access token for refreshToken.
+// The token is shared by every caller with the same refresh token and refreshed at most once at a time.
+func GetSharedAccessToken(ctx context.Context, refreshToken string) (string, error) {
+	key := tokenKey(refreshToken)
+	entry, store := sharedTokenEntry(key)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.valid() {
+		return entry.accessToken, nil
+	}
+
+	// Reuse a token persisted by a previous run
+	if !entry.loaded && store != nil {
+		entry.loaded = true
+		if saved, err := store.Get(key); err != nil {
+			log.Printf("[Antigravity] Failed to load cached access token: %v", err)
+		} else if saved != nil {
+			entry.accessToken = saved.AccessToken
+			entry.expiresAt = saved.ExpiresAt
+			if entry.valid() {
+				return entry.accessToken, nil
+			}
+		}
+	}
+
+	accessToken, expiresIn, err := refreshGoogleToken(ctx, refreshToken)
+	if err != nil {
+		return "", err
+	}
+	entry.accessToken = accessToken
+	entry.expiresAt = time.Now().Add(time.Duration(expiresIn)*time.Second - tokenExpiryBuffer)
+
+	if store != nil {
+		if err := store.Upsert(&domain.OAuthAccessToken{
+			TokenKey:    key,
+			AccessToken: entry.accessToken,
+			ExpiresAt:   entry.expiresAt,
+		}); err != nil {
+			log.Printf("[Antigravity] Failed to persist access token: %v", err)
+		}
+	}
+	return accessToken, nil
+}
+
+// InvalidateSharedAccessToken drops accessToken after upstream rejected it.
+// If another caller has already replaced it with a fresh token, nothing happens.
+func InvalidateSharedAccessToken(refreshToken, accessToken string) {
+	key := tokenKey(refreshToken)
+	entry, store := sharedTokenEntry(key)
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.accessToken != accessToken {
+		return
+	}
+	entry.accessToken = ""
+	entry.expiresAt = time.Time{}
+	entry.loaded = true // the persisted copy is the same rejected token
+	if store != nil {
+		if err := store.Delete(key); err != nil {
+			log.Printf("[Antigravity] Failed to delete cached access token: %v", err)
+		}
+	}
+}
@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"log"
 	"strings"
+
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/thinking"
 )
 
 // buildContents converts Claude messages to Gemini contents
@@ -12,7 +16,8 @@ func buildContents(
 	messages []ClaudeMessage,
 	mappedModel string,
 	sessionID string,
-	signatureCache *SignatureCache,
+	signatureCache *thinking.SignatureCache,
+	signatureMode domain.ThoughtSignatureMode,
 ) ([]map[string]interface{}, error) {
 	contents := []map[string]interface{}{}
 
@@ -22,12 +27,16 @@ func buildContents(
 
 	for _, msg := range messages {
 		parts := []map[string]interface{}{}
+		// Images returned by tools, sent after the function responses of the turn
+		var toolResultMedia []map[string]interface{}
 
 		// String style content: trim and ignore "(no content)" (matches Antigravity-Manager)
 		if text, ok := msg.Content.(string); ok {
 			if text != "(no content)" {
 				trimmed := strings.TrimSpace(text)
-				if trimmed != "" {
+				if imageParts := inlineImageParts(trimmed); msg.Role == "assistant" && imageParts != nil {
+					parts = append(parts, imageParts...)
+				} else if trimmed != "" {
 					parts = append(parts, map[string]interface{}{"text": trimmed})
 				}
 			}
@@ -54,18 +63,34 @@ func buildContents(
 					if block.Text == "(no content)" {
 						continue
 					}
+					if msg.Role == "assistant" {
+						// Images of earlier answers, streamed as markdown
+						if imageParts := inlineImageParts(block.Text); imageParts != nil {
+							parts = append(parts, imageParts...)
+							continue
+						}
+					}
 					parts = append(parts, map[string]interface{}{
 						"text": block.Text,
 					})
 
 				case "tool_use":
-					part := processToolUseBlock(block, lastThoughtSignature, signatureCache)
+					part := processToolUseBlock(block, sessionID, lastThoughtSignature, signatureCache, signatureMode)
 					parts = append(parts, part)
 					toolIDToName[block.ID] = block.Name
+					converter.GlobalToolCallStore().Remember(sessionID, block.ID, block.Name)
 
 				case "tool_result":
-					part := processToolResultBlock(block, toolIDToName, lastThoughtSignature)
+					part := processToolResultBlock(block, sessionID, toolIDToName, lastThoughtSignature)
 					parts = append(parts, part)
+					for _, source := range converter.ToolResultMedia(block.Content) {
+						toolResultMedia = append(toolResultMedia, map[string]interface{}{
+							"inlineData": map[string]interface{}{
+								"mimeType": source.MediaType,
+								"data":     source.Data,
+							},
+						})
+					}
 
 				case "image":
 					if part := processInlineDataBlock(block); part != nil {
@@ -78,6 +103,7 @@ func buildContents(
 				}
 			}
 		}
+		parts = append(parts, toolResultMedia...)
 
 		// Build content
 		if len(parts) == 0 {
@@ -103,7 +129,7 @@ func processThinkingBlock(
 	parts *[]map[string]interface{},
 	mappedModel string,
 	lastThoughtSignature string,
-	signatureCache *SignatureCache,
+	signatureCache *thinking.SignatureCache,
 ) map[string]interface{} {
 	// 1. Position check: must be first block
 	if len(*parts) > 0 {
@@ -143,7 +169,7 @@ func processThinkingBlock(
 	}
 
 	// Valid signature (thinking threshold)
-	if hasValidThinkingSignature(block.Thinking, signature) {
+	if thinking.HasValidBlockSignature(block.Thinking, signature) {
 		part["thoughtSignature"] = signature
 	}
 
@@ -154,8 +180,10 @@ func processThinkingBlock(
 // Reference: Antigravity-Manager's ToolUse processing
 func processToolUseBlock(
 	block ContentBlock,
+	sessionID string,
 	lastThoughtSignature string,
-	signatureCache *SignatureCache,
+	signatureCache *thinking.SignatureCache,
+	signatureMode domain.ThoughtSignatureMode,
 ) map[string]interface{} {
 	// Clean args to remove JSON Schema fields that Gemini doesn't support
 	// Reference: Antigravity-Manager's clean_json_schema call after building functionCall
@@ -168,9 +196,9 @@ func processToolUseBlock(
 
 	part := map[string]interface{}{
 		"functionCall": map[string]interface{}{
-			"name": block.Name,
+			"name": converter.SanitizeToolName(block.Name),
 			"args": cleanedArgs,
-			"id":   block.ID,
+			"id":   converter.GlobalToolCallStore().OriginalID(sessionID, block.ID),
 		},
 	}
 
@@ -178,7 +206,8 @@ func processToolUseBlock(
 	// 1. Client-provided signature
 	// 2. Context signature (last_thought_signature)
 	// 3. Cached signature (from previous tool calls)
-	// 4. Global fallback signature (from cache)
+	// 4. Global fallback signature (from cache), not in strict mode: it may belong to
+	//    another conversation
 	// Reference: Antigravity-Manager's multi-layer signature recovery
 	signature := block.Signature
 	if signature == "" && lastThoughtSignature != "" {
@@ -187,7 +216,7 @@ func processToolUseBlock(
 	if signature == "" && signatureCache != nil {
 		signature = signatureCache.GetToolSignature(block.ID)
 	}
-	if signature == "" {
+	if signature == "" && signatureMode != domain.ThoughtSignatureStrict {
 		// Final fallback: global signature store (best-effort)
 		signature = GetThoughtSignature()
 	}
@@ -203,6 +232,7 @@ func processToolUseBlock(
 // Reference: Antigravity-Manager's ToolResult processing
 func processToolResultBlock(
 	block ContentBlock,
+	sessionID string,
 	toolIDToName map[string]string,
 	lastThoughtSignature string,
 ) map[string]interface{} {
@@ -211,26 +241,31 @@ func processToolResultBlock(
 
 	// 2. Empty result injection
 	if strings.TrimSpace(mergedContent) == "" {
-		if block.IsError != nil && *block.IsError {
+		if len(converter.ToolResultMedia(block.Content)) > 0 {
+			mergedContent = converter.ToolResultMediaNote
+		} else if block.IsError != nil && *block.IsError {
 			mergedContent = "Tool execution failed with no output."
 		} else {
 			mergedContent = "Command executed successfully."
 		}
 	}
 
-	// 3. Get tool name
+	// 3. Get tool name, from the session's earlier requests if the history was truncated
 	toolName := toolIDToName[block.ToolUseID]
+	if toolName == "" {
+		toolName = converter.GlobalToolCallStore().Name(sessionID, block.ToolUseID)
+	}
 	if toolName == "" {
 		toolName = block.ToolUseID
 	}
 
 	part := map[string]interface{}{
 		"functionResponse": map[string]interface{}{
-			"name": toolName,
+			"name": converter.SanitizeToolName(toolName),
 			"response": map[string]interface{}{
 				"result": mergedContent,
 			},
-			"id": block.ToolUseID,
+			"id": converter.GlobalToolCallStore().OriginalID(sessionID, block.ToolUseID),
 		},
 	}
 
@@ -256,6 +291,29 @@ func processInlineDataBlock(block ContentBlock) map[string]interface{} {
 	}
 }
 
+// inlineImageParts splits text with markdown data URI images into text and inlineData parts.
+// It returns nil if the text has no such image.
+func inlineImageParts(text string) []map[string]interface{} {
+	blocks := converter.SplitInlineImages(text)
+	if blocks == nil {
+		return nil
+	}
+	parts := make([]map[string]interface{}, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Source != nil {
+			parts = append(parts, map[string]interface{}{
+				"inlineData": map[string]interface{}{
+					"mimeType": block.Source.MediaType,
+					"data":     block.Source.Data,
+				},
+			})
+		} else if strings.TrimSpace(block.Text) != "" {
+			parts = append(parts, map[string]interface{}{"text": block.Text})
+		}
+	}
+	return parts
+}
+
 // extractToolResultContent extracts text content from tool_result
 func extractToolResultContent(content interface{}) string {
 	switch c := content.(type) {
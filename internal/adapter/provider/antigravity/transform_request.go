@@ -5,16 +5,21 @@ import (
 	"fmt"
 	"log"
 	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/thinking"
 )
 
 // TransformClaudeToGemini converts a Claude API request to Gemini v1internal format
 // Reference: Antigravity-Manager's transform_claude_request_in
 func TransformClaudeToGemini(
 	claudeReqBody []byte,
+	providerID uint64,
 	mappedModel string,
 	stream bool,
 	sessionID string,
-	signatureCache *SignatureCache,
+	signatureCache *thinking.SignatureCache,
+	signatureMode domain.ThoughtSignatureMode,
 ) (geminiReqBody []byte, effectiveMappedModel string, hasThinking bool, err error) {
 	effectiveMappedModel = mappedModel
 
@@ -38,17 +43,17 @@ func TransformClaudeToGemini(
 	}
 
 	// 4. Thinking block pre-filtering
-	filterInvalidThinkingBlocks(&claudeReq.Messages)
+	thinking.FilterInvalidBlocks(claudeReq.Messages)
 
 	// 5. Tool loop recovery
 	closeToolLoopForThinking(&claudeReq.Messages)
 
 	// 6. Remove trailing unsigned thinking blocks (like Antigravity-Manager)
-	removeTrailingUnsignedThinking(&claudeReq.Messages)
+	thinking.RemoveTrailingUnsigned(claudeReq.Messages)
 
 	// 7. Calculate final thinking mode state (before building request)
 	// Reference: Antigravity-Manager's thinking mode resolution (line 170-251)
-	hasThinking = calculateFinalThinkingState(&claudeReq, mappedModel, signatureCache)
+	hasThinking = calculateFinalThinkingState(&claudeReq, providerID, mappedModel, signatureCache)
 
 	// 8. Build Gemini request
 	geminiReq := make(map[string]interface{})
@@ -59,7 +64,7 @@ func TransformClaudeToGemini(
 	}
 
 	// 7.2 Message contents
-	contents, err := buildContents(claudeReq.Messages, mappedModel, sessionID, signatureCache)
+	contents, err := buildContents(claudeReq.Messages, mappedModel, sessionID, signatureCache, signatureMode)
 	if err != nil {
 		return nil, effectiveMappedModel, hasThinking, fmt.Errorf("failed to build contents: %w", err)
 	}
@@ -109,10 +114,7 @@ type ClaudeRequest struct {
 }
 
 // ClaudeMessage represents a message in Claude format
-type ClaudeMessage struct {
-	Role    string      `json:"role"`
-	Content interface{} `json:"content"` // string or []ContentBlock
-}
+type ClaudeMessage = thinking.Message
 
 // ContentBlock represents a content block in Claude format
 type ContentBlock struct {
@@ -200,99 +202,6 @@ func cleanCacheControlFromRequest(claudeReq *ClaudeRequest) {
 	}
 }
 
-func hasValidThinkingSignature(thinkingText, signature string) bool {
-	// Empty thinking + any signature = valid (trailing signature case)
-	if thinkingText == "" && signature != "" {
-		return true
-	}
-	// Non-empty thinking must have a "long enough" signature
-	return signature != "" && len(signature) >= MinThinkingSignatureLength
-}
-
-// filterInvalidThinkingBlocks filters invalid thinking blocks from message history.
-// Mirrors Antigravity-Manager's `filter_invalid_thinking_blocks` behavior:
-// - Only touches assistant/model roles
-// - Invalid thinking blocks are converted to text (preserve content) or dropped if empty
-// - Ensures message content is not empty (injects an empty text block)
-func filterInvalidThinkingBlocks(messages *[]ClaudeMessage) {
-	for i := range *messages {
-		role := (*messages)[i].Role
-		if role != "assistant" && role != "model" {
-			continue
-		}
-
-		blocks := parseContentBlocks((*messages)[i].Content)
-		if blocks == nil {
-			continue
-		}
-
-		filtered := make([]ContentBlock, 0, len(blocks))
-		for _, block := range blocks {
-			if block.Type != "thinking" {
-				filtered = append(filtered, block)
-				continue
-			}
-
-			if hasValidThinkingSignature(block.Thinking, block.Signature) {
-				// Sanitize: cache_control should not be forwarded
-				block.CacheControl = nil
-				filtered = append(filtered, block)
-				continue
-			}
-
-			// Invalid signature: preserve content by downgrading to text (Manager behavior)
-			if strings.TrimSpace(block.Thinking) != "" {
-				filtered = append(filtered, ContentBlock{
-					Type: "text",
-					Text: block.Thinking,
-				})
-			}
-		}
-
-		if len(filtered) == 0 {
-			filtered = append(filtered, ContentBlock{
-				Type: "text",
-				Text: "",
-			})
-		}
-
-		(*messages)[i].Content = filtered
-	}
-}
-
-// removeTrailingUnsignedThinking removes trailing thinking blocks without valid signatures from assistant/model messages.
-// Mirrors Antigravity-Manager's `remove_trailing_unsigned_thinking`.
-func removeTrailingUnsignedThinking(messages *[]ClaudeMessage) {
-	for i := range *messages {
-		role := (*messages)[i].Role
-		if role != "assistant" && role != "model" {
-			continue
-		}
-
-		blocks := parseContentBlocks((*messages)[i].Content)
-		if blocks == nil || len(blocks) == 0 {
-			continue
-		}
-
-		endIndex := len(blocks)
-		for j := len(blocks) - 1; j >= 0; j-- {
-			if blocks[j].Type != "thinking" {
-				break
-			}
-			if !hasValidThinkingSignature(blocks[j].Thinking, blocks[j].Signature) {
-				endIndex = j
-				continue
-			}
-			break
-		}
-
-		if endIndex < len(blocks) {
-			blocks = blocks[:endIndex]
-			(*messages)[i].Content = blocks
-		}
-	}
-}
-
 // closeToolLoopForThinking injects synthetic messages to break tool loops
 // Reference: Antigravity-Manager's close_tool_loop_for_thinking
 func closeToolLoopForThinking(messages *[]ClaudeMessage) {
@@ -449,17 +358,17 @@ func detectWebSearchTool(claudeReq *ClaudeRequest) bool {
 // calculateFinalThinkingState determines the final thinking mode state
 // after all checks (model defaults, target support, history compatibility)
 // Reference: Antigravity-Manager's thinking mode resolution (line 170-251)
-func calculateFinalThinkingState(claudeReq *ClaudeRequest, mappedModel string, signatureCache *SignatureCache) bool {
+func calculateFinalThinkingState(claudeReq *ClaudeRequest, providerID uint64, mappedModel string, signatureCache *thinking.SignatureCache) bool {
 	// 1. Check explicit thinking config first
 	thinkingRequested := claudeReq.Thinking != nil && claudeReq.Thinking.Type == "enabled"
 
 	// 2. If no explicit config, check if model should enable thinking by default (Opus 4.5)
-	if !thinkingRequested && shouldEnableThinkingByDefault(claudeReq.Model) {
+	if !thinkingRequested && thinking.EnabledByDefault(claudeReq.Model) {
 		thinkingRequested = true
 	}
 
 	// 3. Check if target model supports thinking
-	if thinkingRequested && !TargetModelSupportsThinking(mappedModel) {
+	if thinkingRequested && !thinking.TargetModelSupports(providerID, mappedModel) {
 		log.Printf("[Antigravity] Target model '%s' does not support thinking. Force disabling.", mappedModel)
 		return false
 	}
@@ -469,7 +378,7 @@ func calculateFinalThinkingState(claudeReq *ClaudeRequest, mappedModel string, s
 	if thinkingRequested {
 		// Need to convert messages to Gemini format first to check compatibility
 		// For now, we'll do a simplified check on Claude messages
-		if shouldDisableThinkingDueToClaudeHistory(claudeReq.Messages) {
+		if thinking.ShouldDisableDueToHistory(claudeReq.Messages) {
 			log.Printf("[Antigravity] Disabling thinking due to incompatible tool-use history (mixed application)")
 			return false
 		}
@@ -482,10 +391,10 @@ func calculateFinalThinkingState(claudeReq *ClaudeRequest, mappedModel string, s
 		globalSig := GetThoughtSignature()
 
 		// Check if there are thinking blocks in history
-		hasThinkingHistory := hasThinkingInMessages(claudeReq.Messages)
+		hasThinkingHistory := thinking.HasHistory(claudeReq.Messages)
 
 		// Check if there are function calls
-		hasFunctionCalls := hasFunctionCallsInMessages(claudeReq.Messages)
+		hasFunctionCalls := thinking.HasFunctionCalls(claudeReq.Messages)
 
 		// [FIX #298] For first-time thinking requests (no thinking history),
 		// we use permissive mode and let upstream handle validation.
@@ -497,7 +406,7 @@ func calculateFinalThinkingState(claudeReq *ClaudeRequest, mappedModel string, s
 				"signature validation will be handled by upstream API.")
 		}
 
-		if needsSignatureCheck && !hasValidSignatureForFunctionCalls(claudeReq.Messages, globalSig) {
+		if needsSignatureCheck && !thinking.HasValidSignatureForFunctionCalls(claudeReq.Messages, globalSig) {
 			log.Printf("[Antigravity] [FIX #295] No valid signature found for function calls. " +
 				"Disabling thinking to prevent Gemini 3 Pro rejection.")
 			return false
@@ -506,114 +415,3 @@ func calculateFinalThinkingState(claudeReq *ClaudeRequest, mappedModel string, s
 
 	return thinkingRequested
 }
-
-// shouldDisableThinkingDueToClaudeHistory checks Claude messages for thinking/tool incompatibility
-// Reference: Antigravity-Manager's should_disable_thinking_due_to_history
-func shouldDisableThinkingDueToClaudeHistory(messages []ClaudeMessage) bool {
-	// Find last assistant message
-	for i := len(messages) - 1; i >= 0; i-- {
-		if messages[i].Role != "assistant" {
-			continue
-		}
-
-		// Parse content blocks
-		blocks := parseContentBlocks(messages[i].Content)
-		if blocks == nil {
-			return false
-		}
-
-		hasToolUse := false
-		hasThinking := false
-
-		for _, block := range blocks {
-			if block.Type == "tool_use" {
-				hasToolUse = true
-			}
-			if block.Type == "thinking" {
-				hasThinking = true
-			}
-		}
-
-		// If has tool_use but no thinking -> incompatible
-		if hasToolUse && !hasThinking {
-			return true
-		}
-
-		// Only check the last assistant message
-		return false
-	}
-
-	return false
-}
-
-// hasValidSignatureForFunctionCalls checks if we have any valid signature for function calls
-// Reference: Antigravity-Manager's has_valid_signature_for_function_calls (line 405-435)
-// [FIX #295] Prevents Gemini 3 Pro from rejecting requests due to missing thought_signature
-func hasValidSignatureForFunctionCalls(messages []ClaudeMessage, globalSig string) bool {
-	// 1. Check global store
-	if globalSig != "" && len(globalSig) >= MinSignatureLength {
-		return true
-	}
-
-	// 2. Check if any message has a thinking block with valid signature
-	for i := len(messages) - 1; i >= 0; i-- {
-		if messages[i].Role != "assistant" {
-			continue
-		}
-
-		blocks := parseContentBlocks(messages[i].Content)
-		if blocks == nil {
-			continue
-		}
-
-		for _, block := range blocks {
-			if block.Type == "thinking" && block.Signature != "" {
-				if len(block.Signature) >= MinSignatureLength {
-					return true
-				}
-			}
-		}
-	}
-
-	return false
-}
-
-// hasThinkingInMessages checks if any message contains thinking blocks
-func hasThinkingInMessages(messages []ClaudeMessage) bool {
-	for _, msg := range messages {
-		if msg.Role != "assistant" {
-			continue
-		}
-
-		blocks := parseContentBlocks(msg.Content)
-		if blocks == nil {
-			continue
-		}
-
-		for _, block := range blocks {
-			if block.Type == "thinking" {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-// hasFunctionCallsInMessages checks if any message contains tool_use blocks
-func hasFunctionCallsInMessages(messages []ClaudeMessage) bool {
-	for _, msg := range messages {
-		blocks := parseContentBlocks(msg.Content)
-		if blocks == nil {
-			continue
-		}
-
-		for _, block := range blocks {
-			if block.Type == "tool_use" {
-				return true
-			}
-		}
-	}
-
-	return false
-}
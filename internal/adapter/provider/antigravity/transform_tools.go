@@ -3,6 +3,8 @@ package antigravity
 import (
 	"log"
 	"strings"
+
+	"github.com/awsl-project/maxx/internal/converter"
 )
 
 // buildTools converts Claude tools to Gemini tools format
@@ -41,7 +43,7 @@ func buildTools(claudeReq *ClaudeRequest) interface{} {
 		CleanJSONSchema(cleanedSchema)
 
 		functionDeclarations = append(functionDeclarations, map[string]interface{}{
-			"name":        tool.Name,
+			"name":        converter.SanitizeToolName(tool.Name),
 			"description": tool.Description,
 			"parameters":  cleanedSchema,
 		})
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -13,8 +14,10 @@ import (
 	"time"
 
 	"github.com/awsl-project/maxx/internal/adapter/provider"
+	"github.com/awsl-project/maxx/internal/adapter/provider/stream"
 	ctxutil "github.com/awsl-project/maxx/internal/context"
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/httpclient"
 	"github.com/awsl-project/maxx/internal/usage"
 )
 
@@ -23,7 +26,8 @@ func init() {
 }
 
 type CustomAdapter struct {
-	provider *domain.Provider
+	provider   *domain.Provider
+	httpClient *http.Client
 }
 
 func NewAdapter(p *domain.Provider) (provider.ProviderAdapter, error) {
@@ -31,7 +35,8 @@ func NewAdapter(p *domain.Provider) (provider.ProviderAdapter, error) {
 		return nil, fmt.Errorf("provider %s missing custom config", p.Name)
 	}
 	return &CustomAdapter{
-		provider: p,
+		provider:   p,
+		httpClient: httpclient.ForProvider(p, httpclient.DefaultOptions()),
 	}, nil
 }
 
@@ -44,8 +49,12 @@ func (a *CustomAdapter) Execute(ctx context.Context, w http.ResponseWriter, req
 	mappedModel := ctxutil.GetMappedModel(ctx)
 	requestBody := ctxutil.GetRequestBody(ctx)
 
-	// Determine if streaming
-	stream := isStreamRequest(requestBody)
+	// Apply the mapped model (model mapping or fallback chain) to the body
+	if mappedModel != "" {
+		if body, err := updateModelInBody(requestBody, mappedModel, clientType); err == nil {
+			requestBody = body
+		}
+	}
 
 	// Note: Format conversion is now handled by Executor layer
 	// The clientType in context is already the correct type that this provider supports
@@ -55,6 +64,10 @@ func (a *CustomAdapter) Execute(ctx context.Context, w http.ResponseWriter, req
 	baseURL := a.getBaseURL(clientType)
 	requestURI := ctxutil.GetRequestURI(ctx)
 
+	// Determine if streaming (Gemini selects streaming by method, not by body)
+	stream := isStreamRequest(requestBody) ||
+		(clientType == domain.ClientTypeGemini && strings.Contains(requestURI, ":streamGenerateContent"))
+
 	// For Gemini, update model in URL path if mapping is configured
 	if clientType == domain.ClientTypeGemini && mappedModel != "" {
 		requestURI = updateGeminiModelInPath(requestURI, mappedModel)
@@ -76,6 +89,7 @@ func (a *CustomAdapter) Execute(ctx context.Context, w http.ResponseWriter, req
 	if a.provider.Config.Custom.APIKey != "" {
 		setAuthHeader(upstreamReq, clientType, a.provider.Config.Custom.APIKey)
 	}
+	setOpenAIHeaders(upstreamReq, a.provider.Config.Custom)
 
 	// Send request info via EventChannel
 	if eventChan := ctxutil.GetEventChan(ctx); eventChan != nil {
@@ -87,11 +101,8 @@ func (a *CustomAdapter) Execute(ctx context.Context, w http.ResponseWriter, req
 		})
 	}
 
-	// Execute request with reasonable timeout
-	client := &http.Client{
-		Timeout: 10 * time.Minute, // Long timeout for LLM requests
-	}
-	resp, err := client.Do(upstreamReq)
+	// Execute request on the provider's pooled client (10 minute timeout for LLM requests)
+	resp, err := a.httpClient.Do(upstreamReq)
 	if err != nil {
 		proxyErr := domain.NewProxyErrorWithMessage(domain.ErrUpstreamError, true, "failed to connect to upstream")
 		proxyErr.IsNetworkError = true
@@ -99,6 +110,12 @@ func (a *CustomAdapter) Execute(ctx context.Context, w http.ResponseWriter, req
 	}
 	defer resp.Body.Close()
 
+	// Report how long reading the response body (the whole stream, if streaming) took
+	bodyStart := time.Now()
+	defer func() {
+		ctxutil.GetEventChan(ctx).SendTimings(&domain.UpstreamTimings{Stream: time.Since(bodyStart)})
+	}()
+
 	// Check for error response
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
@@ -120,6 +137,8 @@ func (a *CustomAdapter) Execute(ctx context.Context, w http.ResponseWriter, req
 		// Set status code and check if it's a server error (5xx)
 		proxyErr.HTTPStatusCode = resp.StatusCode
 		proxyErr.IsServerError = resp.StatusCode >= 500 && resp.StatusCode < 600
+		proxyErr.ResponseBody = body
+		proxyErr.ResponseFormat = clientType
 
 		// Parse rate limit info for 429 errors
 		if resp.StatusCode == http.StatusTooManyRequests {
@@ -141,6 +160,60 @@ func (a *CustomAdapter) Execute(ctx context.Context, w http.ResponseWriter, req
 	return a.handleNonStreamResponse(ctx, w, resp, clientType)
 }
 
+// ExecuteAuxiliary passes an auxiliary request (Gemini countTokens, model listings and
+// lookups) through unchanged, apart from the mapped model in models/{model} paths
+func (a *CustomAdapter) ExecuteAuxiliary(ctx context.Context, w http.ResponseWriter, req *http.Request, provider *domain.Provider) error {
+	clientType := ctxutil.GetClientType(ctx)
+	requestURI := ctxutil.GetRequestURI(ctx)
+	if mappedModel := ctxutil.GetMappedModel(ctx); mappedModel != "" {
+		requestURI = updateGeminiModelInPath(requestURI, mappedModel)
+	}
+
+	var body io.Reader
+	if requestBody := ctxutil.GetRequestBody(ctx); len(requestBody) > 0 {
+		body = bytes.NewReader(requestBody)
+	}
+	upstreamReq, err := http.NewRequestWithContext(ctx, req.Method, buildUpstreamURL(a.getBaseURL(clientType), requestURI), body)
+	if err != nil {
+		return domain.NewProxyErrorWithMessage(domain.ErrUpstreamError, false, "failed to create upstream request")
+	}
+	upstreamReq.Header = ctxutil.GetRequestHeaders(ctx).Clone()
+	if a.provider.Config.Custom.APIKey != "" {
+		setAuthHeader(upstreamReq, clientType, a.provider.Config.Custom.APIKey)
+	}
+	setOpenAIHeaders(upstreamReq, a.provider.Config.Custom)
+
+	resp, err := a.httpClient.Do(upstreamReq)
+	if err != nil {
+		proxyErr := domain.NewProxyErrorWithMessage(domain.ErrUpstreamError, true, "failed to connect to upstream")
+		proxyErr.IsNetworkError = true
+		return proxyErr
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return domain.NewProxyErrorWithMessage(domain.ErrUpstreamError, true, "failed to read upstream response")
+	}
+	if resp.StatusCode >= 400 {
+		proxyErr := domain.NewProxyErrorWithMessage(
+			fmt.Errorf("upstream error: %s", string(respBody)),
+			isRetryableStatusCode(resp.StatusCode),
+			fmt.Sprintf("upstream returned status %d", resp.StatusCode),
+		)
+		proxyErr.HTTPStatusCode = resp.StatusCode
+		proxyErr.IsServerError = resp.StatusCode >= 500
+		proxyErr.ResponseBody = respBody
+		proxyErr.ResponseFormat = clientType
+		return proxyErr
+	}
+
+	copyResponseHeaders(w.Header(), resp.Header)
+	w.WriteHeader(resp.StatusCode)
+	_, _ = w.Write(respBody)
+	return nil
+}
+
 func (a *CustomAdapter) supportsClientType(ct domain.ClientType) bool {
 	for _, supported := range a.provider.SupportedClientTypes {
 		if supported == ct {
@@ -238,9 +311,10 @@ func (a *CustomAdapter) handleStreamResponse(ctx context.Context, w http.Respons
 	// Note: Response format conversion is handled by Executor's ConvertingResponseWriter
 	// Adapter simply passes through the upstream SSE data
 
-	// Collect all SSE events for response body and token extraction
+	// Collect all SSE events for the response body; usage is extracted as events pass
 	var sseBuffer strings.Builder
 	var sseError error // Track any SSE error event
+	extractor := usage.NewStreamExtractor()
 
 	// Helper to send final events via EventChannel
 	sendFinalEvents := func() {
@@ -252,70 +326,18 @@ func (a *CustomAdapter) handleStreamResponse(ctx context.Context, w http.Respons
 				Body:    sseBuffer.String(),
 			})
 
-			// Extract and send token usage
-			if metrics := usage.ExtractFromStreamContent(sseBuffer.String()); metrics != nil {
-				// Adjust for client-specific quirks (e.g., Codex input_tokens includes cached tokens)
-				metrics = usage.AdjustForClientType(metrics, clientType)
-				eventChan.SendMetrics(&domain.AdapterMetrics{
-					InputTokens:          metrics.InputTokens,
-					OutputTokens:         metrics.OutputTokens,
-					CacheReadCount:       metrics.CacheReadCount,
-					CacheCreationCount:   metrics.CacheCreationCount,
-					Cache5mCreationCount: metrics.Cache5mCreationCount,
-					Cache1hCreationCount: metrics.Cache1hCreationCount,
-				})
-			}
-
-			// Extract and send responseModel
-			if responseModel := extractResponseModelFromSSE(sseBuffer.String(), clientType); responseModel != "" {
-				eventChan.SendResponseModel(responseModel)
-			}
+			sendStreamUsage(eventChan, extractor, sseBuffer.String(), clientType)
 		}
 	}
 
-	// Helper to parse SSE error event from data line
-	parseSSEError := func(dataLine string) error {
-		// Remove "data:" prefix and trim whitespace
-		data := strings.TrimSpace(strings.TrimPrefix(dataLine, "data:"))
-		if data == "" || data == "[DONE]" {
-			return nil
-		}
-
-		// Try to parse as JSON
-		var payload map[string]interface{}
-		if err := json.Unmarshal([]byte(data), &payload); err != nil {
-			return nil
-		}
-
-		// Check for error type
-		if payloadType, ok := payload["type"].(string); ok && payloadType == "error" {
-			// Extract error message
-			if errObj, ok := payload["error"].(map[string]interface{}); ok {
-				msg := "SSE error"
-				if m, ok := errObj["message"].(string); ok {
-					msg = m
-				}
-				code := 0
-				if c, ok := errObj["code"].(float64); ok {
-					code = int(c)
-				}
-				errType := ""
-				if t, ok := errObj["type"].(string); ok {
-					errType = t
-				}
-				return domain.NewProxyErrorWithMessage(
-					fmt.Errorf("SSE error (code=%d): %s", code, msg),
-					isRetryableSSEError(code, errType, msg),
-					msg,
-				)
-			}
-		}
-		return nil
+	cfg := stream.CurrentConfig()
+	if ctxutil.GetPassthrough(ctx) {
+		return a.handlePassthroughStream(ctx, w, flusher, resp, clientType, cfg)
 	}
 
-	// Use buffer-based approach to handle incomplete lines properly
-	var lineBuffer bytes.Buffer
-	buf := make([]byte, 4096)
+	reader := stream.NewLineReader(resp.Body, cfg.ReadBufferSize)
+	sw := stream.NewWriter(w, flusher, cfg)
+	defer sw.Close()
 
 	for {
 		// Check context before reading
@@ -326,42 +348,34 @@ func (a *CustomAdapter) handleStreamResponse(ctx context.Context, w http.Respons
 		default:
 		}
 
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			lineBuffer.Write(buf[:n])
-
-			// Process complete lines (lines ending with \n)
-			for {
-				line, readErr := lineBuffer.ReadString('\n')
-				if readErr != nil {
-					// No complete line yet, put partial data back
-					lineBuffer.WriteString(line)
-					break
-				}
+		line, err := reader.ReadLine()
+		if len(line) > 0 {
+			// Collect all SSE content (preserve complete format including newlines)
+			sseBuffer.Write(line)
 
-				// Collect all SSE content (preserve complete format including newlines)
-				sseBuffer.WriteString(line)
+			// Report usage as soon as an event carries it, for live token counters
+			extractor.Write(line)
+			if metrics, ok := extractor.Poll(); ok {
+				eventChan.SendMetrics(usage.AdjustForClientType(metrics, clientType).ToAdapterMetrics())
+			}
 
-				// Check for SSE error events in data lines
-				lineStr := line
-				if strings.HasPrefix(strings.TrimSpace(lineStr), "data:") {
-					if parseErr := parseSSEError(lineStr); parseErr != nil {
-						sseError = parseErr
-						// Continue to forward the error to client, but track it
-					}
+			// Check for SSE error events in data lines
+			if bytes.HasPrefix(bytes.TrimSpace(line), []byte("data:")) {
+				if parseErr := parseSSEError(string(line)); parseErr != nil {
+					sseError = parseErr
+					// Continue to forward the error to client, but track it
 				}
+			}
 
-				// Note: Response format conversion is handled by Executor's ConvertingResponseWriter
-				// Adapter simply passes through the upstream SSE data
-				if len(line) > 0 {
-					_, writeErr := w.Write([]byte(line))
-					if writeErr != nil {
-						// Client disconnected
-						sendFinalEvents()
-						return domain.NewProxyErrorWithMessage(writeErr, false, "client disconnected")
-					}
-					flusher.Flush()
-				}
+			// Note: Response format conversion is handled by Executor's ConvertingResponseWriter
+			// Adapter simply passes through the upstream SSE data
+			if _, writeErr := sw.Write(line); writeErr != nil {
+				// Client disconnected
+				sendFinalEvents()
+				return domain.NewProxyErrorWithMessage(writeErr, false, "client disconnected")
+			}
+			if stream.IsEventBoundary(line) || reader.Drained() {
+				sw.EventDone()
 			}
 		}
 
@@ -389,6 +403,104 @@ func (a *CustomAdapter) handleStreamResponse(ctx context.Context, w http.Respons
 	}
 }
 
+// SupportsPassthrough implements provider.PassthroughAdapter
+func (a *CustomAdapter) SupportsPassthrough() bool {
+	return true
+}
+
+// handlePassthroughStream copies an upstream stream that needs no conversion straight
+// to the client. Only a bounded sample of the stream is kept for usage extraction and
+// SSE error detection, so an error event is only noticed if it is near the end.
+func (a *CustomAdapter) handlePassthroughStream(ctx context.Context, w http.ResponseWriter, flusher http.Flusher, resp *http.Response, clientType domain.ClientType, cfg stream.Config) error {
+	eventChan := ctxutil.GetEventChan(ctx)
+
+	sample := stream.NewSample(stream.DefaultSampleSize)
+	extractor := usage.NewStreamExtractor()
+	sw := stream.NewWriter(w, flusher, cfg)
+	_, copyErr := stream.Copy(sw, io.TeeReader(resp.Body, io.MultiWriter(sample, extractor)), cfg.ReadBufferSize)
+	sw.Close()
+
+	content := sample.String()
+	sendStreamUsage(eventChan, extractor, content, clientType)
+
+	if ctx.Err() != nil {
+		return domain.NewProxyErrorWithMessage(ctx.Err(), false, "client disconnected")
+	}
+	if errors.Is(copyErr, stream.ErrClientWrite) {
+		return domain.NewProxyErrorWithMessage(copyErr, false, "client disconnected")
+	}
+
+	// Upstream read errors are treated as the end of the stream, same as the line-based path
+	var sseError error
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "data:") {
+			if parseErr := parseSSEError(line); parseErr != nil {
+				sseError = parseErr
+			}
+		}
+	}
+	return sseError
+}
+
+// sendStreamUsage sends the token usage the extractor saw and the response model from SSE content
+func sendStreamUsage(eventChan domain.AdapterEventChan, extractor *usage.StreamExtractor, content string, clientType domain.ClientType) {
+	if content == "" {
+		return
+	}
+
+	// Send token usage
+	if metrics := extractor.Metrics(); metrics != nil {
+		// Adjust for client-specific quirks (e.g., Codex input_tokens includes cached tokens)
+		metrics = usage.AdjustForClientType(metrics, clientType)
+		eventChan.SendMetrics(metrics.ToAdapterMetrics())
+	}
+
+	// Extract and send responseModel
+	if responseModel := extractResponseModelFromSSE(content, clientType); responseModel != "" {
+		eventChan.SendResponseModel(responseModel)
+	}
+}
+
+// parseSSEError parses an SSE error event from a data line
+func parseSSEError(dataLine string) error {
+	// Remove "data:" prefix and trim whitespace
+	data := strings.TrimSpace(strings.TrimPrefix(dataLine, "data:"))
+	if data == "" || data == "[DONE]" {
+		return nil
+	}
+
+	// Try to parse as JSON
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(data), &payload); err != nil {
+		return nil
+	}
+
+	// Check for error type
+	if payloadType, ok := payload["type"].(string); ok && payloadType == "error" {
+		// Extract error message
+		if errObj, ok := payload["error"].(map[string]interface{}); ok {
+			msg := "SSE error"
+			if m, ok := errObj["message"].(string); ok {
+				msg = m
+			}
+			code := 0
+			if c, ok := errObj["code"].(float64); ok {
+				code = int(c)
+			}
+			errType := ""
+			if t, ok := errObj["type"].(string); ok {
+				errType = t
+			}
+			return domain.NewProxyErrorWithMessage(
+				fmt.Errorf("SSE error (code=%d): %s", code, msg),
+				isRetryableSSEError(code, errType, msg),
+				msg,
+			)
+		}
+	}
+	return nil
+}
+
 // Helper functions
 
 func isStreamRequest(body []byte) bool {
@@ -410,6 +522,10 @@ func updateModelInBody(body []byte, model string, clientType domain.ClientType)
 	if err := json.Unmarshal(body, &req); err != nil {
 		return nil, err
 	}
+	// Leave the body untouched when the model is already right (or absent, e.g. Gemini CLI envelopes)
+	if current, ok := req["model"].(string); !ok || current == model {
+		return body, nil
+	}
 	req["model"] = model
 	return json.Marshal(req)
 }
@@ -449,6 +565,23 @@ func setAuthHeader(req *http.Request, clientType domain.ClientType, apiKey strin
 	// The request will be sent as-is (useful for providers that use query params or other auth methods)
 }
 
+// setOpenAIHeaders injects the OpenAI organization/project headers configured on the provider,
+// replacing whatever the client sent
+func setOpenAIHeaders(req *http.Request, cfg *domain.ProviderConfigCustom) {
+	if cfg.OpenAIOrganization == "" && cfg.OpenAIProject == "" {
+		return
+	}
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	if cfg.OpenAIOrganization != "" {
+		req.Header.Set("OpenAI-Organization", cfg.OpenAIOrganization)
+	}
+	if cfg.OpenAIProject != "" {
+		req.Header.Set("OpenAI-Project", cfg.OpenAIProject)
+	}
+}
+
 func isRetryableStatusCode(code int) bool {
 	switch code {
 	case 429, 500, 502, 503, 504:
@@ -6,7 +6,6 @@ import (
 	"crypto/tls"
 	"fmt"
 	"io"
-	"net"
 	"net/http"
 	"strings"
 	"sync"
@@ -16,6 +15,7 @@ import (
 	ctxutil "github.com/awsl-project/maxx/internal/context"
 	"github.com/awsl-project/maxx/internal/converter"
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/httpclient"
 	"github.com/awsl-project/maxx/internal/usage"
 )
 
@@ -54,7 +54,7 @@ func NewAdapter(p *domain.Provider) (provider.ProviderAdapter, error) {
 		provider:   p,
 		tokenCache: &TokenCache{},
 		usageCache: &UsageCache{},
-		httpClient: newKiroHTTPClient(),
+		httpClient: newKiroHTTPClient(p),
 	}, nil
 }
 
@@ -187,6 +187,7 @@ func (a *KiroAdapter) Execute(ctx context.Context, w http.ResponseWriter, req *h
 		)
 		proxyErr.HTTPStatusCode = resp.StatusCode
 		proxyErr.IsServerError = resp.StatusCode >= 500 && resp.StatusCode < 600
+		proxyErr.ResponseBody = body
 
 		return proxyErr
 	}
@@ -195,6 +196,12 @@ func (a *KiroAdapter) Execute(ctx context.Context, w http.ResponseWriter, req *h
 	// Calculate input tokens for the request
 	inputTokens := calculateInputTokens(requestBody)
 
+	// Report how long reading the response stream took
+	bodyStart := time.Now()
+	defer func() {
+		ctxutil.GetEventChan(ctx).SendTimings(&domain.UpstreamTimings{Stream: time.Since(bodyStart)})
+	}()
+
 	if stream {
 		return a.handleStreamResponse(ctx, w, resp, requestModel, inputTokens)
 	}
@@ -685,34 +692,40 @@ func isRetryableStatusCode(status int) bool {
 		status >= 500
 }
 
+// kiroTLSConfig 匹配 kiro2api 的 TLS 配置 (包级变量, 保证连接池按配置复用)
+var kiroTLSConfig = &tls.Config{
+	MinVersion: tls.VersionTLS12,
+	MaxVersion: tls.VersionTLS13,
+	CipherSuites: []uint16{
+		tls.TLS_AES_256_GCM_SHA384,
+		tls.TLS_CHACHA20_POLY1305_SHA256,
+		tls.TLS_AES_128_GCM_SHA256,
+	},
+}
+
 // newKiroHTTPClient creates an HTTP client for Kiro/CodeWhisperer API
 // 匹配 kiro2api/utils/client.go:26-52
-func newKiroHTTPClient() *http.Client {
-	return &http.Client{
-		Transport: &http.Transport{
-			// 连接建立配置 (匹配 kiro2api)
-			DialContext: (&net.Dialer{
-				Timeout:   15 * time.Second,
-				KeepAlive: 30 * time.Second,
-				DualStack: true,
-			}).DialContext,
-
-			// TLS配置 (匹配 kiro2api)
-			TLSHandshakeTimeout: 15 * time.Second,
-			TLSClientConfig: &tls.Config{
-				MinVersion: tls.VersionTLS12,
-				MaxVersion: tls.VersionTLS13,
-				CipherSuites: []uint16{
-					tls.TLS_AES_256_GCM_SHA384,
-					tls.TLS_CHACHA20_POLY1305_SHA256,
-					tls.TLS_AES_128_GCM_SHA256,
-				},
-			},
-
-			// HTTP配置 (匹配 kiro2api)
-			ForceAttemptHTTP2:  false,
-			DisableCompression: false,
-		},
+func newKiroHTTPClient(p *domain.Provider) *http.Client {
+	return httpclient.ForProvider(p, kiroHTTPOptions())
+}
+
+// apiHTTPClient 返回 token 刷新、用量查询使用的共享客户端
+func apiHTTPClient() *http.Client {
+	opts := kiroHTTPOptions()
+	opts.Timeout = 15 * time.Second
+	return httpclient.Get("kiro-api", opts)
+}
+
+func kiroHTTPOptions() httpclient.Options {
+	return httpclient.Options{
+		// 连接建立配置 (匹配 kiro2api)
+		ConnectTimeout: 15 * time.Second,
+		KeepAlive:      30 * time.Second,
+		// TLS配置 (匹配 kiro2api)
+		TLSHandshakeTimeout: 15 * time.Second,
+		TLSConfig:           kiroTLSConfig,
+		// HTTP配置 (匹配 kiro2api)
+		ForceAttemptHTTP2: false,
 		// 注意: kiro2api 不设置整体 Timeout
 	}
 }
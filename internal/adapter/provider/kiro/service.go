@@ -132,8 +132,7 @@ func refreshSocialToken(ctx context.Context, refreshToken string) (*SocialRefres
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
@@ -173,8 +172,7 @@ func getUsageLimits(ctx context.Context, accessToken, profileArn string) (*Usage
 	}
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := apiHTTPClient().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
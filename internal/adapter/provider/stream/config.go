@@ -0,0 +1,91 @@
+// Package stream provides buffered reading and coalesced flushing for upstream SSE streams.
+package stream
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+const (
+	// DefaultReadBufferSize is the upstream read buffer size.
+	DefaultReadBufferSize = 32 * 1024
+	// MinReadBufferSize keeps tiny configured values from degrading throughput.
+	MinReadBufferSize = 4 * 1024
+)
+
+// Config controls how upstream streams are read and flushed to the client.
+type Config struct {
+	// ReadBufferSize is the size of the buffered reader wrapped around the upstream body.
+	ReadBufferSize int
+	// FlushInterval coalesces flushes: at most one flush per interval, with pending data
+	// flushed when the interval elapses. Zero flushes on every SSE event boundary.
+	FlushInterval time.Duration
+	// Passthrough copies streams that need no format conversion straight to the client
+	// without line splitting or capturing the response body. Only a bounded sample of
+	// the stream is kept for token usage extraction.
+	Passthrough bool
+}
+
+// DefaultConfig flushes on every event boundary with a 32KB read buffer.
+func DefaultConfig() Config {
+	return Config{ReadBufferSize: DefaultReadBufferSize}
+}
+
+var (
+	configMu     sync.RWMutex
+	configGetter func() Config
+)
+
+// SetConfigGetter sets the function used to resolve the stream config for each response.
+// This should be called during application initialization.
+func SetConfigGetter(getter func() Config) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	configGetter = getter
+}
+
+// CurrentConfig returns the config for a new stream, falling back to DefaultConfig.
+func CurrentConfig() Config {
+	configMu.RLock()
+	getter := configGetter
+	configMu.RUnlock()
+
+	if getter == nil {
+		return DefaultConfig()
+	}
+	return getter().normalize()
+}
+
+func (c Config) normalize() Config {
+	if c.ReadBufferSize <= 0 {
+		c.ReadBufferSize = DefaultReadBufferSize
+	} else if c.ReadBufferSize < MinReadBufferSize {
+		c.ReadBufferSize = MinReadBufferSize
+	}
+	if c.FlushInterval < 0 {
+		c.FlushInterval = 0
+	}
+	return c
+}
+
+// ConfigFromSettings builds a Config from system settings; missing or invalid values use defaults.
+func ConfigFromSettings(get func(key string) (string, error)) Config {
+	cfg := DefaultConfig()
+	if val, err := get(domain.SettingKeyStreamReadBufferKB); err == nil && val != "" {
+		if kb, err := strconv.Atoi(val); err == nil && kb > 0 {
+			cfg.ReadBufferSize = kb * 1024
+		}
+	}
+	if val, err := get(domain.SettingKeyStreamFlushIntervalMs); err == nil && val != "" {
+		if ms, err := strconv.Atoi(val); err == nil && ms >= 0 {
+			cfg.FlushInterval = time.Duration(ms) * time.Millisecond
+		}
+	}
+	if val, err := get(domain.SettingKeyStreamPassthrough); err == nil {
+		cfg.Passthrough = val == "true"
+	}
+	return cfg.normalize()
+}
@@ -0,0 +1,46 @@
+package stream
+
+import (
+	"sync"
+	"time"
+)
+
+// Heartbeat writes an event to a Writer whenever the stream has been silent for an
+// interval, e.g. Claude ping events while Gemini is thinking, so clients don't treat
+// the stream as stalled.
+type Heartbeat struct {
+	stop     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// StartHeartbeat starts writing event to sw after every interval without output.
+// Stop must be called before sw is closed.
+func StartHeartbeat(sw *Writer, interval time.Duration, event []byte) *Heartbeat {
+	h := &Heartbeat{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(h.done)
+		// Check twice per interval, so a silence is filled within 1.5 intervals
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-h.stop:
+				return
+			case <-ticker.C:
+				sw.WriteIfIdle(event, interval)
+			}
+		}
+	}()
+	return h
+}
+
+// Stop stops the heartbeat and waits for a write in progress to finish. It may be called
+// more than once.
+func (h *Heartbeat) Stop() {
+	if h == nil {
+		return
+	}
+	h.stopOnce.Do(func() { close(h.stop) })
+	<-h.done
+}
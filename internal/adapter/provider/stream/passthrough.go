@@ -0,0 +1,95 @@
+package stream
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// DefaultSampleSize is how many bytes Sample keeps from each end of a stream.
+const DefaultSampleSize = 16 * 1024
+
+// ErrClientWrite wraps errors returned while writing to the client.
+var ErrClientWrite = errors.New("stream: client write failed")
+
+// Copy copies src to sw using io.CopyBuffer with a buffer of bufSize bytes,
+// signalling EventDone after every chunk so data is flushed as soon as it arrives
+// (subject to the flush interval). No line splitting or parsing is done.
+//
+// Write errors are wrapped with ErrClientWrite; any other error comes from src.
+// io.EOF is not reported.
+func Copy(sw *Writer, src io.Reader, bufSize int) (int64, error) {
+	if bufSize <= 0 {
+		bufSize = DefaultReadBufferSize
+	}
+	return io.CopyBuffer(chunkWriter{sw}, src, make([]byte, bufSize))
+}
+
+// chunkWriter ends an "event" after every write; it deliberately does not implement
+// io.ReaderFrom so io.CopyBuffer writes chunk by chunk.
+type chunkWriter struct {
+	sw *Writer
+}
+
+func (cw chunkWriter) Write(p []byte) (int, error) {
+	n, err := cw.sw.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("%w: %v", ErrClientWrite, err)
+	}
+	cw.sw.EventDone()
+	return n, nil
+}
+
+// Sample records the first and last limit bytes written to it, which is where
+// usage and model information lives in every supported SSE format.
+type Sample struct {
+	limit     int
+	head      []byte
+	tail      []byte
+	truncated bool // bytes were dropped between head and tail
+}
+
+// NewSample returns a Sample keeping up to limit bytes from each end.
+func NewSample(limit int) *Sample {
+	if limit <= 0 {
+		limit = DefaultSampleSize
+	}
+	return &Sample{limit: limit}
+}
+
+func (s *Sample) Write(p []byte) (int, error) {
+	n := len(p)
+	if room := s.limit - len(s.head); room > 0 {
+		take := min(room, len(p))
+		s.head = append(s.head, p[:take]...)
+		p = p[take:]
+	}
+	if len(p) == 0 {
+		return n, nil
+	}
+
+	s.tail = append(s.tail, p...)
+	if over := len(s.tail) - s.limit; over > 0 {
+		s.truncated = true
+		s.tail = append(s.tail[:0], s.tail[over:]...)
+	}
+	return n, nil
+}
+
+// String returns the recorded content. When the middle of the stream was dropped,
+// the partial lines on either side of the gap are removed so only whole lines remain.
+func (s *Sample) String() string {
+	if !s.truncated {
+		return string(s.head) + string(s.tail)
+	}
+	head := s.head
+	if i := bytes.LastIndexByte(head, '\n'); i >= 0 {
+		head = head[:i+1]
+	}
+	tail := s.tail
+	if i := bytes.IndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	return string(head) + string(tail)
+}
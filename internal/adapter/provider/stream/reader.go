@@ -0,0 +1,56 @@
+package stream
+
+import (
+	"bufio"
+	"io"
+)
+
+// LineReader reads newline-terminated lines from an upstream body through a bufio.Reader.
+type LineReader struct {
+	r   *bufio.Reader
+	buf []byte // holds lines longer than the read buffer
+}
+
+// NewLineReader wraps r with a read buffer of size bytes.
+func NewLineReader(r io.Reader, size int) *LineReader {
+	if size <= 0 {
+		size = DefaultReadBufferSize
+	}
+	return &LineReader{r: bufio.NewReaderSize(r, size)}
+}
+
+// ReadLine returns the next line including its trailing '\n'.
+// The returned slice is only valid until the next call.
+// A final line without '\n' is returned with a nil error; the following call returns io.EOF.
+// On other read errors any partial line is discarded.
+func (lr *LineReader) ReadLine() ([]byte, error) {
+	line, err := lr.r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		lr.buf = append(lr.buf[:0], line...)
+		for err == bufio.ErrBufferFull {
+			line, err = lr.r.ReadSlice('\n')
+			lr.buf = append(lr.buf, line...)
+		}
+		line = lr.buf
+	}
+
+	if err == io.EOF && len(line) > 0 {
+		return line, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// Drained reports whether all buffered input has been consumed, i.e. the next
+// ReadLine would block on the upstream. Pending output should be flushed at this point
+// so non-SSE or slow streams are not held back.
+func (lr *LineReader) Drained() bool {
+	return lr.r.Buffered() == 0
+}
+
+// IsEventBoundary reports whether line is the blank line that terminates an SSE event.
+func IsEventBoundary(line []byte) bool {
+	return len(line) == 1 && line[0] == '\n' || len(line) == 2 && line[0] == '\r' && line[1] == '\n'
+}
@@ -0,0 +1,129 @@
+package stream
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Writer forwards stream data to the client and coalesces flushes.
+//
+// Data is flushed on SSE event boundaries (EventDone) rather than on every write. With a
+// non-zero FlushInterval, at most one flush happens per interval and a timer flushes any
+// pending data once the interval elapses, so no event is held back longer than that.
+// Close must be called when the stream ends.
+type Writer struct {
+	w        http.ResponseWriter
+	flusher  http.Flusher
+	interval time.Duration
+
+	mu        sync.Mutex
+	lastFlush time.Time
+	lastWrite time.Time
+	pending   bool // data written since the last flush
+	written   bool // anything was written, so the response is committed
+	timer     *time.Timer
+	closed    bool
+}
+
+// NewWriter wraps w, which must implement http.Flusher.
+func NewWriter(w http.ResponseWriter, flusher http.Flusher, cfg Config) *Writer {
+	return &Writer{w: w, flusher: flusher, interval: cfg.FlushInterval, lastWrite: time.Now()}
+}
+
+func (sw *Writer) Write(p []byte) (int, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	n, err := sw.w.Write(p)
+	if n > 0 {
+		sw.pending = true
+		sw.written = true
+		sw.lastWrite = time.Now()
+	}
+	return n, err
+}
+
+// Written reports whether anything, heartbeats included, was written to the client. Until
+// then an adapter can still fail the attempt and let the executor retry.
+func (sw *Writer) Written() bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	return sw.written
+}
+
+// WriteIfIdle writes and flushes p if nothing was written for at least idle, and reports
+// whether it did. Heartbeats use it to fill silences without interleaving with events.
+func (sw *Writer) WriteIfIdle(p []byte, idle time.Duration) bool {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.closed || time.Since(sw.lastWrite) < idle {
+		return false
+	}
+	if _, err := sw.w.Write(p); err != nil {
+		return false
+	}
+	sw.written = true
+	sw.lastWrite = time.Now()
+	sw.flushLocked()
+	return true
+}
+
+// EventDone marks the end of an SSE event (or the point where upstream input is drained)
+// and flushes according to the flush interval.
+func (sw *Writer) EventDone() {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if !sw.pending || sw.closed {
+		return
+	}
+	if sw.interval <= 0 || time.Since(sw.lastFlush) >= sw.interval {
+		sw.flushLocked()
+		return
+	}
+	if sw.timer == nil {
+		sw.timer = time.AfterFunc(sw.interval-time.Since(sw.lastFlush), sw.onTimer)
+	}
+}
+
+// Flush flushes pending data immediately.
+func (sw *Writer) Flush() {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if !sw.closed {
+		sw.flushLocked()
+	}
+}
+
+// Close flushes pending data and stops the flush timer.
+// The writer must not be used after Close.
+func (sw *Writer) Close() {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	if sw.closed {
+		return
+	}
+	if sw.pending {
+		sw.flushLocked()
+	}
+	sw.closed = true
+}
+
+func (sw *Writer) onTimer() {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+	sw.timer = nil
+	if sw.pending && !sw.closed {
+		sw.flushLocked()
+	}
+}
+
+func (sw *Writer) flushLocked() {
+	if sw.timer != nil {
+		sw.timer.Stop()
+		sw.timer = nil
+	}
+	sw.flusher.Flush()
+	sw.pending = false
+	sw.lastFlush = time.Now()
+}
@@ -0,0 +1,163 @@
+// Package audit writes structured audit events to a local JSONL log, separate from the debug
+// log. Events use the field names of Claude Code hook payloads (hook_event_name, session_id,
+// tool_name, tool_input), so tooling written for hooks can consume the log as is.
+//
+// The log rotates by size: audit.jsonl is renamed to audit.jsonl.1 (and older files shift up)
+// once it reaches the size limit, and files beyond the backup count are removed.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/jsonl"
+)
+
+// Event names
+const (
+	EventRequestStarted   = "RequestStarted"
+	EventRequestCompleted = "RequestCompleted"
+	EventModelMapped      = "ModelMapped"
+	EventToolInvoked      = "PreToolUse" // The model asked the client to run a tool
+)
+
+const (
+	defaultMaxSize    = 20 << 20 // Rotate after 20 MB
+	defaultMaxBackups = 5
+)
+
+// Event is one line of the audit log
+type Event struct {
+	Timestamp     time.Time       `json:"timestamp"`
+	HookEventName string          `json:"hook_event_name"`
+	SessionID     string          `json:"session_id,omitempty"`
+	RequestID     string          `json:"request_id,omitempty"`
+	ClientType    string          `json:"client_type,omitempty"`
+	ProjectID     uint64          `json:"project_id,omitempty"`
+	APITokenID    uint64          `json:"api_token_id,omitempty"`
+	ProviderID    uint64          `json:"provider_id,omitempty"`
+	Model         string          `json:"model,omitempty"`
+	MappedModel   string          `json:"mapped_model,omitempty"`
+	Status        string          `json:"status,omitempty"`
+	StatusCode    int             `json:"status_code,omitempty"`
+	DurationMs    int64           `json:"duration_ms,omitempty"`
+	InputTokens   uint64          `json:"input_tokens,omitempty"`
+	OutputTokens  uint64          `json:"output_tokens,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	ToolName      string          `json:"tool_name,omitempty"`
+	ToolUseID     string          `json:"tool_use_id,omitempty"`
+	ToolInput     json.RawMessage `json:"tool_input,omitempty"`
+}
+
+// Filter selects events for Query
+type Filter struct {
+	HookEventName string
+	SessionID     string
+	RequestID     string
+	Since         time.Time
+	Limit         int
+}
+
+func (f *Filter) match(e *Event) bool {
+	return (f.HookEventName == "" || e.HookEventName == f.HookEventName) &&
+		(f.SessionID == "" || e.SessionID == f.SessionID) &&
+		(f.RequestID == "" || e.RequestID == f.RequestID) &&
+		(f.Since.IsZero() || !e.Timestamp.Before(f.Since))
+}
+
+// Logger appends events to a rotating JSONL file
+type Logger struct {
+	out *jsonl.Writer
+}
+
+// NewLogger creates a logger; it writes nothing until a path is set
+func NewLogger() *Logger {
+	return &Logger{out: jsonl.NewWriter(defaultMaxSize, defaultMaxBackups)}
+}
+
+var defaultLogger = NewLogger()
+
+// Default returns the global audit logger
+func Default() *Logger {
+	return defaultLogger
+}
+
+// SetPath sets the log file, closing the previous one
+func (l *Logger) SetPath(path string) {
+	l.out.SetPath(path)
+}
+
+// Path returns the log file, or "" if none is set
+func (l *Logger) Path() string {
+	return l.out.Path()
+}
+
+// Emit appends an event. Failures are logged and otherwise ignored: auditing must never
+// fail a request.
+func (l *Logger) Emit(e *Event) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("[Audit] Failed to encode event: %v", err)
+		return
+	}
+	if err := l.out.Append(append(line, '\n')); err != nil {
+		log.Printf("[Audit] Failed to write event: %v", err)
+	}
+}
+
+// Query returns the matching events, newest first, searching the current file and the
+// rotated backups
+func (l *Logger) Query(f Filter) ([]*Event, error) {
+	// Oldest file first, so the last matches kept are the newest
+	var events []*Event
+	for _, name := range l.out.Files() {
+		var err error
+		events, err = scanFile(name, &f, events)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]*Event, 0, len(events))
+	for i := len(events) - 1; i >= 0; i-- {
+		result = append(result, events[i])
+	}
+	return result, nil
+}
+
+// scanFile appends the matching events of one file, keeping only the last f.Limit
+func scanFile(name string, f *Filter, events []*Event) ([]*Event, error) {
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return events, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e Event
+		if json.Unmarshal([]byte(line), &e) != nil || !f.match(&e) {
+			continue
+		}
+		events = append(events, &e)
+		if f.Limit > 0 && len(events) > f.Limit {
+			events = events[1:]
+		}
+	}
+	return events, scanner.Err()
+}
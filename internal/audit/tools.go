@@ -0,0 +1,248 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// ToolUse is a tool call the model made in a response
+type ToolUse struct {
+	ID    string
+	Name  string
+	Input json.RawMessage
+}
+
+// ExtractToolUses returns the tool calls in a client response body, which is either a JSON
+// document or an SSE stream in the Claude, OpenAI, Codex or Gemini format
+func ExtractToolUses(body string) []ToolUse {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil
+	}
+	if strings.HasPrefix(body, "[") {
+		// Gemini streams without alt=sse are a JSON array of responses
+		var docs []responseDoc
+		if json.Unmarshal([]byte(body), &docs) != nil {
+			return nil
+		}
+		var uses []ToolUse
+		for i := range docs {
+			uses = append(uses, docs[i].toolUses()...)
+		}
+		return uses
+	}
+	if strings.HasPrefix(body, "{") {
+		var doc responseDoc
+		if json.Unmarshal([]byte(body), &doc) != nil {
+			return nil
+		}
+		return doc.toolUses()
+	}
+	return extractStreamToolUses(body)
+}
+
+// responseDoc covers the tool call fields of the non-streaming response formats
+type responseDoc struct {
+	// Claude
+	Content []struct {
+		Type  string          `json:"type"`
+		ID    string          `json:"id"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+	// OpenAI chat completions
+	Choices []struct {
+		Message struct {
+			ToolCalls []openAIToolCall `json:"tool_calls"`
+		} `json:"message"`
+	} `json:"choices"`
+	// Codex (responses API)
+	Output []codexItem `json:"output"`
+	// Gemini
+	Candidates []geminiCandidate `json:"candidates"`
+}
+
+type codexItem struct {
+	Type      string `json:"type"`
+	CallID    string `json:"call_id"`
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+func (it *codexItem) toolUse() (ToolUse, bool) {
+	if it.Type != "function_call" {
+		return ToolUse{}, false
+	}
+	return ToolUse{ID: it.CallID, Name: it.Name, Input: rawArguments(it.Arguments)}, true
+}
+
+type openAIToolCall struct {
+	Index    int    `json:"index"`
+	ID       string `json:"id"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type geminiCandidate struct {
+	Content struct {
+		Parts []struct {
+			FunctionCall *struct {
+				ID   string          `json:"id"`
+				Name string          `json:"name"`
+				Args json.RawMessage `json:"args"`
+			} `json:"functionCall"`
+		} `json:"parts"`
+	} `json:"content"`
+}
+
+func (d *responseDoc) toolUses() []ToolUse {
+	var uses []ToolUse
+	for _, c := range d.Content {
+		if c.Type == "tool_use" || c.Type == "server_tool_use" {
+			uses = append(uses, ToolUse{ID: c.ID, Name: c.Name, Input: c.Input})
+		}
+	}
+	for _, choice := range d.Choices {
+		for _, tc := range choice.Message.ToolCalls {
+			uses = append(uses, ToolUse{ID: tc.ID, Name: tc.Function.Name, Input: rawArguments(tc.Function.Arguments)})
+		}
+	}
+	for i := range d.Output {
+		if use, ok := d.Output[i].toolUse(); ok {
+			uses = append(uses, use)
+		}
+	}
+	uses = append(uses, geminiToolUses(d.Candidates)...)
+	return uses
+}
+
+func geminiToolUses(candidates []geminiCandidate) []ToolUse {
+	var uses []ToolUse
+	for _, cand := range candidates {
+		for _, part := range cand.Content.Parts {
+			if fc := part.FunctionCall; fc != nil {
+				uses = append(uses, ToolUse{ID: fc.ID, Name: fc.Name, Input: fc.Args})
+			}
+		}
+	}
+	return uses
+}
+
+// streamEvent covers the tool call fields of the streaming formats
+type streamEvent struct {
+	// Claude
+	Type         string `json:"type"`
+	Index        int    `json:"index"`
+	ContentBlock *struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"content_block"`
+	Delta *struct {
+		Type        string `json:"type"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	// OpenAI chat completions
+	Choices []struct {
+		Delta struct {
+			ToolCalls []openAIToolCall `json:"tool_calls"`
+		} `json:"delta"`
+	} `json:"choices"`
+	// Codex (responses API)
+	Item *codexItem `json:"item"`
+	// Gemini
+	Candidates []geminiCandidate `json:"candidates"`
+}
+
+// partialUse accumulates a streamed tool call
+type partialUse struct {
+	id, name string
+	input    strings.Builder
+}
+
+func extractStreamToolUses(body string) []ToolUse {
+	claude := make(map[int]*partialUse)
+	openAI := make(map[int]*partialUse)
+	var complete []ToolUse
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), 16<<20)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		data = strings.TrimSpace(data)
+		if !ok || data == "" || data == "[DONE]" {
+			continue
+		}
+		var ev streamEvent
+		if json.Unmarshal([]byte(data), &ev) != nil {
+			continue
+		}
+
+		switch {
+		case ev.Type == "content_block_start" && ev.ContentBlock != nil &&
+			(ev.ContentBlock.Type == "tool_use" || ev.ContentBlock.Type == "server_tool_use"):
+			claude[ev.Index] = &partialUse{id: ev.ContentBlock.ID, name: ev.ContentBlock.Name}
+		case ev.Type == "content_block_delta" && ev.Delta != nil && ev.Delta.Type == "input_json_delta":
+			if p := claude[ev.Index]; p != nil {
+				p.input.WriteString(ev.Delta.PartialJSON)
+			}
+		}
+		for _, choice := range ev.Choices {
+			for _, tc := range choice.Delta.ToolCalls {
+				p := openAI[tc.Index]
+				if p == nil {
+					p = &partialUse{}
+					openAI[tc.Index] = p
+				}
+				if tc.ID != "" {
+					p.id = tc.ID
+				}
+				if tc.Function.Name != "" {
+					p.name = tc.Function.Name
+				}
+				p.input.WriteString(tc.Function.Arguments)
+			}
+		}
+		if ev.Type == "response.output_item.done" && ev.Item != nil {
+			if use, ok := ev.Item.toolUse(); ok {
+				complete = append(complete, use)
+			}
+		}
+		complete = append(complete, geminiToolUses(ev.Candidates)...)
+	}
+
+	uses := collectPartial(claude)
+	uses = append(uses, collectPartial(openAI)...)
+	return append(uses, complete...)
+}
+
+func collectPartial(partials map[int]*partialUse) []ToolUse {
+	indexes := make([]int, 0, len(partials))
+	for i := range partials {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	uses := make([]ToolUse, 0, len(indexes))
+	for _, i := range indexes {
+		p := partials[i]
+		uses = append(uses, ToolUse{ID: p.id, Name: p.name, Input: rawArguments(p.input.String())})
+	}
+	return uses
+}
+
+// rawArguments keeps valid JSON arguments as is and quotes anything else
+func rawArguments(args string) json.RawMessage {
+	if args == "" {
+		return nil
+	}
+	if json.Valid([]byte(args)) {
+		return json.RawMessage(args)
+	}
+	quoted, _ := json.Marshal(args)
+	return quoted
+}
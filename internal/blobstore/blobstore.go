@@ -0,0 +1,48 @@
+// Package blobstore keeps large payloads (request and response bodies) outside the
+// database. Blobs are content-addressed, so identical bodies are stored once and a
+// reference can be written to the database before or after the blob itself.
+package blobstore
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by Get when no blob exists for the reference
+var ErrNotFound = errors.New("blob not found")
+
+// refPrefix marks a reference produced by this package
+const refPrefix = "sha256:"
+
+// Store stores and loads blobs by content reference
+type Store interface {
+	// Put stores data and returns its reference. Storing data that already exists
+	// refreshes its age so that Sweep keeps it.
+	Put(data []byte) (string, error)
+	// Get loads the data for a reference returned by Put
+	Get(ref string) ([]byte, error)
+	// Sweep removes blobs that have not been written since before and returns how
+	// many were removed
+	Sweep(before time.Time) (int, error)
+}
+
+// Ref returns the content reference for data
+func Ref(data []byte) string {
+	sum := sha256.Sum256(data)
+	return refPrefix + hex.EncodeToString(sum[:])
+}
+
+// parseRef returns the hex digest of a reference, or false if it is malformed
+func parseRef(ref string) (string, bool) {
+	digest, ok := strings.CutPrefix(ref, refPrefix)
+	if !ok || len(digest) != sha256.Size*2 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", false
+	}
+	return digest, true
+}
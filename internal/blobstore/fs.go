@@ -0,0 +1,126 @@
+package blobstore
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// blobExt is the suffix of stored blobs; bodies are mostly JSON and compress well
+const blobExt = ".gz"
+
+// FSStore stores gzip-compressed blobs on the local filesystem, sharded by the first
+// two hex digits of the digest: <dir>/ab/abcdef....gz
+type FSStore struct {
+	dir string
+}
+
+// NewFSStore creates a filesystem store rooted at dir, creating it if needed
+func NewFSStore(dir string) (*FSStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &FSStore{dir: dir}, nil
+}
+
+// Dir returns the root directory of the store
+func (s *FSStore) Dir() string {
+	return s.dir
+}
+
+func (s *FSStore) path(digest string) string {
+	return filepath.Join(s.dir, digest[:2], digest+blobExt)
+}
+
+func (s *FSStore) Put(data []byte) (string, error) {
+	ref := Ref(data)
+	digest, _ := parseRef(ref)
+	path := s.path(digest)
+
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err == nil {
+		return ref, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	zw := gzip.NewWriter(tmp)
+	if _, err := zw.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := zw.Close(); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	// Concurrent writers of the same content produce identical files, so whichever
+	// rename lands last is fine
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+func (s *FSStore) Get(ref string) ([]byte, error) {
+	digest, ok := parseRef(ref)
+	if !ok {
+		return nil, fmt.Errorf("invalid blob reference %q", ref)
+	}
+	compressed, err := os.ReadFile(s.path(digest))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
+
+func (s *FSStore) Sweep(before time.Time) (int, error) {
+	removed := 0
+	err := filepath.WalkDir(s.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if !strings.HasSuffix(name, blobExt) && !strings.HasPrefix(name, ".tmp-") {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().Before(before) {
+			if err := os.Remove(path); err == nil {
+				removed++
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
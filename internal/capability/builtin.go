@@ -0,0 +1,44 @@
+package capability
+
+import "github.com/awsl-project/maxx/internal/domain"
+
+var (
+	yes = ptrBool(true)
+	no  = ptrBool(false)
+)
+
+func ptrBool(b bool) *bool { return &b }
+
+// builtinRules are the capabilities known without configuration, most specific pattern first.
+// Rules stored in the database take precedence field by field.
+var builtinRules = []*domain.ModelCapability{
+	// Regular Gemini models think only with an explicit "-thinking" suffix
+	{Pattern: "*-thinking*", SupportsThinking: yes},
+
+	{Pattern: "gemini-1.5-flash*", SupportsTools: yes, SupportsVision: yes, MaxContext: 1_048_576, MaxOutput: 8192},
+	{Pattern: "gemini-1.5-pro*", SupportsTools: yes, SupportsVision: yes, MaxContext: 2_097_152, MaxOutput: 8192},
+	{Pattern: "gemini-2.0*", SupportsTools: yes, SupportsVision: yes, SupportsWebSearch: yes, MaxContext: 1_048_576, MaxOutput: 8192},
+	{Pattern: "gemini-2.5*", SupportsTools: yes, SupportsVision: yes, SupportsWebSearch: yes, MaxContext: 1_048_576, MaxOutput: 65536},
+	{Pattern: "gemini-3*", SupportsTools: yes, SupportsVision: yes, SupportsWebSearch: yes, MaxContext: 1_048_576, MaxOutput: 65536},
+
+	{Pattern: "gpt-4o-mini*", SupportsTools: yes, SupportsVision: yes, SupportsThinking: no, MaxContext: 128_000, MaxOutput: 16384},
+	{Pattern: "gpt-4.1*", SupportsTools: yes, SupportsVision: yes, SupportsThinking: no, MaxContext: 1_047_576, MaxOutput: 32768},
+	{Pattern: "gpt-4o*", SupportsTools: yes, SupportsVision: yes, SupportsThinking: no, MaxContext: 128_000, MaxOutput: 16384},
+	{Pattern: "o4-mini*", SupportsTools: yes, SupportsVision: yes, SupportsThinking: yes, MaxContext: 200_000, MaxOutput: 100_000},
+	{Pattern: "o1-mini*", SupportsTools: no, SupportsVision: no, SupportsThinking: yes, MaxContext: 128_000, MaxOutput: 65536},
+	{Pattern: "gpt-5*", SupportsTools: yes, SupportsVision: yes, SupportsThinking: yes, MaxContext: 400_000, MaxOutput: 128_000},
+	{Pattern: "o1*", SupportsTools: yes, SupportsVision: yes, SupportsThinking: yes, MaxContext: 200_000, MaxOutput: 100_000},
+	{Pattern: "o3*", SupportsTools: yes, SupportsVision: yes, SupportsThinking: yes, MaxContext: 200_000, MaxOutput: 100_000},
+
+	{Pattern: "claude-*", SupportsTools: yes, SupportsVision: yes, SupportsThinking: yes, SupportsWebSearch: yes, MaxContext: 200_000, MaxOutput: 64000},
+	{Pattern: "deepseek-*", SupportsTools: yes, MaxContext: 128_000, MaxOutput: 8192},
+}
+
+// Builtin returns the built-in capability rules
+func Builtin() []domain.ModelCapability {
+	rules := make([]domain.ModelCapability, len(builtinRules))
+	for i, rule := range builtinRules {
+		rules[i] = *rule
+	}
+	return rules
+}
@@ -0,0 +1,80 @@
+package capability
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+// CachePrefix fingerprints the part of a Claude request that prompt caching can reuse
+// across requests: the system prompt and tool definitions
+type CachePrefix struct {
+	Hash         string // Empty if the request has neither system prompt nor tools
+	Bytes        int    // Size of the system prompt and tool definitions
+	CacheControl bool   // The client marked any block with cache_control
+}
+
+// CachePrefixOf fingerprints a Claude Messages request body. cache_control markers
+// are ignored when hashing, so the same prompt with and without them compares equal.
+func CachePrefixOf(body []byte) CachePrefix {
+	var req struct {
+		System   json.RawMessage `json:"system"`
+		Tools    json.RawMessage `json:"tools"`
+		Messages json.RawMessage `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return CachePrefix{}
+	}
+
+	var prefix CachePrefix
+	marker := []byte(`"cache_control"`)
+	prefix.CacheControl = bytes.Contains(req.System, marker) ||
+		bytes.Contains(req.Tools, marker) ||
+		bytes.Contains(req.Messages, marker)
+
+	system := stripCacheControl(req.System)
+	tools := stripCacheControl(req.Tools)
+	if len(system) == 0 && len(tools) == 0 {
+		return prefix
+	}
+	h := sha256.New()
+	h.Write(system)
+	h.Write([]byte{0})
+	h.Write(tools)
+	prefix.Hash = hex.EncodeToString(h.Sum(nil))[:16]
+	prefix.Bytes = len(system) + len(tools)
+	return prefix
+}
+
+// stripCacheControl re-encodes a JSON value without cache_control keys; re-encoding
+// also normalises whitespace. Returns nil for a missing or null value.
+func stripCacheControl(raw json.RawMessage) []byte {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return raw
+	}
+	out, err := json.Marshal(removeKey(v, "cache_control"))
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+func removeKey(v interface{}, key string) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		delete(t, key)
+		for k, child := range t {
+			t[k] = removeKey(child, key)
+		}
+	case []interface{}:
+		for i, child := range t {
+			t[i] = removeKey(child, key)
+		}
+	}
+	return v
+}
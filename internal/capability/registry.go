@@ -0,0 +1,120 @@
+// Package capability answers what an upstream model supports (tools, vision, thinking, web
+// search, context and output limits). Rules configured per provider or globally in the
+// database are layered over built-in defaults, so routing, converters and request validation
+// share one answer instead of each guessing from the model name.
+package capability
+
+import (
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// Registry resolves model capabilities from configured and built-in rules
+type Registry struct {
+	mu    sync.RWMutex
+	rules []*domain.ModelCapability // Configured rules: provider-specific first, then by priority
+	repo  repository.ModelCapabilityRepository
+}
+
+// NewRegistry creates a registry with only the built-in rules
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+var defaultRegistry = NewRegistry()
+
+// Default returns the global registry
+func Default() *Registry {
+	return defaultRegistry
+}
+
+// SetRepository sets where configured rules are loaded from
+func (r *Registry) SetRepository(repo repository.ModelCapabilityRepository) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.repo = repo
+}
+
+// Load (re)loads the configured rules; call it after they change
+func (r *Registry) Load() error {
+	r.mu.RLock()
+	repo := r.repo
+	r.mu.RUnlock()
+	if repo == nil {
+		return nil
+	}
+
+	rules, err := repo.List()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.rules = rules
+	r.mu.Unlock()
+	log.Printf("[Capability] Loaded %d model capability rules", len(rules))
+	return nil
+}
+
+// Lookup returns the capabilities of model on a provider (0 for no particular provider).
+// Each field comes from the first matching rule that sets it: rules for the provider, then
+// global rules, then the built-in defaults. Patterns match case-insensitively.
+func (r *Registry) Lookup(providerID uint64, model string) domain.ModelCapabilities {
+	var caps domain.ModelCapabilities
+	if model == "" {
+		return caps
+	}
+
+	lower := strings.ToLower(model)
+	r.mu.RLock()
+	for _, rule := range r.rules {
+		if rule.ProviderID == 0 || rule.ProviderID == providerID {
+			if domain.MatchWildcard(strings.ToLower(rule.Pattern), lower) {
+				merge(&caps, rule)
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	for _, rule := range builtinRules {
+		if domain.MatchWildcard(rule.Pattern, lower) {
+			merge(&caps, rule)
+		}
+	}
+	return caps
+}
+
+// merge fills the fields of caps that are still unknown from rule
+func merge(caps *domain.ModelCapabilities, rule *domain.ModelCapability) {
+	if caps.SupportsTools == nil {
+		caps.SupportsTools = rule.SupportsTools
+	}
+	if caps.SupportsVision == nil {
+		caps.SupportsVision = rule.SupportsVision
+	}
+	if caps.SupportsThinking == nil {
+		caps.SupportsThinking = rule.SupportsThinking
+	}
+	if caps.SupportsWebSearch == nil {
+		caps.SupportsWebSearch = rule.SupportsWebSearch
+	}
+	if caps.MaxContext == 0 {
+		caps.MaxContext = rule.MaxContext
+	}
+	if caps.MaxOutput == 0 {
+		caps.MaxOutput = rule.MaxOutput
+	}
+}
+
+// Supported reports whether a capability is known to be supported
+func Supported(flag *bool) bool {
+	return flag != nil && *flag
+}
+
+// Unsupported reports whether a capability is known to be missing
+func Unsupported(flag *bool) bool {
+	return flag != nil && !*flag
+}
@@ -0,0 +1,78 @@
+package capability
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Requirements are the capabilities a request needs from the upstream model
+type Requirements struct {
+	Tools  bool // Declares tools
+	Vision bool // Contains images
+}
+
+// RequirementsOf inspects a request body in any client format (Claude, OpenAI, Codex, Gemini
+// and its CLI envelope)
+func RequirementsOf(body []byte) Requirements {
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return Requirements{}
+	}
+	if inner, ok := req["request"].(map[string]interface{}); ok {
+		req = inner
+	}
+
+	var needs Requirements
+	if tools, ok := req["tools"].([]interface{}); ok && len(tools) > 0 {
+		needs.Tools = true
+	}
+	for _, key := range []string{"messages", "input", "contents"} {
+		if containsImage(req[key]) {
+			needs.Vision = true
+			break
+		}
+	}
+	return needs
+}
+
+// Missing returns the first requirement caps are known not to meet, or "" if none
+func (r Requirements) Missing(caps domain.ModelCapabilities) string {
+	switch {
+	case r.Tools && Unsupported(caps.SupportsTools):
+		return "tools"
+	case r.Vision && Unsupported(caps.SupportsVision):
+		return "vision"
+	}
+	return ""
+}
+
+// containsImage walks message content looking for image parts of any format
+func containsImage(v interface{}) bool {
+	switch node := v.(type) {
+	case []interface{}:
+		for _, item := range node {
+			if containsImage(item) {
+				return true
+			}
+		}
+	case map[string]interface{}:
+		switch node["type"] {
+		case "image", "image_url", "input_image":
+			return true
+		}
+		if data, ok := node["inlineData"].(map[string]interface{}); ok {
+			mimeType, _ := data["mimeType"].(string)
+			if strings.HasPrefix(mimeType, "image/") {
+				return true
+			}
+		}
+		for _, key := range []string{"content", "parts"} {
+			if containsImage(node[key]) {
+				return true
+			}
+		}
+	}
+	return false
+}
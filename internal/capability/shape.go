@@ -0,0 +1,50 @@
+package capability
+
+import "encoding/json"
+
+// Shape describes the size and content of a request, for analytics
+type Shape struct {
+	Bytes    int  // Request body size
+	Messages int  // Conversation messages, including system/developer messages sent inline
+	Tools    int  // Tool (function) declarations
+	Image    bool // Contains images
+}
+
+// ShapeOf inspects a request body in any client format (Claude, OpenAI, Codex, Gemini
+// and its CLI envelope). Only Bytes is set if the body is not JSON.
+func ShapeOf(body []byte) Shape {
+	shape := Shape{Bytes: len(body)}
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return shape
+	}
+	if inner, ok := req["request"].(map[string]interface{}); ok {
+		req = inner
+	}
+
+	for _, key := range []string{"messages", "input", "contents"} {
+		if items, ok := req[key].([]interface{}); ok {
+			shape.Messages = len(items)
+			shape.Image = containsImage(items)
+			break
+		}
+		if _, ok := req[key].(string); ok {
+			// Responses API shorthand for a single user message
+			shape.Messages = 1
+			break
+		}
+	}
+
+	tools, _ := req["tools"].([]interface{})
+	for _, t := range tools {
+		// Gemini groups declarations in a single tool entry
+		if tool, ok := t.(map[string]interface{}); ok {
+			if decls, ok := tool["functionDeclarations"].([]interface{}); ok {
+				shape.Tools += len(decls)
+				continue
+			}
+		}
+		shape.Tools++
+	}
+	return shape
+}
@@ -0,0 +1,264 @@
+// Package claudesse emits Claude Messages API stream events.
+//
+// Emitter is a state machine that keeps the event sequence valid whatever order its
+// caller produces content in: message_start is sent first and exactly once, content
+// blocks are indexed consecutively and never overlap, deltas only go to an open block
+// of a matching type, and message_delta/message_stop close the message exactly once,
+// after which nothing more is emitted.
+package claudesse
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Content block types
+const (
+	BlockText          = "text"
+	BlockThinking      = "thinking"
+	BlockToolUse       = "tool_use"
+	BlockServerToolUse = "server_tool_use"
+)
+
+// deltaBlocks lists the block types each delta type may be sent to
+var deltaBlocks = map[string][]string{
+	"text_delta":       {BlockText},
+	"citations_delta":  {BlockText},
+	"thinking_delta":   {BlockThinking},
+	"signature_delta":  {BlockThinking},
+	"input_json_delta": {BlockToolUse, BlockServerToolUse},
+}
+
+// Emitter produces the Claude SSE events of one message
+type Emitter struct {
+	started   bool
+	stopped   bool
+	index     int    // Index of the open block, or of the next block if none is open
+	blockType string // Type of the open block, "" if none
+}
+
+// NewEmitter creates an emitter for a new message
+func NewEmitter() *Emitter {
+	return &Emitter{}
+}
+
+// Started reports whether message_start was sent
+func (e *Emitter) Started() bool {
+	return e.started
+}
+
+// Stopped reports whether the message was finished
+func (e *Emitter) Stopped() bool {
+	return e.stopped
+}
+
+// BlockType returns the type of the open content block, "" if none is open
+func (e *Emitter) BlockType() string {
+	return e.blockType
+}
+
+// BlockCount returns the number of content blocks started so far
+func (e *Emitter) BlockCount() int {
+	if e.blockType != "" {
+		return e.index + 1
+	}
+	return e.index
+}
+
+// MessageStart sends message_start with the given message. Required fields the
+// message lacks are filled in. It does nothing if the message was already started.
+func (e *Emitter) MessageStart(message map[string]interface{}) []byte {
+	if e.started || e.stopped {
+		return nil
+	}
+	e.started = true
+
+	msg := map[string]interface{}{
+		"id":            "msg_" + strconv.FormatInt(time.Now().UnixNano(), 36),
+		"type":          "message",
+		"role":          "assistant",
+		"content":       []interface{}{},
+		"stop_reason":   nil,
+		"stop_sequence": nil,
+	}
+	for k, v := range message {
+		if k == "id" && v == "" {
+			continue
+		}
+		msg[k] = v
+	}
+	return format("message_start", map[string]interface{}{
+		"type":    "message_start",
+		"message": msg,
+	})
+}
+
+// StartBlock opens a content block, closing the open one first. contentBlock must
+// have a "type".
+func (e *Emitter) StartBlock(contentBlock map[string]interface{}) []byte {
+	blockType, _ := contentBlock["type"].(string)
+	if e.stopped || blockType == "" {
+		return nil
+	}
+	output := e.MessageStart(nil)
+	output = append(output, e.EndBlock()...)
+	e.blockType = blockType
+	return append(output, format("content_block_start", map[string]interface{}{
+		"type":          "content_block_start",
+		"index":         e.index,
+		"content_block": contentBlock,
+	})...)
+}
+
+// EndBlock closes the open content block, if any
+func (e *Emitter) EndBlock() []byte {
+	if e.stopped || e.blockType == "" {
+		return nil
+	}
+	output := format("content_block_stop", map[string]interface{}{
+		"type":  "content_block_stop",
+		"index": e.index,
+	})
+	e.index++
+	e.blockType = ""
+	return output
+}
+
+// Delta sends a content_block_delta of deltaType with the given fields to the open
+// block. Deltas that don't fit the open block (or arrive with no block open) are
+// dropped rather than sent out of sequence.
+func (e *Emitter) Delta(deltaType string, fields map[string]interface{}) []byte {
+	if e.stopped || !e.accepts(deltaType) {
+		return nil
+	}
+	delta := map[string]interface{}{"type": deltaType}
+	for k, v := range fields {
+		delta[k] = v
+	}
+	return format("content_block_delta", map[string]interface{}{
+		"type":  "content_block_delta",
+		"index": e.index,
+		"delta": delta,
+	})
+}
+
+// accepts reports whether the open block takes deltas of deltaType
+func (e *Emitter) accepts(deltaType string) bool {
+	for _, blockType := range deltaBlocks[deltaType] {
+		if blockType == e.blockType {
+			return true
+		}
+	}
+	return false
+}
+
+// Text appends text to the open text block, opening one if needed
+func (e *Emitter) Text(text string) []byte {
+	var output []byte
+	if e.blockType != BlockText {
+		output = e.StartBlock(map[string]interface{}{"type": BlockText, "text": ""})
+	}
+	return append(output, e.Delta("text_delta", map[string]interface{}{"text": text})...)
+}
+
+// Thinking appends text to the open thinking block, opening one if needed
+func (e *Emitter) Thinking(text string) []byte {
+	var output []byte
+	if e.blockType != BlockThinking {
+		output = e.StartBlock(map[string]interface{}{"type": BlockThinking, "thinking": ""})
+	}
+	return append(output, e.Delta("thinking_delta", map[string]interface{}{"thinking": text})...)
+}
+
+// Signature sends the signature of the open thinking block
+func (e *Emitter) Signature(signature string) []byte {
+	return e.Delta("signature_delta", map[string]interface{}{"signature": signature})
+}
+
+// StartToolUse opens a tool_use block. Its input is sent with InputJSON.
+func (e *Emitter) StartToolUse(id, name string) []byte {
+	return e.StartBlock(map[string]interface{}{
+		"type":  BlockToolUse,
+		"id":    id,
+		"name":  name,
+		"input": map[string]interface{}{},
+	})
+}
+
+// InputJSON appends a fragment of the open tool_use block's input
+func (e *Emitter) InputJSON(partialJSON string) []byte {
+	return e.Delta("input_json_delta", map[string]interface{}{"partial_json": partialJSON})
+}
+
+// Blocks appends complete, pre-rendered content blocks. build renders them starting
+// at the given index and returns the events and the number of blocks.
+func (e *Emitter) Blocks(build func(index int) ([]byte, int)) []byte {
+	if e.stopped {
+		return nil
+	}
+	output := e.MessageStart(nil)
+	output = append(output, e.EndBlock()...)
+	events, count := build(e.index)
+	if count <= 0 {
+		return output
+	}
+	e.index += count
+	return append(output, events...)
+}
+
+// Finish closes the open block and ends the message with message_delta and
+// message_stop. An empty stopReason means end_turn; usage defaults to zero output
+// tokens. It does nothing once the message was finished, so it can be called again
+// as a safety net when the upstream stream ends.
+func (e *Emitter) Finish(stopReason string, usage map[string]interface{}) []byte {
+	return e.FinishAtSequence(stopReason, "", usage)
+}
+
+// FinishAtSequence is Finish reporting the stop sequence the message stopped at
+// ("" for none)
+func (e *Emitter) FinishAtSequence(stopReason, stopSequence string, usage map[string]interface{}) []byte {
+	if e.stopped {
+		return nil
+	}
+	if stopReason == "" {
+		stopReason = "end_turn"
+	}
+	if usage == nil {
+		usage = map[string]interface{}{"output_tokens": 0}
+	}
+
+	output := e.MessageStart(nil)
+	output = append(output, e.EndBlock()...)
+	var sequence interface{}
+	if stopSequence != "" {
+		sequence = stopSequence
+	}
+	output = append(output, format("message_delta", map[string]interface{}{
+		"type": "message_delta",
+		"delta": map[string]interface{}{
+			"stop_reason":   stopReason,
+			"stop_sequence": sequence,
+		},
+		"usage": usage,
+	})...)
+	output = append(output, format("message_stop", map[string]interface{}{"type": "message_stop"})...)
+	e.stopped = true
+	return output
+}
+
+// Ping returns a ping event. Clients skip pings wherever they occur; they only show the
+// stream is alive while the upstream produces no content.
+func Ping() []byte {
+	return format("ping", map[string]interface{}{"type": "ping"})
+}
+
+// format renders an SSE event
+func format(event string, data interface{}) []byte {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return nil
+	}
+	return []byte(fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload))
+}
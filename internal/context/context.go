@@ -3,6 +3,7 @@ package context
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/awsl-project/maxx/internal/domain"
 	"github.com/awsl-project/maxx/internal/event"
@@ -27,6 +28,13 @@ const (
 	CtxKeyIsStream           contextKey = "is_stream"
 	CtxKeyAPITokenID         contextKey = "api_token_id"
 	CtxKeyEventChan          contextKey = "event_chan"
+	CtxKeyPassthrough        contextKey = "passthrough"            // Stream needs no conversion and response capture is disabled
+	CtxKeyPinnedProvider     contextKey = "pinned_provider"        // Provider name/ID pinned by the client (x-maxx-provider or model@provider)
+	CtxKeyModelOverride      contextKey = "model_override"         // Upstream model forced by the client (x-maxx-model), bypasses model mapping
+	CtxKeyTrace              contextKey = "trace"                  // Record every transformation stage of the request (x-maxx-trace)
+	CtxKeyThoughtSignature   contextKey = "thought_signature_mode" // Route's thoughtSignature mode (Antigravity)
+	CtxKeyPingInterval       contextKey = "ping_interval"          // Route's interval of Claude ping events while upstream is silent
+	CtxKeyDiagnostic         contextKey = "diagnostic"             // Test/probe traffic from the admin UI, not counted towards cooldowns or failback
 )
 
 // Setters
@@ -206,3 +214,81 @@ func GetEventChan(ctx context.Context) domain.AdapterEventChan {
 	}
 	return nil
 }
+
+func WithPassthrough(ctx context.Context, passthrough bool) context.Context {
+	return context.WithValue(ctx, CtxKeyPassthrough, passthrough)
+}
+
+func GetPassthrough(ctx context.Context) bool {
+	if v, ok := ctx.Value(CtxKeyPassthrough).(bool); ok {
+		return v
+	}
+	return false
+}
+
+func WithPinnedProvider(ctx context.Context, provider string) context.Context {
+	return context.WithValue(ctx, CtxKeyPinnedProvider, provider)
+}
+
+func GetPinnedProvider(ctx context.Context) string {
+	if v, ok := ctx.Value(CtxKeyPinnedProvider).(string); ok {
+		return v
+	}
+	return ""
+}
+
+func WithModelOverride(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, CtxKeyModelOverride, model)
+}
+
+func GetModelOverride(ctx context.Context) string {
+	if v, ok := ctx.Value(CtxKeyModelOverride).(string); ok {
+		return v
+	}
+	return ""
+}
+
+func WithTrace(ctx context.Context, trace bool) context.Context {
+	return context.WithValue(ctx, CtxKeyTrace, trace)
+}
+
+func GetTrace(ctx context.Context) bool {
+	if v, ok := ctx.Value(CtxKeyTrace).(bool); ok {
+		return v
+	}
+	return false
+}
+
+func WithDiagnostic(ctx context.Context, diagnostic bool) context.Context {
+	return context.WithValue(ctx, CtxKeyDiagnostic, diagnostic)
+}
+
+func GetDiagnostic(ctx context.Context) bool {
+	if v, ok := ctx.Value(CtxKeyDiagnostic).(bool); ok {
+		return v
+	}
+	return false
+}
+
+func WithThoughtSignatureMode(ctx context.Context, mode domain.ThoughtSignatureMode) context.Context {
+	return context.WithValue(ctx, CtxKeyThoughtSignature, mode)
+}
+
+func GetThoughtSignatureMode(ctx context.Context) domain.ThoughtSignatureMode {
+	if v, ok := ctx.Value(CtxKeyThoughtSignature).(domain.ThoughtSignatureMode); ok {
+		return v
+	}
+	return domain.ThoughtSignatureUnset
+}
+
+func WithPingInterval(ctx context.Context, interval time.Duration) context.Context {
+	return context.WithValue(ctx, CtxKeyPingInterval, interval)
+}
+
+// GetPingInterval returns the interval of Claude ping events, 0 for none
+func GetPingInterval(ctx context.Context) time.Duration {
+	if v, ok := ctx.Value(CtxKeyPingInterval).(time.Duration); ok {
+		return v
+	}
+	return 0
+}
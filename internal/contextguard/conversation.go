@@ -0,0 +1,137 @@
+package contextguard
+
+import (
+	"encoding/json"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Conversation is a request body split into leading instructions and conversation turns.
+// A turn starts at a user message that is not a tool result, so cutting at a turn start
+// never separates tool calls from their results.
+type Conversation struct {
+	clientType domain.ClientType
+	root       map[string]interface{}
+	container  map[string]interface{}
+	key        string
+	pinned     []interface{}
+
+	// Items are the conversation messages after the leading system/developer messages
+	Items []interface{}
+	// TurnStarts are the indexes in Items where a turn starts
+	TurnStarts []int
+}
+
+// ParseConversation parses a request body of clientType; ok is false if the format isn't understood.
+func ParseConversation(clientType domain.ClientType, body []byte) (*Conversation, bool) {
+	key := messagesKey(clientType)
+	if key == "" {
+		return nil, false
+	}
+
+	var root map[string]interface{}
+	if err := json.Unmarshal(body, &root); err != nil {
+		return nil, false
+	}
+
+	container := root
+	if clientType == domain.ClientTypeGemini {
+		// Gemini CLI wraps the request in an envelope
+		if inner, ok := root["request"].(map[string]interface{}); ok {
+			container = inner
+		}
+	}
+	items, ok := container[key].([]interface{})
+	if !ok {
+		return nil, false
+	}
+
+	c := &Conversation{clientType: clientType, root: root, container: container, key: key}
+	for _, item := range items {
+		if len(c.Items) == 0 && isPinned(clientType, item) {
+			c.pinned = append(c.pinned, item)
+			continue
+		}
+		if isTurnStart(clientType, item) {
+			c.TurnStarts = append(c.TurnStarts, len(c.Items))
+		}
+		c.Items = append(c.Items, item)
+	}
+	return c, true
+}
+
+// estimateBase estimates everything except the conversation items
+func (c *Conversation) estimateBase() int {
+	saved := c.container[c.key]
+	c.container[c.key] = c.pinned
+	base := estimateValue(c.root)
+	c.container[c.key] = saved
+	return base
+}
+
+// Build returns the body with Items[:cut] removed and head (if any) inserted in their place.
+func (c *Conversation) Build(cut int, head ...interface{}) ([]byte, error) {
+	items := make([]interface{}, 0, len(c.pinned)+len(head)+len(c.Items)-cut)
+	items = append(items, c.pinned...)
+	items = append(items, head...)
+	items = append(items, c.Items[cut:]...)
+
+	saved := c.container[c.key]
+	c.container[c.key] = items
+	defer func() { c.container[c.key] = saved }()
+	return json.Marshal(c.root)
+}
+
+func messagesKey(clientType domain.ClientType) string {
+	switch clientType {
+	case domain.ClientTypeClaude, domain.ClientTypeOpenAI:
+		return "messages"
+	case domain.ClientTypeCodex:
+		return "input"
+	case domain.ClientTypeGemini:
+		return "contents"
+	}
+	return ""
+}
+
+// isPinned reports whether a leading item must never be dropped (instructions)
+func isPinned(clientType domain.ClientType, item interface{}) bool {
+	msg, _ := item.(map[string]interface{})
+	role, _ := msg["role"].(string)
+	switch clientType {
+	case domain.ClientTypeOpenAI, domain.ClientTypeCodex:
+		return role == "system" || role == "developer"
+	}
+	return false
+}
+
+// isTurnStart reports whether item is a user message that starts a new turn
+func isTurnStart(clientType domain.ClientType, item interface{}) bool {
+	msg, ok := item.(map[string]interface{})
+	if !ok || msg["role"] != "user" {
+		return false
+	}
+
+	switch clientType {
+	case domain.ClientTypeClaude:
+		blocks, _ := msg["content"].([]interface{})
+		for _, b := range blocks {
+			if block, ok := b.(map[string]interface{}); ok && block["type"] == "tool_result" {
+				return false
+			}
+		}
+		return true
+	case domain.ClientTypeCodex:
+		itemType, _ := msg["type"].(string)
+		return itemType == "" || itemType == "message"
+	case domain.ClientTypeGemini:
+		parts, _ := msg["parts"].([]interface{})
+		for _, p := range parts {
+			if part, ok := p.(map[string]interface{}); ok && part["functionResponse"] != nil {
+				return false
+			}
+		}
+		return true
+	}
+	return true
+}
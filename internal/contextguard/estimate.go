@@ -0,0 +1,65 @@
+package contextguard
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	// bytesPerToken is a deliberately simple heuristic (about 4 bytes of text per token)
+	bytesPerToken = 4
+	// imageTokens is charged per image instead of counting its base64 payload
+	imageTokens = 1600
+)
+
+// binaryKeys hold base64 payloads or opaque signatures that don't count as prompt text
+var binaryKeys = map[string]bool{
+	"data":             true, // Claude image source, Gemini inlineData
+	"signature":        true, // Claude thinking signature
+	"thoughtSignature": true, // Gemini thought signature
+}
+
+// EstimateTokens roughly estimates the prompt tokens of a request body in any client format.
+// It counts the bytes of all string values, charging a fixed amount per image
+// instead of its base64 payload.
+func EstimateTokens(body []byte) int {
+	var root interface{}
+	if err := json.Unmarshal(body, &root); err != nil {
+		return len(body) / bytesPerToken
+	}
+	return estimateValue(root)
+}
+
+func estimateValue(v interface{}) int {
+	var bytes, images int
+	walk(v, &bytes, &images)
+	return bytes/bytesPerToken + images*imageTokens
+}
+
+func walk(v interface{}, bytes, images *int) {
+	switch val := v.(type) {
+	case string:
+		if strings.HasPrefix(val, "data:image/") {
+			*images++
+			return
+		}
+		*bytes += len(val)
+	case []interface{}:
+		for _, item := range val {
+			walk(item, bytes, images)
+		}
+	case map[string]interface{}:
+		for key, item := range val {
+			if binaryKeys[key] {
+				if _, ok := item.(string); ok {
+					if key == "data" {
+						*images++
+					}
+					continue
+				}
+			}
+			*bytes += len(key)
+			walk(item, bytes, images)
+		}
+	}
+}
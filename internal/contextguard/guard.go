@@ -0,0 +1,75 @@
+package contextguard
+
+import (
+	"fmt"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Mode selects what the guard does with a prompt that exceeds the context window
+type Mode string
+
+const (
+	ModeOff        Mode = ""            // No checks
+	ModeReject     Mode = "reject"      // Reject with a client-format error
+	ModeDropOldest Mode = "drop_oldest" // Drop the oldest turns until the prompt fits, reject if it can't
+)
+
+// ParseMode parses the context_guard_mode setting; unknown values disable the guard
+func ParseMode(value string) Mode {
+	switch Mode(value) {
+	case ModeReject, ModeDropOldest:
+		return Mode(value)
+	}
+	return ModeOff
+}
+
+// Result is the outcome of a guard check
+type Result struct {
+	// Body is the (possibly truncated) request body to send upstream
+	Body []byte
+	// Estimated and Limit are the prompt estimate and the context window, in tokens
+	Estimated int
+	Limit     int
+	// Dropped is the number of messages removed by truncation
+	Dropped int
+}
+
+// OverflowError reports a prompt that doesn't fit the model's context window
+type OverflowError struct {
+	Model     string
+	Estimated int
+	Limit     int
+}
+
+func (e *OverflowError) Error() string {
+	return fmt.Sprintf("prompt is too long: about %d tokens, but %s accepts at most %d", e.Estimated, e.Model, e.Limit)
+}
+
+// Check estimates the prompt of body against the context window of model on a provider and
+// applies mode. Models with an unknown window always pass.
+func Check(mode Mode, clientType domain.ClientType, body []byte, providerID uint64, model, windowOverrides string) (*Result, error) {
+	result := &Result{Body: body}
+	if mode == ModeOff {
+		return result, nil
+	}
+
+	result.Limit = ContextWindow(providerID, model, windowOverrides)
+	if result.Limit <= 0 {
+		return result, nil
+	}
+	result.Estimated = EstimateTokens(body)
+	if result.Estimated <= result.Limit {
+		return result, nil
+	}
+
+	if mode == ModeDropOldest {
+		if truncated, dropped, ok := DropOldestTurns(clientType, body, result.Limit); ok {
+			result.Body = truncated
+			result.Dropped = dropped
+			result.Estimated = EstimateTokens(truncated)
+			return result, nil
+		}
+	}
+	return result, &OverflowError{Model: model, Estimated: result.Estimated, Limit: result.Limit}
+}
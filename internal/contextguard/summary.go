@@ -0,0 +1,114 @@
+package contextguard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// summaryPrefix marks the injected summary so it is recognizable in request logs
+const summaryPrefix = "[Summary of the earlier conversation]\n"
+
+// summaryAck is the assistant reply paired with the summary to keep roles alternating
+const summaryAck = "Understood. I'll continue from this summary."
+
+// transcriptKeys are the fields whose string values make up the readable transcript
+var transcriptKeys = map[string]bool{
+	"text": true, "content": true, "thinking": true, "name": true,
+	"arguments": true, "output": true, "input": true, "args": true, "response": true,
+}
+
+// Transcript renders Items[from:to] as plain text for summarization
+func (c *Conversation) Transcript(from, to int) string {
+	var sb strings.Builder
+	for _, item := range c.Items[from:to] {
+		msg, _ := item.(map[string]interface{})
+		role, _ := msg["role"].(string)
+		if role == "" {
+			role, _ = msg["type"].(string)
+		}
+		var parts []string
+		collectText(msg, &parts)
+		if len(parts) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "%s: %s\n\n", role, strings.Join(parts, "\n"))
+	}
+	return sb.String()
+}
+
+func collectText(v interface{}, parts *[]string) {
+	switch val := v.(type) {
+	case []interface{}:
+		for _, item := range val {
+			collectText(item, parts)
+		}
+	case map[string]interface{}:
+		for key, item := range val {
+			if binaryKeys[key] {
+				continue
+			}
+			if s, ok := item.(string); ok {
+				if transcriptKeys[key] && s != "" {
+					*parts = append(*parts, s)
+				}
+				continue
+			}
+			if key == "input" || key == "args" || key == "response" {
+				// Tool call arguments and results given as objects
+				if raw, err := json.Marshal(item); err == nil {
+					*parts = append(*parts, string(raw))
+				}
+				continue
+			}
+			collectText(item, parts)
+		}
+	}
+}
+
+// HeadHash identifies Items[:cut], so a summary can be reused while the head is unchanged
+func (c *Conversation) HeadHash(cut int) string {
+	raw, _ := json.Marshal(c.Items[:cut])
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// SummaryHead returns the messages that replace the summarized turns: the summary as a
+// user message followed by a short assistant acknowledgement.
+func SummaryHead(clientType domain.ClientType, summary string) []interface{} {
+	text := summaryPrefix + summary
+	switch clientType {
+	case domain.ClientTypeGemini:
+		return []interface{}{
+			map[string]interface{}{"role": "user", "parts": []interface{}{map[string]interface{}{"text": text}}},
+			map[string]interface{}{"role": "model", "parts": []interface{}{map[string]interface{}{"text": summaryAck}}},
+		}
+	case domain.ClientTypeCodex:
+		return []interface{}{
+			map[string]interface{}{"type": "message", "role": "user", "content": []interface{}{
+				map[string]interface{}{"type": "input_text", "text": text},
+			}},
+			map[string]interface{}{"type": "message", "role": "assistant", "content": []interface{}{
+				map[string]interface{}{"type": "output_text", "text": summaryAck},
+			}},
+		}
+	default:
+		return []interface{}{
+			map[string]interface{}{"role": "user", "content": text},
+			map[string]interface{}{"role": "assistant", "content": summaryAck},
+		}
+	}
+}
+
+// EstimateWith estimates the prompt when Items[:cut] are replaced by head
+func (c *Conversation) EstimateWith(cut int, head []interface{}) int {
+	total := c.estimateBase() + estimateValue(head)
+	for _, item := range c.Items[cut:] {
+		total += estimateValue(item)
+	}
+	return total
+}
@@ -0,0 +1,37 @@
+package contextguard
+
+import "github.com/awsl-project/maxx/internal/domain"
+
+// DropOldestTurns removes the oldest conversation turns until the estimated prompt fits
+// within limit tokens. System/developer messages and the latest turn are always kept.
+//
+// It returns the new body and the number of messages removed; ok is false if the request
+// can't be made to fit (or its format isn't understood).
+func DropOldestTurns(clientType domain.ClientType, body []byte, limit int) (result []byte, dropped int, ok bool) {
+	c, ok := ParseConversation(clientType, body)
+	if !ok || len(c.TurnStarts) < 2 {
+		return nil, 0, false
+	}
+
+	sizes := make([]int, len(c.Items))
+	total := c.estimateBase()
+	for i, item := range c.Items {
+		sizes[i] = estimateValue(item)
+		total += sizes[i]
+	}
+
+	cut := 0
+	for _, start := range c.TurnStarts[1:] {
+		for ; cut < start; cut++ {
+			total -= sizes[cut]
+		}
+		if total <= limit {
+			out, err := c.Build(cut)
+			if err != nil {
+				return nil, 0, false
+			}
+			return out, cut, true
+		}
+	}
+	return nil, 0, false
+}
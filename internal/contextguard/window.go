@@ -0,0 +1,45 @@
+// Package contextguard estimates prompt sizes before dispatch and keeps requests within
+// the target model's context window, either by rejecting them or by dropping old turns.
+package contextguard
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/capability"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// ContextWindow returns the context window of model on a provider (0 for any) in tokens, or 0
+// if unknown. overrides (the model_context_windows setting) take precedence over the
+// capability registry.
+func ContextWindow(providerID uint64, model string, overrides string) int {
+	if window := matchOverride(model, overrides); window > 0 {
+		return window
+	}
+	return capability.Default().Lookup(providerID, model).MaxContext
+}
+
+// matchOverride parses "pattern: tokens" lines and returns the first match.
+// Patterns support wildcards; blank lines and lines starting with "#" are ignored.
+func matchOverride(model, overrides string) int {
+	for _, line := range strings.Split(overrides, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		idx := strings.LastIndex(line, ":")
+		if idx <= 0 {
+			continue
+		}
+		pattern := strings.TrimSpace(line[:idx])
+		tokens, err := strconv.Atoi(strings.ReplaceAll(strings.TrimSpace(line[idx+1:]), "_", ""))
+		if err != nil || tokens <= 0 {
+			continue
+		}
+		if domain.MatchWildcard(pattern, model) {
+			return tokens
+		}
+	}
+	return 0
+}
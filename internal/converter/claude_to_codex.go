@@ -72,7 +72,7 @@ func (c *claudeToCodexRequest) Transform(body []byte, model string, stream bool)
 							Type:      "function_call",
 							ID:        id,
 							CallID:    id,
-							Name:      name,
+							Name:      SanitizeToolName(name),
 							Role:      "assistant",
 							Arguments: string(argJSON),
 						})
@@ -100,7 +100,7 @@ func (c *claudeToCodexRequest) Transform(body []byte, model string, stream bool)
 	for _, tool := range req.Tools {
 		codexReq.Tools = append(codexReq.Tools, CodexTool{
 			Type:        "function",
-			Name:        tool.Name,
+			Name:        SanitizeToolName(tool.Name),
 			Description: tool.Description,
 			Parameters:  tool.InputSchema,
 		})
@@ -143,7 +143,7 @@ func (c *claudeToCodexResponse) Transform(body []byte) ([]byte, error) {
 				Type:      "function_call",
 				ID:        block.ID,
 				CallID:    block.ID,
-				Name:      block.Name,
+				Name:      RestoreToolName(block.Name),
 				Arguments: string(argJSON),
 				Status:    "completed",
 			})
@@ -79,7 +79,7 @@ func (c *claudeToOpenAIRequest) Transform(body []byte, model string, stream bool
 						toolCalls = append(toolCalls, OpenAIToolCall{
 							ID:   id,
 							Type: "function",
-							Function: OpenAIFunctionCall{Name: name, Arguments: string(inputJSON)},
+							Function: OpenAIFunctionCall{Name: SanitizeToolName(name), Arguments: string(inputJSON)},
 						})
 					case "tool_result":
 						toolUseID, _ := m["tool_use_id"].(string)
@@ -110,7 +110,7 @@ func (c *claudeToOpenAIRequest) Transform(body []byte, model string, stream bool
 		openaiReq.Tools = append(openaiReq.Tools, OpenAITool{
 			Type: "function",
 			Function: OpenAIFunction{
-				Name:        tool.Name,
+				Name:        SanitizeToolName(tool.Name),
 				Description: tool.Description,
 				Parameters:  tool.InputSchema,
 			},
@@ -157,7 +157,7 @@ func (c *claudeToOpenAIResponse) Transform(body []byte) ([]byte, error) {
 			toolCalls = append(toolCalls, OpenAIToolCall{
 				ID:   block.ID,
 				Type: "function",
-				Function: OpenAIFunctionCall{Name: block.Name, Arguments: string(inputJSON)},
+				Function: OpenAIFunctionCall{Name: RestoreToolName(block.Name), Arguments: string(inputJSON)},
 			})
 		}
 	}
@@ -193,10 +193,11 @@ func (c *claudeToOpenAIResponse) TransformChunk(chunk []byte, state *TransformSt
 	events, remaining := ParseSSE(state.Buffer + string(chunk))
 	state.Buffer = remaining
 
+	sse := state.openAIEmitter()
 	var output []byte
 	for _, event := range events {
 		if event.Event == "done" {
-			output = append(output, FormatDone()...)
+			output = append(output, sse.Finish("")...)
 			continue
 		}
 
@@ -209,27 +210,22 @@ func (c *claudeToOpenAIResponse) TransformChunk(chunk []byte, state *TransformSt
 		case "message_start":
 			if claudeEvent.Message != nil {
 				state.MessageID = claudeEvent.Message.ID
+				state.Usage.InputTokens = claudeEvent.Message.Usage.InputTokens
+				sse.SetMessage(claudeEvent.Message.ID, claudeEvent.Message.Model)
 			}
-			chunk := OpenAIStreamChunk{
-				ID:      state.MessageID,
-				Object:  "chat.completion.chunk",
-				Created: time.Now().Unix(),
-				Choices: []OpenAIChoice{{
-					Index: 0,
-					Delta: &OpenAIMessage{Role: "assistant", Content: ""},
-				}},
-			}
-			output = append(output, FormatSSE("", chunk)...)
+			output = append(output, sse.Start()...)
 
 		case "content_block_start":
 			if claudeEvent.ContentBlock != nil {
 				state.CurrentBlockType = claudeEvent.ContentBlock.Type
 				state.CurrentIndex = claudeEvent.Index
 				if claudeEvent.ContentBlock.Type == "tool_use" {
-					state.ToolCalls[claudeEvent.Index] = &ToolCallState{
+					tc := &ToolCallState{
 						ID:   claudeEvent.ContentBlock.ID,
-						Name: claudeEvent.ContentBlock.Name,
+						Name: RestoreToolName(claudeEvent.ContentBlock.Name),
 					}
+					state.ToolCalls[claudeEvent.Index] = tc
+					output = append(output, sse.StartToolCall(claudeEvent.Index, tc.ID, tc.Name)...)
 				}
 			}
 
@@ -237,36 +233,11 @@ func (c *claudeToOpenAIResponse) TransformChunk(chunk []byte, state *TransformSt
 			if claudeEvent.Delta != nil {
 				switch claudeEvent.Delta.Type {
 				case "text_delta":
-					chunk := OpenAIStreamChunk{
-						ID:      state.MessageID,
-						Object:  "chat.completion.chunk",
-						Created: time.Now().Unix(),
-						Choices: []OpenAIChoice{{
-							Index: 0,
-							Delta: &OpenAIMessage{Content: claudeEvent.Delta.Text},
-						}},
-					}
-					output = append(output, FormatSSE("", chunk)...)
+					output = append(output, sse.Text(claudeEvent.Delta.Text)...)
 				case "input_json_delta":
-					if tc, ok := state.ToolCalls[state.CurrentIndex]; ok {
+					if tc, ok := state.ToolCalls[claudeEvent.Index]; ok {
 						tc.Arguments += claudeEvent.Delta.PartialJSON
-						chunk := OpenAIStreamChunk{
-							ID:      state.MessageID,
-							Object:  "chat.completion.chunk",
-							Created: time.Now().Unix(),
-							Choices: []OpenAIChoice{{
-								Index: 0,
-								Delta: &OpenAIMessage{
-									ToolCalls: []OpenAIToolCall{{
-										Index:    state.CurrentIndex,
-										ID:       tc.ID,
-										Type:     "function",
-										Function: OpenAIFunctionCall{Name: tc.Name, Arguments: claudeEvent.Delta.PartialJSON},
-									}},
-								},
-							}},
-						}
-						output = append(output, FormatSSE("", chunk)...)
+						output = append(output, sse.ToolArguments(claudeEvent.Index, claudeEvent.Delta.PartialJSON)...)
 					}
 				}
 			}
@@ -277,30 +248,21 @@ func (c *claudeToOpenAIResponse) TransformChunk(chunk []byte, state *TransformSt
 			}
 			if claudeEvent.Usage != nil {
 				state.Usage.OutputTokens = claudeEvent.Usage.OutputTokens
+				if claudeEvent.Usage.InputTokens > 0 {
+					state.Usage.InputTokens = claudeEvent.Usage.InputTokens
+				}
 			}
 
 		case "message_stop":
 			finishReason := "stop"
 			switch state.StopReason {
-			case "end_turn":
-				finishReason = "stop"
 			case "max_tokens":
 				finishReason = "length"
 			case "tool_use":
 				finishReason = "tool_calls"
 			}
-			chunk := OpenAIStreamChunk{
-				ID:      state.MessageID,
-				Object:  "chat.completion.chunk",
-				Created: time.Now().Unix(),
-				Choices: []OpenAIChoice{{
-					Index:        0,
-					Delta:        &OpenAIMessage{},
-					FinishReason: finishReason,
-				}},
-			}
-			output = append(output, FormatSSE("", chunk)...)
-			output = append(output, FormatDone()...)
+			sse.SetUsage(state.Usage.InputTokens, state.Usage.OutputTokens)
+			output = append(output, sse.Finish(finishReason)...)
 		}
 	}
 
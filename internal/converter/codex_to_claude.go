@@ -73,7 +73,7 @@ func (c *codexToClaudeRequest) Transform(body []byte, model string, stream bool)
 					Content: []ClaudeContentBlock{{
 						Type:  "tool_use",
 						ID:    id,
-						Name:  name,
+						Name:  SanitizeToolName(name),
 						Input: args,
 					}},
 				})
@@ -96,7 +96,7 @@ func (c *codexToClaudeRequest) Transform(body []byte, model string, stream bool)
 	// Convert tools
 	for _, tool := range req.Tools {
 		claudeReq.Tools = append(claudeReq.Tools, ClaudeTool{
-			Name:        tool.Name,
+			Name:        SanitizeToolName(tool.Name),
 			Description: tool.Description,
 			InputSchema: tool.Parameters,
 		})
@@ -138,7 +138,7 @@ func (c *codexToClaudeResponse) Transform(body []byte) ([]byte, error) {
 			claudeResp.Content = append(claudeResp.Content, ClaudeContentBlock{
 				Type:  "tool_use",
 				ID:    out.ID,
-				Name:  out.Name,
+				Name:  RestoreToolName(out.Name),
 				Input: args,
 			})
 		}
@@ -157,6 +157,7 @@ func (c *codexToClaudeResponse) TransformChunk(chunk []byte, state *TransformSta
 	events, remaining := ParseSSE(state.Buffer + string(chunk))
 	state.Buffer = remaining
 
+	sse := state.claudeEmitter()
 	var output []byte
 	for _, event := range events {
 		var codexEvent map[string]interface{}
@@ -171,58 +172,20 @@ func (c *codexToClaudeResponse) TransformChunk(chunk []byte, state *TransformSta
 			if resp, ok := codexEvent["response"].(map[string]interface{}); ok {
 				state.MessageID, _ = resp["id"].(string)
 			}
-			msgStart := map[string]interface{}{
-				"type": "message_start",
-				"message": map[string]interface{}{
-					"id":    state.MessageID,
-					"type":  "message",
-					"role":  "assistant",
-					"usage": map[string]int{"input_tokens": 0, "output_tokens": 0},
-				},
-			}
-			output = append(output, FormatSSE("message_start", msgStart)...)
-
-			blockStart := map[string]interface{}{
-				"type":  "content_block_start",
-				"index": 0,
-				"content_block": map[string]interface{}{
-					"type": "text",
-					"text": "",
-				},
-			}
-			output = append(output, FormatSSE("content_block_start", blockStart)...)
+			output = append(output, sse.MessageStart(map[string]interface{}{
+				"id":    state.MessageID,
+				"usage": map[string]int{"input_tokens": 0, "output_tokens": 0},
+			})...)
 
 		case "response.output_item.delta":
 			if delta, ok := codexEvent["delta"].(map[string]interface{}); ok {
-				if text, ok := delta["text"].(string); ok {
-					claudeDelta := map[string]interface{}{
-						"type":  "content_block_delta",
-						"index": 0,
-						"delta": map[string]interface{}{
-							"type": "text_delta",
-							"text": text,
-						},
-					}
-					output = append(output, FormatSSE("content_block_delta", claudeDelta)...)
+				if text, ok := delta["text"].(string); ok && text != "" {
+					output = append(output, sse.Text(text)...)
 				}
 			}
 
 		case "response.done":
-			blockStop := map[string]interface{}{
-				"type":  "content_block_stop",
-				"index": 0,
-			}
-			output = append(output, FormatSSE("content_block_stop", blockStop)...)
-
-			msgDelta := map[string]interface{}{
-				"type": "message_delta",
-				"delta": map[string]interface{}{
-					"stop_reason": "end_turn",
-				},
-				"usage": map[string]int{"output_tokens": 0},
-			}
-			output = append(output, FormatSSE("message_delta", msgDelta)...)
-			output = append(output, FormatSSE("message_stop", map[string]string{"type": "message_stop"})...)
+			output = append(output, sse.Finish("end_turn", nil)...)
 		}
 	}
 
@@ -16,6 +16,10 @@ type codexToGeminiRequest struct{}
 type codexToGeminiResponse struct{}
 
 func (c *codexToGeminiRequest) Transform(body []byte, model string, stream bool) ([]byte, error) {
+	return c.TransformForSession(body, model, stream, "")
+}
+
+func (c *codexToGeminiRequest) TransformForSession(body []byte, model string, stream bool, sessionID string) ([]byte, error) {
 	var req CodexRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		return nil, err
@@ -85,9 +89,11 @@ func (c *codexToGeminiRequest) Transform(body []byte, model string, stream bool)
 						Role: "model",
 						Parts: []GeminiPart{{
 							FunctionCall: &GeminiFunctionCall{
-								Name: name + "_" + callID,
+								Name: SanitizeToolName(name + "_" + callID),
 								Args: args,
 							},
+							// Codex clients don't keep thoughtSignature
+							ThoughtSignature: toolCallSignature(sessionID, callID),
 						}},
 					})
 				case "function_call_output":
@@ -99,7 +105,7 @@ func (c *codexToGeminiRequest) Transform(body []byte, model string, stream bool)
 						Role: "user",
 						Parts: []GeminiPart{{
 							FunctionResponse: &GeminiFunctionResponse{
-								Name:     funcName,
+								Name:     SanitizeToolName(funcName),
 								Response: map[string]interface{}{"result": output},
 							},
 						}},
@@ -115,7 +121,7 @@ func (c *codexToGeminiRequest) Transform(body []byte, model string, stream bool)
 		for _, tool := range req.Tools {
 			if tool.Type == "function" {
 				funcDecls = append(funcDecls, GeminiFunctionDecl{
-					Name:        tool.Name,
+					Name:        SanitizeToolName(tool.Name),
 					Description: tool.Description,
 					Parameters:  tool.Parameters,
 				})
@@ -176,7 +182,7 @@ func (c *codexToGeminiResponse) Transform(body []byte) ([]byte, error) {
 			if part.FunctionCall != nil {
 				argsJSON, _ := json.Marshal(part.FunctionCall.Args)
 				// Extract call_id from name if present
-				name := part.FunctionCall.Name
+				name := RestoreToolName(part.FunctionCall.Name)
 				callID := "call_" + time.Now().Format("20060102150405")
 				if idx := strings.LastIndex(name, "_"); idx > 0 {
 					callID = name[idx+1:]
@@ -224,10 +230,11 @@ func (c *codexToGeminiResponse) TransformChunk(chunk []byte, state *TransformSta
 			continue
 		}
 
-		var geminiChunk GeminiStreamChunk
-		if err := json.Unmarshal(event.Data, &geminiChunk); err != nil {
+		geminiChunk, ok := parseGeminiChunk(event.Data)
+		if !ok {
 			continue
 		}
+		captureThoughtSignatures(geminiChunk, state)
 
 		// Initialize on first chunk
 		if state.MessageID == "" {
@@ -265,12 +272,13 @@ func (c *codexToGeminiResponse) TransformChunk(chunk []byte, state *TransformSta
 				}
 				if part.FunctionCall != nil {
 					argsJSON, _ := json.Marshal(part.FunctionCall.Args)
-					name := part.FunctionCall.Name
+					name := RestoreToolName(part.FunctionCall.Name)
 					callID := "call_" + time.Now().Format("20060102150405")
 					if idx := strings.LastIndex(name, "_"); idx > 0 {
 						callID = name[idx+1:]
 						name = name[:idx]
 					}
+					cacheToolCallSignature(state, callID)
 					itemEvent := CodexStreamEvent{
 						Type: "response.output_item.added",
 						Item: &CodexOutput{
@@ -2,7 +2,6 @@ package converter
 
 import (
 	"encoding/json"
-	"time"
 
 	"github.com/awsl-project/maxx/internal/domain"
 )
@@ -70,7 +69,7 @@ func (c *codexToOpenAIRequest) Transform(body []byte, model string, stream bool)
 							ID:   id,
 							Type: "function",
 							Function: OpenAIFunctionCall{
-								Name:      name,
+								Name:      SanitizeToolName(name),
 								Arguments: args,
 							},
 						}},
@@ -93,7 +92,7 @@ func (c *codexToOpenAIRequest) Transform(body []byte, model string, stream bool)
 		openaiReq.Tools = append(openaiReq.Tools, OpenAITool{
 			Type: "function",
 			Function: OpenAIFunction{
-				Name:        tool.Name,
+				Name:        SanitizeToolName(tool.Name),
 				Description: tool.Description,
 				Parameters:  tool.Parameters,
 			},
@@ -136,7 +135,7 @@ func (c *codexToOpenAIResponse) Transform(body []byte) ([]byte, error) {
 				ID:   out.ID,
 				Type: "function",
 				Function: OpenAIFunctionCall{
-					Name:      out.Name,
+					Name:      RestoreToolName(out.Name),
 					Arguments: out.Arguments,
 				},
 			})
@@ -168,6 +167,7 @@ func (c *codexToOpenAIResponse) TransformChunk(chunk []byte, state *TransformSta
 	events, remaining := ParseSSE(state.Buffer + string(chunk))
 	state.Buffer = remaining
 
+	sse := state.openAIEmitter()
 	var output []byte
 	for _, event := range events {
 		var codexEvent map[string]interface{}
@@ -181,47 +181,27 @@ func (c *codexToOpenAIResponse) TransformChunk(chunk []byte, state *TransformSta
 		case "response.created":
 			if resp, ok := codexEvent["response"].(map[string]interface{}); ok {
 				state.MessageID, _ = resp["id"].(string)
+				model, _ := resp["model"].(string)
+				sse.SetMessage(state.MessageID, model)
 			}
-			openaiChunk := OpenAIStreamChunk{
-				ID:      state.MessageID,
-				Object:  "chat.completion.chunk",
-				Created: time.Now().Unix(),
-				Choices: []OpenAIChoice{{
-					Index: 0,
-					Delta: &OpenAIMessage{Role: "assistant", Content: ""},
-				}},
-			}
-			output = append(output, FormatSSE("", openaiChunk)...)
+			output = append(output, sse.Start()...)
 
 		case "response.output_item.delta":
 			if delta, ok := codexEvent["delta"].(map[string]interface{}); ok {
 				if text, ok := delta["text"].(string); ok {
-					openaiChunk := OpenAIStreamChunk{
-						ID:      state.MessageID,
-						Object:  "chat.completion.chunk",
-						Created: time.Now().Unix(),
-						Choices: []OpenAIChoice{{
-							Index: 0,
-							Delta: &OpenAIMessage{Content: text},
-						}},
-					}
-					output = append(output, FormatSSE("", openaiChunk)...)
+					output = append(output, sse.Text(text)...)
 				}
 			}
 
 		case "response.done":
-			openaiChunk := OpenAIStreamChunk{
-				ID:      state.MessageID,
-				Object:  "chat.completion.chunk",
-				Created: time.Now().Unix(),
-				Choices: []OpenAIChoice{{
-					Index:        0,
-					Delta:        &OpenAIMessage{},
-					FinishReason: "stop",
-				}},
+			if resp, ok := codexEvent["response"].(map[string]interface{}); ok {
+				if usage, ok := resp["usage"].(map[string]interface{}); ok {
+					inputTokens, _ := usage["input_tokens"].(float64)
+					outputTokens, _ := usage["output_tokens"].(float64)
+					sse.SetUsage(int(inputTokens), int(outputTokens))
+				}
 			}
-			output = append(output, FormatSSE("", openaiChunk)...)
-			output = append(output, FormatDone()...)
+			output = append(output, sse.Finish("stop")...)
 		}
 	}
 
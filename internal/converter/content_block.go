@@ -0,0 +1,43 @@
+package converter
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	geminiBlockReasonPattern   = regexp.MustCompile(`"blockReason"\s*:\s*"([A-Z_]+)"`)
+	geminiFinishReasonPattern  = regexp.MustCompile(`"finishReason"\s*:\s*"([A-Z_]+)"`)
+	claudeRefusalPattern       = regexp.MustCompile(`"stop_reason"\s*:\s*"refusal"`)
+	refusalNoticePattern       = regexp.MustCompile(`\(finishReason: ([A-Z_]+)\)`)
+	openAIContentFilterPattern = regexp.MustCompile(`"finish_reason"\s*:\s*"content_filter"`)
+)
+
+// ContentBlockReason returns the category of content an upstream's filters blocked, or ""
+// if the response was not blocked. It reads Gemini responses (promptFeedback.blockReason or
+// a blocking finishReason, also as wrapped by Antigravity), Claude refusals and OpenAI
+// content_filter finishes, streamed or not. Quoted keys inside generated text are escaped,
+// so they don't match.
+func ContentBlockReason(body string) string {
+	if m := geminiBlockReasonPattern.FindStringSubmatch(body); m != nil {
+		return m[1]
+	}
+	if strings.Contains(body, `"finishReason"`) {
+		for _, m := range geminiFinishReasonPattern.FindAllStringSubmatch(body, -1) {
+			if IsGeminiBlockReason(m[1]) {
+				return m[1]
+			}
+		}
+	}
+	if claudeRefusalPattern.MatchString(body) {
+		// Refusals converted from Gemini name the finish reason in their notice
+		if m := refusalNoticePattern.FindStringSubmatch(body); m != nil && IsGeminiBlockReason(m[1]) {
+			return m[1]
+		}
+		return "REFUSAL"
+	}
+	if openAIContentFilterPattern.MatchString(body) {
+		return "CONTENT_FILTER"
+	}
+	return ""
+}
@@ -0,0 +1,154 @@
+package converter
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// maxRawErrorMessage caps the message taken from an upstream body that is not recognized JSON
+const maxRawErrorMessage = 1024
+
+// geminiStatusTypes maps google.rpc.Code names to error types
+var geminiStatusTypes = map[string]string{
+	"INVALID_ARGUMENT":    ErrorTypeInvalidRequest,
+	"FAILED_PRECONDITION": ErrorTypeInvalidRequest,
+	"OUT_OF_RANGE":        ErrorTypeInvalidRequest,
+	"UNAUTHENTICATED":     ErrorTypeAuthentication,
+	"PERMISSION_DENIED":   ErrorTypePermission,
+	"NOT_FOUND":           ErrorTypeNotFound,
+	"RESOURCE_EXHAUSTED":  ErrorTypeRateLimit,
+	"UNAVAILABLE":         ErrorTypeOverloaded,
+	"INTERNAL":            ErrorTypeAPI,
+	"UNKNOWN":             ErrorTypeAPI,
+	"DEADLINE_EXCEEDED":   ErrorTypeAPI,
+}
+
+// openAIErrorTypes maps OpenAI error types and codes that differ from the Claude types
+var openAIErrorTypes = map[string]string{
+	"server_error":        ErrorTypeAPI,
+	"insufficient_quota":  ErrorTypeRateLimit,
+	"rate_limit_exceeded": ErrorTypeRateLimit,
+	"tokens":              ErrorTypeRateLimit,
+	"requests":            ErrorTypeRateLimit,
+	"invalid_api_key":     ErrorTypeAuthentication,
+	"model_not_found":     ErrorTypeNotFound,
+}
+
+// TranslateUpstreamError converts an upstream error response into the error format of clientType.
+// Bodies that are already in the client's format are returned unchanged.
+func TranslateUpstreamError(from, clientType domain.ClientType, status int, body []byte) (int, []byte) {
+	if from != "" && from == clientType {
+		return status, body
+	}
+	apiErr := ParseUpstreamError(status, body)
+	apiErr.Status = apiErr.StatusFor(clientType)
+	return apiErr.Status, ErrorBody(clientType, apiErr)
+}
+
+// ParseUpstreamError extracts an APIError from an upstream error body in any supported format
+// (Claude, OpenAI/Codex, Gemini, or a bare {"message": ...} object).
+func ParseUpstreamError(status int, body []byte) *APIError {
+	apiErr := &APIError{Status: status}
+
+	var root interface{}
+	if err := json.Unmarshal(body, &root); err == nil {
+		// Gemini sometimes wraps errors in an array
+		if arr, ok := root.([]interface{}); ok && len(arr) > 0 {
+			root = arr[0]
+		}
+		if obj, ok := root.(map[string]interface{}); ok {
+			parseErrorObject(obj, apiErr)
+		}
+	} else if msg := strings.TrimSpace(string(body)); msg != "" {
+		if len(msg) > maxRawErrorMessage {
+			msg = msg[:maxRawErrorMessage] + "..."
+		}
+		apiErr.Message = msg
+	}
+
+	if apiErr.Type == "" {
+		apiErr.Type = ErrorTypeForStatus(status)
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(status)
+	}
+	return apiErr
+}
+
+func parseErrorObject(obj map[string]interface{}, apiErr *APIError) {
+	errObj, ok := obj["error"].(map[string]interface{})
+	if !ok {
+		// {"error": "message"} or AWS style {"message": "...", "__type": "..."}
+		if msg, ok := obj["error"].(string); ok {
+			apiErr.Message = msg
+		} else if msg, ok := obj["message"].(string); ok {
+			apiErr.Message = msg
+		}
+		return
+	}
+
+	apiErr.Message, _ = errObj["message"].(string)
+
+	// Gemini: {"error": {"code": 400, "message": "...", "status": "INVALID_ARGUMENT", "details": [...]}}
+	if status, ok := errObj["status"].(string); ok {
+		apiErr.Type = geminiStatusTypes[status]
+		apiErr.Field = geminiFieldViolation(errObj["details"])
+		return
+	}
+
+	// Claude: {"type": "error", "error": {"type": "...", "message": "..."}}
+	// OpenAI: {"error": {"type": "...", "message": "...", "param": "...", "code": "..."}}
+	if code, ok := errObj["code"].(string); ok {
+		apiErr.Type = openAIErrorTypes[code]
+	}
+	if apiErr.Type == "" {
+		if errType, ok := errObj["type"].(string); ok {
+			if mapped, ok := openAIErrorTypes[errType]; ok {
+				apiErr.Type = mapped
+			} else if _, known := geminiStatuses[errType]; known {
+				apiErr.Type = errType
+			}
+		}
+	}
+	apiErr.Field, _ = errObj["param"].(string)
+}
+
+func geminiFieldViolation(details interface{}) string {
+	list, ok := details.([]interface{})
+	if !ok {
+		return ""
+	}
+	for _, d := range list {
+		detail, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		violations, ok := detail["fieldViolations"].([]interface{})
+		if !ok || len(violations) == 0 {
+			continue
+		}
+		if v, ok := violations[0].(map[string]interface{}); ok {
+			field, _ := v["field"].(string)
+			return field
+		}
+	}
+	return ""
+}
+
+// StatusFor returns the HTTP status code clientType uses for this error.
+// Claude reports overload as 529; everyone else uses 503.
+func (e *APIError) StatusFor(clientType domain.ClientType) int {
+	if e.Type == ErrorTypeOverloaded {
+		if clientType == domain.ClientTypeClaude {
+			return 529
+		}
+		return http.StatusServiceUnavailable
+	}
+	if e.Status < http.StatusBadRequest {
+		return http.StatusBadGateway
+	}
+	return e.Status
+}
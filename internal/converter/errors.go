@@ -0,0 +1,242 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Conversion stages
+const (
+	StageRequest  = "request"
+	StageResponse = "response"
+)
+
+// ConversionError describes a request or response that could not be converted between formats.
+// It matches domain.ErrFormatConversion with errors.Is.
+type ConversionError struct {
+	From   domain.ClientType
+	To     domain.ClientType
+	Stage  string // StageRequest or StageResponse
+	Field  string // Path of the offending field, e.g. "messages[2].content[0]" (empty if unknown)
+	Reason string
+	Err    error // Underlying error, if any
+}
+
+func (e *ConversionError) Error() string {
+	msg := fmt.Sprintf("failed to convert %s from %s to %s", e.Stage, e.From, e.To)
+	if e.Field != "" {
+		msg += fmt.Sprintf(": %s: %s", e.Field, e.Reason)
+	} else if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ConversionError) Is(target error) bool {
+	return target == domain.ErrFormatConversion
+}
+
+// APIError returns the error to report to the client.
+// Request failures are the client's fault (400); response failures are reported as 502.
+func (e *ConversionError) APIError() *APIError {
+	if e.Stage == StageResponse {
+		return &APIError{
+			Status:  http.StatusBadGateway,
+			Type:    ErrorTypeAPI,
+			Message: e.Error(),
+			Field:   e.Field,
+		}
+	}
+	return &APIError{
+		Status:  http.StatusBadRequest,
+		Type:    ErrorTypeInvalidRequest,
+		Message: e.Error(),
+		Field:   e.Field,
+	}
+}
+
+// fieldError reports a field that failed validation. Registry fills in From/To/Stage.
+func fieldError(field, format string, args ...interface{}) *ConversionError {
+	return &ConversionError{Field: field, Reason: fmt.Sprintf(format, args...)}
+}
+
+// wrapConversionError turns a transformer error into a *ConversionError,
+// extracting the offending field from JSON decoding errors where possible.
+func wrapConversionError(stage string, from, to domain.ClientType, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var convErr *ConversionError
+	if errors.As(err, &convErr) {
+		if convErr.Stage == "" {
+			convErr.Stage = stage
+		}
+		if convErr.From == "" {
+			convErr.From, convErr.To = from, to
+		}
+		return convErr
+	}
+
+	convErr = &ConversionError{From: from, To: to, Stage: stage, Reason: err.Error(), Err: err}
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		convErr.Field = typeErr.Field
+		convErr.Reason = fmt.Sprintf("expected %s, got %s", typeErr.Type, typeErr.Value)
+	case errors.As(err, &syntaxErr):
+		convErr.Reason = fmt.Sprintf("invalid JSON at offset %d: %v", syntaxErr.Offset, syntaxErr)
+	}
+	return convErr
+}
+
+// Claude error types, used as the canonical error classification for all client formats
+const (
+	ErrorTypeInvalidRequest  = "invalid_request_error"
+	ErrorTypeAuthentication  = "authentication_error"
+	ErrorTypePermission      = "permission_error"
+	ErrorTypeNotFound        = "not_found_error"
+	ErrorTypeRequestTooLarge = "request_too_large"
+	ErrorTypeRateLimit       = "rate_limit_error"
+	ErrorTypeAPI             = "api_error"
+	ErrorTypeOverloaded      = "overloaded_error"
+)
+
+// APIError is a client-facing error that can be rendered in any client's native error format.
+type APIError struct {
+	Status  int    // HTTP status code
+	Type    string // One of the ErrorType* constants
+	Message string
+	Field   string // Offending request field, if known
+}
+
+// ErrorTypeForStatus returns the error type matching an HTTP status code.
+func ErrorTypeForStatus(status int) string {
+	switch {
+	case status == http.StatusUnauthorized:
+		return ErrorTypeAuthentication
+	case status == http.StatusForbidden:
+		return ErrorTypePermission
+	case status == http.StatusNotFound:
+		return ErrorTypeNotFound
+	case status == http.StatusRequestEntityTooLarge:
+		return ErrorTypeRequestTooLarge
+	case status == http.StatusTooManyRequests:
+		return ErrorTypeRateLimit
+	case status == http.StatusServiceUnavailable || status == 529:
+		return ErrorTypeOverloaded
+	case status >= 400 && status < 500:
+		return ErrorTypeInvalidRequest
+	default:
+		return ErrorTypeAPI
+	}
+}
+
+// ErrorBody renders err in the native error JSON format of clientType.
+func ErrorBody(clientType domain.ClientType, err *APIError) []byte {
+	switch clientType {
+	case domain.ClientTypeOpenAI, domain.ClientTypeCodex:
+		return mustMarshal(openAIErrorBody(err))
+	case domain.ClientTypeGemini:
+		return mustMarshal(geminiErrorBody(err))
+	default:
+		return mustMarshal(claudeErrorBody(err))
+	}
+}
+
+// ErrorEvent renders err as a single SSE event in the native streaming format of clientType.
+func ErrorEvent(clientType domain.ClientType, err *APIError) []byte {
+	body := ErrorBody(clientType, err)
+	if clientType == domain.ClientTypeClaude {
+		return FormatSSE("error", body)
+	}
+	return FormatSSE("", body)
+}
+
+func claudeErrorBody(err *APIError) map[string]interface{} {
+	msg := err.Message
+	if err.Field != "" && !strings.Contains(msg, err.Field) {
+		msg = err.Field + ": " + msg
+	}
+	return map[string]interface{}{
+		"type": "error",
+		"error": map[string]interface{}{
+			"type":    err.Type,
+			"message": msg,
+		},
+	}
+}
+
+func openAIErrorBody(err *APIError) map[string]interface{} {
+	errType := err.Type
+	switch errType {
+	case ErrorTypeAPI, ErrorTypeOverloaded:
+		errType = "server_error"
+	case ErrorTypeRequestTooLarge:
+		errType = ErrorTypeInvalidRequest
+	}
+	var param interface{}
+	if err.Field != "" {
+		param = err.Field
+	}
+	var code interface{}
+	if err.Type == ErrorTypeRateLimit {
+		code = "rate_limit_exceeded"
+	}
+	return map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": err.Message,
+			"type":    errType,
+			"param":   param,
+			"code":    code,
+		},
+	}
+}
+
+// geminiStatuses maps error types to google.rpc.Code names
+var geminiStatuses = map[string]string{
+	ErrorTypeInvalidRequest:  "INVALID_ARGUMENT",
+	ErrorTypeAuthentication:  "UNAUTHENTICATED",
+	ErrorTypePermission:      "PERMISSION_DENIED",
+	ErrorTypeNotFound:        "NOT_FOUND",
+	ErrorTypeRequestTooLarge: "INVALID_ARGUMENT",
+	ErrorTypeRateLimit:       "RESOURCE_EXHAUSTED",
+	ErrorTypeAPI:             "INTERNAL",
+	ErrorTypeOverloaded:      "UNAVAILABLE",
+}
+
+func geminiErrorBody(err *APIError) map[string]interface{} {
+	status, ok := geminiStatuses[err.Type]
+	if !ok {
+		status = "INTERNAL"
+	}
+	body := map[string]interface{}{
+		"code":    err.Status,
+		"message": err.Message,
+		"status":  status,
+	}
+	if err.Field != "" {
+		body["details"] = []interface{}{
+			map[string]interface{}{
+				"@type": "type.googleapis.com/google.rpc.BadRequest",
+				"fieldViolations": []interface{}{
+					map[string]interface{}{
+						"field":       err.Field,
+						"description": err.Message,
+					},
+				},
+			},
+		}
+	}
+	return map[string]interface{}{"error": body}
+}
@@ -0,0 +1,57 @@
+package converter
+
+import "encoding/json"
+
+// GeminiHasOutput reports whether a Gemini response, or one chunk of a stream, carries
+// anything for the client: a part with content, a prompt block or a finish reason other
+// than STOP. A 200 response without any (no candidates, or only empty text parts and
+// thought signatures) is an empty completion that is worth retrying. Payloads that can't
+// be parsed count as output, so they are passed through as before.
+func GeminiHasOutput(payload []byte) bool {
+	var resp struct {
+		Candidates []struct {
+			Content struct {
+				Parts []map[string]json.RawMessage `json:"parts"`
+			} `json:"content"`
+			FinishReason string `json:"finishReason"`
+		} `json:"candidates"`
+		PromptFeedback struct {
+			BlockReason string `json:"blockReason"`
+		} `json:"promptFeedback"`
+	}
+	if err := json.Unmarshal(payload, &resp); err != nil {
+		return true
+	}
+	if resp.PromptFeedback.BlockReason != "" {
+		return true
+	}
+	for _, candidate := range resp.Candidates {
+		switch candidate.FinishReason {
+		case "", "STOP", "FINISH_REASON_UNSPECIFIED":
+		default:
+			return true
+		}
+		for _, part := range candidate.Content.Parts {
+			if geminiPartHasOutput(part) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func geminiPartHasOutput(part map[string]json.RawMessage) bool {
+	for key, value := range part {
+		switch key {
+		case "thought", "thoughtSignature":
+			continue
+		case "text":
+			var text string
+			if json.Unmarshal(value, &text) == nil && text == "" {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package converter
+
+import "strings"
+
+// geminiFinishNotices are the Gemini finish and prompt block reasons of content that was
+// stopped without an answer, with the text sent in place of a response that has none
+var geminiFinishNotices = map[string]string{
+	"SAFETY":                   "The response was blocked by safety filters.",
+	"RECITATION":               "The response was blocked for reciting source material.",
+	"BLOCKLIST":                "The response was blocked by the blocklist.",
+	"PROHIBITED_CONTENT":       "The response was blocked for prohibited content.",
+	"SPII":                     "The response was blocked for sensitive personal information.",
+	"IMAGE_SAFETY":             "Image generation was blocked by safety filters.",
+	"IMAGE_PROHIBITED_CONTENT": "Image generation was blocked for prohibited content.",
+	"IMAGE_RECITATION":         "Image generation was blocked for recitation.",
+	"OTHER":                    "The response was blocked.",
+	"IMAGE_OTHER":              "Image generation failed.",
+	"NO_IMAGE":                 "The model did not generate an image.",
+}
+
+// IsGeminiBlockReason reports whether a Gemini finish or prompt block reason means the
+// upstream's filters blocked the content. OTHER, IMAGE_OTHER and NO_IMAGE stop without an
+// answer too, but are not attributed to filters.
+func IsGeminiBlockReason(reason string) bool {
+	switch reason {
+	case "OTHER", "IMAGE_OTHER", "NO_IMAGE":
+		return false
+	}
+	return geminiFinishNotices[reason] != ""
+}
+
+// ClaudeStopReasonForGemini maps a Gemini finish reason to a Claude stop_reason. Blocked
+// responses become "refusal", as Anthropic reports its own safety stops. Gemini reports
+// STOP both for natural stops and stop sequences, so stop sequences are detected from the
+// text (see FindStopSequence).
+func ClaudeStopReasonForGemini(finishReason string, hasToolUse bool) string {
+	switch {
+	case hasToolUse:
+		return "tool_use"
+	case finishReason == "MAX_TOKENS":
+		return "max_tokens"
+	case IsGeminiBlockReason(finishReason):
+		return "refusal"
+	}
+	return "end_turn"
+}
+
+// GeminiFinishNotice returns the text explaining why a response stopped without an
+// answer, "" if the finish reason needs no explanation
+func GeminiFinishNotice(reason string) string {
+	if msg := geminiFinishNotices[reason]; msg != "" {
+		return msg + " (finishReason: " + reason + ")"
+	}
+	return ""
+}
+
+// geminiPromptBlockNotice returns the text explaining a blocked prompt
+func geminiPromptBlockNotice(feedback *GeminiPromptFeedback) string {
+	return "The prompt was blocked by the upstream provider (blockReason: " + feedback.BlockReason + ")"
+}
+
+// FindStopSequence returns the index of the earliest stop sequence in text and the
+// sequence, or -1. Gemini usually leaves a matched sequence out of the text, but backends
+// that ignore stopSequences don't, and the text is then cut there as Anthropic does.
+func FindStopSequence(text string, sequences []string) (int, string) {
+	best, match := -1, ""
+	for _, seq := range sequences {
+		if seq == "" {
+			continue
+		}
+		if idx := strings.Index(text, seq); idx >= 0 && (best < 0 || idx < best) {
+			best, match = idx, seq
+		}
+	}
+	return best, match
+}
+
+// stopSequenceHoldback returns the length of the longest tail of text that is the start of
+// a stop sequence, which a stream must hold back until the next text decides it
+func stopSequenceHoldback(text string, sequences []string) int {
+	hold := 0
+	for _, seq := range sequences {
+		for n := min(len(seq)-1, len(text)); n > hold; n-- {
+			if strings.HasSuffix(text, seq[:n]) {
+				hold = n
+				break
+			}
+		}
+	}
+	return hold
+}
+
+// StreamStopText splits streamed text (prefixed with the text held back before) at the
+// stop sequences: out can be sent, held is a tail that may be the start of a stop sequence
+// and waits for the next text, and seq is set when the text reached one
+func StreamStopText(text string, sequences []string) (out, held, seq string) {
+	if idx, seq := FindStopSequence(text, sequences); idx >= 0 {
+		return text[:idx], "", seq
+	}
+	hold := stopSequenceHoldback(text, sequences)
+	return text[:len(text)-hold], text[len(text)-hold:], ""
+}
+
+// streamStopText returns the part of a streamed text delta that can be sent, holding back
+// a tail that may be the start of a stop sequence; seq is set when the text reached one
+func (s *TransformState) streamStopText(text string) (out, seq string) {
+	out, s.stopHeld, seq = StreamStopText(s.stopHeld+text, s.StopSequences)
+	return out, seq
+}
+
+// cutAtStopSequence truncates a response at the earliest stop sequence in its text
+// blocks, dropping the blocks after it. It returns the sequence, or "" if none occurs.
+func cutAtStopSequence(resp *ClaudeResponse, sequences []string) string {
+	if len(sequences) == 0 {
+		return ""
+	}
+	for i := range resp.Content {
+		block := &resp.Content[i]
+		if block.Type != "text" {
+			continue
+		}
+		if idx, seq := FindStopSequence(block.Text, sequences); idx >= 0 {
+			block.Text = block.Text[:idx]
+			resp.Content = resp.Content[:i+1]
+			if block.Text == "" {
+				resp.Content = resp.Content[:i]
+			}
+			return seq
+		}
+	}
+	return ""
+}
+
+func hasTextBlock(blocks []ClaudeContentBlock) bool {
+	for _, b := range blocks {
+		if b.Type == "text" && b.Text != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/awsl-project/maxx/internal/claudesse"
 	"github.com/awsl-project/maxx/internal/domain"
 )
 
@@ -151,7 +152,7 @@ func (c *geminiToClaudeRequest) Transform(body []byte, model string, stream bool
 				blocks = append(blocks, ClaudeContentBlock{
 					Type:  "tool_use",
 					ID:    fmt.Sprintf("call_%d", toolCallCounter),
-					Name:  part.FunctionCall.Name,
+					Name:  SanitizeToolName(part.FunctionCall.Name),
 					Input: part.FunctionCall.Args,
 				})
 			}
@@ -178,7 +179,7 @@ func (c *geminiToClaudeRequest) Transform(body []byte, model string, stream bool
 	for _, tool := range req.Tools {
 		for _, decl := range tool.FunctionDeclarations {
 			claudeReq.Tools = append(claudeReq.Tools, ClaudeTool{
-				Name:        decl.Name,
+				Name:        SanitizeToolName(decl.Name),
 				Description: decl.Description,
 				InputSchema: decl.Parameters,
 			})
@@ -189,6 +190,10 @@ func (c *geminiToClaudeRequest) Transform(body []byte, model string, stream bool
 }
 
 func (c *geminiToClaudeResponse) Transform(body []byte) ([]byte, error) {
+	return c.TransformWithOptions(body, ResponseOptions{})
+}
+
+func (c *geminiToClaudeResponse) TransformWithOptions(body []byte, opts ResponseOptions) ([]byte, error) {
 	var resp GeminiResponse
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, err
@@ -227,34 +232,51 @@ func (c *geminiToClaudeResponse) Transform(body []byte) ([]byte, error) {
 					Text: part.Text,
 				})
 			}
+			if part.InlineData != nil && part.InlineData.Data != "" {
+				claudeResp.Content = append(claudeResp.Content, InlineImageBlock(part.InlineData.MimeType, part.InlineData.Data))
+			}
 			if part.FunctionCall != nil {
 				hasToolUse = true
 				toolCallCounter++
 				// Apply argument remapping for Claude Code compatibility
 				args := part.FunctionCall.Args
 				remapFunctionCallArgs(part.FunctionCall.Name, args)
+				id := part.FunctionCall.ID
+				if id == "" {
+					id = fmt.Sprintf("call_%d", toolCallCounter)
+				}
+				id = GlobalToolCallStore().SanitizeID(opts.SessionID, id)
+				name := RestoreToolName(part.FunctionCall.Name)
+				GlobalToolCallStore().Remember(opts.SessionID, id, name)
 				claudeResp.Content = append(claudeResp.Content, ClaudeContentBlock{
 					Type:  "tool_use",
-					ID:    fmt.Sprintf("call_%d", toolCallCounter),
-					Name:  part.FunctionCall.Name,
+					ID:    id,
+					Name:  name,
 					Input: args,
 				})
 			}
 		}
 
 		// Map finish reason
-		switch candidate.FinishReason {
-		case "STOP":
-			if hasToolUse {
-				claudeResp.StopReason = "tool_use"
-			} else {
-				claudeResp.StopReason = "end_turn"
+		claudeResp.StopReason = ClaudeStopReasonForGemini(candidate.FinishReason, hasToolUse)
+		if !hasToolUse {
+			if seq := cutAtStopSequence(&claudeResp, opts.StopSequences); seq != "" {
+				claudeResp.StopReason = "stop_sequence"
+				claudeResp.StopSequence = seq
 			}
-		case "MAX_TOKENS":
-			claudeResp.StopReason = "max_tokens"
-		default:
-			claudeResp.StopReason = "end_turn"
 		}
+		if claudeResp.StopReason == "refusal" && !hasTextBlock(claudeResp.Content) {
+			claudeResp.Content = append(claudeResp.Content, ClaudeContentBlock{
+				Type: "text",
+				Text: GeminiFinishNotice(candidate.FinishReason),
+			})
+		}
+
+		applyGrounding(&claudeResp, candidate.GroundingMetadata)
+	} else if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		// The prompt itself was blocked: no candidates are generated
+		claudeResp.StopReason = "refusal"
+		claudeResp.Content = []ClaudeContentBlock{{Type: "text", Text: geminiPromptBlockNotice(resp.PromptFeedback)}}
 	}
 
 	return json.Marshal(claudeResp)
@@ -264,97 +286,227 @@ func (c *geminiToClaudeResponse) TransformChunk(chunk []byte, state *TransformSt
 	events, remaining := ParseSSE(state.Buffer + string(chunk))
 	state.Buffer = remaining
 
+	sse := state.claudeEmitter()
 	var output []byte
 	for _, event := range events {
-		var geminiChunk GeminiStreamChunk
-		if err := json.Unmarshal(event.Data, &geminiChunk); err != nil {
+		geminiChunk, ok := parseGeminiChunk(event.Data)
+		if !ok {
 			continue
 		}
+		captureThoughtSignatures(geminiChunk, state)
+
+		if geminiChunk.UsageMetadata != nil {
+			state.Usage.InputTokens = geminiChunk.UsageMetadata.PromptTokenCount
+			state.Usage.OutputTokens = geminiChunk.UsageMetadata.CandidatesTokenCount
+		}
 
 		// First chunk - send message_start
 		if state.MessageID == "" {
 			state.MessageID = "msg_gemini"
-			msgStart := map[string]interface{}{
-				"type": "message_start",
-				"message": map[string]interface{}{
-					"id":    state.MessageID,
-					"type":  "message",
-					"role":  "assistant",
-					"usage": map[string]int{"input_tokens": 0, "output_tokens": 0},
-				},
+			output = append(output, sse.MessageStart(map[string]interface{}{
+				"id":    state.MessageID,
+				"usage": map[string]int{"input_tokens": state.Usage.InputTokens, "output_tokens": 0},
+			})...)
+		}
+
+		if sse.Stopped() {
+			// Already finished at a stop sequence
+			continue
+		}
+		if len(geminiChunk.Candidates) == 0 {
+			if fb := geminiChunk.PromptFeedback; fb != nil && fb.BlockReason != "" {
+				output = append(output, sse.Text(geminiPromptBlockNotice(fb))...)
+				output = append(output, sse.Finish("refusal", map[string]interface{}{"output_tokens": 0})...)
+			}
+			continue
+		}
+		candidate := geminiChunk.Candidates[0]
+		stopSequence := ""
+		for _, part := range candidate.Content.Parts {
+			if part.FunctionCall == nil {
+				output = append(output, finishStreamedToolUse(sse, state, nil)...)
+			}
+			// Handle thinking blocks (thought: true)
+			if part.Thought && part.Text != "" {
+				output = append(output, sse.Thinking(part.Text)...)
+				continue
+			}
+			text := part.Text
+			if text != "" && len(state.StopSequences) > 0 && len(state.ToolCalls) == 0 {
+				text, stopSequence = state.streamStopText(text)
+			}
+			if part.InlineData != nil && part.InlineData.Data != "" && stopSequence == "" {
+				text += InlineImageMarkdown(part.InlineData.MimeType, part.InlineData.Data)
+			}
+			if text != "" {
+				output = append(output, sse.Text(text)...)
 			}
-			output = append(output, FormatSSE("message_start", msgStart)...)
-
-			blockStart := map[string]interface{}{
-				"type":  "content_block_start",
-				"index": 0,
-				"content_block": map[string]interface{}{
-					"type": "text",
-					"text": "",
-				},
+			if stopSequence != "" {
+				break
+			}
+			if part.FunctionCall != nil {
+				output = append(output, geminiToolUseEvents(sse, part.FunctionCall, state)...)
 			}
-			output = append(output, FormatSSE("content_block_start", blockStart)...)
+		}
+		if stopSequence != "" {
+			usage := map[string]interface{}{"output_tokens": state.Usage.OutputTokens}
+			output = append(output, sse.FinishAtSequence("stop_sequence", stopSequence, usage)...)
+			continue
 		}
 
-		if len(geminiChunk.Candidates) > 0 {
-			candidate := geminiChunk.Candidates[0]
-			for _, part := range candidate.Content.Parts {
-				// Handle thinking blocks (thought: true)
-				if part.Thought && part.Text != "" {
-					// Send thinking content as thinking_delta
-					delta := map[string]interface{}{
-						"type":  "content_block_delta",
-						"index": 0,
-						"delta": map[string]interface{}{
-							"type":     "thinking_delta",
-							"thinking": part.Text,
-						},
-					}
-					output = append(output, FormatSSE("content_block_delta", delta)...)
-					continue
-				}
-				if part.Text != "" {
-					delta := map[string]interface{}{
-						"type":  "content_block_delta",
-						"index": 0,
-						"delta": map[string]interface{}{
-							"type": "text_delta",
-							"text": part.Text,
-						},
-					}
-					output = append(output, FormatSSE("content_block_delta", delta)...)
+		state.Grounding = MergeGroundingMetadata(state.Grounding, candidate.GroundingMetadata)
+
+		if candidate.FinishReason != "" {
+			output = append(output, finishStreamedToolUse(sse, state, nil)...)
+			// Text held back for a possible stop sequence that did not come
+			if state.stopHeld != "" {
+				output = append(output, sse.Text(state.stopHeld)...)
+				state.stopHeld = ""
+			}
+			usage := map[string]interface{}{"output_tokens": state.Usage.OutputTokens}
+			grounding := state.Grounding
+			output = append(output, sse.Blocks(func(index int) ([]byte, int) {
+				events, count := WebSearchStreamEvents(grounding, index)
+				if count > 0 {
+					usage["server_tool_use"] = ClaudeServerToolUsage{WebSearchRequests: 1}
 				}
+				return events, count
+			})...)
+
+			stopReason := ClaudeStopReasonForGemini(candidate.FinishReason, len(state.ToolCalls) > 0)
+			if stopReason == "refusal" && sse.BlockCount() == 0 {
+				output = append(output, sse.Text(GeminiFinishNotice(candidate.FinishReason))...)
 			}
+			output = append(output, sse.Finish(stopReason, usage)...)
+		}
+	}
 
-			if candidate.FinishReason != "" {
-				blockStop := map[string]interface{}{
-					"type":  "content_block_stop",
-					"index": 0,
-				}
-				output = append(output, FormatSSE("content_block_stop", blockStop)...)
+	return output, nil
+}
 
-				stopReason := "end_turn"
-				if candidate.FinishReason == "MAX_TOKENS" {
-					stopReason = "max_tokens"
-				}
+// streamedToolCall tracks a tool_use block whose input is forwarded as Gemini streams it
+type streamedToolCall struct {
+	name    string
+	encoder *PartialArgsEncoder
+	written bool
+	// Tools whose args are remapped can't be forwarded piecemeal: their JSON is buffered and
+	// sent remapped once the call is complete
+	remap  bool
+	buffer strings.Builder
+}
 
-				msgDelta := map[string]interface{}{
-					"type": "message_delta",
-					"delta": map[string]interface{}{
-						"stop_reason": stopReason,
-					},
-					"usage": map[string]int{"output_tokens": state.Usage.OutputTokens},
-				}
-				output = append(output, FormatSSE("message_delta", msgDelta)...)
-				output = append(output, FormatSSE("message_stop", map[string]string{"type": "message_stop"})...)
-			}
+// geminiToolUseEvents sends a Gemini function call as a tool_use block. Calls whose args
+// are streamed as partial args are forwarded fragment by fragment.
+func geminiToolUseEvents(sse *claudesse.Emitter, fc *GeminiFunctionCall, state *TransformState) []byte {
+	if state.streamedCall != nil || fc.WillContinue || len(fc.PartialArgs) > 0 {
+		return geminiStreamedToolUseEvents(sse, fc, state)
+	}
+	output := startGeminiToolUse(sse, fc, state)
+	args := fc.Args
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	remapFunctionCallArgs(fc.Name, args)
+	argsJSON, _ := json.Marshal(args)
+	output = append(output, sse.InputJSON(string(argsJSON))...)
+	return append(output, sse.EndBlock()...)
+}
+
+// startGeminiToolUse opens the tool_use block of a function call
+func startGeminiToolUse(sse *claudesse.Emitter, fc *GeminiFunctionCall, state *TransformState) []byte {
+	if state.ToolCalls == nil {
+		state.ToolCalls = make(map[int]*ToolCallState)
+	}
+	index := len(state.ToolCalls)
+	id := fc.ID
+	if id == "" {
+		id = fmt.Sprintf("call_%d", index+1)
+	}
+	id = GlobalToolCallStore().SanitizeID(state.SessionID, id)
+	name := RestoreToolName(fc.Name)
+	GlobalToolCallStore().Remember(state.SessionID, id, name)
+	cacheToolCallSignature(state, id)
+	state.ToolCalls[index] = &ToolCallState{ID: id, Name: name}
+	return sse.StartToolUse(id, name)
+}
+
+// geminiStreamedToolUseEvents forwards a function call whose args Gemini streams as partial
+// args: the tool_use block is opened on the first fragment and every fragment is sent as an
+// input_json_delta as soon as it arrives. The call ends with a fragment without willContinue.
+func geminiStreamedToolUseEvents(sse *claudesse.Emitter, fc *GeminiFunctionCall, state *TransformState) []byte {
+	var output []byte
+
+	// A named call while another one is open starts a new call
+	if state.streamedCall != nil && fc.Name != "" && fc.Name != state.streamedCall.name {
+		output = append(output, finishStreamedToolUse(sse, state, nil)...)
+	}
+
+	if state.streamedCall == nil {
+		output = append(output, startGeminiToolUse(sse, fc, state)...)
+		state.streamedCall = &streamedToolCall{
+			name:    fc.Name,
+			encoder: NewPartialArgsEncoder(),
+			remap:   argsRemapped(fc.Name),
 		}
+	}
 
-		if geminiChunk.UsageMetadata != nil {
-			state.Usage.InputTokens = geminiChunk.UsageMetadata.PromptTokenCount
-			state.Usage.OutputTokens = geminiChunk.UsageMetadata.CandidatesTokenCount
+	call := state.streamedCall
+	for _, arg := range fc.PartialArgs {
+		fragment := call.encoder.Write(arg)
+		if fragment == "" {
+			continue
 		}
+		call.written = true
+		if call.remap {
+			call.buffer.WriteString(fragment)
+			continue
+		}
+		output = append(output, sse.InputJSON(fragment)...)
 	}
 
-	return output, nil
+	if !fc.WillContinue {
+		output = append(output, finishStreamedToolUse(sse, state, fc.Args)...)
+	}
+	return output
+}
+
+// finishStreamedToolUse completes the open streamed function call, if any. args are the
+// complete args sent with the final fragment, used when no partial args were streamed.
+func finishStreamedToolUse(sse *claudesse.Emitter, state *TransformState, args map[string]interface{}) []byte {
+	call := state.streamedCall
+	if call == nil {
+		return nil
+	}
+	state.streamedCall = nil
+
+	var argsJSON string
+	switch {
+	case !call.written && args != nil:
+		remapFunctionCallArgs(call.name, args)
+		data, _ := json.Marshal(args)
+		argsJSON = string(data)
+	case call.remap:
+		call.buffer.WriteString(call.encoder.Close())
+		var buffered map[string]interface{}
+		if err := json.Unmarshal([]byte(call.buffer.String()), &buffered); err != nil {
+			buffered = map[string]interface{}{}
+		}
+		remapFunctionCallArgs(call.name, buffered)
+		data, _ := json.Marshal(buffered)
+		argsJSON = string(data)
+	default:
+		argsJSON = call.encoder.Close()
+	}
+
+	output := sse.InputJSON(argsJSON)
+	return append(output, sse.EndBlock()...)
+}
+
+// argsRemapped reports whether remapFunctionCallArgs may change the args of a tool
+func argsRemapped(toolName string) bool {
+	switch strings.ToLower(toolName) {
+	case "grep", "glob", "read", "ls":
+		return true
+	}
+	return false
 }
@@ -75,7 +75,7 @@ func (c *geminiToCodexRequest) Transform(body []byte, model string, stream bool)
 				}
 				inputItems = append(inputItems, map[string]interface{}{
 					"type":      "function_call",
-					"name":      name,
+					"name":      SanitizeToolName(name),
 					"call_id":   callID,
 					"arguments": string(argsJSON),
 				})
@@ -130,7 +130,7 @@ skipInputItems:
 		for _, funcDecl := range tool.FunctionDeclarations {
 			codexReq.Tools = append(codexReq.Tools, CodexTool{
 				Type:        "function",
-				Name:        funcDecl.Name,
+				Name:        SanitizeToolName(funcDecl.Name),
 				Description: funcDecl.Description,
 				Parameters:  funcDecl.Parameters,
 			})
@@ -186,9 +186,9 @@ func (c *geminiToCodexResponse) Transform(body []byte) ([]byte, error) {
 			var args map[string]interface{}
 			json.Unmarshal([]byte(out.Arguments), &args)
 			// Embed call_id in name for round-trip
-			name := out.Name
+			name := RestoreToolName(out.Name)
 			if out.CallID != "" {
-				name = out.Name + "_" + out.CallID
+				name += "_" + out.CallID
 			}
 			parts = append(parts, GeminiPart{
 				FunctionCall: &GeminiFunctionCall{
@@ -262,9 +262,9 @@ func (c *geminiToCodexResponse) TransformChunk(chunk []byte, state *TransformSta
 			if codexEvent.Item != nil && codexEvent.Item.Type == "function_call" {
 				var args map[string]interface{}
 				json.Unmarshal([]byte(codexEvent.Item.Arguments), &args)
-				name := codexEvent.Item.Name
+				name := RestoreToolName(codexEvent.Item.Name)
 				if codexEvent.Item.CallID != "" {
-					name = codexEvent.Item.Name + "_" + codexEvent.Item.CallID
+					name += "_" + codexEvent.Item.CallID
 				}
 				geminiChunk := GeminiStreamChunk{
 					Candidates: []GeminiCandidate{{
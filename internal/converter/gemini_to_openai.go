@@ -2,9 +2,11 @@ package converter
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/openaisse"
 )
 
 func init() {
@@ -73,7 +75,7 @@ func (c *geminiToOpenAIRequest) Transform(body []byte, model string, stream bool
 					ID:   "call_" + part.FunctionCall.Name,
 					Type: "function",
 					Function: OpenAIFunctionCall{
-						Name:      part.FunctionCall.Name,
+						Name:      SanitizeToolName(part.FunctionCall.Name),
 						Arguments: string(argsJSON),
 					},
 				})
@@ -107,7 +109,7 @@ func (c *geminiToOpenAIRequest) Transform(body []byte, model string, stream bool
 			openaiReq.Tools = append(openaiReq.Tools, OpenAITool{
 				Type: "function",
 				Function: OpenAIFunction{
-					Name:        decl.Name,
+					Name:        SanitizeToolName(decl.Name),
 					Description: decl.Description,
 					Parameters:  decl.Parameters,
 				},
@@ -119,6 +121,12 @@ func (c *geminiToOpenAIRequest) Transform(body []byte, model string, stream bool
 }
 
 func (c *geminiToOpenAIResponse) Transform(body []byte) ([]byte, error) {
+	return c.TransformWithOptions(body, ResponseOptions{})
+}
+
+// TransformWithOptions converts a non-streaming response; thought parts become the
+// message's reasoning_content unless opts.HideReasoning is set
+func (c *geminiToOpenAIResponse) TransformWithOptions(body []byte, opts ResponseOptions) ([]byte, error) {
 	var resp GeminiResponse
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, err
@@ -139,13 +147,19 @@ func (c *geminiToOpenAIResponse) Transform(body []byte) ([]byte, error) {
 	}
 
 	msg := OpenAIMessage{Role: "assistant"}
-	var textContent string
+	var textContent, reasoningContent string
 	var toolCalls []OpenAIToolCall
 	finishReason := "stop"
 
 	if len(resp.Candidates) > 0 {
 		candidate := resp.Candidates[0]
 		for _, part := range candidate.Content.Parts {
+			if part.Thought {
+				if !opts.HideReasoning {
+					reasoningContent += part.Text
+				}
+				continue
+			}
 			if part.Text != "" {
 				textContent += part.Text
 			}
@@ -155,7 +169,7 @@ func (c *geminiToOpenAIResponse) Transform(body []byte) ([]byte, error) {
 					ID:   "call_" + part.FunctionCall.Name,
 					Type: "function",
 					Function: OpenAIFunctionCall{
-						Name:      part.FunctionCall.Name,
+						Name:      RestoreToolName(part.FunctionCall.Name),
 						Arguments: string(argsJSON),
 					},
 				})
@@ -177,6 +191,7 @@ func (c *geminiToOpenAIResponse) Transform(body []byte) ([]byte, error) {
 	if textContent != "" {
 		msg.Content = textContent
 	}
+	msg.ReasoningContent = reasoningContent
 	if len(toolCalls) > 0 {
 		msg.ToolCalls = toolCalls
 	}
@@ -194,65 +209,73 @@ func (c *geminiToOpenAIResponse) TransformChunk(chunk []byte, state *TransformSt
 	events, remaining := ParseSSE(state.Buffer + string(chunk))
 	state.Buffer = remaining
 
+	sse := state.openAIEmitter()
 	var output []byte
 	for _, event := range events {
-		var geminiChunk GeminiStreamChunk
-		if err := json.Unmarshal(event.Data, &geminiChunk); err != nil {
+		geminiChunk, ok := parseGeminiChunk(event.Data)
+		if !ok {
 			continue
 		}
+		captureThoughtSignatures(geminiChunk, state)
+
+		if geminiChunk.UsageMetadata != nil {
+			sse.SetUsage(geminiChunk.UsageMetadata.PromptTokenCount, geminiChunk.UsageMetadata.CandidatesTokenCount)
+		}
 
 		// First chunk
 		if state.MessageID == "" {
 			state.MessageID = "chatcmpl-gemini"
-			openaiChunk := OpenAIStreamChunk{
-				ID:      state.MessageID,
-				Object:  "chat.completion.chunk",
-				Created: time.Now().Unix(),
-				Choices: []OpenAIChoice{{
-					Index: 0,
-					Delta: &OpenAIMessage{Role: "assistant", Content: ""},
-				}},
-			}
-			output = append(output, FormatSSE("", openaiChunk)...)
+			sse.SetMessage(state.MessageID, "")
+			output = append(output, sse.Start()...)
 		}
 
-		if len(geminiChunk.Candidates) > 0 {
-			candidate := geminiChunk.Candidates[0]
-			for _, part := range candidate.Content.Parts {
-				if part.Text != "" {
-					openaiChunk := OpenAIStreamChunk{
-						ID:      state.MessageID,
-						Object:  "chat.completion.chunk",
-						Created: time.Now().Unix(),
-						Choices: []OpenAIChoice{{
-							Index: 0,
-							Delta: &OpenAIMessage{Content: part.Text},
-						}},
-					}
-					output = append(output, FormatSSE("", openaiChunk)...)
+		if len(geminiChunk.Candidates) == 0 {
+			continue
+		}
+		candidate := geminiChunk.Candidates[0]
+		for _, part := range candidate.Content.Parts {
+			if part.Thought {
+				if !state.HideReasoning {
+					output = append(output, sse.Reasoning(part.Text)...)
 				}
+				continue
 			}
+			if part.Text != "" {
+				output = append(output, sse.Text(part.Text)...)
+			}
+			if part.FunctionCall != nil {
+				output = append(output, geminiToolCallChunks(sse, part.FunctionCall, state)...)
+			}
+		}
 
-			if candidate.FinishReason != "" {
-				finishReason := "stop"
-				if candidate.FinishReason == "MAX_TOKENS" {
-					finishReason = "length"
-				}
-				openaiChunk := OpenAIStreamChunk{
-					ID:      state.MessageID,
-					Object:  "chat.completion.chunk",
-					Created: time.Now().Unix(),
-					Choices: []OpenAIChoice{{
-						Index:        0,
-						Delta:        &OpenAIMessage{},
-						FinishReason: finishReason,
-					}},
-				}
-				output = append(output, FormatSSE("", openaiChunk)...)
-				output = append(output, FormatDone()...)
+		if candidate.FinishReason != "" {
+			finishReason := "stop"
+			if candidate.FinishReason == "MAX_TOKENS" {
+				finishReason = "length"
 			}
+			output = append(output, sse.Finish(finishReason)...)
 		}
 	}
 
 	return output, nil
 }
+
+// geminiToolCallChunks sends a complete Gemini function call as an OpenAI tool call
+func geminiToolCallChunks(sse *openaisse.Emitter, fc *GeminiFunctionCall, state *TransformState) []byte {
+	key := len(state.ToolCalls)
+	id := fc.ID
+	if id == "" {
+		id = fmt.Sprintf("call_%d", key+1)
+	}
+	name := RestoreToolName(fc.Name)
+	args := fc.Args
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+	argsJSON, _ := json.Marshal(args)
+	state.ToolCalls[key] = &ToolCallState{ID: id, Name: name, Arguments: string(argsJSON)}
+	cacheToolCallSignature(state, id)
+
+	output := sse.StartToolCall(key, id, name)
+	return append(output, sse.ToolArguments(key, string(argsJSON))...)
+}
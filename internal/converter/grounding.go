@@ -0,0 +1,198 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Gemini grounding (Google Search) is presented to Claude clients the way Anthropic's native
+// web search tool reports its results: a server_tool_use block with the query, a
+// web_search_tool_result block listing the sources, and citations on the text they support.
+
+// MergeGroundingMetadata merges grounding metadata spread over streamed chunks into dst
+func MergeGroundingMetadata(dst, src *GeminiGroundingMetadata) *GeminiGroundingMetadata {
+	if src == nil {
+		return dst
+	}
+	if dst == nil {
+		dst = &GeminiGroundingMetadata{}
+	}
+	if len(src.WebSearchQueries) > 0 {
+		dst.WebSearchQueries = src.WebSearchQueries
+	}
+	if len(src.GroundingChunks) > 0 {
+		dst.GroundingChunks = src.GroundingChunks
+	}
+	if len(src.GroundingSupports) > 0 {
+		dst.GroundingSupports = src.GroundingSupports
+	}
+	if src.SearchEntryPoint != nil {
+		dst.SearchEntryPoint = src.SearchEntryPoint
+	}
+	return dst
+}
+
+// HasWebSearch reports whether gm contains a search query or results
+func (gm *GeminiGroundingMetadata) HasWebSearch() bool {
+	return gm != nil && (len(gm.WebSearchQueries) > 0 || len(gm.GroundingChunks) > 0)
+}
+
+// WebSearchBlocks returns the server_tool_use and web_search_tool_result blocks describing gm
+func WebSearchBlocks(gm *GeminiGroundingMetadata) []ClaudeContentBlock {
+	if !gm.HasWebSearch() {
+		return nil
+	}
+	toolUseID := fmt.Sprintf("srvtoolu_%d", time.Now().UnixNano())
+	return []ClaudeContentBlock{
+		{
+			Type:  "server_tool_use",
+			ID:    toolUseID,
+			Name:  "web_search",
+			Input: map[string]interface{}{"query": strings.Join(gm.WebSearchQueries, "; ")},
+		},
+		{
+			Type:      "web_search_tool_result",
+			ToolUseID: toolUseID,
+			Content:   webSearchResults(gm),
+		},
+	}
+}
+
+// GroundingCitations returns a citation for every source supporting a segment of the text,
+// in the order the segments appear
+func GroundingCitations(gm *GeminiGroundingMetadata) []ClaudeCitation {
+	if gm == nil {
+		return nil
+	}
+	var citations []ClaudeCitation
+	seen := make(map[string]bool)
+	for _, support := range gm.GroundingSupports {
+		if support.Segment == nil || strings.TrimSpace(support.Segment.Text) == "" {
+			continue
+		}
+		for _, idx := range support.GroundingChunkIndices {
+			if idx < 0 || idx >= len(gm.GroundingChunks) || gm.GroundingChunks[idx].Web == nil {
+				continue
+			}
+			web := gm.GroundingChunks[idx].Web
+			key := fmt.Sprintf("%d|%s", idx, support.Segment.Text)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			citations = append(citations, ClaudeCitation{
+				Type:      "web_search_result_location",
+				URL:       web.URI,
+				Title:     web.Title,
+				CitedText: support.Segment.Text,
+			})
+		}
+	}
+	return citations
+}
+
+// applyGrounding adds the web search blocks of gm in front of the content of a
+// non-streaming response and attaches citations to the text blocks containing the cited text
+func applyGrounding(resp *ClaudeResponse, gm *GeminiGroundingMetadata) {
+	if !gm.HasWebSearch() {
+		return
+	}
+
+	lastText := -1
+	for i := range resp.Content {
+		if resp.Content[i].Type == "text" {
+			lastText = i
+		}
+	}
+	for _, citation := range GroundingCitations(gm) {
+		target := lastText
+		for i := range resp.Content {
+			if resp.Content[i].Type == "text" && strings.Contains(resp.Content[i].Text, citation.CitedText) {
+				target = i
+				break
+			}
+		}
+		if target >= 0 {
+			resp.Content[target].Citations = append(resp.Content[target].Citations, citation)
+		}
+	}
+
+	resp.Content = append(WebSearchBlocks(gm), resp.Content...)
+	resp.Usage.ServerToolUse = &ClaudeServerToolUsage{WebSearchRequests: 1}
+}
+
+// WebSearchStreamEvents returns the Claude SSE events presenting gm as content blocks starting
+// at index, and the number of blocks emitted. Grounding arrives with the last chunks, after
+// the text was streamed, so citations are sent in a separate empty text block.
+func WebSearchStreamEvents(gm *GeminiGroundingMetadata, index int) ([]byte, int) {
+	blocks := WebSearchBlocks(gm)
+	if len(blocks) == 0 {
+		return nil, 0
+	}
+
+	var output []byte
+	count := 0
+	for _, block := range blocks {
+		start := block
+		if block.Type == "server_tool_use" {
+			// Input is streamed as input_json_delta like a regular tool call
+			start.Input = map[string]interface{}{}
+		}
+		output = append(output, FormatSSE("content_block_start", map[string]interface{}{
+			"type":          "content_block_start",
+			"index":         index + count,
+			"content_block": start,
+		})...)
+		if block.Type == "server_tool_use" {
+			inputJSON, _ := json.Marshal(block.Input)
+			output = append(output, FormatSSE("content_block_delta", map[string]interface{}{
+				"type":  "content_block_delta",
+				"index": index + count,
+				"delta": map[string]interface{}{"type": "input_json_delta", "partial_json": string(inputJSON)},
+			})...)
+		}
+		output = append(output, FormatSSE("content_block_stop", map[string]interface{}{
+			"type":  "content_block_stop",
+			"index": index + count,
+		})...)
+		count++
+	}
+
+	if citations := GroundingCitations(gm); len(citations) > 0 {
+		output = append(output, FormatSSE("content_block_start", map[string]interface{}{
+			"type":          "content_block_start",
+			"index":         index + count,
+			"content_block": map[string]interface{}{"type": "text", "text": "", "citations": []interface{}{}},
+		})...)
+		for _, citation := range citations {
+			output = append(output, FormatSSE("content_block_delta", map[string]interface{}{
+				"type":  "content_block_delta",
+				"index": index + count,
+				"delta": map[string]interface{}{"type": "citations_delta", "citation": citation},
+			})...)
+		}
+		output = append(output, FormatSSE("content_block_stop", map[string]interface{}{
+			"type":  "content_block_stop",
+			"index": index + count,
+		})...)
+		count++
+	}
+	return output, count
+}
+
+func webSearchResults(gm *GeminiGroundingMetadata) []ClaudeWebSearchResult {
+	results := make([]ClaudeWebSearchResult, 0, len(gm.GroundingChunks))
+	for _, chunk := range gm.GroundingChunks {
+		if chunk.Web == nil || chunk.Web.URI == "" {
+			continue
+		}
+		results = append(results, ClaudeWebSearchResult{
+			Type:  "web_search_result",
+			URL:   chunk.Web.URI,
+			Title: chunk.Web.Title,
+		})
+	}
+	return results
+}
@@ -0,0 +1,53 @@
+package converter
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Gemini can answer with images (inlineData parts). Claude responses carry them as image
+// blocks; Claude streams have no image delta, so there they are sent as markdown images with
+// a data URI, which clients render and send back as text on the next turn. SplitInlineImages
+// turns that text back into image blocks so the image reaches the upstream as an image again.
+
+var inlineImagePattern = regexp.MustCompile(`!\[image\]\(data:([\w.+-]+/[\w.+-]+);base64,([A-Za-z0-9+/=]+)\)`)
+
+// InlineImageMarkdown renders an inline image as a markdown image with a data URI
+func InlineImageMarkdown(mimeType, data string) string {
+	return fmt.Sprintf("![image](data:%s;base64,%s)", mimeType, data)
+}
+
+// InlineImageBlock returns the Claude image block of an inline image
+func InlineImageBlock(mimeType, data string) ClaudeContentBlock {
+	return ClaudeContentBlock{
+		Type: "image",
+		Source: &ClaudeImageSource{
+			Type:      "base64",
+			MediaType: mimeType,
+			Data:      data,
+		},
+	}
+}
+
+// SplitInlineImages splits text at the markdown images written by InlineImageMarkdown into
+// text and image blocks. It returns nil if the text has no such image.
+func SplitInlineImages(text string) []ClaudeContentBlock {
+	matches := inlineImagePattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	var blocks []ClaudeContentBlock
+	last := 0
+	for _, m := range matches {
+		if m[0] > last {
+			blocks = append(blocks, ClaudeContentBlock{Type: "text", Text: text[last:m[0]]})
+		}
+		blocks = append(blocks, InlineImageBlock(text[m[2]:m[3]], text[m[4]:m[5]]))
+		last = m[1]
+	}
+	if last < len(text) {
+		blocks = append(blocks, ClaudeContentBlock{Type: "text", Text: text[last:]})
+	}
+	return blocks
+}
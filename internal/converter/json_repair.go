@@ -0,0 +1,210 @@
+package converter
+
+import (
+	"encoding/json"
+	"log"
+	"strings"
+)
+
+// maxRepairAttempts bounds the truncation points tried by RepairJSON
+const maxRepairAttempts = 64
+
+// RepairJSON turns truncated JSON (as produced when an upstream response is cut) into a valid
+// document: an unterminated string is closed, the incomplete trailing member is dropped if
+// needed, and open objects and arrays are closed. ok is false if no valid document was found.
+func RepairJSON(raw string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false
+	}
+	if json.Valid([]byte(raw)) {
+		return raw, true
+	}
+
+	// Collect the member boundaries the input can be cut back to
+	var cuts []int
+	inString, escaped := false, false
+	for i := 0; i < len(raw); i++ {
+		ch := raw[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case ',':
+			cuts = append(cuts, i)
+		case '{', '[':
+			cuts = append(cuts, i+1)
+		}
+	}
+
+	// Keep as much as possible: the whole input first, then the latest boundaries
+	cuts = append(cuts, len(raw))
+	for k, attempts := len(cuts)-1, 0; k >= 0 && attempts < maxRepairAttempts; k, attempts = k-1, attempts+1 {
+		if repaired := closeJSON(raw[:cuts[k]]); json.Valid([]byte(repaired)) {
+			return repaired, true
+		}
+	}
+	return "", false
+}
+
+// closeJSON appends whatever is needed to terminate the open string and containers of prefix
+func closeJSON(prefix string) string {
+	var stack []byte
+	inString, escaped := false, false
+	for i := 0; i < len(prefix); i++ {
+		ch := prefix[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(prefix)
+	if inString {
+		if escaped {
+			// Drop a dangling backslash so the closing quote isn't escaped
+			trimmed := strings.TrimSuffix(sb.String(), "\\")
+			sb.Reset()
+			sb.WriteString(trimmed)
+		}
+		sb.WriteByte('"')
+	}
+	out := strings.TrimRight(sb.String(), " \t\r\n")
+	out = strings.TrimSuffix(out, ",")
+	out = strings.TrimSuffix(out, ":")
+	for i := len(stack) - 1; i >= 0; i-- {
+		out += string(stack[i])
+	}
+	return out
+}
+
+// DecodeFunctionArgs decodes functionCall args given as an object or as a JSON string,
+// repairing truncated JSON. Args that can't be recovered decode to an empty object so
+// clients always receive a parsable tool input.
+func DecodeFunctionArgs(name string, raw json.RawMessage) map[string]interface{} {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	text := string(raw)
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		// Some upstreams send args as a serialized JSON string
+		text = s
+	}
+
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(text), &args); err == nil {
+		return args
+	}
+	if repaired, ok := RepairJSON(text); ok {
+		if err := json.Unmarshal([]byte(repaired), &args); err == nil {
+			log.Printf("[Converter] Repaired malformed args of function call %s: %q", name, truncateForLog(text))
+			return args
+		}
+	}
+	log.Printf("[Converter] Dropped unparsable args of function call %s: %q", name, truncateForLog(text))
+	return map[string]interface{}{}
+}
+
+// DecodedFunctionCall is a Gemini function call as received, with its args decoded
+type DecodedFunctionCall struct {
+	Name         string
+	ID           string
+	Args         map[string]interface{}
+	PartialArgs  []GeminiPartialArg // Args arriving in fragments (streamFunctionCallArguments)
+	WillContinue bool
+}
+
+// DecodeGeminiFunctionCall decodes a Gemini function call, accepting args as an object or
+// a JSON string and repairing malformed or truncated args (see DecodeFunctionArgs). The
+// UnmarshalJSON methods of the Gemini function call types share it.
+func DecodeGeminiFunctionCall(data []byte) (*DecodedFunctionCall, error) {
+	var raw struct {
+		Name         string             `json:"name"`
+		Args         json.RawMessage    `json:"args"`
+		ID           string             `json:"id"`
+		PartialArgs  []GeminiPartialArg `json:"partialArgs"`
+		WillContinue bool               `json:"willContinue"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return &DecodedFunctionCall{
+		Name:         raw.Name,
+		ID:           raw.ID,
+		Args:         DecodeFunctionArgs(raw.Name, raw.Args),
+		PartialArgs:  raw.PartialArgs,
+		WillContinue: raw.WillContinue,
+	}, nil
+}
+
+// UnmarshalJSON accepts args as an object or a JSON string and repairs truncated args
+func (fc *GeminiFunctionCall) UnmarshalJSON(data []byte) error {
+	call, err := DecodeGeminiFunctionCall(data)
+	if err != nil {
+		return err
+	}
+	fc.Name = call.Name
+	fc.ID = call.ID
+	fc.Args = call.Args
+	fc.PartialArgs = call.PartialArgs
+	fc.WillContinue = call.WillContinue
+	return nil
+}
+
+// parseGeminiChunk parses a streamed Gemini chunk. A chunk cut off mid function call is
+// repaired, so the call still reaches the client with parsable arguments.
+func parseGeminiChunk(data []byte) (*GeminiStreamChunk, bool) {
+	var chunk GeminiStreamChunk
+	if err := json.Unmarshal(data, &chunk); err == nil {
+		return &chunk, true
+	}
+	if !strings.Contains(string(data), `"functionCall"`) {
+		return nil, false
+	}
+	repaired, ok := RepairJSON(string(data))
+	if !ok || json.Unmarshal([]byte(repaired), &chunk) != nil {
+		return nil, false
+	}
+	log.Printf("[Converter] Repaired truncated Gemini stream chunk with a function call")
+	return &chunk, true
+}
+
+func truncateForLog(s string) string {
+	const maxLen = 512
+	if len(s) > maxLen {
+		return s[:maxLen] + "..."
+	}
+	return s
+}
@@ -0,0 +1,142 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The legacy OpenAI completions API (/v1/completions) is served as chat completions: the
+// prompt becomes a single user message, and chat responses are turned back into
+// text_completion objects. Routing and conversion to other providers then work as for any
+// chat completions request.
+
+// completionOnlyFields have no chat completions equivalent and are dropped
+var completionOnlyFields = []string{"prompt", "suffix", "echo", "logprobs", "best_of"}
+
+// CompletionsToChatRequest converts a /v1/completions request body to a chat completions request
+func CompletionsToChatRequest(body []byte) ([]byte, error) {
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	prompt, err := completionPrompt(req["prompt"])
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range completionOnlyFields {
+		delete(req, field)
+	}
+	req["messages"] = []OpenAIMessage{{Role: "user", Content: prompt}}
+	return json.Marshal(req)
+}
+
+// completionPrompt returns the text of a prompt given as a string or a single-element array
+func completionPrompt(v interface{}) (string, error) {
+	switch p := v.(type) {
+	case string:
+		return p, nil
+	case []interface{}:
+		if len(p) == 1 {
+			if s, ok := p[0].(string); ok {
+				return s, nil
+			}
+		}
+		if len(p) > 1 {
+			return "", errors.New("batched prompts are not supported, send one prompt per request")
+		}
+	case nil:
+		return "", errors.New("prompt is required")
+	}
+	return "", errors.New("token array prompts are not supported, send the prompt as text")
+}
+
+// ChatToCompletionResponse converts a chat completions response to a text_completion response.
+// Bodies without choices (such as errors) are returned unchanged.
+func ChatToCompletionResponse(body []byte) ([]byte, error) {
+	var resp OpenAIResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Choices == nil {
+		return body, nil
+	}
+
+	choices := make([]map[string]interface{}, 0, len(resp.Choices))
+	for _, choice := range resp.Choices {
+		choices = append(choices, completionChoice(choice.Index, choice.Message, choice.FinishReason))
+	}
+	return json.Marshal(map[string]interface{}{
+		"id":      resp.ID,
+		"object":  "text_completion",
+		"created": resp.Created,
+		"model":   resp.Model,
+		"choices": choices,
+		"usage":   resp.Usage,
+	})
+}
+
+// ChatChunkToCompletionChunk converts the data of a chat completions stream chunk to a
+// text_completion stream chunk. Other events (such as errors) are returned unchanged.
+func ChatChunkToCompletionChunk(data []byte) ([]byte, error) {
+	var chunk OpenAIStreamChunk
+	if err := json.Unmarshal(data, &chunk); err != nil {
+		return nil, err
+	}
+	if chunk.Choices == nil && chunk.Usage == nil {
+		return data, nil
+	}
+
+	choices := make([]map[string]interface{}, 0, len(chunk.Choices))
+	for _, choice := range chunk.Choices {
+		choices = append(choices, completionChoice(choice.Index, choice.Delta, choice.FinishReason))
+	}
+	out := map[string]interface{}{
+		"id":      chunk.ID,
+		"object":  "text_completion",
+		"created": chunk.Created,
+		"model":   chunk.Model,
+		"choices": choices,
+	}
+	if chunk.Usage != nil {
+		out["usage"] = chunk.Usage
+	}
+	return json.Marshal(out)
+}
+
+func completionChoice(index int, msg *OpenAIMessage, finishReason string) map[string]interface{} {
+	choice := map[string]interface{}{
+		"text":          "",
+		"index":         index,
+		"logprobs":      nil,
+		"finish_reason": nil,
+	}
+	if msg != nil {
+		choice["text"] = messageText(msg.Content)
+	}
+	if finishReason != "" {
+		choice["finish_reason"] = finishReason
+	}
+	return choice
+}
+
+// messageText returns the text of message content given as a string or content parts
+func messageText(content interface{}) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []interface{}:
+		var sb strings.Builder
+		for _, part := range c {
+			if m, ok := part.(map[string]interface{}); ok && m["type"] == "text" {
+				if text, ok := m["text"].(string); ok {
+					sb.WriteString(text)
+				}
+			}
+		}
+		return sb.String()
+	}
+	return ""
+}
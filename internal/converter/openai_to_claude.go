@@ -102,7 +102,7 @@ func (c *openaiToClaudeRequest) Transform(body []byte, model string, stream bool
 				blocks = append(blocks, ClaudeContentBlock{
 					Type:  "tool_use",
 					ID:    tc.ID,
-					Name:  tc.Function.Name,
+					Name:  SanitizeToolName(tc.Function.Name),
 					Input: input,
 				})
 			}
@@ -115,7 +115,7 @@ func (c *openaiToClaudeRequest) Transform(body []byte, model string, stream bool
 	// Convert tools
 	for _, tool := range req.Tools {
 		claudeReq.Tools = append(claudeReq.Tools, ClaudeTool{
-			Name:        tool.Function.Name,
+			Name:        SanitizeToolName(tool.Function.Name),
 			Description: tool.Function.Description,
 			InputSchema: tool.Function.Parameters,
 		})
@@ -171,7 +171,7 @@ func (c *openaiToClaudeResponse) Transform(body []byte) ([]byte, error) {
 				claudeResp.Content = append(claudeResp.Content, ClaudeContentBlock{
 					Type:  "tool_use",
 					ID:    tc.ID,
-					Name:  tc.Function.Name,
+					Name:  RestoreToolName(tc.Function.Name),
 					Input: input,
 				})
 			}
@@ -195,11 +195,11 @@ func (c *openaiToClaudeResponse) TransformChunk(chunk []byte, state *TransformSt
 	events, remaining := ParseSSE(state.Buffer + string(chunk))
 	state.Buffer = remaining
 
+	sse := state.claudeEmitter()
 	var output []byte
 	for _, event := range events {
 		if event.Event == "done" {
-			// Send message_stop
-			output = append(output, FormatSSE("message_stop", map[string]string{"type": "message_stop"})...)
+			output = append(output, sse.Finish(state.StopReason, map[string]interface{}{"output_tokens": state.Usage.OutputTokens})...)
 			continue
 		}
 
@@ -208,86 +208,64 @@ func (c *openaiToClaudeResponse) TransformChunk(chunk []byte, state *TransformSt
 			continue
 		}
 
-		if len(openaiChunk.Choices) == 0 {
-			continue
-		}
-
+		// With stream_options.include_usage, usage arrives in a final chunk without choices
 		if openaiChunk.Usage != nil {
 			state.Usage.InputTokens = openaiChunk.Usage.PromptTokens
 			state.Usage.OutputTokens = openaiChunk.Usage.CompletionTokens
 		}
 
-		choice := openaiChunk.Choices[0]
-
 		// First chunk - send message_start
 		if state.MessageID == "" {
 			state.MessageID = openaiChunk.ID
-			msgStart := map[string]interface{}{
-				"type": "message_start",
-				"message": map[string]interface{}{
-					"id":    openaiChunk.ID,
-					"type":  "message",
-					"role":  "assistant",
-					"model": openaiChunk.Model,
-					"usage": map[string]int{"input_tokens": 0, "output_tokens": 0},
-				},
-			}
-			output = append(output, FormatSSE("message_start", msgStart)...)
-
-			// Send content_block_start
-			blockStart := map[string]interface{}{
-				"type":  "content_block_start",
-				"index": 0,
-				"content_block": map[string]interface{}{
-					"type": "text",
-					"text": "",
-				},
-			}
-			output = append(output, FormatSSE("content_block_start", blockStart)...)
+			output = append(output, sse.MessageStart(map[string]interface{}{
+				"id":    openaiChunk.ID,
+				"model": openaiChunk.Model,
+				"usage": map[string]int{"input_tokens": state.Usage.InputTokens, "output_tokens": 0},
+			})...)
 		}
 
+		if len(openaiChunk.Choices) == 0 {
+			continue
+		}
+		choice := openaiChunk.Choices[0]
+
 		if choice.Delta != nil {
 			// Text content
 			if content, ok := choice.Delta.Content.(string); ok && content != "" {
-				delta := map[string]interface{}{
-					"type":  "content_block_delta",
-					"index": 0,
-					"delta": map[string]interface{}{
-						"type": "text_delta",
-						"text": content,
-					},
+				output = append(output, sse.Text(content)...)
+			}
+
+			// Tool calls: the first delta of a call has its id and name, later ones
+			// carry argument fragments
+			for _, tc := range choice.Delta.ToolCalls {
+				if state.ToolCalls == nil {
+					state.ToolCalls = make(map[int]*ToolCallState)
+				}
+				call, ok := state.ToolCalls[tc.Index]
+				if !ok {
+					call = &ToolCallState{ID: tc.ID, Name: RestoreToolName(tc.Function.Name)}
+					state.ToolCalls[tc.Index] = call
+					output = append(output, sse.StartToolUse(call.ID, call.Name)...)
+				}
+				if tc.Function.Arguments != "" {
+					call.Arguments += tc.Function.Arguments
+					output = append(output, sse.InputJSON(tc.Function.Arguments)...)
 				}
-				output = append(output, FormatSSE("content_block_delta", delta)...)
 			}
 		}
 
-		// Finish reason
+		// Finish reason: close the open block. The message ends at [DONE], after the
+		// usage chunk.
 		if choice.FinishReason != "" {
-			// Send content_block_stop
-			blockStop := map[string]interface{}{
-				"type":  "content_block_stop",
-				"index": 0,
-			}
-			output = append(output, FormatSSE("content_block_stop", blockStop)...)
+			output = append(output, sse.EndBlock()...)
 
-			// Map finish reason
-			stopReason := "end_turn"
+			state.StopReason = "end_turn"
 			switch choice.FinishReason {
 			case "length":
-				stopReason = "max_tokens"
+				state.StopReason = "max_tokens"
 			case "tool_calls":
-				stopReason = "tool_use"
-			}
-
-			// Send message_delta
-			msgDelta := map[string]interface{}{
-				"type": "message_delta",
-				"delta": map[string]interface{}{
-					"stop_reason": stopReason,
-				},
-				"usage": map[string]int{"output_tokens": state.Usage.OutputTokens},
+				state.StopReason = "tool_use"
 			}
-			output = append(output, FormatSSE("message_delta", msgDelta)...)
 		}
 	}
 
@@ -84,7 +84,7 @@ func (c *openaiToCodexRequest) Transform(body []byte, model string, stream bool)
 				Type:      "function_call",
 				ID:        tc.ID,
 				CallID:    tc.ID,
-				Name:      tc.Function.Name,
+				Name:      SanitizeToolName(tc.Function.Name),
 				Role:      "assistant",
 				Arguments: tc.Function.Arguments,
 			})
@@ -96,7 +96,7 @@ func (c *openaiToCodexRequest) Transform(body []byte, model string, stream bool)
 	for _, tool := range req.Tools {
 		codexReq.Tools = append(codexReq.Tools, CodexTool{
 			Type:        "function",
-			Name:        tool.Function.Name,
+			Name:        SanitizeToolName(tool.Function.Name),
 			Description: tool.Function.Description,
 			Parameters:  tool.Function.Parameters,
 		})
@@ -139,7 +139,7 @@ func (c *openaiToCodexResponse) Transform(body []byte) ([]byte, error) {
 					Type:      "function_call",
 					ID:        tc.ID,
 					CallID:    tc.ID,
-					Name:      tc.Function.Name,
+					Name:      RestoreToolName(tc.Function.Name),
 					Arguments: tc.Function.Arguments,
 					Status:    "completed",
 				})
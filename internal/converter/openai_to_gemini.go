@@ -2,8 +2,10 @@ package converter
 
 import (
 	"encoding/json"
+	"strings"
 
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/thinking"
 )
 
 func init() {
@@ -14,6 +16,15 @@ type openaiToGeminiRequest struct{}
 type openaiToGeminiResponse struct{}
 
 func (c *openaiToGeminiRequest) Transform(body []byte, model string, stream bool) ([]byte, error) {
+	return c.TransformForSession(body, model, stream, "")
+}
+
+func (c *openaiToGeminiRequest) TransformForSession(body []byte, model string, stream bool, sessionID string) ([]byte, error) {
+	return c.TransformWithOptions(body, model, stream, RequestOptions{SessionID: sessionID})
+}
+
+func (c *openaiToGeminiRequest) TransformWithOptions(body []byte, model string, stream bool, opts RequestOptions) ([]byte, error) {
+	sessionID := opts.SessionID
 	var req OpenAIRequest
 	if err := json.Unmarshal(body, &req); err != nil {
 		return nil, err
@@ -31,6 +42,11 @@ func (c *openaiToGeminiRequest) Transform(body []byte, model string, stream bool
 		geminiReq.GenerationConfig.MaxOutputTokens = req.MaxCompletionTokens
 	}
 
+	// Models without thinking reject a thinking config
+	if !opts.HideReasoning && thinking.TargetModelSupports(opts.ProviderID, model) {
+		geminiReq.GenerationConfig.ThinkingConfig = openAIThinkingConfig(req.ReasoningEffort)
+	}
+
 	// Convert stop sequences
 	switch stop := req.Stop.(type) {
 	case string:
@@ -43,19 +59,13 @@ func (c *openaiToGeminiRequest) Transform(body []byte, model string, stream bool
 		}
 	}
 
-	// Convert messages
+	// Convert messages. Gemini has a single systemInstruction, so system and developer
+	// messages go there in order, wherever they appear in the conversation.
+	var systemTexts []string
 	for _, msg := range req.Messages {
-		if msg.Role == "system" {
-			var systemText string
-			if content, ok := msg.Content.(string); ok {
-				systemText = content
-			}
-			if systemText != "" {
-				// [FIX] Set role to "user" for systemInstruction (like CLIProxyAPI)
-				geminiReq.SystemInstruction = &GeminiContent{
-					Role:  "user",
-					Parts: []GeminiPart{{Text: systemText}},
-				}
+		if msg.Role == "system" || msg.Role == "developer" {
+			if text := openAIMessageText(msg.Content); strings.TrimSpace(text) != "" {
+				systemTexts = append(systemTexts, text)
 			}
 			continue
 		}
@@ -71,7 +81,7 @@ func (c *openaiToGeminiRequest) Transform(body []byte, model string, stream bool
 			contentStr, _ := msg.Content.(string)
 			geminiContent.Parts = []GeminiPart{{
 				FunctionResponse: &GeminiFunctionResponse{
-					Name:     msg.ToolCallID,
+					Name:     SanitizeToolName(msg.ToolCallID),
 					Response: map[string]string{"result": contentStr},
 				},
 			}}
@@ -101,21 +111,34 @@ func (c *openaiToGeminiRequest) Transform(body []byte, model string, stream bool
 			json.Unmarshal([]byte(tc.Function.Arguments), &args)
 			geminiContent.Parts = append(geminiContent.Parts, GeminiPart{
 				FunctionCall: &GeminiFunctionCall{
-					Name: tc.Function.Name,
+					Name: SanitizeToolName(tc.Function.Name),
 					Args: args,
 				},
+				// OpenAI clients don't keep thoughtSignature
+				ThoughtSignature: toolCallSignature(sessionID, tc.ID),
 			})
 		}
 
 		geminiReq.Contents = append(geminiReq.Contents, geminiContent)
 	}
 
+	// The default identity patch is written for Claude clients, so OpenAI requests only get
+	// a patch the route configures, and only along with a system prompt of their own
+	if len(systemTexts) > 0 {
+		patchOpts := opts
+		if patchOpts.IdentityPatch == nil {
+			noPatch := ""
+			patchOpts.IdentityPatch = &noPatch
+		}
+		geminiReq.SystemInstruction = buildSystemInstruction(systemTexts, model, patchOpts)
+	}
+
 	// Convert tools
 	if len(req.Tools) > 0 {
 		var funcDecls []GeminiFunctionDecl
 		for _, tool := range req.Tools {
 			funcDecls = append(funcDecls, GeminiFunctionDecl{
-				Name:        tool.Function.Name,
+				Name:        SanitizeToolName(tool.Function.Name),
 				Description: tool.Function.Description,
 				Parameters:  tool.Function.Parameters,
 			})
@@ -126,6 +149,48 @@ func (c *openaiToGeminiRequest) Transform(body []byte, model string, stream bool
 	return json.Marshal(geminiReq)
 }
 
+// openAIReasoningBudgets maps OpenAI reasoning efforts to Gemini thinking budgets
+var openAIReasoningBudgets = map[string]int{
+	"minimal": 512,
+	"low":     1024,
+	"medium":  8192,
+	"high":    24576,
+}
+
+// openAIThinkingConfig asks Gemini to return its thoughts when the client set a reasoning
+// effort, so they can be sent back as reasoning_content. Unknown efforts keep the model's
+// default budget; "none" and no effort leave thinking as configured upstream.
+func openAIThinkingConfig(effort string) *GeminiThinkingConfig {
+	effort = strings.ToLower(strings.TrimSpace(effort))
+	if effort == "" || effort == "none" {
+		return nil
+	}
+	return &GeminiThinkingConfig{
+		IncludeThoughts: true,
+		ThinkingBudget:  openAIReasoningBudgets[effort],
+	}
+}
+
+// openAIMessageText returns the text of an OpenAI message content: the string, or the text
+// parts joined by newlines
+func openAIMessageText(content interface{}) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case []interface{}:
+		var texts []string
+		for _, part := range c {
+			if m, ok := part.(map[string]interface{}); ok && m["type"] == "text" {
+				if text, ok := m["text"].(string); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
 func (c *openaiToGeminiResponse) Transform(body []byte) ([]byte, error) {
 	var resp OpenAIResponse
 	if err := json.Unmarshal(body, &resp); err != nil {
@@ -156,7 +221,7 @@ func (c *openaiToGeminiResponse) Transform(body []byte) ([]byte, error) {
 				json.Unmarshal([]byte(tc.Function.Arguments), &args)
 				candidate.Content.Parts = append(candidate.Content.Parts, GeminiPart{
 					FunctionCall: &GeminiFunctionCall{
-						Name: tc.Function.Name,
+						Name: RestoreToolName(tc.Function.Name),
 						Args: args,
 					},
 				})
@@ -0,0 +1,216 @@
+package converter
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// FineGrainedToolStreamingBeta is the anthropic-beta flag of clients that accept tool input
+// streamed as it is generated, in fragments that aren't necessarily valid JSON on their own
+const FineGrainedToolStreamingBeta = "fine-grained-tool-streaming"
+
+// HasAnthropicBeta reports whether an anthropic-beta header value lists a beta starting with prefix
+func HasAnthropicBeta(header, prefix string) bool {
+	for _, beta := range strings.Split(header, ",") {
+		if strings.HasPrefix(strings.TrimSpace(beta), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GeminiPartialArg is a fragment of function call args streamed by Gemini when
+// toolConfig.functionCallingConfig.streamFunctionCallArguments is set
+type GeminiPartialArg struct {
+	JSONPath     string   `json:"jsonPath"`
+	StringValue  *string  `json:"stringValue,omitempty"`
+	NumberValue  *float64 `json:"numberValue,omitempty"`
+	BoolValue    *bool    `json:"boolValue,omitempty"`
+	NullValue    *string  `json:"nullValue,omitempty"`
+	WillContinue bool     `json:"willContinue,omitempty"` // More of this string value follows
+}
+
+// argsPathSegment is one step of a JSON path: an object key or an array index
+type argsPathSegment struct {
+	key   string
+	index int // -1 for object keys
+}
+
+type argsFrame struct {
+	seg     argsPathSegment // How the parent refers to this container (unused for the root)
+	isArray bool
+	members int
+}
+
+// PartialArgsEncoder rebuilds the JSON text of function call args from streamed partial args.
+// Write returns the text each fragment adds, so it can be forwarded right away as an
+// input_json_delta; the concatenation of all fragments and Close is the complete args object.
+// Fragments must arrive in document order, as Gemini sends them.
+type PartialArgsEncoder struct {
+	stack      []argsFrame // Open containers, root object first
+	stringPath string      // Path of the open string value, if any
+	inString   bool
+}
+
+// NewPartialArgsEncoder creates an encoder for one function call
+func NewPartialArgsEncoder() *PartialArgsEncoder {
+	return &PartialArgsEncoder{}
+}
+
+// Write returns the JSON text arg adds to the args object
+func (e *PartialArgsEncoder) Write(arg GeminiPartialArg) string {
+	segs, ok := parseArgsPath(arg.JSONPath)
+	if !ok || len(segs) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	if e.stack == nil {
+		e.stack = []argsFrame{{}}
+		sb.WriteByte('{')
+	}
+
+	if e.inString {
+		if arg.JSONPath == e.stringPath && arg.StringValue != nil {
+			sb.WriteString(escapeJSONFragment(*arg.StringValue))
+			if !arg.WillContinue {
+				sb.WriteByte('"')
+				e.inString = false
+			}
+			return sb.String()
+		}
+		sb.WriteByte('"')
+		e.inString = false
+	}
+
+	// Close containers that aren't ancestors of this value, then open the missing ones
+	parent, leaf := segs[:len(segs)-1], segs[len(segs)-1]
+	common := 0
+	for common < len(e.stack)-1 && common < len(parent) && e.stack[common+1].seg == parent[common] {
+		common++
+	}
+	for len(e.stack)-1 > common {
+		sb.WriteByte(closer(e.stack[len(e.stack)-1].isArray))
+		e.stack = e.stack[:len(e.stack)-1]
+	}
+	for i, seg := range parent[common:] {
+		e.writeMemberPrefix(&sb, seg)
+		next := leaf
+		if common+i+1 < len(parent) {
+			next = parent[common+i+1]
+		}
+		isArray := next.index >= 0
+		if isArray {
+			sb.WriteByte('[')
+		} else {
+			sb.WriteByte('{')
+		}
+		e.stack = append(e.stack, argsFrame{seg: seg, isArray: isArray})
+	}
+
+	e.writeMemberPrefix(&sb, leaf)
+	switch {
+	case arg.StringValue != nil:
+		sb.WriteByte('"')
+		sb.WriteString(escapeJSONFragment(*arg.StringValue))
+		if arg.WillContinue {
+			e.inString, e.stringPath = true, arg.JSONPath
+		} else {
+			sb.WriteByte('"')
+		}
+	case arg.NumberValue != nil:
+		sb.WriteString(strconv.FormatFloat(*arg.NumberValue, 'f', -1, 64))
+	case arg.BoolValue != nil:
+		sb.WriteString(strconv.FormatBool(*arg.BoolValue))
+	default:
+		sb.WriteString("null")
+	}
+	return sb.String()
+}
+
+// Close returns the text that terminates the args object
+func (e *PartialArgsEncoder) Close() string {
+	if e.stack == nil {
+		return "{}"
+	}
+	var sb strings.Builder
+	if e.inString {
+		sb.WriteByte('"')
+		e.inString = false
+	}
+	for i := len(e.stack) - 1; i >= 0; i-- {
+		sb.WriteByte(closer(e.stack[i].isArray))
+	}
+	e.stack = e.stack[:0]
+	return sb.String()
+}
+
+func (e *PartialArgsEncoder) writeMemberPrefix(sb *strings.Builder, seg argsPathSegment) {
+	top := &e.stack[len(e.stack)-1]
+	if top.members > 0 {
+		sb.WriteByte(',')
+	}
+	top.members++
+	if !top.isArray {
+		key, _ := json.Marshal(seg.key)
+		sb.Write(key)
+		sb.WriteByte(':')
+	}
+}
+
+func closer(isArray bool) byte {
+	if isArray {
+		return ']'
+	}
+	return '}'
+}
+
+// escapeJSONFragment escapes s for use inside a JSON string literal
+func escapeJSONFragment(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted[1 : len(quoted)-1])
+}
+
+// parseArgsPath parses a JSON path such as $.a.b[0] or $['a'][0]
+func parseArgsPath(path string) ([]argsPathSegment, bool) {
+	rest, ok := strings.CutPrefix(path, "$")
+	if !ok {
+		return nil, false
+	}
+
+	var segs []argsPathSegment
+	for rest != "" {
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			segs = append(segs, argsPathSegment{key: rest[1 : end+1], index: -1})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "['") || strings.HasPrefix(rest, `["`):
+			quote := rest[1]
+			end := strings.IndexByte(rest[2:], quote)
+			if end < 0 || len(rest) < end+4 || rest[end+3] != ']' {
+				return nil, false
+			}
+			segs = append(segs, argsPathSegment{key: rest[2 : end+2], index: -1})
+			rest = rest[end+4:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, false
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, false
+			}
+			segs = append(segs, argsPathSegment{index: index})
+			rest = rest[end+1:]
+		default:
+			return nil, false
+		}
+	}
+	return segs, true
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/awsl-project/maxx/internal/claudesse"
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/openaisse"
 )
 
 // TransformState holds state for streaming response conversion
@@ -16,6 +18,33 @@ type TransformState struct {
 	Buffer           string // SSE line buffer
 	Usage            *Usage
 	StopReason       string
+	SessionID        string                   // Session of the request, used to capture thought signatures
+	Grounding        *GeminiGroundingMetadata // Web search grounding collected from the stream
+	Claude           *claudesse.Emitter       // Event sequence of a stream converted to Claude
+	OpenAI           *openaisse.Emitter       // Chunk sequence of a stream converted to OpenAI
+	IncludeUsage     bool                     // OpenAI client asked for a final usage chunk
+	StopSequences    []string                 // Stop sequences of the client request
+	HideReasoning    bool                     // Drop upstream thinking instead of sending it as reasoning
+
+	stopHeld         string            // Streamed text held back as it may be the start of a stop sequence
+	streamedCall     *streamedToolCall // Function call whose args are still arriving as partial args
+	thoughtSignature string            // Signature of the streamed turn, cached for its tool calls
+}
+
+// claudeEmitter returns the Claude event emitter of a stream, creating it on first use
+func (s *TransformState) claudeEmitter() *claudesse.Emitter {
+	if s.Claude == nil {
+		s.Claude = claudesse.NewEmitter()
+	}
+	return s.Claude
+}
+
+// openAIEmitter returns the OpenAI chunk emitter of a stream, creating it on first use
+func (s *TransformState) openAIEmitter() *openaisse.Emitter {
+	if s.OpenAI == nil {
+		s.OpenAI = openaisse.NewEmitter(s.IncludeUsage)
+	}
+	return s.OpenAI
 }
 
 // ToolCallState tracks tool call conversion state
@@ -38,6 +67,51 @@ type RequestTransformer interface {
 	Transform(body []byte, model string, stream bool) ([]byte, error)
 }
 
+// SessionRequestTransformer is implemented by request transformers that use per-session state
+// (such as captured thought signatures) when converting a request
+type SessionRequestTransformer interface {
+	TransformForSession(body []byte, model string, stream bool, sessionID string) ([]byte, error)
+}
+
+// RequestOptions are per-request settings for transformers that implement
+// OptionsRequestTransformer
+type RequestOptions struct {
+	SessionID string
+	// IdentityPatch replaces the identity patch put before the system prompt of Claude and
+	// OpenAI requests converted to Gemini: nil keeps the default patch (Claude requests only),
+	// "" leaves it out
+	IdentityPatch *string
+	// HideReasoning keeps the upstream from returning its thinking, even if the client asked
+	// for reasoning
+	HideReasoning bool
+	// ProviderID is the provider the request is sent to, for its model capability rules
+	// (0 for no particular provider)
+	ProviderID uint64
+}
+
+// OptionsRequestTransformer is implemented by request transformers that take per-request
+// options, such as a route's identity patch
+type OptionsRequestTransformer interface {
+	TransformWithOptions(body []byte, model string, stream bool, opts RequestOptions) ([]byte, error)
+}
+
+// ResponseOptions are per-request settings for response transformers that implement
+// OptionsResponseTransformer
+type ResponseOptions struct {
+	// StopSequences of the client request, so the response can report the one it stopped at
+	StopSequences []string
+	// SessionID of the request, used to remember the tool calls of the response
+	SessionID string
+	// HideReasoning drops the upstream's thinking instead of returning it as reasoning
+	HideReasoning bool
+}
+
+// OptionsResponseTransformer is implemented by response transformers that take per-request
+// options
+type OptionsResponseTransformer interface {
+	TransformWithOptions(body []byte, opts ResponseOptions) ([]byte, error)
+}
+
 // ResponseTransformer transforms response bodies between formats
 type ResponseTransformer interface {
 	// Transform converts a non-streaming response
@@ -96,8 +170,53 @@ func (r *Registry) GetTargetFormat(supportedTypes []domain.ClientType) domain.Cl
 	return ""
 }
 
+// CanConvert reports whether requests and responses can both be converted between the formats
+func (r *Registry) CanConvert(from, to domain.ClientType) bool {
+	if from == to {
+		return true
+	}
+	return r.requests[from][to] != nil && r.responses[from][to] != nil
+}
+
+// PreferredTargetType returns the format a request of originalType is sent in to a provider
+// supporting supportedTypes. Prefers Claude as it has the richest format support.
+func PreferredTargetType(supportedTypes []domain.ClientType, originalType domain.ClientType) domain.ClientType {
+	// If original type is supported, no conversion needed
+	for _, t := range supportedTypes {
+		if t == originalType {
+			return originalType
+		}
+	}
+
+	// Prefer Claude as target (richest format)
+	for _, t := range supportedTypes {
+		if t == domain.ClientTypeClaude {
+			return t
+		}
+	}
+
+	// Fall back to first supported type
+	if len(supportedTypes) > 0 {
+		return supportedTypes[0]
+	}
+
+	return originalType
+}
+
 // TransformRequest converts a request body
 func (r *Registry) TransformRequest(from, to domain.ClientType, body []byte, model string, stream bool) ([]byte, error) {
+	return r.TransformRequestForSession(from, to, body, model, stream, "")
+}
+
+// TransformRequestForSession converts a request body of sessionID, letting transformers that
+// implement SessionRequestTransformer use the session's state
+func (r *Registry) TransformRequestForSession(from, to domain.ClientType, body []byte, model string, stream bool, sessionID string) ([]byte, error) {
+	return r.TransformRequestWithOptions(from, to, body, model, stream, RequestOptions{SessionID: sessionID})
+}
+
+// TransformRequestWithOptions converts a request body with per-request options; transformers
+// that take no options only get the session ID, if they use sessions
+func (r *Registry) TransformRequestWithOptions(from, to domain.ClientType, body []byte, model string, stream bool, opts RequestOptions) ([]byte, error) {
 	if from == to {
 		return body, nil
 	}
@@ -110,11 +229,32 @@ func (r *Registry) TransformRequest(from, to domain.ClientType, body []byte, mod
 	if transformer == nil {
 		return nil, fmt.Errorf("no request transformer from %s to %s", from, to)
 	}
-	return transformer.Transform(body, model, stream)
+	if err := validateRequest(from, body); err != nil {
+		return nil, wrapConversionError(StageRequest, from, to, err)
+	}
+	var converted []byte
+	var err error
+	if ot, ok := transformer.(OptionsRequestTransformer); ok {
+		converted, err = ot.TransformWithOptions(body, model, stream, opts)
+	} else if st, ok := transformer.(SessionRequestTransformer); ok {
+		converted, err = st.TransformForSession(body, model, stream, opts.SessionID)
+	} else {
+		converted, err = transformer.Transform(body, model, stream)
+	}
+	if err != nil {
+		return nil, wrapConversionError(StageRequest, from, to, err)
+	}
+	return converted, nil
 }
 
 // TransformResponse converts a non-streaming response
 func (r *Registry) TransformResponse(from, to domain.ClientType, body []byte) ([]byte, error) {
+	return r.TransformResponseWithOptions(from, to, body, ResponseOptions{})
+}
+
+// TransformResponseWithOptions converts a non-streaming response with per-request options;
+// transformers that take no options ignore them
+func (r *Registry) TransformResponseWithOptions(from, to domain.ClientType, body []byte, opts ResponseOptions) ([]byte, error) {
 	if from == to {
 		return body, nil
 	}
@@ -127,7 +267,17 @@ func (r *Registry) TransformResponse(from, to domain.ClientType, body []byte) ([
 	if transformer == nil {
 		return nil, fmt.Errorf("no response transformer from %s to %s", from, to)
 	}
-	return transformer.Transform(body)
+	var converted []byte
+	var err error
+	if ot, ok := transformer.(OptionsResponseTransformer); ok {
+		converted, err = ot.TransformWithOptions(body, opts)
+	} else {
+		converted, err = transformer.Transform(body)
+	}
+	if err != nil {
+		return nil, wrapConversionError(StageResponse, from, to, err)
+	}
+	return converted, nil
 }
 
 // TransformStreamChunk converts a streaming chunk
@@ -144,7 +294,11 @@ func (r *Registry) TransformStreamChunk(from, to domain.ClientType, chunk []byte
 	if transformer == nil {
 		return nil, fmt.Errorf("no response transformer from %s to %s", from, to)
 	}
-	return transformer.TransformChunk(chunk, state)
+	converted, err := transformer.TransformChunk(chunk, state)
+	if err != nil {
+		return nil, wrapConversionError(StageResponse, from, to, err)
+	}
+	return converted, nil
 }
 
 // NewTransformState creates a new transform state
@@ -105,6 +105,28 @@ func FormatSSE(event string, data interface{}) []byte {
 	return []byte(sb.String())
 }
 
+// IncludeUsage reports whether an OpenAI chat request asks for a usage chunk at the
+// end of the stream (stream_options.include_usage)
+func IncludeUsage(body []byte) bool {
+	var req struct {
+		StreamOptions struct {
+			IncludeUsage bool `json:"include_usage"`
+		} `json:"stream_options"`
+	}
+	return json.Unmarshal(body, &req) == nil && req.StreamOptions.IncludeUsage
+}
+
+// StopSequences returns the stop sequences of a Claude request (stop_sequences)
+func StopSequences(body []byte) []string {
+	var req struct {
+		StopSequences []string `json:"stop_sequences"`
+	}
+	if json.Unmarshal(body, &req) != nil {
+		return nil
+	}
+	return req.StopSequences
+}
+
 // FormatDone returns the SSE [DONE] marker
 func FormatDone() []byte {
 	return []byte("data: [DONE]\n\n")
@@ -0,0 +1,157 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// sessionToolCallTTL is how long a session's tool calls are remembered after its last use
+	sessionToolCallTTL = 24 * time.Hour
+	// sessionToolCallMaxSessions triggers a sweep of expired sessions
+	sessionToolCallMaxSessions = 1000
+	// sessionToolCallMaxIDs bounds the tool calls remembered per session; the map is
+	// started over when it is full
+	sessionToolCallMaxIDs = 5000
+)
+
+// ToolCallStore keeps the tool calls seen in each session's requests and responses: the
+// tool name of every tool_use ID, and the upstream ID of every ID that had to be sanitized
+// for the client.
+//
+// Tool results are converted to Gemini function responses, which need the name of the
+// called function. The name is looked up on the matching tool_use block in the history,
+// but clients that truncate or compact the history drop that block, so the names are
+// remembered across the session's requests.
+type ToolCallStore struct {
+	mu       sync.Mutex
+	sessions map[string]*sessionToolCalls
+}
+
+type sessionToolCalls struct {
+	names     map[string]string // tool_use ID -> tool name
+	originals map[string]string // sanitized ID -> upstream ID
+	updatedAt time.Time
+}
+
+var globalToolCallStore = &ToolCallStore{sessions: make(map[string]*sessionToolCalls)}
+
+// GlobalToolCallStore returns the tool call store shared by all converters
+func GlobalToolCallStore() *ToolCallStore {
+	return globalToolCallStore
+}
+
+// session returns the entry of sessionID, creating it if create is set; the caller holds mu
+func (s *ToolCallStore) session(sessionID string, create bool) *sessionToolCalls {
+	now := time.Now()
+	entry, ok := s.sessions[sessionID]
+	if ok && now.Sub(entry.updatedAt) > sessionToolCallTTL {
+		delete(s.sessions, sessionID)
+		ok = false
+	}
+	if !ok {
+		if !create {
+			return nil
+		}
+		entry = &sessionToolCalls{names: make(map[string]string), originals: make(map[string]string)}
+		s.sessions[sessionID] = entry
+		if len(s.sessions) > sessionToolCallMaxSessions {
+			for id, other := range s.sessions {
+				if now.Sub(other.updatedAt) > sessionToolCallTTL {
+					delete(s.sessions, id)
+				}
+			}
+		}
+	}
+	entry.updatedAt = now
+	return entry
+}
+
+// Remember records the tool name of a tool_use ID in sessionID
+func (s *ToolCallStore) Remember(sessionID, id, name string) {
+	if sessionID == "" || id == "" || name == "" {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.session(sessionID, true)
+	if len(entry.names) >= sessionToolCallMaxIDs {
+		entry.names = make(map[string]string)
+	}
+	entry.names[id] = name
+}
+
+// Name returns the tool name of a tool_use ID in sessionID, or "" if it isn't known
+func (s *ToolCallStore) Name(sessionID, id string) string {
+	if sessionID == "" || id == "" {
+		return ""
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry := s.session(sessionID, false); entry != nil {
+		return entry.names[id]
+	}
+	return ""
+}
+
+// SanitizeID returns a tool_use ID Claude accepts ([a-zA-Z0-9_-]+) for an upstream tool call
+// ID. IDs that already comply are returned as-is; others get a hash suffix of the original
+// and are remembered in sessionID so OriginalID can map them back.
+func (s *ToolCallStore) SanitizeID(sessionID, id string) string {
+	if id == "" || isValidToolUseID(id) {
+		return id
+	}
+	var sb strings.Builder
+	for _, r := range id {
+		if r < 128 && isToolUseIDChar(byte(r)) {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('_')
+		}
+	}
+	sum := sha256.Sum256([]byte(id))
+	sanitized := sb.String() + "_" + hex.EncodeToString(sum[:])[:toolNameHashLength]
+
+	if sessionID != "" {
+		s.mu.Lock()
+		entry := s.session(sessionID, true)
+		if len(entry.originals) >= sessionToolCallMaxIDs {
+			entry.originals = make(map[string]string)
+		}
+		entry.originals[sanitized] = id
+		s.mu.Unlock()
+	}
+	return sanitized
+}
+
+// OriginalID returns the upstream ID of a tool_use ID produced by SanitizeID in sessionID,
+// or id itself if it wasn't sanitized
+func (s *ToolCallStore) OriginalID(sessionID, id string) string {
+	if sessionID == "" || id == "" {
+		return id
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if entry := s.session(sessionID, false); entry != nil {
+		if original, ok := entry.originals[id]; ok {
+			return original
+		}
+	}
+	return id
+}
+
+func isValidToolUseID(id string) bool {
+	for i := 0; i < len(id); i++ {
+		if !isToolUseIDChar(id[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func isToolUseIDChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
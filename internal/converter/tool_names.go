@@ -0,0 +1,98 @@
+package converter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxToolNameLength is the longest tool name Gemini and OpenAI accept
+	maxToolNameLength = 64
+	// toolNameHashLength is the length of the hash suffix that keeps sanitized names unique
+	toolNameHashLength = 8
+	// maxMappedToolNames bounds the sanitized -> original name map
+	maxMappedToolNames = 10000
+)
+
+// toolNameMap remembers the original name of every sanitized tool name.
+// Sanitized names are derived deterministically from the original, so a single shared map
+// serves all requests: the name a model calls is mapped back no matter which request
+// declared it.
+var toolNameMap = struct {
+	sync.RWMutex
+	names map[string]string
+}{names: make(map[string]string)}
+
+// SanitizeToolName returns a tool name every upstream accepts: at most 64 characters of
+// [a-zA-Z0-9_-], starting with a letter or underscore. Names that already comply are
+// returned as-is; others get a hash suffix of the original and are registered so
+// RestoreToolName can map them back.
+// MCP servers often generate names such as "mcp__my.server__some_very_long_tool_name".
+func SanitizeToolName(name string) string {
+	if name == "" || isValidToolName(name) {
+		return name
+	}
+
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			sb.WriteRune(r)
+		case (r >= '0' && r <= '9') || r == '-':
+			if i == 0 {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteByte('_')
+		}
+	}
+	sum := sha256.Sum256([]byte(name))
+	suffix := "_" + hex.EncodeToString(sum[:])[:toolNameHashLength]
+	base := sb.String()
+	if len(base) > maxToolNameLength-len(suffix) {
+		base = base[:maxToolNameLength-len(suffix)]
+	}
+	sanitized := base + suffix
+
+	toolNameMap.Lock()
+	if len(toolNameMap.names) >= maxMappedToolNames {
+		toolNameMap.names = make(map[string]string)
+	}
+	toolNameMap.names[sanitized] = name
+	toolNameMap.Unlock()
+
+	return sanitized
+}
+
+// RestoreToolName returns the original name of a tool name produced by SanitizeToolName,
+// or name itself if it wasn't sanitized
+func RestoreToolName(name string) string {
+	toolNameMap.RLock()
+	defer toolNameMap.RUnlock()
+	if original, ok := toolNameMap.names[name]; ok {
+		return original
+	}
+	return name
+}
+
+func isValidToolName(name string) bool {
+	if len(name) > maxToolNameLength {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '_':
+		case (c >= '0' && c <= '9') || c == '-':
+			if i == 0 {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,36 @@
+package converter
+
+// ToolResultMediaNote stands in for the text of a tool result that returned only images, as
+// a function response can't be empty
+const ToolResultMediaNote = "The tool returned the attached image."
+
+// ToolResultMedia returns the base64 image and document sources in the content of a Claude
+// tool_result block (screenshots of computer-use and MCP tools). Gemini function responses
+// carry only JSON, so converters send these as inlineData parts next to the response.
+func ToolResultMedia(content interface{}) []ClaudeImageSource {
+	blocks, ok := content.([]interface{})
+	if !ok {
+		return nil
+	}
+	var media []ClaudeImageSource
+	for _, block := range blocks {
+		m, ok := block.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if blockType, _ := m["type"].(string); blockType != "image" && blockType != "document" {
+			continue
+		}
+		source, _ := m["source"].(map[string]interface{})
+		if sourceType, _ := source["type"].(string); sourceType != "base64" {
+			continue
+		}
+		mediaType, _ := source["media_type"].(string)
+		data, _ := source["data"].(string)
+		if mediaType == "" || data == "" {
+			continue
+		}
+		media = append(media, ClaudeImageSource{Type: "base64", MediaType: mediaType, Data: data})
+	}
+	return media
+}
@@ -0,0 +1,64 @@
+package converter
+
+import "github.com/awsl-project/maxx/internal/thinking"
+
+// Clients that don't speak Gemini drop thoughtSignature when they send the conversation
+// back, but Gemini thinking models reject function calls in the history without one.
+// The signature of a streamed turn is kept in the shared signature cache under the ID of
+// each tool call of the turn, and put back on that call in the next request.
+
+// toolSignatureKey is the signature cache key of a tool call. IDs made up by the
+// converters (call_1, ...) repeat across conversations, so they are scoped by session.
+func toolSignatureKey(sessionID, toolCallID string) string {
+	return sessionID + "/" + toolCallID
+}
+
+// captureThoughtSignatures keeps the latest signature of a streamed Gemini chunk as the
+// signature of the turn's tool calls
+func captureThoughtSignatures(chunk *GeminiStreamChunk, state *TransformState) {
+	for _, candidate := range chunk.Candidates {
+		for _, part := range candidate.Content.Parts {
+			if thinking.HasValidSignature(part.ThoughtSignature) {
+				state.thoughtSignature = part.ThoughtSignature
+			}
+		}
+	}
+}
+
+// cacheToolCallSignature caches the turn's signature for a tool call sent to the client
+func cacheToolCallSignature(state *TransformState, toolCallID string) {
+	if state.SessionID == "" || toolCallID == "" || state.thoughtSignature == "" {
+		return
+	}
+	thinking.GlobalSignatureCache().CacheToolSignature(toolSignatureKey(state.SessionID, toolCallID), state.thoughtSignature)
+}
+
+// toolCallSignature returns the cached signature of a tool call, "" if there is none
+func toolCallSignature(sessionID, toolCallID string) string {
+	if sessionID == "" || toolCallID == "" {
+		return ""
+	}
+	return thinking.GlobalSignatureCache().GetToolSignature(toolSignatureKey(sessionID, toolCallID))
+}
+
+// latestToolCallSignature returns the signature cached for the latest tool_use of a Claude
+// history that has one
+func latestToolCallSignature(sessionID string, messages []ClaudeMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "assistant" {
+			continue
+		}
+		blocks, _ := messages[i].Content.([]interface{})
+		for j := len(blocks) - 1; j >= 0; j-- {
+			m, ok := blocks[j].(map[string]interface{})
+			if !ok || m["type"] != "tool_use" {
+				continue
+			}
+			id, _ := m["id"].(string)
+			if signature := toolCallSignature(sessionID, id); signature != "" {
+				return signature
+			}
+		}
+	}
+	return ""
+}
@@ -1,5 +1,7 @@
 package converter
 
+import "github.com/awsl-project/maxx/internal/thinking"
+
 // Claude API types
 
 type ClaudeRequest struct {
@@ -29,10 +31,8 @@ type ClaudeOutputConfig struct {
 	Effort string `json:"effort,omitempty"` // "high", "medium", "low"
 }
 
-type ClaudeMessage struct {
-	Role    string               `json:"role"`
-	Content interface{}          `json:"content"` // string or []ContentBlock
-}
+// ClaudeMessage is shared with the thinking normalization
+type ClaudeMessage = thinking.Message
 
 type ClaudeContentBlock struct {
 	Type      string      `json:"type"`
@@ -52,6 +52,26 @@ type ClaudeContentBlock struct {
 	CacheControl interface{} `json:"cache_control,omitempty"`
 	// Image source
 	Source *ClaudeImageSource `json:"source,omitempty"`
+	// Sources cited by a text block
+	Citations []ClaudeCitation `json:"citations,omitempty"`
+}
+
+// ClaudeCitation cites a web search result in a text block
+type ClaudeCitation struct {
+	Type           string `json:"type"` // "web_search_result_location"
+	URL            string `json:"url"`
+	Title          string `json:"title"`
+	EncryptedIndex string `json:"encrypted_index"`
+	CitedText      string `json:"cited_text"`
+}
+
+// ClaudeWebSearchResult is an entry of a web_search_tool_result block
+type ClaudeWebSearchResult struct {
+	Type             string  `json:"type"` // "web_search_result"
+	URL              string  `json:"url"`
+	Title            string  `json:"title"`
+	EncryptedContent string  `json:"encrypted_content"`
+	PageAge          *string `json:"page_age"`
 }
 
 // ClaudeImageSource represents image source in Claude API
@@ -95,10 +115,16 @@ type ClaudeResponse struct {
 }
 
 type ClaudeUsage struct {
-	InputTokens              int `json:"input_tokens"`
-	OutputTokens             int `json:"output_tokens"`
-	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
-	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	InputTokens              int                    `json:"input_tokens"`
+	OutputTokens             int                    `json:"output_tokens"`
+	CacheReadInputTokens     int                    `json:"cache_read_input_tokens,omitempty"`
+	CacheCreationInputTokens int                    `json:"cache_creation_input_tokens,omitempty"`
+	ServerToolUse            *ClaudeServerToolUsage `json:"server_tool_use,omitempty"`
+}
+
+// ClaudeServerToolUsage counts server-side tool invocations
+type ClaudeServerToolUsage struct {
+	WebSearchRequests int `json:"web_search_requests"`
 }
 
 // Claude streaming events
@@ -34,6 +34,11 @@ type GeminiFunctionCall struct {
 	Name string                 `json:"name"`
 	Args map[string]interface{} `json:"args"`
 	ID   string                 `json:"id,omitempty"` // Required for v1internal
+
+	// Streamed args (streamFunctionCallArguments): fragments of the args, and whether more
+	// fragments of this call follow
+	PartialArgs  []GeminiPartialArg `json:"partialArgs,omitempty"`
+	WillContinue bool               `json:"willContinue,omitempty"`
 }
 
 type GeminiFunctionResponse struct {
@@ -92,10 +97,45 @@ type GeminiResponse struct {
 }
 
 type GeminiCandidate struct {
-	Content       GeminiContent       `json:"content"`
-	FinishReason  string              `json:"finishReason,omitempty"`
-	SafetyRatings []GeminiSafetyRating `json:"safetyRatings,omitempty"`
-	Index         int                 `json:"index"`
+	Content           GeminiContent            `json:"content"`
+	FinishReason      string                   `json:"finishReason,omitempty"`
+	SafetyRatings     []GeminiSafetyRating     `json:"safetyRatings,omitempty"`
+	GroundingMetadata *GeminiGroundingMetadata `json:"groundingMetadata,omitempty"`
+	Index             int                      `json:"index"`
+}
+
+// GeminiGroundingMetadata holds the web search results a grounded response is based on
+type GeminiGroundingMetadata struct {
+	WebSearchQueries  []string                 `json:"webSearchQueries,omitempty"`
+	GroundingChunks   []GeminiGroundingChunk   `json:"groundingChunks,omitempty"`
+	GroundingSupports []GeminiGroundingSupport `json:"groundingSupports,omitempty"`
+	SearchEntryPoint  *GeminiSearchEntryPoint  `json:"searchEntryPoint,omitempty"`
+}
+
+type GeminiGroundingChunk struct {
+	Web *GeminiGroundingWeb `json:"web,omitempty"`
+}
+
+type GeminiGroundingWeb struct {
+	URI   string `json:"uri,omitempty"`
+	Title string `json:"title,omitempty"`
+}
+
+// GeminiGroundingSupport links a segment of the response text to the chunks supporting it
+type GeminiGroundingSupport struct {
+	Segment               *GeminiGroundingSegment `json:"segment,omitempty"`
+	GroundingChunkIndices []int                   `json:"groundingChunkIndices,omitempty"`
+	ConfidenceScores      []float64               `json:"confidenceScores,omitempty"`
+}
+
+type GeminiGroundingSegment struct {
+	StartIndex int    `json:"startIndex,omitempty"`
+	EndIndex   int    `json:"endIndex,omitempty"`
+	Text       string `json:"text,omitempty"`
+}
+
+type GeminiSearchEntryPoint struct {
+	RenderedContent string `json:"renderedContent,omitempty"`
 }
 
 type GeminiSafetyRating struct {
@@ -19,6 +19,7 @@ type OpenAIRequest struct {
 	Tools            []OpenAITool     `json:"tools,omitempty"`
 	ToolChoice       interface{}      `json:"tool_choice,omitempty"`
 	ResponseFormat   *OpenAIResponseFormat `json:"response_format,omitempty"`
+	ReasoningEffort  string           `json:"reasoning_effort,omitempty"` // "none", "low", "medium", "high"
 }
 
 type OpenAIMessage struct {
@@ -27,6 +28,7 @@ type OpenAIMessage struct {
 	Name       string          `json:"name,omitempty"`
 	ToolCalls  []OpenAIToolCall `json:"tool_calls,omitempty"`
 	ToolCallID string          `json:"tool_call_id,omitempty"`
+	ReasoningContent string    `json:"reasoning_content,omitempty"`
 }
 
 type OpenAIContentPart struct {
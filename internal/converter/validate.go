@@ -0,0 +1,278 @@
+package converter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// ValidationError is a client request that doesn't match the schema of its format,
+// found before the request was routed
+type ValidationError struct {
+	ClientType domain.ClientType
+	Field      string // Path of the offending field, e.g. "messages[2].content" (empty if the body itself)
+	Reason     string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("invalid %s request: %s: %s", e.ClientType, e.Field, e.Reason)
+	}
+	return fmt.Sprintf("invalid %s request: %s", e.ClientType, e.Reason)
+}
+
+func (e *ValidationError) Is(target error) bool {
+	return target == domain.ErrInvalidRequest
+}
+
+// APIError returns the 400 error to report to the client
+func (e *ValidationError) APIError() *APIError {
+	return &APIError{
+		Status:  http.StatusBadRequest,
+		Type:    ErrorTypeInvalidRequest,
+		Message: e.Error(),
+		Field:   e.Field,
+	}
+}
+
+// ValidateRequest checks a client request against its format before it is routed, so a
+// malformed request is rejected with the offending field and reason instead of failing
+// upstream after an attempt was spent. Besides the structure the transformers rely on,
+// it checks the top-level fields every upstream of the format requires. Returns a
+// *ValidationError.
+func ValidateRequest(clientType domain.ClientType, body []byte) error {
+	err := validateRequest(clientType, body)
+	if err == nil {
+		err = validateTopLevel(clientType, body)
+	}
+	if err == nil {
+		return nil
+	}
+
+	validationErr := &ValidationError{ClientType: clientType, Reason: err.Error()}
+	var convErr *ConversionError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &convErr):
+		validationErr.Field, validationErr.Reason = convErr.Field, convErr.Reason
+	case errors.As(err, &syntaxErr):
+		validationErr.Reason = fmt.Sprintf("invalid JSON at offset %d: %v", syntaxErr.Offset, syntaxErr)
+	case errors.As(err, &typeErr):
+		validationErr.Reason = "body must be a JSON object"
+	}
+	return validationErr
+}
+
+// validateTopLevel checks the scalar request fields: the model (Gemini's is in the URL)
+// and the types of the common generation parameters
+func validateTopLevel(clientType domain.ClientType, body []byte) error {
+	var root map[string]interface{}
+	if err := json.Unmarshal(body, &root); err != nil {
+		return err
+	}
+	if clientType == domain.ClientTypeGemini {
+		return nil
+	}
+
+	if model, ok := root["model"].(string); !ok || model == "" {
+		return fieldError("model", "is required")
+	}
+	if v, ok := root["stream"]; ok {
+		if _, ok := v.(bool); !ok {
+			return fieldError("stream", "must be a boolean")
+		}
+	}
+	for _, key := range []string{"max_tokens", "max_completion_tokens", "max_output_tokens"} {
+		if v, ok := root[key]; ok && v != nil {
+			if n, ok := v.(float64); !ok || n < 1 || n != float64(int64(n)) {
+				return fieldError(key, "must be a positive integer")
+			}
+		}
+	}
+	if v, ok := root["temperature"]; ok && v != nil {
+		if _, ok := v.(float64); !ok {
+			return fieldError("temperature", "must be a number")
+		}
+	}
+	if v, ok := root["tools"]; ok && v != nil {
+		if _, ok := v.([]interface{}); !ok {
+			return fieldError("tools", "must be an array")
+		}
+	}
+	return nil
+}
+
+// validateRequest checks the structure the request transformers rely on, so malformed
+// requests are rejected with the offending field instead of being silently mangled.
+// Only structure is checked; unknown block types and extra fields are left alone.
+func validateRequest(clientType domain.ClientType, body []byte) error {
+	var root map[string]interface{}
+	if err := json.Unmarshal(body, &root); err != nil {
+		return err
+	}
+
+	switch clientType {
+	case domain.ClientTypeClaude:
+		return validateClaudeRequest(root)
+	case domain.ClientTypeOpenAI:
+		return validateOpenAIRequest(root)
+	case domain.ClientTypeCodex:
+		return validateCodexRequest(root)
+	case domain.ClientTypeGemini:
+		// Gemini CLI wraps the request in an envelope
+		if inner, ok := root["request"].(map[string]interface{}); ok {
+			return validateGeminiRequest(inner, "request.")
+		}
+		return validateGeminiRequest(root, "")
+	}
+	return nil
+}
+
+func validateClaudeRequest(root map[string]interface{}) error {
+	messages, err := requireArray(root, "messages", "messages")
+	if err != nil {
+		return err
+	}
+	for i, m := range messages {
+		path := fmt.Sprintf("messages[%d]", i)
+		msg, ok := m.(map[string]interface{})
+		if !ok {
+			return fieldError(path, "must be an object")
+		}
+		if role, _ := msg["role"].(string); role != "user" && role != "assistant" {
+			return fieldError(path+".role", "must be \"user\" or \"assistant\"")
+		}
+		switch content := msg["content"].(type) {
+		case string:
+		case []interface{}:
+			for j, b := range content {
+				if err := validateClaudeBlock(b, fmt.Sprintf("%s.content[%d]", path, j)); err != nil {
+					return err
+				}
+			}
+		default:
+			return fieldError(path+".content", "must be a string or an array of content blocks")
+		}
+	}
+	return nil
+}
+
+func validateClaudeBlock(b interface{}, path string) error {
+	block, ok := b.(map[string]interface{})
+	if !ok {
+		return fieldError(path, "must be an object")
+	}
+	blockType, ok := block["type"].(string)
+	if !ok || blockType == "" {
+		return fieldError(path+".type", "is required")
+	}
+	switch blockType {
+	case "text":
+		if _, ok := block["text"].(string); !ok {
+			return fieldError(path+".text", "must be a string")
+		}
+	case "tool_use":
+		if name, _ := block["name"].(string); name == "" {
+			return fieldError(path+".name", "is required for tool_use blocks")
+		}
+	case "tool_result":
+		if id, _ := block["tool_use_id"].(string); id == "" {
+			return fieldError(path+".tool_use_id", "is required for tool_result blocks")
+		}
+	}
+	return nil
+}
+
+func validateOpenAIRequest(root map[string]interface{}) error {
+	messages, err := requireArray(root, "messages", "messages")
+	if err != nil {
+		return err
+	}
+	for i, m := range messages {
+		path := fmt.Sprintf("messages[%d]", i)
+		msg, ok := m.(map[string]interface{})
+		if !ok {
+			return fieldError(path, "must be an object")
+		}
+		if role, _ := msg["role"].(string); role == "" {
+			return fieldError(path+".role", "is required")
+		}
+		switch msg["content"].(type) {
+		case nil, string, []interface{}:
+		default:
+			return fieldError(path+".content", "must be a string or an array of content parts")
+		}
+		if calls, ok := msg["tool_calls"].([]interface{}); ok {
+			for j, c := range calls {
+				callPath := fmt.Sprintf("%s.tool_calls[%d]", path, j)
+				call, ok := c.(map[string]interface{})
+				if !ok {
+					return fieldError(callPath, "must be an object")
+				}
+				fn, ok := call["function"].(map[string]interface{})
+				if !ok {
+					return fieldError(callPath+".function", "is required")
+				}
+				if name, _ := fn["name"].(string); name == "" {
+					return fieldError(callPath+".function.name", "is required")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func validateCodexRequest(root map[string]interface{}) error {
+	switch input := root["input"].(type) {
+	case nil, string:
+	case []interface{}:
+		for i, item := range input {
+			if _, ok := item.(map[string]interface{}); !ok {
+				return fieldError(fmt.Sprintf("input[%d]", i), "must be an object")
+			}
+		}
+	default:
+		return fieldError("input", "must be a string or an array of input items")
+	}
+	return nil
+}
+
+func validateGeminiRequest(root map[string]interface{}, prefix string) error {
+	contents, err := requireArray(root, "contents", prefix+"contents")
+	if err != nil {
+		return err
+	}
+	for i, c := range contents {
+		path := fmt.Sprintf("%scontents[%d]", prefix, i)
+		content, ok := c.(map[string]interface{})
+		if !ok {
+			return fieldError(path, "must be an object")
+		}
+		parts, err := requireArray(content, "parts", path+".parts")
+		if err != nil {
+			return err
+		}
+		for j, p := range parts {
+			if _, ok := p.(map[string]interface{}); !ok {
+				return fieldError(fmt.Sprintf("%s.parts[%d]", path, j), "must be an object")
+			}
+		}
+	}
+	return nil
+}
+
+func requireArray(obj map[string]interface{}, key, path string) ([]interface{}, error) {
+	v, ok := obj[key]
+	if !ok {
+		return nil, fieldError(path, "is required")
+	}
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fieldError(path, "must be an array")
+	}
+	return arr, nil
+}
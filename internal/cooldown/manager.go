@@ -17,9 +17,13 @@ type Manager struct {
 	reasons        map[CooldownKey]CooldownReason    // cooldown key -> reason
 	failureTracker *FailureTracker                   // tracks failure counts
 	policies       map[CooldownReason]CooldownPolicy // cooldown calculation strategies
+	events         map[CooldownKey]uint64            // cooldown key -> event of the current activation
 	repository     repository.CooldownRepository
 }
 
+// eventRetention is how long cooldown events are kept for analytics
+const eventRetention = 30 * 24 * time.Hour
+
 // NewManager creates a new cooldown manager
 func NewManager() *Manager {
 	return &Manager{
@@ -27,6 +31,7 @@ func NewManager() *Manager {
 		reasons:        make(map[CooldownKey]CooldownReason),
 		failureTracker: NewFailureTracker(),
 		policies:       DefaultPolicies(),
+		events:         make(map[CooldownKey]uint64),
 	}
 }
 
@@ -153,6 +158,7 @@ func (m *Manager) RecordSuccess(providerID uint64, clientType string) {
 
 	// Clear cooldown from memory
 	key := CooldownKey{ProviderID: providerID, ClientType: clientType}
+	m.endEventLocked(key)
 	delete(m.cooldowns, key)
 	delete(m.reasons, key)
 
@@ -172,6 +178,7 @@ func (m *Manager) RecordSuccess(providerID uint64, clientType string) {
 // setCooldownLocked sets cooldown without acquiring lock (internal use only)
 func (m *Manager) setCooldownLocked(providerID uint64, clientType string, until time.Time, reason CooldownReason) {
 	key := CooldownKey{ProviderID: providerID, ClientType: clientType}
+	m.recordEventLocked(key, until, reason)
 	m.cooldowns[key] = until
 	m.reasons[key] = reason
 
@@ -189,6 +196,51 @@ func (m *Manager) setCooldownLocked(providerID uint64, clientType string, until
 	}
 }
 
+// recordEventLocked records a cooldown activation for analytics. Setting a cooldown that
+// is still active with the same reason (a later failure, or the real quota reset time
+// arriving) extends the current activation instead of starting a new one.
+func (m *Manager) recordEventLocked(key CooldownKey, until time.Time, reason CooldownReason) {
+	if m.repository == nil {
+		return
+	}
+	now := time.Now()
+	if id, ok := m.events[key]; ok && m.reasons[key] == reason && now.Before(m.cooldowns[key]) {
+		if err := m.repository.UpdateEventUntil(id, until); err != nil {
+			log.Printf("[Cooldown] Failed to update cooldown event for provider %d: %v", key.ProviderID, err)
+		}
+		return
+	}
+
+	event := &domain.CooldownEvent{
+		ProviderID: key.ProviderID,
+		ClientType: key.ClientType,
+		Reason:     domain.CooldownReason(reason),
+		StartTime:  now,
+		UntilTime:  until,
+	}
+	if err := m.repository.CreateEvent(event); err != nil {
+		log.Printf("[Cooldown] Failed to record cooldown event for provider %d: %v", key.ProviderID, err)
+		delete(m.events, key)
+		return
+	}
+	m.events[key] = event.ID
+}
+
+// endEventLocked ends the current activation now, for a cooldown cleared before it expired.
+// Must be called before the cooldown is removed from m.cooldowns.
+func (m *Manager) endEventLocked(key CooldownKey) {
+	id, ok := m.events[key]
+	if !ok {
+		return
+	}
+	delete(m.events, key)
+	if now := time.Now(); m.repository != nil && now.Before(m.cooldowns[key]) {
+		if err := m.repository.UpdateEventUntil(id, now); err != nil {
+			log.Printf("[Cooldown] Failed to update cooldown event for provider %d: %v", key.ProviderID, err)
+		}
+	}
+}
+
 // SetCooldownDuration sets a cooldown for a provider with a duration from now
 // clientType is optional - empty string means cooldown applies to all client types
 func (m *Manager) SetCooldownDuration(providerID uint64, clientType string, duration time.Duration) {
@@ -199,6 +251,18 @@ func (m *Manager) SetCooldownDuration(providerID uint64, clientType string, dura
 	m.setCooldownLocked(providerID, clientType, until, ReasonUnknown)
 }
 
+// SetManualCooldown pauses a provider for the given duration on behalf of the user
+// The cooldown is recorded with ReasonManual and does not touch failure counts
+func (m *Manager) SetManualCooldown(providerID uint64, clientType string, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	until := time.Now().Add(duration)
+	m.setCooldownLocked(providerID, clientType, until, ReasonManual)
+	log.Printf("[Cooldown] Provider %d (clientType=%s): Manually paused until %s",
+		providerID, clientType, until.Format("2006-01-02 15:04:05"))
+}
+
 // ClearCooldown removes the cooldown for a provider
 // If clientType is empty, clears ALL cooldowns for the provider (both global and specific)
 // If clientType is specified, only clears that specific cooldown
@@ -215,6 +279,7 @@ func (m *Manager) ClearCooldown(providerID uint64, clientType string) {
 			}
 		}
 		for _, key := range keysToDelete {
+			m.endEventLocked(key)
 			delete(m.cooldowns, key)
 			delete(m.reasons, key)
 		}
@@ -231,6 +296,7 @@ func (m *Manager) ClearCooldown(providerID uint64, clientType string) {
 	} else {
 		// Clear specific cooldown
 		key := CooldownKey{ProviderID: providerID, ClientType: clientType}
+		m.endEventLocked(key)
 		delete(m.cooldowns, key)
 		delete(m.reasons, key)
 
@@ -331,6 +397,7 @@ func (m *Manager) CleanupExpired() {
 
 	for key, until := range m.cooldowns {
 		if now.After(until) {
+			m.endEventLocked(key)
 			delete(m.cooldowns, key)
 			delete(m.reasons, key)
 			expiredKeys = append(expiredKeys, key)
@@ -347,6 +414,9 @@ func (m *Manager) CleanupExpired() {
 		if err := m.repository.DeleteExpired(); err != nil {
 			log.Printf("[Cooldown] Failed to delete expired cooldowns from database: %v", err)
 		}
+		if err := m.repository.DeleteEventsBefore(now.Add(-eventRetention)); err != nil {
+			log.Printf("[Cooldown] Failed to delete old cooldown events from database: %v", err)
+		}
 	}
 
 	// Cleanup old failure counts (older than 24 hours)
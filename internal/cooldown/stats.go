@@ -0,0 +1,98 @@
+package cooldown
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Stats aggregates the cooldown activations started in [from, to) by provider and reason,
+// with a timeline bucketed by hour or day. Provider names are left for the caller to fill.
+func (m *Manager) Stats(from, to time.Time, bucket domain.Granularity) (*domain.CooldownStats, error) {
+	m.mu.RLock()
+	repo := m.repository
+	m.mu.RUnlock()
+	if repo == nil {
+		return nil, errors.New("cooldown repository not configured")
+	}
+
+	var size time.Duration
+	switch bucket {
+	case domain.GranularityHour:
+		size = time.Hour
+	case domain.GranularityDay:
+		size = 24 * time.Hour
+	default:
+		return nil, errors.New("bucket must be hour or day")
+	}
+
+	events, err := repo.ListEvents(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return aggregateEvents(events, from, to, bucket, size), nil
+}
+
+func aggregateEvents(events []*domain.CooldownEvent, from, to time.Time, bucket domain.Granularity, size time.Duration) *domain.CooldownStats {
+	providers := make(map[uint64]*domain.CooldownProviderStats)
+	reasons := make(map[uint64]map[domain.CooldownReason]*domain.CooldownReasonStats)
+	buckets := make(map[uint64]map[time.Time]*domain.CooldownStatsBucket)
+
+	for _, e := range events {
+		p, ok := providers[e.ProviderID]
+		if !ok {
+			p = &domain.CooldownProviderStats{ProviderID: e.ProviderID}
+			providers[e.ProviderID] = p
+			reasons[e.ProviderID] = make(map[domain.CooldownReason]*domain.CooldownReasonStats)
+			buckets[e.ProviderID] = make(map[time.Time]*domain.CooldownStatsBucket)
+		}
+		d := max(e.Duration().Milliseconds(), 0)
+		p.Count++
+		p.TotalDurationMs += d
+
+		r, ok := reasons[e.ProviderID][e.Reason]
+		if !ok {
+			r = &domain.CooldownReasonStats{Reason: e.Reason}
+			reasons[e.ProviderID][e.Reason] = r
+			p.Reasons = append(p.Reasons, r)
+		}
+		r.Count++
+		r.TotalDurationMs += d
+		r.MaxDurationMs = max(r.MaxDurationMs, d)
+
+		t := e.StartTime.UTC().Truncate(size)
+		b, ok := buckets[e.ProviderID][t]
+		if !ok {
+			b = &domain.CooldownStatsBucket{Time: t, Counts: make(map[domain.CooldownReason]int)}
+			buckets[e.ProviderID][t] = b
+			// Events are listed oldest first, so the timeline stays in order
+			p.Timeline = append(p.Timeline, b)
+		}
+		b.Counts[e.Reason]++
+		b.DurationMs += d
+	}
+
+	stats := &domain.CooldownStats{
+		From:      from,
+		To:        to,
+		Bucket:    bucket,
+		Providers: make([]*domain.CooldownProviderStats, 0, len(providers)),
+	}
+	for _, p := range providers {
+		for _, r := range p.Reasons {
+			r.AvgDurationMs = r.TotalDurationMs / int64(r.Count)
+		}
+		sort.SliceStable(p.Reasons, func(i, j int) bool { return p.Reasons[i].Count > p.Reasons[j].Count })
+		stats.Providers = append(stats.Providers, p)
+	}
+	sort.Slice(stats.Providers, func(i, j int) bool {
+		a, b := stats.Providers[i], stats.Providers[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.ProviderID < b.ProviderID
+	})
+	return stats
+}
@@ -0,0 +1,264 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/adapter/provider"
+	"github.com/awsl-project/maxx/internal/adapter/provider/antigravity"
+	"github.com/awsl-project/maxx/internal/adapter/provider/kiro"
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
+	"github.com/awsl-project/maxx/internal/failback"
+	"github.com/awsl-project/maxx/internal/repository"
+	"github.com/awsl-project/maxx/internal/repository/cached"
+	"github.com/awsl-project/maxx/internal/routingprofile"
+	"github.com/awsl-project/maxx/internal/service"
+	"github.com/awsl-project/maxx/internal/webhook"
+)
+
+// ConfigReportMessageType 启动配置检查报告事件类型（有问题时才广播）
+const ConfigReportMessageType = "config_report"
+
+// 启动配置检查的总超时
+const startupConfigCheckTimeout = 2 * time.Minute
+
+// 空闲会话检查间隔
+const sessionExpiryInterval = 10 * time.Minute
+
+// 费用告警检查间隔
+const costAlertInterval = 5 * time.Minute
+
+// 延迟 SLO 检查间隔
+const latencySLOInterval = time.Minute
+
+// Webhook 单次发送的超时
+const webhookTimeout = 15 * time.Second
+
+// 故障恢复探测请求使用的模型（按 Provider 类型）
+var probeModels = map[string]string{
+	"antigravity": "gemini-2.5-flash",
+	"kiro":        "claude-sonnet-4-5",
+}
+
+// ValidateProviderCredentials 通过刷新 access token 校验 Provider 的 refresh token
+// 目前支持 antigravity 和 kiro social 认证；其他类型不做检查
+func ValidateProviderCredentials(ctx context.Context, p *domain.Provider) error {
+	switch {
+	case p.Type == "antigravity" && p.Config.Antigravity != nil:
+		result, err := antigravity.ValidateRefreshToken(ctx, p.Config.Antigravity.RefreshToken)
+		if err != nil {
+			return err
+		}
+		if !result.Valid {
+			return errors.New(result.Error)
+		}
+	case p.Type == "kiro" && p.Config.Kiro != nil && p.Config.Kiro.AuthMethod != "idc":
+		result, err := kiro.ValidateSocialToken(ctx, p.Config.Kiro.RefreshToken)
+		if err != nil {
+			return err
+		}
+		if !result.Valid {
+			return errors.New(result.Error)
+		}
+	}
+	return nil
+}
+
+// ProbeProviderRequest 通过 Provider 的适配器发送一个最小的 Claude 请求（max_tokens 为 1），
+// 上游返回错误或出错状态码时视为不健康；没有探测模型的类型退回到凭证校验
+func ProbeProviderRequest(ctx context.Context, p *domain.Provider) error {
+	model, ok := probeModels[p.Type]
+	factory, hasFactory := provider.GetAdapterFactory(p.Type)
+	if !ok || !hasFactory {
+		return ValidateProviderCredentials(ctx, p)
+	}
+	adapter, err := factory(p)
+	if err != nil {
+		return err
+	}
+
+	const uri = "/v1/messages"
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      model,
+		"max_tokens": 1,
+		"stream":     false,
+		"messages": []map[string]interface{}{
+			{"role": "user", "content": "ping"},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	headers.Set("anthropic-version", "2023-06-01")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, strings.NewReader(string(body)))
+	if err != nil {
+		return err
+	}
+	req.Header = headers
+
+	ctx = ctxutil.WithDiagnostic(ctx, true)
+	ctx = ctxutil.WithClientType(ctx, domain.ClientTypeClaude)
+	ctx = ctxutil.WithRequestModel(ctx, model)
+	ctx = ctxutil.WithMappedModel(ctx, model)
+	ctx = ctxutil.WithRequestBody(ctx, body)
+	ctx = ctxutil.WithRequestHeaders(ctx, headers)
+	ctx = ctxutil.WithRequestURI(ctx, uri)
+	ctx = ctxutil.WithIsStream(ctx, false)
+
+	w := &probeResponseWriter{header: make(http.Header)}
+	if err := adapter.Execute(ctx, w, req.WithContext(ctx), p); err != nil {
+		return err
+	}
+	if w.status >= http.StatusBadRequest {
+		return fmt.Errorf("probe request returned status %d", w.status)
+	}
+	return nil
+}
+
+// probeResponseWriter 丢弃探测请求的响应，只记录状态码
+type probeResponseWriter struct {
+	header http.Header
+	status int
+}
+
+func (w *probeResponseWriter) Header() http.Header { return w.header }
+
+func (w *probeResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (w *probeResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *probeResponseWriter) Flush() {}
+
+// CheckConfigOnStartup 启动时检查配置，记录日志并广播报告，使错误的配置能被立即发现
+func CheckConfigOnStartup(adminService *service.AdminService, broadcaster event.Broadcaster) {
+	ctx, cancel := context.WithTimeout(context.Background(), startupConfigCheckTimeout)
+	defer cancel()
+
+	report := adminService.CheckConfig(ctx)
+	if len(report.Issues) == 0 {
+		log.Printf("[Core] Config check passed")
+		return
+	}
+
+	log.Printf("[Core] Config check found %d errors, %d warnings", report.Errors, report.Warnings)
+	for _, issue := range report.Issues {
+		log.Printf("[Core] Config %s: %s", issue.Severity, formatConfigIssue(issue))
+	}
+	if broadcaster != nil {
+		broadcaster.BroadcastMessage(ConfigReportMessageType, report)
+	}
+}
+
+func formatConfigIssue(issue *domain.ConfigIssue) string {
+	resource := fmt.Sprintf("%s %d", issue.Kind, issue.ResourceID)
+	if issue.ResourceName != "" {
+		resource = fmt.Sprintf("%s %d (%s)", issue.Kind, issue.ResourceID, issue.ResourceName)
+	}
+	return fmt.Sprintf("%s [%s]: %s", resource, issue.Code, issue.Message)
+}
+
+// StartFailback 启动 Provider 故障恢复探测，配置在每轮探测前从系统设置读取
+func StartFailback(adminService *service.AdminService, settingRepo repository.SystemSettingRepository, providerRepo *cached.ProviderRepository) {
+	failback.Default().Start(func() failback.Config {
+		return failback.ConfigFromSettings(settingRepo.Get)
+	}, providerRepo.GetAll, adminService.ProbeProvider)
+}
+
+// StartRoutingProfileScheduler 启动路由方案定时切换，每分钟检查一次时间表
+func StartRoutingProfileScheduler(adminService *service.AdminService) {
+	routingprofile.Default().Start(adminService.GetRoutingProfiles, func(id uint64) error {
+		_, err := adminService.ApplyRoutingProfile(id)
+		return err
+	}, adminService.ActiveRoutingProfileID)
+}
+
+// StartSessionExpiry 定期解除绑定或归档空闲会话，配置在每次检查前从系统设置读取
+func StartSessionExpiry(adminService *service.AdminService, broadcaster event.Broadcaster) {
+	go func() {
+		ticker := time.NewTicker(sessionExpiryInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			idleHours, action := adminService.SessionIdleConfig()
+			if idleHours == 0 {
+				continue
+			}
+			result, err := adminService.ExpireIdleSessions(idleHours, action)
+			if err != nil {
+				log.Printf("[Core] Session expiry failed: %v", err)
+				continue
+			}
+			if len(result.SessionIDs) > 0 && broadcaster != nil {
+				broadcaster.BroadcastMessage(service.SessionsExpiredMessageType, result)
+			}
+		}
+	}()
+}
+
+// StartLatencySLOChecks 定期检查延迟 SLO，Provider 持续违反或恢复达标时广播 latency_slo 事件
+func StartLatencySLOChecks(adminService *service.AdminService, broadcaster event.Broadcaster) {
+	go func() {
+		ticker := time.NewTicker(latencySLOInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			events, err := adminService.CheckLatencySLOs()
+			if err != nil {
+				log.Printf("[Core] Latency SLO check failed: %v", err)
+				continue
+			}
+			if broadcaster == nil {
+				continue
+			}
+			for _, e := range events {
+				broadcaster.BroadcastMessage(service.LatencySLOMessageType, e)
+			}
+		}
+	}()
+}
+
+// StartCostAlerts 定期检查费用告警，通知通过广播推送到控制台（桌面版由前端弹出系统通知），
+// 并在配置了 Webhook 地址时发送到 Webhook
+func StartCostAlerts(adminService *service.AdminService, broadcaster event.Broadcaster) {
+	go func() {
+		ticker := time.NewTicker(costAlertInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			notifications, err := adminService.CheckCostAlerts(time.Now())
+			if err != nil {
+				log.Printf("[Core] Cost alert check failed: %v", err)
+				continue
+			}
+			webhookURL := adminService.CostAlertWebhookURL()
+			for _, n := range notifications {
+				if broadcaster != nil {
+					broadcaster.BroadcastMessage(service.CostAlertMessageType, n)
+				}
+				if webhookURL != "" {
+					ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+					if err := webhook.Post(ctx, webhookURL, service.CostAlertMessageType, n); err != nil {
+						log.Printf("[Core] Cost alert webhook failed: %v", err)
+					}
+					cancel()
+				}
+			}
+		}
+	}()
+}
@@ -3,16 +3,28 @@ package core
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/awsl-project/maxx/internal/adapter/client"
+	"github.com/awsl-project/maxx/internal/adapter/provider/antigravity"
 	_ "github.com/awsl-project/maxx/internal/adapter/provider/custom"
+	"github.com/awsl-project/maxx/internal/adapter/provider/stream"
+	"github.com/awsl-project/maxx/internal/audit"
+	"github.com/awsl-project/maxx/internal/blobstore"
+	"github.com/awsl-project/maxx/internal/capability"
 	"github.com/awsl-project/maxx/internal/cooldown"
+	"github.com/awsl-project/maxx/internal/domain"
 	"github.com/awsl-project/maxx/internal/event"
 	"github.com/awsl-project/maxx/internal/executor"
 	"github.com/awsl-project/maxx/internal/handler"
+	"github.com/awsl-project/maxx/internal/i18n"
+	"github.com/awsl-project/maxx/internal/quality"
+	"github.com/awsl-project/maxx/internal/quota"
 	"github.com/awsl-project/maxx/internal/repository"
+	"github.com/awsl-project/maxx/internal/repository/batched"
 	"github.com/awsl-project/maxx/internal/repository/cached"
+	"github.com/awsl-project/maxx/internal/repository/memory"
 	"github.com/awsl-project/maxx/internal/repository/sqlite"
 	"github.com/awsl-project/maxx/internal/router"
 	"github.com/awsl-project/maxx/internal/service"
@@ -24,8 +36,10 @@ import (
 type DatabaseConfig struct {
 	DataDir string
 	DBPath  string // SQLite file path (legacy)
-	DSN     string // Database DSN (mysql://... or sqlite://...)
+	DSN     string // Database DSN (mysql://..., sqlite://... or memory://)
 	LogPath string
+	// 大请求/响应体的存放目录；为空时 SQLite 使用 DataDir/blobs、MySQL 不启用，"off" 表示关闭
+	BlobDir string
 }
 
 // DatabaseRepos 包含所有数据库仓库
@@ -37,10 +51,14 @@ type DatabaseRepos struct {
 	SessionRepo              repository.SessionRepository
 	RetryConfigRepo          repository.RetryConfigRepository
 	RoutingStrategyRepo       repository.RoutingStrategyRepository
-	ProxyRequestRepo         repository.ProxyRequestRepository
-	AttemptRepo              repository.ProxyUpstreamAttemptRepository
+	RoutingProfileRepo       repository.RoutingProfileRepository
+	CostAlertRepo            repository.CostAlertRepository
+	LatencySLORepo           repository.LatencySLORepository
+	ProxyRequestRepo         *batched.ProxyRequestRepository
+	AttemptRepo              *batched.ProxyUpstreamAttemptRepository
 	SettingRepo              repository.SystemSettingRepository
 	AntigravityQuotaRepo     repository.AntigravityQuotaRepository
+	OAuthTokenRepo           repository.OAuthTokenRepository
 	CooldownRepo             repository.CooldownRepository
 	FailureCountRepo         repository.FailureCountRepository
 	CachedProviderRepo        *cached.ProviderRepository
@@ -53,6 +71,7 @@ type DatabaseRepos struct {
 	CachedAPITokenRepo       *cached.APITokenRepository
 	ModelMappingRepo         repository.ModelMappingRepository
 	CachedModelMappingRepo   *cached.ModelMappingRepository
+	ModelCapabilityRepo      repository.ModelCapabilityRepository
 	UsageStatsRepo           repository.UsageStatsRepository
 	ResponseModelRepo        repository.ResponseModelRepository
 }
@@ -70,85 +89,139 @@ type ServerComponents struct {
 	AntigravityHandler  *handler.AntigravityHandler
 	KiroHandler         *handler.KiroHandler
 	ProjectProxyHandler *handler.ProjectProxyHandler
+	StatusHandler       *handler.StatusHandler
+
+	// 启动时的崩溃恢复结果，nil 表示没有需要恢复的记录；由调用方在广播器就绪后广播
+	RecoverySummary *domain.RecoverySummary
 }
 
 // InitializeDatabase 初始化数据库和所有仓库
+// DSN 为 memory:// 时使用纯内存仓库（不落盘、不保留历史），用于压测和 CI
 func InitializeDatabase(config *DatabaseConfig) (*DatabaseRepos, error) {
-	var db *sqlite.DB
-	var err error
+	var repos *DatabaseRepos
 
-	// 优先使用 DSN，否则使用 DBPath（向后兼容）
-	if config.DSN != "" {
-		log.Printf("[Core] Initializing database with DSN")
-		db, err = sqlite.NewDBWithDSN(config.DSN)
+	if memory.IsDSN(config.DSN) {
+		log.Printf("[Core] Using in-memory repositories (nothing is persisted)")
+		repos = newMemoryRepos()
 	} else {
-		log.Printf("[Core] Initializing database: %s", config.DBPath)
-		db, err = sqlite.NewDB(config.DBPath)
-	}
-	if err != nil {
-		return nil, err
+		var db *sqlite.DB
+		var err error
+
+		// 优先使用 DSN，否则使用 DBPath（向后兼容）
+		if config.DSN != "" {
+			log.Printf("[Core] Initializing database with DSN")
+			db, err = sqlite.NewDBWithDSN(config.DSN)
+		} else {
+			log.Printf("[Core] Initializing database: %s", config.DBPath)
+			db, err = sqlite.NewDB(config.DBPath)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if blobDir := resolveBlobDir(config, db.Dialector()); blobDir != "" {
+			store, err := blobstore.NewFSStore(blobDir)
+			if err != nil {
+				return nil, err
+			}
+			log.Printf("[Core] Storing large request/response bodies in %s", blobDir)
+			db.SetBlobStore(store)
+		}
+		repos = newSQLRepos(db)
 	}
 
-	providerRepo := sqlite.NewProviderRepository(db)
-	routeRepo := sqlite.NewRouteRepository(db)
-	projectRepo := sqlite.NewProjectRepository(db)
-	sessionRepo := sqlite.NewSessionRepository(db)
-	retryConfigRepo := sqlite.NewRetryConfigRepository(db)
-	routingStrategyRepo := sqlite.NewRoutingStrategyRepository(db)
-	proxyRequestRepo := sqlite.NewProxyRequestRepository(db)
-	attemptRepo := sqlite.NewProxyUpstreamAttemptRepository(db)
-	settingRepo := sqlite.NewSystemSettingRepository(db)
-	antigravityQuotaRepo := sqlite.NewAntigravityQuotaRepository(db)
-	cooldownRepo := sqlite.NewCooldownRepository(db)
-	failureCountRepo := sqlite.NewFailureCountRepository(db)
-	apiTokenRepo := sqlite.NewAPITokenRepository(db)
-	modelMappingRepo := sqlite.NewModelMappingRepository(db)
-	usageStatsRepo := sqlite.NewUsageStatsRepository(db)
-	responseModelRepo := sqlite.NewResponseModelRepository(db)
-
 	log.Printf("[Core] Creating cached repositories")
 
-	cachedProviderRepo := cached.NewProviderRepository(providerRepo)
-	cachedRouteRepo := cached.NewRouteRepository(routeRepo)
-	cachedRetryConfigRepo := cached.NewRetryConfigRepository(retryConfigRepo)
-	cachedRoutingStrategyRepo := cached.NewRoutingStrategyRepository(routingStrategyRepo)
-	cachedSessionRepo := cached.NewSessionRepository(sessionRepo)
-	cachedProjectRepo := cached.NewProjectRepository(projectRepo)
-	cachedAPITokenRepo := cached.NewAPITokenRepository(apiTokenRepo)
-	cachedModelMappingRepo := cached.NewModelMappingRepository(modelMappingRepo)
-
-	repos := &DatabaseRepos{
-		DB:                       db,
-		ProviderRepo:             providerRepo,
-		RouteRepo:                routeRepo,
-		ProjectRepo:              projectRepo,
-		SessionRepo:              sessionRepo,
-		RetryConfigRepo:          retryConfigRepo,
-		RoutingStrategyRepo:       routingStrategyRepo,
-		ProxyRequestRepo:         proxyRequestRepo,
-		AttemptRepo:              attemptRepo,
-		SettingRepo:              settingRepo,
-		AntigravityQuotaRepo:     antigravityQuotaRepo,
-		CooldownRepo:             cooldownRepo,
-		FailureCountRepo:         failureCountRepo,
-		CachedProviderRepo:        cachedProviderRepo,
-		CachedRouteRepo:          cachedRouteRepo,
-		CachedRetryConfigRepo:    cachedRetryConfigRepo,
-		CachedRoutingStrategyRepo: cachedRoutingStrategyRepo,
-		CachedSessionRepo:        cachedSessionRepo,
-		CachedProjectRepo:        cachedProjectRepo,
-		APITokenRepo:             apiTokenRepo,
-		CachedAPITokenRepo:       cachedAPITokenRepo,
-		ModelMappingRepo:         modelMappingRepo,
-		CachedModelMappingRepo:   cachedModelMappingRepo,
-		UsageStatsRepo:           usageStatsRepo,
-		ResponseModelRepo:        responseModelRepo,
+	repos.CachedProviderRepo = cached.NewProviderRepository(repos.ProviderRepo)
+	repos.CachedRouteRepo = cached.NewRouteRepository(repos.RouteRepo)
+	repos.CachedRetryConfigRepo = cached.NewRetryConfigRepository(repos.RetryConfigRepo)
+	repos.CachedRoutingStrategyRepo = cached.NewRoutingStrategyRepository(repos.RoutingStrategyRepo)
+	repos.CachedSessionRepo = cached.NewSessionRepository(repos.SessionRepo)
+	repos.CachedProjectRepo = cached.NewProjectRepository(repos.ProjectRepo)
+	repos.CachedAPITokenRepo = cached.NewAPITokenRepository(repos.APITokenRepo)
+	repos.CachedModelMappingRepo = cached.NewModelMappingRepository(repos.ModelMappingRepo)
+
+	// 审计日志与数据库放在同一数据目录，是否写入由 audit_log 设置控制
+	// 质量评估样本同样放在数据目录，采样比例由 quality_sample_rates 设置控制
+	if config.DataDir != "" {
+		audit.Default().SetPath(filepath.Join(config.DataDir, "audit.jsonl"))
+		quality.Default().SetPath(filepath.Join(config.DataDir, "quality-samples.jsonl"))
 	}
 
 	log.Printf("[Core] Database initialized successfully")
 	return repos, nil
 }
 
+// resolveBlobDir 确定 blob store 目录
+// MySQL 默认不启用：多实例部署时各实例的本地磁盘不共享
+func resolveBlobDir(config *DatabaseConfig, dialector string) string {
+	switch {
+	case config.BlobDir == "off":
+		return ""
+	case config.BlobDir != "":
+		return config.BlobDir
+	case dialector == "sqlite" && config.DataDir != "":
+		return filepath.Join(config.DataDir, "blobs")
+	}
+	return ""
+}
+
+// newSQLRepos 创建基于 SQLite/MySQL 的仓库
+func newSQLRepos(db *sqlite.DB) *DatabaseRepos {
+	return &DatabaseRepos{
+		DB:                  db,
+		ProviderRepo:        sqlite.NewProviderRepository(db),
+		RouteRepo:           sqlite.NewRouteRepository(db),
+		ProjectRepo:         sqlite.NewProjectRepository(db),
+		SessionRepo:         sqlite.NewSessionRepository(db),
+		RetryConfigRepo:     sqlite.NewRetryConfigRepository(db),
+		RoutingStrategyRepo: sqlite.NewRoutingStrategyRepository(db),
+		RoutingProfileRepo:  sqlite.NewRoutingProfileRepository(db),
+		CostAlertRepo:       sqlite.NewCostAlertRepository(db),
+		LatencySLORepo:      sqlite.NewLatencySLORepository(db),
+		// 请求记录和上游尝试的中间状态更新走 write-behind 队列，减少 SQLite 写竞争
+		ProxyRequestRepo:     batched.NewProxyRequestRepository(sqlite.NewProxyRequestRepository(db), batched.DefaultFlushInterval),
+		AttemptRepo:          batched.NewProxyUpstreamAttemptRepository(sqlite.NewProxyUpstreamAttemptRepository(db), batched.DefaultFlushInterval),
+		SettingRepo:          sqlite.NewSystemSettingRepository(db),
+		AntigravityQuotaRepo: sqlite.NewAntigravityQuotaRepository(db),
+		OAuthTokenRepo:       sqlite.NewOAuthTokenRepository(db),
+		CooldownRepo:         sqlite.NewCooldownRepository(db),
+		FailureCountRepo:     sqlite.NewFailureCountRepository(db),
+		APITokenRepo:         sqlite.NewAPITokenRepository(db),
+		ModelMappingRepo:     sqlite.NewModelMappingRepository(db),
+		ModelCapabilityRepo:  sqlite.NewModelCapabilityRepository(db),
+		UsageStatsRepo:       sqlite.NewUsageStatsRepository(db),
+		ResponseModelRepo:    sqlite.NewResponseModelRepository(db),
+	}
+}
+
+// newMemoryRepos 创建纯内存仓库，只保留最近的请求记录
+func newMemoryRepos() *DatabaseRepos {
+	proxyRequestRepo := memory.NewProxyRequestRepository(memory.DefaultMaxProxyRequests)
+	return &DatabaseRepos{
+		ProviderRepo:         memory.NewProviderRepository(),
+		RouteRepo:            memory.NewRouteRepository(),
+		ProjectRepo:          memory.NewProjectRepository(),
+		SessionRepo:          memory.NewSessionRepository(),
+		RetryConfigRepo:      memory.NewRetryConfigRepository(),
+		RoutingStrategyRepo:  memory.NewRoutingStrategyRepository(),
+		RoutingProfileRepo:   memory.NewRoutingProfileRepository(),
+		CostAlertRepo:        memory.NewCostAlertRepository(),
+		LatencySLORepo:       memory.NewLatencySLORepository(),
+		ProxyRequestRepo:     batched.NewProxyRequestRepository(proxyRequestRepo, batched.DefaultFlushInterval),
+		AttemptRepo:          batched.NewProxyUpstreamAttemptRepository(memory.NewProxyUpstreamAttemptRepository(proxyRequestRepo), batched.DefaultFlushInterval),
+		SettingRepo:          memory.NewSystemSettingRepository(),
+		AntigravityQuotaRepo: memory.NewAntigravityQuotaRepository(),
+		OAuthTokenRepo:       memory.NewOAuthTokenRepository(),
+		CooldownRepo:         memory.NewCooldownRepository(),
+		FailureCountRepo:     memory.NewFailureCountRepository(),
+		APITokenRepo:         memory.NewAPITokenRepository(),
+		ModelMappingRepo:     memory.NewModelMappingRepository(),
+		ModelCapabilityRepo:  memory.NewModelCapabilityRepository(),
+		UsageStatsRepo:       memory.NewUsageStatsRepository(),
+		ResponseModelRepo:    memory.NewResponseModelRepository(),
+	}
+}
+
 // InitializeServerComponents 初始化服务器运行所需的所有组件
 func InitializeServerComponents(
 	repos *DatabaseRepos,
@@ -165,13 +238,15 @@ func InitializeServerComponents(
 		log.Printf("[Core] Warning: Failed to load cooldowns from database: %v", err)
 	}
 
-	log.Printf("[Core] Marking stale requests as failed")
-	if count, err := repos.ProxyRequestRepo.MarkStaleAsFailed(instanceID); err != nil {
-		log.Printf("[Core] Warning: Failed to mark stale requests: %v", err)
-	} else if count > 0 {
-		log.Printf("[Core] Marked %d stale requests as failed", count)
+	log.Printf("[Core] Loading model capabilities")
+	capability.Default().SetRepository(repos.ModelCapabilityRepo)
+	if err := capability.Default().Load(); err != nil {
+		log.Printf("[Core] Warning: Failed to load model capabilities: %v", err)
 	}
 
+	log.Printf("[Core] Recovering stale requests from previous instances")
+	recoverySummary := RecoverStaleRequests(repos.ProxyRequestRepo, repos.AttemptRepo, instanceID)
+
 	log.Printf("[Core] Loading cached data")
 	if err := repos.CachedProviderRepo.Load(); err != nil {
 		log.Printf("[Core] Warning: Failed to load providers cache: %v", err)
@@ -195,6 +270,11 @@ func InitializeServerComponents(
 		log.Printf("[Core] Warning: Failed to load model mappings cache: %v", err)
 	}
 
+	// 从用量统计恢复 Provider 本地配额的当日用量
+	if providers, err := repos.CachedProviderRepo.List(); err == nil {
+		quota.Default().Seed(providers, repos.UsageStatsRepo)
+	}
+
 	log.Printf("[Core] Creating router")
 	r := router.NewRouter(
 		repos.CachedRouteRepo,
@@ -202,6 +282,7 @@ func InitializeServerComponents(
 		repos.CachedRoutingStrategyRepo,
 		repos.CachedRetryConfigRepo,
 		repos.CachedProjectRepo,
+		repos.UsageStatsRepo,
 	)
 
 	log.Printf("[Core] Initializing provider adapters")
@@ -209,6 +290,23 @@ func InitializeServerComponents(
 		log.Printf("[Core] Warning: Failed to initialize adapters: %v", err)
 	}
 
+	// 流式响应的缓冲区大小和刷新间隔从系统设置读取，修改后对新请求生效
+	stream.SetConfigGetter(func() stream.Config {
+		return stream.ConfigFromSettings(repos.SettingRepo.Get)
+	})
+
+	// Executor 在请求时读取的设置（如模型降级链）
+	executor.SetSettingsGetter(repos.SettingRepo.Get)
+
+	// 返回给客户端的错误信息和 OAuth 页面的语言
+	i18n.SetSettingsGetter(repos.SettingRepo.Get)
+
+	// 使用同一 refresh token 的 Antigravity Provider 共享 access token，并持久化以便重启后复用
+	if err := repos.OAuthTokenRepo.DeleteExpired(); err != nil {
+		log.Printf("[Core] Warning: Failed to delete expired access tokens: %v", err)
+	}
+	antigravity.SetTokenStore(repos.OAuthTokenRepo)
+
 	log.Printf("[Core] Starting cooldown cleanup goroutine")
 	go func() {
 		ticker := time.NewTicker(1 * time.Hour)
@@ -266,24 +364,41 @@ func InitializeServerComponents(
 		repos.CachedSessionRepo,
 		repos.CachedRetryConfigRepo,
 		repos.CachedRoutingStrategyRepo,
+		repos.RoutingProfileRepo,
+		repos.CostAlertRepo,
+		repos.LatencySLORepo,
 		repos.ProxyRequestRepo,
 		repos.AttemptRepo,
 		repos.SettingRepo,
 		repos.CachedAPITokenRepo,
 		repos.CachedModelMappingRepo,
+		repos.ModelCapabilityRepo,
 		repos.UsageStatsRepo,
 		repos.ResponseModelRepo,
 		addr,
 		r,
+		r,
 	)
+	adminService.SetCredentialValidator(ValidateProviderCredentials)
+	adminService.SetProviderProber(ProbeProviderRequest)
+	go CheckConfigOnStartup(adminService, wailsBroadcaster)
+	StartFailback(adminService, repos.SettingRepo, repos.CachedProviderRepo)
+	StartRoutingProfileScheduler(adminService)
+	StartSessionExpiry(adminService, wailsBroadcaster)
+	StartCostAlerts(adminService, wailsBroadcaster)
+	StartLatencySLOChecks(adminService, wailsBroadcaster)
 
 	log.Printf("[Core] Creating handlers")
 	tokenAuthMiddleware := handler.NewTokenAuthMiddleware(repos.CachedAPITokenRepo, repos.SettingRepo)
-	proxyHandler := handler.NewProxyHandler(clientAdapter, exec, repos.CachedSessionRepo, tokenAuthMiddleware)
-	adminHandler := handler.NewAdminHandler(adminService, logPath)
+	requestGuard := handler.NewRequestGuard(repos.SettingRepo)
+	proxyHandler := handler.NewProxyHandler(clientAdapter, exec, repos.CachedSessionRepo, tokenAuthMiddleware, requestGuard)
+	adminHandler := handler.NewAdminHandler(adminService, logPath, wailsBroadcaster)
+	adminHandler.SetExecutor(exec)
+	adminHandler.SetRecoverySummary(recoverySummary)
 	antigravityHandler := handler.NewAntigravityHandler(adminService, repos.AntigravityQuotaRepo, wailsBroadcaster)
 	kiroHandler := handler.NewKiroHandler(adminService)
 	projectProxyHandler := handler.NewProjectProxyHandler(proxyHandler, repos.CachedProjectRepo)
+	statusHandler := handler.NewStatusHandler(adminService)
 
 	components := &ServerComponents{
 		Router:              r,
@@ -297,6 +412,8 @@ func InitializeServerComponents(
 		AntigravityHandler:  antigravityHandler,
 		KiroHandler:         kiroHandler,
 		ProjectProxyHandler: projectProxyHandler,
+		StatusHandler:       statusHandler,
+		RecoverySummary:     recoverySummary,
 	}
 
 	log.Printf("[Core] Server components initialized successfully")
@@ -305,6 +422,13 @@ func InitializeServerComponents(
 
 // CloseDatabase 关闭数据库连接
 func CloseDatabase(repos *DatabaseRepos) error {
+	if repos != nil && repos.ProxyRequestRepo != nil {
+		// 先写入队列中未落盘的更新
+		repos.ProxyRequestRepo.Close()
+	}
+	if repos != nil && repos.AttemptRepo != nil {
+		repos.AttemptRepo.Close()
+	}
 	if repos != nil && repos.DB != nil {
 		return repos.DB.Close()
 	}
@@ -0,0 +1,55 @@
+package core
+
+import (
+	"log"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// RecoverySummaryMessageType 崩溃恢复汇总事件类型
+const RecoverySummaryMessageType = "recovery_summary"
+
+// RecoverStaleRequests 崩溃恢复：将其他实例遗留的 PENDING/IN_PROGRESS 请求和尝试标记为 INTERRUPTED
+// 没有需要恢复的记录时返回 nil
+func RecoverStaleRequests(
+	requestRepo repository.ProxyRequestRepository,
+	attemptRepo repository.ProxyUpstreamAttemptRepository,
+	instanceID string,
+) *domain.RecoverySummary {
+	summary := &domain.RecoverySummary{
+		InstanceID:  instanceID,
+		RecoveredAt: time.Now(),
+	}
+
+	// 先处理尝试：依赖请求的 instance_id 判断归属
+	if count, err := attemptRepo.MarkStaleAsInterrupted(instanceID); err != nil {
+		log.Printf("[Core] Warning: Failed to mark stale attempts: %v", err)
+	} else {
+		summary.InterruptedAttempts = count
+	}
+
+	if count, err := requestRepo.MarkStaleAsInterrupted(instanceID); err != nil {
+		log.Printf("[Core] Warning: Failed to mark stale requests: %v", err)
+	} else {
+		summary.InterruptedRequests = count
+	}
+
+	if summary.InterruptedRequests == 0 && summary.InterruptedAttempts == 0 {
+		return nil
+	}
+
+	log.Printf("[Core] Recovered from previous run: %d requests, %d attempts marked as interrupted",
+		summary.InterruptedRequests, summary.InterruptedAttempts)
+	return summary
+}
+
+// BroadcastRecoverySummary 广播崩溃恢复汇总
+func BroadcastRecoverySummary(broadcaster event.Broadcaster, summary *domain.RecoverySummary) {
+	if broadcaster == nil || summary == nil {
+		return
+	}
+	broadcaster.BroadcastMessage(RecoverySummaryMessageType, summary)
+}
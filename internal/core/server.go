@@ -51,14 +51,24 @@ func (s *ManagedServer) setupRoutes() *http.ServeMux {
 	components := s.config.Components
 
 	// API routes under /api prefix (Go 1.22+ enhanced routing)
-	mux.Handle("/api/admin/", http.StripPrefix("/api", components.AdminHandler))
-	mux.Handle("/api/antigravity/", http.StripPrefix("/api", components.AntigravityHandler))
-	mux.Handle("/api/kiro/", http.StripPrefix("/api", components.KiroHandler))
+	// 浏览器访问的控制台与 cmd/maxx 一致：设置 MAXX_ADMIN_PASSWORD 后 Admin API 需要登录（Wails 前端不经过 HTTP）
+	authMiddleware := handler.NewAuthMiddleware()
+	mux.Handle("/api/admin/auth/", http.StripPrefix("/api", handler.NewAuthHandler(authMiddleware)))
+	mux.Handle("/api/admin/", http.StripPrefix("/api", authMiddleware.Wrap(components.AdminHandler)))
+	// 账号相关 API 同样需要登录；OAuth 回调由浏览器跳转打开，无法携带 token，依靠 state 校验
+	mux.Handle("/api/antigravity/", http.StripPrefix("/api", authMiddleware.Wrap(components.AntigravityHandler)))
+	mux.Handle("/api/antigravity/oauth/callback", http.StripPrefix("/api", components.AntigravityHandler))
+	mux.Handle("/api/kiro/", http.StripPrefix("/api", authMiddleware.Wrap(components.KiroHandler)))
 
 	mux.Handle("/v1/messages", components.ProxyHandler)
 	mux.Handle("/v1/chat/completions", components.ProxyHandler)
+	mux.Handle("/v1/completions", components.ProxyHandler)
 	mux.Handle("/responses", components.ProxyHandler)
+	mux.Handle("/v1/responses", components.ProxyHandler)
 	mux.Handle("/v1beta/models/", components.ProxyHandler)
+	mux.Handle("/v1/models", components.ProxyHandler)
+	mux.Handle("/v1/models/", components.ProxyHandler)
+	mux.Handle("/v1beta/models", components.ProxyHandler)
 
 	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
@@ -66,7 +76,11 @@ func (s *ManagedServer) setupRoutes() *http.ServeMux {
 		w.Write([]byte(`{"status":"ok"}`))
 	})
 
-	mux.HandleFunc("/ws", components.WebSocketHub.HandleWebSocket)
+	// 公开只读状态页，无需登录
+	mux.Handle("/status", components.StatusHandler)
+	mux.Handle("/status.json", components.StatusHandler)
+
+	mux.HandleFunc("/ws", components.WebSocketHub.HandleWebSocketWithAuth(authMiddleware))
 
 	if s.config.ServeStatic {
 		staticHandler := handler.NewStaticHandler()
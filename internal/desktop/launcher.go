@@ -2,6 +2,8 @@ package desktop
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -12,19 +14,26 @@ import (
 	"time"
 
 	"github.com/awsl-project/maxx/internal/core"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/updater"
 	"github.com/awsl-project/maxx/internal/version"
+	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 // DesktopConfig 桌面应用配置
 type DesktopConfig struct {
-	Port int `json:"port"` // HTTP 服务端口，默认 9880
+	Port             int    `json:"port"`                  // HTTP 服务端口，默认 9880
+	BindAddress      string `json:"bindAddress,omitempty"` // 监听地址，空表示所有地址
+	AutoFallbackPort bool   `json:"autoFallbackPort"`      // 端口被占用时自动使用其他可用端口
+	AutoCheckUpdate  bool   `json:"autoCheckUpdate"`       // 启动时自动检查更新
 }
 
 // DefaultConfig 返回默认配置
 func DefaultConfig() *DesktopConfig {
 	return &DesktopConfig{
-		Port: 9880,
+		Port:            9880,
+		AutoCheckUpdate: true,
 	}
 }
 
@@ -48,6 +57,10 @@ func loadConfig(dataDir string) *DesktopConfig {
 	if config.Port < 1 || config.Port > 65535 {
 		config.Port = 9880
 	}
+	if err := validateBindAddress(config.BindAddress); err != nil {
+		log.Printf("[Launcher] %v, listening on all addresses", err)
+		config.BindAddress = ""
+	}
 
 	return config
 }
@@ -101,6 +114,9 @@ type ServerStatusInfo struct {
 	RedirectURL string `json:"RedirectURL,omitempty"` // 需要跳转的地址
 	Error       string `json:"Error,omitempty"`
 	Message     string `json:"Message,omitempty"` // 状态消息
+
+	// 端口冲突时返回，前端可据此提示用户切换端口
+	PortConflict *PortConflictInfo `json:"PortConflict,omitempty"`
 }
 
 // LauncherApp 启动器应用（简化版 DesktopApp）
@@ -111,7 +127,7 @@ type LauncherApp struct {
 	dbRepos    *core.DatabaseRepos
 	components *core.ServerComponents
 	dataDir    string
-	serverPort string
+	serverAddr string
 	instanceID string
 	config     *DesktopConfig
 
@@ -120,6 +136,15 @@ type LauncherApp struct {
 	serverError error
 	serverReady bool
 	starting    bool
+
+	// 最近一次刷新的 provider 剩余配额百分比
+	quotaPercents map[uint64]int
+
+	// 最近一次启动时检测到的端口冲突
+	portConflict *PortConflictInfo
+
+	// 最近一次更新检查结果
+	updateInfo *updater.UpdateInfo
 }
 
 // NewLauncherApp 创建启动器应用
@@ -133,11 +158,11 @@ func NewLauncherApp() (*LauncherApp, error) {
 
 	// 加载配置
 	config := loadConfig(dataDir)
-	log.Printf("[Launcher] Config loaded: port=%d", config.Port)
+	log.Printf("[Launcher] Config loaded: port=%d, bind=%q", config.Port, config.BindAddress)
 
 	app := &LauncherApp{
 		dataDir:    dataDir,
-		serverPort: fmt.Sprintf(":%d", config.Port),
+		serverAddr: config.listenAddr(),
 		instanceID: generateInstanceID(),
 		config:     config,
 	}
@@ -152,8 +177,15 @@ func (a *LauncherApp) Startup(ctx context.Context) {
 	log.Printf("[Launcher] Data directory: %s", a.dataDir)
 	log.Printf("[Launcher] Instance ID: %s", a.instanceID)
 
+	// 清理上次更新遗留的旧版本文件
+	updater.CleanupOldBinary()
+
 	// 在后台 goroutine 中启动 HTTP Server
 	go a.startServerAsync()
+
+	if a.config.AutoCheckUpdate {
+		go a.autoCheckUpdate()
+	}
 }
 
 // startServerAsync 异步启动服务器
@@ -162,10 +194,17 @@ func (a *LauncherApp) startServerAsync() {
 	a.starting = true
 	a.serverError = nil
 	a.serverReady = false
+	a.portConflict = nil
 	a.mu.Unlock()
 
 	log.Println("[Launcher] Starting HTTP server in background...")
 
+	// 启动前检测端口冲突
+	if err := a.resolvePortConflict(); err != nil {
+		a.setError(err)
+		return
+	}
+
 	// 初始化数据库
 	dbConfig := &core.DatabaseConfig{
 		DataDir: a.dataDir,
@@ -183,7 +222,7 @@ func (a *LauncherApp) startServerAsync() {
 	// 初始化服务器组件
 	components, err := core.InitializeServerComponents(
 		dbRepos,
-		a.serverPort,
+		a.serverAddr,
 		a.instanceID,
 		filepath.Join(a.dataDir, "maxx.log"),
 	)
@@ -196,11 +235,13 @@ func (a *LauncherApp) startServerAsync() {
 	// 设置 Wails context 用于事件广播
 	if components.WailsBroadcaster != nil {
 		components.WailsBroadcaster.SetContext(a.ctx)
+		// context 设置后再推送崩溃恢复汇总，桌面前端才能收到
+		core.BroadcastRecoverySummary(components.WailsBroadcaster, components.RecoverySummary)
 	}
 
 	// 创建并启动服务器（启用静态文件服务）
 	serverConfig := &core.ServerConfig{
-		Addr:        a.serverPort,
+		Addr:        a.serverAddr,
 		DataDir:     a.dataDir,
 		InstanceID:  a.instanceID,
 		Components:  components,
@@ -230,7 +271,7 @@ func (a *LauncherApp) startServerAsync() {
 	a.starting = false
 	a.mu.Unlock()
 
-	log.Printf("[Launcher] HTTP server started successfully on %s", a.serverPort)
+	log.Printf("[Launcher] HTTP server started successfully on %s", a.serverAddr)
 	log.Println("[Launcher] ========== Server Ready ==========")
 }
 
@@ -240,7 +281,7 @@ func (a *LauncherApp) waitForServerReady() error {
 	maxAttempts := 60 // 最多等待 6 秒
 
 	for range maxAttempts {
-		resp, err := client.Get(fmt.Sprintf("http://localhost%s/health", a.serverPort))
+		resp, err := client.Get(localURL(a.serverAddr) + "/health")
 		if err == nil {
 			resp.Body.Close()
 			if resp.StatusCode == http.StatusOK {
@@ -270,16 +311,17 @@ func (a *LauncherApp) CheckServerStatus() ServerStatusInfo {
 
 	if a.serverError != nil {
 		return ServerStatusInfo{
-			Ready:   false,
-			Error:   a.serverError.Error(),
-			Message: "启动失败",
+			Ready:        false,
+			Error:        a.serverError.Error(),
+			Message:      "启动失败",
+			PortConflict: a.portConflict,
 		}
 	}
 
 	if a.serverReady {
 		return ServerStatusInfo{
 			Ready:       true,
-			RedirectURL: fmt.Sprintf("http://localhost%s", a.serverPort),
+			RedirectURL: localURL(a.serverAddr),
 			Message:     "启动完成",
 		}
 	}
@@ -292,7 +334,17 @@ func (a *LauncherApp) CheckServerStatus() ServerStatusInfo {
 
 // GetServerAddress 获取服务器地址（暴露给前端）
 func (a *LauncherApp) GetServerAddress() string {
-	return fmt.Sprintf("http://localhost%s", a.serverPort)
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return localURL(a.serverAddr)
+}
+
+// CopyServerAddress 复制服务器地址到剪贴板（供托盘调用）
+func (a *LauncherApp) CopyServerAddress() error {
+	if a.ctx == nil {
+		return fmt.Errorf("应用尚未就绪")
+	}
+	return runtime.ClipboardSetText(a.ctx, a.GetServerAddress())
 }
 
 // GetVersion 获取版本信息（暴露给前端）
@@ -319,10 +371,12 @@ func (a *LauncherApp) RestartServer() error {
 		a.dbRepos = nil
 	}
 
-	// 更新端口（使用最新配置）
+	// 更新监听地址（使用最新配置）
+	a.mu.Lock()
 	if a.config != nil {
-		a.serverPort = fmt.Sprintf(":%d", a.config.Port)
+		a.serverAddr = a.config.listenAddr()
 	}
+	a.mu.Unlock()
 
 	// 重置状态
 	a.mu.Lock()
@@ -346,9 +400,10 @@ func (a *LauncherApp) Quit() {
 		a.server.Stop(a.ctx)
 	}
 
-	// 关闭数据库
+	// 关闭数据库（写入队列中未落盘的更新），Shutdown 回调不再重复关闭
 	if a.dbRepos != nil {
 		core.CloseDatabase(a.dbRepos)
+		a.dbRepos = nil
 	}
 
 	// 退出应用
@@ -370,6 +425,27 @@ func (a *LauncherApp) HideWindow() {
 	}
 }
 
+// SingleInstanceID 返回单实例锁的唯一标识
+// 按数据目录区分，使用不同 MAXX_DATA_DIR 的实例可以同时运行
+func (a *LauncherApp) SingleInstanceID() string {
+	sum := sha256.Sum256([]byte(a.dataDir))
+	return "maxx-desktop-" + hex.EncodeToString(sum[:8])
+}
+
+// OnSecondInstanceLaunch 再次启动应用时的回调（由第一个实例执行）
+// 第二个实例会直接退出，这里只需把已有窗口显示到前台
+func (a *LauncherApp) OnSecondInstanceLaunch(data options.SecondInstanceData) {
+	log.Printf("[Launcher] Second instance launched (args=%v), focusing existing window", data.Args)
+	if a.ctx == nil {
+		return
+	}
+	runtime.WindowUnminimise(a.ctx)
+	runtime.WindowShow(a.ctx)
+	// 短暂置顶以确保窗口获得焦点
+	runtime.WindowSetAlwaysOnTop(a.ctx, true)
+	runtime.WindowSetAlwaysOnTop(a.ctx, false)
+}
+
 // Shutdown Wails 关闭回调
 func (a *LauncherApp) Shutdown(ctx context.Context) {
 	log.Println("[Launcher] ========== Application Shutdown ==========")
@@ -412,6 +488,9 @@ func (a *LauncherApp) SaveConfig(config DesktopConfig) error {
 	if config.Port < 1 || config.Port > 65535 {
 		return fmt.Errorf("端口必须在 1-65535 范围内")
 	}
+	if err := validateBindAddress(config.BindAddress); err != nil {
+		return err
+	}
 
 	// 保存到文件
 	if err := saveConfig(a.dataDir, &config); err != nil {
@@ -421,7 +500,7 @@ func (a *LauncherApp) SaveConfig(config DesktopConfig) error {
 	a.mu.Lock()
 	a.config = &config
 	a.mu.Unlock()
-	log.Printf("[Launcher] Config saved: port=%d", config.Port)
+	log.Printf("[Launcher] Config saved: port=%d, bind=%q", config.Port, config.BindAddress)
 
 	return nil
 }
@@ -430,3 +509,16 @@ func (a *LauncherApp) SaveConfig(config DesktopConfig) error {
 func (a *LauncherApp) GetDataDir() string {
 	return a.dataDir
 }
+
+// GetDashboardTimeSeries 获取仪表盘时间序列数据（暴露给前端）
+// rangeKey: 24h / 7d / 30d，groupBy: "" / provider / project
+func (a *LauncherApp) GetDashboardTimeSeries(rangeKey string, groupBy string) (*domain.DashboardTimeSeries, error) {
+	a.mu.RLock()
+	components := a.components
+	a.mu.RUnlock()
+
+	if components == nil || components.AdminService == nil {
+		return nil, fmt.Errorf("服务器尚未就绪")
+	}
+	return components.AdminService.GetDashboardTimeSeries(domain.DashboardRange(rangeKey), domain.DashboardGroupBy(groupBy))
+}
@@ -0,0 +1,52 @@
+package desktop
+
+import (
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/service"
+)
+
+// ListProviders 分页获取 Provider（暴露给前端），参数同 GET /admin/providers
+func (a *LauncherApp) ListProviders(deleted bool, label string, q service.ListQuery) (*service.Page[*domain.Provider], error) {
+	components, err := a.readyComponents()
+	if err != nil {
+		return nil, err
+	}
+	return components.AdminService.ListProviders(deleted, label, q)
+}
+
+// ListRoutes 分页获取路由（暴露给前端），参数同 GET /admin/routes
+func (a *LauncherApp) ListRoutes(deleted bool, q service.ListQuery) (*service.Page[*domain.Route], error) {
+	components, err := a.readyComponents()
+	if err != nil {
+		return nil, err
+	}
+	return components.AdminService.ListRoutes(deleted, q)
+}
+
+// ListProjects 分页获取项目（暴露给前端）
+func (a *LauncherApp) ListProjects(q service.ListQuery) (*service.Page[*domain.Project], error) {
+	components, err := a.readyComponents()
+	if err != nil {
+		return nil, err
+	}
+	return components.AdminService.ListProjects(q)
+}
+
+// ListSessions 分页获取会话（暴露给前端），参数同 GET /admin/sessions
+func (a *LauncherApp) ListSessions(includeArchived bool, q service.ListQuery) (*service.Page[*domain.Session], error) {
+	components, err := a.readyComponents()
+	if err != nil {
+		return nil, err
+	}
+	return components.AdminService.ListSessions(includeArchived, q)
+}
+
+// ListProxyRequests 按游标分页获取请求记录（暴露给前端），最新的在前
+// cursor 为上一页的 nextCursor，0 表示第一页
+func (a *LauncherApp) ListProxyRequests(limit int, cursor uint64) (*service.CursorPaginationResult, error) {
+	components, err := a.readyComponents()
+	if err != nil {
+		return nil, err
+	}
+	return components.AdminService.GetProxyRequestsCursor(limit, cursor, 0)
+}
@@ -0,0 +1,117 @@
+package desktop
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+)
+
+// portFallbackRange 端口冲突时向后搜索的端口数量
+const portFallbackRange = 100
+
+// PortConflictInfo 端口冲突信息（暴露给前端，用于提示用户切换端口）
+type PortConflictInfo struct {
+	Port          int `json:"Port"`                    // 配置的端口
+	SuggestedPort int `json:"SuggestedPort,omitempty"` // 建议使用的可用端口，0 表示未找到
+}
+
+// listenAddr 返回配置对应的监听地址，如 ":9880" 或 "127.0.0.1:9880"
+func (c *DesktopConfig) listenAddr() string {
+	return net.JoinHostPort(c.BindAddress, strconv.Itoa(c.Port))
+}
+
+// localURL 返回本机访问服务器的 URL
+// 监听所有地址时使用 localhost
+func localURL(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port)
+}
+
+// isPortAvailable 检查端口是否可以监听
+func isPortAvailable(bindAddress string, port int) bool {
+	ln, err := net.Listen("tcp", net.JoinHostPort(bindAddress, strconv.Itoa(port)))
+	if err != nil {
+		return false
+	}
+	ln.Close()
+	return true
+}
+
+// findAvailablePort 从 start 之后查找第一个可用端口
+func findAvailablePort(bindAddress string, start int) (int, error) {
+	for port := start + 1; port <= start+portFallbackRange && port <= 65535; port++ {
+		if isPortAvailable(bindAddress, port) {
+			return port, nil
+		}
+	}
+	return 0, fmt.Errorf("no available port in range %d-%d", start+1, start+portFallbackRange)
+}
+
+// validateBindAddress 校验监听地址，空字符串表示监听所有地址
+func validateBindAddress(bindAddress string) error {
+	if bindAddress == "" || bindAddress == "localhost" {
+		return nil
+	}
+	if net.ParseIP(bindAddress) == nil {
+		return fmt.Errorf("监听地址无效: %s", bindAddress)
+	}
+	return nil
+}
+
+// resolvePortConflict 启动前检测端口冲突
+// 开启 AutoFallbackPort 时自动切换到可用端口（不修改配置），否则记录冲突信息并返回错误
+func (a *LauncherApp) resolvePortConflict() error {
+	a.mu.RLock()
+	config := *a.config
+	a.mu.RUnlock()
+
+	if isPortAvailable(config.BindAddress, config.Port) {
+		a.mu.Lock()
+		a.serverAddr = config.listenAddr()
+		a.mu.Unlock()
+		return nil
+	}
+
+	suggested, err := findAvailablePort(config.BindAddress, config.Port)
+	if err != nil {
+		log.Printf("[Launcher] Port %d is in use and no fallback found: %v", config.Port, err)
+	}
+
+	if config.AutoFallbackPort && suggested > 0 {
+		log.Printf("[Launcher] Port %d is in use, falling back to %d", config.Port, suggested)
+		a.mu.Lock()
+		a.serverAddr = net.JoinHostPort(config.BindAddress, strconv.Itoa(suggested))
+		a.mu.Unlock()
+		return nil
+	}
+
+	a.mu.Lock()
+	a.portConflict = &PortConflictInfo{Port: config.Port, SuggestedPort: suggested}
+	a.mu.Unlock()
+	return fmt.Errorf("端口 %d 已被占用", config.Port)
+}
+
+// UseSuggestedPort 保存建议端口到配置并重启服务器（暴露给前端，用于处理端口冲突）
+func (a *LauncherApp) UseSuggestedPort() error {
+	a.mu.RLock()
+	conflict := a.portConflict
+	config := *a.config
+	a.mu.RUnlock()
+
+	if conflict == nil || conflict.SuggestedPort == 0 {
+		return fmt.Errorf("没有可用的建议端口")
+	}
+
+	config.Port = conflict.SuggestedPort
+	if err := a.SaveConfig(config); err != nil {
+		return err
+	}
+	return a.RestartServer()
+}
@@ -0,0 +1,183 @@
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/core"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// providerPauseDuration 托盘"暂停"操作的冷却时长
+const providerPauseDuration = time.Hour
+
+// Provider 快捷状态
+const (
+	ProviderStatusActive   = "active"
+	ProviderStatusCooldown = "cooldown"
+	ProviderStatusPaused   = "paused"
+)
+
+// ProviderQuickStatus Provider 快捷状态（托盘菜单和前端共用）
+type ProviderQuickStatus struct {
+	ID            uint64     `json:"id"`
+	Name          string     `json:"name"`
+	Type          string     `json:"type"`
+	Status        string     `json:"status"`                  // active / cooldown / paused
+	CooldownUntil *time.Time `json:"cooldownUntil,omitempty"` // 冷却结束时间
+	QuotaPercent  int        `json:"quotaPercent"`            // 剩余配额百分比，-1 表示未知
+}
+
+// readyComponents 获取已就绪的服务器组件
+func (a *LauncherApp) readyComponents() (*core.ServerComponents, error) {
+	a.mu.RLock()
+	components := a.components
+	a.mu.RUnlock()
+
+	if components == nil || components.AdminService == nil || components.Router == nil {
+		return nil, fmt.Errorf("服务器尚未就绪")
+	}
+	return components, nil
+}
+
+// GetProviderQuickStatuses 获取所有 Provider 的快捷状态（暴露给前端）
+// 配额百分比仅来自最近一次刷新结果，不会在此处请求上游
+func (a *LauncherApp) GetProviderQuickStatuses() ([]ProviderQuickStatus, error) {
+	components, err := a.readyComponents()
+	if err != nil {
+		return nil, err
+	}
+
+	providers, err := components.AdminService.GetProviders()
+	if err != nil {
+		return nil, err
+	}
+
+	// 每个 provider 取最晚结束的有效冷却
+	now := time.Now()
+	cooldowns := make(map[uint64]*domain.Cooldown)
+	if list, err := components.Router.GetCooldowns(); err == nil {
+		for _, cd := range list {
+			if !cd.UntilTime.After(now) {
+				continue
+			}
+			if existing, ok := cooldowns[cd.ProviderID]; !ok || cd.UntilTime.After(existing.UntilTime) {
+				cooldowns[cd.ProviderID] = cd
+			}
+		}
+	}
+
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	result := make([]ProviderQuickStatus, 0, len(providers))
+	for _, p := range providers {
+		status := ProviderQuickStatus{
+			ID:           p.ID,
+			Name:         p.Name,
+			Type:         p.Type,
+			Status:       ProviderStatusActive,
+			QuotaPercent: -1,
+		}
+		if cd, ok := cooldowns[p.ID]; ok {
+			until := cd.UntilTime
+			status.CooldownUntil = &until
+			status.Status = ProviderStatusCooldown
+			if cd.Reason == domain.CooldownReasonManual {
+				status.Status = ProviderStatusPaused
+			}
+		}
+		if percent, ok := a.quotaPercents[p.ID]; ok {
+			status.QuotaPercent = percent
+		}
+		result = append(result, status)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
+	return result, nil
+}
+
+// PauseProvider 手动暂停 Provider（进入冷却），可通过 ClearProviderCooldown 提前恢复
+func (a *LauncherApp) PauseProvider(providerID uint64) error {
+	components, err := a.readyComponents()
+	if err != nil {
+		return err
+	}
+	return components.Router.PauseProvider(providerID, providerPauseDuration)
+}
+
+// ClearProviderCooldown 清除 Provider 的冷却状态
+func (a *LauncherApp) ClearProviderCooldown(providerID uint64) error {
+	components, err := a.readyComponents()
+	if err != nil {
+		return err
+	}
+	return components.Router.ClearCooldown(providerID)
+}
+
+// RefreshProviderQuota 强制刷新 Provider 配额，返回剩余百分比
+// 仅支持 Antigravity 和 Kiro provider
+func (a *LauncherApp) RefreshProviderQuota(providerID uint64) (int, error) {
+	components, err := a.readyComponents()
+	if err != nil {
+		return -1, err
+	}
+
+	provider, err := components.AdminService.GetProvider(providerID)
+	if err != nil {
+		return -1, fmt.Errorf("provider not found: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	percent := -1
+	switch provider.Type {
+	case "antigravity":
+		if components.AntigravityHandler == nil {
+			return -1, fmt.Errorf("antigravity handler not available")
+		}
+		quota, err := components.AntigravityHandler.GetProviderQuota(ctx, providerID, true)
+		if err != nil {
+			return -1, err
+		}
+		// 取所有模型中最低的剩余百分比
+		for _, m := range quota.Models {
+			if percent < 0 || m.Percentage < percent {
+				percent = m.Percentage
+			}
+		}
+		if quota.IsForbidden {
+			percent = 0
+		}
+	case "kiro":
+		if components.KiroHandler == nil {
+			return -1, fmt.Errorf("kiro handler not available")
+		}
+		quota, err := components.KiroHandler.GetProviderQuota(ctx, providerID)
+		if err != nil {
+			return -1, err
+		}
+		if quota.TotalLimit > 0 {
+			percent = int(quota.Available / quota.TotalLimit * 100)
+		}
+		if quota.IsBanned {
+			percent = 0
+		}
+	default:
+		return -1, fmt.Errorf("provider type %s does not support quota", provider.Type)
+	}
+
+	a.mu.Lock()
+	if a.quotaPercents == nil {
+		a.quotaPercents = make(map[uint64]int)
+	}
+	a.quotaPercents[providerID] = percent
+	a.mu.Unlock()
+
+	return percent, nil
+}
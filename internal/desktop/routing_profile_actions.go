@@ -0,0 +1,44 @@
+package desktop
+
+// RoutingProfileOption 路由方案选项（托盘菜单和前端共用）
+type RoutingProfileOption struct {
+	ID        uint64 `json:"id"`
+	Name      string `json:"name"`
+	Active    bool   `json:"active"`    // 是否为最近一次应用的方案
+	Scheduled bool   `json:"scheduled"` // 是否为当前时间表匹配的方案
+}
+
+// GetRoutingProfileOptions 获取所有路由方案及其状态（暴露给前端）
+func (a *LauncherApp) GetRoutingProfileOptions() ([]RoutingProfileOption, error) {
+	components, err := a.readyComponents()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := components.AdminService.GetRoutingProfiles()
+	if err != nil {
+		return nil, err
+	}
+	active := components.AdminService.GetActiveRoutingProfile()
+
+	result := make([]RoutingProfileOption, 0, len(profiles))
+	for _, p := range profiles {
+		result = append(result, RoutingProfileOption{
+			ID:        p.ID,
+			Name:      p.Name,
+			Active:    p.ID == active.ProfileID,
+			Scheduled: p.ID == active.ScheduledProfileID,
+		})
+	}
+	return result, nil
+}
+
+// ApplyRoutingProfile 手动切换到指定路由方案，保持到下一个时间段开始
+func (a *LauncherApp) ApplyRoutingProfile(profileID uint64) error {
+	components, err := a.readyComponents()
+	if err != nil {
+		return err
+	}
+	_, err = components.AdminService.ApplyRoutingProfile(profileID)
+	return err
+}
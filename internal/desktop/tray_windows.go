@@ -7,6 +7,8 @@ import (
 	_ "embed"
 	"fmt"
 	"log"
+	"sync"
+	"time"
 
 	"github.com/getlantern/systray"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -22,9 +24,49 @@ type TrayManager struct {
 	menuShow         *systray.MenuItem
 	menuServerStatus *systray.MenuItem
 	menuServerAddr   *systray.MenuItem
+	menuCopyAddr     *systray.MenuItem
 	menuSettings     *systray.MenuItem
 	menuRestart      *systray.MenuItem
 	menuQuit         *systray.MenuItem
+
+	// Provider 快捷操作
+	menuProviders *systray.MenuItem
+	providerSlots []*trayProviderSlot
+
+	// 路由方案切换
+	menuProfiles *systray.MenuItem
+	profileSlots []*trayProfileSlot
+}
+
+// trayMaxProviders 托盘中最多展示的 provider 数量
+// systray 不支持删除菜单项，因此预先创建固定数量的槽位并按需显示/隐藏
+const trayMaxProviders = 20
+
+// trayMaxProfiles 托盘中最多展示的路由方案数量
+const trayMaxProfiles = 10
+
+// trayRefreshInterval 托盘状态定时刷新间隔
+const trayRefreshInterval = 30 * time.Second
+
+// trayProviderSlot 单个 provider 的子菜单槽位
+type trayProviderSlot struct {
+	mu         sync.RWMutex
+	providerID uint64
+	bound      bool
+
+	menu        *systray.MenuItem
+	menuPause   *systray.MenuItem
+	menuClear   *systray.MenuItem
+	menuRefresh *systray.MenuItem
+}
+
+// trayProfileSlot 单个路由方案的菜单槽位
+type trayProfileSlot struct {
+	mu        sync.RWMutex
+	profileID uint64
+	bound     bool
+
+	menu *systray.MenuItem
 }
 
 // NewTrayManager 创建托盘管理器
@@ -60,6 +102,35 @@ func (t *TrayManager) onReady() {
 	t.menuServerAddr = systray.AddMenuItem("服务器地址: -", "服务器监听地址")
 	t.menuServerAddr.Disable()
 
+	t.menuCopyAddr = systray.AddMenuItem("复制服务器地址", "复制服务器地址到剪贴板")
+
+	systray.AddSeparator()
+
+	// Provider 子菜单
+	t.menuProviders = systray.AddMenuItem("Providers", "Provider 状态与快捷操作")
+	t.providerSlots = make([]*trayProviderSlot, trayMaxProviders)
+	for i := range t.providerSlots {
+		slot := &trayProviderSlot{}
+		slot.menu = t.menuProviders.AddSubMenuItem("-", "")
+		slot.menuPause = slot.menu.AddSubMenuItem("暂停 1 小时", "将该 Provider 手动置为冷却")
+		slot.menuClear = slot.menu.AddSubMenuItem("清除冷却", "立即恢复该 Provider")
+		slot.menuRefresh = slot.menu.AddSubMenuItem("刷新配额", "重新获取该 Provider 的配额")
+		slot.menu.Hide()
+		t.providerSlots[i] = slot
+		go t.handleProviderSlotEvents(slot)
+	}
+
+	// 路由方案子菜单
+	t.menuProfiles = systray.AddMenuItem("路由方案", "切换路由方案")
+	t.profileSlots = make([]*trayProfileSlot, trayMaxProfiles)
+	for i := range t.profileSlots {
+		slot := &trayProfileSlot{}
+		slot.menu = t.menuProfiles.AddSubMenuItem("-", "切换到该路由方案")
+		slot.menu.Hide()
+		t.profileSlots[i] = slot
+		go t.handleProfileSlotEvents(slot)
+	}
+
 	systray.AddSeparator()
 
 	// 操作菜单
@@ -75,6 +146,18 @@ func (t *TrayManager) onReady() {
 
 	// 启动菜单事件监听
 	go t.handleMenuEvents()
+
+	// 定时刷新状态
+	go t.refreshLoop()
+}
+
+// refreshLoop 定时刷新托盘状态
+func (t *TrayManager) refreshLoop() {
+	ticker := time.NewTicker(trayRefreshInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		t.UpdateStatus()
+	}
 }
 
 // onExit 托盘退出回调
@@ -90,6 +173,14 @@ func (t *TrayManager) handleMenuEvents() {
 			log.Println("[Tray] Show window clicked")
 			t.showWindow()
 
+		case <-t.menuCopyAddr.ClickedCh:
+			log.Println("[Tray] Copy server address clicked")
+			if t.app != nil {
+				if err := t.app.CopyServerAddress(); err != nil {
+					log.Printf("[Tray] Failed to copy server address: %v", err)
+				}
+			}
+
 		case <-t.menuSettings.ClickedCh:
 			log.Println("[Tray] Settings clicked")
 			t.openSettings()
@@ -106,6 +197,63 @@ func (t *TrayManager) handleMenuEvents() {
 	}
 }
 
+// handleProviderSlotEvents 处理单个 provider 槽位的菜单事件
+func (t *TrayManager) handleProviderSlotEvents(slot *trayProviderSlot) {
+	for {
+		var action string
+		select {
+		case <-slot.menuPause.ClickedCh:
+			action = "pause"
+		case <-slot.menuClear.ClickedCh:
+			action = "clear"
+		case <-slot.menuRefresh.ClickedCh:
+			action = "refresh"
+		}
+
+		slot.mu.RLock()
+		providerID, bound := slot.providerID, slot.bound
+		slot.mu.RUnlock()
+		if !bound || t.app == nil {
+			continue
+		}
+
+		var err error
+		switch action {
+		case "pause":
+			log.Printf("[Tray] Pause provider %d clicked", providerID)
+			err = t.app.PauseProvider(providerID)
+		case "clear":
+			log.Printf("[Tray] Clear cooldown for provider %d clicked", providerID)
+			err = t.app.ClearProviderCooldown(providerID)
+		case "refresh":
+			log.Printf("[Tray] Refresh quota for provider %d clicked", providerID)
+			_, err = t.app.RefreshProviderQuota(providerID)
+		}
+		if err != nil {
+			log.Printf("[Tray] Provider %d action %s failed: %v", providerID, action, err)
+		}
+		t.updateProviders()
+	}
+}
+
+// handleProfileSlotEvents 处理单个路由方案槽位的菜单事件
+func (t *TrayManager) handleProfileSlotEvents(slot *trayProfileSlot) {
+	for range slot.menu.ClickedCh {
+		slot.mu.RLock()
+		profileID, bound := slot.profileID, slot.bound
+		slot.mu.RUnlock()
+		if !bound || t.app == nil {
+			continue
+		}
+
+		log.Printf("[Tray] Apply routing profile %d clicked", profileID)
+		if err := t.app.ApplyRoutingProfile(profileID); err != nil {
+			log.Printf("[Tray] Apply routing profile %d failed: %v", profileID, err)
+		}
+		t.updateProfiles()
+	}
+}
+
 // showWindow 显示窗口
 func (t *TrayManager) showWindow() {
 	runtime.WindowShow(t.ctx)
@@ -164,4 +312,135 @@ func (t *TrayManager) UpdateStatus() {
 	} else {
 		t.menuServerAddr.SetTitle("服务器地址: -")
 	}
+
+	t.updateProviders()
+	t.updateProfiles()
+}
+
+// updateProfiles 更新路由方案子菜单，当前方案以 ● 标记，时间表匹配的方案标注 [定时]
+func (t *TrayManager) updateProfiles() {
+	if t.app == nil || t.menuProfiles == nil {
+		return
+	}
+
+	options, err := t.app.GetRoutingProfileOptions()
+	if err != nil {
+		options = nil
+	}
+
+	title := "路由方案: -"
+	for _, opt := range options {
+		if opt.Active {
+			title = fmt.Sprintf("路由方案: %s", opt.Name)
+		}
+	}
+	t.menuProfiles.SetTitle(title)
+	if len(options) == 0 {
+		t.menuProfiles.Disable()
+	} else {
+		t.menuProfiles.Enable()
+	}
+
+	for i, slot := range t.profileSlots {
+		if i >= len(options) {
+			slot.mu.Lock()
+			slot.bound = false
+			slot.mu.Unlock()
+			slot.menu.Hide()
+			continue
+		}
+
+		opt := options[i]
+		slot.mu.Lock()
+		slot.profileID = opt.ID
+		slot.bound = true
+		slot.mu.Unlock()
+
+		label := opt.Name
+		if opt.Active {
+			label = "● " + label
+		}
+		if opt.Scheduled {
+			label += " [定时]"
+		}
+		slot.menu.SetTitle(label)
+		slot.menu.Show()
+	}
+}
+
+// updateProviders 更新 provider 子菜单
+func (t *TrayManager) updateProviders() {
+	if t.app == nil || t.menuProviders == nil {
+		return
+	}
+
+	statuses, err := t.app.GetProviderQuickStatuses()
+	if err != nil {
+		statuses = nil
+	}
+
+	if len(statuses) == 0 {
+		t.menuProviders.SetTitle("Providers: -")
+	} else {
+		t.menuProviders.SetTitle(fmt.Sprintf("Providers (%d)", len(statuses)))
+	}
+
+	for i, slot := range t.providerSlots {
+		if i >= len(statuses) {
+			slot.mu.Lock()
+			slot.bound = false
+			slot.mu.Unlock()
+			slot.menu.Hide()
+			continue
+		}
+
+		st := statuses[i]
+		slot.mu.Lock()
+		slot.providerID = st.ID
+		slot.bound = true
+		slot.mu.Unlock()
+
+		slot.menu.SetTitle(formatProviderTitle(st))
+		slot.menu.Show()
+
+		if st.Status == ProviderStatusActive {
+			slot.menuPause.Enable()
+			slot.menuClear.Disable()
+		} else {
+			slot.menuPause.Disable()
+			slot.menuClear.Enable()
+		}
+		if st.Type == "antigravity" || st.Type == "kiro" {
+			slot.menuRefresh.Show()
+		} else {
+			slot.menuRefresh.Hide()
+		}
+	}
+}
+
+// formatProviderTitle 格式化 provider 菜单标题，如 "Claude [冷却 12m] 配额 80%"
+func formatProviderTitle(st ProviderQuickStatus) string {
+	title := st.Name
+	switch st.Status {
+	case ProviderStatusCooldown, ProviderStatusPaused:
+		label := "冷却"
+		if st.Status == ProviderStatusPaused {
+			label = "已暂停"
+		}
+		if st.CooldownUntil != nil {
+			remaining := time.Until(*st.CooldownUntil).Round(time.Minute)
+			if remaining < time.Minute {
+				remaining = time.Minute
+			}
+			title += fmt.Sprintf(" [%s %s]", label, remaining)
+		} else {
+			title += fmt.Sprintf(" [%s]", label)
+		}
+	default:
+		title += " [正常]"
+	}
+	if st.QuotaPercent >= 0 {
+		title += fmt.Sprintf(" 配额 %d%%", st.QuotaPercent)
+	}
+	return title
 }
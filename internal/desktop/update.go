@@ -0,0 +1,91 @@
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/updater"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// EventUpdateAvailable 发现新版本时推送给前端的事件
+const EventUpdateAvailable = "update_available"
+
+// CheckForUpdates 检查 GitHub 上是否有新版本（暴露给前端）
+// 返回的 Release.Changelog 为 Markdown 格式的更新日志
+func (a *LauncherApp) CheckForUpdates() (*updater.UpdateInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	info, err := updater.CheckForUpdates(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	a.mu.Lock()
+	a.updateInfo = info
+	a.mu.Unlock()
+
+	return info, nil
+}
+
+// DownloadAndInstallUpdate 下载并安装最新版本（暴露给前端）
+// Windows: 替换可执行文件后自动重启；macOS: 打开 dmg 由用户完成安装
+func (a *LauncherApp) DownloadAndInstallUpdate() error {
+	a.mu.RLock()
+	info := a.updateInfo
+	a.mu.RUnlock()
+
+	if info == nil {
+		var err error
+		if info, err = a.CheckForUpdates(); err != nil {
+			return err
+		}
+	}
+	if !info.HasUpdate {
+		return fmt.Errorf("当前已是最新版本")
+	}
+	if info.Asset == nil {
+		return fmt.Errorf("当前平台不支持自动更新，请前往 %s 手动下载", info.Release.URL)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	log.Printf("[Launcher] Downloading update %s (%s)...", info.LatestVersion, info.Asset.Name)
+	path, err := info.Release.Download(ctx, info.Asset, filepath.Join(a.dataDir, "updates"))
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[Launcher] Installing update from %s", path)
+	relaunch, err := updater.Install(path)
+	if err != nil {
+		return err
+	}
+	if relaunch {
+		log.Println("[Launcher] Update installed, restarting...")
+		a.Quit()
+	}
+	return nil
+}
+
+// autoCheckUpdate 启动时后台检查更新，发现新版本时通知前端
+func (a *LauncherApp) autoCheckUpdate() {
+	info, err := a.CheckForUpdates()
+	if err != nil {
+		log.Printf("[Launcher] Update check failed: %v", err)
+		return
+	}
+	if !info.HasUpdate {
+		return
+	}
+
+	log.Printf("[Launcher] New version available: %s (current: %s)", info.LatestVersion, info.CurrentVersion)
+	if a.ctx != nil {
+		runtime.EventsEmit(a.ctx, EventUpdateAvailable, info)
+	}
+}
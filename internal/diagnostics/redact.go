@@ -0,0 +1,195 @@
+// Package diagnostics redacts configuration, logs and request records so they can be shared
+// in bug reports. Credentials are always removed; request and response bodies keep their
+// JSON structure, but the text they carry (prompts, completions) is replaced with its length.
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces a credential
+const Redacted = "[REDACTED]"
+
+// sensitiveKeyParts mark a field or header holding a credential (matched against the
+// lower-cased key with separators removed)
+var sensitiveKeyParts = []string{
+	"apikey", "secret", "password", "passwd", "token", "credential", "cookie",
+	"authorization", "privatekey", "accesskey", "signature",
+}
+
+// IsSensitiveKey reports whether a field or header name denotes a credential
+func IsSensitiveKey(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(key))
+	if strings.HasSuffix(k, "prefix") || strings.HasSuffix(k, "count") {
+		return false
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(k, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretPatterns match credentials embedded in free text such as log lines and URLs
+var secretPatterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`), "${1}" + Redacted},
+	{regexp.MustCompile(`(?i)((?:api[_-]?key|access[_-]?token|refresh[_-]?token|token|secret|password|key)["']?\s*[=:]\s*["']?)[^\s"'&,}]+`), "${1}" + Redacted},
+	{regexp.MustCompile(`(://)[^/\s:@]+:[^/\s@]+@`), "${1}" + Redacted + "@"},
+	{regexp.MustCompile(`\bsk-[A-Za-z0-9_-]{8,}`), Redacted},
+	{regexp.MustCompile(`\bmaxx_[A-Za-z0-9_-]{8,}`), Redacted},
+	{regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{20,}`), Redacted},
+	{regexp.MustCompile(`\bya29\.[0-9A-Za-z_.-]+`), Redacted},
+	{regexp.MustCompile(`\b1//[0-9A-Za-z_-]{20,}`), Redacted},
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]*`), Redacted}, // JWT
+}
+
+// ScrubText removes credentials from free text
+func ScrubText(s string) string {
+	for _, p := range secretPatterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// RedactHeaders returns a copy of headers with credential values removed
+func RedactHeaders(headers map[string]string) map[string]string {
+	if headers == nil {
+		return nil
+	}
+	result := make(map[string]string, len(headers))
+	for k, v := range headers {
+		if IsSensitiveKey(k) && v != "" {
+			v = Redacted
+		}
+		result[k] = ScrubText(v)
+	}
+	return result
+}
+
+// RedactConfig marshals v to JSON with the values of credential fields removed and
+// credentials in other strings scrubbed. Everything else is kept as is.
+func RedactConfig(v interface{}) (json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(redactConfigValue(doc, ""))
+}
+
+func redactConfigValue(v interface{}, key string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = redactConfigValue(child, k)
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redactConfigValue(child, key)
+		}
+		return val
+	case string:
+		if val != "" && IsSensitiveKey(key) && val != "true" && val != "false" {
+			return Redacted
+		}
+		return ScrubText(val)
+	default:
+		return v
+	}
+}
+
+// structuralKeys are the body fields kept verbatim: they describe the shape of a request
+// or response, not the conversation
+var structuralKeys = map[string]bool{
+	"model": true, "type": true, "role": true, "object": true, "status": true, "code": true,
+	"stop_reason": true, "finish_reason": true, "finishReason": true, "stop_sequence": true,
+	"tool_choice": true, "name": true, "media_type": true, "mimeType": true, "mime_type": true,
+	"reason": true, "error": true, "message": true, "event": true, "id": true,
+}
+
+// RedactBody redacts a request or response body. JSON bodies and SSE streams keep their
+// structure and structural fields (model, type, role, stop reasons, error messages, ...);
+// all other text becomes "[N chars]". Bodies of other types are replaced entirely.
+func RedactBody(body string) string {
+	trimmed := strings.TrimSpace(body)
+	if trimmed == "" {
+		return body
+	}
+	if redacted, ok := redactJSONText(trimmed); ok {
+		return redacted
+	}
+	if strings.Contains(body, "data:") {
+		lines := strings.Split(body, "\n")
+		for i, line := range lines {
+			data, ok := strings.CutPrefix(line, "data:")
+			if !ok {
+				continue
+			}
+			if redacted, ok := redactJSONText(strings.TrimSpace(data)); ok {
+				lines[i] = "data: " + redacted
+			} else if d := strings.TrimSpace(data); d != "" && d != "[DONE]" {
+				lines[i] = "data: " + redactedLength(d)
+			}
+		}
+		return strings.Join(lines, "\n")
+	}
+	return fmt.Sprintf("[%d bytes redacted]", len(body))
+}
+
+func redactJSONText(s string) (string, bool) {
+	if !strings.HasPrefix(s, "{") && !strings.HasPrefix(s, "[") {
+		return "", false
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(s), &doc); err != nil {
+		return "", false
+	}
+	data, err := json.Marshal(redactBodyValue(doc, ""))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+func redactBodyValue(v interface{}, key string) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, child := range val {
+			val[k] = redactBodyValue(child, k)
+		}
+		return val
+	case []interface{}:
+		for i, child := range val {
+			val[i] = redactBodyValue(child, key)
+		}
+		return val
+	case string:
+		if val == "" {
+			return val
+		}
+		if IsSensitiveKey(key) {
+			return Redacted
+		}
+		if structuralKeys[key] {
+			return ScrubText(val)
+		}
+		return redactedLength(val)
+	default:
+		return v
+	}
+}
+
+func redactedLength(s string) string {
+	return fmt.Sprintf("[%d chars]", len([]rune(s)))
+}
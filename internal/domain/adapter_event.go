@@ -12,6 +12,8 @@ const (
 	EventMetrics
 	// EventResponseModel is sent when response model is extracted
 	EventResponseModel
+	// EventTimings is sent when the adapter has measured phases of the upstream request
+	EventTimings
 )
 
 // AdapterMetrics contains token usage metrics (avoids import cycle with usage package)
@@ -27,10 +29,11 @@ type AdapterMetrics struct {
 // AdapterEvent represents an event from adapter to executor
 type AdapterEvent struct {
 	Type          AdapterEventType
-	RequestInfo   *RequestInfo    // for EventRequestInfo
-	ResponseInfo  *ResponseInfo   // for EventResponseInfo
-	Metrics       *AdapterMetrics // for EventMetrics
-	ResponseModel string          // for EventResponseModel
+	RequestInfo   *RequestInfo     // for EventRequestInfo
+	ResponseInfo  *ResponseInfo    // for EventResponseInfo
+	Metrics       *AdapterMetrics  // for EventMetrics
+	ResponseModel string           // for EventResponseModel
+	Timings       *UpstreamTimings // for EventTimings (non-zero fields are merged into the attempt)
 }
 
 // AdapterEventChan is used by adapters to send events to executor
@@ -86,6 +89,17 @@ func (ch AdapterEventChan) SendResponseModel(model string) {
 	}
 }
 
+// SendTimings sends phase timings measured by the adapter
+func (ch AdapterEventChan) SendTimings(timings *UpstreamTimings) {
+	if ch == nil || timings == nil {
+		return
+	}
+	select {
+	case ch <- &AdapterEvent{Type: EventTimings, Timings: timings}:
+	default:
+	}
+}
+
 // Close closes the event channel
 func (ch AdapterEventChan) Close() {
 	if ch != nil {
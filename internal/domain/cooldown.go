@@ -12,6 +12,7 @@ const (
 	CooldownReasonRateLimitExceeded  CooldownReason = "rate_limit_exceeded"
 	CooldownReasonConcurrentLimit    CooldownReason = "concurrent_limit"
 	CooldownReasonUnknown            CooldownReason = "unknown"
+	CooldownReasonManual             CooldownReason = "manual"
 )
 
 // Cooldown represents a provider cooldown record
@@ -24,3 +25,53 @@ type Cooldown struct {
 	UntilTime  time.Time      `json:"untilTime"`  // Absolute time when cooldown ends
 	Reason     CooldownReason `json:"reason"`     // Reason for cooldown
 }
+
+// CooldownEvent records one cooldown activation, kept after the cooldown ends for analytics
+type CooldownEvent struct {
+	ID         uint64         `json:"id"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	ProviderID uint64         `json:"providerID"`
+	ClientType string         `json:"clientType"` // Empty for global cooldown
+	Reason     CooldownReason `json:"reason"`
+	StartTime  time.Time      `json:"startTime"`
+	UntilTime  time.Time      `json:"untilTime"`
+}
+
+// Duration returns how long the cooldown was set to last
+func (e *CooldownEvent) Duration() time.Duration {
+	return e.UntilTime.Sub(e.StartTime)
+}
+
+// CooldownReasonStats aggregates the cooldown activations of one reason
+type CooldownReasonStats struct {
+	Reason          CooldownReason `json:"reason"`
+	Count           int            `json:"count"`
+	TotalDurationMs int64          `json:"totalDurationMs"`
+	AvgDurationMs   int64          `json:"avgDurationMs"`
+	MaxDurationMs   int64          `json:"maxDurationMs"`
+}
+
+// CooldownStatsBucket counts the cooldown activations started in one time bucket, by reason
+type CooldownStatsBucket struct {
+	Time       time.Time              `json:"time"`
+	Counts     map[CooldownReason]int `json:"counts"`
+	DurationMs int64                  `json:"durationMs"`
+}
+
+// CooldownProviderStats aggregates the cooldown activations of one provider
+type CooldownProviderStats struct {
+	ProviderID      uint64                 `json:"providerID"`
+	ProviderName    string                 `json:"providerName,omitempty"`
+	Count           int                    `json:"count"`
+	TotalDurationMs int64                  `json:"totalDurationMs"`
+	Reasons         []*CooldownReasonStats `json:"reasons"`  // Most frequent first
+	Timeline        []*CooldownStatsBucket `json:"timeline"` // Oldest first, empty buckets omitted
+}
+
+// CooldownStats aggregates cooldown activations over a time range
+type CooldownStats struct {
+	From      time.Time                `json:"from"`
+	To        time.Time                `json:"to"`
+	Bucket    Granularity              `json:"bucket"`
+	Providers []*CooldownProviderStats `json:"providers"` // Most cooldowns first
+}
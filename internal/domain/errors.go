@@ -1,27 +1,53 @@
 package domain
 
 import (
+    "context"
     "errors"
     "fmt"
     "time"
 )
 
 var (
-    ErrNotFound          = errors.New("not found")
-    ErrAlreadyExists     = errors.New("already exists")
-    ErrSlugExists        = errors.New("slug already exists")
-    ErrInvalidInput      = errors.New("invalid input")
-    ErrNoRoutes          = errors.New("no routes available")
-    ErrAllRoutesFailed   = errors.New("all routes failed")
-    ErrFirstByteTimeout  = errors.New("first byte timeout")
-    ErrStreamIdleTimeout = errors.New("stream idle timeout")
-    ErrUpstreamError     = errors.New("upstream error")
-    ErrFormatConversion  = errors.New("format conversion error")
-    ErrUnsupportedFormat = errors.New("unsupported format")
+    ErrNotFound           = errors.New("not found")
+    ErrAlreadyExists      = errors.New("already exists")
+    ErrSlugExists         = errors.New("slug already exists")
+    ErrInvalidInput       = errors.New("invalid input")
+    ErrInvalidRequest     = errors.New("invalid request")
+    ErrNoRoutes           = errors.New("no routes available")
+    ErrAllRoutesFailed    = errors.New("all routes failed")
+    ErrFirstByteTimeout   = errors.New("first byte timeout")
+    ErrStreamIdleTimeout  = errors.New("stream idle timeout")
+    ErrUpstreamError      = errors.New("upstream error")
+    ErrEmptyResponse      = errors.New("empty upstream response")
+    ErrFormatConversion   = errors.New("format conversion error")
+    ErrUnsupportedFormat  = errors.New("unsupported format")
+    ErrProviderNotAllowed = errors.New("pinned provider is not available for this request")
+)
+
+// ErrorCode is a stable, machine-readable error code. It is persisted on requests and
+// attempts and returned to clients, so its values must not change.
+type ErrorCode string
+
+var (
+    ErrorCodeNoRoutes            ErrorCode = "NO_ROUTES"            // No route can serve the request
+    ErrorCodeAuthFailed          ErrorCode = "AUTH_FAILED"          // The upstream rejected the credentials (401/403)
+    ErrorCodeQuotaExhausted      ErrorCode = "QUOTA_EXHAUSTED"      // The account's quota is used up
+    ErrorCodeRateLimited         ErrorCode = "RATE_LIMITED"         // Too many requests (429)
+    ErrorCodeConversionFailed    ErrorCode = "CONVERSION_FAILED"    // The request or response could not be converted between formats
+    ErrorCodeInvalidRequest      ErrorCode = "INVALID_REQUEST"      // The request was rejected before it was sent upstream
+    ErrorCodeUpstream4xx         ErrorCode = "UPSTREAM_4XX"         // Any other upstream client error
+    ErrorCodeUpstream5xx         ErrorCode = "UPSTREAM_5XX"         // Upstream server error
+    ErrorCodeUpstreamUnreachable ErrorCode = "UPSTREAM_UNREACHABLE" // Network error reaching the upstream
+    ErrorCodeTimeout             ErrorCode = "TIMEOUT"              // First byte or stream idle timeout
+    ErrorCodeClientAbort         ErrorCode = "CLIENT_ABORT"         // The client went away
+    ErrorCodeContentBlocked      ErrorCode = "CONTENT_BLOCKED"      // The upstream's content filters blocked the prompt or response
+    ErrorCodeEmptyResponse       ErrorCode = "EMPTY_RESPONSE"       // The upstream succeeded but returned no content
+    ErrorCodeInternal            ErrorCode = "INTERNAL"             // Anything else
 )
 
 // ProxyError represents an error during proxy execution
 type ProxyError struct {
+    Code               ErrorCode     // Explicit error code (empty = derived from the other fields)
     Err                error
     Retryable          bool
     Message            string
@@ -33,6 +59,8 @@ type ProxyError struct {
     IsServerError      bool          // True for 5xx errors (triggers incremental cooldown)
     IsNetworkError     bool          // True for network errors (connection timeout, DNS failure, etc.)
     HTTPStatusCode     int           // HTTP status code (for logging and error handling)
+    ResponseBody       []byte        // Upstream error response body (translated into the client's error format)
+    ResponseFormat     ClientType    // Format of ResponseBody (empty = unknown)
 }
 
 // RateLimitInfo contains detailed rate limit information from providers
@@ -54,6 +82,67 @@ func (e *ProxyError) Unwrap() error {
     return e.Err
 }
 
+// ErrorCode returns the error's code: Code if set, otherwise one derived from the status
+// code, flags and wrapped error
+func (e *ProxyError) ErrorCode() ErrorCode {
+    if e.Code != "" {
+        return e.Code
+    }
+    if code := sentinelErrorCode(e.Err); code != "" {
+        return code
+    }
+    switch {
+    case e.RateLimitInfo != nil && e.RateLimitInfo.Type == "quota_exhausted":
+        return ErrorCodeQuotaExhausted
+    case e.HTTPStatusCode == 429 || e.RateLimitInfo != nil:
+        return ErrorCodeRateLimited
+    case e.HTTPStatusCode == 401 || e.HTTPStatusCode == 403:
+        return ErrorCodeAuthFailed
+    case e.IsServerError || e.HTTPStatusCode >= 500:
+        return ErrorCodeUpstream5xx
+    case e.HTTPStatusCode >= 400:
+        return ErrorCodeUpstream4xx
+    case e.IsNetworkError:
+        return ErrorCodeUpstreamUnreachable
+    }
+    return ErrorCodeInternal
+}
+
+// ErrorCodeOf returns the error code of any error returned by the executor ("" for nil)
+func ErrorCodeOf(err error) ErrorCode {
+    if err == nil {
+        return ""
+    }
+    var proxyErr *ProxyError
+    if errors.As(err, &proxyErr) {
+        return proxyErr.ErrorCode()
+    }
+    if code := sentinelErrorCode(err); code != "" {
+        return code
+    }
+    return ErrorCodeInternal
+}
+
+func sentinelErrorCode(err error) ErrorCode {
+    switch {
+    case err == nil:
+        return ""
+    case errors.Is(err, ErrNoRoutes), errors.Is(err, ErrAllRoutesFailed), errors.Is(err, ErrProviderNotAllowed):
+        return ErrorCodeNoRoutes
+    case errors.Is(err, ErrFormatConversion), errors.Is(err, ErrUnsupportedFormat):
+        return ErrorCodeConversionFailed
+    case errors.Is(err, ErrInvalidRequest):
+        return ErrorCodeInvalidRequest
+    case errors.Is(err, ErrEmptyResponse):
+        return ErrorCodeEmptyResponse
+    case errors.Is(err, ErrFirstByteTimeout), errors.Is(err, ErrStreamIdleTimeout), errors.Is(err, context.DeadlineExceeded):
+        return ErrorCodeTimeout
+    case errors.Is(err, context.Canceled):
+        return ErrorCodeClientAbort
+    }
+    return ""
+}
+
 func NewProxyError(err error, retryable bool) *ProxyError {
     return &ProxyError{Err: err, Retryable: retryable}
 }
@@ -1,6 +1,9 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // 各种请求的客户端
 type ClientType string
@@ -24,6 +27,11 @@ type ProviderConfigCustom struct {
 
 	// Model 映射: RequestModel → MappedModel
 	ModelMapping map[string]string `json:"modelMapping,omitempty"`
+
+	// OpenAI-Organization / OpenAI-Project 请求头，部分 OpenAI 兼容的中转站要求携带
+	// 配置后由 maxx 注入，覆盖客户端发送的值
+	OpenAIOrganization string `json:"openaiOrganization,omitempty"`
+	OpenAIProject      string `json:"openaiProject,omitempty"`
 }
 
 type ProviderConfigAntigravity struct {
@@ -45,6 +53,28 @@ type ProviderConfigAntigravity struct {
 	// Haiku 模型映射目标 (默认 "gemini-2.5-flash-lite" 省钱，可选 "claude-sonnet-4-5" 更强)
 	// 空值使用默认 gemini-2.5-flash-lite
 	HaikuTarget string `json:"haikuTarget,omitempty"`
+
+	// 历史工具调用的 thoughtSignature 处理方式，空值为 auto；路由上的配置优先
+	ThoughtSignatureMode ThoughtSignatureMode `json:"thoughtSignatureMode,omitempty"`
+}
+
+// ThoughtSignatureMode 向 Gemini 回传历史工具调用时 thoughtSignature 的处理方式
+type ThoughtSignatureMode string
+
+var (
+	ThoughtSignatureUnset      ThoughtSignatureMode = ""            // 未设置：路由跟随供应商，供应商按 auto
+	ThoughtSignatureAuto       ThoughtSignatureMode = "auto"        // 尽量恢复签名（含全局兜底），恢复不到时不带签名
+	ThoughtSignatureStrict     ThoughtSignatureMode = "strict"      // 只使用本会话中的签名，不做全局兜底
+	ThoughtSignatureAlwaysSkip ThoughtSignatureMode = "always_skip" // 没有有效签名的工具调用一律使用 skip_thought_signature_validator 跳过校验
+)
+
+// IsValid 判断是否为已知的签名处理方式（含未设置）
+func (m ThoughtSignatureMode) IsValid() bool {
+	switch m {
+	case ThoughtSignatureUnset, ThoughtSignatureAuto, ThoughtSignatureStrict, ThoughtSignatureAlwaysSkip:
+		return true
+	}
+	return false
 }
 
 type ProviderConfigKiro struct {
@@ -66,10 +96,67 @@ type ProviderConfigKiro struct {
 	ModelMapping map[string]string `json:"modelMapping,omitempty"`
 }
 
+// ProviderHTTPConfig 上游 HTTP 连接设置（所有类型的 Provider 通用）
+type ProviderHTTPConfig struct {
+	// 代理地址，如 http://127.0.0.1:7890；空值使用环境变量中的代理
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// 建立连接超时（秒），0 使用默认值
+	ConnectTimeoutSeconds int `json:"connectTimeoutSeconds,omitempty"`
+}
+
+// ProviderQuotaConfig 本地配额（所有类型的 Provider 通用）
+// 用完后 Router 跳过该 Provider，直到下一个重置时间（见 ProviderResetSchedule）
+type ProviderQuotaConfig struct {
+	// 每个重置周期的请求数上限，0 表示不限制
+	RequestsPerDay uint64 `json:"requestsPerDay,omitempty"`
+
+	// 每个重置周期的 Token 数上限（输入 + 输出），0 表示不限制
+	TokensPerDay uint64 `json:"tokensPerDay,omitempty"`
+}
+
+// ProviderResetSchedule Provider 的配额重置时间（按 Provider 所在时区）
+// 本地配额按该时间重置；上游报告配额耗尽但未给出重置时间时，冷却到下一个重置时间
+// 未配置时每天 00:00（服务器本地时间）重置
+type ProviderResetSchedule struct {
+	// 每天的重置时间 "HH:MM"
+	Time string `json:"time,omitempty"`
+
+	// cron 表达式（分 时 日 月 周），设置后忽略 Time，如 "0 0 * * 1" 表示每周一 00:00
+	Cron string `json:"cron,omitempty"`
+
+	// IANA 时区，如 "America/Los_Angeles"，空值使用服务器本地时区
+	Timezone string `json:"timezone,omitempty"`
+}
+
+// ProviderResponseRules 响应返回给客户端前的后处理（所有类型的 Provider 通用）
+// 作用于转换后的客户端格式响应，流式响应逐个事件处理；错误响应不处理
+type ProviderResponseRules struct {
+	// 从文本内容中删除的水印/前缀，如 "[Powered by xxx] "
+	// 流式响应中被拆到两个事件里的水印无法删除
+	StripText []string `json:"stripText,omitempty"`
+
+	// 把响应中的模型名改写为客户端请求的模型名（而不是映射后的上游模型名）
+	RewriteModel bool `json:"rewriteModel,omitempty"`
+
+	// 从响应 JSON 中删除的字段，点号分隔的路径，如 "system_fingerprint"、"usage.provider_meta"
+	// 路径经过数组时作用于每个元素，如 "choices.logprobs"
+	RemoveFields []string `json:"removeFields,omitempty"`
+}
+
+// IsEmpty 判断是否没有任何规则
+func (r *ProviderResponseRules) IsEmpty() bool {
+	return r == nil || (len(r.StripText) == 0 && !r.RewriteModel && len(r.RemoveFields) == 0)
+}
+
 type ProviderConfig struct {
-	Custom      *ProviderConfigCustom      `json:"custom,omitempty"`
-	Antigravity *ProviderConfigAntigravity `json:"antigravity,omitempty"`
-	Kiro        *ProviderConfigKiro        `json:"kiro,omitempty"`
+	Custom        *ProviderConfigCustom      `json:"custom,omitempty"`
+	Antigravity   *ProviderConfigAntigravity `json:"antigravity,omitempty"`
+	Kiro          *ProviderConfigKiro        `json:"kiro,omitempty"`
+	HTTP          *ProviderHTTPConfig        `json:"http,omitempty"`
+	Quota         *ProviderQuotaConfig       `json:"quota,omitempty"`
+	ResetSchedule *ProviderResetSchedule     `json:"resetSchedule,omitempty"`
+	ResponseRules *ProviderResponseRules     `json:"responseRules,omitempty"`
 }
 
 // Provider 供应商
@@ -101,6 +188,26 @@ type Provider struct {
 	// 如果配置了，在 Route 匹配时会检查前置映射后的模型是否在支持列表中
 	// 空数组表示支持所有模型
 	SupportModels []string `json:"supportModels,omitempty"`
+
+	// 该供应商下路由的默认重试配置，0 表示使用系统默认
+	// 优先级：路由配置 > 供应商配置 > 系统默认
+	RetryConfigID uint64 `json:"retryConfigID,omitempty"`
+
+	// 停用后不参与路由，配置和路由保持不变
+	Disabled bool `json:"disabled,omitempty"`
+
+	// 标签，用于筛选和批量操作（如同一账号池的 Antigravity 账号）
+	Labels []string `json:"labels,omitempty"`
+}
+
+// HasLabel 判断 Provider 是否带有指定标签（不区分大小写）
+func (p *Provider) HasLabel(label string) bool {
+	for _, l := range p.Labels {
+		if strings.EqualFold(l, label) {
+			return true
+		}
+	}
+	return false
 }
 
 type Project struct {
@@ -116,6 +223,9 @@ type Project struct {
 
 	// 启用自定义路由的 ClientType 列表，空数组表示所有 ClientType 都使用全局路由
 	EnabledCustomRoutes []ClientType `json:"enabledCustomRoutes"`
+
+	// Claude 后台小模型请求（话题检测、配额检查等）由本地应答器直接返回，不消耗上游配额
+	LocalBackgroundResponder bool `json:"localBackgroundResponder"`
 }
 
 type Session struct {
@@ -134,6 +244,38 @@ type Session struct {
 
 	// RejectedAt 记录会话被拒绝的时间，nil 表示未被拒绝
 	RejectedAt *time.Time `json:"rejectedAt,omitempty"`
+
+	// 会话固定路由（管理员设置），0 表示不固定
+	// PinnedRouteID 优先于 PinnedProviderID
+	PinnedRouteID    uint64 `json:"pinnedRouteID"`
+	PinnedProviderID uint64 `json:"pinnedProviderID"`
+
+	// 最近一次请求的时间（按分钟更新），nil 表示升级前创建且之后没有请求
+	LastActiveAt *time.Time `json:"lastActiveAt,omitempty"`
+
+	// 因长时间空闲被归档的时间，nil 表示未归档；有新请求时自动取消归档
+	ArchivedAt *time.Time `json:"archivedAt,omitempty"`
+}
+
+// LastActivity 返回会话最近一次活动的时间
+func (s *Session) LastActivity() time.Time {
+	if s.LastActiveAt != nil {
+		return *s.LastActiveAt
+	}
+	return s.CreatedAt
+}
+
+// 会话空闲处理方式
+const (
+	SessionIdleActionUnbind  = "unbind"  // 解除项目绑定和固定路由
+	SessionIdleActionArchive = "archive" // 解除绑定并归档，会话列表默认不再显示
+)
+
+// SessionExpiryResult 一次空闲会话清理的结果
+type SessionExpiryResult struct {
+	Action     string   `json:"action"`
+	IdleHours  int      `json:"idleHours"`
+	SessionIDs []string `json:"sessionIDs"`
 }
 
 // 路由
@@ -161,6 +303,225 @@ type Route struct {
 
 	// 重试配置，0 表示使用系统默认
 	RetryConfigID uint64 `json:"retryConfigID"`
+
+	// 发往该路由前对客户端 system prompt 的处理，nil 表示原样转发
+	SystemPrompt *RouteSystemPrompt `json:"systemPrompt,omitempty"`
+
+	// thoughtSignature 处理方式（仅 Antigravity），空值表示跟随供应商配置
+	ThoughtSignatureMode ThoughtSignatureMode `json:"thoughtSignatureMode,omitempty"`
+
+	// Claude 请求转换为 Gemini 格式时加在 system prompt 前的身份补丁，nil 表示使用默认补丁
+	IdentityPatch *RouteIdentityPatch `json:"identityPatch,omitempty"`
+
+	// A/B 实验：按比例把该路由的流量分给两个目标，nil 表示不做实验
+	Experiment *RouteExperiment `json:"experiment,omitempty"`
+
+	// Claude 流式响应在上游无输出时发送 ping 事件的间隔（秒），0 表示默认 15 秒，负数表示不发送
+	// 目前仅 Antigravity 生效（Gemini 长时间思考时没有输出）
+	PingIntervalSeconds int `json:"pingIntervalSeconds,omitempty"`
+
+	// OpenAI 客户端经 Gemini 上游时隐藏思考内容：不请求也不返回 reasoning_content
+	HideReasoning bool `json:"hideReasoning"`
+}
+
+// 默认 ping 间隔（秒）
+const DefaultPingIntervalSeconds = 15
+
+// PingInterval 返回路由的 ping 间隔，0 表示不发送
+func (r *Route) PingInterval() time.Duration {
+	switch {
+	case r.PingIntervalSeconds < 0:
+		return 0
+	case r.PingIntervalSeconds == 0:
+		return DefaultPingIntervalSeconds * time.Second
+	}
+	return time.Duration(r.PingIntervalSeconds) * time.Second
+}
+
+// 实验组
+const (
+	ExperimentArmA = "A"
+	ExperimentArmB = "B"
+)
+
+// RouteExperiment 路由级 A/B 实验
+// 同一会话的请求始终分到同一组，便于比较两组的延迟、成本和错误率
+type RouteExperiment struct {
+	Enabled bool `json:"enabled"`
+	// 实验名称，请求按名称和组别打标签
+	Name string `json:"name"`
+	// A 组（对照组）和 B 组的目标
+	A ExperimentArm `json:"a"`
+	B ExperimentArm `json:"b"`
+	// 分给 B 组的流量百分比 (0-100)
+	SplitPercent int `json:"splitPercent"`
+}
+
+// ExperimentArm 实验组的目标供应商和模型
+type ExperimentArm struct {
+	// 0 表示使用路由自身的供应商
+	ProviderID uint64 `json:"providerID,omitempty"`
+	// 空值表示按正常的模型映射
+	Model string `json:"model,omitempty"`
+}
+
+// Active 实验是否生效
+func (e *RouteExperiment) Active() bool {
+	return e != nil && e.Enabled && e.Name != ""
+}
+
+// Arm 返回实验组的目标
+func (e *RouteExperiment) Arm(arm string) ExperimentArm {
+	if arm == ExperimentArmB {
+		return e.B
+	}
+	return e.A
+}
+
+// RouteIdentityPatch 路由的身份补丁配置
+type RouteIdentityPatch struct {
+	// 不加身份补丁
+	Disabled bool `json:"disabled,omitempty"`
+	// 补丁模板，空值使用默认补丁；支持变量 {{model}}、{{clientType}}、{{provider}}
+	Template string `json:"template,omitempty"`
+}
+
+// SystemPromptMode 路由对 system prompt 的处理方式
+type SystemPromptMode string
+
+var (
+	SystemPromptKeep     SystemPromptMode = ""         // 原样转发
+	SystemPromptStrip    SystemPromptMode = "strip"    // 删除
+	SystemPromptTruncate SystemPromptMode = "truncate" // 截断到 MaxChars 个字符
+	SystemPromptHash     SystemPromptMode = "hash"     // 替换为 SHA-256 摘要，不泄露内容但仍可区分
+	SystemPromptReplace  SystemPromptMode = "replace"  // 替换为 Replacement
+)
+
+// RouteSystemPrompt 路由的 system prompt 处理配置
+// 在格式转换之前作用于客户端格式的请求体
+type RouteSystemPrompt struct {
+	Mode        SystemPromptMode `json:"mode"`
+	MaxChars    int              `json:"maxChars,omitempty"`
+	Replacement string           `json:"replacement,omitempty"`
+
+	// 在请求记录中保留原始 system prompt 便于调试；
+	// 为 false 时客户端请求记录中的 system prompt 也按相同方式处理
+	RecordOriginal bool `json:"recordOriginal,omitempty"`
+}
+
+// RouteCandidate 路由模拟中的一个候选路由（按尝试顺序排列）
+type RouteCandidate struct {
+	Route *Route `json:"route"`
+	// 实际使用的 Provider：路由运行实验时为所分配实验组的 Provider
+	ProviderID   uint64 `json:"providerID"`
+	ProviderName string `json:"providerName,omitempty"`
+	ProviderType string `json:"providerType,omitempty"`
+
+	// 路由实验分配的实验组（未运行实验时为空）；ExperimentModel 非空时替代模型映射
+	ExperimentArm   string `json:"experimentArm,omitempty"`
+	ExperimentModel string `json:"experimentModel,omitempty"`
+
+	// 是否会被尝试；false 时 SkipReason 说明原因
+	Selected bool `json:"selected"`
+	// provider_not_found, provider_disabled, cooldown, quota_exhausted, no_adapter, unsupported_model
+	SkipReason string `json:"skipReason,omitempty"`
+
+	// 冷却状态，未冷却时为空
+	CooldownUntil  *time.Time     `json:"cooldownUntil,omitempty"`
+	CooldownReason CooldownReason `json:"cooldownReason,omitempty"`
+
+	// 生效的重试配置（路由 > 供应商 > 系统默认）及其来源
+	RetryConfig       *RetryConfig      `json:"retryConfig,omitempty"`
+	RetryConfigSource RetryConfigSource `json:"retryConfigSource,omitempty"`
+
+	// 发往上游的模型及命中的映射规则（无映射时为空）
+	MappedModel  string        `json:"mappedModel,omitempty"`
+	ModelMapping *ModelMapping `json:"modelMapping,omitempty"`
+}
+
+// 路由决策的选择路径
+const (
+	RouterDecisionStrategy   = "strategy"    // 按路由策略排序
+	RouterDecisionPinned     = "pinned"      // 请求指定了 Provider
+	RouterDecisionSessionPin = "session_pin" // 会话固定的路由或 Provider
+	RouterDecisionBackground = "background"  // 后台请求使用的 Provider
+)
+
+// RouterDecision 一次路由匹配的决策过程（按 router_decision_sample_rate 采样记录），
+// 用于事后回答"为什么选了这个 Provider"
+type RouterDecision struct {
+	// 最终采用的选择路径
+	Mode string `json:"mode"`
+	// 是否使用项目自定义路由
+	ProjectRoutes bool `json:"projectRoutes"`
+	// 路由策略及其配置，仅 Mode 为 strategy 时填充
+	Strategy       RoutingStrategyType    `json:"strategy,omitempty"`
+	StrategyConfig *RoutingStrategyConfig `json:"strategyConfig,omitempty"`
+	// 策略排序时每个 Provider 的输入
+	StrategyInputs []*RouterStrategyInput `json:"strategyInputs,omitempty"`
+	// 考虑过的候选路由（按排序后的顺序），跳过的候选注明原因
+	Candidates []*RouterDecisionCandidate `json:"candidates"`
+	// 最终尝试顺序（路由 ID）
+	Order []uint64 `json:"order"`
+	// 回退等补充说明，如会话固定的 Provider 不可用
+	Notes []string `json:"notes,omitempty"`
+}
+
+// RouterStrategyInput 路由策略排序时一个 Provider 的输入
+type RouterStrategyInput struct {
+	ProviderID uint64 `json:"providerID"`
+	// 加权随机策略的权重
+	Weight *int `json:"weight,omitempty"`
+	// 本地配额剩余比例 0-1，未配置配额时为空
+	QuotaRemaining *float64 `json:"quotaRemaining,omitempty"`
+	// 策略读取了请求统计时填充
+	SuccessRate    *float64 `json:"successRate,omitempty"`
+	ActiveRequests *uint64  `json:"activeRequests,omitempty"`
+	// 成本优先策略：按映射后模型预估的请求成本（微美元），模型无定价时为空
+	ProjectedCost *uint64 `json:"projectedCost,omitempty"`
+	// 成本优先策略：平均请求耗时（毫秒）
+	AvgDurationMs *uint64 `json:"avgDurationMs,omitempty"`
+	// 故障切换后被降级，排在备用 Provider 之后
+	Demoted bool `json:"demoted,omitempty"`
+}
+
+// RouterDecisionCandidate 路由决策中的一个候选路由
+type RouterDecisionCandidate struct {
+	RouteID      uint64 `json:"routeID"`
+	ProviderID   uint64 `json:"providerID"`
+	ProviderName string `json:"providerName,omitempty"`
+	Position     int    `json:"position"`
+	// 路由实验分组，未参与实验时为空
+	ExperimentArm string `json:"experimentArm,omitempty"`
+	// 是否进入尝试列表；false 时 SkipReason 说明原因（取值同 RouteCandidate.SkipReason）
+	Selected   bool   `json:"selected"`
+	SkipReason string `json:"skipReason,omitempty"`
+	// 冷却结束时间，仅因冷却跳过时填充
+	CooldownUntil *time.Time `json:"cooldownUntil,omitempty"`
+}
+
+// RouteSimulationRequest 路由模拟参数
+type RouteSimulationRequest struct {
+	ClientType ClientType `json:"clientType"`
+	ProjectID  uint64     `json:"projectID"`
+	Model      string     `json:"model"`
+	APITokenID uint64     `json:"apiTokenID,omitempty"`
+	// 决定路由实验的分组；为空时随机分组
+	SessionID string `json:"sessionID,omitempty"`
+}
+
+// RouteSimulation 路由模拟结果：不发送请求，只展示 Router 匹配和模型映射的结果
+type RouteSimulation struct {
+	RouteSimulationRequest
+
+	// 实际使用的路由策略；加权随机策略下每次模拟的顺序可能不同
+	Strategy RoutingStrategyType `json:"strategy,omitempty"`
+	// 是否使用项目自定义路由
+	UseProjectRoutes bool `json:"useProjectRoutes"`
+
+	Candidates []*RouteCandidate `json:"candidates"`
+	// 没有可用路由时的错误
+	Error string `json:"error,omitempty"`
 }
 
 // RoutePositionUpdate represents a route position update
@@ -169,16 +530,50 @@ type RoutePositionUpdate struct {
 	Position int    `json:"position"`
 }
 
+// RouteConversion 供应商与客户端类型的一个组合：原生支持，或可以通过格式转换提供
+type RouteConversion struct {
+	ProviderID   uint64     `json:"providerID"`
+	ProviderName string     `json:"providerName"`
+	ClientType   ClientType `json:"clientType"`
+
+	// 供应商原生支持该客户端类型，不需要转换路由
+	Native bool `json:"native"`
+	// 请求发往供应商时使用的格式，原生时与 ClientType 相同
+	TargetType ClientType `json:"targetType"`
+	// 是否可以转换（请求和响应方向都有转换器）
+	Convertible bool `json:"convertible"`
+
+	// 该项目下已有的路由，0 表示还没有
+	RouteID      uint64 `json:"routeID,omitempty"`
+	RouteEnabled bool   `json:"routeEnabled,omitempty"`
+}
+
+// ConvertedRoutesRequest 为一个供应商创建（或启用/停用）转换路由
+type ConvertedRoutesRequest struct {
+	ProjectID  uint64 `json:"projectID"`
+	ProviderID uint64 `json:"providerID"`
+	// 要提供的客户端类型，必须是供应商不原生支持、但可以转换的类型
+	ClientTypes []ClientType `json:"clientTypes"`
+	IsEnabled   bool         `json:"isEnabled"`
+}
+
 type RequestInfo struct {
 	Method  string            `json:"method"`
 	Headers map[string]string `json:"headers"`
 	URL     string            `json:"url"`
 	Body    string            `json:"body"`
+	// 请求体存放在 blob store 时的引用；加载成功后为空
+	BodyRef string `json:"bodyRef,omitempty"`
 }
 type ResponseInfo struct {
 	Status  int               `json:"status"`
 	Headers map[string]string `json:"headers"`
 	Body    string            `json:"body"`
+	// 响应体存放在 blob store 时的引用；加载成功后为空
+	BodyRef string `json:"bodyRef,omitempty"`
+	// 响应体超过记录上限时 Body 只保留开头部分，BodySize 为完整大小（字节）
+	BodyTruncated bool  `json:"bodyTruncated,omitempty"`
+	BodySize      int64 `json:"bodySize,omitempty"`
 }
 
 // 追踪
@@ -204,8 +599,9 @@ type ProxyRequest struct {
 	// 是否为 SSE 流式请求
 	IsStream bool `json:"isStream"`
 
-	// PENDING, IN_PROGRESS, COMPLETED, FAILED, REJECTED
+	// PENDING, IN_PROGRESS, COMPLETED, FAILED, REJECTED, INTERRUPTED
 	// REJECTED: 请求被拒绝（如：强制项目绑定超时）
+	// INTERRUPTED: 服务异常退出时仍在处理中，重启后被标记
 	Status string `json:"status"`
 
 	// HTTP 状态码（冗余存储，用于列表查询性能优化）
@@ -217,6 +613,7 @@ type ProxyRequest struct {
 
 	// 错误信息
 	Error                       string `json:"error"`
+	ErrorCode                   ErrorCode `json:"errorCode,omitempty"`
 	ProxyUpstreamAttemptCount   uint64 `json:"proxyUpstreamAttemptCount"`
 	FinalProxyUpstreamAttemptID uint64 `json:"finalProxyUpstreamAttemptID"`
 
@@ -244,6 +641,36 @@ type ProxyRequest struct {
 
 	// 使用的 API Token ID，0 表示未使用 Token
 	APITokenID uint64 `json:"apiTokenID"`
+
+	// 请求形态（客户端原始请求体），用于按模型/供应商分析请求大小
+	PromptBytes  uint64 `json:"promptBytes"`
+	MessageCount int    `json:"messageCount"`
+	ToolCount    int    `json:"toolCount"`
+	HasImage     bool   `json:"hasImage"`
+
+	// Claude 请求的可缓存前缀（system + tools）指纹，用于 prompt 缓存分析
+	PrefixHash  string `json:"prefixHash,omitempty"`
+	PrefixBytes uint64 `json:"prefixBytes,omitempty"`
+	// 客户端请求带有 cache_control
+	CacheControl bool `json:"cacheControl,omitempty"`
+	// 最后尝试的供应商不会把 cache_control 传给上游（格式转换或 Antigravity/Kiro 改写）
+	CacheControlStripped bool `json:"cacheControlStripped,omitempty"`
+
+	// 该会话在短时间内重复发送相似请求（代理循环）
+	AgentLoop bool `json:"agentLoop,omitempty"`
+
+	// 后台请求由本地应答器直接返回，未发送到上游
+	LocalResponse bool `json:"localResponse,omitempty"`
+
+	// 请求所属的路由实验及分组（最后尝试的路由），空值表示未参与实验
+	Experiment    string `json:"experiment,omitempty"`
+	ExperimentArm string `json:"experimentArm,omitempty"`
+
+	// 路由决策过程（按采样率记录，未采样时为空；列表接口不返回）
+	RouterDecision *RouterDecision `json:"routerDecision,omitempty"`
+
+	// 重试链路：每次尝试后执行器的决定（仅请求详情接口填充，不持久化）
+	RetryChain []*RetryStep `json:"retryChain,omitempty"`
 }
 
 type ProxyUpstreamAttempt struct {
@@ -256,9 +683,15 @@ type ProxyUpstreamAttempt struct {
 	EndTime   time.Time     `json:"endTime"`
 	Duration  time.Duration `json:"duration"`
 
-	// PENDING, IN_PROGRESS, COMPLETED, FAILED
+	// PENDING, IN_PROGRESS, COMPLETED, FAILED, CANCELLED, INTERRUPTED
 	Status string `json:"status"`
 
+	// 失败原因的错误码（成功时为空；上游内容过滤拦截时为 CONTENT_BLOCKED）
+	ErrorCode ErrorCode `json:"errorCode,omitempty"`
+
+	// 上游内容过滤拦截的类别（如 Gemini 的 SAFETY、RECITATION），未拦截时为空
+	BlockReason string `json:"blockReason,omitempty"`
+
 	ProxyRequestID uint64 `json:"proxyRequestID"`
 
 	// 是否为 SSE 流式请求
@@ -275,6 +708,9 @@ type ProxyUpstreamAttempt struct {
 	RequestInfo  *RequestInfo  `json:"requestInfo"`
 	ResponseInfo *ResponseInfo `json:"responseInfo"`
 
+	// 各阶段耗时（连接相关字段取最后一次上游请求）
+	Timings *UpstreamTimings `json:"timings,omitempty"`
+
 	RouteID    uint64 `json:"routeID"`
 	ProviderID uint64 `json:"providerID"`
 
@@ -293,6 +729,191 @@ type ProxyUpstreamAttempt struct {
 	Cache1hWriteCount uint64 `json:"cache1hWriteCount"`
 
 	Cost uint64 `json:"cost"`
+
+	// 本次尝试失败后执行器的决定（成功时为空）
+	Decision *AttemptDecision `json:"decision,omitempty"`
+}
+
+// AttemptAction 尝试失败后执行器采取的动作
+type AttemptAction string
+
+var (
+	// 在同一路由上重试
+	AttemptActionRetry AttemptAction = "retry"
+	// 在同一路由上换用回退模型
+	AttemptActionFallbackModel AttemptAction = "fallback_model"
+	// 切换到下一个路由
+	AttemptActionNextRoute AttemptAction = "next_route"
+	// 结束请求（没有可用路由或客户端已断开）
+	AttemptActionStop AttemptAction = "stop"
+)
+
+// AttemptDecisionReason 执行器做出决定的原因
+type AttemptDecisionReason string
+
+var (
+	// 可重试的错误
+	DecisionReasonRetryable AttemptDecisionReason = "retryable_error"
+	// 不可重试的错误
+	DecisionReasonNonRetryable AttemptDecisionReason = "non_retryable_error"
+	// 重试次数已用完
+	DecisionReasonRetriesExhausted AttemptDecisionReason = "retries_exhausted"
+	// 模型不支持该请求
+	DecisionReasonModelUnsupported AttemptDecisionReason = "model_unsupported"
+	// 客户端断开（context 取消）
+	DecisionReasonCancelled AttemptDecisionReason = "context_cancelled"
+	// 非 ProxyError 的未知错误
+	DecisionReasonUnknownError AttemptDecisionReason = "unknown_error"
+	// 响应被上游内容过滤拦截，改用无审查备用 Provider
+	DecisionReasonContentBlocked AttemptDecisionReason = "content_blocked"
+)
+
+// AttemptDecision 一次失败尝试之后执行器的决定
+type AttemptDecision struct {
+	Action AttemptAction         `json:"action"`
+	Reason AttemptDecisionReason `json:"reason"`
+
+	// 重试前的等待时间
+	RetryWait time.Duration `json:"retryWait,omitempty"`
+
+	// 本次失败使 Provider 进入冷却时的冷却截止时间
+	CooldownUntil *time.Time `json:"cooldownUntil,omitempty"`
+
+	// 换用的回退模型（fallback_model）
+	FallbackModel string `json:"fallbackModel,omitempty"`
+}
+
+// RetryStep 重试链路中的一步
+type RetryStep struct {
+	AttemptID  uint64           `json:"attemptID"`
+	RouteID    uint64           `json:"routeID"`
+	ProviderID uint64           `json:"providerID"`
+	Model      string           `json:"model"`
+	Status     string           `json:"status"`
+	ErrorCode  ErrorCode        `json:"errorCode,omitempty"`
+	Decision   *AttemptDecision `json:"decision,omitempty"`
+}
+
+// UpstreamTimings 上游请求各阶段耗时
+// 连接复用时 DNS/Connect/TLS 为 0
+type UpstreamTimings struct {
+	Wait        time.Duration `json:"wait"`       // 请求到达（或上一次尝试结束）到本次尝试开始的等待，包括项目绑定等待、路由和重试退避
+	Conversion  time.Duration `json:"conversion"` // 请求格式转换
+	DNS         time.Duration `json:"dns"`
+	Connect     time.Duration `json:"connect"`
+	TLS         time.Duration `json:"tls"`
+	TTFB        time.Duration `json:"ttfb"`        // 从发出请求到收到响应首字节
+	Stream      time.Duration `json:"stream"`      // 从收到响应头到读完响应体（流式请求为整个流的时长）
+	ClientWrite time.Duration `json:"clientWrite"` // 向客户端写出和 flush 的累计耗时
+	ConnReused  bool          `json:"connReused"`
+}
+
+// Merge 用 other 中非零的字段覆盖 t
+func (t *UpstreamTimings) Merge(other *UpstreamTimings) {
+	if other == nil {
+		return
+	}
+	set := func(dst *time.Duration, src time.Duration) {
+		if src > 0 {
+			*dst = src
+		}
+	}
+	set(&t.Wait, other.Wait)
+	set(&t.Conversion, other.Conversion)
+	set(&t.DNS, other.DNS)
+	set(&t.Connect, other.Connect)
+	set(&t.TLS, other.TLS)
+	set(&t.TTFB, other.TTFB)
+	set(&t.Stream, other.Stream)
+	set(&t.ClientWrite, other.ClientWrite)
+	if other.ConnReused {
+		t.ConnReused = true
+	}
+}
+
+// JSONChange 两个 JSON 文档之间的一处差异
+type JSONChange struct {
+	Op   string      `json:"op"`   // add, remove, replace
+	Path string      `json:"path"` // JSON Pointer（RFC 6901）
+	From interface{} `json:"from"` // 原值，add 时为 null
+	To   interface{} `json:"to"`   // 新值，remove 时为 null
+}
+
+// BodyDiff 两个请求/响应体之间的差异
+type BodyDiff struct {
+	// 两者都是 JSON 文档时才可比较（SSE 流等不可比较）
+	Comparable bool         `json:"comparable"`
+	Changes    []JSONChange `json:"changes"`
+	// 差异过多时只返回前若干条
+	Truncated bool `json:"truncated"`
+}
+
+// AttemptDiff 一次上游尝试的请求/响应，以及与客户端请求/响应的差异
+type AttemptDiff struct {
+	AttemptID    uint64 `json:"attemptID"`
+	ProviderID   uint64 `json:"providerID"`
+	Status       string `json:"status"`
+	RequestModel string `json:"requestModel"`
+	MappedModel  string `json:"mappedModel"`
+
+	UpstreamRequest  *RequestInfo  `json:"upstreamRequest"`
+	UpstreamResponse *ResponseInfo `json:"upstreamResponse"`
+
+	// 客户端请求体 -> 上游请求体，即转换器、模型映射等对请求所做的修改
+	RequestDiff *BodyDiff `json:"requestDiff"`
+	// 上游响应体 -> 客户端响应体，仅最终尝试有
+	ResponseDiff *BodyDiff `json:"responseDiff,omitempty"`
+}
+
+// ProxyRequestDiff 客户端请求与各次上游尝试的对照视图
+type ProxyRequestDiff struct {
+	ProxyRequestID uint64         `json:"proxyRequestID"`
+	ClientType     ClientType     `json:"clientType"`
+	Status         string         `json:"status"`
+	ClientRequest  *RequestInfo   `json:"clientRequest"`
+	ClientResponse *ResponseInfo  `json:"clientResponse"`
+	Attempts       []*AttemptDiff `json:"attempts"`
+}
+
+// RecoverySummary 启动时崩溃恢复的结果
+// 上一个实例遗留的 PENDING/IN_PROGRESS 请求和尝试会被标记为 INTERRUPTED
+type RecoverySummary struct {
+	InstanceID          string    `json:"instanceID"`
+	RecoveredAt         time.Time `json:"recoveredAt"`
+	InterruptedRequests int64     `json:"interruptedRequests"` // 包含超时被标记为 FAILED 的请求
+	InterruptedAttempts int64     `json:"interruptedAttempts"`
+}
+
+// ConfigIssueSeverity 配置问题级别
+type ConfigIssueSeverity string
+
+var (
+	// 配置错误，相关的 Provider/Route 无法正常工作
+	ConfigIssueError ConfigIssueSeverity = "error"
+	// 配置可疑，可能影响部分请求
+	ConfigIssueWarning ConfigIssueSeverity = "warning"
+)
+
+// ConfigIssue 配置检查发现的单个问题
+type ConfigIssue struct {
+	Severity ConfigIssueSeverity `json:"severity"`
+	// 资源类型: provider, route, model_mapping
+	Kind         string `json:"kind"`
+	ResourceID   uint64 `json:"resourceID"`
+	ResourceName string `json:"resourceName,omitempty"`
+	// 问题代码，如 missing_config, orphaned_route, unreachable_base_url, invalid_refresh_token
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ConfigReport 配置检查报告（启动时生成，可通过 /admin/health/config 重新检查）
+type ConfigReport struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	// 没有 error 级别的问题
+	OK       bool           `json:"ok"`
+	Errors   int            `json:"errors"`
+	Warnings int            `json:"warnings"`
+	Issues   []*ConfigIssue `json:"issues"`
 }
 
 // 重试配置
@@ -323,6 +944,33 @@ type RetryConfig struct {
 	MaxInterval time.Duration `json:"maxInterval"`
 }
 
+// RetryConfigSource 生效重试配置的来源
+type RetryConfigSource string
+
+var (
+	RetryConfigSourceRoute    RetryConfigSource = "route"
+	RetryConfigSourceProvider RetryConfigSource = "provider"
+	RetryConfigSourceDefault  RetryConfigSource = "default"
+	// 没有任何可用配置，不重试
+	RetryConfigSourceNone RetryConfigSource = "none"
+)
+
+// EffectiveRetryConfig 路由实际生效的重试配置及其来源
+type EffectiveRetryConfig struct {
+	RouteID    uint64 `json:"routeID"`
+	ProviderID uint64 `json:"providerID"`
+
+	Source RetryConfigSource `json:"source"`
+
+	// 生效的配置，Source 为 none 时为 nil
+	Config *RetryConfig `json:"config,omitempty"`
+
+	// 各层级配置的 ID，0 表示该层未配置（或引用的配置已被删除）
+	RouteRetryConfigID    uint64 `json:"routeRetryConfigID"`
+	ProviderRetryConfigID uint64 `json:"providerRetryConfigID"`
+	DefaultRetryConfigID  uint64 `json:"defaultRetryConfigID"`
+}
+
 // 路由策略类型
 type RoutingStrategyType string
 
@@ -331,12 +979,24 @@ var (
 	RoutingStrategyPriority RoutingStrategyType = "priority"
 	// 加权随机
 	RoutingStrategyWeightedRandom RoutingStrategyType = "weighted_random"
+	// 优先使用本地配额剩余最多的 Provider
+	RoutingStrategyQuotaAware RoutingStrategyType = "quota_aware"
+	// 按映射后模型的预估成本排序，优先使用最便宜的 Provider，可限定平均延迟
+	RoutingStrategyCostAware RoutingStrategyType = "cost_aware"
 )
 
 // 路由策略配置（策略特定参数）
 type RoutingStrategyConfig struct {
-	// 加权随机策略的权重配置等
-	// 根据具体策略扩展
+	// 加权随机策略的权重，key 为 Provider ID，未配置的为 1，0 表示排在最后
+	Weights map[uint64]int `json:"weights,omitempty"`
+
+	// 成本优先策略：预估成本时假定的输出 Token 数，0 表示默认值 1000
+	ExpectedOutputTokens uint64 `json:"expectedOutputTokens,omitempty"`
+	// 成本优先策略：平均延迟上限（毫秒），超过的 Provider 排在达标的之后，0 表示不限制
+	MaxLatencyMs uint64 `json:"maxLatencyMs,omitempty"`
+
+	// 自定义策略（通过 router.RegisterStrategy 注册）的参数
+	Params map[string]string `json:"params,omitempty"`
 }
 
 // 路由策略
@@ -358,6 +1018,212 @@ type RoutingStrategy struct {
 	Config *RoutingStrategyConfig `json:"config"`
 }
 
+// 路由方案：一组路由的启用状态和位置，可手动切换或按时间段自动应用
+type RoutingProfile struct {
+	ID        uint64    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// 软删除时间
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+
+	Name        string `json:"name"`
+	Description string `json:"description"`
+
+	// 应用方案时写入的路由状态，未列出的路由保持不变
+	Routes []RoutingProfileRoute `json:"routes"`
+
+	// 自动应用的时间段，为空表示只能手动切换
+	Schedules []RoutingProfileSchedule `json:"schedules"`
+}
+
+// 路由方案中单个路由的状态
+type RoutingProfileRoute struct {
+	RouteID   uint64 `json:"routeID"`
+	IsEnabled bool   `json:"isEnabled"`
+	Position  int    `json:"position"`
+}
+
+// 路由方案的生效时间段（服务器本地时间）
+type RoutingProfileSchedule struct {
+	// 星期几生效，0 表示周日，为空表示每天
+	Days []int `json:"days,omitempty"`
+
+	// 开始和结束时间，格式 HH:MM；结束早于开始表示跨夜，如 22:00-08:00
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// 当前生效的路由方案
+type ActiveRoutingProfile struct {
+	// 0 表示未应用任何方案
+	ProfileID uint64 `json:"profileID"`
+
+	// 按时间表应用的方案，0 表示当前没有匹配的时间段
+	ScheduledProfileID uint64 `json:"scheduledProfileID"`
+}
+
+// 费用告警的统计范围
+type CostAlertScope string
+
+const (
+	CostAlertScopeGlobal   CostAlertScope = "global"    // 全部请求
+	CostAlertScopeProject  CostAlertScope = "project"   // 单个项目
+	CostAlertScopeProvider CostAlertScope = "provider"  // 单个 Provider
+	CostAlertScopeAPIToken CostAlertScope = "api_token" // 单个 API Token
+)
+
+// 费用告警：按自然日（UTC）统计范围内的费用，超过阈值时通知，并可每日发送前一天的费用摘要
+type CostAlert struct {
+	ID        uint64    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// 软删除时间
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+
+	Name      string `json:"name"`
+	IsEnabled bool   `json:"isEnabled"`
+
+	// 统计范围，ScopeID 为项目、Provider 或 API Token 的 ID，全局时为 0
+	Scope   CostAlertScope `json:"scope"`
+	ScopeID uint64         `json:"scopeID"`
+
+	// 当日费用达到该值（微美元）时告警，0 表示不检查
+	DailyThreshold uint64 `json:"dailyThreshold"`
+
+	// 当日费用高出过去 7 天日均费用的百分比达到该值时告警，如 50 表示高出 50%，0 表示不检查
+	PercentAboveAverage float64 `json:"percentAboveAverage"`
+
+	// 每天发送前一天的费用摘要
+	DailyDigest bool `json:"dailyDigest"`
+
+	// 最近一次阈值告警和摘要的发送时间，每种通知每天最多发送一次
+	LastTriggeredAt *time.Time `json:"lastTriggeredAt,omitempty"`
+	LastDigestAt    *time.Time `json:"lastDigestAt,omitempty"`
+}
+
+// 费用告警通知的类型
+type CostAlertKind string
+
+const (
+	CostAlertKindThreshold    CostAlertKind = "threshold"     // 当日费用达到阈值
+	CostAlertKindAboveAverage CostAlertKind = "above_average" // 当日费用高于过去 7 天日均
+	CostAlertKindDigest       CostAlertKind = "digest"        // 前一天的费用摘要
+)
+
+// 费用告警通知（cost_alert 事件和 Webhook 的请求体）
+type CostAlertNotification struct {
+	AlertID   uint64         `json:"alertID"`
+	AlertName string         `json:"alertName"`
+	Kind      CostAlertKind  `json:"kind"`
+	Scope     CostAlertScope `json:"scope"`
+	ScopeID   uint64         `json:"scopeID"`
+	ScopeName string         `json:"scopeName"`
+
+	// 统计的日期（UTC），格式 YYYY-MM-DD
+	Date string `json:"date"`
+
+	// 当日（摘要为前一天）的费用（微美元）和用量
+	Cost         uint64 `json:"cost"`
+	Requests     uint64 `json:"requests"`
+	InputTokens  uint64 `json:"inputTokens"`
+	OutputTokens uint64 `json:"outputTokens"`
+
+	// 触发告警的阈值和过去 7 天的日均费用（微美元）
+	Threshold    uint64  `json:"threshold,omitempty"`
+	AverageCost  uint64  `json:"averageCost,omitempty"`
+	PercentAbove float64 `json:"percentAbove,omitempty"`
+
+	Message     string    `json:"message"`
+	TriggeredAt time.Time `json:"triggeredAt"`
+}
+
+// 延迟 SLO 统计的指标
+type LatencySLOMetric string
+
+const (
+	LatencySLOMetricTTFB     LatencySLOMetric = "ttfb"     // 发出上游请求到收到响应首字节
+	LatencySLOMetricDuration LatencySLOMetric = "duration" // 上游尝试的总耗时
+)
+
+// 延迟 SLO：Provider 处理匹配模型的上游尝试（成功与失败均计入，客户端取消和诊断请求除外）时，
+// 统计窗口内指定分位的延迟不超过目标值，如 TTFB p95 < 2s。未收到首字节的失败尝试以其总耗时作为 TTFB
+type LatencySLO struct {
+	ID        uint64    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// 软删除时间
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+
+	Name      string `json:"name"`
+	IsEnabled bool   `json:"isEnabled"`
+
+	// 适用的 Provider，0 表示所有 Provider（每个 Provider 单独计算）
+	ProviderID uint64 `json:"providerID"`
+
+	// 适用的模型（请求模型或映射后的模型），支持通配符 *，空表示所有模型
+	Model string `json:"model"`
+
+	Metric LatencySLOMetric `json:"metric"`
+
+	// 分位数，如 95 表示 p95
+	Percentile float64 `json:"percentile"`
+
+	// 目标延迟
+	Target time.Duration `json:"target"`
+
+	// 统计窗口，最长 24 小时
+	Window time.Duration `json:"window"`
+
+	// 窗口内样本少于该值时不判定是否违反
+	MinSamples int `json:"minSamples"`
+
+	// 持续违反超过该时长才发出违反事件
+	Persistence time.Duration `json:"persistence"`
+}
+
+// 某个 Provider 在一个延迟 SLO 下的达标情况
+type LatencySLOStatus struct {
+	SLOID        uint64           `json:"sloID"`
+	SLOName      string           `json:"sloName"`
+	ProviderID   uint64           `json:"providerID"`
+	ProviderName string           `json:"providerName"`
+	Model        string           `json:"model"`
+	Metric       LatencySLOMetric `json:"metric"`
+	Percentile   float64          `json:"percentile"`
+	Target       time.Duration    `json:"target"`
+
+	// 统计窗口内的样本数、分位延迟，以及不超过目标的样本比例（0-1）
+	Samples    int           `json:"samples"`
+	Value      time.Duration `json:"value"`
+	Compliance float64       `json:"compliance"`
+
+	// 样本足够且分位延迟超过目标
+	Violating      bool       `json:"violating"`
+	ViolatingSince *time.Time `json:"violatingSince,omitempty"`
+
+	// 违反已持续超过 Persistence，已发出违反事件
+	Persistent bool `json:"persistent"`
+}
+
+// 延迟 SLO 事件的类型
+type LatencySLOEventKind string
+
+const (
+	LatencySLOEventViolation LatencySLOEventKind = "violation" // 持续违反
+	LatencySLOEventRecovered LatencySLOEventKind = "recovered" // 持续违反后恢复达标
+)
+
+// 延迟 SLO 事件（latency_slo 广播事件的内容）
+type LatencySLOEvent struct {
+	*LatencySLOStatus
+	Kind        LatencySLOEventKind `json:"kind"`
+	Message     string              `json:"message"`
+	TriggeredAt time.Time           `json:"triggeredAt"`
+}
+
 // 系统设置（键值对字典表）
 type SystemSetting struct {
 	Key       string    `json:"key"`
@@ -368,8 +1234,41 @@ type SystemSetting struct {
 
 // 系统设置 Key 常量
 const (
-	SettingKeyProxyPort             = "proxy_port"              // 代理服务器端口，默认 9880
-	SettingKeyRequestRetentionHours = "request_retention_hours" // 请求记录保留小时数，默认 168 小时（7天），0 表示不清理
+	SettingKeyProxyPort                = "proxy_port"                  // 代理服务器端口，默认 9880
+	SettingKeyRequestRetentionHours    = "request_retention_hours"     // 请求记录保留小时数，默认 168 小时（7天），0 表示不清理
+	SettingKeyStreamReadBufferKB       = "stream_read_buffer_kb"       // 流式响应读取缓冲区大小（KB），默认 32
+	SettingKeyStreamFlushIntervalMs    = "stream_flush_interval_ms"    // 流式响应合并刷新间隔（毫秒），默认 0 表示每个事件结束即刷新
+	SettingKeyStreamPassthrough        = "stream_passthrough"          // 无需格式转换的流式响应直接透传（不记录响应体），默认 false
+	SettingKeyModelFallbackChains      = "model_fallback_chains"       // 模型降级链，每行一条，如 "gemini-3-pro -> gemini-2.5-pro -> claude-sonnet-4"
+	SettingKeyContextGuardMode         = "context_guard_mode"          // 上下文窗口检查：空=关闭, reject=拒绝, drop_oldest=丢弃最早的对话轮次
+	SettingKeyModelContextWindows      = "model_context_windows"       // 自定义模型上下文窗口，每行一条，如 "my-model-*: 128000"
+	SettingKeyHistorySummaryThreshold  = "history_summary_threshold"   // 对话历史超过该估算 token 数时压缩为摘要，默认 0 表示关闭
+	SettingKeyHistorySummaryModel      = "history_summary_model"       // 生成摘要使用的模型（按路由映射），为空时关闭
+	SettingKeyHistorySummaryKeepTurns  = "history_summary_keep_turns"  // 摘要时保留的最近对话轮次数，默认 4
+	SettingKeyModelMappingLearning     = "model_mapping_learning"      // 模型映射学习模式：记录每次映射的成败并生成映射建议，默认 false
+	SettingKeyAuditLog                 = "audit_log"                   // 审计日志：将请求、模型映射和工具调用事件写入数据目录下的 audit.jsonl，默认 false
+	SettingKeyFailbackProbeInterval    = "failback_probe_interval"     // 故障切换后探测首选 Provider 的间隔（秒），默认 0 表示关闭自动切回
+	SettingKeyFailbackSuccessThreshold = "failback_success_threshold"  // 连续探测成功多少次后切回首选 Provider，默认 3
+	SettingKeyBackgroundProvider       = "background_provider"         // Claude 后台小模型请求（话题检测、摘要等）使用的 Provider（名称或 ID），为空时关闭
+	SettingKeyBackgroundModelPatterns  = "background_model_patterns"   // 后台请求的模型通配符，逗号或换行分隔，默认 "*haiku*"
+	SettingKeyBackgroundMaxTokens      = "background_max_tokens"       // max_tokens 不超过该值才视为后台请求，默认 1024
+	SettingKeyBackgroundLocalResponder = "background_local_responder"  // 未绑定项目的后台请求由本地应答器直接返回（已绑定项目的请求按项目设置），默认 false
+	SettingKeyBackgroundLocalMaxInput  = "background_local_max_input"  // 估算输入 token 数不超过该值的后台请求才由本地应答，默认 2048
+	SettingKeyBackgroundLocalResponses = "background_local_responses"  // 本地应答内容，每行 "关键词 => 回复"，按顺序匹配 system 和消息文本（不区分大小写），"*" 匹配任意请求；未匹配的请求照常转发
+	SettingKeyResponseCaptureMaxMB     = "response_capture_max_mb"     // 响应体在内存中最多保留的大小（MB），超出部分写入临时文件，记录中只保留开头，默认 8，0 表示不限制
+	SettingKeyAgentLoopThreshold       = "agent_loop_threshold"        // 同一会话在时间窗口内重复相似请求（相同模型和最后一条消息）达到该次数时视为代理循环，默认 0 表示关闭
+	SettingKeyAgentLoopWindowSecs      = "agent_loop_window_secs"      // 代理循环检测的时间窗口（秒），默认 60
+	SettingKeyAgentLoopAction          = "agent_loop_action"           // 检测到代理循环后的处理：空=仅告警并标记请求, throttle=以 429 拒绝该会话的重复请求直到窗口过去
+	SettingKeyRequestValidation        = "request_validation"          // 路由前按客户端类型校验请求体，不合法时直接返回带字段路径的 400，默认 true，设为 false 关闭
+	SettingKeyActiveRoutingProfile     = "active_routing_profile"      // 最近一次应用的路由方案 ID（手动或按时间表），为空表示未应用
+	SettingKeyStatusPage               = "status_page"                 // 公开只读状态页 /status（Provider 健康状态和聚合吞吐量），默认关闭，设为 true 开启
+	SettingKeySessionIdleHours         = "session_idle_hours"          // 会话空闲超过该小时数后自动处理，默认 0 表示关闭
+	SettingKeySessionIdleAction        = "session_idle_action"         // 空闲会话的处理方式：unbind=解除项目绑定和固定路由（默认）, archive=解除绑定并归档
+	SettingKeyRouterDecisionSampleRate = "router_decision_sample_rate" // 记录路由决策过程的请求比例 0-1，默认 0 表示不记录
+	SettingKeyUncensoredFallback       = "uncensored_fallback"         // 非流式响应被上游内容过滤拦截时改用的 Provider（名称或 ID），为空时关闭
+	SettingKeyQualitySampleRates       = "quality_sample_rates"        // 按 Provider 完整保存请求/响应用于质量评估的百分比，每行一条，如 "my-provider: 5"，"*" 表示其他 Provider，默认关闭
+	SettingKeyCostAlertWebhookURL      = "cost_alert_webhook_url"      // 费用告警和每日摘要以 JSON POST 到该地址，为空时只推送到控制台
+	SettingKeyLanguage                 = "language"                    // 界面、返回给客户端的错误信息和 OAuth 页面使用的语言：en, zh；为空时按请求的 Accept-Language
 )
 
 // Antigravity 模型配额
@@ -410,6 +1309,92 @@ type AntigravityQuota struct {
 	Models []AntigravityModelQuota `json:"models"`
 }
 
+// ProviderQuotaStatus Provider 本地配额的当前用量
+type ProviderQuotaStatus struct {
+	ProviderID     uint64    `json:"providerID"`
+	ProviderName   string    `json:"providerName"`
+	RequestsPerDay uint64    `json:"requestsPerDay"`
+	TokensPerDay   uint64    `json:"tokensPerDay"`
+	Requests       uint64    `json:"requests"`
+	Tokens         uint64    `json:"tokens"`
+	WindowStart    time.Time `json:"windowStart"`
+	ResetAt        time.Time `json:"resetAt"`
+	Exhausted      bool      `json:"exhausted"`
+}
+
+// FailbackStatus 故障切换后首选 Provider 的恢复状态
+// Demoted 为 true 时该 Provider 的路由排在其他路由之后，连续探测成功 RequiredSuccesses 次后切回
+type FailbackStatus struct {
+	ProviderID           uint64     `json:"providerID"`
+	ProviderName         string     `json:"providerName"`
+	Demoted              bool       `json:"demoted"`
+	DemotedAt            time.Time  `json:"demotedAt"`
+	ConsecutiveSuccesses int        `json:"consecutiveSuccesses"`
+	RequiredSuccesses    int        `json:"requiredSuccesses"` // 切回后很快再次失败时翻倍，防止来回切换
+	LastProbeAt          *time.Time `json:"lastProbeAt,omitempty"`
+	LastError            string     `json:"lastError,omitempty"`
+	RecoveredAt          *time.Time `json:"recoveredAt,omitempty"` // 最近一次切回时间
+}
+
+// 公开状态页的整体状态
+const (
+	PublicStatusOperational = "operational" // 所有 Provider 可用
+	PublicStatusDegraded    = "degraded"    // 部分 Provider 冷却中或正在恢复
+	PublicStatusDown        = "down"        // 所有 Provider 都在冷却中
+	PublicStatusUnknown     = "unknown"     // 没有配置 Provider
+)
+
+// 公开状态页中 Provider 的状态
+const (
+	PublicProviderActive     = "active"     // 可用
+	PublicProviderCooldown   = "cooldown"   // 冷却中
+	PublicProviderRecovering = "recovering" // 冷却已结束，等待探测成功后切回
+)
+
+// PublicStatus 只读公开状态页的数据，可分享给共用实例的同事
+// 只包含 Provider 健康状态和聚合吞吐量，不包含任何请求内容、凭证或项目信息
+type PublicStatus struct {
+	Status      string                  `json:"status"`
+	GeneratedAt time.Time               `json:"generatedAt"`
+	Providers   []*PublicProviderStatus `json:"providers"`
+	LastHour    PublicThroughput        `json:"lastHour"`
+	Last24h     PublicThroughput        `json:"last24h"`
+}
+
+// PublicProviderStatus 单个 Provider 的公开状态
+type PublicProviderStatus struct {
+	Name           string         `json:"name"`
+	Type           string         `json:"type"`
+	Status         string         `json:"status"`
+	CooldownUntil  *time.Time     `json:"cooldownUntil,omitempty"`  // 最晚结束的冷却
+	CooldownReason CooldownReason `json:"cooldownReason,omitempty"` // 最晚结束的冷却的原因
+	Requests24h    uint64         `json:"requests24h"`
+	SuccessRate24h float64        `json:"successRate24h"` // 0-100，没有请求时为 0
+}
+
+// PublicThroughput 一段时间内的聚合吞吐量
+type PublicThroughput struct {
+	Requests          uint64  `json:"requests"`
+	SuccessRate       float64 `json:"successRate"` // 0-100，没有请求时为 0
+	RequestsPerMinute float64 `json:"requestsPerMinute"`
+	InputTokens       uint64  `json:"inputTokens"`
+	OutputTokens      uint64  `json:"outputTokens"`
+}
+
+// AttemptProgress 流式上游请求的实时进度，在请求进行中定期广播
+type AttemptProgress struct {
+	ProxyRequestID  uint64 `json:"proxyRequestID"`
+	AttemptID       uint64 `json:"attemptID"`
+	ProviderID      uint64 `json:"providerID"`
+	Model           string `json:"model"`
+	ElapsedMs       int64  `json:"elapsedMs"`
+	InputTokens     uint64 `json:"inputTokens"` // 上游目前报告的用量
+	OutputTokens    uint64 `json:"outputTokens"`
+	CacheReadCount  uint64 `json:"cacheReadCount"`
+	CacheWriteCount uint64 `json:"cacheWriteCount"`
+	EstimatedCost   uint64 `json:"estimatedCost"` // 按目前用量估算的成本（微美元）
+}
+
 // Provider 统计信息
 type ProviderStats struct {
 	ProviderID uint64 `json:"providerID"`
@@ -419,6 +1404,7 @@ type ProviderStats struct {
 	SuccessfulRequests uint64  `json:"successfulRequests"`
 	FailedRequests    uint64  `json:"failedRequests"`
 	SuccessRate       float64 `json:"successRate"` // 0-100
+	AvgDurationMs     uint64  `json:"avgDurationMs"` // 平均请求耗时（毫秒）
 
 	// 活动请求（正在处理中）
 	ActiveRequests uint64 `json:"activeRequests"`
@@ -433,6 +1419,119 @@ type ProviderStats struct {
 	TotalCost uint64 `json:"totalCost"`
 }
 
+// RequestShapeStats 某个请求模型在某个供应商上的请求形态统计
+// 用于了解哪些负载在产生成本、哪些供应商接收的 prompt 最大
+type RequestShapeStats struct {
+	Model      string `json:"model"`
+	ProviderID uint64 `json:"providerID"`
+
+	TotalRequests uint64 `json:"totalRequests"`
+
+	// 请求体大小（字节）
+	TotalPromptBytes uint64 `json:"totalPromptBytes"`
+	AvgPromptBytes   uint64 `json:"avgPromptBytes"`
+	MaxPromptBytes   uint64 `json:"maxPromptBytes"`
+
+	// 消息数
+	TotalMessages uint64  `json:"totalMessages"`
+	AvgMessages   float64 `json:"avgMessages"`
+	MaxMessages   uint64  `json:"maxMessages"`
+
+	// 工具定义数，及带工具定义的请求数
+	TotalTools   uint64  `json:"totalTools"`
+	AvgTools     float64 `json:"avgTools"`
+	ToolRequests uint64  `json:"toolRequests"`
+
+	// 含图片的请求数
+	ImageRequests uint64 `json:"imageRequests"`
+
+	InputTokens uint64 `json:"inputTokens"`
+	// 成本 (微美元)
+	TotalCost uint64 `json:"totalCost"`
+}
+
+// ExperimentArmStats 路由实验某一组的对比指标
+type ExperimentArmStats struct {
+	Experiment string `json:"experiment"`
+	Arm        string `json:"arm"`
+
+	TotalRequests      uint64  `json:"totalRequests"`
+	SuccessfulRequests uint64  `json:"successfulRequests"`
+	FailedRequests     uint64  `json:"failedRequests"`
+	ErrorRate          float64 `json:"errorRate"` // 0-1
+
+	// 成功请求的平均耗时（毫秒）
+	AvgDurationMs float64 `json:"avgDurationMs"`
+
+	InputTokens  uint64 `json:"inputTokens"`
+	OutputTokens uint64 `json:"outputTokens"`
+	// 成本 (微美元)
+	TotalCost uint64 `json:"totalCost"`
+	AvgCost   uint64 `json:"avgCost"`
+
+	// 成功请求的耗时总和（纳秒），用于计算平均值
+	TotalDuration uint64 `json:"-"`
+}
+
+// PromptPrefixStats 某个路由上使用相同可缓存前缀（system + tools）的 Claude 请求汇总
+type PromptPrefixStats struct {
+	RouteID     uint64 `json:"routeID"`
+	ProviderID  uint64 `json:"providerID"`
+	Model       string `json:"model"`
+	PrefixHash  string `json:"prefixHash"`
+	PrefixBytes uint64 `json:"prefixBytes"`
+
+	Requests             uint64 `json:"requests"`
+	CacheControlRequests uint64 `json:"cacheControlRequests"`
+	StrippedRequests     uint64 `json:"strippedRequests"`
+
+	InputTokens      uint64 `json:"inputTokens"`
+	CacheReadTokens  uint64 `json:"cacheReadTokens"`
+	CacheWriteTokens uint64 `json:"cacheWriteTokens"`
+}
+
+// PromptCacheRecommendation prompt 缓存建议
+type PromptCacheRecommendation string
+
+const (
+	// cache_control 因格式转换或供应商改写被丢弃：应路由到原生接收 Claude 请求的供应商
+	PromptCacheRecommendNativeProvider PromptCacheRecommendation = "native_provider"
+	// 前缀重复但客户端未标记 cache_control：应在客户端开启 prompt 缓存
+	PromptCacheRecommendClientCacheControl PromptCacheRecommendation = "client_cache_control"
+	// 已转发 cache_control 但几乎没有缓存命中：上游可能不支持 prompt 缓存
+	PromptCacheRecommendProviderNoCache PromptCacheRecommendation = "provider_no_cache"
+	// 大部分前缀只出现一次：system prompt 或工具定义在请求间变化，无法复用缓存
+	PromptCacheRecommendStablePrefix PromptCacheRecommendation = "stable_prefix"
+)
+
+// PromptCacheAdvice 某个路由的 prompt 缓存分析
+// token 数按前缀字节数估算（约 4 字节/token），节省金额按请求模型的输入价格与缓存读取价格之差估算
+type PromptCacheAdvice struct {
+	RouteID    uint64 `json:"routeID"`
+	ProviderID uint64 `json:"providerID"`
+
+	// 带 system prompt 或工具定义的 Claude 请求数
+	Requests         uint64 `json:"requests"`
+	DistinctPrefixes uint64 `json:"distinctPrefixes"`
+	// 前缀与窗口内之前的请求相同、且长度达到可缓存下限的请求数
+	RepeatedRequests uint64 `json:"repeatedRequests"`
+	AvgPrefixTokens  uint64 `json:"avgPrefixTokens"`
+
+	CacheControlRequests uint64 `json:"cacheControlRequests"`
+	StrippedRequests     uint64 `json:"strippedRequests"`
+
+	// 重复请求中可以从缓存读取的前缀 tokens（估算）
+	CacheableTokens  uint64 `json:"cacheableTokens"`
+	CacheReadTokens  uint64 `json:"cacheReadTokens"`
+	CacheWriteTokens uint64 `json:"cacheWriteTokens"`
+	// 可缓存但未从缓存读取的 tokens
+	MissedTokens uint64 `json:"missedTokens"`
+	// 未命中部分如果命中缓存可节省的成本 (微美元)
+	EstimatedSavings uint64 `json:"estimatedSavings"`
+
+	Recommendations []PromptCacheRecommendation `json:"recommendations"`
+}
+
 // Granularity 统计数据的时间粒度
 type Granularity string
 
@@ -490,6 +1589,57 @@ type UsageStatsSummary struct {
 	TotalCost          uint64  `json:"totalCost"`
 }
 
+// DashboardRange 仪表盘时间序列的时间范围
+type DashboardRange string
+
+const (
+	DashboardRange24h DashboardRange = "24h" // 最近 24 小时，按小时分桶
+	DashboardRange7d  DashboardRange = "7d"  // 最近 7 天，按天分桶
+	DashboardRange30d DashboardRange = "30d" // 最近 30 天，按天分桶
+)
+
+// DashboardGroupBy 仪表盘时间序列的分组维度
+type DashboardGroupBy string
+
+const (
+	DashboardGroupByNone     DashboardGroupBy = ""         // 只返回总计
+	DashboardGroupByProvider DashboardGroupBy = "provider" // 按 Provider 分组
+	DashboardGroupByProject  DashboardGroupBy = "project"  // 按项目分组
+)
+
+// TimeSeriesPoint 时间序列中的一个时间桶
+type TimeSeriesPoint struct {
+	TimeBucket         time.Time `json:"timeBucket"`
+	TotalRequests      uint64    `json:"totalRequests"`
+	SuccessfulRequests uint64    `json:"successfulRequests"`
+	FailedRequests     uint64    `json:"failedRequests"`
+	ErrorRate          float64   `json:"errorRate"` // 0-100
+	InputTokens        uint64    `json:"inputTokens"`
+	OutputTokens       uint64    `json:"outputTokens"`
+	CacheRead          uint64    `json:"cacheRead"`
+	CacheWrite         uint64    `json:"cacheWrite"`
+	Cost               uint64    `json:"cost"` // 微美元
+}
+
+// TimeSeries 某个维度（Provider/项目）的完整时间序列
+// 时间桶是连续的，没有数据的桶以 0 填充，前端无需再补齐
+type TimeSeries struct {
+	ID     uint64             `json:"id"` // Provider ID 或项目 ID，总计序列为 0
+	Name   string             `json:"name"`
+	Points []*TimeSeriesPoint `json:"points"`
+}
+
+// DashboardTimeSeries 仪表盘图表数据
+type DashboardTimeSeries struct {
+	Range       DashboardRange   `json:"range"`
+	Granularity Granularity      `json:"granularity"`
+	GroupBy     DashboardGroupBy `json:"groupBy"`
+	StartTime   time.Time        `json:"startTime"`
+	EndTime     time.Time        `json:"endTime"`
+	Total       *TimeSeries      `json:"total"`
+	Series      []*TimeSeries    `json:"series"`
+}
+
 // APIToken API 访问令牌
 type APIToken struct {
 	ID        uint64    `json:"id"`
@@ -509,6 +1659,9 @@ type APIToken struct {
 	// 关联的项目 ID，0 表示使用全局路由
 	ProjectID uint64 `json:"projectID"`
 
+	// 允许使用的客户端类型，空表示不限制
+	AllowedClientTypes []ClientType `json:"allowedClientTypes,omitempty"`
+
 	// 是否启用
 	IsEnabled bool `json:"isEnabled"`
 
@@ -525,6 +1678,19 @@ type APIToken struct {
 	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
 
+// AllowsClientType 检查 Token 是否允许用于该客户端类型
+func (t *APIToken) AllowsClientType(clientType ClientType) bool {
+	if len(t.AllowedClientTypes) == 0 {
+		return true
+	}
+	for _, ct := range t.AllowedClientTypes {
+		if ct == clientType {
+			return true
+		}
+	}
+	return false
+}
+
 // APITokenCreateResult 创建 Token 的返回结果（包含明文 Token，仅返回一次）
 type APITokenCreateResult struct {
 	Token    string    `json:"token"`    // 明文 Token（仅创建时返回）
@@ -588,6 +1754,81 @@ type ModelMappingQuery struct {
 	APITokenID   uint64
 }
 
+// ModelMappingOutcome 学习模式下记录的映射结果（请求模型 -> 映射模型，按供应商汇总）
+type ModelMappingOutcome struct {
+	ProviderID   uint64    `json:"providerID"`
+	ProviderName string    `json:"providerName"`
+	RequestModel string    `json:"requestModel"`
+	MappedModel  string    `json:"mappedModel"`
+	Attempts     uint64    `json:"attempts"`
+	Failures     uint64    `json:"failures"`
+	FailureRate  float64   `json:"failureRate"` // 0-1
+	LastSeen     time.Time `json:"lastSeen"`
+}
+
+// ModelMappingSuggestion 根据映射结果生成的映射规则建议
+type ModelMappingSuggestion struct {
+	ProviderID   uint64 `json:"providerID"`
+	ProviderName string `json:"providerName"`
+
+	// 建议规则的源模式（日期后缀替换为 *）
+	Pattern string `json:"pattern"`
+	// 当前映射到的模型及其失败情况
+	CurrentTarget string  `json:"currentTarget"`
+	Attempts      uint64  `json:"attempts"`
+	Failures      uint64  `json:"failures"`
+	FailureRate   float64 `json:"failureRate"`
+
+	// 同一供应商上表现更好的目标模型，没有时为空
+	SuggestedTarget            string  `json:"suggestedTarget,omitempty"`
+	SuggestedTargetFailureRate float64 `json:"suggestedTargetFailureRate,omitempty"`
+
+	// 能成功处理该模式的其他供应商（没有更好的目标模型时给出）
+	AlternativeProviderID   uint64 `json:"alternativeProviderID,omitempty"`
+	AlternativeProviderName string `json:"alternativeProviderName,omitempty"`
+
+	Reason string `json:"reason"`
+}
+
+// ModelCapability 模型能力规则：声明某个（供应商的）模型支持哪些功能
+// 路由、转换器和请求校验都通过 capability 注册表查询，不再各自按模型名猜测
+// 未设置的字段（nil / 0）表示“未知”，由优先级更低的规则或内置默认值决定
+type ModelCapability struct {
+	ID        uint64    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// 软删除时间
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+
+	// 供应商 ID，0 表示所有供应商；指定供应商的规则优先于全局规则
+	ProviderID uint64 `json:"providerID,omitempty"`
+
+	// 模型模式（上游模型名，即映射后的模型），支持通配符 *
+	Pattern string `json:"pattern"`
+
+	SupportsTools     *bool `json:"supportsTools,omitempty"`     // 工具调用
+	SupportsVision    *bool `json:"supportsVision,omitempty"`    // 图片输入
+	SupportsThinking  *bool `json:"supportsThinking,omitempty"`  // 思考 / 推理
+	SupportsWebSearch *bool `json:"supportsWebSearch,omitempty"` // 联网搜索
+
+	MaxContext int `json:"maxContext,omitempty"` // 上下文窗口（token），0 表示未知
+	MaxOutput  int `json:"maxOutput,omitempty"`  // 最大输出（token），0 表示未知
+
+	// 优先级，数字越小优先级越高
+	Priority int `json:"priority"`
+}
+
+// ModelCapabilities 对某个供应商模型合并所有匹配规则后的能力
+type ModelCapabilities struct {
+	SupportsTools     *bool `json:"supportsTools,omitempty"`
+	SupportsVision    *bool `json:"supportsVision,omitempty"`
+	SupportsThinking  *bool `json:"supportsThinking,omitempty"`
+	SupportsWebSearch *bool `json:"supportsWebSearch,omitempty"`
+	MaxContext        int   `json:"maxContext,omitempty"`
+	MaxOutput         int   `json:"maxOutput,omitempty"`
+}
+
 // ResponseModel 记录所有出现过的 response model
 // 用于快速查询可选的模型列表，避免每次 DISTINCT 查询
 type ResponseModel struct {
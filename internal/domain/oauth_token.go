@@ -0,0 +1,14 @@
+package domain
+
+import "time"
+
+// OAuthAccessToken 按 refresh token 共享的 access token 缓存
+// 多个 Provider 使用同一账号时复用同一个 access token，避免各自刷新导致 refresh token 失效
+type OAuthAccessToken struct {
+	ID          uint64    `json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	TokenKey    string    `json:"tokenKey"` // refresh token 的 SHA-256 摘要，不保存 refresh token 本身
+	AccessToken string    `json:"-"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,55 @@
+package event
+
+import "sync"
+
+// 配置资源名，与 admin API 的资源路径一致
+const (
+	ConfigProviders         = "providers"
+	ConfigRoutes            = "routes"
+	ConfigProjects          = "projects"
+	ConfigRetryConfigs      = "retry-configs"
+	ConfigRoutingStrategies = "routing-strategies"
+	ConfigModelMappings     = "model-mappings"
+	ConfigSettings          = "settings"
+)
+
+// ConfigChange 配置变更事件
+type ConfigChange struct {
+	Resource string // 变更的资源，见 Config* 常量；admin API 的写操作为其资源路径
+}
+
+// ConfigBus 配置变更事件总线
+// 发布是同步的，订阅者应尽快返回
+type ConfigBus struct {
+	mu          sync.RWMutex
+	subscribers []func(ConfigChange)
+}
+
+var defaultConfigBus = &ConfigBus{}
+
+// DefaultConfigBus 返回进程内共享的配置变更事件总线
+func DefaultConfigBus() *ConfigBus {
+	return defaultConfigBus
+}
+
+// Subscribe 订阅配置变更
+func (b *ConfigBus) Subscribe(fn func(ConfigChange)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, fn)
+}
+
+// Publish 通知所有订阅者配置已变更
+func (b *ConfigBus) Publish(change ConfigChange) {
+	b.mu.RLock()
+	subscribers := b.subscribers
+	b.mu.RUnlock()
+	for _, fn := range subscribers {
+		fn(change)
+	}
+}
+
+// PublishConfigChange 在默认总线上发布 resource 的变更
+func PublishConfigChange(resource string) {
+	defaultConfigBus.Publish(ConfigChange{Resource: resource})
+}
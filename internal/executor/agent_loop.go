@@ -0,0 +1,96 @@
+package executor
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/loopdetect"
+)
+
+// AgentLoopMessageType is the broadcast message type of agent loop warnings
+const AgentLoopMessageType = "agent_loop_detected"
+
+// agentLoopActionThrottle rejects the repeated requests of a looping session
+const agentLoopActionThrottle = "throttle"
+
+const defaultAgentLoopWindow = 60 * time.Second
+
+// AgentLoopWarning is broadcast when a session starts looping
+type AgentLoopWarning struct {
+	SessionID      string            `json:"sessionID"`
+	ProxyRequestID uint64            `json:"proxyRequestID"`
+	ClientType     domain.ClientType `json:"clientType"`
+	Model          string            `json:"model"`
+	Count          int               `json:"count"`
+	WindowSeconds  int               `json:"windowSeconds"`
+	Throttled      bool              `json:"throttled"`
+}
+
+func agentLoopConfig() loopdetect.Config {
+	cfg := loopdetect.Config{Window: defaultAgentLoopWindow}
+	if n, err := strconv.Atoi(getSetting(domain.SettingKeyAgentLoopThreshold)); err == nil {
+		cfg.Threshold = n
+	}
+	if n, err := strconv.Atoi(getSetting(domain.SettingKeyAgentLoopWindowSecs)); err == nil && n > 0 {
+		cfg.Window = time.Duration(n) * time.Second
+	}
+	return cfg
+}
+
+// checkAgentLoop records the request with the loop detector and tags it if its session
+// keeps sending the same request. The first looping request is broadcast as a warning;
+// with the throttle action, looping requests are rejected with a 429 in the client's
+// format until the session stops repeating for a window.
+func (e *Executor) checkAgentLoop(proxyReq *domain.ProxyRequest, body []byte) *domain.ProxyError {
+	cfg := agentLoopConfig()
+	if !cfg.Enabled() || proxyReq.SessionID == "" {
+		return nil
+	}
+	fingerprint, ok := loopdetect.Fingerprint(proxyReq.RequestModel, body)
+	if !ok {
+		return nil
+	}
+	result := loopdetect.Default().Observe(proxyReq.SessionID, fingerprint, cfg)
+	if !result.Looping {
+		return nil
+	}
+
+	proxyReq.AgentLoop = true
+	throttle := getSetting(domain.SettingKeyAgentLoopAction) == agentLoopActionThrottle
+	if result.NewLoop {
+		log.Printf("[Executor] Agent loop detected: session %s sent %d similar %s requests within %s",
+			proxyReq.SessionID, result.Count, proxyReq.RequestModel, cfg.Window)
+		if e.broadcaster != nil {
+			e.broadcaster.BroadcastMessage(AgentLoopMessageType, &AgentLoopWarning{
+				SessionID:      proxyReq.SessionID,
+				ProxyRequestID: proxyReq.ID,
+				ClientType:     proxyReq.ClientType,
+				Model:          proxyReq.RequestModel,
+				Count:          result.Count,
+				WindowSeconds:  int(cfg.Window.Seconds()),
+				Throttled:      throttle,
+			})
+		}
+	}
+	if !throttle {
+		return nil
+	}
+
+	err := fmt.Errorf("session repeated the same request %d times within %s; throttled as an agent loop", result.Count, cfg.Window)
+	proxyErr := domain.NewProxyErrorWithMessage(err, false, "agent loop throttled")
+	proxyErr.Code = domain.ErrorCodeRateLimited
+	proxyErr.HTTPStatusCode = http.StatusTooManyRequests
+	proxyErr.RetryAfter = time.Until(result.Until)
+	proxyErr.ResponseBody = converter.ErrorBody(proxyReq.ClientType, &converter.APIError{
+		Status:  http.StatusTooManyRequests,
+		Type:    converter.ErrorTypeRateLimit,
+		Message: err.Error(),
+	})
+	proxyErr.ResponseFormat = proxyReq.ClientType
+	return proxyErr
+}
@@ -0,0 +1,74 @@
+package executor
+
+import (
+	"github.com/awsl-project/maxx/internal/audit"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// auditEnabled reports whether audit events are written (see the audit_log setting)
+func auditEnabled() bool {
+	return getSetting(domain.SettingKeyAuditLog) == "true"
+}
+
+// auditRequestStarted records a newly received request
+func auditRequestStarted(req *domain.ProxyRequest) {
+	if !auditEnabled() {
+		return
+	}
+	e := auditEvent(audit.EventRequestStarted, req)
+	e.Timestamp = req.StartTime
+	audit.Default().Emit(e)
+}
+
+// auditModelMapped records a request model mapped to a different upstream model
+func auditModelMapped(req *domain.ProxyRequest, provider *domain.Provider, mappedModel string) {
+	if mappedModel == req.RequestModel || !auditEnabled() {
+		return
+	}
+	e := auditEvent(audit.EventModelMapped, req)
+	e.ProviderID = provider.ID
+	e.MappedModel = mappedModel
+	audit.Default().Emit(e)
+}
+
+// auditRequestCompleted records the final state of a request, preceded by the tool calls
+// in its response
+func auditRequestCompleted(req *domain.ProxyRequest) {
+	if !auditEnabled() {
+		return
+	}
+	if req.Status == "COMPLETED" && req.ResponseInfo != nil {
+		for _, use := range audit.ExtractToolUses(req.ResponseInfo.Body) {
+			e := auditEvent(audit.EventToolInvoked, req)
+			e.ProviderID = req.ProviderID
+			e.MappedModel = req.ResponseModel
+			e.ToolName = use.Name
+			e.ToolUseID = use.ID
+			e.ToolInput = use.Input
+			audit.Default().Emit(e)
+		}
+	}
+
+	e := auditEvent(audit.EventRequestCompleted, req)
+	e.ProviderID = req.ProviderID
+	e.MappedModel = req.ResponseModel
+	e.Status = req.Status
+	e.StatusCode = req.StatusCode
+	e.DurationMs = req.Duration.Milliseconds()
+	e.InputTokens = req.InputTokenCount
+	e.OutputTokens = req.OutputTokenCount
+	e.Error = req.Error
+	audit.Default().Emit(e)
+}
+
+func auditEvent(name string, req *domain.ProxyRequest) *audit.Event {
+	return &audit.Event{
+		HookEventName: name,
+		SessionID:     req.SessionID,
+		RequestID:     req.RequestID,
+		ClientType:    string(req.ClientType),
+		ProjectID:     req.ProjectID,
+		APITokenID:    req.APITokenID,
+		Model:         req.RequestModel,
+	}
+}
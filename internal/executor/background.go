@@ -0,0 +1,66 @@
+package executor
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+const (
+	defaultBackgroundModelPatterns = "*haiku*"
+	defaultBackgroundMaxTokens     = 1024
+)
+
+// backgroundProvider returns the provider (name or ID) that Claude background requests go
+// to, or "" if the request is not one or no background provider is configured.
+//
+// Claude Code sends frequent small requests to its "small fast model" (topic detection,
+// titles, summaries). They are recognised by the model matching one of the background
+// patterns and max_tokens not exceeding the background limit.
+func backgroundProvider(clientType domain.ClientType, requestModel string, body []byte) string {
+	provider := strings.TrimSpace(getSetting(domain.SettingKeyBackgroundProvider))
+	if provider == "" || !isBackgroundRequest(clientType, requestModel, body) {
+		return ""
+	}
+	return provider
+}
+
+// isBackgroundRequest reports whether a request is a Claude background request
+func isBackgroundRequest(clientType domain.ClientType, requestModel string, body []byte) bool {
+	if clientType != domain.ClientTypeClaude || requestModel == "" {
+		return false
+	}
+	if !matchesBackgroundModel(requestModel) {
+		return false
+	}
+
+	var req struct {
+		MaxTokens int `json:"max_tokens"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil || req.MaxTokens <= 0 {
+		return false
+	}
+	return req.MaxTokens <= backgroundMaxTokens()
+}
+
+func matchesBackgroundModel(model string) bool {
+	patterns := getSetting(domain.SettingKeyBackgroundModelPatterns)
+	if strings.TrimSpace(patterns) == "" {
+		patterns = defaultBackgroundModelPatterns
+	}
+	for _, pattern := range strings.FieldsFunc(patterns, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if pattern = strings.TrimSpace(pattern); pattern != "" && domain.MatchWildcard(pattern, model) {
+			return true
+		}
+	}
+	return false
+}
+
+func backgroundMaxTokens() int {
+	if n, err := strconv.Atoi(getSetting(domain.SettingKeyBackgroundMaxTokens)); err == nil && n > 0 {
+		return n
+	}
+	return defaultBackgroundMaxTokens
+}
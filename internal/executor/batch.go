@@ -0,0 +1,369 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+const (
+	// maxBatchRuns caps the requests (prompts x providers) of one batch run
+	maxBatchRuns = 200
+	// defaultBatchConcurrency and maxBatchConcurrency bound the requests of a batch run in flight
+	defaultBatchConcurrency = 4
+	maxBatchConcurrency     = 16
+	defaultBatchMaxTokens   = 1024
+)
+
+// BatchRunRequest asks for a set of prompts to be run through the normal routing of a
+// client type. With Providers, every prompt is run once on each provider (pinned as by
+// the X-Maxx-Provider header), to compare them on the same prompts.
+type BatchRunRequest struct {
+	Model       string            `json:"model"`
+	ClientType  domain.ClientType `json:"clientType,omitempty"` // claude (default) or openai
+	Prompts     []string          `json:"prompts"`
+	System      string            `json:"system,omitempty"`
+	MaxTokens   int               `json:"maxTokens,omitempty"`
+	Providers   []string          `json:"providers,omitempty"` // Provider names or IDs
+	Concurrency int               `json:"concurrency,omitempty"`
+	ProjectID   uint64            `json:"projectID,omitempty"`
+}
+
+// BatchRunItem is the result of one prompt on one provider
+type BatchRunItem struct {
+	Prompt         int     `json:"prompt"`                   // Index into the request's prompts
+	PinnedProvider string  `json:"pinnedProvider,omitempty"` // Requested provider, empty for normal routing
+	ProxyRequestID uint64  `json:"proxyRequestID,omitempty"`
+	ProviderID     uint64  `json:"providerID,omitempty"`
+	ResponseModel  string  `json:"responseModel,omitempty"`
+	Status         string  `json:"status"`
+	StatusCode     int     `json:"statusCode,omitempty"`
+	DurationMs     int64   `json:"durationMs"`
+	InputTokens    uint64  `json:"inputTokens"`
+	OutputTokens   uint64  `json:"outputTokens"`
+	Cost           uint64  `json:"cost"` // Micro-USD
+	Output         string  `json:"output,omitempty"`
+	Error          string  `json:"error,omitempty"`
+	ErrorCode      string  `json:"errorCode,omitempty"`
+	outputRate     float64 // Output tokens per second, for the provider summary
+}
+
+// BatchRunProviderSummary aggregates the items served by one provider
+type BatchRunProviderSummary struct {
+	ProviderID      uint64  `json:"providerID"`
+	Requests        int     `json:"requests"`
+	Succeeded       int     `json:"succeeded"`
+	Failed          int     `json:"failed"`
+	AvgDurationMs   int64   `json:"avgDurationMs"`
+	OutputPerSecond float64 `json:"outputPerSecond"` // Average output tokens per second of successful items
+	InputTokens     uint64  `json:"inputTokens"`
+	OutputTokens    uint64  `json:"outputTokens"`
+	Cost            uint64  `json:"cost"`
+}
+
+// BatchRunResult is the consolidated result of a batch run
+type BatchRunResult struct {
+	Model        string                     `json:"model"`
+	ClientType   domain.ClientType          `json:"clientType"`
+	Total        int                        `json:"total"`
+	Succeeded    int                        `json:"succeeded"`
+	Failed       int                        `json:"failed"`
+	DurationMs   int64                      `json:"durationMs"`
+	InputTokens  uint64                     `json:"inputTokens"`
+	OutputTokens uint64                     `json:"outputTokens"`
+	Cost         uint64                     `json:"cost"`
+	Providers    []*BatchRunProviderSummary `json:"providers"`
+	Items        []*BatchRunItem            `json:"items"`
+}
+
+// requestObserverKey carries a callback that receives the proxy request record Execute
+// creates, so internal callers can read its final state
+type requestObserverKey struct{}
+
+func observeRequest(ctx context.Context, proxyReq *domain.ProxyRequest) {
+	if observe, ok := ctx.Value(requestObserverKey{}).(func(*domain.ProxyRequest)); ok {
+		observe(proxyReq)
+	}
+}
+
+// RunBatch runs every prompt of req through Execute with bounded concurrency and returns
+// the results in request order. The requests are recorded like any other proxy request;
+// a failed item does not stop the others.
+func (e *Executor) RunBatch(ctx context.Context, req *BatchRunRequest) (*BatchRunResult, error) {
+	if err := e.validateBatchRun(req); err != nil {
+		return nil, err
+	}
+
+	targets := req.Providers
+	if len(targets) == 0 {
+		targets = []string{""}
+	}
+	items := make([]*BatchRunItem, 0, len(req.Prompts)*len(targets))
+	for i := range req.Prompts {
+		for _, provider := range targets {
+			items = append(items, &BatchRunItem{Prompt: i, PinnedProvider: provider})
+		}
+	}
+
+	start := time.Now()
+	sessionID := "batch-" + generateRequestID()
+	sem := make(chan struct{}, req.Concurrency)
+	var wg sync.WaitGroup
+	for _, item := range items {
+		wg.Add(1)
+		go func(item *BatchRunItem) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			e.runBatchItem(ctx, req, sessionID, item)
+		}(item)
+	}
+	wg.Wait()
+
+	result := summarizeBatchRun(req, items)
+	result.DurationMs = time.Since(start).Milliseconds()
+	log.Printf("[Executor] Batch run of %s: %d requests, %d succeeded, %d failed in %dms",
+		req.Model, result.Total, result.Succeeded, result.Failed, result.DurationMs)
+	return result, nil
+}
+
+// validateBatchRun checks req and fills in its defaults
+func (e *Executor) validateBatchRun(req *BatchRunRequest) error {
+	req.Model = strings.TrimSpace(req.Model)
+	if req.Model == "" {
+		return fmt.Errorf("%w: model is required", domain.ErrInvalidInput)
+	}
+	if len(req.Prompts) == 0 {
+		return fmt.Errorf("%w: prompts is required", domain.ErrInvalidInput)
+	}
+	switch req.ClientType {
+	case "":
+		req.ClientType = domain.ClientTypeClaude
+	case domain.ClientTypeClaude, domain.ClientTypeOpenAI:
+	default:
+		return fmt.Errorf("%w: clientType must be claude or openai", domain.ErrInvalidInput)
+	}
+	if n := len(req.Prompts) * max(len(req.Providers), 1); n > maxBatchRuns {
+		return fmt.Errorf("%w: %d requests exceed the limit of %d per batch", domain.ErrInvalidInput, n, maxBatchRuns)
+	}
+	if req.MaxTokens <= 0 {
+		req.MaxTokens = defaultBatchMaxTokens
+	}
+	if req.Concurrency <= 0 {
+		req.Concurrency = defaultBatchConcurrency
+	}
+	req.Concurrency = min(req.Concurrency, maxBatchConcurrency)
+	// A batch can't answer the project binding prompt, so it would wait until the timeout
+	if req.ProjectID == 0 && e.projectWaiter != nil && e.projectWaiter.IsForceProjectEnabled() {
+		return fmt.Errorf("%w: projectID is required while project binding is enforced", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+// runBatchItem executes one prompt as a non-streaming request of the batch's client type
+func (e *Executor) runBatchItem(ctx context.Context, req *BatchRunRequest, sessionID string, item *BatchRunItem) {
+	body, uri, err := batchRequestBody(req, req.Prompts[item.Prompt])
+	if err != nil {
+		item.Status = "FAILED"
+		item.Error = err.Error()
+		return
+	}
+
+	headers := make(http.Header)
+	headers.Set("Content-Type", "application/json")
+	if req.ClientType == domain.ClientTypeClaude {
+		headers.Set("anthropic-version", "2023-06-01")
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, uri, strings.NewReader(string(body)))
+	if err != nil {
+		item.Status = "FAILED"
+		item.Error = err.Error()
+		return
+	}
+	httpReq.Header = headers
+
+	var proxyReq *domain.ProxyRequest
+	runCtx := context.WithValue(ctx, requestObserverKey{}, func(pr *domain.ProxyRequest) { proxyReq = pr })
+	runCtx = ctxutil.WithClientType(runCtx, req.ClientType)
+	// Evaluation runs must not affect production routing (cooldowns, failback, SLOs)
+	runCtx = ctxutil.WithDiagnostic(runCtx, true)
+	runCtx = ctxutil.WithSessionID(runCtx, sessionID)
+	runCtx = ctxutil.WithProjectID(runCtx, req.ProjectID)
+	runCtx = ctxutil.WithRequestModel(runCtx, req.Model)
+	runCtx = ctxutil.WithRequestBody(runCtx, body)
+	runCtx = ctxutil.WithRequestHeaders(runCtx, headers)
+	runCtx = ctxutil.WithRequestURI(runCtx, uri)
+	runCtx = ctxutil.WithIsStream(runCtx, false)
+	if item.PinnedProvider != "" {
+		runCtx = ctxutil.WithPinnedProvider(runCtx, item.PinnedProvider)
+	}
+
+	buf := newBufferedResponseWriter()
+	execErr := e.Execute(runCtx, buf, httpReq)
+
+	if proxyReq != nil {
+		item.ProxyRequestID = proxyReq.ID
+		item.ProviderID = proxyReq.ProviderID
+		item.ResponseModel = proxyReq.ResponseModel
+		item.StatusCode = proxyReq.StatusCode
+		item.DurationMs = proxyReq.Duration.Milliseconds()
+		item.InputTokens = proxyReq.InputTokenCount
+		item.OutputTokens = proxyReq.OutputTokenCount
+		item.Cost = proxyReq.Cost
+		item.ErrorCode = string(proxyReq.ErrorCode)
+		item.Error = proxyReq.Error
+	}
+	if execErr != nil {
+		item.Status = "FAILED"
+		item.Error = execErr.Error()
+		item.ErrorCode = string(domain.ErrorCodeOf(execErr))
+		if proxyErr, ok := execErr.(*domain.ProxyError); ok && item.StatusCode == 0 {
+			item.StatusCode = proxyErr.HTTPStatusCode
+		}
+		return
+	}
+	if item.StatusCode == 0 {
+		item.StatusCode = buf.status
+	}
+	if buf.status >= http.StatusBadRequest {
+		item.Status = "FAILED"
+		if item.Error == "" {
+			item.Error = fmt.Sprintf("upstream returned status %d", buf.status)
+		}
+		return
+	}
+	item.Status = "COMPLETED"
+	item.Output = batchResponseText(req.ClientType, buf.body.Bytes())
+	if item.DurationMs > 0 {
+		item.outputRate = float64(item.OutputTokens) * 1000 / float64(item.DurationMs)
+	}
+}
+
+// batchRequestBody builds the request of one prompt and returns it with its URI
+func batchRequestBody(req *BatchRunRequest, prompt string) ([]byte, string, error) {
+	user := map[string]interface{}{"role": "user", "content": prompt}
+	if req.ClientType == domain.ClientTypeOpenAI {
+		var messages []interface{}
+		if req.System != "" {
+			messages = append(messages, map[string]interface{}{"role": "system", "content": req.System})
+		}
+		body, err := json.Marshal(map[string]interface{}{
+			"model":      req.Model,
+			"max_tokens": req.MaxTokens,
+			"stream":     false,
+			"messages":   append(messages, user),
+		})
+		return body, "/v1/chat/completions", err
+	}
+
+	payload := map[string]interface{}{
+		"model":      req.Model,
+		"max_tokens": req.MaxTokens,
+		"stream":     false,
+		"messages":   []interface{}{user},
+	}
+	if req.System != "" {
+		payload["system"] = req.System
+	}
+	body, err := json.Marshal(payload)
+	return body, "/v1/messages", err
+}
+
+// batchResponseText returns the generated text of a non-streaming response
+func batchResponseText(clientType domain.ClientType, body []byte) string {
+	if clientType == domain.ClientTypeOpenAI {
+		var resp struct {
+			Choices []struct {
+				Message struct {
+					Content string `json:"content"`
+				} `json:"message"`
+			} `json:"choices"`
+		}
+		if json.Unmarshal(body, &resp) != nil || len(resp.Choices) == 0 {
+			return ""
+		}
+		return resp.Choices[0].Message.Content
+	}
+
+	var resp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	return sb.String()
+}
+
+// summarizeBatchRun totals the items, overall and per provider (in order of first use)
+func summarizeBatchRun(req *BatchRunRequest, items []*BatchRunItem) *BatchRunResult {
+	result := &BatchRunResult{
+		Model:      req.Model,
+		ClientType: req.ClientType,
+		Total:      len(items),
+		Providers:  make([]*BatchRunProviderSummary, 0),
+		Items:      items,
+	}
+	byProvider := make(map[uint64]*BatchRunProviderSummary)
+	rated := make(map[uint64]int)
+	durations := make(map[uint64]int64)
+	for _, item := range items {
+		ok := item.Status == "COMPLETED"
+		if ok {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+		result.InputTokens += item.InputTokens
+		result.OutputTokens += item.OutputTokens
+		result.Cost += item.Cost
+		if item.ProviderID == 0 {
+			continue
+		}
+
+		s := byProvider[item.ProviderID]
+		if s == nil {
+			s = &BatchRunProviderSummary{ProviderID: item.ProviderID}
+			byProvider[item.ProviderID] = s
+			result.Providers = append(result.Providers, s)
+		}
+		s.Requests++
+		durations[item.ProviderID] += item.DurationMs
+		s.InputTokens += item.InputTokens
+		s.OutputTokens += item.OutputTokens
+		s.Cost += item.Cost
+		if !ok {
+			s.Failed++
+			continue
+		}
+		s.Succeeded++
+		if item.outputRate > 0 {
+			s.OutputPerSecond += item.outputRate
+			rated[item.ProviderID]++
+		}
+	}
+	for id, s := range byProvider {
+		s.AvgDurationMs = durations[id] / int64(s.Requests)
+		if n := rated[id]; n > 0 {
+			s.OutputPerSecond = math.Round(s.OutputPerSecond/float64(n)*100) / 100
+		}
+	}
+	return result
+}
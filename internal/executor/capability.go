@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/awsl-project/maxx/internal/capability"
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// checkCapabilities rejects a route whose model is known to lack something the request needs
+// (such as tools or images), instead of letting the upstream fail or silently drop it.
+// Unknown capabilities pass.
+func checkCapabilities(clientType domain.ClientType, body []byte, providerID uint64, model string) *domain.ProxyError {
+	missing := capability.RequirementsOf(body).Missing(capability.Default().Lookup(providerID, model))
+	if missing == "" {
+		return nil
+	}
+
+	err := fmt.Errorf("model %s does not support %s", model, missing)
+	proxyErr := domain.NewProxyErrorWithMessage(err, false, "model lacks a required capability")
+	proxyErr.Code = domain.ErrorCodeInvalidRequest
+	proxyErr.HTTPStatusCode = http.StatusBadRequest
+	proxyErr.ResponseBody = converter.ErrorBody(clientType, &converter.APIError{
+		Status:  http.StatusBadRequest,
+		Type:    converter.ErrorTypeInvalidRequest,
+		Message: err.Error(),
+	})
+	proxyErr.ResponseFormat = clientType
+	return proxyErr
+}
@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// RecordClientAbort records a request whose client disconnected before the body was
+// fully received. The request is stored as CANCELLED with no upstream attempts: it was
+// never routed, so no provider failure, cooldown or stat is affected.
+func (e *Executor) RecordClientAbort(req *http.Request, clientType domain.ClientType, apiTokenID uint64, reason string) {
+	now := e.clock.Now()
+	headers := flattenHeaders(req.Header)
+	if req.Host != "" {
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers["Host"] = req.Host
+	}
+	proxyReq := &domain.ProxyRequest{
+		InstanceID: e.instanceID,
+		RequestID:  generateRequestID(),
+		ClientType: clientType,
+		StartTime:  now,
+		EndTime:    now,
+		Status:     "CANCELLED",
+		Error:      reason,
+		ErrorCode:  domain.ErrorCodeClientAbort,
+		APITokenID: apiTokenID,
+		RequestInfo: &domain.RequestInfo{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: headers,
+		},
+	}
+	if err := e.proxyRequestRepo.Create(proxyReq); err != nil {
+		log.Printf("[Executor] Failed to record aborted request: %v", err)
+		return
+	}
+	if e.broadcaster != nil {
+		e.broadcaster.BroadcastProxyRequest(proxyReq)
+	}
+}
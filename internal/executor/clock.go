@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/cooldown"
+)
+
+// Clock is the executor's source of time, replaceable so retry backoff and
+// cancellation during waits can be tested without sleeping
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// CooldownTracker is the part of the cooldown manager the executor reports attempt outcomes to.
+// *cooldown.Manager implements it; tests can record the decisions instead.
+type CooldownTracker interface {
+	RecordFailure(providerID uint64, clientType string, reason cooldown.CooldownReason, explicitUntil *time.Time) time.Time
+	RecordSuccess(providerID uint64, clientType string)
+	UpdateCooldown(providerID uint64, clientType string, until time.Time)
+}
+
+// SetClock replaces the executor's clock (tests only; the default is the wall clock)
+func (e *Executor) SetClock(c Clock) {
+	e.clock = c
+}
+
+// SetCooldownTracker replaces where cooldown decisions are recorded (default: cooldown.Default())
+func (e *Executor) SetCooldownTracker(t CooldownTracker) {
+	e.cooldowns = t
+}
@@ -0,0 +1,166 @@
+package executor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/cooldown"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// fakeClock returns a fixed time and hands out timers that only fire when the test says so
+type fakeClock struct {
+	now    time.Time
+	waits  []time.Duration
+	timers chan chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, timers: make(chan chan time.Time, 10)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.waits = append(c.waits, d)
+	ch := make(chan time.Time, 1)
+	c.timers <- ch
+	return ch
+}
+
+type failureRecord struct {
+	providerID uint64
+	clientType string
+	reason     cooldown.CooldownReason
+	until      *time.Time
+}
+
+// fakeCooldowns records cooldown decisions instead of applying them
+type fakeCooldowns struct {
+	failures  []failureRecord
+	successes int
+}
+
+func (f *fakeCooldowns) RecordFailure(providerID uint64, clientType string, reason cooldown.CooldownReason, explicitUntil *time.Time) time.Time {
+	f.failures = append(f.failures, failureRecord{providerID, clientType, reason, explicitUntil})
+	return time.Time{}
+}
+
+func (f *fakeCooldowns) RecordSuccess(providerID uint64, clientType string) { f.successes++ }
+
+func (f *fakeCooldowns) UpdateCooldown(providerID uint64, clientType string, until time.Time) {}
+
+func TestWaitRetry(t *testing.T) {
+	clock := newFakeClock(time.Unix(1700000000, 0))
+	e := &Executor{clock: clock}
+
+	// Timer fires: the wait completes
+	done := make(chan error, 1)
+	go func() { done <- e.waitRetry(context.Background(), 3*time.Second) }()
+	(<-clock.timers) <- clock.now
+	if err := <-done; err != nil {
+		t.Fatalf("waitRetry() = %v, want nil", err)
+	}
+	if len(clock.waits) != 1 || clock.waits[0] != 3*time.Second {
+		t.Fatalf("waits = %v, want [3s]", clock.waits)
+	}
+
+	// Client goes away during the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() { done <- e.waitRetry(ctx, time.Minute) }()
+	<-clock.timers
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Fatalf("waitRetry() = %v, want context.Canceled", err)
+	}
+}
+
+func TestCalculateBackoff(t *testing.T) {
+	e := &Executor{}
+	config := &domain.RetryConfig{
+		InitialInterval: time.Second,
+		BackoffRate:     2,
+		MaxInterval:     5 * time.Second,
+	}
+	for attempt, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second} {
+		if got := e.calculateBackoff(config, attempt); got != want {
+			t.Errorf("calculateBackoff(attempt %d) = %v, want %v", attempt, got, want)
+		}
+	}
+}
+
+func TestHandleCooldown(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	resetAt := now.Add(time.Hour)
+	provider := &domain.Provider{ID: 7}
+
+	tests := []struct {
+		name       string
+		err        *domain.ProxyError
+		wantReason cooldown.CooldownReason
+		wantUntil  *time.Time
+		wantClient string
+	}{
+		{
+			name:       "retry after",
+			err:        &domain.ProxyError{RetryAfter: 30 * time.Second},
+			wantReason: cooldown.ReasonRateLimit,
+			wantUntil:  ptrTime(now.Add(30 * time.Second)),
+			wantClient: "claude",
+		},
+		{
+			name: "quota reset time",
+			err: &domain.ProxyError{RateLimitInfo: &domain.RateLimitInfo{
+				Type:           "quota_exhausted",
+				QuotaResetTime: resetAt,
+				ClientType:     "gemini",
+			}},
+			wantReason: cooldown.ReasonQuotaExhausted,
+			wantUntil:  &resetAt,
+			wantClient: "gemini",
+		},
+		{
+			name:       "server error",
+			err:        &domain.ProxyError{IsServerError: true},
+			wantReason: cooldown.ReasonServerError,
+			wantClient: "claude",
+		},
+		{
+			name:       "network error",
+			err:        &domain.ProxyError{IsNetworkError: true},
+			wantReason: cooldown.ReasonNetworkError,
+			wantClient: "claude",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cooldowns := &fakeCooldowns{}
+			e := &Executor{clock: newFakeClock(now), cooldowns: cooldowns}
+			ctx := ctxutil.WithClientType(context.Background(), domain.ClientTypeClaude)
+
+			e.handleCooldown(ctx, tt.err, provider)
+
+			if len(cooldowns.failures) != 1 {
+				t.Fatalf("recorded %d failures, want 1", len(cooldowns.failures))
+			}
+			got := cooldowns.failures[0]
+			if got.providerID != provider.ID || got.clientType != tt.wantClient || got.reason != tt.wantReason {
+				t.Errorf("RecordFailure(%d, %q, %q), want (%d, %q, %q)",
+					got.providerID, got.clientType, got.reason, provider.ID, tt.wantClient, tt.wantReason)
+			}
+			switch {
+			case tt.wantUntil == nil && got.until != nil:
+				t.Errorf("until = %v, want policy-based (nil)", *got.until)
+			case tt.wantUntil != nil && (got.until == nil || !got.until.Equal(*tt.wantUntil)):
+				t.Errorf("until = %v, want %v", got.until, *tt.wantUntil)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/router"
+)
+
+// uncensoredFallback returns the provider (name or ID) blocked non-streaming responses are
+// retried on, "" if none (see the uncensored_fallback setting)
+func uncensoredFallback() string {
+	return strings.TrimSpace(getSetting(domain.SettingKeyUncensoredFallback))
+}
+
+// isProvider reports whether p is the provider named by name (name, case-insensitive, or ID)
+func isProvider(p *domain.Provider, name string) bool {
+	return strings.EqualFold(p.Name, name) || strconv.FormatUint(p.ID, 10) == name
+}
+
+// contentBlockReason returns the category of content the upstream's filters blocked in an
+// attempt, "" if none. The upstream response names the category; the client response is
+// checked too, as passthrough and some adapters don't record the upstream body.
+func contentBlockReason(attempt *domain.ProxyUpstreamAttempt, capture *ResponseCapture) string {
+	if attempt.ResponseInfo != nil {
+		if reason := converter.ContentBlockReason(attempt.ResponseInfo.Body); reason != "" {
+			return reason
+		}
+	}
+	return converter.ContentBlockReason(capture.Body())
+}
+
+// insertRoutes returns routes with extra inserted before index at
+func insertRoutes(routes []*router.MatchedRoute, at int, extra []*router.MatchedRoute) []*router.MatchedRoute {
+	result := make([]*router.MatchedRoute, 0, len(routes)+len(extra))
+	result = append(result, routes[:at]...)
+	result = append(result, extra...)
+	return append(result, routes[at:]...)
+}
+
+// heldResponseWriter holds a response back until it is released, so a blocked response
+// can be dropped and the request retried on the uncensored fallback provider
+type heldResponseWriter struct {
+	w      http.ResponseWriter
+	header http.Header // Headers before the attempt, restored when the response is dropped
+	status int
+	body   bytes.Buffer
+}
+
+func newHeldResponseWriter(w http.ResponseWriter) *heldResponseWriter {
+	return &heldResponseWriter{w: w, header: w.Header().Clone()}
+}
+
+func (h *heldResponseWriter) Header() http.Header {
+	return h.w.Header()
+}
+
+func (h *heldResponseWriter) WriteHeader(code int) {
+	if h.status == 0 {
+		h.status = code
+	}
+}
+
+func (h *heldResponseWriter) Write(b []byte) (int, error) {
+	if h.status == 0 {
+		h.status = http.StatusOK
+	}
+	return h.body.Write(b)
+}
+
+// Flush does nothing: the response is sent when released
+func (h *heldResponseWriter) Flush() {}
+
+// Release sends the held response
+func (h *heldResponseWriter) Release() {
+	if h.status == 0 {
+		return
+	}
+	h.w.WriteHeader(h.status)
+	_, _ = h.w.Write(h.body.Bytes())
+	if f, ok := h.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Drop discards the held response and the headers it set
+func (h *heldResponseWriter) Drop() {
+	header := h.w.Header()
+	for k := range header {
+		delete(header, k)
+	}
+	for k, v := range h.header {
+		header[k] = v
+	}
+	h.status = 0
+	h.body.Reset()
+}
@@ -0,0 +1,41 @@
+package executor
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/awsl-project/maxx/internal/contextguard"
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// applyContextGuard checks the request against the context window of model before dispatch.
+// It returns the body to send (truncated if the drop_oldest policy applied), or a
+// non-retryable error in the client's format if the prompt doesn't fit.
+func applyContextGuard(clientType domain.ClientType, body []byte, providerID uint64, model string) ([]byte, *domain.ProxyError) {
+	mode := contextguard.ParseMode(getSetting(domain.SettingKeyContextGuardMode))
+	if mode == contextguard.ModeOff {
+		return body, nil
+	}
+
+	result, err := contextguard.Check(mode, clientType, body, providerID, model, getSetting(domain.SettingKeyModelContextWindows))
+	if err != nil {
+		log.Printf("[Executor] Context guard: %v", err)
+		apiErr := &converter.APIError{
+			Status:  http.StatusBadRequest,
+			Type:    converter.ErrorTypeInvalidRequest,
+			Message: err.Error(),
+		}
+		proxyErr := domain.NewProxyErrorWithMessage(err, false, "context window exceeded")
+		proxyErr.Code = domain.ErrorCodeInvalidRequest
+		proxyErr.HTTPStatusCode = http.StatusBadRequest
+		proxyErr.ResponseBody = converter.ErrorBody(clientType, apiErr)
+		proxyErr.ResponseFormat = clientType
+		return nil, proxyErr
+	}
+	if result.Dropped > 0 {
+		log.Printf("[Executor] Context guard: dropped %d oldest messages to fit %s (~%d/%d tokens)",
+			result.Dropped, model, result.Estimated, result.Limit)
+	}
+	return result.Body, nil
+}
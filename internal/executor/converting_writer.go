@@ -2,6 +2,7 @@ package executor
 
 import (
 	"bytes"
+	"errors"
 	"net/http"
 	"strings"
 
@@ -77,6 +78,30 @@ func NewConvertingResponseWriter(
 	}
 }
 
+// SetSessionID sets the session the response belongs to, so per-session state
+// (such as thought signatures) can be captured during conversion
+func (c *ConvertingResponseWriter) SetSessionID(sessionID string) {
+	c.streamState.SessionID = sessionID
+}
+
+// SetIncludeUsage sets whether an OpenAI client asked for a final usage chunk
+// (stream_options.include_usage)
+func (c *ConvertingResponseWriter) SetIncludeUsage(includeUsage bool) {
+	c.streamState.IncludeUsage = includeUsage
+}
+
+// SetStopSequences sets the stop sequences of the client request, so the converted
+// response can report the one it stopped at
+func (c *ConvertingResponseWriter) SetStopSequences(sequences []string) {
+	c.streamState.StopSequences = sequences
+}
+
+// SetHideReasoning sets whether upstream thinking is dropped instead of being sent to
+// the client as reasoning
+func (c *ConvertingResponseWriter) SetHideReasoning(hide bool) {
+	c.streamState.HideReasoning = hide
+}
+
 // Header returns the header map
 func (c *ConvertingResponseWriter) Header() http.Header {
 	return c.underlying.Header()
@@ -138,10 +163,24 @@ func (c *ConvertingResponseWriter) Finalize() error {
 	body := c.buffer.Bytes()
 
 	// Convert the response
-	converted, err := c.converter.TransformResponse(c.targetType, c.originalType, body)
+	statusCode := c.statusCode
+	converted, err := c.converter.TransformResponseWithOptions(c.targetType, c.originalType, body,
+		converter.ResponseOptions{
+			StopSequences: c.streamState.StopSequences,
+			SessionID:     c.streamState.SessionID,
+			HideReasoning: c.streamState.HideReasoning,
+		})
 	if err != nil {
-		// On conversion error, use original body
-		converted = body
+		var convErr *converter.ConversionError
+		if statusCode < http.StatusBadRequest && errors.As(err, &convErr) {
+			// A successful response we can't convert: report it in the client's error format
+			apiErr := convErr.APIError()
+			statusCode = apiErr.Status
+			converted = converter.ErrorBody(c.originalType, apiErr)
+		} else {
+			// Upstream error bodies are passed through as-is
+			converted = body
+		}
 	}
 
 	// Update Content-Type header based on original client type
@@ -149,11 +188,13 @@ func (c *ConvertingResponseWriter) Finalize() error {
 
 	// Write headers and body
 	if !c.headersSent {
-		c.underlying.WriteHeader(c.statusCode)
+		c.underlying.WriteHeader(statusCode)
 		c.headersSent = true
 	}
-	_, writeErr := c.underlying.Write(converted)
-	return writeErr
+	if _, writeErr := c.underlying.Write(converted); writeErr != nil {
+		return writeErr
+	}
+	return err
 }
 
 // updateContentType sets the Content-Type header based on client type
@@ -186,26 +227,7 @@ func NeedsConversion(originalType, targetType domain.ClientType) bool {
 // GetPreferredTargetType returns the best target type for conversion
 // Prefers Claude as it has the richest format support
 func GetPreferredTargetType(supportedTypes []domain.ClientType, originalType domain.ClientType) domain.ClientType {
-	// If original type is supported, no conversion needed
-	for _, t := range supportedTypes {
-		if t == originalType {
-			return originalType
-		}
-	}
-
-	// Prefer Claude as target (richest format)
-	for _, t := range supportedTypes {
-		if t == domain.ClientTypeClaude {
-			return t
-		}
-	}
-
-	// Fall back to first supported type
-	if len(supportedTypes) > 0 {
-		return supportedTypes[0]
-	}
-
-	return originalType
+	return converter.PreferredTargetType(supportedTypes, originalType)
 }
 
 // IsSSELine checks if a line is an SSE data line
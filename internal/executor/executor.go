@@ -1,19 +1,30 @@
 package executor
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"log"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/awsl-project/maxx/internal/adapter/provider/stream"
+	"github.com/awsl-project/maxx/internal/capability"
+	"github.com/awsl-project/maxx/internal/contextguard"
 	"github.com/awsl-project/maxx/internal/converter"
 	"github.com/awsl-project/maxx/internal/cooldown"
 	ctxutil "github.com/awsl-project/maxx/internal/context"
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/failback"
 	"github.com/awsl-project/maxx/internal/event"
+	"github.com/awsl-project/maxx/internal/httpclient"
 	"github.com/awsl-project/maxx/internal/pricing"
+	"github.com/awsl-project/maxx/internal/quality"
+	"github.com/awsl-project/maxx/internal/quota"
 	"github.com/awsl-project/maxx/internal/repository"
 	"github.com/awsl-project/maxx/internal/router"
+	"github.com/awsl-project/maxx/internal/slo"
 	"github.com/awsl-project/maxx/internal/stats"
 	"github.com/awsl-project/maxx/internal/usage"
 	"github.com/awsl-project/maxx/internal/waiter"
@@ -32,6 +43,8 @@ type Executor struct {
 	instanceID         string
 	statsAggregator    *stats.StatsAggregator
 	converter          *converter.Registry
+	clock              Clock
+	cooldowns          CooldownTracker
 }
 
 // NewExecutor creates a new executor
@@ -59,6 +72,8 @@ func NewExecutor(
 		instanceID:         instanceID,
 		statsAggregator:    statsAggregator,
 		converter:          converter.GetGlobalRegistry(),
+		clock:              realClock{},
+		cooldowns:          cooldown.Default(),
 	}
 }
 
@@ -73,6 +88,17 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 	// Get API Token ID from context
 	apiTokenID := ctxutil.GetAPITokenID(ctx)
 
+	// Request-level overrides: "model@provider" pins a provider like the x-maxx-provider header
+	pinnedProvider := ctxutil.GetPinnedProvider(ctx)
+	modelOverride := ctxutil.GetModelOverride(ctx)
+	if model, name, ok := e.router.SplitProviderSuffix(requestModel); ok {
+		if pinnedProvider == "" {
+			pinnedProvider = name
+		}
+		ctx = stripModelSuffix(ctx, clientType, requestModel, model)
+		requestModel = model
+	}
+
 	// Create proxy request record immediately (PENDING status)
 	proxyReq := &domain.ProxyRequest{
 		InstanceID:   e.instanceID,
@@ -81,7 +107,7 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 		ClientType:   clientType,
 		ProjectID:    projectID,
 		RequestModel: requestModel,
-		StartTime:    time.Now(),
+		StartTime:    e.clock.Now(),
 		IsStream:     isStream,
 		Status:       "PENDING",
 		APITokenID:   apiTokenID,
@@ -105,10 +131,45 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 		Headers: headers,
 		Body:    string(requestBody),
 	}
+	shape := capability.ShapeOf(requestBody)
+	proxyReq.PromptBytes = uint64(shape.Bytes)
+	proxyReq.MessageCount = shape.Messages
+	proxyReq.ToolCount = shape.Tools
+	proxyReq.HasImage = shape.Image
+	if clientType == domain.ClientTypeClaude {
+		prefix := capability.CachePrefixOf(requestBody)
+		proxyReq.PrefixHash = prefix.Hash
+		proxyReq.PrefixBytes = uint64(prefix.Bytes)
+		proxyReq.CacheControl = prefix.CacheControl
+	}
 
 	if err := e.proxyRequestRepo.Create(proxyReq); err != nil {
 		log.Printf("[Executor] Failed to create proxy request: %v", err)
 	}
+	observeRequest(ctx, proxyReq)
+
+	// Gemini streams without alt=sse are a streamed JSON array: upstream is still asked
+	// for SSE, and the events are reframed for the client
+	geminiJSONStream := clientType == domain.ClientTypeGemini && isStream && wantsGeminiJSONStream(requestURI)
+	if geminiJSONStream {
+		ctx = ctxutil.WithRequestURI(ctx, withAltSSE(requestURI))
+	}
+	auditRequestStarted(proxyReq)
+	defer auditRequestCompleted(proxyReq)
+
+	// Verbose trace of every transformation stage, opted into per request (nil when off)
+	trace := startTrace(ctxutil.GetTrace(ctx), w, proxyReq)
+	if trace != nil {
+		trace.add(TraceStage{
+			Stage:   TraceStageClientRequest,
+			Format:  clientType,
+			Model:   requestModel,
+			Method:  req.Method,
+			URL:     requestURI,
+			Headers: headers,
+			Body:    string(requestBody),
+		})
+	}
 
 	// Broadcast the new request immediately
 	if e.broadcaster != nil {
@@ -117,6 +178,34 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 
 	ctx = ctxutil.WithProxyRequest(ctx, proxyReq)
 
+	if err := validateRequest(clientType, requestBody); err != nil {
+		proxyReq.Status = "REJECTED"
+		proxyReq.Error = err.Error()
+		proxyReq.ErrorCode = domain.ErrorCodeInvalidRequest
+		proxyReq.StatusCode = http.StatusBadRequest
+		proxyReq.EndTime = e.clock.Now()
+		proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
+		_ = e.proxyRequestRepo.Update(proxyReq)
+		if e.broadcaster != nil {
+			e.broadcaster.BroadcastProxyRequest(proxyReq)
+		}
+		return err
+	}
+
+	if proxyErr := e.checkAgentLoop(proxyReq, requestBody); proxyErr != nil {
+		proxyReq.Status = "REJECTED"
+		proxyReq.Error = proxyErr.Error()
+		proxyReq.ErrorCode = proxyErr.Code
+		proxyReq.StatusCode = proxyErr.HTTPStatusCode
+		proxyReq.EndTime = e.clock.Now()
+		proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
+		_ = e.proxyRequestRepo.Update(proxyReq)
+		if e.broadcaster != nil {
+			e.broadcaster.BroadcastProxyRequest(proxyReq)
+		}
+		return proxyErr
+	}
+
 	// Check for project binding if required
 	if projectID == 0 && e.projectWaiter != nil {
 		// Get session for project waiter
@@ -133,9 +222,11 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 			// Determine status based on error type
 			status := "REJECTED"
 			errorMsg := "project binding timeout: " + err.Error()
+			errorCode := domain.ErrorCodeTimeout
 			if err == context.Canceled {
 				status = "CANCELLED"
 				errorMsg = "client cancelled: " + err.Error()
+				errorCode = domain.ErrorCodeClientAbort
 				// Notify frontend to close the dialog
 				if e.broadcaster != nil {
 					e.broadcaster.BroadcastMessage("session_pending_cancelled", map[string]interface{}{
@@ -147,7 +238,8 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 			// Update request record with final status
 			proxyReq.Status = status
 			proxyReq.Error = errorMsg
-			proxyReq.EndTime = time.Now()
+			proxyReq.ErrorCode = errorCode
+			proxyReq.EndTime = e.clock.Now()
 			proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
 			_ = e.proxyRequestRepo.Update(proxyReq)
 
@@ -156,7 +248,9 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 				e.broadcaster.BroadcastProxyRequest(proxyReq)
 			}
 
-			return domain.NewProxyErrorWithMessage(err, false, "project binding required: "+err.Error())
+			proxyErr := domain.NewProxyErrorWithMessage(err, false, "project binding required: "+err.Error())
+			proxyErr.Code = errorCode
+			return proxyErr
 		}
 
 		// Update projectID from the now-bound session
@@ -165,17 +259,58 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 		ctx = ctxutil.WithProjectID(ctx, projectID)
 	}
 
+	// Small background requests of projects with the local responder never reach upstream
+	if pinnedProvider == "" && modelOverride == "" {
+		if reply, ok := e.localBackgroundReply(clientType, projectID, requestModel, requestBody); ok {
+			return e.serveLocalReply(w, proxyReq, requestBody, reply)
+		}
+	}
+
 	// Match routes
-	routes, err := e.router.Match(&router.MatchContext{
-		ClientType:   clientType,
-		ProjectID:    projectID,
-		RequestModel: requestModel,
-		APITokenID:   apiTokenID,
-	})
+	matchCtx := &router.MatchContext{
+		ClientType:          clientType,
+		ProjectID:           projectID,
+		RequestModel:        requestModel,
+		APITokenID:          apiTokenID,
+		SessionID:           sessionID,
+		PinnedProvider:      pinnedProvider,
+		EstimateInputTokens: func() uint64 {
+			return uint64(contextguard.EstimateTokens(requestBody))
+		},
+		MapModel: func(route *domain.Route, provider *domain.Provider) string {
+			return e.mapModel(requestModel, route, provider, clientType, projectID, apiTokenID)
+		},
+	}
+	if pinnedProvider == "" {
+		matchCtx.BackgroundProvider = backgroundProvider(clientType, requestModel, requestBody)
+	}
+	if session, _ := e.sessionRepo.GetBySessionID(sessionID); session != nil {
+		matchCtx.SessionPinnedRouteID = session.PinnedRouteID
+		matchCtx.SessionPinnedProviderID = session.PinnedProviderID
+	}
+	// Admin diagnostic requests always record how their routes were chosen
+	if ctxutil.GetDiagnostic(ctx) || sampleRouterDecision() {
+		matchCtx.Decision = &domain.RouterDecision{}
+	}
+	routes, err := e.router.Match(matchCtx)
+	proxyReq.RouterDecision = matchCtx.Decision
+	if errors.Is(err, domain.ErrProviderNotAllowed) {
+		proxyReq.Status = "REJECTED"
+		proxyReq.Error = "pinned provider not available: " + pinnedProvider
+		proxyReq.ErrorCode = domain.ErrorCodeNoRoutes
+		proxyReq.EndTime = e.clock.Now()
+		proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
+		_ = e.proxyRequestRepo.Update(proxyReq)
+		if e.broadcaster != nil {
+			e.broadcaster.BroadcastProxyRequest(proxyReq)
+		}
+		return domain.NewProxyErrorWithMessage(err, false, "provider "+pinnedProvider)
+	}
 	if err != nil {
 		proxyReq.Status = "FAILED"
 		proxyReq.Error = "no routes available"
-		proxyReq.EndTime = time.Now()
+		proxyReq.ErrorCode = domain.ErrorCodeNoRoutes
+		proxyReq.EndTime = e.clock.Now()
 		proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
 		_ = e.proxyRequestRepo.Update(proxyReq)
 		if e.broadcaster != nil {
@@ -187,7 +322,8 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 	if len(routes) == 0 {
 		proxyReq.Status = "FAILED"
 		proxyReq.Error = "no routes configured"
-		proxyReq.EndTime = time.Now()
+		proxyReq.ErrorCode = domain.ErrorCodeNoRoutes
+		proxyReq.EndTime = e.clock.Now()
 		proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
 		_ = e.proxyRequestRepo.Update(proxyReq)
 		if e.broadcaster != nil {
@@ -198,7 +334,7 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 
 	// Update status to IN_PROGRESS
 	proxyReq.Status = "IN_PROGRESS"
-	_ = e.proxyRequestRepo.Update(proxyReq)
+	_ = e.updateProxyRequestDeferred(proxyReq)
 	ctx = ctxutil.WithProxyRequest(ctx, proxyReq)
 
 	// Add broadcaster to context so adapters can send updates
@@ -218,13 +354,15 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 	defer func() {
 		// If still IN_PROGRESS, mark as cancelled/failed
 		if proxyReq.Status == "IN_PROGRESS" {
-			proxyReq.EndTime = time.Now()
+			proxyReq.EndTime = e.clock.Now()
 			proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
 			if ctx.Err() != nil {
 				proxyReq.Status = "CANCELLED"
 				proxyReq.Error = "client disconnected"
+				proxyReq.ErrorCode = domain.ErrorCodeClientAbort
 			} else {
 				proxyReq.Status = "FAILED"
+				proxyReq.ErrorCode = domain.ErrorCodeInternal
 			}
 			_ = e.proxyRequestRepo.Update(proxyReq)
 			if e.broadcaster != nil {
@@ -236,8 +374,10 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 		if currentAttempt != nil && currentAttempt.Status == "IN_PROGRESS" {
 			if ctx.Err() != nil {
 				currentAttempt.Status = "CANCELLED"
+				currentAttempt.ErrorCode = domain.ErrorCodeClientAbort
 			} else {
 				currentAttempt.Status = "FAILED"
+				currentAttempt.ErrorCode = domain.ErrorCodeInternal
 			}
 			_ = e.attemptRepo.Update(currentAttempt)
 			if e.broadcaster != nil {
@@ -246,9 +386,49 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 		}
 	}()
 
+	// Compress long conversation history before dispatch (optional, see history_summary_* settings)
+	clientBody := ctxutil.GetRequestBody(ctx)
+	if summarized := e.summarizeHistory(ctx, req, proxyReq, clientBody); !bytes.Equal(summarized, clientBody) {
+		ctx = ctxutil.WithRequestBody(ctx, summarized)
+		if trace != nil {
+			trace.add(TraceStage{Stage: TraceStageHistorySummary, Format: clientType, Body: string(summarized)})
+		}
+	}
+
+	// Cost of the history summarization attempts, added to the cost of the serving attempt
+	summaryCost := proxyReq.Cost
+
 	// Try routes in order with retry logic
+	// Each route starts from the client's original request (conversion results don't carry over)
+	baseCtx := ctx
 	var lastErr error
-	for _, matchedRoute := range routes {
+
+	// Model fallback chain state of the current route
+	var fallbackModel string
+	var triedModels map[string]bool
+
+	// Phase timings: the wait before an attempt runs from the request's arrival (then from the
+	// end of the previous attempt); request conversion is reported on the route's first attempt
+	waitStart := proxyReq.StartTime
+	var conversionTime time.Duration
+
+	// Provider that blocked responses are retried on once (uncensored_fallback setting)
+	blockFallback := uncensoredFallback()
+	blockFallbackUsed := false
+
+	// Response capture of the current attempt; each is closed (removing its spill file)
+	// when the next attempt starts or Execute returns
+	var responseCapture *ResponseCapture
+	defer func() {
+		if responseCapture != nil {
+			responseCapture.Close()
+		}
+	}()
+
+	for i := 0; i < len(routes); i++ {
+		matchedRoute := routes[i]
+		ctx = baseCtx
+
 		// Check context before starting new route
 		if ctx.Err() != nil {
 			return ctx.Err()
@@ -257,7 +437,9 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 		// Update proxyReq with current route/provider for real-time tracking
 		proxyReq.RouteID = matchedRoute.Route.ID
 		proxyReq.ProviderID = matchedRoute.Provider.ID
-		_ = e.proxyRequestRepo.Update(proxyReq)
+		proxyReq.Experiment = matchedRoute.Experiment
+		proxyReq.ExperimentArm = matchedRoute.ExperimentArm
+		_ = e.updateProxyRequestDeferred(proxyReq)
 		if e.broadcaster != nil {
 			e.broadcaster.BroadcastProxyRequest(proxyReq)
 		}
@@ -265,14 +447,95 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 		// Determine model mapping
 		// Model mapping is done in Executor after Router has filtered by SupportModels
 		clientType := ctxutil.GetClientType(ctx)
-		mappedModel := e.mapModel(requestModel, matchedRoute.Route, matchedRoute.Provider, clientType, projectID, apiTokenID)
+		mappedModel := modelOverride
+		if mappedModel == "" {
+			mappedModel = matchedRoute.ExperimentModel
+		}
+		if mappedModel == "" {
+			mappedModel = e.mapModel(requestModel, matchedRoute.Route, matchedRoute.Provider, clientType, projectID, apiTokenID)
+		}
+		if fallbackModel != "" {
+			mappedModel = fallbackModel
+			fallbackModel = ""
+		} else {
+			triedModels = make(map[string]bool)
+		}
+		triedModels[mappedModel] = true
 		ctx = ctxutil.WithMappedModel(ctx, mappedModel)
+		auditModelMapped(proxyReq, matchedRoute.Provider, mappedModel)
+
+		// Skip routes whose model is known to lack a feature the request uses
+		if capErr := checkCapabilities(clientType, ctxutil.GetRequestBody(ctx), matchedRoute.Provider.ID, mappedModel); capErr != nil {
+			log.Printf("[Executor] Skipping provider %s: %v", matchedRoute.Provider.Name, capErr.Err)
+			lastErr = capErr
+			if next := nextFallbackModel(mappedModel, triedModels); next != "" {
+				fallbackModel = next
+				i-- // Retry the same route with the fallback model
+			}
+			continue
+		}
+
+		// Per-route system prompt rewrite, applied to the client format before conversion and
+		// before the context guard, so the guard measures the prompt that is actually sent
+		if cfg := matchedRoute.Route.SystemPrompt; cfg != nil {
+			if rewritten, changed := rewriteSystemPrompt(clientType, ctxutil.GetRequestBody(ctx), cfg); changed {
+				if trace != nil {
+					trace.add(TraceStage{
+						Stage:    TraceStageSystemPrompt,
+						Provider: matchedRoute.Provider.Name,
+						Format:   clientType,
+						Model:    mappedModel,
+						Body:     string(rewritten),
+					})
+				}
+				ctx = ctxutil.WithRequestBody(ctx, rewritten)
+				if !cfg.RecordOriginal && proxyReq.RequestInfo != nil {
+					// Don't keep the prompt in the client request record either
+					if recorded, ok := rewriteSystemPrompt(clientType, []byte(proxyReq.RequestInfo.Body), cfg); ok {
+						proxyReq.RequestInfo.Body = string(recorded)
+					}
+				}
+			}
+		}
+
+		// Context-window guard: don't burn an upstream attempt on a prompt the model can't take
+		guardedBody, guardErr := applyContextGuard(clientType, ctxutil.GetRequestBody(ctx), matchedRoute.Provider.ID, mappedModel)
+		if guardErr != nil {
+			lastErr = guardErr
+			if next := nextFallbackModel(mappedModel, triedModels); next != "" {
+				log.Printf("[Executor] Falling back to %s on provider %s", next, matchedRoute.Provider.Name)
+				fallbackModel = next
+				i-- // Retry the same route with the fallback model
+			}
+			continue
+		}
+		if trace != nil && len(guardedBody) != len(ctxutil.GetRequestBody(ctx)) {
+			trace.add(TraceStage{
+				Stage:    TraceStageContextGuard,
+				Provider: matchedRoute.Provider.Name,
+				Format:   clientType,
+				Model:    mappedModel,
+				Body:     string(guardedBody),
+			})
+		}
+		ctx = ctxutil.WithRequestBody(ctx, guardedBody)
+
+		ctx = ctxutil.WithThoughtSignatureMode(ctx, matchedRoute.Route.ThoughtSignatureMode)
+		ctx = ctxutil.WithPingInterval(ctx, matchedRoute.Route.PingInterval())
+
+		// Gemini may repeat function call IDs within a response; repeated ones are renamed for
+		// the client, and the requests returning their results get the original IDs back
+		geminiToolIDs := clientType == domain.ClientTypeClaude && e.claudeGoesToGemini(matchedRoute)
+		if geminiToolIDs {
+			ctx = ctxutil.WithRequestBody(ctx, restoreToolUseIDs(ctxutil.GetRequestBody(ctx)))
+		}
 
 		// Format conversion: check if client type is supported by provider
 		// If not, convert request to a supported format
 		originalClientType := clientType
 		targetClientType := clientType
 		needsConversion := false
+		conversionTime = 0
 
 		supportedTypes := matchedRoute.ProviderAdapter.SupportedClientTypes()
 		if e.converter.NeedConvert(clientType, supportedTypes) {
@@ -284,9 +547,26 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 
 				// Convert request body
 				requestBody := ctxutil.GetRequestBody(ctx)
-				convertedBody, convErr := e.converter.TransformRequest(
-					clientType, targetClientType, requestBody, mappedModel, isStream)
-				if convErr != nil {
+				conversionStart := e.clock.Now()
+				convertedBody, convErr := e.converter.TransformRequestWithOptions(
+					clientType, targetClientType, requestBody, mappedModel, isStream, converter.RequestOptions{
+						SessionID:     sessionID,
+						IdentityPatch: identityPatchOption(matchedRoute.Route.IdentityPatch, converter.IdentityPatchVars{
+							Model:      mappedModel,
+							ClientType: string(clientType),
+							Provider:   matchedRoute.Provider.Name,
+						}),
+						HideReasoning: matchedRoute.Route.HideReasoning,
+						ProviderID:    matchedRoute.Provider.ID,
+					})
+				conversionTime = e.clock.Now().Sub(conversionStart)
+				var conversionErr *converter.ConversionError
+				if errors.As(convErr, &conversionErr) {
+					// The request itself is invalid for the target format, retrying won't help
+					log.Printf("[Executor] Request conversion failed: %v", convErr)
+					lastErr = domain.NewProxyErrorWithMessage(conversionErr, false, "request conversion failed")
+					continue
+				} else if convErr != nil {
 					log.Printf("[Executor] Request conversion failed: %v, proceeding with original format", convErr)
 					needsConversion = false
 				} else {
@@ -302,10 +582,22 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 						ctx = ctxutil.WithRequestURI(ctx, convertedURI)
 						log.Printf("[Executor] URI converted: %s -> %s", originalURI, convertedURI)
 					}
+					if trace != nil {
+						trace.add(TraceStage{
+							Stage:    TraceStageConvertedRequest,
+							Provider: matchedRoute.Provider.Name,
+							Format:   targetClientType,
+							Model:    mappedModel,
+							URL:      convertedURI,
+							Body:     string(convertedBody),
+						})
+					}
 				}
 			}
 		}
 
+		proxyReq.CacheControlStripped = proxyReq.CacheControl && !forwardsCacheControl(matchedRoute.Provider.Type, needsConversion)
+
 		// Get retry config
 		retryConfig := e.getRetryConfig(matchedRoute.RetryConfig)
 
@@ -317,7 +609,12 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 			}
 
 			// Create attempt record with start time
-			attemptStartTime := time.Now()
+			attemptStartTime := e.clock.Now()
+			phases := domain.UpstreamTimings{
+				Wait:       attemptStartTime.Sub(waitStart) - conversionTime,
+				Conversion: conversionTime,
+			}
+			conversionTime = 0
 			attemptRecord := &domain.ProxyUpstreamAttempt{
 				ProxyRequestID: proxyReq.ID,
 				RouteID:        matchedRoute.Route.ID,
@@ -353,45 +650,203 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 			eventChan := domain.NewAdapterEventChan()
 			attemptCtx = ctxutil.WithEventChan(attemptCtx, eventChan)
 
+			// Zero-copy passthrough: same format on both sides and response capture disabled
+			// (traced and quality-sampled requests always capture the response), only for
+			// adapters that copy the upstream bytes unchanged
+			qualitySampled := sampleQuality(matchedRoute.Provider)
+			rules := responseRules(matchedRoute.Provider)
+			passthrough := trace == nil && !qualitySampled && !needsConversion && rules == nil && isStream && stream.CurrentConfig().Passthrough && supportsPassthrough(matchedRoute.ProviderAdapter)
+			if passthrough {
+				attemptCtx = ctxutil.WithPassthrough(attemptCtx, true)
+			}
+
+			// Record connection timings of the upstream request (the last one wins if the adapter retries internally)
+			var timings atomic.Pointer[domain.UpstreamTimings]
+			attemptCtx = httpclient.WithTimingsHook(attemptCtx, func(t domain.UpstreamTimings) {
+				timings.Store(&t)
+			})
+
 			// Start real-time event processing goroutine
 			// This ensures RequestInfo is broadcast as soon as adapter sends it
 			eventDone := make(chan struct{})
-			go e.processAdapterEventsRealtime(eventChan, attemptRecord, eventDone)
+			progress := e.startProgressTicker(attemptRecord)
+			go e.processAdapterEventsRealtime(eventChan, attemptRecord, progress, eventDone)
 
 			// Wrap ResponseWriter to capture actual client response
 			// If format conversion is needed, use ConvertingResponseWriter
 			var responseWriter http.ResponseWriter
 			var convertingWriter *ConvertingResponseWriter
-			responseCapture := NewResponseCapture(w)
+			var jsonStreamWriter *geminiJSONStreamWriter
+			// Hold non-streaming responses back while the uncensored fallback could replace them
+			var held *heldResponseWriter
+			upstreamWriter := w
+			if blockFallback != "" && !blockFallbackUsed && !isStream && !isProvider(matchedRoute.Provider, blockFallback) {
+				held = newHeldResponseWriter(w)
+				upstreamWriter = held
+			}
+			if responseCapture != nil {
+				responseCapture.Close()
+			}
+			if passthrough {
+				responseCapture = NewStatusCapture(upstreamWriter)
+			} else {
+				responseCapture = NewResponseCapture(upstreamWriter)
+				responseCapture.SetMemoryLimit(captureMemoryLimit())
+			}
+
+			var clientWriter http.ResponseWriter = responseCapture
+			var toolIDWriter *toolUseIDWriter
+			if geminiToolIDs && !passthrough {
+				toolIDWriter = newToolUseIDWriter(clientWriter, isStream)
+				clientWriter = toolIDWriter
+			}
+			if geminiJSONStream {
+				// Reframe the (converted) SSE stream as the JSON array the client asked for
+				jsonStreamWriter = newGeminiJSONStreamWriter(responseCapture)
+				clientWriter = jsonStreamWriter
+			}
+			var rulesWriter *jsonRewriteWriter
+			if rules != nil {
+				// Post-process the client-format response (before it is reframed as a JSON array)
+				rulesWriter = newResponseRulesWriter(clientWriter, rules, requestModel, isStream)
+				clientWriter = rulesWriter
+			}
 
 			if needsConversion {
 				// Use ConvertingResponseWriter to transform response from targetType back to originalType
 				convertingWriter = NewConvertingResponseWriter(
-					responseCapture, e.converter, originalClientType, targetClientType, isStream)
+					clientWriter, e.converter, originalClientType, targetClientType, isStream)
+				convertingWriter.SetSessionID(sessionID)
+				convertingWriter.SetIncludeUsage(converter.IncludeUsage(ctxutil.GetRequestBody(ctx)))
+				convertingWriter.SetHideReasoning(matchedRoute.Route.HideReasoning)
+				if originalClientType == domain.ClientTypeClaude {
+					convertingWriter.SetStopSequences(converter.StopSequences(ctxutil.GetRequestBody(ctx)))
+				}
 				responseWriter = convertingWriter
 			} else {
-				responseWriter = responseCapture
+				responseWriter = clientWriter
 			}
 
 			// Execute request
 			err := matchedRoute.ProviderAdapter.Execute(attemptCtx, responseWriter, req, matchedRoute.Provider)
 
+			// Flush the last post-processed event before the JSON array is closed
+			if rulesWriter != nil && isStream {
+				rulesWriter.Finish()
+			}
+
+			// Close the JSON array, also after a stream that broke off midway
+			if jsonStreamWriter != nil && (err == nil || jsonStreamWriter.started) {
+				jsonStreamWriter.Finish()
+			}
+
 			// For non-streaming responses with conversion, finalize the conversion
 			if needsConversion && convertingWriter != nil && !isStream {
 				if finalizeErr := convertingWriter.Finalize(); finalizeErr != nil {
 					log.Printf("[Executor] Response conversion finalize failed: %v", finalizeErr)
 				}
 			}
+			if rulesWriter != nil && !isStream {
+				rulesWriter.Finish()
+			}
+			if toolIDWriter != nil {
+				toolIDWriter.Finish()
+			}
 
 			// Close event channel and wait for processing goroutine to finish
 			eventChan.Close()
 			<-eventDone
+			progress.Stop()
+			waitStart = e.clock.Now()
+
+			// Connection timings, overridden by phases the adapter measured itself
+			phases.Merge(timings.Load())
+			phases.Merge(attemptRecord.Timings)
+			phases.ClientWrite = responseCapture.WriteTime()
+			attemptRecord.Timings = &phases
+
+			// Passthrough adapters don't report the upstream response: record the status and
+			// headers relayed to the client, which are the upstream's
+			if passthrough && attemptRecord.ResponseInfo == nil && responseCapture.Written() {
+				attemptRecord.ResponseInfo = responseCapture.ResponseInfo()
+			}
+
+			if trace != nil {
+				attemptNum := proxyReq.ProxyUpstreamAttemptCount
+				trace.addInfo(TraceStageUpstreamRequest, attemptNum, matchedRoute.Provider.Name, attemptRecord.RequestInfo, nil)
+				trace.addInfo(TraceStageUpstreamResponse, attemptNum, matchedRoute.Provider.Name, nil, attemptRecord.ResponseInfo)
+				if err == nil || responseCapture.Body() != "" {
+					trace.add(TraceStage{
+						Stage:      TraceStageClientResponse,
+						Attempt:    attemptNum,
+						Provider:   matchedRoute.Provider.Name,
+						Format:     originalClientType,
+						StatusCode: responseCapture.StatusCode(),
+						Headers:    responseCapture.CapturedHeaders(),
+						Body:       responseCapture.Body(),
+					})
+				}
+			}
+
+			// Content blocked by the upstream's filters is no error, but it is recorded and, with
+			// an uncensored fallback configured, retried once on that provider
+			var blockReason string
+			if err == nil {
+				blockReason = contentBlockReason(attemptRecord, responseCapture)
+			}
+			if held != nil {
+				var fallback []*router.MatchedRoute
+				if blockReason != "" {
+					fallbackCtx := *matchCtx
+					fallbackCtx.Decision = nil
+					fallback = e.router.MatchProvider(&fallbackCtx, blockFallback)
+				}
+				if len(fallback) == 0 {
+					held.Release()
+				} else {
+					held.Drop()
+					blockFallbackUsed = true
+					log.Printf("[Executor] Provider %s blocked the response (%s), retrying on %s",
+						matchedRoute.Provider.Name, blockReason, fallback[0].Provider.Name)
+
+					attemptRecord.EndTime = e.clock.Now()
+					attemptRecord.Duration = attemptRecord.EndTime.Sub(attemptRecord.StartTime)
+					attemptRecord.Status = "COMPLETED"
+					attemptRecord.ErrorCode = domain.ErrorCodeContentBlocked
+					attemptRecord.BlockReason = blockReason
+					if attemptRecord.InputTokenCount > 0 || attemptRecord.OutputTokenCount > 0 {
+						metrics := &usage.Metrics{
+							InputTokens:          attemptRecord.InputTokenCount,
+							OutputTokens:         attemptRecord.OutputTokenCount,
+							CacheReadCount:       attemptRecord.CacheReadCount,
+							CacheCreationCount:   attemptRecord.CacheWriteCount,
+							Cache5mCreationCount: attemptRecord.Cache5mWriteCount,
+							Cache1hCreationCount: attemptRecord.Cache1hWriteCount,
+						}
+						attemptRecord.Cost = pricing.GlobalCalculator().Calculate(attemptRecord.MappedModel, metrics)
+					}
+					quota.Default().Record(matchedRoute.Provider, attemptRecord.InputTokenCount+attemptRecord.OutputTokenCount)
+					currentAttempt = nil
+					e.recordDecision(attemptRecord, &domain.AttemptDecision{
+						Action: domain.AttemptActionNextRoute,
+						Reason: domain.DecisionReasonContentBlocked,
+					})
+					proxyReq.FinalProxyUpstreamAttemptID = attemptRecord.ID
+
+					routes = insertRoutes(routes, i+1, fallback)
+					break
+				}
+			}
 
 			if err == nil {
 				// Success - set end time and duration
-				attemptRecord.EndTime = time.Now()
+				attemptRecord.EndTime = e.clock.Now()
 				attemptRecord.Duration = attemptRecord.EndTime.Sub(attemptRecord.StartTime)
 				attemptRecord.Status = "COMPLETED"
+				if blockReason != "" {
+					attemptRecord.ErrorCode = domain.ErrorCodeContentBlocked
+					attemptRecord.BlockReason = blockReason
+				}
 
 				// Calculate cost in executor (unified for all adapters)
 				// Adapter only needs to set token counts, executor handles pricing
@@ -411,38 +866,54 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 				if e.broadcaster != nil {
 					e.broadcaster.BroadcastProxyUpstreamAttempt(attemptRecord)
 				}
+				recordMappingOutcome(attemptRecord, matchedRoute.Provider)
+				quota.Default().Record(matchedRoute.Provider, attemptRecord.InputTokenCount+attemptRecord.OutputTokenCount)
 				currentAttempt = nil // Clear so defer doesn't update
 
-				// Reset failure counts on success
-				clientType := string(ctxutil.GetClientType(attemptCtx))
-				cooldown.Default().RecordSuccess(matchedRoute.Provider.ID, clientType)
+				// Reset failure counts on success (diagnostics traffic leaves them untouched)
+				if !ctxutil.GetDiagnostic(attemptCtx) {
+					clientType := string(ctxutil.GetClientType(attemptCtx))
+					e.cooldowns.RecordSuccess(matchedRoute.Provider.ID, clientType)
+					failback.Default().ReportSuccess(matchedRoute.Provider.ID)
+					slo.Default().Record(matchedRoute.Provider.ID, attemptRecord.RequestModel, attemptRecord.MappedModel,
+						attemptRecord.Timings.TTFB, attemptRecord.Duration)
+				}
 
 				proxyReq.Status = "COMPLETED"
-				proxyReq.EndTime = time.Now()
+				if blockReason != "" {
+					proxyReq.ErrorCode = domain.ErrorCodeContentBlocked
+				} else {
+					proxyReq.ErrorCode = ""
+				}
+				proxyReq.EndTime = e.clock.Now()
 				proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
 				proxyReq.FinalProxyUpstreamAttemptID = attemptRecord.ID
 				proxyReq.ResponseModel = mappedModel // Record the actual model used
 
 				// Capture actual client response (what was sent to client, e.g. Claude format)
 				// This is different from attemptRecord.ResponseInfo which is upstream response (Gemini format)
-				proxyReq.ResponseInfo = &domain.ResponseInfo{
-					Status:  responseCapture.StatusCode(),
-					Headers: responseCapture.CapturedHeaders(),
-					Body:    responseCapture.Body(),
-				}
+				proxyReq.ResponseInfo = responseCapture.ResponseInfo()
 				proxyReq.StatusCode = responseCapture.StatusCode()
 
 				// Extract token usage from final client response (not from upstream attempt)
 				// This ensures we use the correct format (Claude/OpenAI/Gemini) for the client type
-				if metrics := usage.ExtractFromResponse(responseCapture.Body()); metrics != nil {
+				if metrics := responseCapture.Usage(); metrics != nil {
 					proxyReq.InputTokenCount = metrics.InputTokens
 					proxyReq.OutputTokenCount = metrics.OutputTokens
 					proxyReq.CacheReadCount = metrics.CacheReadCount
 					proxyReq.CacheWriteCount = metrics.CacheCreationCount
 					proxyReq.Cache5mWriteCount = metrics.Cache5mCreationCount
 					proxyReq.Cache1hWriteCount = metrics.Cache1hCreationCount
+				} else if passthrough {
+					// Body was not captured, the adapter extracted usage from a sample of the stream
+					proxyReq.InputTokenCount = attemptRecord.InputTokenCount
+					proxyReq.OutputTokenCount = attemptRecord.OutputTokenCount
+					proxyReq.CacheReadCount = attemptRecord.CacheReadCount
+					proxyReq.CacheWriteCount = attemptRecord.CacheWriteCount
+					proxyReq.Cache5mWriteCount = attemptRecord.Cache5mWriteCount
+					proxyReq.Cache1hWriteCount = attemptRecord.Cache1hWriteCount
 				}
-				proxyReq.Cost = attemptRecord.Cost
+				proxyReq.Cost = summaryCost + attemptRecord.Cost
 
 				_ = e.proxyRequestRepo.Update(proxyReq)
 
@@ -451,19 +922,46 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 					e.broadcaster.BroadcastProxyRequest(proxyReq)
 				}
 
+				if qualitySampled {
+					var tags []string
+					if isStream {
+						tags = append(tags, quality.TagStream)
+					}
+					if needsConversion {
+						tags = append(tags, quality.TagConverted)
+					}
+					if blockReason != "" {
+						tags = append(tags, quality.TagContentBlocked)
+					}
+					recordQualitySample(proxyReq, matchedRoute.Provider, requestBody, responseCapture, tags)
+				}
+
 				return nil
 			}
 
 			// Handle error - set end time and duration
-			attemptRecord.EndTime = time.Now()
+			attemptRecord.EndTime = e.clock.Now()
 			attemptRecord.Duration = attemptRecord.EndTime.Sub(attemptRecord.StartTime)
 			lastErr = err
 
 			// Update attempt status first (before checking context)
 			if ctx.Err() != nil {
 				attemptRecord.Status = "CANCELLED"
+				attemptRecord.ErrorCode = domain.ErrorCodeClientAbort
 			} else {
 				attemptRecord.Status = "FAILED"
+				attemptRecord.ErrorCode = domain.ErrorCodeOf(err)
+				// Failed attempts count toward latency SLOs too, or a provider timing out on
+				// most requests would look compliant. Without a first byte the whole attempt
+				// is its TTFB: the first byte took at least that long.
+				if !ctxutil.GetDiagnostic(attemptCtx) && attemptRecord.ErrorCode != domain.ErrorCodeClientAbort {
+					ttfb := attemptRecord.Timings.TTFB
+					if ttfb <= 0 {
+						ttfb = attemptRecord.Duration
+					}
+					slo.Default().Record(matchedRoute.Provider.ID, attemptRecord.RequestModel, attemptRecord.MappedModel,
+						ttfb, attemptRecord.Duration)
+				}
 			}
 
 			// Calculate cost in executor even for failed attempts (may have partial token usage)
@@ -483,6 +981,8 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 			if e.broadcaster != nil {
 				e.broadcaster.BroadcastProxyUpstreamAttempt(attemptRecord)
 			}
+			recordMappingOutcome(attemptRecord, matchedRoute.Provider)
+			quota.Default().Record(matchedRoute.Provider, attemptRecord.InputTokenCount+attemptRecord.OutputTokenCount)
 			currentAttempt = nil // Clear so defer doesn't double update
 
 			// Update proxyReq with latest attempt info (even on failure)
@@ -490,15 +990,11 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 
 			// Capture actual client response (even on failure, if any response was sent)
 			if responseCapture.Body() != "" {
-				proxyReq.ResponseInfo = &domain.ResponseInfo{
-					Status:  responseCapture.StatusCode(),
-					Headers: responseCapture.CapturedHeaders(),
-					Body:    responseCapture.Body(),
-				}
+				proxyReq.ResponseInfo = responseCapture.ResponseInfo()
 				proxyReq.StatusCode = responseCapture.StatusCode()
 
 				// Extract token usage from final client response
-				if metrics := usage.ExtractFromResponse(responseCapture.Body()); metrics != nil {
+				if metrics := responseCapture.Usage(); metrics != nil {
 					proxyReq.InputTokenCount = metrics.InputTokens
 					proxyReq.OutputTokenCount = metrics.OutputTokens
 					proxyReq.CacheReadCount = metrics.CacheReadCount
@@ -507,9 +1003,10 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 					proxyReq.Cache1hWriteCount = metrics.Cache1hCreationCount
 				}
 			}
-			proxyReq.Cost = attemptRecord.Cost
+			proxyReq.Cost = summaryCost + attemptRecord.Cost
 
-			_ = e.proxyRequestRepo.Update(proxyReq)
+			// Intermediate state only, the final status is always written synchronously
+			_ = e.updateProxyRequestDeferred(proxyReq)
 			if e.broadcaster != nil {
 				e.broadcaster.BroadcastProxyRequest(proxyReq)
 			}
@@ -519,26 +1016,57 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 				// Set final status before returning to ensure it's persisted
 				// (defer block also handles this, but we want to be explicit and broadcast immediately)
 				proxyReq.Status = "CANCELLED"
-				proxyReq.EndTime = time.Now()
+				proxyReq.EndTime = e.clock.Now()
 				proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
 				proxyReq.Error = "client disconnected"
+				proxyReq.ErrorCode = domain.ErrorCodeClientAbort
 				_ = e.proxyRequestRepo.Update(proxyReq)
 				if e.broadcaster != nil {
 					e.broadcaster.BroadcastProxyRequest(proxyReq)
 				}
+				e.recordDecision(attemptRecord, &domain.AttemptDecision{
+					Action: domain.AttemptActionStop,
+					Reason: domain.DecisionReasonCancelled,
+				})
 				return ctx.Err()
 			}
 
 			// Check if retryable
 			proxyErr, ok := err.(*domain.ProxyError)
 			if !ok {
+				e.recordDecision(attemptRecord, &domain.AttemptDecision{
+					Action: nextRouteAction(i, len(routes)),
+					Reason: domain.DecisionReasonUnknownError,
+				})
 				break // Move to next route
 			}
 
 			// Handle cooldown (unified cooldown logic for all providers)
-			e.handleCooldown(attemptCtx, proxyErr, matchedRoute.Provider)
+			decision := &domain.AttemptDecision{}
+			if until := e.handleCooldown(attemptCtx, proxyErr, matchedRoute.Provider); until.After(e.clock.Now()) {
+				decision.CooldownUntil = &until
+			}
+
+			// The model can't serve this request: walk the fallback chain on this route
+			// before failing over to the next provider
+			if isCapabilityError(proxyErr) {
+				if next := nextFallbackModel(mappedModel, triedModels); next != "" {
+					log.Printf("[Executor] Model %s rejected the request (status %d), falling back to %s on provider %s",
+						mappedModel, proxyErr.HTTPStatusCode, next, matchedRoute.Provider.Name)
+					fallbackModel = next
+					decision.Action = domain.AttemptActionFallbackModel
+					decision.Reason = domain.DecisionReasonModelUnsupported
+					decision.FallbackModel = next
+					e.recordDecision(attemptRecord, decision)
+					i-- // Retry the same route with the fallback model
+					break
+				}
+			}
 
 			if !proxyErr.Retryable {
+				decision.Action = nextRouteAction(i, len(routes))
+				decision.Reason = domain.DecisionReasonNonRetryable
+				e.recordDecision(attemptRecord, decision)
 				break // Move to next route
 			}
 
@@ -548,20 +1076,32 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 				if proxyErr.RetryAfter > 0 {
 					waitTime = proxyErr.RetryAfter
 				}
-				select {
-				case <-ctx.Done():
+				decision.Action = domain.AttemptActionRetry
+				decision.Reason = domain.DecisionReasonRetryable
+				decision.RetryWait = waitTime
+				e.recordDecision(attemptRecord, decision)
+				if err := e.waitRetry(ctx, waitTime); err != nil {
+					e.recordDecision(attemptRecord, &domain.AttemptDecision{
+						Action:        domain.AttemptActionStop,
+						Reason:        domain.DecisionReasonCancelled,
+						CooldownUntil: decision.CooldownUntil,
+					})
 					// Set final status before returning
 					proxyReq.Status = "CANCELLED"
-					proxyReq.EndTime = time.Now()
+					proxyReq.EndTime = e.clock.Now()
 					proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
 					proxyReq.Error = "client disconnected during retry wait"
+					proxyReq.ErrorCode = domain.ErrorCodeClientAbort
 					_ = e.proxyRequestRepo.Update(proxyReq)
 					if e.broadcaster != nil {
 						e.broadcaster.BroadcastProxyRequest(proxyReq)
 					}
-					return ctx.Err()
-				case <-time.After(waitTime):
+					return err
 				}
+			} else {
+				decision.Action = nextRouteAction(i, len(routes))
+				decision.Reason = domain.DecisionReasonRetriesExhausted
+				e.recordDecision(attemptRecord, decision)
 			}
 		}
 		// Inner loop ended, will try next route if available
@@ -569,10 +1109,13 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 
 	// All routes failed
 	proxyReq.Status = "FAILED"
-	proxyReq.EndTime = time.Now()
+	proxyReq.EndTime = e.clock.Now()
 	proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
 	if lastErr != nil {
 		proxyReq.Error = lastErr.Error()
+		proxyReq.ErrorCode = domain.ErrorCodeOf(lastErr)
+	} else {
+		proxyReq.ErrorCode = domain.ErrorCodeNoRoutes
 	}
 	_ = e.proxyRequestRepo.Update(proxyReq)
 
@@ -587,6 +1130,34 @@ func (e *Executor) Execute(ctx context.Context, w http.ResponseWriter, req *http
 	return domain.NewProxyErrorWithMessage(domain.ErrAllRoutesFailed, false, "all routes exhausted")
 }
 
+// deferredProxyRequestUpdater is implemented by repositories that support write-behind updates
+type deferredProxyRequestUpdater interface {
+	UpdateDeferred(req *domain.ProxyRequest) error
+}
+
+// updateProxyRequestDeferred persists a non-critical intermediate state.
+// Falls back to a synchronous Update when the repository has no write-behind support.
+func (e *Executor) updateProxyRequestDeferred(req *domain.ProxyRequest) error {
+	if repo, ok := e.proxyRequestRepo.(deferredProxyRequestUpdater); ok {
+		return repo.UpdateDeferred(req)
+	}
+	return e.proxyRequestRepo.Update(req)
+}
+
+// deferredAttemptUpdater is implemented by repositories that support write-behind updates
+type deferredAttemptUpdater interface {
+	UpdateDeferred(attempt *domain.ProxyUpstreamAttempt) error
+}
+
+// updateAttemptDeferred persists a non-critical update of an attempt.
+// Falls back to a synchronous Update when the repository has no write-behind support.
+func (e *Executor) updateAttemptDeferred(attempt *domain.ProxyUpstreamAttempt) error {
+	if repo, ok := e.attemptRepo.(deferredAttemptUpdater); ok {
+		return repo.UpdateDeferred(attempt)
+	}
+	return e.attemptRepo.Update(attempt)
+}
+
 func (e *Executor) mapModel(requestModel string, route *domain.Route, provider *domain.Provider, clientType domain.ClientType, projectID uint64, apiTokenID uint64) string {
 	// Database model mapping with full query conditions
 	query := &domain.ModelMappingQuery{
@@ -639,10 +1210,54 @@ func (e *Executor) calculateBackoff(config *domain.RetryConfig, attempt int) tim
 	return time.Duration(wait)
 }
 
+// waitRetry waits d before the next attempt, returning the context's error if the
+// client goes away first
+func (e *Executor) waitRetry(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-e.clock.After(d):
+		return nil
+	}
+}
+
+// recordDecision stores what the executor does after a failed attempt, so the retry chain
+// can be reconstructed from the request's attempts
+func (e *Executor) recordDecision(attempt *domain.ProxyUpstreamAttempt, decision *domain.AttemptDecision) {
+	attempt.Decision = decision
+	_ = e.updateAttemptDeferred(attempt)
+	if e.broadcaster != nil {
+		e.broadcaster.BroadcastProxyUpstreamAttempt(attempt)
+	}
+}
+
+// nextRouteAction is the action of moving on from route i of n: the next route, or stopping
+// after the last one
+func nextRouteAction(i, n int) domain.AttemptAction {
+	if i+1 < n {
+		return domain.AttemptActionNextRoute
+	}
+	return domain.AttemptActionStop
+}
+
 func generateRequestID() string {
 	return time.Now().Format("20060102150405.000000")
 }
 
+// forwardsCacheControl reports whether a Claude request's cache_control markers reach the
+// upstream. Converted requests lose them, and the Antigravity and Kiro adapters rewrite
+// the request into APIs without prompt caching.
+func forwardsCacheControl(providerType string, converted bool) bool {
+	if converted {
+		return false
+	}
+	switch providerType {
+	case "antigravity", "kiro":
+		return false
+	}
+	return true
+}
+
 // flattenHeaders converts http.Header to map[string]string (taking first value)
 func flattenHeaders(h http.Header) map[string]string {
 	if h == nil {
@@ -659,7 +1274,18 @@ func flattenHeaders(h http.Header) map[string]string {
 
 // handleCooldown processes cooldown information from ProxyError and sets provider cooldown
 // Priority: 1) Explicit time from API, 2) Policy-based calculation based on failure reason
-func (e *Executor) handleCooldown(ctx context.Context, proxyErr *domain.ProxyError, provider *domain.Provider) {
+// Returns the end of the cooldown (zero or in the past if none was applied)
+func (e *Executor) handleCooldown(ctx context.Context, proxyErr *domain.ProxyError, provider *domain.Provider) time.Time {
+	// The client going away says nothing about the provider's health
+	if domain.ErrorCodeOf(proxyErr) == domain.ErrorCodeClientAbort {
+		return time.Time{}
+	}
+	// Test and probe requests from the admin UI must not trip failure counters
+	if ctxutil.GetDiagnostic(ctx) {
+		log.Printf("[Executor] Diagnostic request failed on provider %s, cooldown not applied", provider.Name)
+		return time.Time{}
+	}
+
 	// Determine which client type to apply cooldown to
 	clientType := proxyErr.CooldownClientType
 	if proxyErr.RateLimitInfo != nil && proxyErr.RateLimitInfo.ClientType != "" {
@@ -688,9 +1314,17 @@ func (e *Executor) handleCooldown(ctx context.Context, proxyErr *domain.ProxyErr
 		reason = mapRateLimitTypeToReason(proxyErr.RateLimitInfo.Type)
 	} else if proxyErr.RetryAfter > 0 {
 		// Has Retry-After duration from API
-		untilTime := time.Now().Add(proxyErr.RetryAfter)
+		untilTime := e.clock.Now().Add(proxyErr.RetryAfter)
 		explicitUntil = &untilTime
 		reason = cooldown.ReasonRateLimit
+	} else if proxyErr.RateLimitInfo != nil && proxyErr.RateLimitInfo.Type == "quota_exhausted" {
+		// Quota exhausted without a reset time: wait for the provider's configured reset
+		reason = cooldown.ReasonQuotaExhausted
+		if provider.Config != nil && provider.Config.ResetSchedule != nil {
+			if untilTime := quota.ScheduleOf(provider).Next(e.clock.Now()); !untilTime.IsZero() {
+				explicitUntil = &untilTime
+			}
+		}
 	} else if proxyErr.IsServerError {
 		// Server error (5xx) - no explicit time, use policy
 		reason = cooldown.ReasonServerError
@@ -708,12 +1342,19 @@ func (e *Executor) handleCooldown(ctx context.Context, proxyErr *domain.ProxyErr
 	// Record failure and apply cooldown
 	// If explicitUntil is not nil, it will be used directly
 	// Otherwise, cooldown duration is calculated based on policy and failure count
-	cooldown.Default().RecordFailure(provider.ID, clientType, reason, explicitUntil)
+	until := e.cooldowns.RecordFailure(provider.ID, clientType, reason, explicitUntil)
+
+	// Unhealthy providers stay behind their backups until probes show they recovered
+	if (reason == cooldown.ReasonServerError || reason == cooldown.ReasonNetworkError) &&
+		until.After(e.clock.Now()) {
+		failback.Default().Demote(provider.ID)
+	}
 
 	// If there's an async update channel, listen for updates
 	if proxyErr.CooldownUpdateChan != nil {
 		go e.handleAsyncCooldownUpdate(proxyErr.CooldownUpdateChan, provider, clientType)
 	}
+	return until
 }
 
 // mapRateLimitTypeToReason maps RateLimitInfo.Type to CooldownReason
@@ -735,9 +1376,9 @@ func (e *Executor) handleAsyncCooldownUpdate(updateChan chan time.Time, provider
 	select {
 	case newCooldownTime := <-updateChan:
 		if !newCooldownTime.IsZero() {
-			cooldown.Default().UpdateCooldown(provider.ID, clientType, newCooldownTime)
+			e.cooldowns.UpdateCooldown(provider.ID, clientType, newCooldownTime)
 		}
-	case <-time.After(15 * time.Second):
+	case <-e.clock.After(15 * time.Second):
 		// Timeout waiting for update
 	}
 }
@@ -781,6 +1422,8 @@ func (e *Executor) processAdapterEvents(eventChan domain.AdapterEventChan, attem
 				if event.ResponseModel != "" {
 					attempt.ResponseModel = event.ResponseModel
 				}
+			case domain.EventTimings:
+				mergeAttemptTimings(attempt, event.Timings)
 			}
 		default:
 			// No more events
@@ -789,9 +1432,20 @@ func (e *Executor) processAdapterEvents(eventChan domain.AdapterEventChan, attem
 	}
 }
 
+// mergeAttemptTimings merges phase timings sent by the adapter into the attempt
+func mergeAttemptTimings(attempt *domain.ProxyUpstreamAttempt, timings *domain.UpstreamTimings) {
+	if timings == nil {
+		return
+	}
+	if attempt.Timings == nil {
+		attempt.Timings = &domain.UpstreamTimings{}
+	}
+	attempt.Timings.Merge(timings)
+}
+
 // processAdapterEventsRealtime processes events in real-time during adapter execution
 // It broadcasts updates immediately when RequestInfo/ResponseInfo are received
-func (e *Executor) processAdapterEventsRealtime(eventChan domain.AdapterEventChan, attempt *domain.ProxyUpstreamAttempt, done chan struct{}) {
+func (e *Executor) processAdapterEventsRealtime(eventChan domain.AdapterEventChan, attempt *domain.ProxyUpstreamAttempt, progress *progressTicker, done chan struct{}) {
 	defer close(done)
 
 	if eventChan == nil || attempt == nil {
@@ -824,6 +1478,7 @@ func (e *Executor) processAdapterEventsRealtime(eventChan domain.AdapterEventCha
 				attempt.CacheWriteCount = event.Metrics.CacheCreationCount
 				attempt.Cache5mWriteCount = event.Metrics.Cache5mCreationCount
 				attempt.Cache1hWriteCount = event.Metrics.Cache1hCreationCount
+				progress.update(event.Metrics)
 				needsBroadcast = true
 			}
 		case domain.EventResponseModel:
@@ -831,6 +1486,8 @@ func (e *Executor) processAdapterEventsRealtime(eventChan domain.AdapterEventCha
 				attempt.ResponseModel = event.ResponseModel
 				needsBroadcast = true
 			}
+		case domain.EventTimings:
+			mergeAttemptTimings(attempt, event.Timings)
 		}
 
 		// Broadcast update immediately for real-time visibility
@@ -0,0 +1,95 @@
+package executor
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// parseFallbackChains parses the model fallback setting: one chain per line,
+// models separated by "->". The first model of a chain may be a wildcard pattern.
+// Blank lines and lines starting with "#" are ignored.
+func parseFallbackChains(value string) [][]string {
+	var chains [][]string
+	for _, line := range strings.Split(value, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var chain []string
+		for _, model := range strings.Split(line, "->") {
+			if model = strings.TrimSpace(model); model != "" {
+				chain = append(chain, model)
+			}
+		}
+		if len(chain) > 1 {
+			chains = append(chains, chain)
+		}
+	}
+	return chains
+}
+
+// nextFallbackModel returns the model to try after model failed with a capability error,
+// or "" if there is none. Models already tried on this route are skipped, so cyclic
+// chains terminate.
+func nextFallbackModel(model string, tried map[string]bool) string {
+	for _, chain := range parseFallbackChains(getSetting(domain.SettingKeyModelFallbackChains)) {
+		pos := -1
+		if domain.MatchWildcard(chain[0], model) {
+			pos = 0
+		} else {
+			for i := 1; i < len(chain); i++ {
+				if chain[i] == model {
+					pos = i
+					break
+				}
+			}
+		}
+		if pos < 0 {
+			continue
+		}
+		for _, next := range chain[pos+1:] {
+			if !tried[next] {
+				return next
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// capabilityErrorHints are substrings of upstream error messages that mean the model
+// can't handle this request (as opposed to a bad request or a provider failure).
+var capabilityErrorHints = []string{
+	// context length
+	"context length", "context_length", "context window", "maximum context",
+	"prompt is too long", "input is too long", "too many tokens", "token limit",
+	"exceeds the maximum number of tokens", "input token count",
+	// images
+	"image input", "images are not supported", "image is not supported",
+	"does not support image", "vision is not supported", "multimodal",
+	// tools
+	"tool schema", "function schema", "invalid schema", "tools are not supported",
+	"does not support tools", "function calling is not", "tool use is not supported",
+}
+
+// isCapabilityError reports whether an upstream error says the model can't serve the request
+// (context too long, images unsupported, tool schema rejected). These errors are worth
+// retrying with a different model but not with the same one.
+func isCapabilityError(err *domain.ProxyError) bool {
+	switch err.HTTPStatusCode {
+	case http.StatusRequestEntityTooLarge:
+		return true
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+	default:
+		return false
+	}
+	body := strings.ToLower(string(err.ResponseBody))
+	for _, hint := range capabilityErrorHints {
+		if strings.Contains(body, hint) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/awsl-project/maxx/internal/adapter/provider"
+	"github.com/awsl-project/maxx/internal/capability"
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/contextguard"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Gemini methods served besides generateContent / streamGenerateContent
+const (
+	GeminiMethodCountTokens = "countTokens" // POST models/{model}:countTokens
+	GeminiMethodGetModel    = "getModel"    // GET models/{model}
+)
+
+// geminiDefaultOutputLimit is reported as outputTokenLimit by locally answered models.get
+// for models without a known output limit
+const geminiDefaultOutputLimit = 65536
+
+// ExecuteGeminiAuxiliary serves the auxiliary Gemini methods gemini-cli calls next to
+// generateContent. The request is forwarded unchanged to the first Gemini-native route whose
+// adapter can pass it through (see ExecutePassthrough); if there is none (e.g. the routes
+// target Claude or OpenAI providers) or all of them fail, the answer is estimated locally.
+// These calls are not recorded as proxy requests: they don't generate anything.
+func (e *Executor) ExecuteGeminiAuxiliary(ctx context.Context, w http.ResponseWriter, req *http.Request, method string) error {
+	served, err := e.passThrough(ctx, w, req)
+	if served || err != nil {
+		return err
+	}
+
+	requestModel := ctxutil.GetRequestModel(ctx)
+	if model, _, ok := e.router.SplitProviderSuffix(requestModel); ok {
+		requestModel = model
+	}
+	writeGeminiAuxiliaryEstimate(w, method, requestModel, ctxutil.GetRequestBody(ctx))
+	return nil
+}
+
+// writeGeminiAuxiliaryEstimate answers countTokens and models.get without an upstream
+func writeGeminiAuxiliaryEstimate(w http.ResponseWriter, method, model string, body []byte) {
+	var resp map[string]interface{}
+	switch method {
+	case GeminiMethodCountTokens:
+		// Body is {"contents": [...]} or {"generateContentRequest": {...}}; both estimate the same
+		resp = map[string]interface{}{"totalTokens": contextguard.EstimateTokens(body)}
+	default:
+		outputLimit := capability.Default().Lookup(0, model).MaxOutput
+		if outputLimit == 0 {
+			outputLimit = geminiDefaultOutputLimit
+		}
+		resp = map[string]interface{}{
+			"name":                       "models/" + model,
+			"version":                    "001",
+			"displayName":                model,
+			"inputTokenLimit":            contextguard.ContextWindow(0, model, getSetting(domain.SettingKeyModelContextWindows)),
+			"outputTokenLimit":           outputLimit,
+			"supportedGenerationMethods": []string{"generateContent", "streamGenerateContent", "countTokens"},
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func supportsClientType(adapter provider.ProviderAdapter, clientType domain.ClientType) bool {
+	for _, ct := range adapter.SupportedClientTypes() {
+		if ct == clientType {
+			return true
+		}
+	}
+	return false
+}
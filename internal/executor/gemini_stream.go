@@ -0,0 +1,142 @@
+package executor
+
+import (
+	"bytes"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// wantsGeminiJSONStream reports whether a Gemini streamGenerateContent request asks for
+// the JSON array framing: any alt other than "sse", including none, which is the
+// Gemini API default.
+func wantsGeminiJSONStream(requestURI string) bool {
+	u, err := url.ParseRequestURI(requestURI)
+	if err != nil {
+		return false
+	}
+	return u.Query().Get("alt") != "sse"
+}
+
+// withAltSSE returns the request URI with alt=sse, so upstream streams SSE
+func withAltSSE(requestURI string) string {
+	u, err := url.ParseRequestURI(requestURI)
+	if err != nil {
+		return requestURI
+	}
+	q := u.Query()
+	q.Set("alt", "sse")
+	u.RawQuery = q.Encode()
+	return u.RequestURI()
+}
+
+// geminiJSONStreamWriter reframes a Gemini SSE stream as the streamed JSON array Gemini
+// sends without alt=sse ("[{...}\n,\r\n{...}\n]"), chunk by chunk as events arrive.
+// Error responses are passed through unchanged.
+type geminiJSONStreamWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	line        []byte
+	data        []byte // Data lines of the current event
+	started     bool   // "[" was written
+}
+
+func newGeminiJSONStreamWriter(w http.ResponseWriter) *geminiJSONStreamWriter {
+	return &geminiJSONStreamWriter{ResponseWriter: w, statusCode: http.StatusOK}
+}
+
+func (g *geminiJSONStreamWriter) WriteHeader(code int) {
+	g.statusCode = code
+	g.wroteHeader = true
+	if code < 400 {
+		g.Header().Set("Content-Type", "application/json")
+		g.Header().Del("Content-Length")
+	}
+	g.ResponseWriter.WriteHeader(code)
+}
+
+func (g *geminiJSONStreamWriter) Write(b []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.statusCode >= 400 {
+		return g.ResponseWriter.Write(b)
+	}
+	data := b
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			g.line = append(g.line, data...)
+			break
+		}
+		g.line = append(g.line, data[:i]...)
+		if err := g.processLine(); err != nil {
+			return 0, err
+		}
+		data = data[i+1:]
+	}
+	return len(b), nil
+}
+
+func (g *geminiJSONStreamWriter) processLine() error {
+	line := strings.TrimRight(string(g.line), "\r")
+	g.line = g.line[:0]
+	if line == "" {
+		return g.emit()
+	}
+	// event:, id: and comment lines carry nothing for the JSON framing
+	if payload, ok := strings.CutPrefix(line, "data:"); ok {
+		if len(g.data) > 0 {
+			g.data = append(g.data, '\n')
+		}
+		g.data = append(g.data, strings.TrimPrefix(payload, " ")...)
+	}
+	return nil
+}
+
+// emit writes the current event as the next array element
+func (g *geminiJSONStreamWriter) emit() error {
+	payload := bytes.TrimSpace(g.data)
+	g.data = g.data[:0]
+	if len(payload) == 0 || string(payload) == "[DONE]" {
+		return nil
+	}
+	sep := "\n,\r\n"
+	if !g.started {
+		sep = "["
+		g.started = true
+	}
+	if _, err := g.ResponseWriter.Write([]byte(sep)); err != nil {
+		return err
+	}
+	_, err := g.ResponseWriter.Write(payload)
+	return err
+}
+
+// Flush implements http.Flusher
+func (g *geminiJSONStreamWriter) Flush() {
+	if f, ok := g.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Finish emits a trailing event and closes the array ("[]" if no chunk was sent)
+func (g *geminiJSONStreamWriter) Finish() {
+	if !g.wroteHeader {
+		g.WriteHeader(http.StatusOK)
+	}
+	if g.statusCode >= 400 {
+		return
+	}
+	if len(g.line) > 0 {
+		_ = g.processLine()
+	}
+	_ = g.emit()
+	if !g.started {
+		g.started = true
+		_, _ = g.ResponseWriter.Write([]byte("["))
+	}
+	_, _ = g.ResponseWriter.Write([]byte("\n]"))
+	g.Flush()
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+)
+
+// jsonRewriteWriter rewrites the JSON of a successful response on its way to the client.
+// Streams are rewritten event by event (each data: line); non-streaming responses are held
+// and rewritten as a whole in Finish. Error responses are passed through unchanged.
+type jsonRewriteWriter struct {
+	http.ResponseWriter
+	stream      bool
+	match       func(payload []byte) bool           // Cheap check whether an event may need rewriting; nil means all
+	rewrite     func(v map[string]interface{}) bool // Rewrites v in place; reports whether it changed
+	statusCode  int
+	wroteHeader bool
+	line        []byte       // Incomplete line of a stream
+	body        bytes.Buffer // Non-streaming response body
+}
+
+func newJSONRewriteWriter(w http.ResponseWriter, stream bool, rewrite func(v map[string]interface{}) bool) *jsonRewriteWriter {
+	return &jsonRewriteWriter{ResponseWriter: w, stream: stream, rewrite: rewrite, statusCode: http.StatusOK}
+}
+
+func (j *jsonRewriteWriter) WriteHeader(code int) {
+	j.statusCode = code
+	j.wroteHeader = true
+	if code < 400 && !j.stream {
+		// The body may change length
+		j.Header().Del("Content-Length")
+	}
+	j.ResponseWriter.WriteHeader(code)
+}
+
+func (j *jsonRewriteWriter) Write(b []byte) (int, error) {
+	if !j.wroteHeader {
+		j.WriteHeader(http.StatusOK)
+	}
+	if j.statusCode >= 400 {
+		return j.ResponseWriter.Write(b)
+	}
+	if !j.stream {
+		return j.body.Write(b)
+	}
+
+	var out []byte
+	data := b
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			j.line = append(j.line, data...)
+			break
+		}
+		j.line = append(j.line, data[:i+1]...)
+		out = append(out, j.rewriteLine(j.line)...)
+		j.line = j.line[:0]
+		data = data[i+1:]
+	}
+	if len(out) > 0 {
+		if _, err := j.ResponseWriter.Write(out); err != nil {
+			return 0, err
+		}
+	}
+	return len(b), nil
+}
+
+// rewriteLine rewrites the JSON payload of a data: line
+func (j *jsonRewriteWriter) rewriteLine(line []byte) []byte {
+	payload, ok := bytes.CutPrefix(bytes.TrimRight(line, "\r\n"), []byte("data:"))
+	if !ok || (j.match != nil && !j.match(payload)) {
+		return line
+	}
+	var event map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(payload), &event); err != nil {
+		return line
+	}
+	if !j.rewrite(event) {
+		return line
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return line
+	}
+	return []byte("data: " + string(data) + "\n")
+}
+
+// Flush implements http.Flusher
+func (j *jsonRewriteWriter) Flush() {
+	if f, ok := j.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Finish writes the held non-streaming response and any incomplete last line of a stream
+func (j *jsonRewriteWriter) Finish() {
+	if j.statusCode >= 400 {
+		return
+	}
+	if j.stream {
+		if len(j.line) > 0 {
+			_, _ = j.ResponseWriter.Write(j.rewriteLine(j.line))
+			j.line = j.line[:0]
+		}
+		return
+	}
+	if j.body.Len() == 0 {
+		return
+	}
+	body := j.body.Bytes()
+	var resp map[string]interface{}
+	if err := json.Unmarshal(body, &resp); err == nil && j.rewrite(resp) {
+		if data, err := json.Marshal(resp); err == nil {
+			body = data
+		}
+	}
+	_, _ = j.ResponseWriter.Write(body)
+	j.body.Reset()
+}
@@ -0,0 +1,213 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/contextguard"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+const (
+	defaultBackgroundLocalMaxInput = 2048
+	// Claude Code's topic detection expects a JSON verdict and its quota check (a bare
+	// "quota" message) any reply. Other background prompts, such as the bash command prefix
+	// check, need a real answer and go upstream.
+	defaultBackgroundLocalResponses = `isNewTopic => {"isNewTopic": false, "title": null}
+quota => OK`
+)
+
+// localResponse is one "keyword => reply" rule of the local responder
+type localResponse struct {
+	keyword string
+	reply   string
+}
+
+// localBackgroundReply returns the canned reply for a Claude background request that is
+// answered locally instead of being sent upstream, or ok=false if the request goes upstream.
+//
+// The local responder is enabled per project (requests without a project follow the
+// background_local_responder setting). Only background requests whose estimated input
+// stays within background_local_max_input tokens and that match a reply rule are answered.
+func (e *Executor) localBackgroundReply(clientType domain.ClientType, projectID uint64, requestModel string, body []byte) (reply string, ok bool) {
+	if !isBackgroundRequest(clientType, requestModel, body) || !e.localResponderEnabled(projectID) {
+		return "", false
+	}
+	if contextguard.EstimateTokens(body) > backgroundLocalMaxInput() {
+		return "", false
+	}
+
+	prompt := strings.ToLower(claudePromptText(body))
+	for _, rule := range backgroundLocalResponses() {
+		if rule.keyword == "*" || strings.Contains(prompt, strings.ToLower(rule.keyword)) {
+			return rule.reply, true
+		}
+	}
+	return "", false
+}
+
+func (e *Executor) localResponderEnabled(projectID uint64) bool {
+	if projectID == 0 {
+		return getSetting(domain.SettingKeyBackgroundLocalResponder) == "true"
+	}
+	project, err := e.router.GetProject(projectID)
+	return err == nil && project.LocalBackgroundResponder
+}
+
+func backgroundLocalMaxInput() int {
+	if n, err := strconv.Atoi(getSetting(domain.SettingKeyBackgroundLocalMaxInput)); err == nil && n > 0 {
+		return n
+	}
+	return defaultBackgroundLocalMaxInput
+}
+
+// backgroundLocalResponses parses the reply rules, one "keyword => reply" per line
+func backgroundLocalResponses() []localResponse {
+	value := getSetting(domain.SettingKeyBackgroundLocalResponses)
+	if strings.TrimSpace(value) == "" {
+		value = defaultBackgroundLocalResponses
+	}
+	var rules []localResponse
+	for _, line := range strings.Split(value, "\n") {
+		keyword, reply, found := strings.Cut(line, "=>")
+		keyword, reply = strings.TrimSpace(keyword), strings.TrimSpace(reply)
+		if !found || keyword == "" || reply == "" {
+			continue
+		}
+		rules = append(rules, localResponse{keyword: keyword, reply: reply})
+	}
+	return rules
+}
+
+// claudePromptText returns the text of a Claude request's system prompt and messages
+func claudePromptText(body []byte) string {
+	var req struct {
+		System   json.RawMessage `json:"system"`
+		Messages []struct {
+			Content json.RawMessage `json:"content"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+	parts := []string{claudeContentText(req.System)}
+	for _, msg := range req.Messages {
+		parts = append(parts, claudeContentText(msg.Content))
+	}
+	return strings.Join(parts, "\n")
+}
+
+// claudeContentText returns the text of a string or an array of content blocks
+func claudeContentText(raw json.RawMessage) string {
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return text
+	}
+	var blocks []struct {
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(raw, &blocks) != nil {
+		return ""
+	}
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		if block.Text != "" {
+			texts = append(texts, block.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// serveLocalReply answers a background request with a canned reply in the Claude format
+// and completes its record; nothing is sent upstream.
+func (e *Executor) serveLocalReply(w http.ResponseWriter, proxyReq *domain.ProxyRequest, body []byte, reply string) error {
+	inputTokens := contextguard.EstimateTokens(body)
+	outputTokens := (len(reply) + 3) / 4
+	id := "msg_local_" + proxyReq.RequestID
+
+	var respBody string
+	if proxyReq.IsStream {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		respBody = localReplyEvents(id, proxyReq.RequestModel, reply, inputTokens, outputTokens)
+	} else {
+		w.Header().Set("Content-Type", "application/json")
+		data, _ := json.Marshal(map[string]interface{}{
+			"id":            id,
+			"type":          "message",
+			"role":          "assistant",
+			"model":         proxyReq.RequestModel,
+			"content":       []map[string]string{{"type": "text", "text": reply}},
+			"stop_reason":   "end_turn",
+			"stop_sequence": nil,
+			"usage":         map[string]int{"input_tokens": inputTokens, "output_tokens": outputTokens},
+		})
+		respBody = string(data)
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(respBody))
+	if f, ok := w.(http.Flusher); ok {
+		f.Flush()
+	}
+	log.Printf("[Executor] Answered background request %s (%s) locally", proxyReq.RequestID, proxyReq.RequestModel)
+
+	proxyReq.Status = "COMPLETED"
+	proxyReq.StatusCode = http.StatusOK
+	proxyReq.LocalResponse = true
+	proxyReq.ResponseModel = proxyReq.RequestModel
+	proxyReq.ResponseInfo = &domain.ResponseInfo{
+		Status:  http.StatusOK,
+		Headers: map[string]string{"Content-Type": w.Header().Get("Content-Type")},
+		Body:    respBody,
+	}
+	proxyReq.EndTime = e.clock.Now()
+	proxyReq.Duration = proxyReq.EndTime.Sub(proxyReq.StartTime)
+	_ = e.proxyRequestRepo.Update(proxyReq)
+	if e.broadcaster != nil {
+		e.broadcaster.BroadcastProxyRequest(proxyReq)
+	}
+	return nil
+}
+
+// localReplyEvents renders a reply as the event stream of a Claude message
+func localReplyEvents(id, model, reply string, inputTokens, outputTokens int) string {
+	events := []struct {
+		name string
+		data map[string]interface{}
+	}{
+		{"message_start", map[string]interface{}{
+			"type": "message_start",
+			"message": map[string]interface{}{
+				"id": id, "type": "message", "role": "assistant", "model": model,
+				"content": []interface{}{}, "stop_reason": nil, "stop_sequence": nil,
+				"usage": map[string]int{"input_tokens": inputTokens, "output_tokens": 0},
+			},
+		}},
+		{"content_block_start", map[string]interface{}{
+			"type": "content_block_start", "index": 0,
+			"content_block": map[string]string{"type": "text", "text": ""},
+		}},
+		{"content_block_delta", map[string]interface{}{
+			"type": "content_block_delta", "index": 0,
+			"delta": map[string]string{"type": "text_delta", "text": reply},
+		}},
+		{"content_block_stop", map[string]interface{}{"type": "content_block_stop", "index": 0}},
+		{"message_delta", map[string]interface{}{
+			"type":  "message_delta",
+			"delta": map[string]interface{}{"stop_reason": "end_turn", "stop_sequence": nil},
+			"usage": map[string]int{"output_tokens": outputTokens},
+		}},
+		{"message_stop", map[string]interface{}{"type": "message_stop"}},
+	}
+
+	var sb strings.Builder
+	for _, event := range events {
+		data, _ := json.Marshal(event.data)
+		fmt.Fprintf(&sb, "event: %s\ndata: %s\n\n", event.name, data)
+	}
+	return sb.String()
+}
@@ -0,0 +1,21 @@
+package executor
+
+import (
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/mappinglearn"
+)
+
+// recordMappingOutcome feeds a finished attempt to the model mapping learner when
+// learning mode is on. Cancelled attempts say nothing about the mapping and are skipped.
+func recordMappingOutcome(attempt *domain.ProxyUpstreamAttempt, provider *domain.Provider) {
+	if getSetting(domain.SettingKeyModelMappingLearning) != "true" || attempt.Status == "CANCELLED" {
+		return
+	}
+	mappinglearn.Default().Record(mappinglearn.Outcome{
+		ProviderID:   provider.ID,
+		ProviderName: provider.Name,
+		RequestModel: attempt.RequestModel,
+		MappedModel:  attempt.MappedModel,
+		Success:      attempt.Status == "COMPLETED",
+	})
+}
@@ -0,0 +1,35 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// stripModelSuffix replaces a "model@provider" request model with the bare model
+// in the request body (or the URL path for Gemini), so the suffix never reaches upstream.
+func stripModelSuffix(ctx context.Context, clientType domain.ClientType, from, to string) context.Context {
+	ctx = ctxutil.WithRequestModel(ctx, to)
+
+	uri := ctxutil.GetRequestURI(ctx)
+	if clientType == domain.ClientTypeGemini && strings.Contains(uri, "/models/"+from) {
+		return ctxutil.WithRequestURI(ctx, strings.Replace(uri, "/models/"+from, "/models/"+to, 1))
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(ctxutil.GetRequestBody(ctx), &data); err != nil {
+		return ctx
+	}
+	if model, _ := data["model"].(string); model != from {
+		return ctx
+	}
+	data["model"] = to
+	body, err := json.Marshal(data)
+	if err != nil {
+		return ctx
+	}
+	return ctxutil.WithRequestBody(ctx, body)
+}
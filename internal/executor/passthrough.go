@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/awsl-project/maxx/internal/adapter/provider"
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/router"
+)
+
+// ExecutePassthrough serves the body-less GET and HEAD calls client SDKs make next to
+// generation, such as GET /v1/models, GET /v1/models/{model} and GET /v1beta/models.
+// They are routed like any other request of the client type, so pinning, project routes
+// and cooldowns apply, and forwarded unchanged to the first route whose adapter can pass
+// them through natively. Upstream failures put the provider in cooldown as usual.
+// These calls are not recorded as proxy requests: they don't generate anything.
+func (e *Executor) ExecutePassthrough(ctx context.Context, w http.ResponseWriter, req *http.Request) error {
+	served, err := e.passThrough(ctx, w, req)
+	if served || err != nil {
+		return err
+	}
+	return domain.NewProxyErrorWithMessage(domain.ErrNoRoutes, false, "no route can pass "+req.Method+" "+req.URL.Path+" through")
+}
+
+// passThrough forwards req to the first matched route whose adapter implements
+// provider.AuxiliaryAdapter and natively serves the client type. It reports whether a route
+// answered; client errors (bad request, unknown model) are returned as they won't go away
+// on another provider. With no answer and no error the caller decides how to respond.
+func (e *Executor) passThrough(ctx context.Context, w http.ResponseWriter, req *http.Request) (bool, error) {
+	clientType := ctxutil.GetClientType(ctx)
+	projectID := ctxutil.GetProjectID(ctx)
+	apiTokenID := ctxutil.GetAPITokenID(ctx)
+	requestModel := ctxutil.GetRequestModel(ctx)
+
+	pinnedProvider := ctxutil.GetPinnedProvider(ctx)
+	if model, name, ok := e.router.SplitProviderSuffix(requestModel); ok {
+		if pinnedProvider == "" {
+			pinnedProvider = name
+		}
+		requestModel = model
+	}
+
+	routes, err := e.router.Match(&router.MatchContext{
+		ClientType:     clientType,
+		ProjectID:      projectID,
+		RequestModel:   requestModel,
+		APITokenID:     apiTokenID,
+		PinnedProvider: pinnedProvider,
+	})
+	if errors.Is(err, domain.ErrProviderNotAllowed) {
+		return false, err
+	}
+
+	for _, matched := range routes {
+		adapter, ok := matched.ProviderAdapter.(provider.AuxiliaryAdapter)
+		if !ok || !supportsClientType(matched.ProviderAdapter, clientType) {
+			continue
+		}
+
+		// Model listings carry no model to map
+		mappedModel := ctxutil.GetModelOverride(ctx)
+		if mappedModel == "" && requestModel != "" {
+			mappedModel = e.mapModel(requestModel, matched.Route, matched.Provider, clientType, projectID, apiTokenID)
+		}
+
+		// Buffer the response so a failed route doesn't leave a partial answer behind
+		buf := newBufferedResponseWriter()
+		err := adapter.ExecuteAuxiliary(ctxutil.WithMappedModel(ctx, mappedModel), buf, req, matched.Provider)
+		if err == nil {
+			for key, values := range buf.header {
+				w.Header()[key] = values
+			}
+			w.WriteHeader(buf.status)
+			_, _ = w.Write(buf.body.Bytes())
+			return true, nil
+		}
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		log.Printf("[Executor] %s %s via provider %s failed: %v", req.Method, req.URL.Path, matched.Provider.Name, err)
+
+		var proxyErr *domain.ProxyError
+		if !errors.As(err, &proxyErr) {
+			continue
+		}
+		if !proxyErr.Retryable && proxyErr.HTTPStatusCode >= 400 && proxyErr.HTTPStatusCode < 500 {
+			return false, proxyErr
+		}
+		// Rate limits and outages apply to generation as well; successes are not recorded,
+		// as a metadata call says little about the provider's health
+		if proxyErr.Retryable {
+			e.handleCooldown(ctx, proxyErr, matched.Provider)
+		}
+	}
+	return false, nil
+}
+
+// supportsPassthrough reports whether the adapter streams the upstream bytes unchanged
+// when an attempt runs in passthrough mode
+func supportsPassthrough(adapter provider.ProviderAdapter) bool {
+	p, ok := adapter.(provider.PassthroughAdapter)
+	return ok && p.SupportsPassthrough()
+}
@@ -0,0 +1,15 @@
+package executor
+
+import (
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// validateRequest checks the client request against its format before routing, unless
+// the request_validation setting is "false". Returns a *converter.ValidationError.
+func validateRequest(clientType domain.ClientType, body []byte) error {
+	if getSetting(domain.SettingKeyRequestValidation) == "false" {
+		return nil
+	}
+	return converter.ValidateRequest(clientType, body)
+}
@@ -0,0 +1,106 @@
+package executor
+
+import (
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/pricing"
+	"github.com/awsl-project/maxx/internal/usage"
+)
+
+// AttemptProgressMessageType is the broadcast message type of attempt progress events
+const AttemptProgressMessageType = "attempt_progress"
+
+// progressInterval is how often progress is broadcast while a stream is running
+const progressInterval = time.Second
+
+// progressTicker periodically broadcasts the progress of a streaming attempt: the token
+// usage reported so far, the elapsed time and the cost estimated from them
+type progressTicker struct {
+	e       *Executor
+	attempt *domain.ProxyUpstreamAttempt
+
+	mu      sync.Mutex
+	metrics domain.AdapterMetrics
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// startProgressTicker starts broadcasting progress for an attempt; returns nil if there
+// is nobody to broadcast to. The attempt's ID, provider and model must not change while
+// the ticker runs.
+func (e *Executor) startProgressTicker(attempt *domain.ProxyUpstreamAttempt) *progressTicker {
+	if e.broadcaster == nil || !attempt.IsStream {
+		return nil
+	}
+	t := &progressTicker{
+		e:       e,
+		attempt: attempt,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// update records the usage reported by the adapter
+func (t *progressTicker) update(m *domain.AdapterMetrics) {
+	if t == nil || m == nil {
+		return
+	}
+	t.mu.Lock()
+	t.metrics = *m
+	t.mu.Unlock()
+}
+
+// Stop stops the ticker and waits for it to exit
+func (t *progressTicker) Stop() {
+	if t == nil {
+		return
+	}
+	close(t.stop)
+	<-t.done
+}
+
+func (t *progressTicker) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.e.broadcaster.BroadcastMessage(AttemptProgressMessageType, t.snapshot())
+		}
+	}
+}
+
+func (t *progressTicker) snapshot() *domain.AttemptProgress {
+	t.mu.Lock()
+	m := t.metrics
+	t.mu.Unlock()
+
+	a := t.attempt
+	return &domain.AttemptProgress{
+		ProxyRequestID:  a.ProxyRequestID,
+		AttemptID:       a.ID,
+		ProviderID:      a.ProviderID,
+		Model:           a.MappedModel,
+		ElapsedMs:       t.e.clock.Now().Sub(a.StartTime).Milliseconds(),
+		InputTokens:     m.InputTokens,
+		OutputTokens:    m.OutputTokens,
+		CacheReadCount:  m.CacheReadCount,
+		CacheWriteCount: m.CacheCreationCount,
+		EstimatedCost: pricing.GlobalCalculator().Calculate(a.MappedModel, &usage.Metrics{
+			InputTokens:          m.InputTokens,
+			OutputTokens:         m.OutputTokens,
+			CacheReadCount:       m.CacheReadCount,
+			CacheCreationCount:   m.CacheCreationCount,
+			Cache5mCreationCount: m.Cache5mCreationCount,
+			Cache1hCreationCount: m.Cache1hCreationCount,
+		}),
+	}
+}
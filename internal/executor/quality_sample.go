@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"io"
+	"log"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/quality"
+)
+
+// qualitySampleRate returns the percentage (0-100) of a provider's successful requests
+// stored for quality review. The quality_sample_rates setting has one "provider: percent"
+// entry per line or comma, the provider given by name or ID, or "*" for all others.
+func qualitySampleRate(p *domain.Provider) float64 {
+	rate := 0.0
+	for _, entry := range strings.FieldsFunc(getSetting(domain.SettingKeyQualitySampleRates), func(r rune) bool {
+		return r == '\n' || r == ','
+	}) {
+		idx := strings.LastIndex(entry, ":")
+		if idx < 0 {
+			continue
+		}
+		name := strings.TrimSpace(entry[:idx])
+		percent, err := strconv.ParseFloat(strings.TrimSpace(entry[idx+1:]), 64)
+		if err != nil || percent < 0 {
+			continue
+		}
+		if isProvider(p, name) {
+			return percent
+		}
+		if name == "*" {
+			rate = percent
+		}
+	}
+	return rate
+}
+
+// sampleQuality reports whether a request served by p is stored for quality review
+func sampleQuality(p *domain.Provider) bool {
+	rate := qualitySampleRate(p)
+	return rate >= 100 || (rate > 0 && rand.Float64()*100 < rate)
+}
+
+// recordQualitySample stores the full request and response of a successful sampled request.
+// The response is read from the capture's spill file when it exceeded the memory limit.
+func recordQualitySample(req *domain.ProxyRequest, provider *domain.Provider, requestBody []byte, capture *ResponseCapture, tags []string) {
+	body, err := capture.OpenBody()
+	if err != nil {
+		log.Printf("[Executor] Quality sample of request %d: failed to read response: %v", req.ID, err)
+		return
+	}
+	response, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		log.Printf("[Executor] Quality sample of request %d: failed to read response: %v", req.ID, err)
+		return
+	}
+
+	quality.Default().Record(&quality.Sample{
+		Timestamp:      req.EndTime,
+		Tags:           append([]string{quality.TagReview}, tags...),
+		ProxyRequestID: req.ID,
+		RequestID:      req.RequestID,
+		SessionID:      req.SessionID,
+		ClientType:     string(req.ClientType),
+		ProviderID:     provider.ID,
+		ProviderName:   provider.Name,
+		ProviderType:   provider.Type,
+		Model:          req.RequestModel,
+		ResponseModel:  req.ResponseModel,
+		StatusCode:     req.StatusCode,
+		DurationMs:     req.Duration.Milliseconds(),
+		InputTokens:    req.InputTokenCount,
+		OutputTokens:   req.OutputTokenCount,
+		Cost:           req.Cost,
+		Request:        quality.Body(requestBody),
+		Response:       quality.Body(response),
+	})
+}
@@ -2,16 +2,38 @@ package executor
 
 import (
 	"bytes"
+	"io"
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/usage"
 )
 
+// defaultCaptureMemoryMB is how much of a response body is kept in memory by default
+const defaultCaptureMemoryMB = 8
+
 // ResponseCapture wraps http.ResponseWriter to capture the response
 // This allows us to record the actual response sent to the client
 type ResponseCapture struct {
 	http.ResponseWriter
 	statusCode int
+	written    bool // The status was sent (explicitly or by the first write)
 	body       bytes.Buffer
 	headers    http.Header
+	skipBody   bool
+	writeTime  time.Duration // Time spent writing and flushing to the client
+
+	// Bodies larger than memoryLimit keep only their head in memory; the full body is
+	// spilled to a temp file and usage is extracted as the body passes through
+	memoryLimit int
+	spill       *os.File
+	spillFailed bool
+	size        int64
+	usage       *usage.StreamExtractor
 }
 
 // NewResponseCapture creates a new ResponseCapture wrapper
@@ -20,19 +42,85 @@ func NewResponseCapture(w http.ResponseWriter) *ResponseCapture {
 		ResponseWriter: w,
 		statusCode:     http.StatusOK, // Default status
 		headers:        make(http.Header),
+		usage:          usage.NewStreamExtractor(),
 	}
 }
 
+// NewStatusCapture creates a ResponseCapture that only records status and headers.
+// Used for passthrough streams, where buffering the whole body is what we want to avoid.
+func NewStatusCapture(w http.ResponseWriter) *ResponseCapture {
+	rc := NewResponseCapture(w)
+	rc.skipBody = true
+	return rc
+}
+
+// SetMemoryLimit sets how many bytes of the body are kept in memory (0 = no limit)
+func (rc *ResponseCapture) SetMemoryLimit(n int) {
+	rc.memoryLimit = n
+}
+
+// captureMemoryLimit returns the configured in-memory capture limit in bytes
+func captureMemoryLimit() int {
+	mb := defaultCaptureMemoryMB
+	if n, err := strconv.Atoi(getSetting(domain.SettingKeyResponseCaptureMaxMB)); err == nil && n >= 0 {
+		mb = n
+	}
+	return mb << 20
+}
+
 // WriteHeader captures the status code and forwards to underlying writer
 func (rc *ResponseCapture) WriteHeader(code int) {
 	rc.statusCode = code
+	rc.written = true
 	rc.ResponseWriter.WriteHeader(code)
 }
 
 // Write captures the body and forwards to underlying writer
 func (rc *ResponseCapture) Write(b []byte) (int, error) {
-	rc.body.Write(b)
-	return rc.ResponseWriter.Write(b)
+	rc.written = true
+	if !rc.skipBody {
+		rc.capture(b)
+	}
+	start := time.Now()
+	n, err := rc.ResponseWriter.Write(b)
+	rc.writeTime += time.Since(start)
+	return n, err
+}
+
+func (rc *ResponseCapture) capture(b []byte) {
+	rc.size += int64(len(b))
+	rc.usage.Write(b)
+	if rc.spillFailed {
+		return
+	}
+
+	if rc.spill == nil && (rc.memoryLimit <= 0 || rc.body.Len()+len(b) <= rc.memoryLimit) {
+		rc.body.Write(b)
+		return
+	}
+	if rc.spill == nil {
+		f, err := os.CreateTemp("", "maxx-response-*")
+		if err != nil {
+			log.Printf("[Executor] Failed to spill response body: %v", err)
+		} else if _, err := f.Write(rc.body.Bytes()); err != nil {
+			log.Printf("[Executor] Failed to spill response body: %v", err)
+			f.Close()
+			os.Remove(f.Name())
+		} else {
+			rc.spill = f
+		}
+		// Keep the head in memory for the request record
+		if free := rc.memoryLimit - rc.body.Len(); free > 0 {
+			rc.body.Write(b[:min(free, len(b))])
+		}
+		if rc.spill == nil {
+			rc.spillFailed = true
+			return
+		}
+	}
+	if _, err := rc.spill.Write(b); err != nil {
+		log.Printf("[Executor] Failed to spill response body: %v", err)
+	}
 }
 
 // Header returns the header map (for setting headers)
@@ -43,7 +131,9 @@ func (rc *ResponseCapture) Header() http.Header {
 // Flush implements http.Flusher for streaming support
 func (rc *ResponseCapture) Flush() {
 	if f, ok := rc.ResponseWriter.(http.Flusher); ok {
+		start := time.Now()
 		f.Flush()
+		rc.writeTime += time.Since(start)
 	}
 }
 
@@ -52,11 +142,71 @@ func (rc *ResponseCapture) StatusCode() int {
 	return rc.statusCode
 }
 
-// Body returns the captured response body
+// Written reports whether a response was started
+func (rc *ResponseCapture) Written() bool {
+	return rc.written
+}
+
+// WriteTime returns the total time spent writing and flushing to the client
+func (rc *ResponseCapture) WriteTime() time.Duration {
+	return rc.writeTime
+}
+
+// Body returns the captured response body. Bodies over the memory limit are cut to
+// their head, see Truncated.
 func (rc *ResponseCapture) Body() string {
 	return rc.body.String()
 }
 
+// Truncated reports whether Body holds only the head of a body over the memory limit
+func (rc *ResponseCapture) Truncated() bool {
+	return int64(rc.body.Len()) < rc.size
+}
+
+// ResponseInfo returns the captured response for the request record
+func (rc *ResponseCapture) ResponseInfo() *domain.ResponseInfo {
+	info := &domain.ResponseInfo{
+		Status:  rc.statusCode,
+		Headers: rc.CapturedHeaders(),
+		Body:    rc.Body(),
+	}
+	if rc.Truncated() {
+		info.BodyTruncated = true
+		info.BodySize = rc.size
+	}
+	return info
+}
+
+// OpenBody returns a reader over the full captured body, read from the spill file if
+// the body exceeded the memory limit. If spilling failed only the head is available.
+func (rc *ResponseCapture) OpenBody() (io.ReadCloser, error) {
+	if rc.spill == nil {
+		return io.NopCloser(bytes.NewReader(rc.body.Bytes())), nil
+	}
+	return os.Open(rc.spill.Name())
+}
+
+// Usage returns the token usage of the captured body, extracted as it was written
+func (rc *ResponseCapture) Usage() *usage.Metrics {
+	if m := rc.usage.Metrics(); m != nil && !m.IsEmpty() {
+		return m
+	}
+	if rc.size > 0 && int64(rc.body.Len()) == rc.size {
+		// Usage the incremental extractor missed, e.g. not at the end of a large JSON body
+		return usage.ExtractFromResponse(rc.body.String())
+	}
+	return nil
+}
+
+// Close removes the spill file, if any
+func (rc *ResponseCapture) Close() {
+	if rc.spill != nil {
+		rc.spill.Close()
+		os.Remove(rc.spill.Name())
+		rc.spill = nil
+	}
+}
+
 // CapturedHeaders returns the headers that were set
 func (rc *ResponseCapture) CapturedHeaders() map[string]string {
 	result := make(map[string]string)
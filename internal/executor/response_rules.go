@@ -0,0 +1,137 @@
+package executor
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// responseRules returns the response post-processing rules of a provider, or nil if it has none
+func responseRules(p *domain.Provider) *domain.ProviderResponseRules {
+	if p == nil || p.Config == nil || p.Config.ResponseRules.IsEmpty() {
+		return nil
+	}
+	return p.Config.ResponseRules
+}
+
+// newResponseRulesWriter applies a provider's response rules to the client response;
+// requestModel is the model the client asked for
+func newResponseRulesWriter(w http.ResponseWriter, rules *domain.ProviderResponseRules, requestModel string, stream bool) *jsonRewriteWriter {
+	return newJSONRewriteWriter(w, stream, func(v map[string]interface{}) bool {
+		return applyResponseRules(v, rules, requestModel)
+	})
+}
+
+// applyResponseRules applies the rules to a response body or stream event of any client
+// format; reports whether v changed
+func applyResponseRules(v map[string]interface{}, rules *domain.ProviderResponseRules, requestModel string) bool {
+	changed := false
+	for _, path := range rules.RemoveFields {
+		if removeField(v, strings.Split(path, ".")) {
+			changed = true
+		}
+	}
+	if rules.RewriteModel && requestModel != "" && rewriteModel(v, requestModel) {
+		changed = true
+	}
+	if len(rules.StripText) > 0 && stripText(v, rules.StripText) {
+		changed = true
+	}
+	return changed
+}
+
+// removeField deletes the field at path; arrays on the way apply the rest of the path to
+// each element
+func removeField(v interface{}, path []string) bool {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			if _, ok := node[path[0]]; !ok {
+				return false
+			}
+			delete(node, path[0])
+			return true
+		}
+		child, ok := node[path[0]]
+		return ok && removeField(child, path[1:])
+	case []interface{}:
+		changed := false
+		for _, item := range node {
+			if removeField(item, path) {
+				changed = true
+			}
+		}
+		return changed
+	}
+	return false
+}
+
+// modelContainers are the objects that carry the model of a response besides the top level:
+// Claude's message_start message and the Responses API's response
+var modelContainers = []string{"message", "response"}
+
+// rewriteModel sets the model names of a response (model, Gemini's modelVersion) to model
+func rewriteModel(v map[string]interface{}, model string) bool {
+	changed := false
+	for _, key := range []string{"model", "modelVersion"} {
+		if current, ok := v[key].(string); ok && current != model {
+			v[key] = model
+			changed = true
+		}
+	}
+	for _, key := range modelContainers {
+		if inner, ok := v[key].(map[string]interface{}); ok && rewriteModel(inner, model) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// textKeys are the fields holding generated text in the client formats: text (Claude,
+// Gemini, Responses API parts), content (OpenAI messages and deltas) and delta (Responses
+// API text deltas)
+var textKeys = map[string]bool{"text": true, "content": true, "delta": true}
+
+// toolArgumentKeys hold tool call arguments (Claude's input, Gemini's args), which are
+// left alone even if they have text fields
+var toolArgumentKeys = map[string]bool{"input": true, "args": true}
+
+// stripText removes the watermarks from every text field of v
+func stripText(v interface{}, watermarks []string) bool {
+	changed := false
+	switch node := v.(type) {
+	case map[string]interface{}:
+		for key, child := range node {
+			if toolArgumentKeys[key] {
+				continue
+			}
+			if s, ok := child.(string); ok {
+				if !textKeys[key] {
+					continue
+				}
+				stripped := s
+				for _, mark := range watermarks {
+					if mark != "" {
+						stripped = strings.ReplaceAll(stripped, mark, "")
+					}
+				}
+				if stripped != s {
+					node[key] = stripped
+					changed = true
+				}
+				continue
+			}
+			if stripText(child, watermarks) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, item := range node {
+			if stripText(item, watermarks) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
@@ -0,0 +1,18 @@
+package executor
+
+import (
+	"math/rand"
+	"strconv"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// sampleRouterDecision reports whether the router decision of a request is recorded
+// (see the router_decision_sample_rate setting)
+func sampleRouterDecision() bool {
+	rate, err := strconv.ParseFloat(getSetting(domain.SettingKeyRouterDecisionSampleRate), 64)
+	if err != nil || rate <= 0 {
+		return false
+	}
+	return rate >= 1 || rand.Float64() < rate
+}
@@ -0,0 +1,32 @@
+package executor
+
+import "sync"
+
+var (
+	settingsMu     sync.RWMutex
+	settingsGetter func(key string) (string, error)
+)
+
+// SetSettingsGetter sets the function used to read system settings at request time.
+// This should be called during application initialization.
+func SetSettingsGetter(getter func(key string) (string, error)) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	settingsGetter = getter
+}
+
+// getSetting returns a system setting, or "" if unset or no getter is configured
+func getSetting(key string) string {
+	settingsMu.RLock()
+	getter := settingsGetter
+	settingsMu.RUnlock()
+
+	if getter == nil {
+		return ""
+	}
+	val, err := getter(key)
+	if err != nil {
+		return ""
+	}
+	return val
+}
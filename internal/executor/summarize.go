@@ -0,0 +1,383 @@
+package executor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/contextguard"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/pricing"
+	"github.com/awsl-project/maxx/internal/quota"
+	"github.com/awsl-project/maxx/internal/router"
+	"github.com/awsl-project/maxx/internal/usage"
+)
+
+// summaryPrompt is the system prompt of the internal summarization request
+const summaryPrompt = "You compress conversation history for an AI assistant. " +
+	"Summarize the conversation below so the assistant can continue the task without the original messages. " +
+	"Keep the user's goals and requirements, decisions made, important facts, file paths, identifiers, " +
+	"relevant code, significant tool results, and open questions or next steps. " +
+	"Be concise and factual, and write in the language of the conversation. Output only the summary."
+
+const (
+	// defaultSummaryKeepTurns is how many recent turns are kept verbatim
+	defaultSummaryKeepTurns = 4
+	// summaryMaxTokens caps the length of a generated summary
+	summaryMaxTokens = 4096
+	// maxCachedSummaries bounds the per-session summary cache
+	maxCachedSummaries = 1024
+)
+
+// historySummary is the latest summary of a session: it replaces the conversation items
+// before cut, as long as those items still hash to hash.
+type historySummary struct {
+	cut     int
+	hash    string
+	summary string
+	usedAt  time.Time
+}
+
+var (
+	summaryMu    sync.Mutex
+	summaryCache = make(map[string]*historySummary)
+)
+
+// summarizeHistory replaces the older turns of a long conversation with a summary generated
+// by the configured summary model, keeping the most recent turns verbatim. Clients resend the
+// full history every time, so the summary is cached per session and reused (or extended with
+// only the new turns) while the summarized head is unchanged.
+// The summarization requests are recorded as upstream attempts of proxyReq.
+// On any failure the original body is returned and the request proceeds unchanged.
+func (e *Executor) summarizeHistory(ctx context.Context, req *http.Request, proxyReq *domain.ProxyRequest, body []byte) []byte {
+	threshold, _ := strconv.Atoi(strings.TrimSpace(getSetting(domain.SettingKeyHistorySummaryThreshold)))
+	model := strings.TrimSpace(getSetting(domain.SettingKeyHistorySummaryModel))
+	if threshold <= 0 || model == "" {
+		return body
+	}
+	estimated := contextguard.EstimateTokens(body)
+	if estimated <= threshold {
+		return body
+	}
+	keep, err := strconv.Atoi(strings.TrimSpace(getSetting(domain.SettingKeyHistorySummaryKeepTurns)))
+	if err != nil || keep <= 0 {
+		keep = defaultSummaryKeepTurns
+	}
+
+	clientType := ctxutil.GetClientType(ctx)
+	conv, ok := contextguard.ParseConversation(clientType, body)
+	if !ok || len(conv.TurnStarts) <= keep {
+		return body
+	}
+	cut := conv.TurnStarts[len(conv.TurnStarts)-keep]
+
+	sessionID := ctxutil.GetSessionID(ctx)
+	prev := lookupSummary(sessionID, conv)
+	if prev != nil {
+		head := contextguard.SummaryHead(clientType, prev.summary)
+		if prev.cut >= cut || conv.EstimateWith(prev.cut, head) <= threshold {
+			return buildSummarized(conv, prev.cut, head, body, estimated)
+		}
+	}
+
+	// Only the turns after the previous summary need to be read again
+	from, transcript := 0, ""
+	if prev != nil {
+		from = prev.cut
+		transcript = "Summary of the conversation so far:\n" + prev.summary + "\n\nLater messages:\n\n"
+	}
+	transcript += conv.Transcript(from, cut)
+
+	summary, err := e.generateSummary(ctx, req, proxyReq, model, transcript)
+	if err != nil {
+		log.Printf("[Executor] History summary failed, forwarding full history: %v", err)
+		return body
+	}
+	storeSummary(sessionID, &historySummary{cut: cut, hash: conv.HeadHash(cut), summary: summary})
+	return buildSummarized(conv, cut, contextguard.SummaryHead(clientType, summary), body, estimated)
+}
+
+func buildSummarized(conv *contextguard.Conversation, cut int, head []interface{}, body []byte, estimated int) []byte {
+	out, err := conv.Build(cut, head...)
+	if err != nil {
+		log.Printf("[Executor] History summary: failed to rebuild request: %v", err)
+		return body
+	}
+	log.Printf("[Executor] History summary: replaced %d messages (~%d -> ~%d tokens)",
+		cut, estimated, contextguard.EstimateTokens(out))
+	return out
+}
+
+// lookupSummary returns the cached summary of sessionID if it still matches conv
+func lookupSummary(sessionID string, conv *contextguard.Conversation) *historySummary {
+	if sessionID == "" {
+		return nil
+	}
+	summaryMu.Lock()
+	cached, ok := summaryCache[sessionID]
+	if ok {
+		cached.usedAt = time.Now()
+	}
+	summaryMu.Unlock()
+	if !ok || cached.cut > len(conv.Items) || conv.HeadHash(cached.cut) != cached.hash {
+		return nil
+	}
+	return cached
+}
+
+func storeSummary(sessionID string, s *historySummary) {
+	if sessionID == "" {
+		return
+	}
+	s.usedAt = time.Now()
+
+	summaryMu.Lock()
+	defer summaryMu.Unlock()
+	if _, ok := summaryCache[sessionID]; !ok && len(summaryCache) >= maxCachedSummaries {
+		// Evict the least recently used session
+		var oldestID string
+		var oldest time.Time
+		for id, entry := range summaryCache {
+			if oldestID == "" || entry.usedAt.Before(oldest) {
+				oldestID, oldest = id, entry.usedAt
+			}
+		}
+		delete(summaryCache, oldestID)
+	}
+	summaryCache[sessionID] = s
+}
+
+// generateSummary sends transcript to the summary model through the routes of the
+// current client type, trying each route once.
+func (e *Executor) generateSummary(ctx context.Context, req *http.Request, proxyReq *domain.ProxyRequest, model, transcript string) (string, error) {
+	routes, err := e.router.Match(&router.MatchContext{
+		ClientType:   ctxutil.GetClientType(ctx),
+		ProjectID:    ctxutil.GetProjectID(ctx),
+		RequestModel: model,
+		APITokenID:   ctxutil.GetAPITokenID(ctx),
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(routes) == 0 {
+		return "", domain.ErrNoRoutes
+	}
+
+	var lastErr error
+	for _, route := range routes {
+		summary, err := e.requestSummary(ctx, req, proxyReq, route, model, transcript)
+		if err == nil {
+			return summary, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return "", lastErr
+}
+
+// requestSummary performs one non-streaming summarization request on route.
+// The request is built in Claude format and converted if the provider needs another format.
+// It is recorded as an upstream attempt of proxyReq, so its usage and cost show up in the
+// stats, and its failures put the provider into cooldown like any other attempt.
+func (e *Executor) requestSummary(ctx context.Context, req *http.Request, proxyReq *domain.ProxyRequest, route *router.MatchedRoute, model, transcript string) (summary string, err error) {
+	clientType := ctxutil.GetClientType(ctx)
+	mappedModel := e.mapModel(model, route.Route, route.Provider, clientType, ctxutil.GetProjectID(ctx), ctxutil.GetAPITokenID(ctx))
+
+	body, err := json.Marshal(map[string]interface{}{
+		"model":      mappedModel,
+		"max_tokens": summaryMaxTokens,
+		"stream":     false,
+		"system":     summaryPrompt,
+		"messages": []interface{}{
+			map[string]interface{}{"role": "user", "content": transcript},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	targetType := domain.ClientTypeClaude
+	uri := "/v1/messages"
+	supportedTypes := route.ProviderAdapter.SupportedClientTypes()
+	if e.converter.NeedConvert(domain.ClientTypeClaude, supportedTypes) {
+		targetType = GetPreferredTargetType(supportedTypes, domain.ClientTypeClaude)
+		body, err = e.converter.TransformRequest(domain.ClientTypeClaude, targetType, body, mappedModel, false)
+		if err != nil {
+			return "", err
+		}
+		switch targetType {
+		case domain.ClientTypeGemini:
+			uri = "/v1beta/models/" + mappedModel + ":generateContent"
+		case domain.ClientTypeCodex:
+			uri = "/v1/responses"
+		default:
+			uri = ConvertRequestURI(uri, domain.ClientTypeClaude, targetType)
+		}
+	}
+
+	headers := ctxutil.GetRequestHeaders(ctx).Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	headers.Del("Content-Length")
+	headers.Del("Accept-Encoding")
+	if targetType == domain.ClientTypeClaude && headers.Get("anthropic-version") == "" {
+		headers.Set("anthropic-version", "2023-06-01")
+	}
+
+	summaryCtx := ctxutil.WithClientType(ctx, targetType)
+	summaryCtx = ctxutil.WithOriginalClientType(summaryCtx, domain.ClientTypeClaude)
+	summaryCtx = ctxutil.WithRequestModel(summaryCtx, model)
+	summaryCtx = ctxutil.WithMappedModel(summaryCtx, mappedModel)
+	summaryCtx = ctxutil.WithRequestBody(summaryCtx, body)
+	summaryCtx = ctxutil.WithRequestURI(summaryCtx, uri)
+	summaryCtx = ctxutil.WithRequestHeaders(summaryCtx, headers)
+	summaryCtx = ctxutil.WithIsStream(summaryCtx, false)
+
+	attempt := e.startSummaryAttempt(proxyReq, route, model, mappedModel)
+	summaryCtx = ctxutil.WithUpstreamAttempt(summaryCtx, attempt)
+	eventChan := domain.NewAdapterEventChan()
+	summaryCtx = ctxutil.WithEventChan(summaryCtx, eventChan)
+	eventDone := make(chan struct{})
+	go e.processAdapterEventsRealtime(eventChan, attempt, nil, eventDone)
+	defer func() {
+		e.finishSummaryAttempt(summaryCtx, proxyReq, route.Provider, attempt, err)
+	}()
+
+	buf := newBufferedResponseWriter()
+	var w http.ResponseWriter = buf
+	var convertingWriter *ConvertingResponseWriter
+	if targetType != domain.ClientTypeClaude {
+		convertingWriter = NewConvertingResponseWriter(buf, e.converter, domain.ClientTypeClaude, targetType, false)
+		w = convertingWriter
+	}
+
+	err = route.ProviderAdapter.Execute(summaryCtx, w, req, route.Provider)
+	eventChan.Close()
+	<-eventDone
+	if err != nil {
+		return "", err
+	}
+	if convertingWriter != nil {
+		if err := convertingWriter.Finalize(); err != nil {
+			return "", err
+		}
+	}
+	if buf.status >= http.StatusBadRequest {
+		return "", fmt.Errorf("summary request returned status %d", buf.status)
+	}
+
+	var resp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(buf.body.Bytes(), &resp); err != nil {
+		return "", fmt.Errorf("invalid summary response: %w", err)
+	}
+	var sb strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			sb.WriteString(block.Text)
+		}
+	}
+	summary = strings.TrimSpace(sb.String())
+	if summary == "" {
+		return "", fmt.Errorf("empty summary from %s", route.Provider.Name)
+	}
+	log.Printf("[Executor] History summary generated by %s (%s), %d chars", route.Provider.Name, mappedModel, len(summary))
+	return summary, nil
+}
+
+// startSummaryAttempt records the start of a summarization request as an attempt of proxyReq
+func (e *Executor) startSummaryAttempt(proxyReq *domain.ProxyRequest, route *router.MatchedRoute, model, mappedModel string) *domain.ProxyUpstreamAttempt {
+	attempt := &domain.ProxyUpstreamAttempt{
+		ProxyRequestID: proxyReq.ID,
+		RouteID:        route.Route.ID,
+		ProviderID:     route.Provider.ID,
+		Status:         "IN_PROGRESS",
+		StartTime:      e.clock.Now(),
+		RequestModel:   model,
+		MappedModel:    mappedModel,
+	}
+	if err := e.attemptRepo.Create(attempt); err != nil {
+		log.Printf("[Executor] Failed to create summary attempt record: %v", err)
+	}
+	proxyReq.ProxyUpstreamAttemptCount++
+	if e.broadcaster != nil {
+		e.broadcaster.BroadcastProxyRequest(proxyReq)
+		e.broadcaster.BroadcastProxyUpstreamAttempt(attempt)
+	}
+	return attempt
+}
+
+// finishSummaryAttempt records the outcome and cost of a summarization attempt, adds the
+// cost to proxyReq and applies the cooldown of a failed one
+func (e *Executor) finishSummaryAttempt(ctx context.Context, proxyReq *domain.ProxyRequest, provider *domain.Provider, attempt *domain.ProxyUpstreamAttempt, err error) {
+	attempt.EndTime = e.clock.Now()
+	attempt.Duration = attempt.EndTime.Sub(attempt.StartTime)
+	attempt.Status = "COMPLETED"
+	if err != nil {
+		attempt.Status = "FAILED"
+		if ctx.Err() != nil {
+			attempt.Status = "CANCELLED"
+			attempt.ErrorCode = domain.ErrorCodeClientAbort
+		}
+	}
+	if attempt.InputTokenCount > 0 || attempt.OutputTokenCount > 0 {
+		attempt.Cost = pricing.GlobalCalculator().Calculate(attempt.MappedModel, &usage.Metrics{
+			InputTokens:          attempt.InputTokenCount,
+			OutputTokens:         attempt.OutputTokenCount,
+			CacheReadCount:       attempt.CacheReadCount,
+			CacheCreationCount:   attempt.CacheWriteCount,
+			Cache5mCreationCount: attempt.Cache5mWriteCount,
+			Cache1hCreationCount: attempt.Cache1hWriteCount,
+		})
+		proxyReq.Cost += attempt.Cost
+	}
+	_ = e.attemptRepo.Update(attempt)
+	if e.broadcaster != nil {
+		e.broadcaster.BroadcastProxyUpstreamAttempt(attempt)
+	}
+	quota.Default().Record(provider, attempt.InputTokenCount+attempt.OutputTokenCount)
+
+	if proxyErr, ok := err.(*domain.ProxyError); ok && ctx.Err() == nil {
+		e.handleCooldown(ctx, proxyErr, provider)
+	}
+}
+
+// bufferedResponseWriter collects a response in memory for internal requests
+type bufferedResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newBufferedResponseWriter() *bufferedResponseWriter {
+	return &bufferedResponseWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+func (b *bufferedResponseWriter) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponseWriter) WriteHeader(code int) {
+	b.status = code
+}
+
+func (b *bufferedResponseWriter) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponseWriter) Flush() {}
@@ -0,0 +1,160 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// rewriteSystemPrompt applies a route's system prompt option to a client-format request
+// body. It reports whether the body changed; bodies without a system prompt and bodies
+// that are not JSON are returned as they are.
+func rewriteSystemPrompt(clientType domain.ClientType, body []byte, cfg *domain.RouteSystemPrompt) ([]byte, bool) {
+	if cfg == nil || cfg.Mode == domain.SystemPromptKeep {
+		return body, false
+	}
+	var data map[string]interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return body, false
+	}
+
+	var changed bool
+	switch clientType {
+	case domain.ClientTypeClaude:
+		changed = rewriteField(data, "system", cfg, func(text string) interface{} { return text })
+	case domain.ClientTypeCodex:
+		changed = rewriteField(data, "instructions", cfg, func(text string) interface{} { return text })
+	case domain.ClientTypeOpenAI:
+		changed = rewriteOpenAISystem(data, cfg)
+	case domain.ClientTypeGemini:
+		parts := func(text string) interface{} {
+			return map[string]interface{}{"parts": []interface{}{map[string]interface{}{"text": text}}}
+		}
+		changed = rewriteField(data, "systemInstruction", cfg, parts)
+		changed = rewriteField(data, "system_instruction", cfg, parts) || changed
+	}
+	if !changed {
+		return body, false
+	}
+
+	out, err := json.Marshal(data)
+	if err != nil {
+		return body, false
+	}
+	return out, true
+}
+
+// rewriteField rewrites the system prompt held in data[key]; wrap builds the field's
+// value from the new text
+func rewriteField(data map[string]interface{}, key string, cfg *domain.RouteSystemPrompt, wrap func(string) interface{}) bool {
+	v, ok := data[key]
+	if !ok {
+		return false
+	}
+	original := promptText(v)
+	text, keep := transformPrompt(original, cfg)
+	if !keep {
+		delete(data, key)
+		return true
+	}
+	if text == original {
+		return false
+	}
+	data[key] = wrap(text)
+	return true
+}
+
+// rewriteOpenAISystem merges the system and developer messages into one rewritten system
+// message at the start of the conversation
+func rewriteOpenAISystem(data map[string]interface{}, cfg *domain.RouteSystemPrompt) bool {
+	messages, ok := data["messages"].([]interface{})
+	if !ok {
+		return false
+	}
+	var texts []string
+	rest := make([]interface{}, 0, len(messages))
+	for _, m := range messages {
+		msg, ok := m.(map[string]interface{})
+		if role, _ := msg["role"].(string); ok && (role == "system" || role == "developer") {
+			texts = append(texts, promptText(msg["content"]))
+			continue
+		}
+		rest = append(rest, m)
+	}
+	if len(texts) == 0 {
+		return false
+	}
+
+	if text, keep := transformPrompt(strings.Join(texts, "\n\n"), cfg); keep {
+		rest = append([]interface{}{map[string]interface{}{"role": "system", "content": text}}, rest...)
+	}
+	data["messages"] = rest
+	return true
+}
+
+// transformPrompt returns the new system prompt; keep is false if it should be removed
+func transformPrompt(text string, cfg *domain.RouteSystemPrompt) (string, bool) {
+	switch cfg.Mode {
+	case domain.SystemPromptStrip:
+		return "", false
+	case domain.SystemPromptTruncate:
+		if cfg.MaxChars <= 0 {
+			return "", false
+		}
+		if runes := []rune(text); len(runes) > cfg.MaxChars {
+			return string(runes[:cfg.MaxChars]), true
+		}
+		return text, true
+	case domain.SystemPromptHash:
+		sum := sha256.Sum256([]byte(text))
+		return "sha256:" + hex.EncodeToString(sum[:]), true
+	case domain.SystemPromptReplace:
+		if cfg.Replacement == "" {
+			return "", false
+		}
+		return cfg.Replacement, true
+	}
+	return text, true
+}
+
+// promptText returns the text of a system prompt value: a string, a list of content
+// blocks or a Gemini content object with parts
+func promptText(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case []interface{}:
+		var texts []string
+		for _, item := range val {
+			switch block := item.(type) {
+			case string:
+				texts = append(texts, block)
+			case map[string]interface{}:
+				if text, ok := block["text"].(string); ok {
+					texts = append(texts, text)
+				}
+			}
+		}
+		return strings.Join(texts, "\n\n")
+	case map[string]interface{}:
+		return promptText(val["parts"])
+	}
+	return ""
+}
+
+// identityPatchOption returns a route's identity patch as a converter option: nil for the
+// default patch, "" when the route disables it
+func identityPatchOption(cfg *domain.RouteIdentityPatch, vars converter.IdentityPatchVars) *string {
+	if cfg == nil || (!cfg.Disabled && cfg.Template == "") {
+		return nil
+	}
+	patch := ""
+	if !cfg.Disabled {
+		patch = converter.RenderIdentityPatch(cfg.Template, vars)
+	}
+	return &patch
+}
@@ -0,0 +1,152 @@
+package executor
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sync"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/router"
+)
+
+// maxToolUseIDAliases bounds the remembered renamed tool use IDs; the oldest are forgotten first
+const maxToolUseIDAliases = 10000
+
+// toolUseIDAliases maps the IDs given to repeated tool calls to the upstream's original IDs
+var toolUseIDAliases = &aliasMap{aliases: make(map[string]string)}
+
+type aliasMap struct {
+	mu      sync.Mutex
+	aliases map[string]string
+	order   []string
+}
+
+func (m *aliasMap) add(alias, original string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.order) >= maxToolUseIDAliases {
+		delete(m.aliases, m.order[0])
+		m.order = m.order[1:]
+	}
+	m.aliases[alias] = original
+	m.order = append(m.order, alias)
+}
+
+func (m *aliasMap) original(alias string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	original, ok := m.aliases[alias]
+	return original, ok
+}
+
+// claudeGoesToGemini reports whether a route sends Claude requests to Gemini, which may
+// repeat function call IDs within a response: directly or through Antigravity
+func (e *Executor) claudeGoesToGemini(route *router.MatchedRoute) bool {
+	if route.Provider.Type == "antigravity" {
+		return true
+	}
+	supported := route.ProviderAdapter.SupportedClientTypes()
+	return e.converter.NeedConvert(domain.ClientTypeClaude, supported) &&
+		GetPreferredTargetType(supported, domain.ClientTypeClaude) == domain.ClientTypeGemini
+}
+
+// restoreToolUseIDs gives the tool_use and tool_result blocks of a Claude request whose
+// IDs were renamed in an earlier response their upstream IDs back
+func restoreToolUseIDs(body []byte) []byte {
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return body
+	}
+	messages, _ := req["messages"].([]interface{})
+	changed := false
+	for _, m := range messages {
+		msg, _ := m.(map[string]interface{})
+		blocks, _ := msg["content"].([]interface{})
+		for _, b := range blocks {
+			block, _ := b.(map[string]interface{})
+			key := ""
+			switch block["type"] {
+			case "tool_use":
+				key = "id"
+			case "tool_result":
+				key = "tool_use_id"
+			default:
+				continue
+			}
+			id, _ := block[key].(string)
+			if original, ok := toolUseIDAliases.original(id); ok {
+				block[key] = original
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return body
+	}
+	restored, err := json.Marshal(req)
+	if err != nil {
+		return body
+	}
+	return restored
+}
+
+// newToolUseID returns a fresh Claude-style tool use ID
+func newToolUseID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "toolu_" + hex.EncodeToString(b)
+}
+
+// toolUseIDWriter validates the tool_use IDs of a Claude response and renames repeated ones,
+// remembering the original IDs for the requests that return their results. Streams are
+// checked event by event; non-streaming responses are held until Finish.
+// Error responses are passed through unchanged.
+type toolUseIDWriter struct {
+	*jsonRewriteWriter
+	seen map[string]bool
+}
+
+func newToolUseIDWriter(w http.ResponseWriter, stream bool) *toolUseIDWriter {
+	t := &toolUseIDWriter{seen: make(map[string]bool)}
+	t.jsonRewriteWriter = newJSONRewriteWriter(w, stream, t.checkResponse)
+	t.match = func(payload []byte) bool { return bytes.Contains(payload, []byte(`"content_block_start"`)) }
+	return t
+}
+
+// checkResponse renames repeated IDs in a content_block_start event or a complete message
+func (t *toolUseIDWriter) checkResponse(v map[string]interface{}) bool {
+	if block, ok := v["content_block"].(map[string]interface{}); ok {
+		return t.checkBlock(block)
+	}
+	changed := false
+	content, _ := v["content"].([]interface{})
+	for _, c := range content {
+		if block, ok := c.(map[string]interface{}); ok && t.checkBlock(block) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// checkBlock renames the ID of a tool_use block that was already used; reports whether it did
+func (t *toolUseIDWriter) checkBlock(block map[string]interface{}) bool {
+	if block["type"] != "tool_use" {
+		return false
+	}
+	id, _ := block["id"].(string)
+	if id == "" {
+		return false
+	}
+	if !t.seen[id] {
+		t.seen[id] = true
+		return false
+	}
+	alias := newToolUseID()
+	t.seen[alias] = true
+	toolUseIDAliases.add(alias, id)
+	block["id"] = alias
+	return true
+}
@@ -0,0 +1,147 @@
+package executor
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Trace stages, in the order a request goes through them
+const (
+	TraceStageClientRequest    = "client_request"    // Body as received from the client
+	TraceStageHistorySummary   = "history_summary"   // Body after history summarization
+	TraceStageContextGuard     = "context_guard"     // Body after the context-window guard dropped messages
+	TraceStageSystemPrompt     = "system_prompt"     // Body after the route's system prompt rewrite
+	TraceStageConvertedRequest = "converted_request" // Body after format conversion for the provider
+	TraceStageUpstreamRequest  = "upstream_request"  // Request as sent upstream by the adapter (wrapped, with auth)
+	TraceStageUpstreamResponse = "upstream_response" // Raw upstream response
+	TraceStageClientResponse   = "client_response"   // Response as sent to the client (after conversion)
+)
+
+// HeaderTraceID is set on traced responses to the ID of the proxy request, under which the
+// trace can be downloaded from /admin/requests/{id}/trace
+const HeaderTraceID = "X-Maxx-Trace-Id"
+
+// maxTraces bounds the number of traces kept in memory (traces hold full bodies)
+const maxTraces = 100
+
+// Trace records each transformation stage of a single request
+type Trace struct {
+	ProxyRequestID uint64            `json:"proxyRequestID"`
+	RequestID      string            `json:"requestID"`
+	ClientType     domain.ClientType `json:"clientType"`
+	RequestModel   string            `json:"requestModel"`
+	StartTime      time.Time         `json:"startTime"`
+	Stages         []TraceStage      `json:"stages"`
+
+	mu sync.Mutex
+}
+
+// TraceStage is one recorded stage of a trace
+type TraceStage struct {
+	Stage      string            `json:"stage"`
+	Time       time.Time         `json:"time"`
+	Attempt    uint64            `json:"attempt,omitempty"` // 1-based upstream attempt number
+	Provider   string            `json:"provider,omitempty"`
+	Format     domain.ClientType `json:"format,omitempty"`
+	Model      string            `json:"model,omitempty"`
+	Method     string            `json:"method,omitempty"`
+	URL        string            `json:"url,omitempty"`
+	StatusCode int               `json:"statusCode,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body"`
+}
+
+func newTrace(proxyReq *domain.ProxyRequest) *Trace {
+	return &Trace{
+		ProxyRequestID: proxyReq.ID,
+		RequestID:      proxyReq.RequestID,
+		ClientType:     proxyReq.ClientType,
+		RequestModel:   proxyReq.RequestModel,
+		StartTime:      proxyReq.StartTime,
+	}
+}
+
+// add records a stage
+func (t *Trace) add(stage TraceStage) {
+	stage.Time = time.Now()
+	t.mu.Lock()
+	t.Stages = append(t.Stages, stage)
+	t.mu.Unlock()
+}
+
+// addInfo records a stage from the request or response info captured by an adapter
+func (t *Trace) addInfo(stage string, attempt uint64, provider string, req *domain.RequestInfo, resp *domain.ResponseInfo) {
+	s := TraceStage{Stage: stage, Attempt: attempt, Provider: provider}
+	switch {
+	case req != nil:
+		s.Method, s.URL, s.Headers, s.Body = req.Method, req.URL, req.Headers, req.Body
+	case resp != nil:
+		s.StatusCode, s.Headers, s.Body = resp.Status, resp.Headers, resp.Body
+	default:
+		return
+	}
+	t.add(s)
+}
+
+// TraceStore keeps the traces of recent traced requests
+type TraceStore struct {
+	mu     sync.Mutex
+	traces map[uint64]*Trace
+	order  []uint64
+}
+
+var globalTraceStore = &TraceStore{traces: make(map[uint64]*Trace)}
+
+// GlobalTraceStore returns the store of recorded traces
+func GlobalTraceStore() *TraceStore {
+	return globalTraceStore
+}
+
+// Get returns the trace of a proxy request, or nil if it wasn't traced (or was evicted)
+func (s *TraceStore) Get(proxyRequestID uint64) *Trace {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.traces[proxyRequestID]
+}
+
+func (s *TraceStore) put(t *Trace) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.traces[t.ProxyRequestID]; !ok {
+		s.order = append(s.order, t.ProxyRequestID)
+	}
+	s.traces[t.ProxyRequestID] = t
+	for len(s.order) > maxTraces {
+		delete(s.traces, s.order[0])
+		s.order = s.order[1:]
+	}
+}
+
+// Snapshot returns a copy of the trace that is safe to serialize while the request is running
+func (t *Trace) Snapshot() *Trace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return &Trace{
+		ProxyRequestID: t.ProxyRequestID,
+		RequestID:      t.RequestID,
+		ClientType:     t.ClientType,
+		RequestModel:   t.RequestModel,
+		StartTime:      t.StartTime,
+		Stages:         append([]TraceStage(nil), t.Stages...),
+	}
+}
+
+// startTrace begins tracing a request if the client asked for it
+func startTrace(traced bool, w http.ResponseWriter, proxyReq *domain.ProxyRequest) *Trace {
+	if !traced || proxyReq.ID == 0 {
+		return nil
+	}
+	t := newTrace(proxyReq)
+	globalTraceStore.put(t)
+	w.Header().Set(HeaderTraceID, strconv.FormatUint(proxyReq.ID, 10))
+	return t
+}
@@ -0,0 +1,301 @@
+// Package failback moves traffic back to a preferred provider after it failed over to a
+// backup. A provider that goes into cooldown is demoted: its routes are tried after all
+// others, even once the cooldown has expired, while it is re-probed in the background.
+// After enough consecutive successful probes its cooldown is cleared and it is promoted
+// back to its place in the route order. Providers that fail again shortly after recovering
+// need twice as many successful probes next time, so a flapping upstream does not keep
+// pulling traffic back and forth.
+package failback
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/cooldown"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+const (
+	defaultSuccessThreshold = 3
+	maxThresholdFactor      = 8                // Flapping raises the threshold up to 8x
+	flapWindow              = 10 * time.Minute // A failure this soon after recovering counts as flapping
+	probeTimeout            = 10 * time.Second
+	idleCheckInterval       = 30 * time.Second // How often the settings are re-read while disabled
+)
+
+// ProbeFunc checks whether a provider is healthy
+type ProbeFunc func(ctx context.Context, p *domain.Provider) error
+
+// Config is the failback configuration
+type Config struct {
+	ProbeInterval    time.Duration // 0 disables failback
+	SuccessThreshold int
+}
+
+// ConfigFromSettings reads the failback settings
+func ConfigFromSettings(get func(key string) (string, error)) Config {
+	cfg := Config{SuccessThreshold: defaultSuccessThreshold}
+	if v, err := get(domain.SettingKeyFailbackProbeInterval); err == nil {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			cfg.ProbeInterval = time.Duration(secs) * time.Second
+		}
+	}
+	if v, err := get(domain.SettingKeyFailbackSuccessThreshold); err == nil {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.SuccessThreshold = n
+		}
+	}
+	return cfg
+}
+
+type state struct {
+	demoted     bool
+	demotedAt   time.Time
+	successes   int // Consecutive successful probes
+	required    int // Successes needed to fail back
+	lastProbeAt time.Time
+	lastError   string
+	recoveredAt time.Time
+}
+
+// Manager tracks demoted providers
+type Manager struct {
+	mu        sync.Mutex
+	states    map[uint64]*state
+	config    func() Config
+	providers func() map[uint64]*domain.Provider
+	probe     ProbeFunc
+	started   bool
+	cooldowns *cooldown.Manager
+}
+
+// NewManager creates a manager; failback stays disabled until Start is called
+func NewManager() *Manager {
+	return &Manager{
+		states:    make(map[uint64]*state),
+		config:    func() Config { return Config{} },
+		cooldowns: cooldown.Default(),
+	}
+}
+
+var defaultManager = NewManager()
+
+// Default returns the global failback manager
+func Default() *Manager {
+	return defaultManager
+}
+
+func (m *Manager) currentConfig() Config {
+	m.mu.Lock()
+	config := m.config
+	m.mu.Unlock()
+	return config()
+}
+
+// Demote marks a provider as failed over. Called when a request to it fails with a server
+// or network error and puts it into cooldown; rate limits and exhausted quotas are not
+// health problems a probe could see the end of.
+func (m *Manager) Demote(providerID uint64) {
+	cfg := m.currentConfig()
+	if cfg.ProbeInterval <= 0 {
+		return
+	}
+	threshold := cfg.SuccessThreshold
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.states[providerID]
+	if !ok {
+		s = &state{}
+		m.states[providerID] = s
+	}
+	s.successes = 0
+	if s.demoted {
+		return
+	}
+	s.demoted = true
+	s.demotedAt = time.Now()
+	if !s.recoveredAt.IsZero() && time.Since(s.recoveredAt) < flapWindow && threshold > 0 {
+		// Failed again right after failing back: double the previous requirement, so
+		// repeated flapping keeps raising it
+		s.required = min(max(s.required, threshold)*2, threshold*maxThresholdFactor)
+	} else {
+		s.required = threshold
+	}
+	log.Printf("[Failback] Provider %d demoted, needs %d successful probes to fail back", providerID, s.required)
+}
+
+// ReportSuccess counts a successful request to a demoted provider like a successful probe
+func (m *Manager) ReportSuccess(providerID uint64) {
+	if m.IsDemoted(providerID) {
+		m.recordProbe(providerID, nil)
+	}
+}
+
+// IsDemoted reports whether a provider is waiting to fail back
+func (m *Manager) IsDemoted(providerID uint64) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.states[providerID]
+	return ok && s.demoted
+}
+
+// Reorder moves the routes of demoted providers behind all others, keeping the order
+// within both groups
+func (m *Manager) Reorder(routes []*domain.Route) []*domain.Route {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.states) == 0 {
+		return routes
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return !m.demotedLocked(routes[i].ProviderID) && m.demotedLocked(routes[j].ProviderID)
+	})
+	return routes
+}
+
+func (m *Manager) demotedLocked(providerID uint64) bool {
+	s, ok := m.states[providerID]
+	return ok && s.demoted
+}
+
+// Status returns the failback state of the providers seen failing over
+func (m *Manager) Status(providers map[uint64]*domain.Provider) []*domain.FailbackStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]*domain.FailbackStatus, 0, len(m.states))
+	for id, s := range m.states {
+		st := &domain.FailbackStatus{
+			ProviderID:           id,
+			Demoted:              s.demoted,
+			DemotedAt:            s.demotedAt,
+			ConsecutiveSuccesses: s.successes,
+			RequiredSuccesses:    s.required,
+			LastError:            s.lastError,
+		}
+		if p := providers[id]; p != nil {
+			st.ProviderName = p.Name
+		}
+		if !s.lastProbeAt.IsZero() {
+			t := s.lastProbeAt
+			st.LastProbeAt = &t
+		}
+		if !s.recoveredAt.IsZero() {
+			t := s.recoveredAt
+			st.RecoveredAt = &t
+		}
+		result = append(result, st)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ProviderID < result[j].ProviderID })
+	return result
+}
+
+// Promote fails a provider back immediately, clearing its cooldown
+func (m *Manager) Promote(providerID uint64) {
+	m.mu.Lock()
+	if s, ok := m.states[providerID]; ok && s.demoted {
+		s.demoted = false
+		s.successes = 0
+		s.recoveredAt = time.Now()
+	}
+	m.mu.Unlock()
+	m.cooldowns.ClearCooldown(providerID, "")
+}
+
+// Start runs the background prober. The configuration is re-read before every round, so
+// failback can be switched on and off at runtime. Calling Start again only replaces the
+// sources.
+func (m *Manager) Start(config func() Config, providers func() map[uint64]*domain.Provider, probe ProbeFunc) {
+	m.mu.Lock()
+	m.config, m.providers, m.probe = config, providers, probe
+	started := m.started
+	m.started = true
+	m.mu.Unlock()
+	if started {
+		return
+	}
+
+	go func() {
+		for {
+			cfg := m.currentConfig()
+			wait := cfg.ProbeInterval
+			if wait <= 0 {
+				wait = idleCheckInterval
+				m.reset()
+			}
+			time.Sleep(wait)
+			if cfg.ProbeInterval > 0 {
+				m.probeDemoted()
+			}
+		}
+	}()
+}
+
+// reset forgets all state (failback was disabled)
+func (m *Manager) reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.states) > 0 {
+		m.states = make(map[uint64]*state)
+	}
+}
+
+func (m *Manager) probeDemoted() {
+	m.mu.Lock()
+	providers, probe := m.providers(), m.probe
+	var ids []uint64
+	for id, s := range m.states {
+		if _, ok := providers[id]; !ok {
+			delete(m.states, id) // Provider was deleted
+			continue
+		}
+		if s.demoted {
+			ids = append(ids, id)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, id := range ids {
+		// Probes are diagnostics traffic: they must not count as the provider's own failures
+		probeCtx, cancel := context.WithTimeout(ctxutil.WithDiagnostic(context.Background(), true), probeTimeout)
+		err := probe(probeCtx, providers[id])
+		cancel()
+		m.recordProbe(id, err)
+	}
+}
+
+func (m *Manager) recordProbe(providerID uint64, err error) {
+	m.mu.Lock()
+	s, ok := m.states[providerID]
+	if !ok || !s.demoted {
+		m.mu.Unlock()
+		return
+	}
+	s.lastProbeAt = time.Now()
+	if err != nil {
+		s.successes = 0
+		s.lastError = err.Error()
+		m.mu.Unlock()
+		return
+	}
+	s.lastError = ""
+	s.successes++
+	recovered := s.successes >= s.required
+	if recovered {
+		s.demoted = false
+		s.successes = 0
+		s.recoveredAt = time.Now()
+	}
+	m.mu.Unlock()
+
+	if recovered {
+		log.Printf("[Failback] Provider %d is healthy again, failing back", providerID)
+		m.cooldowns.ClearCooldown(providerID, "")
+	}
+}
@@ -1,14 +1,27 @@
 package handler
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/awsl-project/maxx/internal/adapter/provider/antigravity"
+	"github.com/awsl-project/maxx/internal/audit"
+	ctxutil "github.com/awsl-project/maxx/internal/context"
 	"github.com/awsl-project/maxx/internal/cooldown"
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
+	"github.com/awsl-project/maxx/internal/executor"
+	"github.com/awsl-project/maxx/internal/importer"
+	"github.com/awsl-project/maxx/internal/quality"
 	"github.com/awsl-project/maxx/internal/repository"
 	"github.com/awsl-project/maxx/internal/service"
 )
@@ -16,18 +29,34 @@ import (
 // AdminHandler handles admin API requests over HTTP
 // Delegates business logic to AdminService
 type AdminHandler struct {
-	svc     *service.AdminService
-	logPath string
+	svc         *service.AdminService
+	logPath     string
+	broadcaster event.Broadcaster
+	executor    *executor.Executor // Runs batch evaluations; nil disables /admin/batch-runs
+	etags       *etagCache
+	recovery    *domain.RecoverySummary // Crash recovery at startup; nil if nothing was recovered
 }
 
 // NewAdminHandler creates a new admin handler
-func NewAdminHandler(svc *service.AdminService, logPath string) *AdminHandler {
+func NewAdminHandler(svc *service.AdminService, logPath string, broadcaster event.Broadcaster) *AdminHandler {
 	return &AdminHandler{
-		svc:     svc,
-		logPath: logPath,
+		svc:         svc,
+		logPath:     logPath,
+		broadcaster: broadcaster,
+		etags:       newETagCache(),
 	}
 }
 
+// SetExecutor sets the executor batch runs are sent through
+func (h *AdminHandler) SetExecutor(exec *executor.Executor) {
+	h.executor = exec
+}
+
+// SetRecoverySummary sets the crash recovery result served by /admin/recovery-summary
+func (h *AdminHandler) SetRecoverySummary(summary *domain.RecoverySummary) {
+	h.recovery = summary
+}
+
 // ServeHTTP routes admin requests
 func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/admin")
@@ -45,12 +74,40 @@ func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		id, _ = strconv.ParseUint(parts[2], 10, 64)
 	}
 
+	// GETs get ETags; successful writes are published as config changes, which
+	// invalidates the cached GET responses
+	switch r.Method {
+	case http.MethodGet:
+		h.etags.serve(w, r, etagCacheable(resource, parts), func(w http.ResponseWriter, r *http.Request) {
+			h.dispatch(w, r, resource, id, parts)
+		})
+	case http.MethodHead, http.MethodOptions:
+		h.dispatch(w, r, resource, id, parts)
+	default:
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		h.dispatch(rw, r, resource, id, parts)
+		if rw.statusCode < http.StatusBadRequest {
+			event.PublishConfigChange(resource)
+		}
+	}
+}
+
+// dispatch routes an admin request to the handler of its resource
+func (h *AdminHandler) dispatch(w http.ResponseWriter, r *http.Request, resource string, id uint64, parts []string) {
 	switch resource {
 	case "providers":
 		h.handleProviders(w, r, id)
 	case "routes":
 		if len(parts) > 2 && parts[2] == "batch-positions" {
 			h.handleBatchUpdateRoutePositions(w, r)
+		} else if len(parts) > 2 && parts[2] == "simulate" {
+			h.handleSimulateRoutes(w, r)
+		} else if len(parts) > 2 && parts[2] == "conversions" {
+			h.handleRouteConversions(w, r)
+		} else if len(parts) > 3 && parts[3] == "retry-config" && id > 0 {
+			h.handleEffectiveRetryConfig(w, r, id)
+		} else if len(parts) > 3 && parts[3] == "restore" && id > 0 {
+			h.handleRestoreRoute(w, r, id)
 		} else {
 			h.handleRoutes(w, r, id)
 		}
@@ -62,6 +119,12 @@ func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleRetryConfigs(w, r, id)
 	case "routing-strategies":
 		h.handleRoutingStrategies(w, r, id)
+	case "routing-profiles":
+		h.handleRoutingProfiles(w, r, id, parts)
+	case "cost-alerts":
+		h.handleCostAlerts(w, r, id)
+	case "latency-slos":
+		h.handleLatencySLOs(w, r, id)
 	case "requests":
 		h.handleProxyRequests(w, r, id, parts)
 	case "settings":
@@ -70,18 +133,50 @@ func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		h.handleProxyStatus(w, r)
 	case "provider-stats":
 		h.handleProviderStats(w, r)
+	case "request-shape-stats":
+		h.handleRequestShapeStats(w, r)
+	case "experiment-stats":
+		h.handleExperimentStats(w, r)
+	case "latency-slo-stats":
+		h.handleLatencySLOStats(w, r)
+	case "prompt-cache-advisor":
+		h.handlePromptCacheAdvisor(w, r)
+	case "provider-quotas":
+		h.handleProviderQuotas(w, r, id)
+	case "failback":
+		h.handleFailback(w, r, id)
 	case "cooldowns":
-		h.handleCooldowns(w, r, id)
+		if len(parts) > 2 && parts[2] == "stats" {
+			h.handleCooldownStats(w, r)
+		} else {
+			h.handleCooldowns(w, r, id)
+		}
 	case "logs":
 		h.handleLogs(w, r)
+	case "audit":
+		h.handleAudit(w, r)
 	case "api-tokens":
 		h.handleAPITokens(w, r, id)
 	case "model-mappings":
 		h.handleModelMappings(w, r, id)
+	case "model-capabilities":
+		h.handleModelCapabilities(w, r, id)
 	case "usage-stats":
 		h.handleUsageStats(w, r)
 	case "response-models":
 		h.handleResponseModels(w, r)
+	case "dashboard":
+		h.handleDashboard(w, r, parts)
+	case "health":
+		h.handleHealth(w, r, parts)
+	case "diagnostics":
+		h.handleDiagnostics(w, r, parts)
+	case "recovery-summary":
+		h.handleRecoverySummary(w, r)
+	case "batch-runs":
+		h.handleBatchRuns(w, r)
+	case "quality-samples":
+		h.handleQualitySamples(w, r, parts)
 	default:
 		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
 	}
@@ -99,6 +194,18 @@ func (h *AdminHandler) handleProviders(w http.ResponseWriter, r *http.Request, i
 		h.handleProvidersImport(w, r)
 		return
 	}
+	if strings.HasSuffix(path, "/import-external") {
+		h.handleProvidersImportExternal(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/batch") {
+		h.handleProvidersBatch(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/restore") && id > 0 {
+		h.handleRestoreProvider(w, r, id)
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
@@ -110,11 +217,29 @@ func (h *AdminHandler) handleProviders(w http.ResponseWriter, r *http.Request, i
 			}
 			writeJSON(w, http.StatusOK, provider)
 		} else {
-			providers, err := h.svc.GetProviders()
+			if q, ok := parseListQuery(r); ok {
+				page, err := h.svc.ListProviders(r.URL.Query().Get("deleted") == "true", r.URL.Query().Get("label"), q)
+				writeListPage(w, page, err)
+				return
+			}
+			getProviders := h.svc.GetProviders
+			if r.URL.Query().Get("deleted") == "true" {
+				getProviders = h.svc.GetDeletedProviders
+			}
+			providers, err := getProviders()
 			if err != nil {
 				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 				return
 			}
+			if label := r.URL.Query().Get("label"); label != "" {
+				filtered := make([]*domain.Provider, 0, len(providers))
+				for _, p := range providers {
+					if p.HasLabel(label) {
+						filtered = append(filtered, p)
+					}
+				}
+				providers = filtered
+			}
 			writeJSON(w, http.StatusOK, providers)
 		}
 	case http.MethodPost:
@@ -141,14 +266,26 @@ func (h *AdminHandler) handleProviders(w http.ResponseWriter, r *http.Request, i
 		}
 		// Decode the update - for Provider, we expect full object updates from the form,
 		// but we still need to preserve ID and timestamps
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
 		var provider domain.Provider
-		if err := json.NewDecoder(r.Body).Decode(&provider); err != nil {
+		if err := json.Unmarshal(body, &provider); err != nil {
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 			return
 		}
 		// Preserve ID and timestamps
 		provider.ID = existing.ID
 		provider.CreatedAt = existing.CreatedAt
+		// Forms that don't edit the retry config don't send it
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(body, &fields) == nil {
+			if _, ok := fields["retryConfigID"]; !ok {
+				provider.RetryConfigID = existing.RetryConfigID
+			}
+		}
 		if err := h.svc.UpdateProvider(&provider); err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 			return
@@ -169,6 +306,59 @@ func (h *AdminHandler) handleProviders(w http.ResponseWriter, r *http.Request, i
 	}
 }
 
+// handleProvidersBatch applies one action to many providers selected by IDs or label
+// POST /admin/providers/batch {ids, label, action, patch}
+func (h *AdminHandler) handleProvidersBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req service.ProviderBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+
+	result, err := h.svc.BatchProviders(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleRestoreProvider restores a soft-deleted provider and the routes deleted with it
+func (h *AdminHandler) handleRestoreProvider(w http.ResponseWriter, r *http.Request, id uint64) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	provider, err := h.svc.RestoreProvider(id)
+	if err != nil {
+		writeRestoreError(w, err, "provider not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, provider)
+}
+
+// writeRestoreError maps the errors of restoring a soft-deleted provider or route
+func writeRestoreError(w http.ResponseWriter, err error, notFound string) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": notFound})
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
 // handleProvidersExport exports all providers as JSON
 func (h *AdminHandler) handleProvidersExport(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -210,6 +400,55 @@ func (h *AdminHandler) handleProvidersImport(w http.ResponseWriter, r *http.Requ
 	writeJSON(w, http.StatusOK, result)
 }
 
+// handleProvidersImportExternal imports providers from CLIProxyAPI or Antigravity-Manager
+// configuration and token files. With dryRun set it only reports what would be created.
+func (h *AdminHandler) handleProvidersImportExternal(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var body struct {
+		Files  []importer.File `json:"files"`
+		DryRun bool            `json:"dryRun"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+	if len(body.Files) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "no files to import"})
+		return
+	}
+
+	result, err := h.svc.ImportExternalProviders(body.Files, body.DryRun)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleRestoreRoute restores a soft-deleted route
+func (h *AdminHandler) handleRestoreRoute(w http.ResponseWriter, r *http.Request, id uint64) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	route, err := h.svc.RestoreRoute(id)
+	if err != nil {
+		writeRestoreError(w, err, "route not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, route)
+}
+
 // Route handlers
 func (h *AdminHandler) handleRoutes(w http.ResponseWriter, r *http.Request, id uint64) {
 	switch r.Method {
@@ -222,7 +461,16 @@ func (h *AdminHandler) handleRoutes(w http.ResponseWriter, r *http.Request, id u
 			}
 			writeJSON(w, http.StatusOK, route)
 		} else {
-			routes, err := h.svc.GetRoutes()
+			if q, ok := parseListQuery(r); ok {
+				page, err := h.svc.ListRoutes(r.URL.Query().Get("deleted") == "true", q)
+				writeListPage(w, page, err)
+				return
+			}
+			getRoutes := h.svc.GetRoutes
+			if r.URL.Query().Get("deleted") == "true" {
+				getRoutes = h.svc.GetDeletedRoutes
+			}
+			routes, err := getRoutes()
 			if err != nil {
 				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 				return
@@ -235,6 +483,14 @@ func (h *AdminHandler) handleRoutes(w http.ResponseWriter, r *http.Request, id u
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 			return
 		}
+		if err := validateRouteExperiment(route.Experiment); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if !route.ThoughtSignatureMode.IsValid() {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid thoughtSignatureMode: must be strict, auto or always_skip"})
+			return
+		}
 		if err := h.svc.CreateRoute(&route); err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 			return
@@ -293,6 +549,58 @@ func (h *AdminHandler) handleRoutes(w http.ResponseWriter, r *http.Request, id u
 				existing.RetryConfigID = uint64(f)
 			}
 		}
+		if v, ok := updates["thoughtSignatureMode"]; ok {
+			s, isString := v.(string)
+			mode := domain.ThoughtSignatureMode(s)
+			if (v != nil && !isString) || !mode.IsValid() {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid thoughtSignatureMode: must be strict, auto or always_skip"})
+				return
+			}
+			existing.ThoughtSignatureMode = mode
+		}
+		if v, ok := updates["pingIntervalSeconds"]; ok {
+			if f, ok := v.(float64); ok {
+				existing.PingIntervalSeconds = int(f)
+			}
+		}
+		if v, ok := updates["hideReasoning"]; ok {
+			if b, ok := v.(bool); ok {
+				existing.HideReasoning = b
+			}
+		}
+		if v, ok := updates["systemPrompt"]; ok {
+			existing.SystemPrompt = nil
+			if data, err := json.Marshal(v); err == nil && v != nil {
+				var sp domain.RouteSystemPrompt
+				if json.Unmarshal(data, &sp) == nil && sp.Mode != domain.SystemPromptKeep {
+					existing.SystemPrompt = &sp
+				}
+			}
+		}
+		if v, ok := updates["identityPatch"]; ok {
+			existing.IdentityPatch = nil
+			if data, err := json.Marshal(v); err == nil && v != nil {
+				var ip domain.RouteIdentityPatch
+				if json.Unmarshal(data, &ip) == nil && (ip.Disabled || ip.Template != "") {
+					existing.IdentityPatch = &ip
+				}
+			}
+		}
+		if v, ok := updates["experiment"]; ok {
+			existing.Experiment = nil
+			if data, err := json.Marshal(v); err == nil && v != nil {
+				var exp domain.RouteExperiment
+				if err := json.Unmarshal(data, &exp); err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid experiment: " + err.Error()})
+					return
+				}
+				existing.Experiment = &exp
+			}
+		}
+		if err := validateRouteExperiment(existing.Experiment); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
 		if err := h.svc.UpdateRoute(existing); err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 			return
@@ -334,6 +642,66 @@ func (h *AdminHandler) handleBatchUpdateRoutePositions(w http.ResponseWriter, r
 	writeJSON(w, http.StatusOK, map[string]string{"message": "positions updated successfully"})
 }
 
+// POST /admin/routes/simulate dry-runs route matching and model mapping for a request
+func (h *AdminHandler) handleSimulateRoutes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req domain.RouteSimulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	result, err := h.svc.SimulateRoutes(&req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// Route conversion handler
+// GET /admin/routes/conversions?projectID=&providerID= lists provider/client type pairs
+// with their conversion capability; POST creates or toggles converted routes
+func (h *AdminHandler) handleRouteConversions(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		projectID, _ := strconv.ParseUint(r.URL.Query().Get("projectID"), 10, 64)
+		providerID, _ := strconv.ParseUint(r.URL.Query().Get("providerID"), 10, 64)
+		conversions, err := h.svc.GetRouteConversions(projectID, providerID)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, conversions)
+	case http.MethodPost:
+		var req domain.ConvertedRoutesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		routes, err := h.svc.CreateConvertedRoutes(&req)
+		if err != nil {
+			if errors.Is(err, domain.ErrInvalidInput) {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, routes)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
 // Project handlers
 func (h *AdminHandler) handleProjects(w http.ResponseWriter, r *http.Request, id uint64, parts []string) {
 	// Check for by-slug endpoint: /admin/projects/by-slug/{slug}
@@ -352,6 +720,11 @@ func (h *AdminHandler) handleProjects(w http.ResponseWriter, r *http.Request, id
 			}
 			writeJSON(w, http.StatusOK, project)
 		} else {
+			if q, ok := parseListQuery(r); ok {
+				page, err := h.svc.ListProjects(q)
+				writeListPage(w, page, err)
+				return
+			}
 			projects, err := h.svc.GetProjects()
 			if err != nil {
 				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
@@ -430,8 +803,15 @@ func (h *AdminHandler) handleProjectBySlug(w http.ResponseWriter, r *http.Reques
 }
 
 // Session handlers
-// Routes: /admin/sessions, /admin/sessions/{sessionID}/project, /admin/sessions/{sessionID}/reject
+// Routes: /admin/sessions, /admin/sessions/{sessionID}/project, /admin/sessions/{sessionID}/reject,
+// /admin/sessions/{sessionID}/pin
 func (h *AdminHandler) handleSessions(w http.ResponseWriter, r *http.Request, parts []string) {
+	// POST /admin/sessions/purge-expired
+	if len(parts) == 3 && parts[2] == "purge-expired" {
+		h.handleSessionPurgeExpired(w, r)
+		return
+	}
+
 	// Check for sub-resource: /admin/sessions/{sessionID}/project
 	if len(parts) > 3 && parts[3] == "project" {
 		h.handleSessionProject(w, r, parts[2])
@@ -444,9 +824,20 @@ func (h *AdminHandler) handleSessions(w http.ResponseWriter, r *http.Request, pa
 		return
 	}
 
+	// Check for sub-resource: /admin/sessions/{sessionID}/pin
+	if len(parts) > 3 && parts[3] == "pin" {
+		h.handleSessionPin(w, r, parts[2])
+		return
+	}
+
 	switch r.Method {
 	case http.MethodGet:
-		sessions, err := h.svc.GetSessions()
+		if q, ok := parseListQuery(r); ok {
+			page, err := h.svc.ListSessions(r.URL.Query().Get("include_archived") == "true", q)
+			writeListPage(w, page, err)
+			return
+		}
+		sessions, err := h.svc.GetSessions(r.URL.Query().Get("include_archived") == "true")
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 			return
@@ -457,6 +848,46 @@ func (h *AdminHandler) handleSessions(w http.ResponseWriter, r *http.Request, pa
 	}
 }
 
+// handleSessionPurgeExpired handles POST /admin/sessions/purge-expired
+// The body may override the configured idle hours and action; an empty body uses the settings.
+func (h *AdminHandler) handleSessionPurgeExpired(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var body struct {
+		IdleHours int    `json:"idleHours"`
+		Action    string `json:"action"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	idleHours, action := h.svc.SessionIdleConfig()
+	if body.IdleHours != 0 {
+		idleHours = body.IdleHours
+	}
+	if body.Action != "" {
+		action = body.Action
+	}
+
+	result, err := h.svc.ExpireIdleSessions(idleHours, action)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidInput) {
+			status = http.StatusBadRequest
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	if len(result.SessionIDs) > 0 && h.broadcaster != nil {
+		h.broadcaster.BroadcastMessage(service.SessionsExpiredMessageType, result)
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
 // handleSessionProject handles PUT /admin/sessions/{sessionID}/project
 func (h *AdminHandler) handleSessionProject(w http.ResponseWriter, r *http.Request, sessionID string) {
 	if r.Method != http.MethodPut {
@@ -507,6 +938,45 @@ func (h *AdminHandler) handleSessionReject(w http.ResponseWriter, r *http.Reques
 	writeJSON(w, http.StatusOK, session)
 }
 
+// handleSessionPin handles PUT /admin/sessions/{sessionID}/pin (pin to a route or provider)
+// and DELETE /admin/sessions/{sessionID}/pin (unpin)
+func (h *AdminHandler) handleSessionPin(w http.ResponseWriter, r *http.Request, sessionID string) {
+	if sessionID == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "session ID required"})
+		return
+	}
+
+	var body struct {
+		RouteID    uint64 `json:"routeID"`
+		ProviderID uint64 `json:"providerID"`
+	}
+	switch r.Method {
+	case http.MethodPut:
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	case http.MethodDelete:
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	session, err := h.svc.PinSession(sessionID, body.RouteID, body.ProviderID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, domain.ErrInvalidInput) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, domain.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, session)
+}
+
 // RetryConfig handlers
 func (h *AdminHandler) handleRetryConfigs(w http.ResponseWriter, r *http.Request, id uint64) {
 	switch r.Method {
@@ -643,43 +1113,371 @@ func (h *AdminHandler) handleRoutingStrategies(w http.ResponseWriter, r *http.Re
 	}
 }
 
-// ProxyRequest handlers
-// Routes: /admin/requests, /admin/requests/count, /admin/requests/{id}, /admin/requests/{id}/attempts
-func (h *AdminHandler) handleProxyRequests(w http.ResponseWriter, r *http.Request, id uint64, parts []string) {
-	// Check for count endpoint: /admin/requests/count
-	if len(parts) > 2 && parts[2] == "count" {
-		h.handleProxyRequestsCount(w, r)
+// RoutingProfile handlers
+func (h *AdminHandler) handleRoutingProfiles(w http.ResponseWriter, r *http.Request, id uint64, parts []string) {
+	// GET /admin/routing-profiles/active
+	if len(parts) > 2 && parts[2] == "active" {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, h.svc.GetActiveRoutingProfile())
 		return
 	}
-
-	// Check for sub-resource: /admin/requests/{id}/attempts
-	if len(parts) > 3 && parts[3] == "attempts" && id > 0 {
-		h.handleProxyUpstreamAttempts(w, r, id)
+	// POST /admin/routing-profiles/{id}/apply and /admin/routing-profiles/{id}/capture
+	if len(parts) > 3 && id > 0 {
+		h.handleRoutingProfileAction(w, r, id, parts[3])
 		return
 	}
 
 	switch r.Method {
 	case http.MethodGet:
 		if id > 0 {
-			req, err := h.svc.GetProxyRequest(id)
+			profile, err := h.svc.GetRoutingProfile(id)
 			if err != nil {
-				writeJSON(w, http.StatusNotFound, map[string]string{"error": "proxy request not found"})
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "routing profile not found"})
 				return
 			}
-			writeJSON(w, http.StatusOK, req)
+			writeJSON(w, http.StatusOK, profile)
 		} else {
-			limit := 100
-			var before, after uint64
-			if l := r.URL.Query().Get("limit"); l != "" {
-				limit, _ = strconv.Atoi(l)
-			}
-			if b := r.URL.Query().Get("before"); b != "" {
-				before, _ = strconv.ParseUint(b, 10, 64)
-			}
-			if a := r.URL.Query().Get("after"); a != "" {
-				after, _ = strconv.ParseUint(a, 10, 64)
-			}
-			result, err := h.svc.GetProxyRequestsCursor(limit, before, after)
+			profiles, err := h.svc.GetRoutingProfiles()
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, profiles)
+		}
+	case http.MethodPost:
+		var profile domain.RoutingProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := h.svc.CreateRoutingProfile(&profile); err != nil {
+			writeRoutingProfileError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, profile)
+	case http.MethodPut:
+		if id == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id required"})
+			return
+		}
+		// Get existing profile first to preserve timestamps
+		existing, err := h.svc.GetRoutingProfile(id)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "routing profile not found"})
+			return
+		}
+		var profile domain.RoutingProfile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		profile.ID = existing.ID
+		profile.CreatedAt = existing.CreatedAt
+		if err := h.svc.UpdateRoutingProfile(&profile); err != nil {
+			writeRoutingProfileError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, profile)
+	case http.MethodDelete:
+		if id == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id required"})
+			return
+		}
+		if err := h.svc.DeleteRoutingProfile(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusNoContent, nil)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// handleRoutingProfileAction applies a profile, or captures the current route states into it
+func (h *AdminHandler) handleRoutingProfileAction(w http.ResponseWriter, r *http.Request, id uint64, action string) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var (
+		profile *domain.RoutingProfile
+		err     error
+	)
+	switch action {
+	case "apply":
+		profile, err = h.svc.ApplyRoutingProfile(id)
+	case "capture":
+		profile, err = h.svc.CaptureRoutingProfile(id)
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if err != nil {
+		writeRoutingProfileError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, profile)
+}
+
+func writeRoutingProfileError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "routing profile not found"})
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// CostAlert handlers
+func (h *AdminHandler) handleCostAlerts(w http.ResponseWriter, r *http.Request, id uint64) {
+	switch r.Method {
+	case http.MethodGet:
+		if id > 0 {
+			alert, err := h.svc.GetCostAlert(id)
+			if err != nil {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "cost alert not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, alert)
+		} else {
+			alerts, err := h.svc.GetCostAlerts()
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, alerts)
+		}
+	case http.MethodPost:
+		var alert domain.CostAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := h.svc.CreateCostAlert(&alert); err != nil {
+			writeCostAlertError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, alert)
+	case http.MethodPut:
+		if id == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id required"})
+			return
+		}
+		// Get existing alert first to preserve timestamps
+		existing, err := h.svc.GetCostAlert(id)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "cost alert not found"})
+			return
+		}
+		var alert domain.CostAlert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		alert.ID = existing.ID
+		alert.CreatedAt = existing.CreatedAt
+		if err := h.svc.UpdateCostAlert(&alert); err != nil {
+			writeCostAlertError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, alert)
+	case http.MethodDelete:
+		if id == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id required"})
+			return
+		}
+		if err := h.svc.DeleteCostAlert(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusNoContent, nil)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+func writeCostAlertError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "cost alert not found"})
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+// LatencySLO handlers
+func (h *AdminHandler) handleLatencySLOs(w http.ResponseWriter, r *http.Request, id uint64) {
+	switch r.Method {
+	case http.MethodGet:
+		if id > 0 {
+			objective, err := h.svc.GetLatencySLO(id)
+			if err != nil {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "latency SLO not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, objective)
+		} else {
+			slos, err := h.svc.GetLatencySLOs()
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, slos)
+		}
+	case http.MethodPost:
+		var objective domain.LatencySLO
+		if err := json.NewDecoder(r.Body).Decode(&objective); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if err := h.svc.CreateLatencySLO(&objective); err != nil {
+			writeLatencySLOError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusCreated, objective)
+	case http.MethodPut:
+		if id == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id required"})
+			return
+		}
+		// Get existing SLO first to preserve timestamps
+		existing, err := h.svc.GetLatencySLO(id)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "latency SLO not found"})
+			return
+		}
+		var objective domain.LatencySLO
+		if err := json.NewDecoder(r.Body).Decode(&objective); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		objective.ID = existing.ID
+		objective.CreatedAt = existing.CreatedAt
+		if err := h.svc.UpdateLatencySLO(&objective); err != nil {
+			writeLatencySLOError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, objective)
+	case http.MethodDelete:
+		if id == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id required"})
+			return
+		}
+		if err := h.svc.DeleteLatencySLO(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusNoContent, nil)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+func writeLatencySLOError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "latency SLO not found"})
+	case errors.Is(err, domain.ErrInvalidInput):
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+	}
+}
+
+func (h *AdminHandler) handleLatencySLOStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	stats, err := h.svc.GetLatencySLOStats()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// ProxyRequest handlers
+// Routes: /admin/requests, /admin/requests/count, /admin/requests/{id}, /admin/requests/{id}/attempts
+func (h *AdminHandler) handleProxyRequests(w http.ResponseWriter, r *http.Request, id uint64, parts []string) {
+	// Check for count endpoint: /admin/requests/count
+	if len(parts) > 2 && parts[2] == "count" {
+		h.handleProxyRequestsCount(w, r)
+		return
+	}
+
+	// Check for body downloads: /admin/requests/{id}/{request,response}-body and
+	// /admin/requests/{id}/attempts/{attemptID}/{request,response}-body
+	if id > 0 && len(parts) > 3 && isBodyDownload(parts[len(parts)-1]) {
+		var attemptID uint64
+		if len(parts) == 6 && parts[3] == "attempts" {
+			attemptID, _ = strconv.ParseUint(parts[4], 10, 64)
+		}
+		if len(parts) == 4 || attemptID > 0 {
+			h.handleProxyRequestBody(w, r, id, attemptID, parts[len(parts)-1] == "response-body")
+			return
+		}
+	}
+
+	// Check for replay: /admin/requests/{id}/attempts/{attemptID}/replay
+	if len(parts) == 6 && parts[3] == "attempts" && parts[5] == "replay" && id > 0 {
+		attemptID, _ := strconv.ParseUint(parts[4], 10, 64)
+		h.handleAttemptReplay(w, r, id, attemptID)
+		return
+	}
+
+	// Check for sub-resource: /admin/requests/{id}/attempts
+	if len(parts) > 3 && parts[3] == "attempts" && id > 0 {
+		h.handleProxyUpstreamAttempts(w, r, id)
+		return
+	}
+
+	// Check for sub-resource: /admin/requests/{id}/trace
+	if len(parts) > 3 && parts[3] == "trace" && id > 0 {
+		h.handleProxyRequestTrace(w, r, id)
+		return
+	}
+
+	// Check for sub-resource: /admin/requests/{id}/diff
+	if len(parts) > 3 && parts[3] == "diff" && id > 0 {
+		h.handleProxyRequestDiff(w, r, id)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if id > 0 {
+			req, err := h.svc.GetProxyRequest(id)
+			if err != nil {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "proxy request not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, req)
+		} else {
+			limit := 100
+			var before, after uint64
+			if l := r.URL.Query().Get("limit"); l != "" {
+				limit, _ = strconv.Atoi(l)
+			}
+			if b := r.URL.Query().Get("before"); b != "" {
+				before, _ = strconv.ParseUint(b, 10, 64)
+			}
+			if a := r.URL.Query().Get("after"); a != "" {
+				after, _ = strconv.ParseUint(a, 10, 64)
+			}
+			// cursor is the nextCursor of the previous page, the same as before
+			if c := r.URL.Query().Get("cursor"); c != "" && before == 0 && after == 0 {
+				before, _ = strconv.ParseUint(c, 10, 64)
+			}
+			result, err := h.svc.GetProxyRequestsCursor(limit, before, after)
 			if err != nil {
 				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 				return
@@ -691,6 +1489,23 @@ func (h *AdminHandler) handleProxyRequests(w http.ResponseWriter, r *http.Reques
 	}
 }
 
+// Effective retry config handler
+// GET /admin/routes/{id}/retry-config returns the retry config the route runs with and
+// which level it comes from (route, provider or global default)
+func (h *AdminHandler) handleEffectiveRetryConfig(w http.ResponseWriter, r *http.Request, routeID uint64) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	eff, err := h.svc.GetEffectiveRetryConfig(routeID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "route not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, eff)
+}
+
 // ProxyRequestsCount handler
 func (h *AdminHandler) handleProxyRequestsCount(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -706,6 +1521,23 @@ func (h *AdminHandler) handleProxyRequestsCount(w http.ResponseWriter, r *http.R
 	writeJSON(w, http.StatusOK, count)
 }
 
+// Request diff handler
+// GET /admin/requests/{id}/diff returns the client request aligned with every upstream attempt,
+// with JSON pointers to the fields the conversion changed
+func (h *AdminHandler) handleProxyRequestDiff(w http.ResponseWriter, r *http.Request, proxyRequestID uint64) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	diff, err := h.svc.GetProxyRequestDiff(proxyRequestID)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "proxy request not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, diff)
+}
+
 // ProxyUpstreamAttempt handlers
 func (h *AdminHandler) handleProxyUpstreamAttempts(w http.ResponseWriter, r *http.Request, proxyRequestID uint64) {
 	if r.Method != http.MethodGet {
@@ -721,6 +1553,142 @@ func (h *AdminHandler) handleProxyUpstreamAttempts(w http.ResponseWriter, r *htt
 	writeJSON(w, http.StatusOK, attempts)
 }
 
+// Attempt replay handler
+// POST /admin/requests/{id}/attempts/{attemptID}/replay sends the recorded upstream request of an
+// Antigravity attempt again with another Antigravity account ({"providerID": ...})
+func (h *AdminHandler) handleAttemptReplay(w http.ResponseWriter, r *http.Request, proxyRequestID, attemptID uint64) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	var req struct {
+		ProviderID uint64 `json:"providerID"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	if attemptID == 0 || req.ProviderID == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "attemptID and providerID are required"})
+		return
+	}
+
+	result, err := h.ReplayAttempt(r.Context(), proxyRequestID, attemptID, req.ProviderID)
+	if err != nil {
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		case errors.Is(err, domain.ErrInvalidInput):
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		default:
+			writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+		}
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// ReplayAttempt sends the v1internal request of an Antigravity attempt again with another
+// Antigravity account, to tell whether the failure is specific to the original account.
+// The replay bypasses routing and is not recorded as a request.
+func (h *AdminHandler) ReplayAttempt(ctx context.Context, proxyRequestID, attemptID, providerID uint64) (*antigravity.ReplayResult, error) {
+	attempts, err := h.svc.GetProxyUpstreamAttempts(proxyRequestID)
+	if err != nil {
+		return nil, fmt.Errorf("request %w: %v", domain.ErrNotFound, err)
+	}
+	var attempt *domain.ProxyUpstreamAttempt
+	for _, a := range attempts {
+		if a.ID == attemptID {
+			attempt = a
+			break
+		}
+	}
+	if attempt == nil {
+		return nil, fmt.Errorf("attempt %w", domain.ErrNotFound)
+	}
+	if attempt.ProviderID == providerID {
+		return nil, fmt.Errorf("%w: choose a different provider than the one of the attempt", domain.ErrInvalidInput)
+	}
+	original, err := h.svc.GetProvider(attempt.ProviderID)
+	if err != nil || original.Type != "antigravity" {
+		return nil, fmt.Errorf("%w: attempt was not sent to an Antigravity provider", domain.ErrInvalidInput)
+	}
+	if attempt.RequestInfo == nil || attempt.RequestInfo.Body == "" {
+		return nil, fmt.Errorf("%w: attempt has no recorded upstream request", domain.ErrInvalidInput)
+	}
+
+	target, err := h.svc.GetProvider(providerID)
+	if err != nil {
+		return nil, fmt.Errorf("provider %w", domain.ErrNotFound)
+	}
+	// A replay is a diagnostic request, independent of the target's traffic
+	return antigravity.Replay(ctxutil.WithDiagnostic(ctx, true), target, attempt.RequestInfo.URL, attempt.RequestInfo.Body)
+}
+
+func isBodyDownload(part string) bool {
+	return part == "request-body" || part == "response-body"
+}
+
+// handleProxyRequestBody downloads the stored request or response body of a request or one of
+// its upstream attempts as a file, byte for byte (SSE streams as recorded)
+func (h *AdminHandler) handleProxyRequestBody(w http.ResponseWriter, r *http.Request, proxyRequestID, attemptID uint64, response bool) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	body, err := h.svc.GetProxyRequestBody(proxyRequestID, attemptID, response)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "body not found"})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	name := fmt.Sprintf("request-%d", proxyRequestID)
+	if attemptID > 0 {
+		name += fmt.Sprintf("-attempt-%d", attemptID)
+	}
+	if response {
+		name += "-response"
+	} else {
+		name += "-request"
+	}
+	switch {
+	case strings.HasPrefix(body.ContentType, "text/event-stream"):
+		name += ".sse"
+	case strings.Contains(body.ContentType, "json"):
+		name += ".json"
+	default:
+		name += ".txt"
+	}
+
+	w.Header().Set("Content-Type", body.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, name))
+	w.Header().Set("Content-Length", strconv.Itoa(len(body.Body)))
+	w.WriteHeader(http.StatusOK)
+	io.WriteString(w, body.Body)
+}
+
+// handleProxyRequestTrace downloads the trace of a request sent with X-Maxx-Trace
+func (h *AdminHandler) handleProxyRequestTrace(w http.ResponseWriter, r *http.Request, proxyRequestID uint64) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	trace := executor.GlobalTraceStore().Get(proxyRequestID)
+	if trace == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no trace recorded for this request"})
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="maxx-trace-%d.json"`, proxyRequestID))
+	writeJSON(w, http.StatusOK, trace.Snapshot())
+}
+
 // Settings handlers
 func (h *AdminHandler) handleSettings(w http.ResponseWriter, r *http.Request, parts []string) {
 	var key string
@@ -771,38 +1739,335 @@ func (h *AdminHandler) handleSettings(w http.ResponseWriter, r *http.Request, pa
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 			return
 		}
-		writeJSON(w, http.StatusNoContent, nil)
-	default:
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		writeJSON(w, http.StatusNoContent, nil)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// Proxy status handler
+func (h *AdminHandler) handleProxyStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.svc.GetProxyStatus(r))
+}
+
+// Health handler
+// GET /admin/health/config returns the last config check report
+// POST /admin/health/config (or GET ?refresh=true) runs the check again
+func (h *AdminHandler) handleHealth(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) < 3 || parts[2] != "config" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		if r.URL.Query().Get("refresh") == "true" {
+			writeJSON(w, http.StatusOK, h.svc.CheckConfig(r.Context()))
+			return
+		}
+		writeJSON(w, http.StatusOK, h.svc.GetConfigReport(r.Context()))
+	case http.MethodPost:
+		writeJSON(w, http.StatusOK, h.svc.CheckConfig(r.Context()))
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// Recovery summary handler
+// GET /admin/recovery-summary returns the requests and attempts interrupted by the previous
+// run, marked at startup; null if there were none. The startup broadcast reaches no console,
+// as none is connected yet.
+func (h *AdminHandler) handleRecoverySummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	writeJSON(w, http.StatusOK, h.recovery)
+}
+
+// Diagnostics handler
+// GET /admin/diagnostics/bundle?failed=20&log_lines=500 downloads a redacted diagnostic zip for bug reports
+func (h *AdminHandler) handleDiagnostics(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) < 3 || parts[2] != "bundle" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	failed := queryInt(r, "failed", 20, 200)
+	logLines := queryInt(r, "log_lines", 500, 5000)
+	lines, err := ReadLastNLines(h.logPath, logLines)
+	if err != nil {
+		lines = []string{"failed to read log: " + err.Error()}
+	}
+
+	var buf bytes.Buffer
+	err = h.svc.WriteDiagnosticBundle(r.Context(), &buf, service.DiagnosticBundleOptions{
+		LogLines:       lines,
+		FailedRequests: failed,
+	})
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="maxx-diagnostics-%s.zip"`, time.Now().Format("20060102-150405")))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// Batch run handler
+// POST /admin/batch-runs runs a set of prompts through normal routing (optionally once per
+// provider) and returns the consolidated results, for comparing provider quality and cost
+func (h *AdminHandler) handleBatchRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if h.executor == nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "batch runs are not available"})
+		return
+	}
+
+	var req executor.BatchRunRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid JSON: " + err.Error()})
+		return
+	}
+
+	result, err := h.executor.RunBatch(r.Context(), &req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// queryInt reads a positive integer query parameter, capped at max
+func queryInt(r *http.Request, name string, def, max int) int {
+	v := def
+	if s := r.URL.Query().Get(name); s != "" {
+		if parsed, err := strconv.Atoi(s); err == nil && parsed >= 0 {
+			v = parsed
+		}
+	}
+	if v > max {
+		v = max
+	}
+	return v
+}
+
+// Provider quota handlers
+// GET /admin/provider-quotas lists the local quota usage, DELETE /admin/provider-quotas/{id} resets it
+func (h *AdminHandler) handleProviderQuotas(w http.ResponseWriter, r *http.Request, providerID uint64) {
+	switch r.Method {
+	case http.MethodGet:
+		quotas, err := h.svc.GetProviderQuotas()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, quotas)
+	case http.MethodDelete:
+		if providerID == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "provider id required"})
+			return
+		}
+		h.svc.ResetProviderQuota(providerID)
+		writeJSON(w, http.StatusOK, map[string]string{"message": "quota reset"})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// Failback handlers
+// GET /admin/failback lists providers that failed over, DELETE /admin/failback/{id} fails back immediately
+func (h *AdminHandler) handleFailback(w http.ResponseWriter, r *http.Request, providerID uint64) {
+	switch r.Method {
+	case http.MethodGet:
+		status, err := h.svc.GetFailbackStatus()
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, status)
+	case http.MethodDelete:
+		if providerID == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "provider id required"})
+			return
+		}
+		h.svc.PromoteProvider(providerID)
+		writeJSON(w, http.StatusOK, map[string]string{"message": "provider promoted"})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// Provider stats handler
+func (h *AdminHandler) handleProviderStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	clientType := r.URL.Query().Get("client_type")
+	var projectID uint64
+	if pidStr := r.URL.Query().Get("project_id"); pidStr != "" {
+		projectID, _ = strconv.ParseUint(pidStr, 10, 64)
+	}
+	stats, err := h.svc.GetProviderStats(clientType, projectID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleRequestShapeStats handles GET /admin/request-shape-stats
+// Query: start, end (RFC3339), providerId, projectId, clientType
+func (h *AdminHandler) handleRequestShapeStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	query := r.URL.Query()
+	filter := repository.RequestShapeFilter{}
+	if startStr := query.Get("start"); startStr != "" {
+		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+			utc := t.UTC()
+			filter.StartTime = &utc
+		}
+	}
+	if endStr := query.Get("end"); endStr != "" {
+		if t, err := time.Parse(time.RFC3339, endStr); err == nil {
+			utc := t.UTC()
+			filter.EndTime = &utc
+		}
+	}
+	if providerIDStr := query.Get("providerId"); providerIDStr != "" {
+		if id, err := strconv.ParseUint(providerIDStr, 10, 64); err == nil {
+			filter.ProviderID = &id
+		}
+	}
+	if projectIDStr := query.Get("projectId"); projectIDStr != "" {
+		if id, err := strconv.ParseUint(projectIDStr, 10, 64); err == nil {
+			filter.ProjectID = &id
+		}
+	}
+	if clientType := query.Get("clientType"); clientType != "" {
+		filter.ClientType = &clientType
+	}
+
+	stats, err := h.svc.GetRequestShapeStats(filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if stats == nil {
+		stats = []*domain.RequestShapeStats{}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
+// handleExperimentStats handles GET /admin/experiment-stats
+// Query: start, end (RFC3339), experiment
+func (h *AdminHandler) handleExperimentStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	query := r.URL.Query()
+	filter := repository.ExperimentStatsFilter{}
+	if startStr := query.Get("start"); startStr != "" {
+		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+			utc := t.UTC()
+			filter.StartTime = &utc
+		}
+	}
+	if endStr := query.Get("end"); endStr != "" {
+		if t, err := time.Parse(time.RFC3339, endStr); err == nil {
+			utc := t.UTC()
+			filter.EndTime = &utc
+		}
+	}
+	if experiment := query.Get("experiment"); experiment != "" {
+		filter.Experiment = &experiment
 	}
-}
 
-// Proxy status handler
-func (h *AdminHandler) handleProxyStatus(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	stats, err := h.svc.GetExperimentStats(filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
-	writeJSON(w, http.StatusOK, h.svc.GetProxyStatus(r))
+	if stats == nil {
+		stats = []*domain.ExperimentArmStats{}
+	}
+	writeJSON(w, http.StatusOK, stats)
 }
 
-// Provider stats handler
-func (h *AdminHandler) handleProviderStats(w http.ResponseWriter, r *http.Request) {
+// handlePromptCacheAdvisor handles GET /admin/prompt-cache-advisor
+// Query: start, end (RFC3339), routeId
+func (h *AdminHandler) handlePromptCacheAdvisor(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
 		return
 	}
-	clientType := r.URL.Query().Get("client_type")
-	var projectID uint64
-	if pidStr := r.URL.Query().Get("project_id"); pidStr != "" {
-		projectID, _ = strconv.ParseUint(pidStr, 10, 64)
+
+	query := r.URL.Query()
+	filter := repository.PromptPrefixFilter{}
+	if startStr := query.Get("start"); startStr != "" {
+		if t, err := time.Parse(time.RFC3339, startStr); err == nil {
+			utc := t.UTC()
+			filter.StartTime = &utc
+		}
 	}
-	stats, err := h.svc.GetProviderStats(clientType, projectID)
+	if endStr := query.Get("end"); endStr != "" {
+		if t, err := time.Parse(time.RFC3339, endStr); err == nil {
+			utc := t.UTC()
+			filter.EndTime = &utc
+		}
+	}
+	if routeIDStr := query.Get("routeId"); routeIDStr != "" {
+		if id, err := strconv.ParseUint(routeIDStr, 10, 64); err == nil {
+			filter.RouteID = &id
+		}
+	}
+
+	advice, err := h.svc.GetPromptCacheAdvice(filter)
 	if err != nil {
 		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return
 	}
-	writeJSON(w, http.StatusOK, stats)
+	if advice == nil {
+		advice = []*domain.PromptCacheAdvice{}
+	}
+	writeJSON(w, http.StatusOK, advice)
+}
+
+// validateRouteExperiment checks a route's A/B experiment settings
+func validateRouteExperiment(exp *domain.RouteExperiment) error {
+	if exp == nil || !exp.Enabled {
+		return nil
+	}
+	if strings.TrimSpace(exp.Name) == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if exp.SplitPercent < 0 || exp.SplitPercent > 100 {
+		return fmt.Errorf("experiment splitPercent must be between 0 and 100")
+	}
+	return nil
 }
 
 // Logs handler
@@ -834,6 +2099,118 @@ func (h *AdminHandler) handleLogs(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// Audit handler
+// GET /admin/audit?event=&session_id=&request_id=&since=&limit= - query the audit log, newest first
+func (h *AdminHandler) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	q := r.URL.Query()
+	filter := audit.Filter{
+		HookEventName: q.Get("event"),
+		SessionID:     q.Get("session_id"),
+		RequestID:     q.Get("request_id"),
+		Limit:         100,
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			filter.Limit = parsed
+		}
+	}
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+	if since := q.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid since, expected RFC3339"})
+			return
+		}
+		filter.Since = t
+	}
+
+	events, err := h.svc.QueryAuditEvents(filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"events": events,
+		"count":  len(events),
+	})
+}
+
+// Quality samples handler
+// GET /admin/quality-samples?provider_id=&model=&tag=&since=&until=&limit= lists sampled request/response pairs, newest first
+// GET /admin/quality-samples/export?... downloads them as JSONL, oldest first (all matches unless limit is set)
+func (h *AdminHandler) handleQualitySamples(w http.ResponseWriter, r *http.Request, parts []string) {
+	export := len(parts) > 2 && parts[2] == "export"
+	if len(parts) > 2 && !export {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	q := r.URL.Query()
+	filter := quality.Filter{
+		Model: q.Get("model"),
+		Tag:   q.Get("tag"),
+	}
+	if id := q.Get("provider_id"); id != "" {
+		parsed, err := strconv.ParseUint(id, 10, 64)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid provider_id"})
+			return
+		}
+		filter.ProviderID = parsed
+	}
+	for name, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := q.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid " + name + ", expected RFC3339"})
+				return
+			}
+			*t = parsed
+		}
+	}
+	if !export {
+		filter.Limit = 100
+	}
+	if l := q.Get("limit"); l != "" {
+		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 {
+			filter.Limit = parsed
+		}
+	}
+
+	if export {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="maxx-quality-samples-%s.jsonl"`, time.Now().Format("20060102-150405")))
+		if _, err := h.svc.ExportQualitySamples(w, filter); err != nil {
+			log.Printf("[Admin] Quality sample export failed: %v", err)
+		}
+		return
+	}
+
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+	samples, err := h.svc.QueryQualitySamples(filter)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"samples": samples,
+		"count":   len(samples),
+	})
+}
+
 // Cooldowns handler
 // GET /admin/cooldowns - list all active cooldowns
 // DELETE /admin/cooldowns/{id} - clear cooldown for a provider
@@ -877,6 +2254,55 @@ func (h *AdminHandler) handleCooldowns(w http.ResponseWriter, r *http.Request, p
 	}
 }
 
+// handleCooldownStats handles GET /admin/cooldowns/stats?from=&to=&bucket=hour|day
+// Aggregates cooldown activations by provider and reason; defaults to the last 7 days
+func (h *AdminHandler) handleCooldownStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	query := r.URL.Query()
+	to := time.Now().UTC()
+	if s := query.Get("to"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid to: " + err.Error()})
+			return
+		}
+		to = t.UTC()
+	}
+	from := to.Add(-7 * 24 * time.Hour)
+	if s := query.Get("from"); s != "" {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid from: " + err.Error()})
+			return
+		}
+		from = t.UTC()
+	}
+	bucket := domain.GranularityHour
+	if s := query.Get("bucket"); s != "" {
+		bucket = domain.Granularity(s)
+	}
+
+	stats, err := cooldown.Default().Stats(from, to, bucket)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if providers, err := h.svc.GetProviders(); err == nil {
+		names := make(map[uint64]string, len(providers))
+		for _, p := range providers {
+			names[p.ID] = p.Name
+		}
+		for _, p := range stats.Providers {
+			p.ProviderName = names[p.ProviderID]
+		}
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
+
 // API Token handlers
 func (h *AdminHandler) handleAPITokens(w http.ResponseWriter, r *http.Request, id uint64) {
 	switch r.Method {
@@ -902,6 +2328,8 @@ func (h *AdminHandler) handleAPITokens(w http.ResponseWriter, r *http.Request, i
 			Description string  `json:"description"`
 			ProjectID   uint64  `json:"projectID"`
 			ExpiresAt   *string `json:"expiresAt"`
+
+			AllowedClientTypes []domain.ClientType `json:"allowedClientTypes"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
@@ -920,7 +2348,7 @@ func (h *AdminHandler) handleAPITokens(w http.ResponseWriter, r *http.Request, i
 			}
 			expiresAt = &t
 		}
-		result, err := h.svc.CreateAPIToken(body.Name, body.Description, body.ProjectID, expiresAt)
+		result, err := h.svc.CreateAPIToken(body.Name, body.Description, body.ProjectID, body.AllowedClientTypes, expiresAt)
 		if err != nil {
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 			return
@@ -942,6 +2370,8 @@ func (h *AdminHandler) handleAPITokens(w http.ResponseWriter, r *http.Request, i
 			ProjectID   *uint64 `json:"projectID"`
 			IsEnabled   *bool   `json:"isEnabled"`
 			ExpiresAt   *string `json:"expiresAt"`
+
+			AllowedClientTypes *[]domain.ClientType `json:"allowedClientTypes"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
@@ -963,6 +2393,9 @@ func (h *AdminHandler) handleAPITokens(w http.ResponseWriter, r *http.Request, i
 		if body.IsEnabled != nil {
 			existing.IsEnabled = *body.IsEnabled
 		}
+		if body.AllowedClientTypes != nil {
+			existing.AllowedClientTypes = *body.AllowedClientTypes
+		}
 		if body.ExpiresAt != nil {
 			if *body.ExpiresAt == "" {
 				existing.ExpiresAt = nil
@@ -1008,6 +2441,15 @@ func (h *AdminHandler) handleModelMappings(w http.ResponseWriter, r *http.Reques
 		h.handleResetModelMappingsToDefaults(w, r)
 		return
 	}
+	// Learning mode endpoints: /admin/model-mappings/outcomes and /admin/model-mappings/suggestions
+	if strings.HasSuffix(path, "/outcomes") {
+		h.handleModelMappingOutcomes(w, r)
+		return
+	}
+	if strings.HasSuffix(path, "/suggestions") {
+		h.handleModelMappingSuggestions(w, r)
+		return
+	}
 
 	switch r.Method {
 	case http.MethodGet:
@@ -1133,6 +2575,145 @@ func (h *AdminHandler) handleResetModelMappingsToDefaults(w http.ResponseWriter,
 	writeJSON(w, http.StatusOK, map[string]string{"message": "mappings reset to defaults"})
 }
 
+// GET lists the outcomes recorded in learning mode, DELETE forgets them
+func (h *AdminHandler) handleModelMappingOutcomes(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, h.svc.GetModelMappingOutcomes())
+	case http.MethodDelete:
+		h.svc.ResetModelMappingOutcomes()
+		writeJSON(w, http.StatusOK, map[string]string{"message": "outcomes cleared"})
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// GET /admin/model-mappings/suggestions?min_attempts=10&failure_rate=0.5
+func (h *AdminHandler) handleModelMappingSuggestions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	minAttempts := uint64(10)
+	if v, err := strconv.ParseUint(r.URL.Query().Get("min_attempts"), 10, 64); err == nil && v > 0 {
+		minAttempts = v
+	}
+	failureRate := 0.5
+	if v, err := strconv.ParseFloat(r.URL.Query().Get("failure_rate"), 64); err == nil && v > 0 && v <= 1 {
+		failureRate = v
+	}
+	suggestions := h.svc.GetModelMappingSuggestions(minAttempts, failureRate)
+	if suggestions == nil {
+		suggestions = []*domain.ModelMappingSuggestion{}
+	}
+	writeJSON(w, http.StatusOK, suggestions)
+}
+
+// Model Capability handlers
+func (h *AdminHandler) handleModelCapabilities(w http.ResponseWriter, r *http.Request, id uint64) {
+	path := r.URL.Path
+	// GET /admin/model-capabilities/builtin lists the built-in defaults
+	if strings.HasSuffix(path, "/builtin") {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		writeJSON(w, http.StatusOK, h.svc.GetBuiltinModelCapabilities())
+		return
+	}
+	// GET /admin/model-capabilities/resolve?model=xxx&provider_id=1 returns the merged capabilities
+	if strings.HasSuffix(path, "/resolve") {
+		if r.Method != http.MethodGet {
+			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+			return
+		}
+		model := r.URL.Query().Get("model")
+		if model == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "model is required"})
+			return
+		}
+		providerID, _ := strconv.ParseUint(r.URL.Query().Get("provider_id"), 10, 64)
+		writeJSON(w, http.StatusOK, h.svc.ResolveModelCapabilities(providerID, model))
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if id > 0 {
+			c, err := h.svc.GetModelCapability(id)
+			if err != nil {
+				writeJSON(w, http.StatusNotFound, map[string]string{"error": "capability not found"})
+				return
+			}
+			writeJSON(w, http.StatusOK, c)
+		} else {
+			capabilities, err := h.svc.GetModelCapabilities()
+			if err != nil {
+				writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+				return
+			}
+			writeJSON(w, http.StatusOK, capabilities)
+		}
+	case http.MethodPost:
+		var c domain.ModelCapability
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if c.Pattern == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pattern is required"})
+			return
+		}
+		c.ID = 0
+		if err := h.svc.CreateModelCapability(&c); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusCreated, c)
+	case http.MethodPut:
+		// The body replaces the rule: omitted capabilities become unknown
+		if id == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id required"})
+			return
+		}
+		existing, err := h.svc.GetModelCapability(id)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "capability not found"})
+			return
+		}
+		var c domain.ModelCapability
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		if c.Pattern == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "pattern cannot be empty"})
+			return
+		}
+		c.ID = existing.ID
+		c.CreatedAt = existing.CreatedAt
+		c.DeletedAt = nil
+		if err := h.svc.UpdateModelCapability(&c); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, c)
+	case http.MethodDelete:
+		if id == 0 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "id required"})
+			return
+		}
+		if err := h.svc.DeleteModelCapability(id); err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusNoContent, nil)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
 // Usage Stats handlers
 func (h *AdminHandler) handleUsageStats(w http.ResponseWriter, r *http.Request) {
 	// Check for recalculate endpoint: /admin/usage-stats/recalculate
@@ -1247,6 +2828,62 @@ func (h *AdminHandler) handleResponseModels(w http.ResponseWriter, r *http.Reque
 	writeJSON(w, http.StatusOK, names)
 }
 
+// handleDashboard handles dashboard chart data
+// GET /admin/dashboard/timeseries?range=24h|7d|30d&groupBy=provider|project
+func (h *AdminHandler) handleDashboard(w http.ResponseWriter, r *http.Request, parts []string) {
+	if len(parts) < 3 || parts[2] != "timeseries" {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return
+	}
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+
+	query := r.URL.Query()
+	result, err := h.svc.GetDashboardTimeSeries(
+		domain.DashboardRange(query.Get("range")),
+		domain.DashboardGroupBy(query.Get("groupBy")),
+	)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// parseListQuery reads the pagination and sorting parameters of a list endpoint:
+// limit, cursor (the nextCursor of the previous page), sort and order (asc/desc).
+// Lists are paginated only when one of them is given; otherwise the endpoint returns
+// the whole list as a plain array, as it always did.
+func parseListQuery(r *http.Request) (service.ListQuery, bool) {
+	query := r.URL.Query()
+	if !query.Has("limit") && !query.Has("cursor") && !query.Has("sort") && !query.Has("order") {
+		return service.ListQuery{}, false
+	}
+	q := service.ListQuery{Sort: query.Get("sort"), Order: query.Get("order")}
+	q.Limit, _ = strconv.Atoi(query.Get("limit"))
+	q.Cursor, _ = strconv.ParseUint(query.Get("cursor"), 10, 64)
+	return q, true
+}
+
+// writeListPage writes a page of a list endpoint; invalid sorting or cursors are 400s
+func writeListPage[T any](w http.ResponseWriter, page *service.Page[T], err error) {
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidInput) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, page)
+}
+
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
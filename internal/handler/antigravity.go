@@ -3,7 +3,9 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"html/template"
 	"net/http"
 	"strconv"
 	"strings"
@@ -12,6 +14,7 @@ import (
 	"github.com/awsl-project/maxx/internal/adapter/provider/antigravity"
 	"github.com/awsl-project/maxx/internal/domain"
 	"github.com/awsl-project/maxx/internal/event"
+	"github.com/awsl-project/maxx/internal/i18n"
 	"github.com/awsl-project/maxx/internal/repository"
 	"github.com/awsl-project/maxx/internal/service"
 )
@@ -269,12 +272,12 @@ func (h *AntigravityHandler) GetProviderQuota(ctx context.Context, providerID ui
 	// 获取 provider
 	provider, err := h.svc.GetProvider(providerID)
 	if err != nil {
-		return nil, fmt.Errorf("provider not found: %w", err)
+		return nil, fmt.Errorf("provider %w", domain.ErrNotFound)
 	}
 
 	// 检查是否为 Antigravity provider
 	if provider.Type != "antigravity" || provider.Config == nil || provider.Config.Antigravity == nil {
-		return nil, fmt.Errorf("not an Antigravity provider")
+		return nil, fmt.Errorf("%w: not an Antigravity provider", domain.ErrInvalidInput)
 	}
 
 	config := provider.Config.Antigravity
@@ -330,9 +333,9 @@ func (h *AntigravityHandler) handleGetQuota(w http.ResponseWriter, r *http.Reque
 
 	quota, err := h.GetProviderQuota(r.Context(), providerID, forceRefresh)
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
+		if errors.Is(err, domain.ErrNotFound) {
 			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
-		} else if strings.Contains(err.Error(), "not an Antigravity") {
+		} else if errors.Is(err, domain.ErrInvalidInput) {
 			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
 		} else {
 			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
@@ -465,16 +468,17 @@ func (h *AntigravityHandler) handleOAuthCallback(w http.ResponseWriter, r *http.
 	// 获取 code 和 state
 	code := r.URL.Query().Get("code")
 	state := r.URL.Query().Get("state")
+	lang := i18n.FromRequest(r)
 
 	if code == "" || state == "" {
-		h.sendOAuthErrorResult(w, state, "Missing code or state parameter")
+		h.sendOAuthErrorResult(w, lang, state, i18n.T(lang, "Missing code or state parameter"))
 		return
 	}
 
 	// 验证 state
 	session, ok := h.oauthManager.GetSession(state)
 	if !ok {
-		h.sendOAuthErrorResult(w, state, "Invalid or expired state")
+		h.sendOAuthErrorResult(w, lang, state, i18n.T(lang, "Invalid or expired state"))
 		return
 	}
 
@@ -486,14 +490,14 @@ func (h *AntigravityHandler) handleOAuthCallback(w http.ResponseWriter, r *http.
 	// 使用 code 交换 tokens
 	accessToken, refreshToken, _, err := antigravity.ExchangeCodeForTokens(r.Context(), code, redirectURI)
 	if err != nil {
-		h.sendOAuthErrorResult(w, state, fmt.Sprintf("Token exchange failed: %v", err))
+		h.sendOAuthErrorResult(w, lang, state, i18n.Sprintf(lang, "Token exchange failed: %v", err))
 		return
 	}
 
 	// 获取用户信息
 	userInfo, err := antigravity.FetchUserInfo(r.Context(), accessToken)
 	if err != nil {
-		h.sendOAuthErrorResult(w, state, fmt.Sprintf("Failed to fetch user info: %v", err))
+		h.sendOAuthErrorResult(w, lang, state, i18n.Sprintf(lang, "Failed to fetch user info: %v", err))
 		return
 	}
 
@@ -535,13 +539,11 @@ func (h *AntigravityHandler) handleOAuthCallback(w http.ResponseWriter, r *http.
 	h.oauthManager.CompleteSession(state, result)
 
 	// 返回成功页面
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(oauthSuccessHTML))
+	writeOAuthPage(w, http.StatusOK, oauthSuccessPage, lang)
 }
 
 // sendOAuthErrorResult 发送 OAuth 错误结果并返回错误页面
-func (h *AntigravityHandler) sendOAuthErrorResult(w http.ResponseWriter, state, errorMsg string) {
+func (h *AntigravityHandler) sendOAuthErrorResult(w http.ResponseWriter, lang i18n.Lang, state, errorMsg string) {
 	// 推送错误结果到前端
 	result := &antigravity.OAuthResult{
 		State:   state,
@@ -552,9 +554,23 @@ func (h *AntigravityHandler) sendOAuthErrorResult(w http.ResponseWriter, state,
 	h.oauthManager.CompleteSession(state, result)
 
 	// 返回错误页面
+	writeOAuthPage(w, http.StatusBadRequest, oauthErrorPage, lang)
+}
+
+// oauthPageData OAuth 结果页面的模板数据，页面文本通过 {{.T "..."}} 翻译
+type oauthPageData struct {
+	Lang i18n.Lang
+}
+
+func (d oauthPageData) T(message string) string {
+	return i18n.T(d.Lang, message)
+}
+
+// writeOAuthPage 以指定语言渲染 OAuth 结果页面
+func writeOAuthPage(w http.ResponseWriter, status int, page *template.Template, lang i18n.Lang) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	w.WriteHeader(http.StatusBadRequest)
-	w.Write([]byte(oauthErrorHTML))
+	w.WriteHeader(status)
+	_ = page.Execute(w, oauthPageData{Lang: lang})
 }
 
 // getScheme 从请求中获取协议 (http 或 https)
@@ -569,12 +585,12 @@ func getScheme(r *http.Request) string {
 }
 
 // OAuth 成功页面 HTML
-const oauthSuccessHTML = `<!DOCTYPE html>
-<html lang="en">
+var oauthSuccessPage = template.Must(template.New("oauth-success").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Authorization Successful</title>
+    <title>{{.T "Authorization Successful"}}</title>
     <style>
         body {
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, Cantarell, sans-serif;
@@ -625,8 +641,8 @@ const oauthSuccessHTML = `<!DOCTYPE html>
 <body>
     <div class="container">
         <div class="icon">✅</div>
-        <h1>Authorization Successful!</h1>
-        <p>You can now close this window and return to the application.</p>
+        <h1>{{.T "Authorization Successful!"}}</h1>
+        <p>{{.T "You can now close this window and return to the application."}}</p>
         <div class="spinner"></div>
     </div>
     <script>
@@ -635,15 +651,15 @@ const oauthSuccessHTML = `<!DOCTYPE html>
         }, 2000);
     </script>
 </body>
-</html>`
+</html>`))
 
 // OAuth 错误页面 HTML
-const oauthErrorHTML = `<!DOCTYPE html>
-<html lang="en">
+var oauthErrorPage = template.Must(template.New("oauth-error").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
 <head>
     <meta charset="UTF-8">
     <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Authorization Failed</title>
+    <title>{{.T "Authorization Failed"}}</title>
     <style>
         body {
             font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, Oxygen, Ubuntu, Cantarell, sans-serif;
@@ -681,9 +697,9 @@ const oauthErrorHTML = `<!DOCTYPE html>
 <body>
     <div class="container">
         <div class="icon">❌</div>
-        <h1>Authorization Failed</h1>
-        <p>Please return to the application and try again.</p>
+        <h1>{{.T "Authorization Failed"}}</h1>
+        <p>{{.T "Please return to the application and try again."}}</p>
     </div>
 </body>
-</html>`
+</html>`))
 
@@ -85,6 +85,12 @@ func (m *AuthMiddleware) Wrap(next http.Handler) http.Handler {
 	})
 }
 
+// WebSocketAuthorized reports whether a WebSocket handshake may subscribe to events.
+// Browsers cannot set headers on the handshake, so the token is read from the token query parameter.
+func (m *AuthMiddleware) WebSocketAuthorized(r *http.Request) bool {
+	return !m.IsEnabled() || m.ValidateToken(r.URL.Query().Get("token"))
+}
+
 // VerifyPassword checks if the provided password is correct
 func (m *AuthMiddleware) VerifyPassword(password string) bool {
 	if !m.IsEnabled() {
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/awsl-project/maxx/internal/converter"
+)
+
+// completionsResponseWriter turns the chat completions response of a legacy /v1/completions
+// request back into text_completion objects. Streams are converted line by line; other
+// responses are buffered and converted by finish.
+type completionsResponseWriter struct {
+	w      http.ResponseWriter
+	stream bool
+	status int
+	buf    bytes.Buffer // Non-streaming body, or the incomplete last line of a stream
+}
+
+func newCompletionsResponseWriter(w http.ResponseWriter, stream bool) *completionsResponseWriter {
+	return &completionsResponseWriter{w: w, stream: stream, status: http.StatusOK}
+}
+
+func (c *completionsResponseWriter) Header() http.Header {
+	return c.w.Header()
+}
+
+func (c *completionsResponseWriter) WriteHeader(code int) {
+	c.status = code
+	if c.stream {
+		c.w.WriteHeader(code)
+	}
+}
+
+func (c *completionsResponseWriter) Write(b []byte) (int, error) {
+	c.buf.Write(b)
+	if !c.stream {
+		return len(b), nil
+	}
+
+	for {
+		idx := bytes.IndexByte(c.buf.Bytes(), '\n')
+		if idx < 0 {
+			return len(b), nil
+		}
+		line := c.buf.Next(idx + 1)
+		if _, err := c.w.Write(convertCompletionsLine(line)); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Flush is a no-op for non-streaming responses: flushing would commit the status and
+// headers before finish has rewritten the buffered body
+func (c *completionsResponseWriter) Flush() {
+	if !c.stream {
+		return
+	}
+	if f, ok := c.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish writes out the buffered response
+func (c *completionsResponseWriter) finish() {
+	if c.stream {
+		if c.buf.Len() > 0 {
+			_, _ = c.w.Write(convertCompletionsLine(c.buf.Bytes()))
+			c.buf.Reset()
+		}
+		return
+	}
+
+	body := c.buf.Bytes()
+	if c.status < http.StatusBadRequest {
+		if converted, err := converter.ChatToCompletionResponse(body); err == nil {
+			body = converted
+		}
+	}
+	c.w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	c.w.WriteHeader(c.status)
+	_, _ = c.w.Write(body)
+}
+
+// convertCompletionsLine converts an SSE data line carrying a chat completions chunk
+func convertCompletionsLine(line []byte) []byte {
+	trimmed := bytes.TrimRight(line, "\r\n")
+	data, ok := bytes.CutPrefix(trimmed, []byte("data:"))
+	if !ok {
+		return line
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || string(data) == "[DONE]" {
+		return line
+	}
+	converted, err := converter.ChatChunkToCompletionChunk(data)
+	if err != nil {
+		return line
+	}
+	out := append([]byte("data: "), converted...)
+	return append(out, line[len(trimmed):]...)
+}
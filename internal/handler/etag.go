@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/awsl-project/maxx/internal/event"
+)
+
+// maxETagEntries bounds the cached responses; the cache starts over when it is full
+const maxETagEntries = 256
+
+// etagCachedResources are the admin resources whose collection and item GETs only change
+// with the configuration, so they are kept until the next config change. Their other
+// sub-routes (such as /model-mappings/outcomes) are built from traffic and never cached.
+var etagCachedResources = map[string]bool{
+	event.ConfigProviders:         true,
+	event.ConfigRoutes:            true,
+	event.ConfigProjects:          true,
+	event.ConfigRetryConfigs:      true,
+	event.ConfigRoutingStrategies: true,
+	event.ConfigModelMappings:     true,
+	event.ConfigSettings:          true,
+}
+
+// etagCacheable reports whether the GET response of an admin path is kept until the next
+// config change: the collection or a single item of a config resource
+func etagCacheable(resource string, parts []string) bool {
+	if !etagCachedResources[resource] {
+		return false
+	}
+	switch {
+	case len(parts) == 2:
+		return true
+	case len(parts) == 3 && resource == event.ConfigSettings:
+		return true
+	case len(parts) == 3:
+		_, err := strconv.ParseUint(parts[2], 10, 64)
+		return err == nil
+	}
+	return false
+}
+
+// etagCache answers conditional admin GETs. Every successful GET response carries an ETag
+// of its body, and If-None-Match requests that still match get a 304 without the body.
+// Responses of config resources are also kept by URL until the config change event bus
+// reports a change, so polling them doesn't rebuild the response either.
+type etagCache struct {
+	mu         sync.Mutex
+	generation uint64 // Incremented on every config change
+	entries    map[string]*etagEntry
+}
+
+type etagEntry struct {
+	etag   string
+	header http.Header
+	body   []byte
+}
+
+func newETagCache() *etagCache {
+	c := &etagCache{entries: make(map[string]*etagEntry)}
+	event.DefaultConfigBus().Subscribe(func(event.ConfigChange) {
+		c.invalidate()
+	})
+	return c
+}
+
+func (c *etagCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	clear(c.entries)
+}
+
+func (c *etagCache) get(key string) (*etagEntry, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[key], c.generation
+}
+
+// put stores an entry built at generation unless the config changed in the meantime
+func (c *etagCache) put(key string, generation uint64, entry *etagEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if len(c.entries) >= maxETagEntries {
+		clear(c.entries)
+	}
+	c.entries[key] = entry
+}
+
+// serve handles a GET through next, adding the ETag and answering If-None-Match. cached
+// keeps the response until the next config change. Downloads (responses with a
+// Content-Disposition) are sent through as they are written.
+func (c *etagCache) serve(w http.ResponseWriter, r *http.Request, cached bool, next http.HandlerFunc) {
+	key := r.URL.RequestURI()
+	entry, generation := c.get(key)
+	if cached && entry != nil {
+		writeETagResponse(w, r, entry)
+		return
+	}
+
+	rec := &bufferedResponse{w: w, header: make(http.Header), statusCode: http.StatusOK}
+	next(rec, r)
+	if rec.direct {
+		return
+	}
+	if rec.statusCode != http.StatusOK {
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		w.WriteHeader(rec.statusCode)
+		_, _ = w.Write(rec.body.Bytes())
+		return
+	}
+
+	sum := sha256.Sum256(rec.body.Bytes())
+	entry = &etagEntry{
+		etag:   `"` + hex.EncodeToString(sum[:16]) + `"`,
+		header: rec.header,
+		body:   rec.body.Bytes(),
+	}
+	if cached {
+		c.put(key, generation, entry)
+	}
+	writeETagResponse(w, r, entry)
+}
+
+func writeETagResponse(w http.ResponseWriter, r *http.Request, entry *etagEntry) {
+	for k, v := range entry.header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("ETag", entry.etag)
+	// Let browsers keep the response but revalidate it on every request
+	w.Header().Set("Cache-Control", "no-cache")
+	if etagMatches(r.Header.Get("If-None-Match"), entry.etag) {
+		w.Header().Del("Content-Length")
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(entry.body)
+}
+
+// etagMatches reports whether an If-None-Match header matches etag (weak comparison)
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse holds a response so its ETag can be computed before it is sent.
+// A download is written to w directly instead.
+type bufferedResponse struct {
+	w           http.ResponseWriter
+	header      http.Header
+	statusCode  int
+	body        bytes.Buffer
+	wroteHeader bool
+	direct      bool
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(code int) {
+	if b.wroteHeader {
+		return
+	}
+	b.wroteHeader = true
+	b.statusCode = code
+	if b.header.Get("Content-Disposition") != "" {
+		b.direct = true
+		for k, v := range b.header {
+			b.w.Header()[k] = v
+		}
+		b.w.WriteHeader(code)
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	if b.direct {
+		return b.w.Write(p)
+	}
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) Flush() {
+	if f, ok := b.w.(http.Flusher); b.direct && ok {
+		f.Flush()
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/awsl-project/maxx/internal/i18n"
 	"github.com/awsl-project/maxx/internal/repository"
 )
 
@@ -32,7 +33,7 @@ func (h *ProjectProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	// Expected format: /{slug}/v1/messages, /{slug}/v1/chat/completions, etc.
 	slug, apiPath, ok := h.parseProjectPath(r.URL.Path)
 	if !ok {
-		writeError(w, http.StatusNotFound, "invalid project proxy path")
+		writeError(w, http.StatusNotFound, i18n.T(i18n.FromRequest(r), "invalid project proxy path"))
 		return
 	}
 
@@ -40,7 +41,7 @@ func (h *ProjectProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request)
 	project, err := h.projectRepo.GetBySlug(slug)
 	if err != nil {
 		log.Printf("[ProjectProxy] Project not found for slug: %s", slug)
-		writeError(w, http.StatusNotFound, "project not found")
+		writeError(w, http.StatusNotFound, i18n.T(i18n.FromRequest(r), "project not found"))
 		return
 	}
 
@@ -86,17 +87,21 @@ func isValidAPIPath(path string) bool {
 		return true
 	}
 	// OpenAI API
-	if strings.HasPrefix(path, "/v1/chat/completions") {
+	if strings.HasPrefix(path, "/v1/chat/completions") || strings.HasPrefix(path, "/v1/completions") {
 		return true
 	}
 	// Codex API
-	if strings.HasPrefix(path, "/responses") {
+	if strings.HasPrefix(path, "/responses") || strings.HasPrefix(path, "/v1/responses") {
 		return true
 	}
 	// Gemini API
 	if strings.HasPrefix(path, "/v1beta/models/") {
 		return true
 	}
+	// Model listings and lookups
+	if path == "/v1/models" || path == "/v1beta/models" || strings.HasPrefix(path, "/v1/models/") {
+		return true
+	}
 	return false
 }
 
@@ -2,24 +2,55 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/awsl-project/maxx/internal/adapter/client"
 	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/converter"
 	"github.com/awsl-project/maxx/internal/domain"
 	"github.com/awsl-project/maxx/internal/executor"
+	"github.com/awsl-project/maxx/internal/i18n"
 	"github.com/awsl-project/maxx/internal/repository/cached"
 )
 
+// Reserved request headers for per-request overrides
+const (
+	// HeaderPinnedProvider pins the request to one provider (name or ID), skipping route ordering.
+	// The provider must be reachable through the request's normal routes.
+	HeaderPinnedProvider = "X-Maxx-Provider"
+	// HeaderModelOverride sends the request upstream with this model, skipping model mapping
+	HeaderModelOverride = "X-Maxx-Model"
+	// HeaderTrace records every transformation stage of the request into a trace that can be
+	// downloaded from /admin/requests/{id}/trace (the ID is returned in X-Maxx-Trace-Id).
+	// The maxx_trace query parameter does the same for clients that can't set headers.
+	HeaderTrace = "X-Maxx-Trace"
+	queryTrace  = "maxx_trace"
+	// HeaderDiagnostic marks a test/probe request sent by admin tooling from outside (in-process
+	// probes, batch runs and replays set the flag on their context instead): its failures and
+	// successes are not recorded against the provider's cooldown or failback state. The value
+	// is the admin session token; any value is accepted when admin authentication is off.
+	HeaderDiagnostic = "X-Maxx-Diagnostic"
+)
+
+// HeaderErrorCode is set on failed responses to the request's machine-readable error code
+// (see domain.ErrorCode). Error bodies generated by maxx carry the same value in "code";
+// upstream error bodies are passed on in the client's native format and only get the header.
+const HeaderErrorCode = "X-Maxx-Error-Code"
+
 // ProxyHandler handles AI API proxy requests
 type ProxyHandler struct {
 	clientAdapter *client.Adapter
 	executor      *executor.Executor
 	sessionRepo   *cached.SessionRepository
 	tokenAuth     *TokenAuthMiddleware
+	guard         *RequestGuard
+	adminAuth     *AuthMiddleware
 }
 
 // NewProxyHandler creates a new proxy handler
@@ -28,21 +59,33 @@ func NewProxyHandler(
 	exec *executor.Executor,
 	sessionRepo *cached.SessionRepository,
 	tokenAuth *TokenAuthMiddleware,
+	guard *RequestGuard,
 ) *ProxyHandler {
 	return &ProxyHandler{
 		clientAdapter: clientAdapter,
 		executor:      exec,
 		sessionRepo:   sessionRepo,
 		tokenAuth:     tokenAuth,
+		guard:         guard,
+		adminAuth:     NewAuthMiddleware(),
 	}
 }
 
 // ServeHTTP handles proxy requests
 func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	log.Printf("[Proxy] Received request: %s %s", r.Method, r.URL.Path)
+	lang := i18n.FromRequest(r)
+
+	// OpenAI's /v1/responses is the Codex (Responses API) endpoint
+	if r.URL.Path == "/v1/responses" {
+		r.URL.Path, r.URL.RawPath = "/responses", ""
+	}
 
-	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	// Gemini countTokens and models.get (GET) are served next to generateContent
+	geminiMethod := geminiAuxiliaryMethod(r)
+	passthrough := geminiMethod == "" && isPassthroughRequest(r)
+	if r.Method != http.MethodPost && geminiMethod == "" && !passthrough {
+		writeError(w, http.StatusMethodNotAllowed, i18n.T(lang, "method not allowed"))
 		return
 	}
 
@@ -60,19 +103,40 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Read body
-	body, err := io.ReadAll(r.Body)
+	// Read body within the size and content-type limits; errors use the format the
+	// endpoint implies, since the body isn't available to detect the client from
+	body, err := h.guard.ReadBody(w, r, h.clientAdapter.DetectClientType(r, nil))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to read request body")
+		var abortErr *ClientAbortError
+		if errors.As(err, &abortErr) {
+			h.recordClientAbort(r, abortErr)
+			writeGuardError(w, h.clientAdapter.DetectClientType(r, nil), http.StatusBadRequest,
+				i18n.T(lang, "request body was not fully received: the client closed the connection"))
+		}
 		return
 	}
 	defer r.Body.Close()
 
+	// Legacy completions are served as chat completions, converting the response back
+	if r.URL.Path == "/v1/completions" {
+		chatBody, err := converter.CompletionsToChatRequest(body)
+		if err != nil {
+			writeInvalidRequest(w, domain.ClientTypeOpenAI, err.Error())
+			return
+		}
+		body = chatBody
+		r.URL.Path, r.URL.RawPath = "/v1/chat/completions", ""
+
+		completions := newCompletionsResponseWriter(w, h.clientAdapter.IsStreamRequest(r, body))
+		defer completions.finish()
+		w = completions
+	}
+
 	// Detect client type and extract info
 	clientType := h.clientAdapter.DetectClientType(r, body)
 	log.Printf("[Proxy] Detected client type: %s", clientType)
 	if clientType == "" {
-		writeError(w, http.StatusBadRequest, "unable to detect client type")
+		writeError(w, http.StatusBadRequest, i18n.T(lang, "unable to detect client type"))
 		return
 	}
 
@@ -83,7 +147,11 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		apiToken, err = h.tokenAuth.ValidateRequest(r, clientType)
 		if err != nil {
 			log.Printf("[Proxy] Token auth failed: %v", err)
-			writeError(w, http.StatusUnauthorized, err.Error())
+			status := http.StatusUnauthorized
+			if errors.Is(err, ErrTokenClientTypeNotAllowed) {
+				status = http.StatusForbidden
+			}
+			writeError(w, status, i18n.T(lang, err.Error()))
 			return
 		}
 		if apiToken != nil {
@@ -97,6 +165,14 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	sessionID := h.clientAdapter.ExtractSessionID(r, body, clientType)
 	stream := h.clientAdapter.IsStreamRequest(r, body)
 
+	// Request-level overrides, stripped so they are not forwarded upstream
+	pinnedProvider := strings.TrimSpace(r.Header.Get(HeaderPinnedProvider))
+	modelOverride := strings.TrimSpace(r.Header.Get(HeaderModelOverride))
+	r.Header.Del(HeaderPinnedProvider)
+	r.Header.Del(HeaderModelOverride)
+	trace := extractTraceFlag(r)
+	diagnostic := h.extractDiagnosticFlag(r)
+
 	// Build context
 	ctx := r.Context()
 	ctx = ctxutil.WithClientType(ctx, clientType)
@@ -107,6 +183,18 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx = ctxutil.WithRequestURI(ctx, r.URL.RequestURI())
 	ctx = ctxutil.WithIsStream(ctx, stream)
 	ctx = ctxutil.WithAPITokenID(ctx, apiTokenID)
+	if pinnedProvider != "" {
+		ctx = ctxutil.WithPinnedProvider(ctx, pinnedProvider)
+	}
+	if modelOverride != "" {
+		ctx = ctxutil.WithModelOverride(ctx, modelOverride)
+	}
+	if trace {
+		ctx = ctxutil.WithTrace(ctx, true)
+	}
+	if diagnostic {
+		ctx = ctxutil.WithDiagnostic(ctx, true)
+	}
 
 	// Check for project ID from header (set by ProjectProxyHandler)
 	var projectID uint64
@@ -128,6 +216,7 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			projectID = apiToken.ProjectID
 			log.Printf("[Proxy] Using project ID from token: %d", projectID)
 		}
+		h.sessionRepo.Touch(session)
 	} else {
 		// Create new session
 		// If no project from header, use token's project
@@ -135,10 +224,12 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 			projectID = apiToken.ProjectID
 			log.Printf("[Proxy] Using project ID from token for new session: %d", projectID)
 		}
+		now := time.Now()
 		session = &domain.Session{
-			SessionID:  sessionID,
-			ClientType: clientType,
-			ProjectID:  projectID,
+			SessionID:    sessionID,
+			ClientType:   clientType,
+			ProjectID:    projectID,
+			LastActiveAt: &now,
 		}
 		_ = h.sessionRepo.Create(session)
 	}
@@ -146,23 +237,154 @@ func (h *ProxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx = ctxutil.WithProjectID(ctx, projectID)
 
 	// Execute request (executor handles request recording, project binding, routing, etc.)
-	err = h.executor.Execute(ctx, w, r)
+	if geminiMethod != "" {
+		err = h.executor.ExecuteGeminiAuxiliary(ctx, w, r, geminiMethod)
+	} else if passthrough {
+		err = h.executor.ExecutePassthrough(ctx, w, r)
+	} else {
+		err = h.executor.Execute(ctx, w, r)
+	}
 	if err != nil {
+		w.Header().Set(HeaderErrorCode, string(domain.ErrorCodeOf(err)))
+
+		// Requests that fail pre-flight validation never reached a route
+		var validationErr *converter.ValidationError
+		if errors.As(err, &validationErr) {
+			apiErr := validationErr.APIError()
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(apiErr.Status)
+			w.Write(converter.ErrorBody(clientType, apiErr))
+			return
+		}
+
+		// Conversion errors happen before anything is sent upstream,
+		// so report them as a regular error response in the client's own format
+		var convErr *converter.ConversionError
+		if errors.As(err, &convErr) {
+			writeConversionError(w, clientType, convErr)
+			return
+		}
+
+		// Pinned provider outside the request's routes (or in cooldown)
+		if errors.Is(err, domain.ErrProviderNotAllowed) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write(converter.ErrorBody(clientType, &converter.APIError{
+				Status:  http.StatusForbidden,
+				Type:    converter.ErrorTypePermission,
+				Message: err.Error(),
+			}))
+			return
+		}
+
 		proxyErr, ok := err.(*domain.ProxyError)
 		if ok {
 			if stream {
-				writeStreamError(w, proxyErr)
+				writeStreamError(w, clientType, proxyErr, lang)
 			} else {
-				writeProxyError(w, proxyErr)
+				writeProxyError(w, clientType, proxyErr, lang)
 			}
 		} else {
-			writeError(w, http.StatusInternalServerError, err.Error())
+			writeError(w, http.StatusInternalServerError, i18n.Error(lang, domain.ErrorCodeInternal, err.Error()))
 		}
 	}
 }
 
 // Helper functions
 
+// geminiAuxiliaryMethod returns the auxiliary Gemini method a request calls
+// (models/{model}:countTokens or GET models/{model}), or "" for any other request
+func geminiAuxiliaryMethod(r *http.Request) string {
+	name, ok := strings.CutPrefix(r.URL.Path, "/v1beta/models/")
+	if !ok || name == "" {
+		return ""
+	}
+	switch {
+	case r.Method == http.MethodPost && strings.HasSuffix(name, ":countTokens"):
+		return executor.GeminiMethodCountTokens
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && !strings.ContainsAny(name, ":/"):
+		return executor.GeminiMethodGetModel
+	}
+	return ""
+}
+
+// isPassthroughRequest reports whether a request is a GET or HEAD call that is passed
+// through to a provider unchanged: model listings (/v1/models, /v1beta/models) and OpenAI
+// and Anthropic model lookups (/v1/models/{model})
+func isPassthroughRequest(r *http.Request) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	path := r.URL.Path
+	if path == "/v1/models" || path == "/v1beta/models" {
+		return true
+	}
+	model, ok := strings.CutPrefix(path, "/v1/models/")
+	return ok && model != "" && !strings.Contains(model, "/")
+}
+
+// extractTraceFlag reports whether the client asked for a trace, and strips the flag from the
+// request so it is not forwarded upstream
+func extractTraceFlag(r *http.Request) bool {
+	flag := r.Header.Get(HeaderTrace)
+	r.Header.Del(HeaderTrace)
+
+	query := r.URL.Query()
+	if query.Has(queryTrace) {
+		if flag == "" {
+			flag = query.Get(queryTrace)
+			if flag == "" {
+				flag = "1" // Bare ?maxx_trace
+			}
+		}
+		query.Del(queryTrace)
+		r.URL.RawQuery = query.Encode()
+	}
+
+	enabled, err := strconv.ParseBool(strings.TrimSpace(flag))
+	return err == nil && enabled
+}
+
+// extractDiagnosticFlag reports whether the request is admin diagnostics traffic and removes
+// the header so it is not forwarded upstream
+func (h *ProxyHandler) extractDiagnosticFlag(r *http.Request) bool {
+	value := strings.TrimSpace(r.Header.Get(HeaderDiagnostic))
+	r.Header.Del(HeaderDiagnostic)
+	if value == "" {
+		return false
+	}
+	if !h.adminAuth.IsEnabled() {
+		return true
+	}
+	if h.adminAuth.ValidateToken(strings.TrimPrefix(value, "Bearer ")) {
+		return true
+	}
+	log.Printf("[Proxy] Ignoring %s header with an invalid admin token", HeaderDiagnostic)
+	return false
+}
+
+// recordClientAbort records a request whose client went away while uploading the body.
+// It never reached a provider, so it counts against no provider's failures or cooldown.
+// Requests that would fail token authentication are only logged.
+func (h *ProxyHandler) recordClientAbort(r *http.Request, abortErr *ClientAbortError) {
+	log.Printf("[Proxy] Client disconnected during body upload: %s %s (%v)", r.Method, r.URL.Path, abortErr.Err)
+	clientType := h.clientAdapter.DetectClientType(r, nil)
+	if clientType == "" {
+		return
+	}
+	var apiTokenID uint64
+	if h.tokenAuth != nil {
+		token, err := h.tokenAuth.ValidateRequest(r, clientType)
+		if err != nil {
+			return
+		}
+		if token != nil {
+			apiTokenID = token.ID
+		}
+	}
+	h.executor.RecordClientAbort(r, clientType, apiTokenID, abortErr.Error())
+}
+
 func writeError(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
@@ -174,7 +396,9 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	})
 }
 
-func writeProxyError(w http.ResponseWriter, err *domain.ProxyError) {
+// writeProxyError writes a failed request's error. Errors generated by maxx are
+// localized; upstream error bodies are passed on as they are.
+func writeProxyError(w http.ResponseWriter, clientType domain.ClientType, err *domain.ProxyError, lang i18n.Lang) {
 	w.Header().Set("Content-Type", "application/json")
 	if err.RetryAfter > 0 {
 		sec := int64(err.RetryAfter.Seconds())
@@ -183,17 +407,44 @@ func writeProxyError(w http.ResponseWriter, err *domain.ProxyError) {
 		}
 		w.Header().Set("Retry-After", strconv.FormatInt(sec, 10))
 	}
+
+	// Upstream error body: translate into the client's native error format
+	if err.ResponseBody != nil && err.HTTPStatusCode >= 400 {
+		status, body := converter.TranslateUpstreamError(err.ResponseFormat, clientType, err.HTTPStatusCode, err.ResponseBody)
+		w.WriteHeader(status)
+		w.Write(body)
+		return
+	}
+
 	w.WriteHeader(http.StatusBadGateway)
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"error": map[string]interface{}{
-			"message":   err.Error(),
+			"message":   i18n.Error(lang, err.ErrorCode(), err.Error()),
 			"type":      "upstream_error",
+			"code":      err.ErrorCode(),
 			"retryable": err.Retryable,
 		},
 	})
 }
 
-func writeStreamError(w http.ResponseWriter, err *domain.ProxyError) {
+func writeInvalidRequest(w http.ResponseWriter, clientType domain.ClientType, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(converter.ErrorBody(clientType, &converter.APIError{
+		Status:  http.StatusBadRequest,
+		Type:    converter.ErrorTypeInvalidRequest,
+		Message: message,
+	}))
+}
+
+func writeConversionError(w http.ResponseWriter, clientType domain.ClientType, err *converter.ConversionError) {
+	apiErr := err.APIError()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Status)
+	w.Write(converter.ErrorBody(clientType, apiErr))
+}
+
+func writeStreamError(w http.ResponseWriter, clientType domain.ClientType, err *domain.ProxyError, lang i18n.Lang) {
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
 	if err.RetryAfter > 0 {
@@ -205,11 +456,21 @@ func writeStreamError(w http.ResponseWriter, err *domain.ProxyError) {
 	}
 	w.WriteHeader(http.StatusOK)
 
+	// Upstream error body: send it as an error event in the client's native format
+	if err.ResponseBody != nil && err.HTTPStatusCode >= 400 {
+		w.Write(converter.ErrorEvent(clientType, converter.ParseUpstreamError(err.HTTPStatusCode, err.ResponseBody)))
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		return
+	}
+
 	errorEvent := map[string]interface{}{
 		"type": "error",
 		"error": map[string]interface{}{
-			"message":   err.Error(),
+			"message":   i18n.Error(lang, err.ErrorCode(), err.Error()),
 			"type":      "upstream_error",
+			"code":      err.ErrorCode(),
 			"retryable": err.Retryable,
 		},
 	}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/i18n"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// Setting keys for the proxy request guard
+const (
+	// SettingKeyMaxRequestBodyMB limits the size of proxy request bodies in MB (default 32, 0 = no limit)
+	SettingKeyMaxRequestBodyMB = "max_request_body_mb"
+	// SettingKeyStrictContentType rejects proxy requests that are not sent as application/json
+	SettingKeyStrictContentType = "strict_content_type"
+)
+
+const defaultMaxRequestBodyMB = 32
+
+// RequestGuard rejects oversized and malformed proxy request bodies before they are
+// recorded or routed, answering in the client's native error format
+type RequestGuard struct {
+	settingRepo repository.SystemSettingRepository
+}
+
+// NewRequestGuard creates a request guard configured by system settings
+func NewRequestGuard(settingRepo repository.SystemSettingRepository) *RequestGuard {
+	return &RequestGuard{settingRepo: settingRepo}
+}
+
+// maxBodyBytes returns the body size limit, 0 for none
+func (g *RequestGuard) maxBodyBytes() int64 {
+	mb := defaultMaxRequestBodyMB
+	if val, err := g.settingRepo.Get(SettingKeyMaxRequestBodyMB); err == nil && val != "" {
+		if n, err := strconv.Atoi(val); err == nil && n >= 0 {
+			mb = n
+		}
+	}
+	return int64(mb) << 20
+}
+
+func (g *RequestGuard) strictContentType() bool {
+	val, err := g.settingRepo.Get(SettingKeyStrictContentType)
+	return err == nil && val == "true"
+}
+
+// errRequestRejected is returned by ReadBody after it has answered the request
+var errRequestRejected = errors.New("request rejected")
+
+// ClientAbortError is returned by ReadBody when the client went away before sending
+// the whole body. Nothing has been written; a client that only half-closed the
+// connection may still be reading the answer.
+type ClientAbortError struct {
+	Received int64 // Body bytes received before the client went away
+	Err      error
+}
+
+func (e *ClientAbortError) Error() string {
+	return fmt.Sprintf("client disconnected after sending %d body bytes: %v", e.Received, e.Err)
+}
+
+func (e *ClientAbortError) Unwrap() error { return e.Err }
+
+// isClientAbort reports whether a body read failed because the client closed the
+// connection (or stopped sending) rather than because the body is invalid
+func isClientAbort(r *http.Request, err error) bool {
+	return r.Context().Err() != nil ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, net.ErrClosed) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// ReadBody reads the request body within the configured limits. If the request is
+// rejected, the error has been written (in clientType's format) and errRequestRejected
+// is returned; a client that goes away mid-upload yields a *ClientAbortError.
+// A nil guard reads the body without checks.
+func (g *RequestGuard) ReadBody(w http.ResponseWriter, r *http.Request, clientType domain.ClientType) ([]byte, error) {
+	if g == nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			if isClientAbort(r, err) {
+				return nil, &ClientAbortError{Received: int64(len(body)), Err: err}
+			}
+			writeError(w, http.StatusBadRequest, i18n.T(i18n.FromRequest(r), "failed to read request body"))
+			return nil, errRequestRejected
+		}
+		return body, nil
+	}
+
+	lang := i18n.FromRequest(r)
+	hasBody := r.Method == http.MethodPost
+	if hasBody && g.strictContentType() && !isJSONContentType(r.Header.Get("Content-Type")) {
+		writeGuardError(w, clientType, http.StatusUnsupportedMediaType,
+			i18n.Sprintf(lang, "unsupported Content-Type %q, expected application/json", r.Header.Get("Content-Type")))
+		return nil, errRequestRejected
+	}
+
+	reader := r.Body
+	if limit := g.maxBodyBytes(); limit > 0 {
+		if r.ContentLength > limit {
+			g.rejectTooLarge(w, r, clientType, limit)
+			return nil, errRequestRejected
+		}
+		reader = http.MaxBytesReader(w, r.Body, limit)
+	}
+	body, err := io.ReadAll(reader)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			g.rejectTooLarge(w, r, clientType, maxBytesErr.Limit)
+			return nil, errRequestRejected
+		}
+		if isClientAbort(r, err) {
+			return nil, &ClientAbortError{Received: int64(len(body)), Err: err}
+		}
+		writeGuardError(w, clientType, http.StatusBadRequest, i18n.T(lang, "failed to read request body"))
+		return nil, errRequestRejected
+	}
+
+	if hasBody {
+		if len(body) == 0 {
+			writeGuardError(w, clientType, http.StatusBadRequest, i18n.T(lang, "request body is empty"))
+			return nil, errRequestRejected
+		}
+		if !json.Valid(body) {
+			writeGuardError(w, clientType, http.StatusBadRequest, i18n.T(lang, "request body is not valid JSON"))
+			return nil, errRequestRejected
+		}
+	}
+	return body, nil
+}
+
+func (g *RequestGuard) rejectTooLarge(w http.ResponseWriter, r *http.Request, clientType domain.ClientType, limit int64) {
+	log.Printf("[Proxy] Rejected %s %s: body exceeds %d bytes", r.Method, r.URL.Path, limit)
+	// The rest of the body is not read; don't let the server try to reuse the connection
+	w.Header().Set("Connection", "close")
+	writeGuardError(w, clientType, http.StatusRequestEntityTooLarge,
+		i18n.Sprintf(i18n.FromRequest(r), "request body exceeds the %d MB limit", limit>>20))
+}
+
+// isJSONContentType reports whether a Content-Type is application/json or a +json type
+func isJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func writeGuardError(w http.ResponseWriter, clientType domain.ClientType, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(converter.ErrorBody(clientType, &converter.APIError{
+		Status:  status,
+		Type:    converter.ErrorTypeForStatus(status),
+		Message: message,
+	}))
+}
@@ -12,6 +12,9 @@ import (
 // StaticFS is the embedded filesystem for static files (set by main package)
 var StaticFS fs.FS
 
+// StaticDir is the directory static files are read from when StaticFS is not set
+var StaticDir = filepath.Join("web", "dist")
+
 // NewStaticHandler creates a handler for serving static files from web/dist
 // If StaticFS is set, it uses the embedded filesystem; otherwise, reads from disk
 func NewStaticHandler() http.Handler {
@@ -21,11 +24,10 @@ func NewStaticHandler() http.Handler {
 	return newFileSystemStaticHandler()
 }
 
-// newFileSystemStaticHandler serves static files from disk (web/dist)
+// newFileSystemStaticHandler serves static files from disk (StaticDir)
 func newFileSystemStaticHandler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// Get the web/dist directory path
-		webDistPath := filepath.Join("web", "dist")
+		webDistPath := StaticDir
 
 		// Clean the URL path
 		urlPath := path.Clean(r.URL.Path)
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/service"
+)
+
+// statusCacheTTL bounds how often an unauthenticated visitor can make the status
+// page query the stats tables
+const statusCacheTTL = 10 * time.Second
+
+// StatusHandler serves the read-only public status page: provider health, cooldowns
+// and aggregate throughput, without request contents. GET /status renders HTML for
+// browsers; /status.json, ?format=json or an Accept: application/json header return
+// the same data as JSON.
+type StatusHandler struct {
+	svc *service.AdminService
+
+	mu       sync.Mutex
+	cached   *domain.PublicStatus
+	cachedAt time.Time
+}
+
+// NewStatusHandler creates a status page handler
+func NewStatusHandler(svc *service.AdminService) *StatusHandler {
+	return &StatusHandler{svc: svc}
+}
+
+func (h *StatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if !h.svc.StatusPageEnabled() {
+		http.NotFound(w, r)
+		return
+	}
+
+	status, err := h.status()
+	if err != nil {
+		log.Printf("[Status] Failed to build status: %v", err)
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "status unavailable"})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if wantsStatusJSON(r) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		writeJSON(w, http.StatusOK, status)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := statusPageTemplate.Execute(w, status); err != nil {
+		log.Printf("[Status] Failed to render status page: %v", err)
+	}
+}
+
+// status returns the current status, rebuilt at most once per statusCacheTTL
+func (h *StatusHandler) status() (*domain.PublicStatus, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cached != nil && time.Since(h.cachedAt) < statusCacheTTL {
+		return h.cached, nil
+	}
+	status, err := h.svc.GetPublicStatus()
+	if err != nil {
+		return nil, err
+	}
+	h.cached = status
+	h.cachedAt = time.Now()
+	return status, nil
+}
+
+func wantsStatusJSON(r *http.Request) bool {
+	if strings.HasSuffix(r.URL.Path, ".json") || r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+var statusPageTemplate = template.Must(template.New("status").Funcs(template.FuncMap{
+	"percent": func(v float64) string { return fmt.Sprintf("%.1f%%", v) },
+	"rate":    func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"until": func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		remaining := time.Until(*t).Round(time.Minute)
+		if remaining < time.Minute {
+			return "< 1m"
+		}
+		return remaining.String()
+	},
+	"clock": func(t time.Time) string { return t.Format("2006-01-02 15:04:05 MST") },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta http-equiv="refresh" content="30">
+<title>maxx status: {{.Status}}</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 860px; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { font-size: 1.4rem; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 1.5rem; }
+th, td { text-align: left; padding: .4rem .6rem; border-bottom: 1px solid #ddd; }
+th { font-weight: 600; color: #555; }
+.operational, .active { color: #15803d; }
+.degraded, .recovering { color: #b45309; }
+.down, .cooldown, .unknown { color: #b91c1c; }
+.muted { color: #777; font-size: .85rem; }
+</style>
+</head>
+<body>
+<h1>maxx status: <span class="{{.Status}}">{{.Status}}</span></h1>
+
+<h2>Throughput</h2>
+<table>
+<tr><th></th><th>Requests</th><th>Success rate</th><th>Requests / min</th><th>Input tokens</th><th>Output tokens</th></tr>
+<tr><td>Last hour</td><td>{{.LastHour.Requests}}</td><td>{{percent .LastHour.SuccessRate}}</td><td>{{rate .LastHour.RequestsPerMinute}}</td><td>{{.LastHour.InputTokens}}</td><td>{{.LastHour.OutputTokens}}</td></tr>
+<tr><td>Last 24 hours</td><td>{{.Last24h.Requests}}</td><td>{{percent .Last24h.SuccessRate}}</td><td>{{rate .Last24h.RequestsPerMinute}}</td><td>{{.Last24h.InputTokens}}</td><td>{{.Last24h.OutputTokens}}</td></tr>
+</table>
+
+<h2>Providers</h2>
+<table>
+<tr><th>Provider</th><th>Type</th><th>Status</th><th>Requests (24h)</th><th>Success rate (24h)</th></tr>
+{{range .Providers}}<tr>
+<td>{{.Name}}</td>
+<td>{{.Type}}</td>
+<td class="{{.Status}}">{{.Status}}{{if .CooldownUntil}} ({{.CooldownReason}}, {{until .CooldownUntil}} left){{end}}</td>
+<td>{{.Requests24h}}</td>
+<td>{{percent .SuccessRate24h}}</td>
+</tr>{{else}}<tr><td colspan="5" class="muted">No providers configured</td></tr>{{end}}
+</table>
+
+<p class="muted">Updated {{clock .GeneratedAt}}. Refreshes every 30 seconds. Also available as <a href="/status.json">JSON</a>.</p>
+</body>
+</html>
+`))
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"strings"
 	"time"
@@ -27,6 +28,10 @@ var (
 	ErrInvalidToken  = errors.New("invalid API token")
 	ErrTokenDisabled = errors.New("API token is disabled")
 	ErrTokenExpired  = errors.New("API token has expired")
+
+	// ErrTokenClientTypeNotAllowed is returned for a valid token used with a client type it is
+	// restricted from; it is a permission error (403), not an authentication failure
+	ErrTokenClientTypeNotAllowed = errors.New("API token is not allowed for this client type")
 )
 
 // TokenAuthMiddleware handles API token authentication for proxy requests
@@ -57,6 +62,26 @@ func (m *TokenAuthMiddleware) IsEnabled() bool {
 	return val == "true"
 }
 
+// LoopbackBypassEnabled checks if requests from this machine may skip token authentication
+func (m *TokenAuthMiddleware) LoopbackBypassEnabled() bool {
+	val, err := m.settingRepo.Get(SettingKeyProxyTokenLoopbackBypass)
+	return err == nil && val == "true"
+}
+
+// IsLoopbackRequest reports whether a request comes directly from this machine. Requests
+// carrying forwarding headers are not: a local reverse proxy may be relaying LAN traffic.
+func IsLoopbackRequest(req *http.Request) bool {
+	if req.Header.Get("X-Forwarded-For") != "" || req.Header.Get("X-Real-IP") != "" || req.Header.Get("Forwarded") != "" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		host = req.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // ExtractToken extracts the token from the request based on client type
 // First tries the primary header for the client type, then falls back to other headers
 func (m *TokenAuthMiddleware) ExtractToken(req *http.Request, clientType domain.ClientType) string {
@@ -100,9 +125,18 @@ func (m *TokenAuthMiddleware) ExtractToken(req *http.Request, clientType domain.
 }
 
 // ValidateRequest validates the token from the request
-// Returns the token entity if valid, nil if auth is disabled, error if invalid
+// Returns the token entity if valid, nil if auth is disabled, error if invalid.
+// With the loopback bypass on, tokens are required from other machines only (even if auth
+// is otherwise disabled); local requests may omit them, but a maxx token they do send is
+// still validated so usage is attributed to it.
 func (m *TokenAuthMiddleware) ValidateRequest(req *http.Request, clientType domain.ClientType) (*domain.APIToken, error) {
-	if !m.IsEnabled() {
+	required := m.IsEnabled()
+	optional := false
+	if m.LoopbackBypassEnabled() {
+		required = !IsLoopbackRequest(req)
+		optional = !required
+	}
+	if !required && !optional {
 		return nil, nil // Auth disabled, allow all
 	}
 
@@ -110,6 +144,9 @@ func (m *TokenAuthMiddleware) ValidateRequest(req *http.Request, clientType doma
 	token := m.ExtractToken(req, clientType)
 	token = strings.TrimSpace(token)
 
+	if optional && !strings.HasPrefix(token, TokenPrefix) {
+		return nil, nil // Local request without a maxx token
+	}
 	if token == "" {
 		return nil, ErrMissingToken
 	}
@@ -138,6 +175,11 @@ func (m *TokenAuthMiddleware) ValidateRequest(req *http.Request, clientType doma
 		return nil, ErrTokenExpired
 	}
 
+	// Check client type restrictions
+	if !apiToken.AllowsClientType(clientType) {
+		return nil, ErrTokenClientTypeNotAllowed
+	}
+
 	// Update usage (async to not block request)
 	go func() {
 		if err := m.tokenRepo.IncrementUseCount(apiToken.ID); err != nil {
@@ -148,6 +190,20 @@ func (m *TokenAuthMiddleware) ValidateRequest(req *http.Request, clientType doma
 	return apiToken, nil
 }
 
+// IsLoopbackAddr reports whether a listen address (host:port) only accepts connections
+// from this machine. An empty host (":9880") listens on all interfaces.
+func IsLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // GenerateToken creates a new random token
 // Returns: plain token, prefix for display, error if generation fails
 func GenerateToken() (plain string, prefix string, err error) {
@@ -169,5 +225,9 @@ func GenerateToken() (plain string, prefix string, err error) {
 	return plain, prefix, nil
 }
 
-// Setting key for token auth
-const SettingKeyProxyTokenAuthEnabled = "api_token_auth_enabled"
+// Setting keys for token auth
+const (
+	SettingKeyProxyTokenAuthEnabled = "api_token_auth_enabled"
+	// Allow unauthenticated requests from loopback addresses, require tokens from elsewhere
+	SettingKeyProxyTokenLoopbackBypass = "api_token_loopback_bypass"
+)
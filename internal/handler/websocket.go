@@ -59,6 +59,30 @@ func (h *WebSocketHub) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 		log.Printf("WebSocket upgrade error: %v", err)
 		return
 	}
+	h.serve(conn)
+}
+
+// HandleWebSocketWithAuth 返回需要登录的 WebSocket handler。
+// 未登录的连接会先升级再以 1008 关闭，而不是返回 401：控制台在登录前就会建立连接，
+// 握手失败会被当作服务不可用；收到 1008 后控制台在登录后携带 token 重连。
+func (h *WebSocketHub) HandleWebSocketWithAuth(auth *AuthMiddleware) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			log.Printf("WebSocket upgrade error: %v", err)
+			return
+		}
+		if !auth.WebSocketAuthorized(r) {
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "unauthorized"))
+			conn.Close()
+			return
+		}
+		h.serve(conn)
+	}
+}
+
+// serve 注册连接并保持到客户端断开
+func (h *WebSocketHub) serve(conn *websocket.Conn) {
 
 	h.mu.Lock()
 	h.clients[conn] = true
@@ -0,0 +1,135 @@
+// Package httpclient provides the shared outbound HTTP clients used by provider adapters.
+//
+// Clients are pooled by key so connections are reused across requests, and every client
+// records connection timings for requests whose context carries a timings hook.
+package httpclient
+
+import (
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Options configures a pooled client. Options must be comparable: a pooled client is
+// rebuilt when it is requested again with different options.
+type Options struct {
+	// Timeout limits the whole request including reading the body (0 = no limit, rely on the context)
+	Timeout               time.Duration
+	ConnectTimeout        time.Duration
+	KeepAlive             time.Duration
+	TLSHandshakeTimeout   time.Duration
+	ResponseHeaderTimeout time.Duration
+	MaxIdleConnsPerHost   int
+	IdleConnTimeout       time.Duration
+	ForceAttemptHTTP2     bool
+	// ProxyURL routes requests through a proxy; empty uses the environment (HTTP_PROXY etc.)
+	ProxyURL  string
+	TLSConfig *tls.Config
+}
+
+// DefaultOptions suits long-running LLM requests: generous connect/TLS timeouts,
+// a warm connection pool and a 10 minute overall limit.
+func DefaultOptions() Options {
+	return Options{
+		Timeout:             10 * time.Minute,
+		ConnectTimeout:      20 * time.Second,
+		KeepAlive:           60 * time.Second,
+		TLSHandshakeTimeout: 20 * time.Second,
+		MaxIdleConnsPerHost: 16,
+		IdleConnTimeout:     90 * time.Second,
+		ForceAttemptHTTP2:   true,
+	}
+}
+
+// APIOptions suits short control-plane calls (token refresh, quota, account info).
+func APIOptions() Options {
+	opts := DefaultOptions()
+	opts.Timeout = 15 * time.Second
+	return opts
+}
+
+type pooledClient struct {
+	opts   Options
+	client *http.Client
+}
+
+var pool struct {
+	mu      sync.Mutex
+	clients map[string]*pooledClient
+}
+
+// Get returns the shared client for key, creating it on first use.
+// If opts differ from the pooled client's options, the client is replaced.
+func Get(key string, opts Options) *http.Client {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.clients == nil {
+		pool.clients = make(map[string]*pooledClient)
+	}
+	if pc, ok := pool.clients[key]; ok {
+		if pc.opts == opts {
+			return pc.client
+		}
+		pc.client.CloseIdleConnections()
+	}
+
+	client := newClient(opts)
+	pool.clients[key] = &pooledClient{opts: opts, client: client}
+	return client
+}
+
+// ForProvider returns the shared client of a provider, with the provider's HTTP settings
+// (proxy, connect timeout) applied on top of opts.
+func ForProvider(p *domain.Provider, opts Options) *http.Client {
+	if p.Config != nil && p.Config.HTTP != nil {
+		cfg := p.Config.HTTP
+		if cfg.ProxyURL != "" {
+			opts.ProxyURL = cfg.ProxyURL
+		}
+		if cfg.ConnectTimeoutSeconds > 0 {
+			opts.ConnectTimeout = time.Duration(cfg.ConnectTimeoutSeconds) * time.Second
+		}
+	}
+	return Get(fmt.Sprintf("provider:%d", p.ID), opts)
+}
+
+func newClient(opts Options) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   opts.ConnectTimeout,
+		KeepAlive: opts.KeepAlive,
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if opts.ProxyURL != "" {
+		if u, err := url.Parse(opts.ProxyURL); err == nil && u.Host != "" {
+			proxy = http.ProxyURL(u)
+		} else {
+			log.Printf("[HTTPClient] Ignoring invalid proxy URL %q", opts.ProxyURL)
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     opts.ForceAttemptHTTP2,
+		MaxIdleConnsPerHost:   opts.MaxIdleConnsPerHost,
+		IdleConnTimeout:       opts.IdleConnTimeout,
+		TLSHandshakeTimeout:   opts.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: opts.ResponseHeaderTimeout,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       opts.TLSConfig,
+	}
+
+	return &http.Client{
+		Transport: &tracingTransport{base: transport},
+		Timeout:   opts.Timeout,
+	}
+}
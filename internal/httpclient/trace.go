@@ -0,0 +1,74 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type timingsHookKey struct{}
+
+// WithTimingsHook returns a context whose outbound requests report their connection
+// timings to hook once response headers arrive. hook runs on the requesting goroutine.
+func WithTimingsHook(ctx context.Context, hook func(domain.UpstreamTimings)) context.Context {
+	return context.WithValue(ctx, timingsHookKey{}, hook)
+}
+
+// tracingTransport measures DNS/connect/TLS/TTFB for requests that carry a timings hook
+// and refuses to start requests whose context is already done.
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	hook, _ := ctx.Value(timingsHookKey{}).(func(domain.UpstreamTimings))
+	if hook == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	var (
+		timings                          domain.UpstreamTimings
+		dnsStart, connectStart, tlsStart time.Time
+		start                            = time.Now()
+	)
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { dnsStart = time.Now() },
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			if !dnsStart.IsZero() {
+				timings.DNS = time.Since(dnsStart)
+			}
+		},
+		ConnectStart: func(string, string) { connectStart = time.Now() },
+		ConnectDone: func(string, string, error) {
+			if !connectStart.IsZero() {
+				timings.Connect = time.Since(connectStart)
+			}
+		},
+		TLSHandshakeStart: func() { tlsStart = time.Now() },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			if !tlsStart.IsZero() {
+				timings.TLS = time.Since(tlsStart)
+			}
+		},
+		GotConn:              func(info httptrace.GotConnInfo) { timings.ConnReused = info.Reused },
+		GotFirstResponseByte: func() { timings.TTFB = time.Since(start) },
+	}
+
+	resp, err := t.base.RoundTrip(req.WithContext(httptrace.WithClientTrace(ctx, trace)))
+	if err == nil {
+		if timings.TTFB == 0 {
+			timings.TTFB = time.Since(start)
+		}
+		hook(timings)
+	}
+	return resp, err
+}
@@ -0,0 +1,102 @@
+// Package i18n localizes the messages maxx itself shows to end users: the error responses
+// sent to API clients and the OAuth result pages. Messages are looked up by their English
+// text, so untranslated ones fall back to English as they were. The language is the
+// "language" setting; when it is unset, the request's Accept-Language header decides.
+package i18n
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Lang is a supported language
+type Lang string
+
+const (
+	English Lang = "en"
+	Chinese Lang = "zh"
+)
+
+var (
+	settingsMu     sync.RWMutex
+	settingsGetter func(key string) (string, error)
+)
+
+// SetSettingsGetter sets the function used to read the language setting at request time
+func SetSettingsGetter(getter func(key string) (string, error)) {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	settingsGetter = getter
+}
+
+// Parse returns the supported language of a language tag such as "zh-CN" or "en"
+func Parse(tag string) (Lang, bool) {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	switch Lang(strings.ToLower(primary)) {
+	case English:
+		return English, true
+	case Chinese:
+		return Chinese, true
+	}
+	return "", false
+}
+
+// Configured returns the language chosen in the settings, if any
+func Configured() (Lang, bool) {
+	settingsMu.RLock()
+	getter := settingsGetter
+	settingsMu.RUnlock()
+	if getter == nil {
+		return "", false
+	}
+	value, err := getter(domain.SettingKeyLanguage)
+	if err != nil {
+		return "", false
+	}
+	return Parse(value)
+}
+
+// FromRequest returns the language of the messages for r: the configured language, else the
+// first supported language of the Accept-Language header, else English
+func FromRequest(r *http.Request) Lang {
+	if lang, ok := Configured(); ok {
+		return lang
+	}
+	if r != nil {
+		for _, entry := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+			tag, _, _ := strings.Cut(entry, ";")
+			if lang, ok := Parse(tag); ok {
+				return lang
+			}
+		}
+	}
+	return English
+}
+
+// T returns the translation of an English message
+func T(lang Lang, message string) string {
+	if translated, ok := catalogs[lang][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf formats the translation of an English format string
+func Sprintf(lang Lang, format string, args ...any) string {
+	return fmt.Sprintf(T(lang, format), args...)
+}
+
+// Error returns the message of an error generated by maxx: in English the error itself, in
+// other languages a summary of its code followed by the (English) details
+func Error(lang Lang, code domain.ErrorCode, detail string) string {
+	summary, ok := errorSummaries[lang][code]
+	if !ok {
+		return detail
+	}
+	return fmt.Sprintf(T(lang, "%s (%s)"), summary, detail)
+}
@@ -0,0 +1,58 @@
+package i18n
+
+import "github.com/awsl-project/maxx/internal/domain"
+
+// catalogs maps English messages and format strings to their translations
+var catalogs = map[Lang]map[string]string{
+	Chinese: {
+		"%s (%s)": "%s（%s）",
+
+		// Proxy request errors
+		"method not allowed":                                     "不支持该请求方法",
+		"unable to detect client type":                           "无法识别客户端类型",
+		"invalid project proxy path":                             "项目代理路径无效",
+		"project not found":                                      "项目不存在",
+		"missing API token":                                      "缺少 API 令牌",
+		"invalid API token":                                      "API 令牌无效",
+		"API token is disabled":                                  "API 令牌已停用",
+		"API token has expired":                                  "API 令牌已过期",
+		"API token is not allowed for this client type":          "该 API 令牌不允许用于此客户端类型",
+		"failed to read request body":                            "读取请求体失败",
+		"request body is empty":                                  "请求体为空",
+		"request body is not valid JSON":                         "请求体不是有效的 JSON",
+		"request body exceeds the %d MB limit":                   "请求体超过 %d MB 的限制",
+		"unsupported Content-Type %q, expected application/json": "不支持的 Content-Type %q，应为 application/json",
+		"request body was not fully received: the client closed the connection": "请求体未完整接收：客户端已关闭连接",
+
+		// OAuth result pages
+		"Authorization Successful":                                     "授权成功",
+		"Authorization Successful!":                                    "授权成功！",
+		"You can now close this window and return to the application.": "现在可以关闭此窗口并返回应用。",
+		"Authorization Failed":                                         "授权失败",
+		"Please return to the application and try again.":              "请返回应用后重试。",
+		"Missing code or state parameter":                              "缺少 code 或 state 参数",
+		"Invalid or expired state":                                     "state 无效或已过期",
+		"Token exchange failed: %v":                                    "令牌交换失败：%v",
+		"Failed to fetch user info: %v":                                "获取用户信息失败：%v",
+	},
+}
+
+// errorSummaries describes each error code for the error messages generated by maxx
+var errorSummaries = map[Lang]map[domain.ErrorCode]string{
+	Chinese: {
+		domain.ErrorCodeNoRoutes:            "没有可用的路由",
+		domain.ErrorCodeAuthFailed:          "上游认证失败",
+		domain.ErrorCodeQuotaExhausted:      "上游配额已用完",
+		domain.ErrorCodeRateLimited:         "请求过于频繁",
+		domain.ErrorCodeConversionFailed:    "请求格式转换失败",
+		domain.ErrorCodeInvalidRequest:      "请求无效",
+		domain.ErrorCodeUpstream4xx:         "上游拒绝了请求",
+		domain.ErrorCodeUpstream5xx:         "上游服务出错",
+		domain.ErrorCodeUpstreamUnreachable: "无法连接上游服务",
+		domain.ErrorCodeTimeout:             "上游响应超时",
+		domain.ErrorCodeClientAbort:         "客户端已断开连接",
+		domain.ErrorCodeContentBlocked:      "内容被上游过滤",
+		domain.ErrorCodeEmptyResponse:       "上游返回了空响应",
+		domain.ErrorCodeInternal:            "内部错误",
+	},
+}
@@ -0,0 +1,117 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// antigravityManagerAccount is one account file from Antigravity-Manager's accounts
+// directory, or one entry of its account export
+type antigravityManagerAccount struct {
+	Email string `json:"email"`
+	Token *struct {
+		RefreshToken string `json:"refresh_token"`
+		ProjectID    string `json:"project_id"`
+		Email        string `json:"email"`
+	} `json:"token"`
+	// The account export puts the token fields at the top level
+	RefreshToken string `json:"refresh_token"`
+	ProjectID    string `json:"project_id"`
+	Disabled     bool   `json:"disabled"`
+}
+
+func parseAntigravityManagerAccounts(plan *Plan, file string, doc any) {
+	var accounts []antigravityManagerAccount
+	if list, ok := doc.([]any); ok {
+		if err := decodeInto(list, &accounts); err != nil {
+			plan.warnf("%s: %v", file, err)
+			return
+		}
+	} else {
+		var account antigravityManagerAccount
+		if err := decodeInto(doc, &account); err != nil {
+			plan.warnf("%s: %v", file, err)
+			return
+		}
+		accounts = append(accounts, account)
+	}
+
+	for i, a := range accounts {
+		origin := file
+		if len(accounts) > 1 {
+			origin = fmt.Sprintf("%s[%d]", file, i)
+		}
+		refreshToken, projectID, email := a.RefreshToken, a.ProjectID, a.Email
+		if a.Token != nil {
+			refreshToken = firstNonEmpty(a.Token.RefreshToken, refreshToken)
+			projectID = firstNonEmpty(a.Token.ProjectID, projectID)
+			email = firstNonEmpty(email, a.Token.Email)
+		}
+		switch {
+		case a.Disabled:
+			plan.warnf("%s: skipped, the account is disabled", origin)
+		case refreshToken == "":
+			plan.warnf("%s: skipped, no refresh_token", origin)
+		default:
+			plan.add(antigravityProvider(email, refreshToken, projectID), nil, origin)
+		}
+	}
+}
+
+// antigravityManagerConfig is the part of gui_config.json that maps to maxx
+type antigravityManagerConfig struct {
+	Proxy struct {
+		CustomMapping    map[string]string `json:"custom_mapping"`
+		AnthropicMapping map[string]string `json:"anthropic_mapping"`
+		OpenAIMapping    map[string]string `json:"openai_mapping"`
+	} `json:"proxy"`
+}
+
+// parseAntigravityManagerConfig returns the custom model mappings, which apply to
+// every Antigravity account. The per-protocol series mappings select model families
+// rather than models and have no maxx equivalent.
+func parseAntigravityManagerConfig(plan *Plan, file string, doc any) []domain.ModelMappingRule {
+	var cfg antigravityManagerConfig
+	if err := decodeInto(doc, &cfg); err != nil {
+		plan.warnf("%s: %v", file, err)
+		return nil
+	}
+	if len(cfg.Proxy.AnthropicMapping) > 0 || len(cfg.Proxy.OpenAIMapping) > 0 {
+		plan.warnf("%s: anthropic_mapping and openai_mapping were not imported; add equivalent model mappings manually", file)
+	}
+
+	patterns := make([]string, 0, len(cfg.Proxy.CustomMapping))
+	for pattern := range cfg.Proxy.CustomMapping {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var rules []domain.ModelMappingRule
+	for _, pattern := range patterns {
+		if target := cfg.Proxy.CustomMapping[pattern]; target != "" && target != pattern {
+			rules = append(rules, domain.ModelMappingRule{Pattern: pattern, Target: target})
+		}
+	}
+	return rules
+}
+
+func antigravityProvider(email, refreshToken, projectID string) *domain.Provider {
+	endpoint := ""
+	if projectID != "" {
+		endpoint = "https://us-central1-aiplatform.googleapis.com/v1/projects/" + projectID + "/locations/us-central1"
+	}
+	return &domain.Provider{
+		Type: "antigravity",
+		Name: firstNonEmpty(email, "Antigravity Account"),
+		Config: &domain.ProviderConfig{
+			Antigravity: &domain.ProviderConfigAntigravity{
+				Email:        email,
+				RefreshToken: refreshToken,
+				ProjectID:    projectID,
+				Endpoint:     endpoint,
+			},
+		},
+	}
+}
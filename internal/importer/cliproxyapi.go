@@ -0,0 +1,238 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// cliProxyAPIConfig is the part of CLIProxyAPI's config.yaml that maps to providers
+type cliProxyAPIConfig struct {
+	ProxyURL string `json:"proxy-url"`
+
+	ClaudeKeys []cliProxyAPIKey `json:"claude-api-key"`
+	CodexKeys  []cliProxyAPIKey `json:"codex-api-key"`
+	GeminiKeys []cliProxyAPIKey `json:"gemini-api-key"`
+	// Older releases listed bare Gemini keys here
+	GenerativeLanguageKeys []string `json:"generative-language-api-key"`
+
+	OpenAICompatibility []cliProxyAPICompat `json:"openai-compatibility"`
+}
+
+type cliProxyAPIKey struct {
+	APIKey   string            `json:"api-key"`
+	BaseURL  string            `json:"base-url"`
+	ProxyURL string            `json:"proxy-url"`
+	Headers  map[string]string `json:"headers"`
+	Models   []modelAlias      `json:"models"`
+}
+
+type cliProxyAPICompat struct {
+	Name          string `json:"name"`
+	BaseURL       string `json:"base-url"`
+	APIKeyEntries []struct {
+		APIKey   string `json:"api-key"`
+		ProxyURL string `json:"proxy-url"`
+	} `json:"api-key-entries"`
+	// Older releases listed bare keys here
+	APIKeys []string          `json:"api-keys"`
+	Headers map[string]string `json:"headers"`
+	Models  []modelAlias      `json:"models"`
+}
+
+// Default upstreams CLIProxyAPI uses when an entry has no base-url
+const (
+	cliProxyAPIClaudeBaseURL = "https://api.anthropic.com"
+	cliProxyAPIGeminiBaseURL = "https://generativelanguage.googleapis.com"
+)
+
+func parseCLIProxyAPIConfig(plan *Plan, file string, doc any) {
+	var cfg cliProxyAPIConfig
+	if err := decodeInto(doc, &cfg); err != nil {
+		plan.warnf("%s: %v", file, err)
+		return
+	}
+
+	addKey := func(section string, i int, k cliProxyAPIKey, clientType domain.ClientType, label, defaultBaseURL string) {
+		origin := fmt.Sprintf("%s: %s[%d]", file, section, i)
+		if k.APIKey == "" {
+			plan.warnf("%s: skipped, no api-key", origin)
+			return
+		}
+		baseURL := strings.TrimSuffix(k.BaseURL, "/")
+		if baseURL == "" {
+			baseURL = defaultBaseURL
+		}
+		if baseURL == "" {
+			plan.warnf("%s: skipped, no base-url", origin)
+			return
+		}
+		if len(k.Headers) > 0 {
+			plan.warnf("%s: custom headers are not supported and were ignored", origin)
+		}
+		plan.add(customProvider(
+			fmt.Sprintf("%s %s", label, keyLabel(baseURL, k.APIKey)),
+			baseURL, k.APIKey, clientType, firstNonEmpty(k.ProxyURL, cfg.ProxyURL),
+		), aliasMappings(k.Models), origin)
+	}
+
+	for i, k := range cfg.ClaudeKeys {
+		addKey("claude-api-key", i, k, domain.ClientTypeClaude, "Claude", cliProxyAPIClaudeBaseURL)
+	}
+	for i, k := range cfg.CodexKeys {
+		// Codex entries have no default upstream: CLIProxyAPI only uses API keys
+		// for third-party Codex relays
+		addKey("codex-api-key", i, k, domain.ClientTypeCodex, "Codex", "")
+	}
+	for i, k := range cfg.GeminiKeys {
+		addKey("gemini-api-key", i, k, domain.ClientTypeGemini, "Gemini", cliProxyAPIGeminiBaseURL)
+	}
+	for i, key := range cfg.GenerativeLanguageKeys {
+		addKey("generative-language-api-key", i, cliProxyAPIKey{APIKey: key}, domain.ClientTypeGemini, "Gemini", cliProxyAPIGeminiBaseURL)
+	}
+
+	for i, c := range cfg.OpenAICompatibility {
+		origin := fmt.Sprintf("%s: openai-compatibility[%d]", file, i)
+		// CLIProxyAPI appends /chat/completions to base-url; maxx forwards the
+		// client's /v1/chat/completions path, so the version segment is dropped here
+		baseURL := strings.TrimSuffix(strings.TrimSuffix(c.BaseURL, "/"), "/v1")
+		if baseURL == "" {
+			plan.warnf("%s: skipped, no base-url", origin)
+			continue
+		}
+		if len(c.Headers) > 0 {
+			plan.warnf("%s: custom headers are not supported and were ignored", origin)
+		}
+
+		type entry struct{ key, proxy string }
+		var entries []entry
+		for _, e := range c.APIKeyEntries {
+			if e.APIKey != "" {
+				entries = append(entries, entry{e.APIKey, e.ProxyURL})
+			}
+		}
+		for _, key := range c.APIKeys {
+			if key != "" {
+				entries = append(entries, entry{key, ""})
+			}
+		}
+		if len(entries) == 0 {
+			plan.warnf("%s: skipped, no API keys", origin)
+			continue
+		}
+
+		// Only the listed models are routed to this provider, as in CLIProxyAPI
+		var supported []string
+		for _, m := range c.Models {
+			if m.Alias != "" {
+				supported = append(supported, m.Alias)
+			} else if m.Name != "" {
+				supported = append(supported, m.Name)
+			}
+		}
+
+		name := firstNonEmpty(c.Name, "OpenAI Compatible")
+		for j, e := range entries {
+			providerName := name
+			if len(entries) > 1 {
+				providerName = fmt.Sprintf("%s …%s", name, lastFour(e.key))
+			}
+			p := customProvider(providerName, baseURL, e.key, domain.ClientTypeOpenAI, firstNonEmpty(e.proxy, cfg.ProxyURL))
+			p.SupportModels = supported
+			plan.add(p, aliasMappings(c.Models), fmt.Sprintf("%s key %d", origin, j))
+		}
+	}
+}
+
+// cliProxyAPIAuth is a token file from CLIProxyAPI's auth-dir
+type cliProxyAPIAuth struct {
+	Type         string `json:"type"`
+	Email        string `json:"email"`
+	RefreshToken string `json:"refresh_token"`
+	ProjectID    string `json:"project_id"`
+	Disabled     bool   `json:"disabled"`
+
+	// Kiro
+	AuthMethod   string `json:"auth_method"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Region       string `json:"region"`
+}
+
+func parseCLIProxyAPIAuth(plan *Plan, file string, doc any) {
+	var auth cliProxyAPIAuth
+	if err := decodeInto(doc, &auth); err != nil {
+		plan.warnf("%s: %v", file, err)
+		return
+	}
+	if auth.Disabled {
+		plan.warnf("%s: skipped, the account is disabled", file)
+		return
+	}
+	if auth.RefreshToken == "" && (auth.Type == "antigravity" || auth.Type == "kiro") {
+		plan.warnf("%s: skipped, no refresh_token", file)
+		return
+	}
+
+	switch auth.Type {
+	case "antigravity":
+		plan.add(antigravityProvider(auth.Email, auth.RefreshToken, auth.ProjectID), nil, file)
+	case "kiro":
+		method := "social"
+		if auth.AuthMethod == "idc" || auth.AuthMethod == "builder-id" {
+			method = "idc"
+		}
+		name := firstNonEmpty(auth.Email, "Kiro Account")
+		plan.add(&domain.Provider{
+			Type: "kiro",
+			Name: name,
+			Config: &domain.ProviderConfig{
+				Kiro: &domain.ProviderConfigKiro{
+					AuthMethod:   method,
+					RefreshToken: auth.RefreshToken,
+					Region:       auth.Region,
+					ClientID:     auth.ClientID,
+					ClientSecret: auth.ClientSecret,
+					Email:        auth.Email,
+				},
+			},
+		}, nil, file)
+	default:
+		plan.warnf("%s: %q OAuth accounts have no maxx equivalent and were skipped", file, auth.Type)
+	}
+}
+
+func customProvider(name, baseURL, apiKey string, clientType domain.ClientType, proxyURL string) *domain.Provider {
+	p := &domain.Provider{
+		Type: "custom",
+		Name: name,
+		Config: &domain.ProviderConfig{
+			Custom: &domain.ProviderConfigCustom{
+				BaseURL: baseURL,
+				APIKey:  apiKey,
+			},
+		},
+		SupportedClientTypes: []domain.ClientType{clientType},
+	}
+	if proxyURL != "" {
+		p.Config.HTTP = &domain.ProviderHTTPConfig{ProxyURL: proxyURL}
+	}
+	return p
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func lastFour(s string) string {
+	if len(s) <= 4 {
+		return s
+	}
+	return s[len(s)-4:]
+}
@@ -0,0 +1,238 @@
+// Package importer converts configuration and token files from other proxy tools
+// (CLIProxyAPI and Antigravity-Manager) into maxx providers and model mappings.
+// It only parses; creating the providers, routes and mappings is left to the caller.
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// File formats recognised by Parse
+const (
+	FormatCLIProxyAPIConfig         = "cliproxyapi-config"
+	FormatCLIProxyAPIAuth           = "cliproxyapi-auth"
+	FormatAntigravityManagerAccount = "antigravity-manager-account"
+	FormatAntigravityManagerConfig  = "antigravity-manager-config"
+	FormatAntigravityManagerIndex   = "antigravity-manager-index"
+)
+
+// File is one configuration or token file supplied by the user
+type File struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Plan is everything an import would create
+type Plan struct {
+	// Format detected for each file, keyed by file name
+	Formats   map[string]string
+	Providers []*PlannedProvider
+	Warnings  []string
+}
+
+// PlannedProvider is a provider to create, with the model mappings scoped to it
+type PlannedProvider struct {
+	Provider      *domain.Provider
+	ModelMappings []domain.ModelMappingRule
+	// Where the provider came from, e.g. "config.yaml: claude-api-key[0]"
+	Origin string
+}
+
+// Parse detects the format of each file and converts its contents into a plan.
+// Files that cannot be recognised or contain nothing importable produce warnings
+// rather than errors so that one bad file does not block the rest; only when no file
+// can be read at all is the parse error returned, wrapping domain.ErrInvalidInput.
+func Parse(files []File) (*Plan, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: no files to import", domain.ErrInvalidInput)
+	}
+
+	plan := &Plan{Formats: make(map[string]string, len(files))}
+	var amMappings []domain.ModelMappingRule
+	for _, f := range files {
+		name := path.Base(f.Name)
+		format, doc, err := detect(f)
+		if err != nil {
+			plan.warnf("%s: %v", name, err)
+			continue
+		}
+		plan.Formats[name] = format
+
+		switch format {
+		case FormatCLIProxyAPIConfig:
+			parseCLIProxyAPIConfig(plan, name, doc)
+		case FormatCLIProxyAPIAuth:
+			parseCLIProxyAPIAuth(plan, name, doc)
+		case FormatAntigravityManagerAccount:
+			parseAntigravityManagerAccounts(plan, name, doc)
+		case FormatAntigravityManagerConfig:
+			amMappings = append(amMappings, parseAntigravityManagerConfig(plan, name, doc)...)
+		case FormatAntigravityManagerIndex:
+			// The index only lists account IDs; the tokens live in the per-account files
+		}
+	}
+
+	// Antigravity-Manager's mapping table applies to every account it manages
+	if len(plan.Formats) == 0 {
+		return nil, fmt.Errorf("%w: no readable CLIProxyAPI or Antigravity-Manager file: %s",
+			domain.ErrInvalidInput, strings.Join(plan.Warnings, "; "))
+	}
+
+	if len(amMappings) > 0 {
+		applied := false
+		for _, pp := range plan.Providers {
+			if pp.Provider.Type == "antigravity" {
+				pp.ModelMappings = append(pp.ModelMappings, amMappings...)
+				applied = true
+			}
+		}
+		if !applied {
+			plan.warnf("model mappings from the Antigravity-Manager config were not imported: no Antigravity accounts in the selected files")
+		}
+	}
+
+	plan.Providers = dedupe(plan, plan.Providers)
+	uniqueNames(plan.Providers)
+	return plan, nil
+}
+
+func (p *Plan) warnf(format string, args ...any) {
+	p.Warnings = append(p.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (p *Plan) add(provider *domain.Provider, mappings []domain.ModelMappingRule, origin string) {
+	p.Providers = append(p.Providers, &PlannedProvider{
+		Provider:      provider,
+		ModelMappings: mappings,
+		Origin:        origin,
+	})
+}
+
+// detect returns the file's format and its decoded contents
+func detect(f File) (string, any, error) {
+	ext := strings.ToLower(path.Ext(f.Name))
+	if ext == ".yaml" || ext == ".yml" {
+		doc, err := parseYAML([]byte(f.Content))
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid YAML: %v", err)
+		}
+		return FormatCLIProxyAPIConfig, doc, nil
+	}
+
+	var doc any
+	if err := json.Unmarshal([]byte(f.Content), &doc); err != nil {
+		return "", nil, fmt.Errorf("not a YAML or JSON file: %v", err)
+	}
+	switch v := doc.(type) {
+	case map[string]any:
+		switch {
+		case hasString(v, "type"):
+			return FormatCLIProxyAPIAuth, doc, nil
+		case hasObject(v, "token") || hasString(v, "refresh_token"):
+			return FormatAntigravityManagerAccount, doc, nil
+		case hasObject(v, "proxy"):
+			return FormatAntigravityManagerConfig, doc, nil
+		case v["accounts"] != nil:
+			return FormatAntigravityManagerIndex, doc, nil
+		}
+	case []any:
+		// Antigravity-Manager's account export: [{"email": ..., "refresh_token": ...}]
+		return FormatAntigravityManagerAccount, doc, nil
+	}
+	return "", nil, fmt.Errorf("unrecognised file format")
+}
+
+func hasString(m map[string]any, key string) bool {
+	s, ok := m[key].(string)
+	return ok && s != ""
+}
+
+func hasObject(m map[string]any, key string) bool {
+	_, ok := m[key].(map[string]any)
+	return ok
+}
+
+// decodeInto converts a decoded YAML or JSON document into a typed struct
+func decodeInto(doc any, v any) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// modelAlias is the {name, alias} pair CLIProxyAPI uses to expose upstream models
+type modelAlias struct {
+	Name  string `json:"name"`
+	Alias string `json:"alias"`
+}
+
+// aliasMappings turns alias → upstream name pairs into mapping rules, in order
+func aliasMappings(models []modelAlias) []domain.ModelMappingRule {
+	var rules []domain.ModelMappingRule
+	for _, m := range models {
+		if m.Alias != "" && m.Name != "" && m.Alias != m.Name {
+			rules = append(rules, domain.ModelMappingRule{Pattern: m.Alias, Target: m.Name})
+		}
+	}
+	return rules
+}
+
+// keyLabel identifies an API key by its host and last four characters
+func keyLabel(baseURL, apiKey string) string {
+	host := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	if len(apiKey) > 4 {
+		return fmt.Sprintf("%s …%s", host, lastFour(apiKey))
+	}
+	return host
+}
+
+// dedupe drops providers whose credentials already appear earlier in the plan, which
+// happens when the same account is exported by both tools
+func dedupe(plan *Plan, providers []*PlannedProvider) []*PlannedProvider {
+	seen := make(map[string]string, len(providers))
+	result := providers[:0]
+	for _, pp := range providers {
+		key := credentialKey(pp.Provider)
+		if origin, ok := seen[key]; ok && key != "" {
+			plan.warnf("%s: skipped, same account as %s", pp.Origin, origin)
+			continue
+		}
+		seen[key] = pp.Origin
+		result = append(result, pp)
+	}
+	return result
+}
+
+func credentialKey(p *domain.Provider) string {
+	switch {
+	case p.Config.Custom != nil:
+		return "custom|" + p.Config.Custom.BaseURL + "|" + p.Config.Custom.APIKey
+	case p.Config.Antigravity != nil:
+		return "antigravity|" + p.Config.Antigravity.RefreshToken
+	case p.Config.Kiro != nil:
+		return "kiro|" + p.Config.Kiro.RefreshToken
+	}
+	return ""
+}
+
+// uniqueNames appends " (2)", " (3)", ... to providers that share a name
+func uniqueNames(providers []*PlannedProvider) {
+	seen := make(map[string]int, len(providers))
+	for _, pp := range providers {
+		name := pp.Provider.Name
+		seen[name]++
+		if n := seen[name]; n > 1 {
+			pp.Provider.Name = fmt.Sprintf("%s (%d)", name, n)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Package jsondiff computes the differences between two JSON documents as a list of
+// changes addressed by JSON pointers (RFC 6901). Arrays are compared element by element, so
+// the result is meant for display, not as a minimal patch.
+package jsondiff
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Change operations
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Compare diffs two JSON texts. Comparable is false when either is not a JSON document,
+// e.g. an SSE stream. At most limit changes are returned (0 means no limit).
+func Compare(from, to string, limit int) *domain.BodyDiff {
+	var a, b interface{}
+	if json.Unmarshal([]byte(from), &a) != nil || json.Unmarshal([]byte(to), &b) != nil {
+		return &domain.BodyDiff{Changes: []domain.JSONChange{}}
+	}
+	d := &differ{limit: limit, changes: []domain.JSONChange{}}
+	d.diff("", a, b)
+	return &domain.BodyDiff{Comparable: true, Changes: d.changes, Truncated: d.truncated}
+}
+
+type differ struct {
+	limit     int
+	changes   []domain.JSONChange
+	truncated bool
+}
+
+func (d *differ) add(c domain.JSONChange) {
+	if d.limit > 0 && len(d.changes) >= d.limit {
+		d.truncated = true
+		return
+	}
+	d.changes = append(d.changes, c)
+}
+
+func (d *differ) diff(path string, a, b interface{}) {
+	if d.truncated {
+		return
+	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		if bv, ok := b.(map[string]interface{}); ok {
+			d.diffObjects(path, av, bv)
+			return
+		}
+	case []interface{}:
+		if bv, ok := b.([]interface{}); ok {
+			d.diffArrays(path, av, bv)
+			return
+		}
+	}
+	if !reflect.DeepEqual(a, b) {
+		d.add(domain.JSONChange{Op: OpReplace, Path: path, From: a, To: b})
+	}
+}
+
+func (d *differ) diffObjects(path string, a, b map[string]interface{}) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		p := path + "/" + escape(k)
+		av, inA := a[k]
+		bv, inB := b[k]
+		switch {
+		case !inB:
+			d.add(domain.JSONChange{Op: OpRemove, Path: p, From: av})
+		case !inA:
+			d.add(domain.JSONChange{Op: OpAdd, Path: p, To: bv})
+		default:
+			d.diff(p, av, bv)
+		}
+	}
+}
+
+func (d *differ) diffArrays(path string, a, b []interface{}) {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		p := path + "/" + strconv.Itoa(i)
+		switch {
+		case i >= len(b):
+			d.add(domain.JSONChange{Op: OpRemove, Path: p, From: a[i]})
+		case i >= len(a):
+			d.add(domain.JSONChange{Op: OpAdd, Path: p, To: b[i]})
+		default:
+			d.diff(p, a[i], b[i])
+		}
+	}
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func escape(key string) string {
+	return pointerEscaper.Replace(key)
+}
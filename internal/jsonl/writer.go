@@ -0,0 +1,122 @@
+// Package jsonl appends lines to a JSONL file that rotates by size: name.jsonl is renamed to
+// name.jsonl.1 (and older files shift up) once it reaches the size limit, and files beyond
+// the backup count are removed. The audit log and the quality samples are written with it.
+package jsonl
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// Writer appends lines to a rotating JSONL file
+type Writer struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewWriter creates a writer rotating after maxSize bytes and keeping maxBackups rotated
+// files; it writes nothing until a path is set
+func NewWriter(maxSize int64, maxBackups int) *Writer {
+	return &Writer{maxSize: maxSize, maxBackups: maxBackups}
+}
+
+// SetPath sets the file, closing the previous one
+func (w *Writer) SetPath(path string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closeLocked()
+	w.path = path
+}
+
+// Path returns the file, or "" if none is set
+func (w *Writer) Path() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.path
+}
+
+// Append writes one line (ending in a newline), rotating the file first if the line
+// would take it past the size limit. It does nothing while no path is set.
+func (w *Writer) Append(line []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.path == "" {
+		return nil
+	}
+	if err := w.openLocked(); err != nil {
+		return fmt.Errorf("open %s: %w", w.path, err)
+	}
+	if w.size > 0 && w.size+int64(len(line)) > w.maxSize {
+		if err := w.rotateLocked(); err != nil {
+			return fmt.Errorf("rotate %s: %w", w.path, err)
+		}
+	}
+	n, err := w.file.Write(line)
+	w.size += int64(n)
+	return err
+}
+
+// Files returns the file and its rotated backups, oldest first, or nil if no path is set
+func (w *Writer) Files() []string {
+	w.mu.Lock()
+	path, maxBackups := w.path, w.maxBackups
+	w.mu.Unlock()
+	if path == "" {
+		return nil
+	}
+	files := make([]string, 0, maxBackups+1)
+	for i := maxBackups; i >= 1; i-- {
+		files = append(files, backupName(path, i))
+	}
+	return append(files, path)
+}
+
+func (w *Writer) openLocked() error {
+	if w.file != nil {
+		return nil
+	}
+	f, err := os.OpenFile(w.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file, w.size = f, info.Size()
+	return nil
+}
+
+func (w *Writer) closeLocked() {
+	if w.file != nil {
+		w.file.Close()
+		w.file, w.size = nil, 0
+	}
+}
+
+// rotateLocked shifts name.jsonl.N to .N+1, dropping the oldest, and starts a new file
+func (w *Writer) rotateLocked() error {
+	w.closeLocked()
+	os.Remove(backupName(w.path, w.maxBackups))
+	for i := w.maxBackups - 1; i >= 1; i-- {
+		os.Rename(backupName(w.path, i), backupName(w.path, i+1))
+	}
+	if w.maxBackups > 0 {
+		if err := os.Rename(w.path, backupName(w.path, 1)); err != nil {
+			return err
+		}
+	} else {
+		os.Remove(w.path)
+	}
+	return w.openLocked()
+}
+
+func backupName(path string, n int) string {
+	return fmt.Sprintf("%s.%d", path, n)
+}
@@ -0,0 +1,127 @@
+// Package loopdetect flags sessions that keep sending the same request in rapid
+// succession, as agents stuck in a retry or tool-call loop do.
+package loopdetect
+
+import (
+	"sync"
+	"time"
+)
+
+// Config controls when repeated requests count as a loop
+type Config struct {
+	// Threshold is how many similar requests within Window make a loop; <= 0 disables detection
+	Threshold int
+	Window    time.Duration
+}
+
+// Enabled reports whether detection is on
+func (c Config) Enabled() bool {
+	return c.Threshold > 0 && c.Window > 0
+}
+
+// Result is the outcome of observing a request
+type Result struct {
+	// Count is the number of similar requests of the session within the window,
+	// including this one
+	Count int
+	// Looping is true once Count reaches the threshold
+	Looping bool
+	// NewLoop is true for the request that reached the threshold, so a loop is
+	// reported once rather than on every further repetition
+	NewLoop bool
+	// Until is when the session falls below the threshold if it stops repeating
+	Until time.Time
+}
+
+type hit struct {
+	fingerprint uint64
+	at          time.Time
+}
+
+// sweepInterval is how often idle sessions are dropped
+const sweepInterval = time.Minute
+
+// Detector tracks the recent request fingerprints of each session
+type Detector struct {
+	mu        sync.Mutex
+	sessions  map[string][]hit
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewDetector creates an empty detector
+func NewDetector() *Detector {
+	return &Detector{
+		sessions: make(map[string][]hit),
+		now:      time.Now,
+	}
+}
+
+var (
+	defaultDetector     *Detector
+	defaultDetectorOnce sync.Once
+)
+
+// Default returns the process-wide detector
+func Default() *Detector {
+	defaultDetectorOnce.Do(func() {
+		defaultDetector = NewDetector()
+	})
+	return defaultDetector
+}
+
+// Observe records a request of sessionID with the given fingerprint and reports
+// whether the session is looping on it
+func (d *Detector) Observe(sessionID string, fingerprint uint64, cfg Config) Result {
+	if !cfg.Enabled() || sessionID == "" {
+		return Result{}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := d.now()
+	cutoff := now.Add(-cfg.Window)
+	if now.Sub(d.lastSweep) >= sweepInterval {
+		d.sweep(cutoff)
+		d.lastSweep = now
+	}
+
+	hits := expire(d.sessions[sessionID], cutoff)
+	hits = append(hits, hit{fingerprint: fingerprint, at: now})
+	d.sessions[sessionID] = hits
+
+	var similar []time.Time
+	for _, h := range hits {
+		if h.fingerprint == fingerprint {
+			similar = append(similar, h.at)
+		}
+	}
+
+	result := Result{Count: len(similar)}
+	if result.Count >= cfg.Threshold {
+		result.Looping = true
+		result.NewLoop = result.Count == cfg.Threshold
+		// Once the oldest requests that keep the count at the threshold expire
+		result.Until = similar[result.Count-cfg.Threshold].Add(cfg.Window)
+	}
+	return result
+}
+
+// sweep drops sessions with no request after cutoff. Caller holds d.mu.
+func (d *Detector) sweep(cutoff time.Time) {
+	for id, hits := range d.sessions {
+		if len(hits) == 0 || hits[len(hits)-1].at.Before(cutoff) {
+			delete(d.sessions, id)
+		}
+	}
+}
+
+// expire drops hits at or before cutoff; hits are in time order
+func expire(hits []hit, cutoff time.Time) []hit {
+	i := 0
+	for i < len(hits) && !hits[i].at.After(cutoff) {
+		i++
+	}
+	return hits[i:]
+}
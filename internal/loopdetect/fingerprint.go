@@ -0,0 +1,81 @@
+package loopdetect
+
+import (
+	"encoding/json"
+	"hash/fnv"
+)
+
+// idKeys are per-call identifiers that differ between otherwise identical tool calls
+// and results (Claude tool_use/tool_result, OpenAI tool calls, Responses API items)
+var idKeys = map[string]bool{
+	"id":           true,
+	"tool_use_id":  true,
+	"tool_call_id": true,
+	"call_id":      true,
+}
+
+// Fingerprint identifies what a request asks for: the model and the last message of the
+// conversation, with per-call IDs removed. An agent stuck in a loop sends the same last
+// message (the same prompt, or the same tool result) over and over while its history
+// grows. ok is false if the body has no conversation in a known format.
+func Fingerprint(model string, body []byte) (uint64, bool) {
+	var req map[string]interface{}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return 0, false
+	}
+	// Gemini CLI wraps the request in an envelope
+	if inner, ok := req["request"].(map[string]interface{}); ok {
+		req = inner
+	}
+
+	var last interface{}
+	for _, key := range []string{"messages", "input", "contents"} {
+		switch items := req[key].(type) {
+		case []interface{}:
+			if len(items) > 0 {
+				last = items[len(items)-1]
+			}
+		case string:
+			// Responses API shorthand for a single user message
+			last = items
+		}
+		if last != nil {
+			break
+		}
+	}
+	if last == nil {
+		return 0, false
+	}
+
+	// Map keys are marshaled in sorted order, so equal messages encode the same
+	canonical, err := json.Marshal(stripIDs(last))
+	if err != nil {
+		return 0, false
+	}
+	h := fnv.New64a()
+	h.Write([]byte(model))
+	h.Write([]byte{0})
+	h.Write(canonical)
+	return h.Sum64(), true
+}
+
+// stripIDs returns v without idKeys at any depth
+func stripIDs(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(node))
+		for k, child := range node {
+			if !idKeys[k] {
+				out[k] = stripIDs(child)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(node))
+		for i, child := range node {
+			out[i] = stripIDs(child)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,238 @@
+// Package mappinglearn records how model mappings fare upstream and suggests mapping rules
+// from that history. When learning mode is on, every upstream attempt is recorded as
+// (provider, requested model -> mapped model, success/failure); mappings that keep failing
+// are reported together with a target or provider that served the same models better.
+package mappinglearn
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// maxEntries bounds memory use; the least recently seen entry is evicted first
+const maxEntries = 2000
+
+// Outcome is the result of one upstream attempt
+type Outcome struct {
+	ProviderID   uint64
+	ProviderName string
+	RequestModel string
+	MappedModel  string
+	Success      bool
+}
+
+type entryKey struct {
+	providerID   uint64
+	requestModel string
+	mappedModel  string
+}
+
+type entry struct {
+	providerName string
+	attempts     uint64
+	failures     uint64
+	lastSeen     time.Time
+}
+
+// Learner aggregates mapping outcomes in memory
+type Learner struct {
+	mu      sync.Mutex
+	entries map[entryKey]*entry
+}
+
+// NewLearner creates an empty learner
+func NewLearner() *Learner {
+	return &Learner{entries: make(map[entryKey]*entry)}
+}
+
+var defaultLearner = NewLearner()
+
+// Default returns the global learner
+func Default() *Learner {
+	return defaultLearner
+}
+
+// Record adds the outcome of an attempt
+func (l *Learner) Record(o Outcome) {
+	if o.RequestModel == "" || o.MappedModel == "" {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	k := entryKey{providerID: o.ProviderID, requestModel: o.RequestModel, mappedModel: o.MappedModel}
+	e, ok := l.entries[k]
+	if !ok {
+		if len(l.entries) >= maxEntries {
+			l.evictOldestLocked()
+		}
+		e = &entry{}
+		l.entries[k] = e
+	}
+	e.providerName = o.ProviderName
+	e.attempts++
+	if !o.Success {
+		e.failures++
+	}
+	e.lastSeen = time.Now()
+}
+
+func (l *Learner) evictOldestLocked() {
+	var oldestKey entryKey
+	var oldest time.Time
+	for k, e := range l.entries {
+		if oldest.IsZero() || e.lastSeen.Before(oldest) {
+			oldestKey, oldest = k, e.lastSeen
+		}
+	}
+	delete(l.entries, oldestKey)
+}
+
+// Reset forgets all recorded outcomes
+func (l *Learner) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = make(map[entryKey]*entry)
+}
+
+// Outcomes returns the recorded outcomes, most failures first
+func (l *Learner) Outcomes() []*domain.ModelMappingOutcome {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make([]*domain.ModelMappingOutcome, 0, len(l.entries))
+	for k, e := range l.entries {
+		result = append(result, &domain.ModelMappingOutcome{
+			ProviderID:   k.providerID,
+			ProviderName: e.providerName,
+			RequestModel: k.requestModel,
+			MappedModel:  k.mappedModel,
+			Attempts:     e.attempts,
+			Failures:     e.failures,
+			FailureRate:  float64(e.failures) / float64(e.attempts),
+			LastSeen:     e.lastSeen,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Failures != result[j].Failures {
+			return result[i].Failures > result[j].Failures
+		}
+		return result[i].Attempts > result[j].Attempts
+	})
+	return result
+}
+
+// group aggregates the entries of one provider, source pattern and target
+type group struct {
+	providerID   uint64
+	providerName string
+	pattern      string
+	target       string
+	attempts     uint64
+	failures     uint64
+}
+
+func (g *group) failureRate() float64 {
+	return float64(g.failures) / float64(g.attempts)
+}
+
+// Suggest returns a suggestion for every mapping that failed at least maxFailureRate of at
+// least minAttempts attempts, most failures first
+func (l *Learner) Suggest(minAttempts uint64, maxFailureRate float64) []*domain.ModelMappingSuggestion {
+	groups := l.groups()
+
+	var suggestions []*domain.ModelMappingSuggestion
+	for _, g := range groups {
+		if g.attempts < minAttempts || g.failureRate() < maxFailureRate {
+			continue
+		}
+		s := &domain.ModelMappingSuggestion{
+			ProviderID:    g.providerID,
+			ProviderName:  g.providerName,
+			Pattern:       g.pattern,
+			CurrentTarget: g.target,
+			Attempts:      g.attempts,
+			Failures:      g.failures,
+			FailureRate:   g.failureRate(),
+		}
+
+		// Prefer another target on the same provider, then another provider
+		var sameProvider, otherProvider *group
+		for _, alt := range groups {
+			if alt == g || alt.pattern != g.pattern || alt.attempts < minAttempts || alt.failureRate() >= maxFailureRate {
+				continue
+			}
+			if alt.providerID == g.providerID {
+				if alt.target != g.target && (sameProvider == nil || alt.failureRate() < sameProvider.failureRate()) {
+					sameProvider = alt
+				}
+			} else if otherProvider == nil || alt.failureRate() < otherProvider.failureRate() {
+				otherProvider = alt
+			}
+		}
+
+		switch {
+		case sameProvider != nil:
+			s.SuggestedTarget = sameProvider.target
+			s.SuggestedTargetFailureRate = sameProvider.failureRate()
+			s.Reason = fmt.Sprintf("%s frequently fails on %s (%.0f%% of %d attempts), consider mapping to %s (%.0f%% failures)",
+				g.pattern, g.providerName, s.FailureRate*100, g.attempts, sameProvider.target, s.SuggestedTargetFailureRate*100)
+		case otherProvider != nil:
+			s.AlternativeProviderID = otherProvider.providerID
+			s.AlternativeProviderName = otherProvider.providerName
+			s.Reason = fmt.Sprintf("%s frequently fails on %s (%.0f%% of %d attempts), %s serves it as %s with %.0f%% failures",
+				g.pattern, g.providerName, s.FailureRate*100, g.attempts, otherProvider.providerName, otherProvider.target, otherProvider.failureRate()*100)
+		default:
+			s.Reason = fmt.Sprintf("%s frequently fails on %s (%.0f%% of %d attempts), no better target has been seen yet",
+				g.pattern, g.providerName, s.FailureRate*100, g.attempts)
+		}
+		suggestions = append(suggestions, s)
+	}
+
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Failures > suggestions[j].Failures
+	})
+	return suggestions
+}
+
+func (l *Learner) groups() []*group {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	type groupKey struct {
+		providerID uint64
+		pattern    string
+		target     string
+	}
+	byKey := make(map[groupKey]*group)
+	var groups []*group
+	for k, e := range l.entries {
+		gk := groupKey{providerID: k.providerID, pattern: Pattern(k.requestModel), target: k.mappedModel}
+		g, ok := byKey[gk]
+		if !ok {
+			g = &group{providerID: gk.providerID, pattern: gk.pattern, target: gk.target}
+			byKey[gk] = g
+			groups = append(groups, g)
+		}
+		g.providerName = e.providerName
+		g.attempts += e.attempts
+		g.failures += e.failures
+	}
+	return groups
+}
+
+var dateSuffix = regexp.MustCompile(`-(\d{8}|\d{4}-\d{2}-\d{2}|latest)$`)
+
+// Pattern generalizes a model name to the mapping pattern suggested for it, replacing a
+// date or "latest" suffix with a wildcard (claude-3-7-sonnet-20250219 -> claude-3-7-sonnet-*)
+func Pattern(model string) string {
+	if loc := dateSuffix.FindStringIndex(model); loc != nil {
+		return model[:loc[0]] + "-*"
+	}
+	return model
+}
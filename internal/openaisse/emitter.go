@@ -0,0 +1,209 @@
+// Package openaisse emits OpenAI chat completion stream chunks.
+//
+// Emitter keeps a converted stream consistent with what OpenAI itself sends: every
+// chunk carries the same id, created and model, the first delta has the assistant
+// role, tool calls are numbered in the order they start and only their first delta
+// carries the id and name, finish_reason is sent exactly once, followed by the usage
+// chunk if the client asked for it (stream_options.include_usage) and [DONE].
+package openaisse
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// Usage is the token usage of a completion
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type chunk struct {
+	ID      string   `json:"id"`
+	Object  string   `json:"object"`
+	Created int64    `json:"created"`
+	Model   string   `json:"model"`
+	Choices []choice `json:"choices"`
+	Usage   *Usage   `json:"usage,omitempty"`
+}
+
+type choice struct {
+	Index        int     `json:"index"`
+	Delta        delta   `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+type delta struct {
+	Role             string     `json:"role,omitempty"`
+	Content          *string    `json:"content,omitempty"`
+	ReasoningContent *string    `json:"reasoning_content,omitempty"`
+	ToolCalls        []toolCall `json:"tool_calls,omitempty"`
+}
+
+type toolCall struct {
+	Index    int      `json:"index"`
+	ID       string   `json:"id,omitempty"`
+	Type     string   `json:"type,omitempty"`
+	Function function `json:"function"`
+}
+
+type function struct {
+	Name      string `json:"name,omitempty"`
+	Arguments string `json:"arguments"`
+}
+
+// Emitter produces the chunks of one chat completion stream
+type Emitter struct {
+	id           string
+	model        string
+	created      int64
+	includeUsage bool
+
+	started   bool
+	finished  bool
+	toolCalls map[int]int // Caller's key of a tool call -> its tool_calls index
+	usage     Usage
+}
+
+// NewEmitter creates an emitter. includeUsage is the client's
+// stream_options.include_usage.
+func NewEmitter(includeUsage bool) *Emitter {
+	return &Emitter{
+		created:      time.Now().Unix(),
+		includeUsage: includeUsage,
+		toolCalls:    make(map[int]int),
+	}
+}
+
+// SetMessage sets the completion id and model. They are fixed by the first chunk
+// sent; later calls only fill in values still missing.
+func (e *Emitter) SetMessage(id, model string) {
+	if e.id == "" && !e.started {
+		e.id = id
+	}
+	if e.model == "" && !e.started {
+		e.model = model
+	}
+}
+
+// SetUsage records the token usage reported upstream
+func (e *Emitter) SetUsage(promptTokens, completionTokens int) {
+	e.usage = Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+	}
+}
+
+// Finished reports whether the stream was finished
+func (e *Emitter) Finished() bool {
+	return e.finished
+}
+
+// Start sends the first chunk, with the assistant role. Content and tool calls
+// send it as needed.
+func (e *Emitter) Start() []byte {
+	if e.started || e.finished {
+		return nil
+	}
+	e.started = true
+	if e.id == "" {
+		e.id = "chatcmpl-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	empty := ""
+	return e.format(delta{Role: "assistant", Content: &empty}, nil)
+}
+
+// Text sends a content delta
+func (e *Emitter) Text(content string) []byte {
+	if e.finished || content == "" {
+		return nil
+	}
+	output := e.Start()
+	return append(output, e.format(delta{Content: &content}, nil)...)
+}
+
+// Reasoning sends a reasoning_content delta, the field OpenAI-compatible reasoning
+// models stream their thinking in
+func (e *Emitter) Reasoning(content string) []byte {
+	if e.finished || content == "" {
+		return nil
+	}
+	output := e.Start()
+	return append(output, e.format(delta{ReasoningContent: &content}, nil)...)
+}
+
+// StartToolCall sends the first delta of a tool call, identified by key in later
+// calls. A key already started is ignored.
+func (e *Emitter) StartToolCall(key int, id, name string) []byte {
+	if e.finished {
+		return nil
+	}
+	if _, ok := e.toolCalls[key]; ok {
+		return nil
+	}
+	index := len(e.toolCalls)
+	e.toolCalls[key] = index
+	output := e.Start()
+	return append(output, e.format(delta{ToolCalls: []toolCall{{
+		Index:    index,
+		ID:       id,
+		Type:     "function",
+		Function: function{Name: name},
+	}}}, nil)...)
+}
+
+// ToolArguments sends a fragment of the arguments of the tool call started with key
+func (e *Emitter) ToolArguments(key int, fragment string) []byte {
+	index, ok := e.toolCalls[key]
+	if e.finished || !ok || fragment == "" {
+		return nil
+	}
+	return e.format(delta{ToolCalls: []toolCall{{
+		Index:    index,
+		Function: function{Arguments: fragment},
+	}}}, nil)
+}
+
+// Finish ends the stream with finish_reason, the usage chunk if the client asked
+// for it, and [DONE]. An empty finishReason means stop; stop becomes tool_calls if
+// tool calls were sent. It does nothing once the stream was finished.
+func (e *Emitter) Finish(finishReason string) []byte {
+	if e.finished {
+		return nil
+	}
+	if finishReason == "" {
+		finishReason = "stop"
+	}
+	if finishReason == "stop" && len(e.toolCalls) > 0 {
+		finishReason = "tool_calls"
+	}
+
+	output := e.Start()
+	output = append(output, e.format(delta{}, &finishReason)...)
+	if e.includeUsage {
+		usage := e.usage
+		output = append(output, e.marshal(chunk{Choices: []choice{}, Usage: &usage})...)
+	}
+	e.finished = true
+	return append(output, "data: [DONE]\n\n"...)
+}
+
+func (e *Emitter) format(d delta, finishReason *string) []byte {
+	return e.marshal(chunk{Choices: []choice{{Delta: d, FinishReason: finishReason}}})
+}
+
+// marshal renders c as an SSE event with the stream's id, created and model
+func (e *Emitter) marshal(c chunk) []byte {
+	c.ID = e.id
+	c.Object = "chat.completion.chunk"
+	c.Created = e.created
+	c.Model = e.model
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil
+	}
+	return append(append([]byte("data: "), data...), "\n\n"...)
+}
@@ -0,0 +1,215 @@
+// Package quality stores sampled request/response pairs for offline review of provider
+// response quality. Samples are kept in full, independent of response capture limits and
+// request retention, in a JSONL file that rotates by size like the audit log
+// (quality-samples.jsonl, then .1, .2, ...).
+package quality
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/jsonl"
+)
+
+// TagReview marks every sample as awaiting quality review
+const TagReview = "quality_review"
+
+// Other tags describe how the sample was served
+const (
+	TagStream         = "stream"
+	TagConverted      = "converted"
+	TagContentBlocked = "content_blocked"
+)
+
+const (
+	defaultMaxSize    = 50 << 20 // Rotate after 50 MB
+	defaultMaxBackups = 5
+)
+
+// Sample is one line of the sample file: a full client request and the response it got
+type Sample struct {
+	Timestamp      time.Time       `json:"timestamp"`
+	Tags           []string        `json:"tags"`
+	ProxyRequestID uint64          `json:"proxy_request_id"`
+	RequestID      string          `json:"request_id,omitempty"`
+	SessionID      string          `json:"session_id,omitempty"`
+	ClientType     string          `json:"client_type"`
+	ProviderID     uint64          `json:"provider_id"`
+	ProviderName   string          `json:"provider_name,omitempty"`
+	ProviderType   string          `json:"provider_type,omitempty"`
+	Model          string          `json:"model"`
+	ResponseModel  string          `json:"response_model,omitempty"`
+	StatusCode     int             `json:"status_code"`
+	DurationMs     int64           `json:"duration_ms"`
+	InputTokens    uint64          `json:"input_tokens"`
+	OutputTokens   uint64          `json:"output_tokens"`
+	Cost           uint64          `json:"cost"` // Micro-USD
+	Request        json.RawMessage `json:"request"`
+	Response       json.RawMessage `json:"response"` // JSON body, or a JSON string of an SSE stream
+}
+
+// Body returns body as raw JSON if it is valid JSON, otherwise as a JSON string
+func Body(body []byte) json.RawMessage {
+	if json.Valid(body) {
+		return json.RawMessage(body)
+	}
+	quoted, _ := json.Marshal(string(body))
+	return quoted
+}
+
+// Filter selects samples for Query and Export
+type Filter struct {
+	ProviderID uint64
+	Model      string
+	Tag        string
+	Since      time.Time
+	Until      time.Time
+	Limit      int // Most recent matches to return, 0 for all
+}
+
+func (f *Filter) match(s *Sample) bool {
+	return (f.ProviderID == 0 || s.ProviderID == f.ProviderID) &&
+		(f.Model == "" || s.Model == f.Model || s.ResponseModel == f.Model) &&
+		(f.Tag == "" || slices.Contains(s.Tags, f.Tag)) &&
+		(f.Since.IsZero() || !s.Timestamp.Before(f.Since)) &&
+		(f.Until.IsZero() || s.Timestamp.Before(f.Until))
+}
+
+// Store appends samples to a rotating JSONL file
+type Store struct {
+	out *jsonl.Writer
+}
+
+// NewStore creates a store; it writes nothing until a path is set
+func NewStore() *Store {
+	return &Store{out: jsonl.NewWriter(defaultMaxSize, defaultMaxBackups)}
+}
+
+var defaultStore = NewStore()
+
+// Default returns the global sample store
+func Default() *Store {
+	return defaultStore
+}
+
+// SetPath sets the sample file, closing the previous one
+func (s *Store) SetPath(path string) {
+	s.out.SetPath(path)
+}
+
+// Record appends a sample. Failures are logged and otherwise ignored: sampling must never
+// fail a request.
+func (s *Store) Record(sample *Sample) {
+	if sample.Timestamp.IsZero() {
+		sample.Timestamp = time.Now()
+	}
+	line, err := json.Marshal(sample)
+	if err != nil {
+		log.Printf("[Quality] Failed to encode sample: %v", err)
+		return
+	}
+	if err := s.out.Append(append(line, '\n')); err != nil {
+		log.Printf("[Quality] Failed to write sample: %v", err)
+	}
+}
+
+// Query returns the matching samples, newest first
+func (s *Store) Query(f Filter) ([]*Sample, error) {
+	var samples []*Sample
+	err := s.scan(func(sample *Sample, _ []byte) error {
+		if !f.match(sample) {
+			return nil
+		}
+		samples = append(samples, sample)
+		if f.Limit > 0 && len(samples) > f.Limit {
+			samples = samples[1:]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(samples)
+	if samples == nil {
+		samples = []*Sample{}
+	}
+	return samples, nil
+}
+
+// Export writes the matching samples to w as JSONL, oldest first, and returns how many
+// were written. With a limit only the most recent matches are written.
+func (s *Store) Export(w io.Writer, f Filter) (int, error) {
+	if f.Limit > 0 {
+		samples, err := s.Query(f)
+		if err != nil {
+			return 0, err
+		}
+		enc := json.NewEncoder(w)
+		for i := len(samples) - 1; i >= 0; i-- {
+			if err := enc.Encode(samples[i]); err != nil {
+				return len(samples) - 1 - i, err
+			}
+		}
+		return len(samples), nil
+	}
+
+	// Without a limit the matching lines are copied as they are read
+	count := 0
+	err := s.scan(func(sample *Sample, line []byte) error {
+		if !f.match(sample) {
+			return nil
+		}
+		if _, err := w.Write(line); err != nil {
+			return err
+		}
+		if _, err := w.Write([]byte{'\n'}); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// scan calls fn with every sample in the files, oldest first
+func (s *Store) scan(fn func(sample *Sample, line []byte) error) error {
+	for _, name := range s.out.Files() {
+		if err := scanFile(name, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanFile(name string, fn func(sample *Sample, line []byte) error) error {
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 256<<20)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var sample Sample
+		if json.Unmarshal(line, &sample) != nil {
+			continue
+		}
+		if err := fn(&sample, line); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,172 @@
+// Package quota keeps local per-provider usage ledgers for the request and token budgets
+// configured on providers, and the providers' quota reset schedules. Unlike cooldowns, which
+// react to upstream errors, the ledger is consulted before routing: once a provider has used
+// its budget it is skipped until its next scheduled reset.
+package quota
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// usage is the consumption of one provider in the current window
+type usage struct {
+	windowStart time.Time
+	requests    uint64
+	tokens      uint64
+	exhausted   bool // Logged as exhausted in this window
+}
+
+// Ledger tracks provider usage against the configured budgets
+type Ledger struct {
+	mu     sync.Mutex
+	usages map[uint64]*usage
+	now    func() time.Time
+}
+
+// NewLedger creates an empty ledger
+func NewLedger() *Ledger {
+	return &Ledger{
+		usages: make(map[uint64]*usage),
+		now:    time.Now,
+	}
+}
+
+var defaultLedger = NewLedger()
+
+// Default returns the global ledger
+func Default() *Ledger {
+	return defaultLedger
+}
+
+// Config returns the quota config of a provider, or nil if it has no budget
+func Config(p *domain.Provider) *domain.ProviderQuotaConfig {
+	if p == nil || p.Config == nil || p.Config.Quota == nil {
+		return nil
+	}
+	cfg := p.Config.Quota
+	if cfg.RequestsPerDay == 0 && cfg.TokensPerDay == 0 {
+		return nil
+	}
+	return cfg
+}
+
+// Record adds an upstream attempt and its tokens to the provider's ledger
+func (l *Ledger) Record(p *domain.Provider, tokens uint64) {
+	cfg := Config(p)
+	if cfg == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.currentLocked(p)
+	u.requests++
+	u.tokens += tokens
+	if !u.exhausted && exceeds(u, cfg) {
+		u.exhausted = true
+		log.Printf("[Quota] Provider %s (ID: %d) used its budget (%d requests, %d tokens), skipped until %s",
+			p.Name, p.ID, u.requests, u.tokens, ScheduleOf(p).Next(l.now()).Format(time.RFC3339))
+	}
+}
+
+// IsExhausted reports whether the provider has used its budget for the current window
+func (l *Ledger) IsExhausted(p *domain.Provider) bool {
+	cfg := Config(p)
+	if cfg == nil {
+		return false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return exceeds(l.currentLocked(p), cfg)
+}
+
+// Status returns the provider's usage in the current window, or nil if it has no budget
+func (l *Ledger) Status(p *domain.Provider) *domain.ProviderQuotaStatus {
+	cfg := Config(p)
+	if cfg == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	u := l.currentLocked(p)
+	return &domain.ProviderQuotaStatus{
+		ProviderID:     p.ID,
+		ProviderName:   p.Name,
+		RequestsPerDay: cfg.RequestsPerDay,
+		TokensPerDay:   cfg.TokensPerDay,
+		Requests:       u.requests,
+		Tokens:         u.tokens,
+		WindowStart:    u.windowStart,
+		ResetAt:        ScheduleOf(p).Next(l.now()),
+		Exhausted:      exceeds(u, cfg),
+	}
+}
+
+// Reset clears the provider's usage for the current window
+func (l *Ledger) Reset(providerID uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.usages, providerID)
+}
+
+// Seed restores the usage of the current windows from the usage statistics, so budgets
+// survive restarts. Minute statistics are kept for a day; longer windows are restored from
+// hourly statistics, starting at the hour of the reset.
+func (l *Ledger) Seed(providers []*domain.Provider, statsRepo repository.UsageStatsRepository) {
+	for _, p := range providers {
+		cfg := Config(p)
+		if cfg == nil {
+			continue
+		}
+		now := l.now()
+		start := ScheduleOf(p).Previous(now)
+		granularity := domain.GranularityMinute
+		if now.Sub(start) > 23*time.Hour {
+			granularity = domain.GranularityHour
+			start = start.Truncate(time.Hour)
+		}
+		providerID := p.ID
+		summaries, err := statsRepo.GetSummaryByProvider(repository.UsageStatsFilter{
+			Granularity: granularity,
+			StartTime:   &start,
+			ProviderID:  &providerID,
+		})
+		if err != nil {
+			log.Printf("[Quota] Failed to restore usage of provider %d: %v", p.ID, err)
+			continue
+		}
+		summary := summaries[p.ID]
+		if summary == nil {
+			continue
+		}
+
+		l.mu.Lock()
+		u := l.currentLocked(p)
+		u.requests = summary.TotalRequests
+		u.tokens = summary.TotalInputTokens + summary.TotalOutputTokens
+		u.exhausted = exceeds(u, cfg)
+		l.mu.Unlock()
+	}
+}
+
+// currentLocked returns the usage of the current window, starting a new one after a reset
+func (l *Ledger) currentLocked(p *domain.Provider) *usage {
+	start := ScheduleOf(p).Previous(l.now())
+	u, ok := l.usages[p.ID]
+	if !ok || !u.windowStart.Equal(start) {
+		u = &usage{windowStart: start}
+		l.usages[p.ID] = u
+	}
+	return u
+}
+
+func exceeds(u *usage, cfg *domain.ProviderQuotaConfig) bool {
+	return (cfg.RequestsPerDay > 0 && u.requests >= cfg.RequestsPerDay) ||
+		(cfg.TokensPerDay > 0 && u.tokens >= cfg.TokensPerDay)
+}
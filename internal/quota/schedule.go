@@ -0,0 +1,264 @@
+package quota
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // Provider time zones must resolve on hosts without a zoneinfo database
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// Schedule is when a provider's quota resets: daily at a fixed local time, or on a cron
+// schedule, in the provider's time zone
+type Schedule struct {
+	loc          *time.Location
+	hour, minute int
+	cron         *cronSpec
+}
+
+var defaultSchedule = &Schedule{loc: time.Local}
+
+var scheduleCache sync.Map // domain.ProviderResetSchedule -> *Schedule
+
+// ScheduleOf returns the reset schedule of a provider. Providers without a schedule, or
+// with an invalid one, reset daily at midnight server time.
+func ScheduleOf(p *domain.Provider) *Schedule {
+	if p == nil || p.Config == nil || p.Config.ResetSchedule == nil {
+		return defaultSchedule
+	}
+	cfg := *p.Config.ResetSchedule
+	if s, ok := scheduleCache.Load(cfg); ok {
+		return s.(*Schedule)
+	}
+	s, err := ParseSchedule(&cfg)
+	if err != nil {
+		s = defaultSchedule
+	}
+	scheduleCache.Store(cfg, s)
+	return s
+}
+
+// ParseSchedule validates and parses a reset schedule
+func ParseSchedule(cfg *domain.ProviderResetSchedule) (*Schedule, error) {
+	s := &Schedule{loc: time.Local}
+	if cfg == nil {
+		return s, nil
+	}
+	if tz := strings.TrimSpace(cfg.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time zone %q", tz)
+		}
+		s.loc = loc
+	}
+
+	if spec := strings.TrimSpace(cfg.Cron); spec != "" {
+		c, err := parseCron(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron %q: %w", spec, err)
+		}
+		s.cron = c
+		if s.Next(time.Now()).IsZero() {
+			return nil, fmt.Errorf("cron %q never fires", spec)
+		}
+		return s, nil
+	}
+
+	var err error
+	s.hour, s.minute, err = parseTimeOfDay(cfg.Time)
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Previous returns the last reset at or before now (zero if a cron schedule has none
+// within the search range)
+func (s *Schedule) Previous(now time.Time) time.Time {
+	t := now.In(s.loc)
+	if s.cron != nil {
+		return s.cron.previous(t)
+	}
+	reset := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, s.loc)
+	if reset.After(t) {
+		reset = time.Date(t.Year(), t.Month(), t.Day()-1, s.hour, s.minute, 0, 0, s.loc)
+	}
+	return reset
+}
+
+// Next returns the first reset after now (zero if a cron schedule has none within the
+// search range)
+func (s *Schedule) Next(now time.Time) time.Time {
+	t := now.In(s.loc)
+	if s.cron != nil {
+		return s.cron.next(t)
+	}
+	reset := time.Date(t.Year(), t.Month(), t.Day(), s.hour, s.minute, 0, 0, s.loc)
+	if !reset.After(t) {
+		reset = time.Date(t.Year(), t.Month(), t.Day()+1, s.hour, s.minute, 0, 0, s.loc)
+	}
+	return reset
+}
+
+// parseTimeOfDay parses "HH:MM"; empty means midnight
+func parseTimeOfDay(s string) (hour, minute int, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, 0, nil
+	}
+	h, m, ok := strings.Cut(s, ":")
+	if ok {
+		hour, err = strconv.Atoi(h)
+		if err == nil {
+			minute, err = strconv.Atoi(m)
+		}
+	}
+	if !ok || err != nil || hour < 0 || hour > 23 || minute < 0 || minute > 59 {
+		return 0, 0, fmt.Errorf("invalid reset time %q, expected HH:MM", s)
+	}
+	return hour, minute, nil
+}
+
+// cronSpec is a standard 5-field cron expression (minute hour day-of-month month day-of-week)
+type cronSpec struct {
+	minute, hour, dom, month, dow uint64 // Bit sets of allowed values
+	domStar, dowStar              bool
+}
+
+// cronSearchLimit bounds the search for the next or previous firing
+const cronSearchLimit = 5 * 366 * 24 * time.Hour
+
+func parseCron(spec string) (*cronSpec, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, errors.New("expected 5 fields: minute hour day-of-month month day-of-week")
+	}
+	c := &cronSpec{}
+	var err error
+	if c.minute, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if c.hour, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if c.dom, c.domStar, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if c.month, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if c.dow, c.dowStar, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// 7 is Sunday as well
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// parseCronField parses a comma-separated list of *, values, ranges and steps
+func parseCronField(field string, min, max int) (bits uint64, star bool, err error) {
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			if step, err = strconv.Atoi(stepPart); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		lo, hi := min, max
+		switch {
+		case rangePart == "*":
+			star = star || !hasStep
+		case strings.Contains(rangePart, "-"):
+			l, h, _ := strings.Cut(rangePart, "-")
+			lo, err = strconv.Atoi(l)
+			if err == nil {
+				hi, err = strconv.Atoi(h)
+			}
+			if err != nil {
+				return 0, false, fmt.Errorf("invalid range %q", part)
+			}
+		default:
+			if lo, err = strconv.Atoi(rangePart); err != nil {
+				return 0, false, fmt.Errorf("invalid value %q", part)
+			}
+			hi = lo
+			if hasStep {
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, false, fmt.Errorf("%q out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
+
+func (c *cronSpec) dayMatches(t time.Time) bool {
+	if c.month&(1<<uint(t.Month())) == 0 {
+		return false
+	}
+	domMatch := c.dom&(1<<uint(t.Day())) != 0
+	dowMatch := c.dow&(1<<uint(t.Weekday())) != 0
+	// As in cron, a restricted day-of-month and day-of-week match if either does
+	if c.domStar || c.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// next returns the first firing after t
+func (c *cronSpec) next(t time.Time) time.Time {
+	loc := t.Location()
+	end := t.Add(cronSearchLimit)
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	for t.Before(end) {
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, loc)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, loc)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// previous returns the last firing at or before t
+func (c *cronSpec) previous(t time.Time) time.Time {
+	loc := t.Location()
+	end := t.Add(-cronSearchLimit)
+	t = t.Truncate(time.Minute)
+	for t.After(end) {
+		if !c.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if c.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc).Add(-time.Minute)
+			continue
+		}
+		if c.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(-time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
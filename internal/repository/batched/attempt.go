@@ -0,0 +1,58 @@
+package batched
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// ProxyUpstreamAttemptRepository adds write-behind updates on top of a backing repository,
+// with the same semantics as ProxyRequestRepository.
+type ProxyUpstreamAttemptRepository struct {
+	repository.ProxyUpstreamAttemptRepository
+	queue *writeQueue[*domain.ProxyUpstreamAttempt]
+}
+
+func NewProxyUpstreamAttemptRepository(repo repository.ProxyUpstreamAttemptRepository, flushInterval time.Duration) *ProxyUpstreamAttemptRepository {
+	return &ProxyUpstreamAttemptRepository{
+		ProxyUpstreamAttemptRepository: repo,
+		queue:                          newWriteQueue("proxy_upstream_attempts", repo.Update, flushInterval, DefaultMaxPending),
+	}
+}
+
+// Update writes immediately and supersedes any pending deferred update for the same record.
+func (r *ProxyUpstreamAttemptRepository) Update(attempt *domain.ProxyUpstreamAttempt) error {
+	return r.queue.writeNow(attempt.ID, attempt)
+}
+
+// UpdateDeferred queues a snapshot of attempt to be written on the next flush.
+// Records without an ID (failed Create) are written synchronously.
+func (r *ProxyUpstreamAttemptRepository) UpdateDeferred(attempt *domain.ProxyUpstreamAttempt) error {
+	if attempt.ID == 0 {
+		return r.Update(attempt)
+	}
+	snapshot := *attempt
+	r.queue.enqueue(attempt.ID, &snapshot)
+	return nil
+}
+
+func (r *ProxyUpstreamAttemptRepository) ListByProxyRequestID(proxyRequestID uint64) ([]*domain.ProxyUpstreamAttempt, error) {
+	r.queue.flush()
+	return r.ProxyUpstreamAttemptRepository.ListByProxyRequestID(proxyRequestID)
+}
+
+func (r *ProxyUpstreamAttemptRepository) MarkStaleAsInterrupted(currentInstanceID string) (int64, error) {
+	r.queue.flush()
+	return r.ProxyUpstreamAttemptRepository.MarkStaleAsInterrupted(currentInstanceID)
+}
+
+// Flush writes all pending updates immediately.
+func (r *ProxyUpstreamAttemptRepository) Flush() {
+	r.queue.flush()
+}
+
+// Close flushes pending updates and stops the background writer.
+func (r *ProxyUpstreamAttemptRepository) Close() {
+	r.queue.close()
+}
@@ -0,0 +1,68 @@
+package batched
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// ProxyRequestRepository adds write-behind updates on top of a backing repository.
+// Update stays synchronous; UpdateDeferred coalesces non-critical updates
+// (status flips, live route tracking) and flushes them periodically.
+type ProxyRequestRepository struct {
+	repository.ProxyRequestRepository
+	queue *writeQueue[*domain.ProxyRequest]
+}
+
+func NewProxyRequestRepository(repo repository.ProxyRequestRepository, flushInterval time.Duration) *ProxyRequestRepository {
+	return &ProxyRequestRepository{
+		ProxyRequestRepository: repo,
+		queue:                  newWriteQueue("proxy_requests", repo.Update, flushInterval, DefaultMaxPending),
+	}
+}
+
+// Update writes immediately and supersedes any pending deferred update for the same record.
+func (r *ProxyRequestRepository) Update(req *domain.ProxyRequest) error {
+	return r.queue.writeNow(req.ID, req)
+}
+
+// UpdateDeferred queues a snapshot of req to be written on the next flush.
+// Records without an ID (failed Create) are written synchronously.
+func (r *ProxyRequestRepository) UpdateDeferred(req *domain.ProxyRequest) error {
+	if req.ID == 0 {
+		return r.Update(req)
+	}
+	snapshot := *req
+	r.queue.enqueue(req.ID, &snapshot)
+	return nil
+}
+
+// GetByID returns the pending snapshot if one is queued, so readers see their own writes.
+func (r *ProxyRequestRepository) GetByID(id uint64) (*domain.ProxyRequest, error) {
+	if req, ok := r.queue.get(id); ok {
+		snapshot := *req
+		return &snapshot, nil
+	}
+	return r.ProxyRequestRepository.GetByID(id)
+}
+
+func (r *ProxyRequestRepository) List(limit, offset int) ([]*domain.ProxyRequest, error) {
+	r.queue.flush()
+	return r.ProxyRequestRepository.List(limit, offset)
+}
+
+func (r *ProxyRequestRepository) ListCursor(limit int, before, after uint64) ([]*domain.ProxyRequest, error) {
+	r.queue.flush()
+	return r.ProxyRequestRepository.ListCursor(limit, before, after)
+}
+
+// Flush writes all pending updates immediately.
+func (r *ProxyRequestRepository) Flush() {
+	r.queue.flush()
+}
+
+// Close flushes pending updates and stops the background writer.
+func (r *ProxyRequestRepository) Close() {
+	r.queue.close()
+}
@@ -0,0 +1,142 @@
+package batched
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultFlushInterval is how often pending writes are flushed.
+	DefaultFlushInterval = 500 * time.Millisecond
+	// DefaultMaxPending triggers an early flush once this many records are queued.
+	DefaultMaxPending = 256
+)
+
+// writeQueue coalesces deferred writes by record ID and flushes them in the background.
+//
+// Ordering guarantee: every write to the backing store (deferred or synchronous) goes
+// through writeMu, and a synchronous write drops any pending deferred write for the same
+// ID first. A stale snapshot can therefore never overwrite a newer synchronous write.
+type writeQueue[T any] struct {
+	name       string
+	write      func(T) error
+	maxPending int
+
+	mu      sync.Mutex
+	pending map[uint64]T
+	order   []uint64
+
+	writeMu sync.Mutex
+
+	kick      chan struct{}
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newWriteQueue[T any](name string, write func(T) error, interval time.Duration, maxPending int) *writeQueue[T] {
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	if maxPending <= 0 {
+		maxPending = DefaultMaxPending
+	}
+	q := &writeQueue[T]{
+		name:       name,
+		write:      write,
+		maxPending: maxPending,
+		pending:    make(map[uint64]T),
+		kick:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go q.loop(interval)
+	return q
+}
+
+func (q *writeQueue[T]) loop(interval time.Duration) {
+	defer close(q.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			q.flush()
+		case <-q.kick:
+			q.flush()
+		case <-q.stop:
+			q.flush()
+			return
+		}
+	}
+}
+
+// enqueue stores the latest snapshot for id, replacing any pending one.
+func (q *writeQueue[T]) enqueue(id uint64, item T) {
+	q.mu.Lock()
+	if _, ok := q.pending[id]; !ok {
+		q.order = append(q.order, id)
+	}
+	q.pending[id] = item
+	full := len(q.pending) >= q.maxPending
+	q.mu.Unlock()
+
+	if full {
+		select {
+		case q.kick <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// get returns the pending snapshot for id, if any.
+func (q *writeQueue[T]) get(id uint64) (T, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	item, ok := q.pending[id]
+	return item, ok
+}
+
+// writeNow performs a synchronous write, discarding any pending snapshot for id.
+func (q *writeQueue[T]) writeNow(id uint64, item T) error {
+	q.writeMu.Lock()
+	defer q.writeMu.Unlock()
+
+	q.mu.Lock()
+	delete(q.pending, id)
+	q.mu.Unlock()
+
+	return q.write(item)
+}
+
+// flush writes all pending snapshots in first-enqueued order.
+func (q *writeQueue[T]) flush() {
+	q.mu.Lock()
+	order := q.order
+	q.order = nil
+	q.mu.Unlock()
+
+	for _, id := range order {
+		q.writeMu.Lock()
+		q.mu.Lock()
+		item, ok := q.pending[id]
+		delete(q.pending, id)
+		q.mu.Unlock()
+		if ok {
+			if err := q.write(item); err != nil {
+				log.Printf("[Batched] %s: failed to flush record %d: %v", q.name, id, err)
+			}
+		}
+		q.writeMu.Unlock()
+	}
+}
+
+// close stops the background loop after a final flush.
+func (q *writeQueue[T]) close() {
+	q.closeOnce.Do(func() {
+		close(q.stop)
+		<-q.done
+	})
+}
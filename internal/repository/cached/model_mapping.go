@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
 	"github.com/awsl-project/maxx/internal/repository"
 )
 
@@ -73,6 +74,7 @@ func (r *ModelMappingRepository) Create(mapping *domain.ModelMapping) error {
 	defer r.mu.Unlock()
 	r.cache = append(r.cache, mapping)
 	r.sortCache()
+	event.PublishConfigChange(event.ConfigModelMappings)
 	return nil
 }
 
@@ -90,6 +92,7 @@ func (r *ModelMappingRepository) Update(mapping *domain.ModelMapping) error {
 		}
 	}
 	r.sortCache() // 可能 priority 或 scope 变了，需要重新排序
+	event.PublishConfigChange(event.ConfigModelMappings)
 	return nil
 }
 
@@ -106,6 +109,7 @@ func (r *ModelMappingRepository) Delete(id uint64) error {
 			break
 		}
 	}
+	event.PublishConfigChange(event.ConfigModelMappings)
 	return nil
 }
 
@@ -188,6 +192,7 @@ func (r *ModelMappingRepository) DeleteAll() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.cache = make([]*domain.ModelMapping, 0)
+	event.PublishConfigChange(event.ConfigModelMappings)
 	return nil
 }
 
@@ -199,6 +204,7 @@ func (r *ModelMappingRepository) ClearAll() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.cache = make([]*domain.ModelMapping, 0)
+	event.PublishConfigChange(event.ConfigModelMappings)
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
 	"github.com/awsl-project/maxx/internal/repository"
 )
 
@@ -48,6 +49,7 @@ func (r *ProjectRepository) Create(p *domain.Project) error {
 		r.slugCache[p.Slug] = p
 	}
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigProjects)
 	return nil
 }
 
@@ -75,6 +77,7 @@ func (r *ProjectRepository) Update(p *domain.Project) error {
 		r.slugCache[p.Slug] = p
 	}
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigProjects)
 	return nil
 }
 
@@ -94,6 +97,7 @@ func (r *ProjectRepository) Delete(id uint64) error {
 		delete(r.slugCache, p.Slug)
 	}
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigProjects)
 	return nil
 }
 
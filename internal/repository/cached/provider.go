@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
 	"github.com/awsl-project/maxx/internal/repository"
 )
 
@@ -40,6 +41,7 @@ func (r *ProviderRepository) Create(p *domain.Provider) error {
 	r.mu.Lock()
 	r.cache[p.ID] = p
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigProviders)
 	return nil
 }
 
@@ -48,8 +50,14 @@ func (r *ProviderRepository) Update(p *domain.Provider) error {
 		return err
 	}
 	r.mu.Lock()
-	r.cache[p.ID] = p
+	// 已软删除的 provider 更新后仍不进入缓存，不参与路由
+	if p.DeletedAt != nil {
+		delete(r.cache, p.ID)
+	} else {
+		r.cache[p.ID] = p
+	}
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigProviders)
 	return nil
 }
 
@@ -62,6 +70,26 @@ func (r *ProviderRepository) Delete(id uint64) error {
 	r.mu.Lock()
 	delete(r.cache, id)
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigProviders)
+	return nil
+}
+
+func (r *ProviderRepository) ListDeleted() ([]*domain.Provider, error) {
+	return r.repo.ListDeleted()
+}
+
+func (r *ProviderRepository) Restore(id uint64) error {
+	if err := r.repo.Restore(id); err != nil {
+		return err
+	}
+	p, err := r.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cache[id] = p
+	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigProviders)
 	return nil
 }
 
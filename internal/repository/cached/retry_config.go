@@ -4,6 +4,7 @@ import (
     "sync"
 
     "github.com/awsl-project/maxx/internal/domain"
+    "github.com/awsl-project/maxx/internal/event"
     "github.com/awsl-project/maxx/internal/repository"
 )
 
@@ -51,6 +52,7 @@ func (r *RetryConfigRepository) Create(c *domain.RetryConfig) error {
         r.defaultCache = c
     }
     r.mu.Unlock()
+    event.PublishConfigChange(event.ConfigRetryConfigs)
     return nil
 }
 
@@ -71,6 +73,7 @@ func (r *RetryConfigRepository) Update(c *domain.RetryConfig) error {
         r.defaultCache = nil
     }
     r.mu.Unlock()
+    event.PublishConfigChange(event.ConfigRetryConfigs)
     return nil
 }
 
@@ -84,6 +87,7 @@ func (r *RetryConfigRepository) Delete(id uint64) error {
     }
     delete(r.cache, id)
     r.mu.Unlock()
+    event.PublishConfigChange(event.ConfigRetryConfigs)
     return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
 	"github.com/awsl-project/maxx/internal/repository"
 )
 
@@ -39,6 +40,7 @@ func (r *RouteRepository) Create(route *domain.Route) error {
 	r.cache = append(r.cache, route)
 	r.sortCacheLocked()
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigRoutes)
 	return nil
 }
 
@@ -55,6 +57,7 @@ func (r *RouteRepository) Update(route *domain.Route) error {
 	}
 	r.sortCacheLocked()
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigRoutes)
 	return nil
 }
 
@@ -70,6 +73,27 @@ func (r *RouteRepository) Delete(id uint64) error {
 		}
 	}
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigRoutes)
+	return nil
+}
+
+func (r *RouteRepository) ListDeleted() ([]*domain.Route, error) {
+	return r.repo.ListDeleted()
+}
+
+func (r *RouteRepository) Restore(id uint64) error {
+	if err := r.repo.Restore(id); err != nil {
+		return err
+	}
+	route, err := r.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.cache = append(r.cache, route)
+	r.sortCacheLocked()
+	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigRoutes)
 	return nil
 }
 
@@ -78,7 +102,11 @@ func (r *RouteRepository) BatchUpdatePositions(updates []domain.RoutePositionUpd
 		return err
 	}
 	// Reload cache to reflect position changes
-	return r.Load()
+	if err := r.Load(); err != nil {
+		return err
+	}
+	event.PublishConfigChange(event.ConfigRoutes)
+	return nil
 }
 
 func (r *RouteRepository) GetByID(id uint64) (*domain.Route, error) {
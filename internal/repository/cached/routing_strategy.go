@@ -4,6 +4,7 @@ import (
 	"sync"
 
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
 	"github.com/awsl-project/maxx/internal/repository"
 )
 
@@ -40,6 +41,7 @@ func (r *RoutingStrategyRepository) Create(s *domain.RoutingStrategy) error {
 	r.mu.Lock()
 	r.cache[s.ProjectID] = s
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigRoutingStrategies)
 	return nil
 }
 
@@ -68,6 +70,7 @@ func (r *RoutingStrategyRepository) Update(s *domain.RoutingStrategy) error {
 	}
 	r.cache[s.ProjectID] = s
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigRoutingStrategies)
 	return nil
 }
 
@@ -89,6 +92,7 @@ func (r *RoutingStrategyRepository) Delete(id uint64) error {
 	r.mu.Lock()
 	delete(r.cache, projectID)
 	r.mu.Unlock()
+	event.PublishConfigChange(event.ConfigRoutingStrategies)
 	return nil
 }
 
@@ -3,6 +3,7 @@ package cached
 import (
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/awsl-project/maxx/internal/domain"
 	"github.com/awsl-project/maxx/internal/repository"
@@ -96,6 +97,30 @@ func (r *SessionRepository) GetOrCreate(sessionID string, clientType domain.Clie
 	return s, nil
 }
 
+// sessionTouchInterval limits how often a session's activity time is written back
+const sessionTouchInterval = time.Minute
+
+// Touch records activity on a session: it refreshes LastActiveAt (at most once per
+// sessionTouchInterval) and restores the session if it was archived for being idle.
+// The cached session is shared by concurrent requests, so a copy of it is updated and
+// replaces it in the cache.
+func (r *SessionRepository) Touch(s *domain.Session) {
+	now := time.Now()
+	r.mu.RLock()
+	if cached, ok := r.cache[s.SessionID]; ok {
+		s = cached
+	}
+	touched := *s
+	r.mu.RUnlock()
+
+	if touched.ArchivedAt == nil && touched.LastActiveAt != nil && now.Sub(*touched.LastActiveAt) < sessionTouchInterval {
+		return
+	}
+	touched.LastActiveAt = &now
+	touched.ArchivedAt = nil
+	_ = r.Update(&touched)
+}
+
 func (r *SessionRepository) List() ([]*domain.Session, error) {
 	return r.repo.List()
 }
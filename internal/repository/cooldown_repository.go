@@ -27,6 +27,18 @@ type CooldownRepository interface {
 
 	// Get retrieves a specific cooldown
 	Get(providerID uint64, clientType string) (*domain.Cooldown, error)
+
+	// CreateEvent records a cooldown activation
+	CreateEvent(event *domain.CooldownEvent) error
+
+	// UpdateEventUntil changes the end time of a recorded activation
+	UpdateEventUntil(id uint64, until time.Time) error
+
+	// ListEvents returns the activations started in [from, to), oldest first
+	ListEvents(from, to time.Time) ([]*domain.CooldownEvent, error)
+
+	// DeleteEventsBefore removes activations started before the given time
+	DeleteEventsBefore(before time.Time) error
 }
 
 // CooldownInfo is a helper structure for returning cooldown information
@@ -12,6 +12,10 @@ type ProviderRepository interface {
 	Delete(id uint64) error
 	GetByID(id uint64) (*domain.Provider, error)
 	List() ([]*domain.Provider, error)
+	// ListDeleted returns the soft-deleted providers, most recently deleted first
+	ListDeleted() ([]*domain.Provider, error)
+	// Restore clears the deletion of a soft-deleted provider (ErrNotFound if it isn't deleted)
+	Restore(id uint64) error
 }
 
 type RouteRepository interface {
@@ -22,6 +26,10 @@ type RouteRepository interface {
 	// FindByKey finds a route by the unique key (projectID, providerID, clientType)
 	FindByKey(projectID, providerID uint64, clientType domain.ClientType) (*domain.Route, error)
 	List() ([]*domain.Route, error)
+	// ListDeleted returns the soft-deleted routes, most recently deleted first
+	ListDeleted() ([]*domain.Route, error)
+	// Restore clears the deletion of a soft-deleted route (ErrNotFound if it isn't deleted)
+	Restore(id uint64) error
 	// BatchUpdatePositions updates positions for multiple routes in a transaction
 	BatchUpdatePositions(updates []domain.RoutePositionUpdate) error
 }
@@ -34,6 +42,30 @@ type RoutingStrategyRepository interface {
 	List() ([]*domain.RoutingStrategy, error)
 }
 
+type RoutingProfileRepository interface {
+	Create(profile *domain.RoutingProfile) error
+	Update(profile *domain.RoutingProfile) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*domain.RoutingProfile, error)
+	List() ([]*domain.RoutingProfile, error)
+}
+
+type CostAlertRepository interface {
+	Create(alert *domain.CostAlert) error
+	Update(alert *domain.CostAlert) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*domain.CostAlert, error)
+	List() ([]*domain.CostAlert, error)
+}
+
+type LatencySLORepository interface {
+	Create(slo *domain.LatencySLO) error
+	Update(slo *domain.LatencySLO) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*domain.LatencySLO, error)
+	List() ([]*domain.LatencySLO, error)
+}
+
 type RetryConfigRepository interface {
 	Create(config *domain.RetryConfig) error
 	Update(config *domain.RetryConfig) error
@@ -71,17 +103,25 @@ type ProxyRequestRepository interface {
 	Count() (int64, error)
 	// UpdateProjectIDBySessionID 批量更新指定 sessionID 的所有请求的 projectID
 	UpdateProjectIDBySessionID(sessionID string, projectID uint64) (int64, error)
-	// MarkStaleAsFailed marks all IN_PROGRESS/PENDING requests from other instances as FAILED
-	// Also marks requests that have been IN_PROGRESS for too long (> 30 minutes) as timed out
-	MarkStaleAsFailed(currentInstanceID string) (int64, error)
+	// MarkStaleAsInterrupted marks all IN_PROGRESS/PENDING requests from other instances as INTERRUPTED
+	// Also marks requests that have been IN_PROGRESS for too long (> 30 minutes) as timed out (FAILED)
+	MarkStaleAsInterrupted(currentInstanceID string) (int64, error)
 	// DeleteOlderThan 删除指定时间之前的请求记录
 	DeleteOlderThan(before time.Time) (int64, error)
+	// GetShapeStats 按请求模型和供应商汇总请求形态（大小、消息数、工具、图片）
+	GetShapeStats(filter RequestShapeFilter) ([]*domain.RequestShapeStats, error)
+	// GetExperimentStats 按路由实验和分组汇总请求的成功率、耗时和成本
+	GetExperimentStats(filter ExperimentStatsFilter) ([]*domain.ExperimentArmStats, error)
+	// GetPromptPrefixStats 按路由、请求模型和可缓存前缀汇总 Claude 请求
+	GetPromptPrefixStats(filter PromptPrefixFilter) ([]*domain.PromptPrefixStats, error)
 }
 
 type ProxyUpstreamAttemptRepository interface {
 	Create(attempt *domain.ProxyUpstreamAttempt) error
 	Update(attempt *domain.ProxyUpstreamAttempt) error
 	ListByProxyRequestID(proxyRequestID uint64) ([]*domain.ProxyUpstreamAttempt, error)
+	// MarkStaleAsInterrupted marks IN_PROGRESS/PENDING attempts of requests from other instances as INTERRUPTED
+	MarkStaleAsInterrupted(currentInstanceID string) (int64, error)
 }
 
 type SystemSettingRepository interface {
@@ -102,6 +142,17 @@ type AntigravityQuotaRepository interface {
 	Delete(email string) error
 }
 
+type OAuthTokenRepository interface {
+	// Get 获取未过期的 access token，不存在或已过期时返回 nil
+	Get(tokenKey string) (*domain.OAuthAccessToken, error)
+	// Upsert 更新或插入 access token（基于 tokenKey）
+	Upsert(token *domain.OAuthAccessToken) error
+	// Delete 删除 access token
+	Delete(tokenKey string) error
+	// DeleteExpired 删除所有已过期的 access token
+	DeleteExpired() error
+}
+
 type UsageStatsRepository interface {
 	// Upsert 更新或插入统计记录
 	Upsert(stats *domain.UsageStats) error
@@ -150,6 +201,29 @@ type UsageStatsFilter struct {
 	Model       *string            // 模型名称
 }
 
+// RequestShapeFilter 请求形态统计的过滤条件
+type RequestShapeFilter struct {
+	StartTime  *time.Time // 开始时间
+	EndTime    *time.Time // 结束时间
+	ProviderID *uint64    // Provider ID
+	ProjectID  *uint64    // 项目 ID
+	ClientType *string    // 客户端类型
+}
+
+// PromptPrefixFilter prompt 缓存分析的过滤条件
+type PromptPrefixFilter struct {
+	StartTime *time.Time // 开始时间
+	EndTime   *time.Time // 结束时间
+	RouteID   *uint64    // 路由 ID
+}
+
+// ExperimentStatsFilter 路由实验统计的过滤条件
+type ExperimentStatsFilter struct {
+	StartTime  *time.Time // 开始时间
+	EndTime    *time.Time // 结束时间
+	Experiment *string    // 实验名称
+}
+
 type APITokenRepository interface {
 	Create(token *domain.APIToken) error
 	Update(token *domain.APIToken) error
@@ -175,6 +249,15 @@ type ModelMappingRepository interface {
 	SeedDefaults() error // Re-seed default mappings
 }
 
+type ModelCapabilityRepository interface {
+	Create(capability *domain.ModelCapability) error
+	Update(capability *domain.ModelCapability) error
+	Delete(id uint64) error
+	GetByID(id uint64) (*domain.ModelCapability, error)
+	// List 获取所有规则，按供应商规则优先、priority、id 排序
+	List() ([]*domain.ModelCapability, error)
+}
+
 type ResponseModelRepository interface {
 	// Upsert 更新或插入 response model（基于 name）
 	Upsert(name string) error
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type AntigravityQuotaRepository struct {
+	rows *table[domain.AntigravityQuota]
+}
+
+func NewAntigravityQuotaRepository() *AntigravityQuotaRepository {
+	return &AntigravityQuotaRepository{rows: newTable[domain.AntigravityQuota]()}
+}
+
+func (r *AntigravityQuotaRepository) Upsert(quota *domain.AntigravityQuota) error {
+	now := time.Now()
+
+	// Try to update first
+	updated := r.rows.update(func(q *domain.AntigravityQuota) bool {
+		return q.DeletedAt == nil && q.Email == quota.Email
+	}, func(q *domain.AntigravityQuota) {
+		q.UpdatedAt = now
+		q.Name = quota.Name
+		q.Picture = quota.Picture
+		q.GCPProjectID = quota.GCPProjectID
+		q.SubscriptionTier = quota.SubscriptionTier
+		q.IsForbidden = quota.IsForbidden
+		q.Models = quota.Models
+	})
+
+	// If no rows updated, insert new record
+	if updated == 0 {
+		quota.CreatedAt = now
+		quota.UpdatedAt = now
+		quota.DeletedAt = nil
+		r.rows.insert(quota, &quota.ID)
+	}
+	quota.UpdatedAt = now
+	return nil
+}
+
+func (r *AntigravityQuotaRepository) GetByEmail(email string) (*domain.AntigravityQuota, error) {
+	q, ok := r.rows.find(func(q *domain.AntigravityQuota) bool { return q.DeletedAt == nil && q.Email == email })
+	if !ok {
+		return nil, nil
+	}
+	return q, nil
+}
+
+func (r *AntigravityQuotaRepository) List() ([]*domain.AntigravityQuota, error) {
+	return r.rows.list(
+		func(q *domain.AntigravityQuota) bool { return q.DeletedAt == nil },
+		func(a, b *domain.AntigravityQuota) bool { return a.UpdatedAt.After(b.UpdatedAt) },
+	), nil
+}
+
+func (r *AntigravityQuotaRepository) Delete(email string) error {
+	r.rows.update(func(q *domain.AntigravityQuota) bool { return q.Email == email }, func(q *domain.AntigravityQuota) {
+		softDelete(&q.DeletedAt, &q.UpdatedAt)
+	})
+	return nil
+}
@@ -0,0 +1,78 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type APITokenRepository struct {
+	rows *table[domain.APIToken]
+}
+
+func NewAPITokenRepository() *APITokenRepository {
+	return &APITokenRepository{rows: newTable[domain.APIToken]()}
+}
+
+func (r *APITokenRepository) Create(t *domain.APIToken) error {
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	r.rows.insert(t, &t.ID)
+	return nil
+}
+
+// Update only touches the editable fields, same as the SQL repository
+// (token, usage counters and timestamps are preserved).
+func (r *APITokenRepository) Update(t *domain.APIToken) error {
+	t.UpdatedAt = time.Now()
+	r.rows.update(func(row *domain.APIToken) bool { return row.ID == t.ID }, func(row *domain.APIToken) {
+		row.UpdatedAt = t.UpdatedAt
+		row.Name = t.Name
+		row.Description = t.Description
+		row.ProjectID = t.ProjectID
+		row.IsEnabled = t.IsEnabled
+		row.ExpiresAt = t.ExpiresAt
+	})
+	return nil
+}
+
+func (r *APITokenRepository) Delete(id uint64) error {
+	r.rows.update(func(t *domain.APIToken) bool { return t.ID == id }, func(t *domain.APIToken) {
+		softDelete(&t.DeletedAt, &t.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *APITokenRepository) GetByID(id uint64) (*domain.APIToken, error) {
+	t, ok := r.rows.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return t, nil
+}
+
+func (r *APITokenRepository) GetByToken(token string) (*domain.APIToken, error) {
+	t, ok := r.rows.find(func(t *domain.APIToken) bool { return t.DeletedAt == nil && t.Token == token })
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return t, nil
+}
+
+func (r *APITokenRepository) List() ([]*domain.APIToken, error) {
+	return r.rows.list(
+		func(t *domain.APIToken) bool { return t.DeletedAt == nil },
+		func(a, b *domain.APIToken) bool { return a.CreatedAt.After(b.CreatedAt) },
+	), nil
+}
+
+func (r *APITokenRepository) IncrementUseCount(id uint64) error {
+	now := time.Now()
+	r.rows.update(func(t *domain.APIToken) bool { return t.ID == id }, func(t *domain.APIToken) {
+		t.UseCount++
+		t.LastUsedAt = &now
+		t.UpdatedAt = now
+	})
+	return nil
+}
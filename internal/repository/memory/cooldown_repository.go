@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type CooldownRepository struct {
+	rows   *table[domain.Cooldown]
+	events *table[domain.CooldownEvent]
+}
+
+func NewCooldownRepository() *CooldownRepository {
+	return &CooldownRepository{
+		rows:   newTable[domain.Cooldown](),
+		events: newTable[domain.CooldownEvent](),
+	}
+}
+
+func (r *CooldownRepository) GetAll() ([]*domain.Cooldown, error) {
+	now := time.Now()
+	return r.rows.list(func(c *domain.Cooldown) bool { return c.UntilTime.After(now) }, nil), nil
+}
+
+func (r *CooldownRepository) GetByProvider(providerID uint64) ([]*domain.Cooldown, error) {
+	now := time.Now()
+	return r.rows.list(func(c *domain.Cooldown) bool {
+		return c.ProviderID == providerID && c.UntilTime.After(now)
+	}, nil), nil
+}
+
+func (r *CooldownRepository) Get(providerID uint64, clientType string) (*domain.Cooldown, error) {
+	now := time.Now()
+	c, ok := r.rows.find(func(c *domain.Cooldown) bool {
+		return c.ProviderID == providerID && c.ClientType == clientType && c.UntilTime.After(now)
+	})
+	if !ok {
+		return nil, nil
+	}
+	return c, nil
+}
+
+func (r *CooldownRepository) Upsert(cooldown *domain.Cooldown) error {
+	now := time.Now()
+	updated := r.rows.update(func(c *domain.Cooldown) bool {
+		return c.ProviderID == cooldown.ProviderID && c.ClientType == cooldown.ClientType
+	}, func(c *domain.Cooldown) {
+		c.UntilTime = cooldown.UntilTime
+		c.Reason = cooldown.Reason
+		c.UpdatedAt = now
+	})
+	cooldown.CreatedAt = now
+	cooldown.UpdatedAt = now
+	if updated == 0 {
+		r.rows.insert(cooldown, &cooldown.ID)
+	}
+	return nil
+}
+
+func (r *CooldownRepository) Delete(providerID uint64, clientType string) error {
+	r.rows.remove(func(c *domain.Cooldown) bool { return c.ProviderID == providerID && c.ClientType == clientType })
+	return nil
+}
+
+func (r *CooldownRepository) DeleteAll(providerID uint64) error {
+	r.rows.remove(func(c *domain.Cooldown) bool { return c.ProviderID == providerID })
+	return nil
+}
+
+func (r *CooldownRepository) DeleteExpired() error {
+	now := time.Now()
+	r.rows.remove(func(c *domain.Cooldown) bool { return !c.UntilTime.After(now) })
+	return nil
+}
+
+func (r *CooldownRepository) CreateEvent(event *domain.CooldownEvent) error {
+	event.CreatedAt = time.Now()
+	r.events.insert(event, &event.ID)
+	return nil
+}
+
+func (r *CooldownRepository) UpdateEventUntil(id uint64, until time.Time) error {
+	r.events.update(func(e *domain.CooldownEvent) bool { return e.ID == id }, func(e *domain.CooldownEvent) {
+		e.UntilTime = until
+	})
+	return nil
+}
+
+func (r *CooldownRepository) ListEvents(from, to time.Time) ([]*domain.CooldownEvent, error) {
+	return r.events.list(func(e *domain.CooldownEvent) bool {
+		return !e.StartTime.Before(from) && e.StartTime.Before(to)
+	}, func(a, b *domain.CooldownEvent) bool { return a.StartTime.Before(b.StartTime) }), nil
+}
+
+func (r *CooldownRepository) DeleteEventsBefore(before time.Time) error {
+	r.events.remove(func(e *domain.CooldownEvent) bool { return e.StartTime.Before(before) })
+	return nil
+}
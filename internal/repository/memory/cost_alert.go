@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type CostAlertRepository struct {
+	rows *table[domain.CostAlert]
+}
+
+func NewCostAlertRepository() *CostAlertRepository {
+	return &CostAlertRepository{rows: newTable[domain.CostAlert]()}
+}
+
+func (r *CostAlertRepository) Create(a *domain.CostAlert) error {
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+	r.rows.insert(a, &a.ID)
+	return nil
+}
+
+func (r *CostAlertRepository) Update(a *domain.CostAlert) error {
+	a.UpdatedAt = time.Now()
+	r.rows.put(a.ID, a)
+	return nil
+}
+
+func (r *CostAlertRepository) Delete(id uint64) error {
+	r.rows.update(func(a *domain.CostAlert) bool { return a.ID == id }, func(a *domain.CostAlert) {
+		softDelete(&a.DeletedAt, &a.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *CostAlertRepository) GetByID(id uint64) (*domain.CostAlert, error) {
+	a, ok := r.rows.get(id)
+	if !ok || a.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return a, nil
+}
+
+func (r *CostAlertRepository) List() ([]*domain.CostAlert, error) {
+	return r.rows.list(func(a *domain.CostAlert) bool { return a.DeletedAt == nil }, nil), nil
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type FailureCountRepository struct {
+	rows *table[domain.FailureCount]
+}
+
+func NewFailureCountRepository() *FailureCountRepository {
+	return &FailureCountRepository{rows: newTable[domain.FailureCount]()}
+}
+
+func (r *FailureCountRepository) Get(providerID uint64, clientType string, reason string) (*domain.FailureCount, error) {
+	fc, ok := r.rows.find(func(fc *domain.FailureCount) bool {
+		return fc.ProviderID == providerID && fc.ClientType == clientType && fc.Reason == reason
+	})
+	if !ok {
+		return nil, nil
+	}
+	return fc, nil
+}
+
+func (r *FailureCountRepository) GetAll() ([]*domain.FailureCount, error) {
+	return r.rows.list(nil, nil), nil
+}
+
+func (r *FailureCountRepository) Upsert(fc *domain.FailureCount) error {
+	now := time.Now()
+	updated := r.rows.update(func(row *domain.FailureCount) bool {
+		return row.ProviderID == fc.ProviderID && row.ClientType == fc.ClientType && row.Reason == fc.Reason
+	}, func(row *domain.FailureCount) {
+		row.Count = fc.Count
+		row.LastFailureAt = fc.LastFailureAt
+		row.UpdatedAt = now
+	})
+	if updated == 0 {
+		fc.CreatedAt = now
+		fc.UpdatedAt = now
+		r.rows.insert(fc, &fc.ID)
+		return nil
+	}
+	fc.UpdatedAt = now
+	return nil
+}
+
+func (r *FailureCountRepository) Delete(providerID uint64, clientType string, reason string) error {
+	r.rows.remove(func(fc *domain.FailureCount) bool {
+		return fc.ProviderID == providerID && fc.ClientType == clientType && fc.Reason == reason
+	})
+	return nil
+}
+
+func (r *FailureCountRepository) DeleteAll(providerID uint64, clientType string) error {
+	r.rows.remove(func(fc *domain.FailureCount) bool {
+		return fc.ProviderID == providerID && fc.ClientType == clientType
+	})
+	return nil
+}
+
+func (r *FailureCountRepository) DeleteExpired(olderThanSeconds int64) error {
+	threshold := time.Now().Add(-time.Duration(olderThanSeconds) * time.Second)
+	r.rows.remove(func(fc *domain.FailureCount) bool { return fc.LastFailureAt.Before(threshold) })
+	return nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type LatencySLORepository struct {
+	rows *table[domain.LatencySLO]
+}
+
+func NewLatencySLORepository() *LatencySLORepository {
+	return &LatencySLORepository{rows: newTable[domain.LatencySLO]()}
+}
+
+func (r *LatencySLORepository) Create(s *domain.LatencySLO) error {
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	r.rows.insert(s, &s.ID)
+	return nil
+}
+
+func (r *LatencySLORepository) Update(s *domain.LatencySLO) error {
+	s.UpdatedAt = time.Now()
+	r.rows.put(s.ID, s)
+	return nil
+}
+
+func (r *LatencySLORepository) Delete(id uint64) error {
+	r.rows.update(func(s *domain.LatencySLO) bool { return s.ID == id }, func(s *domain.LatencySLO) {
+		softDelete(&s.DeletedAt, &s.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *LatencySLORepository) GetByID(id uint64) (*domain.LatencySLO, error) {
+	s, ok := r.rows.get(id)
+	if !ok || s.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *LatencySLORepository) List() ([]*domain.LatencySLO, error) {
+	return r.rows.list(func(s *domain.LatencySLO) bool { return s.DeletedAt == nil }, nil), nil
+}
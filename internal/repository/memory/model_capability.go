@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type ModelCapabilityRepository struct {
+	rows *table[domain.ModelCapability]
+}
+
+func NewModelCapabilityRepository() *ModelCapabilityRepository {
+	return &ModelCapabilityRepository{rows: newTable[domain.ModelCapability]()}
+}
+
+func (r *ModelCapabilityRepository) Create(capability *domain.ModelCapability) error {
+	now := time.Now()
+	capability.CreatedAt = now
+	capability.UpdatedAt = now
+	r.rows.insert(capability, &capability.ID)
+	return nil
+}
+
+func (r *ModelCapabilityRepository) Update(capability *domain.ModelCapability) error {
+	capability.UpdatedAt = time.Now()
+	r.rows.put(capability.ID, capability)
+	return nil
+}
+
+func (r *ModelCapabilityRepository) Delete(id uint64) error {
+	r.rows.update(func(c *domain.ModelCapability) bool { return c.ID == id }, func(c *domain.ModelCapability) {
+		softDelete(&c.DeletedAt, &c.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *ModelCapabilityRepository) GetByID(id uint64) (*domain.ModelCapability, error) {
+	c, ok := r.rows.get(id)
+	if !ok || c.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return c, nil
+}
+
+// List returns live rules, provider-specific ones first, then by priority and id.
+func (r *ModelCapabilityRepository) List() ([]*domain.ModelCapability, error) {
+	return r.rows.list(
+		func(c *domain.ModelCapability) bool { return c.DeletedAt == nil },
+		func(a, b *domain.ModelCapability) bool {
+			if (a.ProviderID == 0) != (b.ProviderID == 0) {
+				return a.ProviderID != 0
+			}
+			return a.Priority < b.Priority
+		},
+	), nil
+}
@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type ModelMappingRepository struct {
+	rows *table[domain.ModelMapping]
+}
+
+// NewModelMappingRepository creates the repository pre-seeded with the built-in rules,
+// matching a freshly created SQL database.
+func NewModelMappingRepository() *ModelMappingRepository {
+	r := &ModelMappingRepository{rows: newTable[domain.ModelMapping]()}
+	r.insertDefaults()
+	return r
+}
+
+func (r *ModelMappingRepository) Create(mapping *domain.ModelMapping) error {
+	now := time.Now()
+	mapping.CreatedAt = now
+	mapping.UpdatedAt = now
+	if mapping.Scope == "" {
+		mapping.Scope = domain.ModelMappingScopeGlobal
+	}
+	r.rows.insert(mapping, &mapping.ID)
+	return nil
+}
+
+func (r *ModelMappingRepository) Update(mapping *domain.ModelMapping) error {
+	mapping.UpdatedAt = time.Now()
+	if mapping.Scope == "" {
+		mapping.Scope = domain.ModelMappingScopeGlobal
+	}
+	r.rows.put(mapping.ID, mapping)
+	return nil
+}
+
+func (r *ModelMappingRepository) Delete(id uint64) error {
+	r.rows.update(func(m *domain.ModelMapping) bool { return m.ID == id }, func(m *domain.ModelMapping) {
+		softDelete(&m.DeletedAt, &m.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *ModelMappingRepository) GetByID(id uint64) (*domain.ModelMapping, error) {
+	m, ok := r.rows.get(id)
+	if !ok || m.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return m, nil
+}
+
+func (r *ModelMappingRepository) List() ([]*domain.ModelMapping, error) {
+	return r.listWhere(func(*domain.ModelMapping) bool { return true }), nil
+}
+
+func (r *ModelMappingRepository) ListEnabled() ([]*domain.ModelMapping, error) {
+	return r.List()
+}
+
+func (r *ModelMappingRepository) ListByQuery(query *domain.ModelMappingQuery) ([]*domain.ModelMapping, error) {
+	return r.listWhere(func(m *domain.ModelMapping) bool {
+		return (m.ClientType == "" || m.ClientType == query.ClientType) &&
+			(m.ProviderType == "" || m.ProviderType == query.ProviderType) &&
+			(m.ProviderID == 0 || m.ProviderID == query.ProviderID) &&
+			(m.ProjectID == 0 || m.ProjectID == query.ProjectID) &&
+			(m.RouteID == 0 || m.RouteID == query.RouteID) &&
+			(m.APITokenID == 0 || m.APITokenID == query.APITokenID)
+	}), nil
+}
+
+func (r *ModelMappingRepository) ListByClientType(clientType domain.ClientType) ([]*domain.ModelMapping, error) {
+	return r.listWhere(func(m *domain.ModelMapping) bool {
+		return m.ClientType == "" || m.ClientType == clientType
+	}), nil
+}
+
+// listWhere returns live mappings ordered by scope (route, provider, global), priority, id.
+func (r *ModelMappingRepository) listWhere(match func(*domain.ModelMapping) bool) []*domain.ModelMapping {
+	return r.rows.list(
+		func(m *domain.ModelMapping) bool { return m.DeletedAt == nil && match(m) },
+		func(a, b *domain.ModelMapping) bool {
+			if sa, sb := scopeRank(a.Scope), scopeRank(b.Scope); sa != sb {
+				return sa < sb
+			}
+			return a.Priority < b.Priority
+		},
+	)
+}
+
+func scopeRank(scope domain.ModelMappingScope) int {
+	switch scope {
+	case domain.ModelMappingScopeRoute:
+		return 1
+	case domain.ModelMappingScopeProvider:
+		return 2
+	default:
+		return 3
+	}
+}
+
+func (r *ModelMappingRepository) Count() (int, error) {
+	return len(r.rows.list(func(m *domain.ModelMapping) bool { return m.DeletedAt == nil }, nil)), nil
+}
+
+func (r *ModelMappingRepository) DeleteAll() error {
+	r.rows.update(func(m *domain.ModelMapping) bool { return m.DeletedAt == nil }, func(m *domain.ModelMapping) {
+		softDelete(&m.DeletedAt, &m.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *ModelMappingRepository) ClearAll() error {
+	r.rows.remove(nil)
+	return nil
+}
+
+func (r *ModelMappingRepository) SeedDefaults() error {
+	// Clear all existing mappings first
+	if err := r.ClearAll(); err != nil {
+		return err
+	}
+	r.insertDefaults()
+	return nil
+}
+
+func (r *ModelMappingRepository) insertDefaults() {
+	defaultRules := []domain.ModelMapping{
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "gpt-4o-mini*", Target: "gemini-2.5-flash", Priority: 0},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "gpt-4o*", Target: "gemini-3-flash", Priority: 1},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "gpt-4*", Target: "gemini-3-pro-high", Priority: 2},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "gpt-3.5*", Target: "gemini-2.5-flash", Priority: 3},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "o1-*", Target: "gemini-3-pro-high", Priority: 4},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "o3-*", Target: "gemini-3-pro-high", Priority: 5},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "claude-3-5-sonnet-*", Target: "claude-sonnet-4-5", Priority: 6},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "claude-3-opus-*", Target: "claude-opus-4-5-thinking", Priority: 7},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "claude-opus-4-*", Target: "claude-opus-4-5-thinking", Priority: 8},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "claude-haiku-*", Target: "gemini-2.5-flash-lite", Priority: 9},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "claude-3-haiku-*", Target: "gemini-2.5-flash-lite", Priority: 10},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "*opus*", Target: "claude-opus-4-5-thinking", Priority: 11},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "*sonnet*", Target: "claude-sonnet-4-5", Priority: 12},
+		{Scope: "global", ClientType: "claude", ProviderType: "antigravity", Pattern: "*haiku*", Target: "gemini-2.5-flash-lite", Priority: 13},
+	}
+
+	for i := range defaultRules {
+		r.Create(&defaultRules[i])
+	}
+}
@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type OAuthTokenRepository struct {
+	rows *table[domain.OAuthAccessToken]
+}
+
+func NewOAuthTokenRepository() *OAuthTokenRepository {
+	return &OAuthTokenRepository{rows: newTable[domain.OAuthAccessToken]()}
+}
+
+func (r *OAuthTokenRepository) Get(tokenKey string) (*domain.OAuthAccessToken, error) {
+	now := time.Now()
+	t, ok := r.rows.find(func(t *domain.OAuthAccessToken) bool {
+		return t.TokenKey == tokenKey && t.ExpiresAt.After(now)
+	})
+	if !ok {
+		return nil, nil
+	}
+	return t, nil
+}
+
+func (r *OAuthTokenRepository) Upsert(token *domain.OAuthAccessToken) error {
+	now := time.Now()
+	updated := r.rows.update(func(t *domain.OAuthAccessToken) bool { return t.TokenKey == token.TokenKey }, func(t *domain.OAuthAccessToken) {
+		t.AccessToken = token.AccessToken
+		t.ExpiresAt = token.ExpiresAt
+		t.UpdatedAt = now
+	})
+	token.CreatedAt = now
+	token.UpdatedAt = now
+	if updated == 0 {
+		r.rows.insert(token, &token.ID)
+	}
+	return nil
+}
+
+func (r *OAuthTokenRepository) Delete(tokenKey string) error {
+	r.rows.remove(func(t *domain.OAuthAccessToken) bool { return t.TokenKey == tokenKey })
+	return nil
+}
+
+func (r *OAuthTokenRepository) DeleteExpired() error {
+	now := time.Now()
+	r.rows.remove(func(t *domain.OAuthAccessToken) bool { return !t.ExpiresAt.After(now) })
+	return nil
+}
@@ -0,0 +1,89 @@
+package memory
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type ProjectRepository struct {
+	rows *table[domain.Project]
+	// slugMu serializes slug uniqueness checks with the following write
+	slugMu sync.Mutex
+}
+
+func NewProjectRepository() *ProjectRepository {
+	return &ProjectRepository{rows: newTable[domain.Project]()}
+}
+
+func (r *ProjectRepository) Create(p *domain.Project) error {
+	r.slugMu.Lock()
+	defer r.slugMu.Unlock()
+
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	// Generate slug if not provided
+	if p.Slug == "" {
+		p.Slug = domain.GenerateSlug(p.Name)
+	}
+
+	// Ensure slug uniqueness (only among non-deleted projects)
+	baseSlug := p.Slug
+	for counter := 1; r.slugTaken(p.Slug, 0); {
+		counter++
+		p.Slug = baseSlug + "-" + strconv.Itoa(counter)
+	}
+
+	r.rows.insert(p, &p.ID)
+	return nil
+}
+
+func (r *ProjectRepository) Update(p *domain.Project) error {
+	r.slugMu.Lock()
+	defer r.slugMu.Unlock()
+
+	p.UpdatedAt = time.Now()
+	if p.Slug != "" && r.slugTaken(p.Slug, p.ID) {
+		return domain.ErrSlugExists
+	}
+	r.rows.put(p.ID, p)
+	return nil
+}
+
+func (r *ProjectRepository) slugTaken(slug string, excludeID uint64) bool {
+	_, ok := r.rows.find(func(p *domain.Project) bool {
+		return p.DeletedAt == nil && p.Slug == slug && p.ID != excludeID
+	})
+	return ok
+}
+
+func (r *ProjectRepository) Delete(id uint64) error {
+	r.rows.update(func(p *domain.Project) bool { return p.ID == id }, func(p *domain.Project) {
+		softDelete(&p.DeletedAt, &p.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *ProjectRepository) GetByID(id uint64) (*domain.Project, error) {
+	p, ok := r.rows.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *ProjectRepository) GetBySlug(slug string) (*domain.Project, error) {
+	p, ok := r.rows.find(func(p *domain.Project) bool { return p.DeletedAt == nil && p.Slug == slug })
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *ProjectRepository) List() ([]*domain.Project, error) {
+	return r.rows.list(func(p *domain.Project) bool { return p.DeletedAt == nil }, nil), nil
+}
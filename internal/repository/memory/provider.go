@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type ProviderRepository struct {
+	rows *table[domain.Provider]
+}
+
+func NewProviderRepository() *ProviderRepository {
+	return &ProviderRepository{rows: newTable[domain.Provider]()}
+}
+
+func (r *ProviderRepository) Create(p *domain.Provider) error {
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	r.rows.insert(p, &p.ID)
+	return nil
+}
+
+func (r *ProviderRepository) Update(p *domain.Provider) error {
+	p.UpdatedAt = time.Now()
+	r.rows.put(p.ID, p)
+	return nil
+}
+
+func (r *ProviderRepository) Delete(id uint64) error {
+	r.rows.update(func(p *domain.Provider) bool { return p.ID == id }, func(p *domain.Provider) {
+		softDelete(&p.DeletedAt, &p.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *ProviderRepository) GetByID(id uint64) (*domain.Provider, error) {
+	p, ok := r.rows.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *ProviderRepository) List() ([]*domain.Provider, error) {
+	return r.rows.list(func(p *domain.Provider) bool { return p.DeletedAt == nil }, nil), nil
+}
+
+func (r *ProviderRepository) ListDeleted() ([]*domain.Provider, error) {
+	return r.rows.list(
+		func(p *domain.Provider) bool { return p.DeletedAt != nil },
+		func(a, b *domain.Provider) bool { return a.DeletedAt.After(*b.DeletedAt) },
+	), nil
+}
+
+func (r *ProviderRepository) Restore(id uint64) error {
+	n := r.rows.update(func(p *domain.Provider) bool { return p.ID == id && p.DeletedAt != nil }, func(p *domain.Provider) {
+		p.DeletedAt = nil
+		p.UpdatedAt = time.Now()
+	})
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,365 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// DefaultMaxProxyRequests is how many request records are kept before the oldest are dropped.
+const DefaultMaxProxyRequests = 1000
+
+// ProxyRequestRepository keeps only the most recent requests so long benchmark runs
+// do not grow without bound.
+type ProxyRequestRepository struct {
+	rows       *table[domain.ProxyRequest]
+	maxRecords int
+
+	mu    sync.Mutex
+	order []uint64 // IDs in creation order, for eviction
+
+	// onDelete is called with the IDs of removed requests (used to drop their attempts)
+	onDelete func(ids []uint64)
+}
+
+// NewProxyRequestRepository creates a repository that keeps at most maxRecords requests.
+// maxRecords <= 0 uses DefaultMaxProxyRequests.
+func NewProxyRequestRepository(maxRecords int) *ProxyRequestRepository {
+	if maxRecords <= 0 {
+		maxRecords = DefaultMaxProxyRequests
+	}
+	return &ProxyRequestRepository{
+		rows:       newTable[domain.ProxyRequest](),
+		maxRecords: maxRecords,
+	}
+}
+
+func (r *ProxyRequestRepository) Create(p *domain.ProxyRequest) error {
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	r.rows.insert(p, &p.ID)
+
+	r.mu.Lock()
+	r.order = append(r.order, p.ID)
+	var evicted []uint64
+	if over := len(r.order) - r.maxRecords; over > 0 {
+		evicted = append(evicted, r.order[:over]...)
+		r.order = append(r.order[:0:0], r.order[over:]...)
+	}
+	r.mu.Unlock()
+
+	if len(evicted) > 0 {
+		r.rows.delete(evicted...)
+		r.notifyDelete(evicted)
+	}
+	return nil
+}
+
+func (r *ProxyRequestRepository) Update(p *domain.ProxyRequest) error {
+	p.UpdatedAt = time.Now()
+	if _, ok := r.rows.get(p.ID); !ok {
+		// Evicted while in flight; do not resurrect it
+		return nil
+	}
+	r.rows.put(p.ID, p)
+	return nil
+}
+
+func (r *ProxyRequestRepository) GetByID(id uint64) (*domain.ProxyRequest, error) {
+	p, ok := r.rows.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *ProxyRequestRepository) List(limit, offset int) ([]*domain.ProxyRequest, error) {
+	return page(r.rows.list(nil, newestFirst), limit, offset), nil
+}
+
+func (r *ProxyRequestRepository) ListCursor(limit int, before, after uint64) ([]*domain.ProxyRequest, error) {
+	rows := r.rows.list(func(p *domain.ProxyRequest) bool {
+		if after > 0 {
+			return p.ID > after
+		}
+		if before > 0 {
+			return p.ID < before
+		}
+		return true
+	}, newestFirst)
+	rows = page(rows, limit, 0)
+
+	// Same as the SQL repository: list views do not carry request/response bodies
+	// or router decisions
+	for _, p := range rows {
+		p.RequestInfo = nil
+		p.ResponseInfo = nil
+		p.RouterDecision = nil
+	}
+	return rows, nil
+}
+
+func (r *ProxyRequestRepository) Count() (int64, error) {
+	return int64(r.rows.len()), nil
+}
+
+func (r *ProxyRequestRepository) UpdateProjectIDBySessionID(sessionID string, projectID uint64) (int64, error) {
+	now := time.Now()
+	return r.rows.update(func(p *domain.ProxyRequest) bool { return p.SessionID == sessionID }, func(p *domain.ProxyRequest) {
+		p.ProjectID = projectID
+		p.UpdatedAt = now
+	}), nil
+}
+
+func (r *ProxyRequestRepository) MarkStaleAsInterrupted(currentInstanceID string) (int64, error) {
+	timeoutThreshold := time.Now().Add(-30 * time.Minute)
+	now := time.Now()
+
+	return r.rows.update(func(p *domain.ProxyRequest) bool {
+		if p.Status != "PENDING" && p.Status != "IN_PROGRESS" {
+			return false
+		}
+		return p.InstanceID != currentInstanceID ||
+			(!p.StartTime.IsZero() && p.StartTime.Before(timeoutThreshold))
+	}, func(p *domain.ProxyRequest) {
+		if p.InstanceID != currentInstanceID {
+			p.Status = "INTERRUPTED"
+			p.Error = "Server restarted"
+		} else {
+			p.Status = "FAILED"
+			p.Error = "Request timed out (stuck in progress)"
+		}
+		p.UpdatedAt = now
+	}), nil
+}
+
+func (r *ProxyRequestRepository) DeleteOlderThan(before time.Time) (int64, error) {
+	var ids []uint64
+	for _, p := range r.rows.list(func(p *domain.ProxyRequest) bool { return p.CreatedAt.Before(before) }, nil) {
+		ids = append(ids, p.ID)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	deleted := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	r.mu.Lock()
+	kept := r.order[:0]
+	for _, id := range r.order {
+		if !deleted[id] {
+			kept = append(kept, id)
+		}
+	}
+	r.order = kept
+	r.mu.Unlock()
+
+	r.rows.delete(ids...)
+	r.notifyDelete(ids)
+	return int64(len(ids)), nil
+}
+
+// GetShapeStats aggregates the request shapes of the stored requests, which only cover
+// the most recent maxRecords requests.
+func (r *ProxyRequestRepository) GetShapeStats(filter repository.RequestShapeFilter) ([]*domain.RequestShapeStats, error) {
+	type key struct {
+		model      string
+		providerID uint64
+	}
+	byKey := make(map[key]*domain.RequestShapeStats)
+	var results []*domain.RequestShapeStats
+	for _, p := range r.rows.list(func(p *domain.ProxyRequest) bool { return matchesShapeFilter(p, filter) }, nil) {
+		k := key{p.RequestModel, p.ProviderID}
+		s, ok := byKey[k]
+		if !ok {
+			s = &domain.RequestShapeStats{Model: p.RequestModel, ProviderID: p.ProviderID}
+			byKey[k] = s
+			results = append(results, s)
+		}
+		s.TotalRequests++
+		s.TotalPromptBytes += p.PromptBytes
+		s.MaxPromptBytes = max(s.MaxPromptBytes, p.PromptBytes)
+		s.TotalMessages += uint64(p.MessageCount)
+		s.MaxMessages = max(s.MaxMessages, uint64(p.MessageCount))
+		s.TotalTools += uint64(p.ToolCount)
+		if p.ToolCount > 0 {
+			s.ToolRequests++
+		}
+		if p.HasImage {
+			s.ImageRequests++
+		}
+		s.InputTokens += p.InputTokenCount
+		s.TotalCost += p.Cost
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].TotalCost > results[j].TotalCost })
+	return results, nil
+}
+
+func matchesShapeFilter(p *domain.ProxyRequest, filter repository.RequestShapeFilter) bool {
+	switch {
+	case p.PromptBytes == 0:
+		return false
+	case filter.StartTime != nil && p.CreatedAt.Before(*filter.StartTime):
+		return false
+	case filter.EndTime != nil && p.CreatedAt.After(*filter.EndTime):
+		return false
+	case filter.ProviderID != nil && p.ProviderID != *filter.ProviderID:
+		return false
+	case filter.ProjectID != nil && p.ProjectID != *filter.ProjectID:
+		return false
+	case filter.ClientType != nil && string(p.ClientType) != *filter.ClientType:
+		return false
+	}
+	return true
+}
+
+// GetExperimentStats aggregates the finished requests of route experiments among the
+// stored requests, which only cover the most recent maxRecords requests.
+func (r *ProxyRequestRepository) GetExperimentStats(filter repository.ExperimentStatsFilter) ([]*domain.ExperimentArmStats, error) {
+	type key struct {
+		experiment string
+		arm        string
+	}
+	byKey := make(map[key]*domain.ExperimentArmStats)
+	var results []*domain.ExperimentArmStats
+	for _, p := range r.rows.list(func(p *domain.ProxyRequest) bool { return matchesExperimentFilter(p, filter) }, nil) {
+		k := key{p.Experiment, p.ExperimentArm}
+		s, ok := byKey[k]
+		if !ok {
+			s = &domain.ExperimentArmStats{Experiment: p.Experiment, Arm: p.ExperimentArm}
+			byKey[k] = s
+			results = append(results, s)
+		}
+		s.TotalRequests++
+		if p.Status == "COMPLETED" {
+			s.SuccessfulRequests++
+			s.TotalDuration += uint64(p.Duration)
+		} else {
+			s.FailedRequests++
+		}
+		s.InputTokens += p.InputTokenCount
+		s.OutputTokens += p.OutputTokenCount
+		s.TotalCost += p.Cost
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Experiment != results[j].Experiment {
+			return results[i].Experiment < results[j].Experiment
+		}
+		return results[i].Arm < results[j].Arm
+	})
+	return results, nil
+}
+
+func matchesExperimentFilter(p *domain.ProxyRequest, filter repository.ExperimentStatsFilter) bool {
+	switch {
+	case p.Experiment == "":
+		return false
+	case p.Status != "COMPLETED" && p.Status != "FAILED":
+		return false
+	case filter.StartTime != nil && p.CreatedAt.Before(*filter.StartTime):
+		return false
+	case filter.EndTime != nil && p.CreatedAt.After(*filter.EndTime):
+		return false
+	case filter.Experiment != nil && p.Experiment != *filter.Experiment:
+		return false
+	}
+	return true
+}
+
+// GetPromptPrefixStats aggregates the completed Claude requests among the stored
+// requests, which only cover the most recent maxRecords requests.
+func (r *ProxyRequestRepository) GetPromptPrefixStats(filter repository.PromptPrefixFilter) ([]*domain.PromptPrefixStats, error) {
+	type key struct {
+		routeID uint64
+		model   string
+		hash    string
+	}
+	byKey := make(map[key]*domain.PromptPrefixStats)
+	var results []*domain.PromptPrefixStats
+	for _, p := range r.rows.list(func(p *domain.ProxyRequest) bool { return matchesPromptPrefixFilter(p, filter) }, nil) {
+		k := key{p.RouteID, p.RequestModel, p.PrefixHash}
+		s, ok := byKey[k]
+		if !ok {
+			s = &domain.PromptPrefixStats{RouteID: p.RouteID, Model: p.RequestModel, PrefixHash: p.PrefixHash}
+			byKey[k] = s
+			results = append(results, s)
+		}
+		s.ProviderID = max(s.ProviderID, p.ProviderID)
+		s.PrefixBytes = max(s.PrefixBytes, p.PrefixBytes)
+		s.Requests++
+		if p.CacheControl {
+			s.CacheControlRequests++
+		}
+		if p.CacheControlStripped {
+			s.StrippedRequests++
+		}
+		s.InputTokens += p.InputTokenCount
+		s.CacheReadTokens += p.CacheReadCount
+		s.CacheWriteTokens += p.CacheWriteCount
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].RouteID != results[j].RouteID {
+			return results[i].RouteID < results[j].RouteID
+		}
+		return results[i].Requests > results[j].Requests
+	})
+	return results, nil
+}
+
+func matchesPromptPrefixFilter(p *domain.ProxyRequest, filter repository.PromptPrefixFilter) bool {
+	switch {
+	case p.PrefixHash == "":
+		return false
+	case p.Status != "COMPLETED":
+		return false
+	case filter.StartTime != nil && p.CreatedAt.Before(*filter.StartTime):
+		return false
+	case filter.EndTime != nil && p.CreatedAt.After(*filter.EndTime):
+		return false
+	case filter.RouteID != nil && p.RouteID != *filter.RouteID:
+		return false
+	}
+	return true
+}
+
+// instanceID returns the instance that handled the request, if it is still stored.
+func (r *ProxyRequestRepository) instanceID(id uint64) (string, bool) {
+	p, ok := r.rows.get(id)
+	if !ok {
+		return "", false
+	}
+	return p.InstanceID, true
+}
+
+func (r *ProxyRequestRepository) notifyDelete(ids []uint64) {
+	r.mu.Lock()
+	onDelete := r.onDelete
+	r.mu.Unlock()
+	if onDelete != nil {
+		onDelete(ids)
+	}
+}
+
+func newestFirst(a, b *domain.ProxyRequest) bool {
+	return a.ID > b.ID
+}
+
+// page applies limit/offset; limit <= 0 means no limit.
+func page[T any](rows []T, limit, offset int) []T {
+	if offset > 0 {
+		if offset >= len(rows) {
+			return rows[:0]
+		}
+		rows = rows[offset:]
+	}
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type ProxyUpstreamAttemptRepository struct {
+	rows     *table[domain.ProxyUpstreamAttempt]
+	requests *ProxyRequestRepository
+}
+
+// NewProxyUpstreamAttemptRepository creates the attempt repository for requests.
+// Attempts are dropped together with their request when it is evicted or deleted.
+func NewProxyUpstreamAttemptRepository(requests *ProxyRequestRepository) *ProxyUpstreamAttemptRepository {
+	r := &ProxyUpstreamAttemptRepository{
+		rows:     newTable[domain.ProxyUpstreamAttempt](),
+		requests: requests,
+	}
+	requests.mu.Lock()
+	requests.onDelete = r.deleteByProxyRequestIDs
+	requests.mu.Unlock()
+	return r
+}
+
+func (r *ProxyUpstreamAttemptRepository) Create(a *domain.ProxyUpstreamAttempt) error {
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+	r.rows.insert(a, &a.ID)
+	return nil
+}
+
+func (r *ProxyUpstreamAttemptRepository) Update(a *domain.ProxyUpstreamAttempt) error {
+	a.UpdatedAt = time.Now()
+	r.rows.put(a.ID, a)
+	return nil
+}
+
+func (r *ProxyUpstreamAttemptRepository) ListByProxyRequestID(proxyRequestID uint64) ([]*domain.ProxyUpstreamAttempt, error) {
+	return r.rows.list(func(a *domain.ProxyUpstreamAttempt) bool { return a.ProxyRequestID == proxyRequestID }, nil), nil
+}
+
+func (r *ProxyUpstreamAttemptRepository) MarkStaleAsInterrupted(currentInstanceID string) (int64, error) {
+	now := time.Now()
+	return r.rows.update(func(a *domain.ProxyUpstreamAttempt) bool {
+		if a.Status != "PENDING" && a.Status != "IN_PROGRESS" {
+			return false
+		}
+		instanceID, ok := r.requests.instanceID(a.ProxyRequestID)
+		return ok && instanceID != currentInstanceID
+	}, func(a *domain.ProxyUpstreamAttempt) {
+		a.Status = "INTERRUPTED"
+		a.UpdatedAt = now
+	}), nil
+}
+
+func (r *ProxyUpstreamAttemptRepository) deleteByProxyRequestIDs(ids []uint64) {
+	deleted := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		deleted[id] = true
+	}
+	r.rows.remove(func(a *domain.ProxyUpstreamAttempt) bool { return deleted[a.ProxyRequestID] })
+}
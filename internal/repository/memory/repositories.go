@@ -0,0 +1,25 @@
+package memory
+
+import "github.com/awsl-project/maxx/internal/repository"
+
+// Compile-time interface checks
+var (
+	_ repository.ProviderRepository             = (*ProviderRepository)(nil)
+	_ repository.RouteRepository                = (*RouteRepository)(nil)
+	_ repository.RoutingStrategyRepository      = (*RoutingStrategyRepository)(nil)
+	_ repository.RetryConfigRepository          = (*RetryConfigRepository)(nil)
+	_ repository.ProjectRepository              = (*ProjectRepository)(nil)
+	_ repository.SessionRepository              = (*SessionRepository)(nil)
+	_ repository.ProxyRequestRepository         = (*ProxyRequestRepository)(nil)
+	_ repository.ProxyUpstreamAttemptRepository = (*ProxyUpstreamAttemptRepository)(nil)
+	_ repository.SystemSettingRepository        = (*SystemSettingRepository)(nil)
+	_ repository.AntigravityQuotaRepository     = (*AntigravityQuotaRepository)(nil)
+	_ repository.OAuthTokenRepository           = (*OAuthTokenRepository)(nil)
+	_ repository.UsageStatsRepository           = (*UsageStatsRepository)(nil)
+	_ repository.APITokenRepository             = (*APITokenRepository)(nil)
+	_ repository.ModelMappingRepository         = (*ModelMappingRepository)(nil)
+	_ repository.ModelCapabilityRepository      = (*ModelCapabilityRepository)(nil)
+	_ repository.ResponseModelRepository        = (*ResponseModelRepository)(nil)
+	_ repository.CooldownRepository             = (*CooldownRepository)(nil)
+	_ repository.FailureCountRepository         = (*FailureCountRepository)(nil)
+)
@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type ResponseModelRepository struct {
+	rows *table[domain.ResponseModel]
+}
+
+func NewResponseModelRepository() *ResponseModelRepository {
+	return &ResponseModelRepository{rows: newTable[domain.ResponseModel]()}
+}
+
+func (r *ResponseModelRepository) Upsert(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	now := time.Now()
+	updated := r.rows.update(func(m *domain.ResponseModel) bool { return m.Name == name }, func(m *domain.ResponseModel) {
+		m.LastSeenAt = now
+		m.UseCount++
+	})
+	if updated == 0 {
+		m := &domain.ResponseModel{CreatedAt: now, Name: name, LastSeenAt: now, UseCount: 1}
+		r.rows.insert(m, &m.ID)
+	}
+	return nil
+}
+
+func (r *ResponseModelRepository) BatchUpsert(names []string) error {
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			if err := r.Upsert(name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *ResponseModelRepository) List() ([]*domain.ResponseModel, error) {
+	return r.rows.list(nil, func(a, b *domain.ResponseModel) bool {
+		if a.UseCount != b.UseCount {
+			return a.UseCount > b.UseCount
+		}
+		return a.LastSeenAt.After(b.LastSeenAt)
+	}), nil
+}
+
+func (r *ResponseModelRepository) ListNames() ([]string, error) {
+	models, _ := r.List()
+	names := make([]string, len(models))
+	for i, m := range models {
+		names[i] = m.Name
+	}
+	return names, nil
+}
@@ -0,0 +1,56 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type RetryConfigRepository struct {
+	rows *table[domain.RetryConfig]
+}
+
+func NewRetryConfigRepository() *RetryConfigRepository {
+	return &RetryConfigRepository{rows: newTable[domain.RetryConfig]()}
+}
+
+func (r *RetryConfigRepository) Create(c *domain.RetryConfig) error {
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	r.rows.insert(c, &c.ID)
+	return nil
+}
+
+func (r *RetryConfigRepository) Update(c *domain.RetryConfig) error {
+	c.UpdatedAt = time.Now()
+	r.rows.put(c.ID, c)
+	return nil
+}
+
+func (r *RetryConfigRepository) Delete(id uint64) error {
+	r.rows.update(func(c *domain.RetryConfig) bool { return c.ID == id }, func(c *domain.RetryConfig) {
+		softDelete(&c.DeletedAt, &c.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *RetryConfigRepository) GetByID(id uint64) (*domain.RetryConfig, error) {
+	c, ok := r.rows.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *RetryConfigRepository) GetDefault() (*domain.RetryConfig, error) {
+	c, ok := r.rows.find(func(c *domain.RetryConfig) bool { return c.DeletedAt == nil && c.IsDefault })
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return c, nil
+}
+
+func (r *RetryConfigRepository) List() ([]*domain.RetryConfig, error) {
+	return r.rows.list(func(c *domain.RetryConfig) bool { return c.DeletedAt == nil }, nil), nil
+}
@@ -0,0 +1,102 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type RouteRepository struct {
+	rows *table[domain.Route]
+}
+
+func NewRouteRepository() *RouteRepository {
+	return &RouteRepository{rows: newTable[domain.Route]()}
+}
+
+func (r *RouteRepository) Create(route *domain.Route) error {
+	now := time.Now()
+	route.CreatedAt = now
+	route.UpdatedAt = now
+	r.rows.insert(route, &route.ID)
+	return nil
+}
+
+func (r *RouteRepository) Update(route *domain.Route) error {
+	route.UpdatedAt = time.Now()
+	r.rows.put(route.ID, route)
+	return nil
+}
+
+func (r *RouteRepository) Delete(id uint64) error {
+	r.rows.update(func(route *domain.Route) bool { return route.ID == id }, func(route *domain.Route) {
+		softDelete(&route.DeletedAt, &route.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *RouteRepository) BatchUpdatePositions(updates []domain.RoutePositionUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	positions := make(map[uint64]int, len(updates))
+	for _, update := range updates {
+		positions[update.ID] = update.Position
+	}
+	now := time.Now()
+	r.rows.update(func(route *domain.Route) bool {
+		_, ok := positions[route.ID]
+		return ok
+	}, func(route *domain.Route) {
+		route.Position = positions[route.ID]
+		route.UpdatedAt = now
+	})
+	return nil
+}
+
+func (r *RouteRepository) GetByID(id uint64) (*domain.Route, error) {
+	route, ok := r.rows.get(id)
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return route, nil
+}
+
+func (r *RouteRepository) FindByKey(projectID, providerID uint64, clientType domain.ClientType) (*domain.Route, error) {
+	route, ok := r.rows.find(func(route *domain.Route) bool {
+		return route.DeletedAt == nil &&
+			route.ProjectID == projectID &&
+			route.ProviderID == providerID &&
+			route.ClientType == clientType
+	})
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return route, nil
+}
+
+func (r *RouteRepository) List() ([]*domain.Route, error) {
+	return r.rows.list(
+		func(route *domain.Route) bool { return route.DeletedAt == nil },
+		func(a, b *domain.Route) bool { return a.Position < b.Position },
+	), nil
+}
+
+func (r *RouteRepository) ListDeleted() ([]*domain.Route, error) {
+	return r.rows.list(
+		func(route *domain.Route) bool { return route.DeletedAt != nil },
+		func(a, b *domain.Route) bool { return a.DeletedAt.After(*b.DeletedAt) },
+	), nil
+}
+
+func (r *RouteRepository) Restore(id uint64) error {
+	n := r.rows.update(func(route *domain.Route) bool { return route.ID == id && route.DeletedAt != nil }, func(route *domain.Route) {
+		route.DeletedAt = nil
+		route.UpdatedAt = time.Now()
+	})
+	if n == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
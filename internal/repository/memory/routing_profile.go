@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type RoutingProfileRepository struct {
+	rows *table[domain.RoutingProfile]
+}
+
+func NewRoutingProfileRepository() *RoutingProfileRepository {
+	return &RoutingProfileRepository{rows: newTable[domain.RoutingProfile]()}
+}
+
+func (r *RoutingProfileRepository) Create(p *domain.RoutingProfile) error {
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	r.rows.insert(p, &p.ID)
+	return nil
+}
+
+func (r *RoutingProfileRepository) Update(p *domain.RoutingProfile) error {
+	p.UpdatedAt = time.Now()
+	r.rows.put(p.ID, p)
+	return nil
+}
+
+func (r *RoutingProfileRepository) Delete(id uint64) error {
+	r.rows.update(func(p *domain.RoutingProfile) bool { return p.ID == id }, func(p *domain.RoutingProfile) {
+		softDelete(&p.DeletedAt, &p.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *RoutingProfileRepository) GetByID(id uint64) (*domain.RoutingProfile, error) {
+	p, ok := r.rows.get(id)
+	if !ok || p.DeletedAt != nil {
+		return nil, domain.ErrNotFound
+	}
+	return p, nil
+}
+
+func (r *RoutingProfileRepository) List() ([]*domain.RoutingProfile, error) {
+	return r.rows.list(func(p *domain.RoutingProfile) bool { return p.DeletedAt == nil }, nil), nil
+}
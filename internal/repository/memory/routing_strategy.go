@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type RoutingStrategyRepository struct {
+	rows *table[domain.RoutingStrategy]
+}
+
+func NewRoutingStrategyRepository() *RoutingStrategyRepository {
+	return &RoutingStrategyRepository{rows: newTable[domain.RoutingStrategy]()}
+}
+
+func (r *RoutingStrategyRepository) Create(s *domain.RoutingStrategy) error {
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	r.rows.insert(s, &s.ID)
+	return nil
+}
+
+func (r *RoutingStrategyRepository) Update(s *domain.RoutingStrategy) error {
+	s.UpdatedAt = time.Now()
+	r.rows.put(s.ID, s)
+	return nil
+}
+
+func (r *RoutingStrategyRepository) Delete(id uint64) error {
+	r.rows.update(func(s *domain.RoutingStrategy) bool { return s.ID == id }, func(s *domain.RoutingStrategy) {
+		softDelete(&s.DeletedAt, &s.UpdatedAt)
+	})
+	return nil
+}
+
+func (r *RoutingStrategyRepository) GetByProjectID(projectID uint64) (*domain.RoutingStrategy, error) {
+	s, ok := r.rows.find(func(s *domain.RoutingStrategy) bool {
+		return s.DeletedAt == nil && s.ProjectID == projectID
+	})
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *RoutingStrategyRepository) List() ([]*domain.RoutingStrategy, error) {
+	return r.rows.list(func(s *domain.RoutingStrategy) bool { return s.DeletedAt == nil }, nil), nil
+}
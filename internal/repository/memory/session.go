@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type SessionRepository struct {
+	rows *table[domain.Session]
+}
+
+func NewSessionRepository() *SessionRepository {
+	return &SessionRepository{rows: newTable[domain.Session]()}
+}
+
+func (r *SessionRepository) Create(s *domain.Session) error {
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+	r.rows.insert(s, &s.ID)
+	return nil
+}
+
+func (r *SessionRepository) Update(s *domain.Session) error {
+	s.UpdatedAt = time.Now()
+	r.rows.put(s.ID, s)
+	return nil
+}
+
+func (r *SessionRepository) GetBySessionID(sessionID string) (*domain.Session, error) {
+	s, ok := r.rows.find(func(s *domain.Session) bool { return s.DeletedAt == nil && s.SessionID == sessionID })
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	return s, nil
+}
+
+func (r *SessionRepository) List() ([]*domain.Session, error) {
+	return r.rows.list(
+		func(s *domain.Session) bool { return s.DeletedAt == nil },
+		func(a, b *domain.Session) bool { return a.CreatedAt.After(b.CreatedAt) },
+	), nil
+}
@@ -0,0 +1,153 @@
+// Package memory provides in-memory implementations of the repository interfaces.
+//
+// Nothing is persisted: all data is lost when the process exits. It is intended for
+// benchmarks, CI and running maxx as a stateless converter/router (DSN "memory://").
+package memory
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DSN selects the in-memory repositories instead of a SQL database.
+const DSN = "memory://"
+
+// IsDSN reports whether dsn selects the in-memory repositories.
+func IsDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, DSN)
+}
+
+// table stores rows by auto-increment ID.
+// Rows are copied on the way in and out, so callers never share state with the store
+// (nested pointers such as provider configs are shared, same as a shallow GORM scan).
+type table[T any] struct {
+	mu     sync.RWMutex
+	nextID uint64
+	rows   map[uint64]*T
+}
+
+func newTable[T any]() *table[T] {
+	return &table[T]{rows: make(map[uint64]*T)}
+}
+
+// insert assigns the next ID through id (a pointer into row) and stores a copy of row.
+func (t *table[T]) insert(row *T, id *uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nextID++
+	*id = t.nextID
+	cp := *row
+	t.rows[t.nextID] = &cp
+}
+
+// put stores a copy of row under id, creating the row if it does not exist.
+func (t *table[T]) put(id uint64, row *T) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	cp := *row
+	t.rows[id] = &cp
+	if id > t.nextID {
+		t.nextID = id
+	}
+}
+
+func (t *table[T]) get(id uint64) (*T, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	row, ok := t.rows[id]
+	if !ok {
+		return nil, false
+	}
+	cp := *row
+	return &cp, true
+}
+
+// find returns the matching row with the lowest ID.
+func (t *table[T]) find(match func(*T) bool) (*T, bool) {
+	rows := t.list(match, nil)
+	if len(rows) == 0 {
+		return nil, false
+	}
+	return rows[0], true
+}
+
+// list returns copies of all matching rows ordered by ID, then stably by less (if set).
+func (t *table[T]) list(match func(*T) bool, less func(a, b *T) bool) []*T {
+	t.mu.RLock()
+	ids := t.sortedIDs()
+	result := make([]*T, 0, len(ids))
+	for _, id := range ids {
+		row := t.rows[id]
+		if match == nil || match(row) {
+			cp := *row
+			result = append(result, &cp)
+		}
+	}
+	t.mu.RUnlock()
+
+	if less != nil {
+		sort.SliceStable(result, func(i, j int) bool { return less(result[i], result[j]) })
+	}
+	return result
+}
+
+// update applies fn to every matching row in place and returns the number of rows changed.
+func (t *table[T]) update(match func(*T) bool, fn func(*T)) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var n int64
+	for _, row := range t.rows {
+		if match(row) {
+			fn(row)
+			n++
+		}
+	}
+	return n
+}
+
+// remove deletes every matching row and returns the number of rows removed.
+func (t *table[T]) remove(match func(*T) bool) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var n int64
+	for id, row := range t.rows {
+		if match == nil || match(row) {
+			delete(t.rows, id)
+			n++
+		}
+	}
+	return n
+}
+
+// delete removes rows by ID.
+func (t *table[T]) delete(ids ...uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range ids {
+		delete(t.rows, id)
+	}
+}
+
+func (t *table[T]) len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return len(t.rows)
+}
+
+func (t *table[T]) sortedIDs() []uint64 {
+	ids := make([]uint64, 0, len(t.rows))
+	for id := range t.rows {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// softDelete marks a row as deleted, mirroring the deleted_at column of the SQL repositories.
+func softDelete(deletedAt **time.Time, updatedAt *time.Time) {
+	now := time.Now()
+	*deletedAt = &now
+	*updatedAt = now
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+type SystemSettingRepository struct {
+	mu       sync.RWMutex
+	settings map[string]*domain.SystemSetting
+}
+
+func NewSystemSettingRepository() *SystemSettingRepository {
+	return &SystemSettingRepository{settings: make(map[string]*domain.SystemSetting)}
+}
+
+func (r *SystemSettingRepository) Get(key string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if s, ok := r.settings[key]; ok {
+		return s.Value, nil
+	}
+	return "", nil
+}
+
+func (r *SystemSettingRepository) Set(key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if s, ok := r.settings[key]; ok {
+		s.Value = value
+		s.UpdatedAt = now
+		return nil
+	}
+	r.settings[key] = &domain.SystemSetting{Key: key, Value: value, CreatedAt: now, UpdatedAt: now}
+	return nil
+}
+
+func (r *SystemSettingRepository) GetAll() ([]*domain.SystemSetting, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	settings := make([]*domain.SystemSetting, 0, len(r.settings))
+	for _, s := range r.settings {
+		cp := *s
+		settings = append(settings, &cp)
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings, nil
+}
+
+func (r *SystemSettingRepository) Delete(key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.settings, key)
+	return nil
+}
@@ -0,0 +1,213 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// UsageStatsRepository stores pre-aggregated rows written through Upsert.
+// There is no request history to aggregate from, so AggregateMinute, RollUp and
+// ClearAndRecalculate are no-ops and dashboards stay empty unless rows are upserted.
+type UsageStatsRepository struct {
+	rows *table[domain.UsageStats]
+}
+
+func NewUsageStatsRepository() *UsageStatsRepository {
+	return &UsageStatsRepository{rows: newTable[domain.UsageStats]()}
+}
+
+func (r *UsageStatsRepository) Upsert(stats *domain.UsageStats) error {
+	stats.CreatedAt = time.Now()
+	updated := r.rows.update(func(s *domain.UsageStats) bool { return sameBucket(s, stats) }, func(s *domain.UsageStats) {
+		s.TotalRequests = stats.TotalRequests
+		s.SuccessfulRequests = stats.SuccessfulRequests
+		s.FailedRequests = stats.FailedRequests
+		s.TotalDurationMs = stats.TotalDurationMs
+		s.InputTokens = stats.InputTokens
+		s.OutputTokens = stats.OutputTokens
+		s.CacheRead = stats.CacheRead
+		s.CacheWrite = stats.CacheWrite
+		s.Cost = stats.Cost
+	})
+	if updated == 0 {
+		r.rows.insert(stats, &stats.ID)
+	}
+	return nil
+}
+
+func (r *UsageStatsRepository) BatchUpsert(stats []*domain.UsageStats) error {
+	for _, s := range stats {
+		if err := r.Upsert(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *UsageStatsRepository) Query(filter repository.UsageStatsFilter) ([]*domain.UsageStats, error) {
+	return r.rows.list(
+		func(s *domain.UsageStats) bool { return matchFilter(s, filter) },
+		func(a, b *domain.UsageStats) bool { return a.TimeBucket.After(b.TimeBucket) },
+	), nil
+}
+
+func (r *UsageStatsRepository) QueryWithRealtime(filter repository.UsageStatsFilter) ([]*domain.UsageStats, error) {
+	return r.Query(filter)
+}
+
+func (r *UsageStatsRepository) GetSummary(filter repository.UsageStatsFilter) (*domain.UsageStatsSummary, error) {
+	rows, _ := r.Query(filter)
+	s := &domain.UsageStatsSummary{}
+	for _, row := range rows {
+		addToSummary(s, row)
+	}
+	finishSummary(s)
+	return s, nil
+}
+
+func (r *UsageStatsRepository) GetSummaryByProvider(filter repository.UsageStatsFilter) (map[uint64]*domain.UsageStatsSummary, error) {
+	return r.summaryBy(filter, func(s *domain.UsageStats) uint64 { return s.ProviderID }), nil
+}
+
+func (r *UsageStatsRepository) GetSummaryByRoute(filter repository.UsageStatsFilter) (map[uint64]*domain.UsageStatsSummary, error) {
+	return r.summaryBy(filter, func(s *domain.UsageStats) uint64 { return s.RouteID }), nil
+}
+
+func (r *UsageStatsRepository) GetSummaryByProject(filter repository.UsageStatsFilter) (map[uint64]*domain.UsageStatsSummary, error) {
+	return r.summaryBy(filter, func(s *domain.UsageStats) uint64 { return s.ProjectID }), nil
+}
+
+func (r *UsageStatsRepository) GetSummaryByAPIToken(filter repository.UsageStatsFilter) (map[uint64]*domain.UsageStatsSummary, error) {
+	return r.summaryBy(filter, func(s *domain.UsageStats) uint64 { return s.APITokenID }), nil
+}
+
+func (r *UsageStatsRepository) GetSummaryByClientType(filter repository.UsageStatsFilter) (map[string]*domain.UsageStatsSummary, error) {
+	return summarize(r.rows.list(func(s *domain.UsageStats) bool { return matchFilter(s, filter) }, nil),
+		func(s *domain.UsageStats) string { return s.ClientType }), nil
+}
+
+func (r *UsageStatsRepository) summaryBy(filter repository.UsageStatsFilter, key func(*domain.UsageStats) uint64) map[uint64]*domain.UsageStatsSummary {
+	return summarize(r.rows.list(func(s *domain.UsageStats) bool { return matchFilter(s, filter) }, nil), key)
+}
+
+func (r *UsageStatsRepository) DeleteOlderThan(granularity domain.Granularity, before time.Time) (int64, error) {
+	return r.rows.remove(func(s *domain.UsageStats) bool {
+		return s.Granularity == granularity && s.TimeBucket.Before(before)
+	}), nil
+}
+
+func (r *UsageStatsRepository) GetLatestTimeBucket(granularity domain.Granularity) (*time.Time, error) {
+	var latest *time.Time
+	for _, s := range r.rows.list(func(s *domain.UsageStats) bool { return s.Granularity == granularity }, nil) {
+		if latest == nil || s.TimeBucket.After(*latest) {
+			t := s.TimeBucket
+			latest = &t
+		}
+	}
+	return latest, nil
+}
+
+func (r *UsageStatsRepository) GetProviderStats(clientType string, projectID uint64) (map[uint64]*domain.ProviderStats, error) {
+	stats := make(map[uint64]*domain.ProviderStats)
+	durations := make(map[uint64]uint64)
+	rows := r.rows.list(func(s *domain.UsageStats) bool {
+		return s.ProviderID > 0 &&
+			(clientType == "" || s.ClientType == clientType) &&
+			(projectID == 0 || s.ProjectID == projectID)
+	}, nil)
+	for _, row := range rows {
+		s, ok := stats[row.ProviderID]
+		if !ok {
+			s = &domain.ProviderStats{ProviderID: row.ProviderID}
+			stats[row.ProviderID] = s
+		}
+		s.TotalRequests += row.TotalRequests
+		s.SuccessfulRequests += row.SuccessfulRequests
+		s.FailedRequests += row.FailedRequests
+		s.TotalInputTokens += row.InputTokens
+		s.TotalOutputTokens += row.OutputTokens
+		s.TotalCacheRead += row.CacheRead
+		s.TotalCacheWrite += row.CacheWrite
+		s.TotalCost += row.Cost
+		durations[row.ProviderID] += row.TotalDurationMs
+	}
+	for _, s := range stats {
+		if s.TotalRequests > 0 {
+			s.SuccessRate = float64(s.SuccessfulRequests) / float64(s.TotalRequests) * 100
+			s.AvgDurationMs = durations[s.ProviderID] / s.TotalRequests
+		}
+	}
+	return stats, nil
+}
+
+func (r *UsageStatsRepository) AggregateMinute() (int, error) {
+	return 0, nil
+}
+
+func (r *UsageStatsRepository) RollUp(from, to domain.Granularity) (int, error) {
+	return 0, nil
+}
+
+func (r *UsageStatsRepository) ClearAndRecalculate() error {
+	r.rows.remove(nil)
+	return nil
+}
+
+func sameBucket(a, b *domain.UsageStats) bool {
+	return a.Granularity == b.Granularity &&
+		a.TimeBucket.Equal(b.TimeBucket) &&
+		a.RouteID == b.RouteID &&
+		a.ProviderID == b.ProviderID &&
+		a.ProjectID == b.ProjectID &&
+		a.APITokenID == b.APITokenID &&
+		a.ClientType == b.ClientType &&
+		a.Model == b.Model
+}
+
+func matchFilter(s *domain.UsageStats, f repository.UsageStatsFilter) bool {
+	return s.Granularity == f.Granularity &&
+		(f.StartTime == nil || !s.TimeBucket.Before(*f.StartTime)) &&
+		(f.EndTime == nil || !s.TimeBucket.After(*f.EndTime)) &&
+		(f.RouteID == nil || s.RouteID == *f.RouteID) &&
+		(f.ProviderID == nil || s.ProviderID == *f.ProviderID) &&
+		(f.ProjectID == nil || s.ProjectID == *f.ProjectID) &&
+		(f.APITokenID == nil || s.APITokenID == *f.APITokenID) &&
+		(f.ClientType == nil || s.ClientType == *f.ClientType) &&
+		(f.Model == nil || s.Model == *f.Model)
+}
+
+func summarize[K comparable](rows []*domain.UsageStats, key func(*domain.UsageStats) K) map[K]*domain.UsageStatsSummary {
+	result := make(map[K]*domain.UsageStatsSummary)
+	for _, row := range rows {
+		k := key(row)
+		s, ok := result[k]
+		if !ok {
+			s = &domain.UsageStatsSummary{}
+			result[k] = s
+		}
+		addToSummary(s, row)
+	}
+	for _, s := range result {
+		finishSummary(s)
+	}
+	return result
+}
+
+func addToSummary(s *domain.UsageStatsSummary, row *domain.UsageStats) {
+	s.TotalRequests += row.TotalRequests
+	s.SuccessfulRequests += row.SuccessfulRequests
+	s.FailedRequests += row.FailedRequests
+	s.TotalInputTokens += row.InputTokens
+	s.TotalOutputTokens += row.OutputTokens
+	s.TotalCacheRead += row.CacheRead
+	s.TotalCacheWrite += row.CacheWrite
+	s.TotalCost += row.Cost
+}
+
+func finishSummary(s *domain.UsageStatsSummary) {
+	if s.TotalRequests > 0 {
+		s.SuccessRate = float64(s.SuccessfulRequests) / float64(s.TotalRequests) * 100
+	}
+}
@@ -40,6 +40,8 @@ func (r *APITokenRepository) Update(t *domain.APIToken) error {
 			"project_id":  t.ProjectID,
 			"is_enabled":  boolToInt(t.IsEnabled),
 			"expires_at":  toTimestampPtr(t.ExpiresAt),
+
+			"allowed_client_types": toJSON(t.AllowedClientTypes),
 		}).Error
 }
 
@@ -118,6 +120,8 @@ func (r *APITokenRepository) toModel(t *domain.APIToken) *APIToken {
 		ExpiresAt:   toTimestampPtr(t.ExpiresAt),
 		LastUsedAt:  toTimestampPtr(t.LastUsedAt),
 		UseCount:    t.UseCount,
+
+		AllowedClientTypes: toJSON(t.AllowedClientTypes),
 	}
 }
 
@@ -136,6 +140,8 @@ func (r *APITokenRepository) toDomain(m *APIToken) *domain.APIToken {
 		ExpiresAt:   fromTimestampPtr(m.ExpiresAt),
 		LastUsedAt:  fromTimestampPtr(m.LastUsedAt),
 		UseCount:    m.UseCount,
+
+		AllowedClientTypes: fromJSON[[]domain.ClientType](m.AllowedClientTypes),
 	}
 }
 
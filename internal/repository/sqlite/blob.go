@@ -0,0 +1,110 @@
+package sqlite
+
+import (
+	"log"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/blobstore"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// BlobThreshold is the body size from which request and response bodies are moved to
+// the blob store instead of being stored inline
+const BlobThreshold = 16 * 1024
+
+// SetBlobStore enables storing large request/response bodies in store. Rows written
+// before keep their inline bodies; both kinds are loaded transparently.
+func (d *DB) SetBlobStore(store blobstore.Store) {
+	d.blobs = store
+}
+
+// putBody moves a large body to the blob store and returns the body and reference to
+// persist. If the store is disabled or fails the body stays inline.
+func (d *DB) putBody(body string) (string, string) {
+	if d.blobs == nil || len(body) < BlobThreshold {
+		return body, ""
+	}
+	ref, err := d.blobs.Put([]byte(body))
+	if err != nil {
+		log.Printf("[DB] Failed to store body in blob store, keeping it inline: %v", err)
+		return body, ""
+	}
+	return "", ref
+}
+
+// getBody loads a body from the blob store. On failure the reference is kept so that
+// callers can tell the body was stored but is no longer available.
+func (d *DB) getBody(ref string) (string, bool) {
+	if d.blobs == nil {
+		return "", false
+	}
+	data, err := d.blobs.Get(ref)
+	if err != nil {
+		log.Printf("[DB] Failed to load body %s: %v", ref, err)
+		return "", false
+	}
+	return string(data), true
+}
+
+func (d *DB) externalizeRequestInfo(info *domain.RequestInfo) *domain.RequestInfo {
+	if info == nil || info.BodyRef != "" {
+		return info
+	}
+	body, ref := d.putBody(info.Body)
+	if ref == "" {
+		return info
+	}
+	out := *info
+	out.Body, out.BodyRef = body, ref
+	return &out
+}
+
+func (d *DB) externalizeResponseInfo(info *domain.ResponseInfo) *domain.ResponseInfo {
+	if info == nil || info.BodyRef != "" {
+		return info
+	}
+	body, ref := d.putBody(info.Body)
+	if ref == "" {
+		return info
+	}
+	out := *info
+	out.Body, out.BodyRef = body, ref
+	return &out
+}
+
+func (d *DB) loadRequestInfo(s string) *domain.RequestInfo {
+	info := fromJSON[*domain.RequestInfo](s)
+	if info != nil && info.BodyRef != "" {
+		if body, ok := d.getBody(info.BodyRef); ok {
+			info.Body, info.BodyRef = body, ""
+		}
+	}
+	return info
+}
+
+func (d *DB) loadResponseInfo(s string) *domain.ResponseInfo {
+	info := fromJSON[*domain.ResponseInfo](s)
+	if info != nil && info.BodyRef != "" {
+		if body, ok := d.getBody(info.BodyRef); ok {
+			info.Body, info.BodyRef = body, ""
+		}
+	}
+	return info
+}
+
+// sweepBlobs removes blobs last written before the cutoff. Writing a body refreshes
+// its blob, so a blob older than the cutoff is only referenced by rows created before
+// it, which the retention cleanup has just deleted.
+func (d *DB) sweepBlobs(before time.Time) {
+	if d.blobs == nil {
+		return
+	}
+	n, err := d.blobs.Sweep(before)
+	if err != nil {
+		log.Printf("[DB] Failed to sweep blob store: %v", err)
+		return
+	}
+	if n > 0 {
+		log.Printf("[DB] Removed %d expired blobs", n)
+	}
+}
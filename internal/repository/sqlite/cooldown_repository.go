@@ -92,6 +92,60 @@ func (r *CooldownRepository) DeleteExpired() error {
 	return r.db.gorm.Where("until_time <= ?", now).Delete(&Cooldown{}).Error
 }
 
+func (r *CooldownRepository) CreateEvent(event *domain.CooldownEvent) error {
+	now := time.Now()
+	model := &CooldownEvent{
+		BaseModel: BaseModel{
+			CreatedAt: toTimestamp(now),
+			UpdatedAt: toTimestamp(now),
+		},
+		ProviderID: event.ProviderID,
+		ClientType: event.ClientType,
+		Reason:     string(event.Reason),
+		StartTime:  toTimestamp(event.StartTime),
+		UntilTime:  toTimestamp(event.UntilTime),
+	}
+	if err := r.db.gorm.Create(model).Error; err != nil {
+		return err
+	}
+	event.ID = model.ID
+	event.CreatedAt = now
+	return nil
+}
+
+func (r *CooldownRepository) UpdateEventUntil(id uint64, until time.Time) error {
+	return r.db.gorm.Model(&CooldownEvent{}).Where("id = ?", id).Updates(map[string]any{
+		"until_time": toTimestamp(until),
+		"updated_at": toTimestamp(time.Now()),
+	}).Error
+}
+
+func (r *CooldownRepository) ListEvents(from, to time.Time) ([]*domain.CooldownEvent, error) {
+	var models []CooldownEvent
+	err := r.db.gorm.Where("start_time >= ? AND start_time < ?", toTimestamp(from), toTimestamp(to)).
+		Order("start_time ASC").Find(&models).Error
+	if err != nil {
+		return nil, err
+	}
+	events := make([]*domain.CooldownEvent, len(models))
+	for i, m := range models {
+		events[i] = &domain.CooldownEvent{
+			ID:         m.ID,
+			CreatedAt:  fromTimestamp(m.CreatedAt),
+			ProviderID: m.ProviderID,
+			ClientType: m.ClientType,
+			Reason:     domain.CooldownReason(m.Reason),
+			StartTime:  fromTimestamp(m.StartTime),
+			UntilTime:  fromTimestamp(m.UntilTime),
+		}
+	}
+	return events, nil
+}
+
+func (r *CooldownRepository) DeleteEventsBefore(before time.Time) error {
+	return r.db.gorm.Where("start_time < ?", toTimestamp(before)).Delete(&CooldownEvent{}).Error
+}
+
 func (r *CooldownRepository) toDomain(m *Cooldown) *domain.Cooldown {
 	return &domain.Cooldown{
 		ID:         m.ID,
@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"errors"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"gorm.io/gorm"
+)
+
+type CostAlertRepository struct {
+	db *DB
+}
+
+func NewCostAlertRepository(db *DB) *CostAlertRepository {
+	return &CostAlertRepository{db: db}
+}
+
+func (r *CostAlertRepository) Create(a *domain.CostAlert) error {
+	now := time.Now()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+
+	model := r.toModel(a)
+	if err := r.db.gorm.Create(model).Error; err != nil {
+		return err
+	}
+	a.ID = model.ID
+	return nil
+}
+
+func (r *CostAlertRepository) Update(a *domain.CostAlert) error {
+	a.UpdatedAt = time.Now()
+	model := r.toModel(a)
+	return r.db.gorm.Save(model).Error
+}
+
+func (r *CostAlertRepository) Delete(id uint64) error {
+	now := time.Now().UnixMilli()
+	return r.db.gorm.Model(&CostAlert{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"updated_at": now,
+		}).Error
+}
+
+func (r *CostAlertRepository) GetByID(id uint64) (*domain.CostAlert, error) {
+	var model CostAlert
+	if err := r.db.gorm.Where("deleted_at = 0").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return r.toDomain(&model), nil
+}
+
+func (r *CostAlertRepository) List() ([]*domain.CostAlert, error) {
+	var models []CostAlert
+	if err := r.db.gorm.Where("deleted_at = 0").Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	alerts := make([]*domain.CostAlert, len(models))
+	for i, m := range models {
+		alerts[i] = r.toDomain(&m)
+	}
+	return alerts, nil
+}
+
+func (r *CostAlertRepository) toModel(a *domain.CostAlert) *CostAlert {
+	return &CostAlert{
+		SoftDeleteModel: SoftDeleteModel{
+			BaseModel: BaseModel{
+				ID:        a.ID,
+				CreatedAt: toTimestamp(a.CreatedAt),
+				UpdatedAt: toTimestamp(a.UpdatedAt),
+			},
+			DeletedAt: toTimestampPtr(a.DeletedAt),
+		},
+		Name:                a.Name,
+		IsEnabled:           boolToInt(a.IsEnabled),
+		Scope:               string(a.Scope),
+		ScopeID:             a.ScopeID,
+		DailyThreshold:      a.DailyThreshold,
+		PercentAboveAverage: a.PercentAboveAverage,
+		DailyDigest:         boolToInt(a.DailyDigest),
+		LastTriggeredAt:     toTimestampPtr(a.LastTriggeredAt),
+		LastDigestAt:        toTimestampPtr(a.LastDigestAt),
+	}
+}
+
+func (r *CostAlertRepository) toDomain(m *CostAlert) *domain.CostAlert {
+	return &domain.CostAlert{
+		ID:                  m.ID,
+		CreatedAt:           fromTimestamp(m.CreatedAt),
+		UpdatedAt:           fromTimestamp(m.UpdatedAt),
+		DeletedAt:           fromTimestampPtr(m.DeletedAt),
+		Name:                m.Name,
+		IsEnabled:           m.IsEnabled == 1,
+		Scope:               domain.CostAlertScope(m.Scope),
+		ScopeID:             m.ScopeID,
+		DailyThreshold:      m.DailyThreshold,
+		PercentAboveAverage: m.PercentAboveAverage,
+		DailyDigest:         m.DailyDigest == 1,
+		LastTriggeredAt:     fromTimestampPtr(m.LastTriggeredAt),
+		LastDigestAt:        fromTimestampPtr(m.LastDigestAt),
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/awsl-project/maxx/internal/blobstore"
 	"gorm.io/driver/mysql"
 	"github.com/glebarez/sqlite"
 	"gorm.io/gorm"
@@ -14,8 +15,9 @@ import (
 )
 
 type DB struct {
-	gorm     *gorm.DB
-	dialector string // "sqlite" or "mysql"
+	gorm      *gorm.DB
+	dialector string          // "sqlite" or "mysql"
+	blobs     blobstore.Store // optional store for large request/response bodies
 }
 
 // GormDB returns the underlying GORM DB instance
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"errors"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"gorm.io/gorm"
+)
+
+type LatencySLORepository struct {
+	db *DB
+}
+
+func NewLatencySLORepository(db *DB) *LatencySLORepository {
+	return &LatencySLORepository{db: db}
+}
+
+func (r *LatencySLORepository) Create(s *domain.LatencySLO) error {
+	now := time.Now()
+	s.CreatedAt = now
+	s.UpdatedAt = now
+
+	model := r.toModel(s)
+	if err := r.db.gorm.Create(model).Error; err != nil {
+		return err
+	}
+	s.ID = model.ID
+	return nil
+}
+
+func (r *LatencySLORepository) Update(s *domain.LatencySLO) error {
+	s.UpdatedAt = time.Now()
+	model := r.toModel(s)
+	return r.db.gorm.Save(model).Error
+}
+
+func (r *LatencySLORepository) Delete(id uint64) error {
+	now := time.Now().UnixMilli()
+	return r.db.gorm.Model(&LatencySLO{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"updated_at": now,
+		}).Error
+}
+
+func (r *LatencySLORepository) GetByID(id uint64) (*domain.LatencySLO, error) {
+	var model LatencySLO
+	if err := r.db.gorm.Where("deleted_at = 0").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return r.toDomain(&model), nil
+}
+
+func (r *LatencySLORepository) List() ([]*domain.LatencySLO, error) {
+	var models []LatencySLO
+	if err := r.db.gorm.Where("deleted_at = 0").Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	slos := make([]*domain.LatencySLO, len(models))
+	for i, m := range models {
+		slos[i] = r.toDomain(&m)
+	}
+	return slos, nil
+}
+
+func (r *LatencySLORepository) toModel(s *domain.LatencySLO) *LatencySLO {
+	return &LatencySLO{
+		SoftDeleteModel: SoftDeleteModel{
+			BaseModel: BaseModel{
+				ID:        s.ID,
+				CreatedAt: toTimestamp(s.CreatedAt),
+				UpdatedAt: toTimestamp(s.UpdatedAt),
+			},
+			DeletedAt: toTimestampPtr(s.DeletedAt),
+		},
+		Name:            s.Name,
+		IsEnabled:       boolToInt(s.IsEnabled),
+		ProviderID:      s.ProviderID,
+		Model:           s.Model,
+		Metric:          string(s.Metric),
+		Percentile:      s.Percentile,
+		TargetMs:        int(s.Target.Milliseconds()),
+		WindowSecs:      int(s.Window.Seconds()),
+		MinSamples:      s.MinSamples,
+		PersistenceSecs: int(s.Persistence.Seconds()),
+	}
+}
+
+func (r *LatencySLORepository) toDomain(m *LatencySLO) *domain.LatencySLO {
+	return &domain.LatencySLO{
+		ID:          m.ID,
+		CreatedAt:   fromTimestamp(m.CreatedAt),
+		UpdatedAt:   fromTimestamp(m.UpdatedAt),
+		DeletedAt:   fromTimestampPtr(m.DeletedAt),
+		Name:        m.Name,
+		IsEnabled:   m.IsEnabled == 1,
+		ProviderID:  m.ProviderID,
+		Model:       m.Model,
+		Metric:      domain.LatencySLOMetric(m.Metric),
+		Percentile:  m.Percentile,
+		Target:      time.Duration(m.TargetMs) * time.Millisecond,
+		Window:      time.Duration(m.WindowSecs) * time.Second,
+		MinSamples:  m.MinSamples,
+		Persistence: time.Duration(m.PersistenceSecs) * time.Second,
+	}
+}
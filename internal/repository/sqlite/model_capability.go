@@ -0,0 +1,124 @@
+package sqlite
+
+import (
+	"errors"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"gorm.io/gorm"
+)
+
+type ModelCapabilityRepository struct {
+	db *DB
+}
+
+func NewModelCapabilityRepository(db *DB) *ModelCapabilityRepository {
+	return &ModelCapabilityRepository{db: db}
+}
+
+func (r *ModelCapabilityRepository) Create(capability *domain.ModelCapability) error {
+	now := time.Now()
+	capability.CreatedAt = now
+	capability.UpdatedAt = now
+
+	model := r.toModel(capability)
+	if err := r.db.gorm.Create(model).Error; err != nil {
+		return err
+	}
+	capability.ID = model.ID
+	return nil
+}
+
+func (r *ModelCapabilityRepository) Update(capability *domain.ModelCapability) error {
+	capability.UpdatedAt = time.Now()
+	return r.db.gorm.Save(r.toModel(capability)).Error
+}
+
+func (r *ModelCapabilityRepository) Delete(id uint64) error {
+	now := time.Now().UnixMilli()
+	return r.db.gorm.Model(&ModelCapability{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"updated_at": now,
+		}).Error
+}
+
+func (r *ModelCapabilityRepository) GetByID(id uint64) (*domain.ModelCapability, error) {
+	var model ModelCapability
+	if err := r.db.gorm.Where("id = ? AND deleted_at = 0", id).First(&model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return r.toDomain(&model), nil
+}
+
+func (r *ModelCapabilityRepository) List() ([]*domain.ModelCapability, error) {
+	var models []ModelCapability
+	if err := r.db.gorm.Where("deleted_at = 0").Order("CASE WHEN provider_id = 0 THEN 1 ELSE 0 END, priority, id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	capabilities := make([]*domain.ModelCapability, len(models))
+	for i := range models {
+		capabilities[i] = r.toDomain(&models[i])
+	}
+	return capabilities, nil
+}
+
+func (r *ModelCapabilityRepository) toModel(c *domain.ModelCapability) *ModelCapability {
+	return &ModelCapability{
+		SoftDeleteModel: SoftDeleteModel{
+			BaseModel: BaseModel{
+				ID:        c.ID,
+				CreatedAt: toTimestamp(c.CreatedAt),
+				UpdatedAt: toTimestamp(c.UpdatedAt),
+			},
+			DeletedAt: toTimestampPtr(c.DeletedAt),
+		},
+		ProviderID:        c.ProviderID,
+		Pattern:           c.Pattern,
+		SupportsTools:     toTriState(c.SupportsTools),
+		SupportsVision:    toTriState(c.SupportsVision),
+		SupportsThinking:  toTriState(c.SupportsThinking),
+		SupportsWebSearch: toTriState(c.SupportsWebSearch),
+		MaxContext:        c.MaxContext,
+		MaxOutput:         c.MaxOutput,
+		Priority:          c.Priority,
+	}
+}
+
+func (r *ModelCapabilityRepository) toDomain(m *ModelCapability) *domain.ModelCapability {
+	return &domain.ModelCapability{
+		ID:                m.ID,
+		CreatedAt:         fromTimestamp(m.CreatedAt),
+		UpdatedAt:         fromTimestamp(m.UpdatedAt),
+		DeletedAt:         fromTimestampPtr(m.DeletedAt),
+		ProviderID:        m.ProviderID,
+		Pattern:           m.Pattern,
+		SupportsTools:     fromTriState(m.SupportsTools),
+		SupportsVision:    fromTriState(m.SupportsVision),
+		SupportsThinking:  fromTriState(m.SupportsThinking),
+		SupportsWebSearch: fromTriState(m.SupportsWebSearch),
+		MaxContext:        m.MaxContext,
+		MaxOutput:         m.MaxOutput,
+		Priority:          m.Priority,
+	}
+}
+
+// toTriState stores an optional bool as -1 (unset), 0 or 1
+func toTriState(b *bool) int {
+	if b == nil {
+		return -1
+	}
+	return boolToInt(*b)
+}
+
+func fromTriState(v int) *bool {
+	if v < 0 {
+		return nil
+	}
+	b := v == 1
+	return &b
+}
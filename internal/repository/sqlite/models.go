@@ -119,6 +119,9 @@ type Provider struct {
 	Config               string `gorm:"type:longtext"`
 	SupportedClientTypes string `gorm:"type:text"`
 	SupportModels        string `gorm:"type:text"`
+	RetryConfigID        uint64 `gorm:"default:0"`
+	Disabled             int    `gorm:"default:0"`
+	Labels               string `gorm:"type:text"`
 }
 
 func (Provider) TableName() string { return "providers" }
@@ -126,9 +129,10 @@ func (Provider) TableName() string { return "providers" }
 // Project model
 type Project struct {
 	SoftDeleteModel
-	Name                string `gorm:"not null"`
-	Slug                string `gorm:"not null;default:''"`
-	EnabledCustomRoutes string `gorm:"type:text"`
+	Name                     string `gorm:"not null"`
+	Slug                     string `gorm:"not null;default:''"`
+	EnabledCustomRoutes      string `gorm:"type:text"`
+	LocalBackgroundResponder int    `gorm:"default:0"`
 }
 
 func (Project) TableName() string { return "projects" }
@@ -140,6 +144,12 @@ type Session struct {
 	ClientType string `gorm:"not null"`
 	ProjectID  uint64 `gorm:"default:0"`
 	RejectedAt int64  `gorm:"default:0"`
+
+	PinnedRouteID    uint64 `gorm:"default:0"`
+	PinnedProviderID uint64 `gorm:"default:0"`
+
+	LastActiveAt int64 `gorm:"default:0"`
+	ArchivedAt   int64 `gorm:"default:0"`
 }
 
 func (Session) TableName() string { return "sessions" }
@@ -147,13 +157,19 @@ func (Session) TableName() string { return "sessions" }
 // Route model
 type Route struct {
 	SoftDeleteModel
-	IsEnabled     int    `gorm:"default:1"`
-	IsNative      int    `gorm:"default:1"`
-	ProjectID     uint64 `gorm:"default:0"`
-	ClientType    string `gorm:"not null"`
-	ProviderID    uint64 `gorm:"not null"`
-	Position      int    `gorm:"default:0"`
-	RetryConfigID uint64 `gorm:"default:0"`
+	IsEnabled            int    `gorm:"default:1"`
+	IsNative             int    `gorm:"default:1"`
+	ProjectID            uint64 `gorm:"default:0"`
+	ClientType           string `gorm:"not null"`
+	ProviderID           uint64 `gorm:"not null"`
+	Position             int    `gorm:"default:0"`
+	RetryConfigID        uint64 `gorm:"default:0"`
+	SystemPrompt         string `gorm:"type:text"`
+	IdentityPatch        string `gorm:"type:text"`
+	Experiment           string `gorm:"type:text"`
+	ThoughtSignatureMode string `gorm:"type:varchar(32);default:''"`
+	PingIntervalSeconds  int    `gorm:"default:0"`
+	HideReasoning        int    `gorm:"default:0"`
 }
 
 func (Route) TableName() string { return "routes" }
@@ -181,6 +197,50 @@ type RoutingStrategy struct {
 
 func (RoutingStrategy) TableName() string { return "routing_strategies" }
 
+// RoutingProfile model
+type RoutingProfile struct {
+	SoftDeleteModel
+	Name        string `gorm:"not null"`
+	Description string `gorm:"type:text"`
+	Routes      string `gorm:"type:longtext"`
+	Schedules   string `gorm:"type:text"`
+}
+
+func (RoutingProfile) TableName() string { return "routing_profiles" }
+
+// CostAlert model
+type CostAlert struct {
+	SoftDeleteModel
+	Name                string  `gorm:"not null"`
+	IsEnabled           int     `gorm:"default:0"`
+	Scope               string  `gorm:"default:'global'"`
+	ScopeID             uint64  `gorm:"default:0"`
+	DailyThreshold      uint64  `gorm:"default:0"`
+	PercentAboveAverage float64 `gorm:"default:0"`
+	DailyDigest         int     `gorm:"default:0"`
+	LastTriggeredAt     int64   `gorm:"default:0"`
+	LastDigestAt        int64   `gorm:"default:0"`
+}
+
+func (CostAlert) TableName() string { return "cost_alerts" }
+
+// LatencySLO model
+type LatencySLO struct {
+	SoftDeleteModel
+	Name            string  `gorm:"not null"`
+	IsEnabled       int     `gorm:"default:0"`
+	ProviderID      uint64  `gorm:"default:0"`
+	Model           string  `gorm:"default:''"`
+	Metric          string  `gorm:"default:'ttfb'"`
+	Percentile      float64 `gorm:"default:95"`
+	TargetMs        int     `gorm:"default:0"`
+	WindowSecs      int     `gorm:"default:900"`
+	MinSamples      int     `gorm:"default:0"`
+	PersistenceSecs int     `gorm:"default:0"`
+}
+
+func (LatencySLO) TableName() string { return "latency_slos" }
+
 // APIToken model
 type APIToken struct {
 	SoftDeleteModel
@@ -193,6 +253,8 @@ type APIToken struct {
 	ExpiresAt   int64  `gorm:"default:0"`
 	LastUsedAt  int64  `gorm:"default:0"`
 	UseCount    uint64 `gorm:"default:0"`
+
+	AllowedClientTypes string `gorm:"type:text"`
 }
 
 func (APIToken) TableName() string { return "api_tokens" }
@@ -214,6 +276,24 @@ type ModelMapping struct {
 
 func (ModelMapping) TableName() string { return "model_mappings" }
 
+// ModelCapability model
+// Boolean capabilities are stored as -1 (unknown), 0 (no) or 1 (yes); no column default,
+// since GORM would substitute it for an explicit 0
+type ModelCapability struct {
+	SoftDeleteModel
+	ProviderID        uint64 `gorm:"default:0"`
+	Pattern           string `gorm:"not null"`
+	SupportsTools     int
+	SupportsVision    int
+	SupportsThinking  int
+	SupportsWebSearch int
+	MaxContext        int `gorm:"default:0"`
+	MaxOutput         int `gorm:"default:0"`
+	Priority          int `gorm:"default:0"`
+}
+
+func (ModelCapability) TableName() string { return "model_capabilities" }
+
 // AntigravityQuota model
 type AntigravityQuota struct {
 	SoftDeleteModel
@@ -230,6 +310,16 @@ func (AntigravityQuota) TableName() string { return "antigravity_quotas" }
 
 // ==================== Log/Status/Stats Models (no soft delete) ====================
 
+// OAuthAccessToken model
+type OAuthAccessToken struct {
+	BaseModel
+	TokenKey    string `gorm:"type:varchar(64);not null;uniqueIndex"`
+	AccessToken string `gorm:"type:text;not null"`
+	ExpiresAt   int64  `gorm:"not null;index"`
+}
+
+func (OAuthAccessToken) TableName() string { return "oauth_access_tokens" }
+
 // ProxyRequest model
 type ProxyRequest struct {
 	BaseModel
@@ -246,6 +336,7 @@ type ProxyRequest struct {
 	RequestInfo                 string `gorm:"type:longtext"`
 	ResponseInfo                string `gorm:"type:longtext"`
 	Error                       string `gorm:"type:longtext"`
+	ErrorCode                   string `gorm:"type:varchar(64);default:''"`
 	ProxyUpstreamAttemptCount   uint64 `gorm:"default:0"`
 	FinalProxyUpstreamAttemptID uint64 `gorm:"default:0"`
 	InputTokenCount             uint64 `gorm:"default:0"`
@@ -261,6 +352,19 @@ type ProxyRequest struct {
 	StatusCode                  int    `gorm:"default:0"`
 	ProjectID                   uint64 `gorm:"default:0"`
 	APITokenID                  uint64 `gorm:"default:0"`
+	PromptBytes                 uint64 `gorm:"default:0"`
+	MessageCount                int    `gorm:"default:0"`
+	ToolCount                   int    `gorm:"default:0"`
+	HasImage                    int    `gorm:"default:0"`
+	AgentLoop                   int    `gorm:"default:0"`
+	LocalResponse               int    `gorm:"default:0"`
+	Experiment                  string `gorm:"type:varchar(128);default:''"`
+	ExperimentArm               string `gorm:"type:varchar(8);default:''"`
+	PrefixHash                  string `gorm:"type:varchar(32);default:''"`
+	PrefixBytes                 uint64 `gorm:"default:0"`
+	CacheControl                int    `gorm:"default:0"`
+	CacheControlStripped        int    `gorm:"default:0"`
+	RouterDecision              string `gorm:"type:longtext"`
 }
 
 func (ProxyRequest) TableName() string { return "proxy_requests" }
@@ -269,9 +373,13 @@ func (ProxyRequest) TableName() string { return "proxy_requests" }
 type ProxyUpstreamAttempt struct {
 	BaseModel
 	Status            string `gorm:"type:text"`
+	ErrorCode         string `gorm:"type:varchar(64);default:''"`
 	ProxyRequestID    uint64 `gorm:"index"`
 	RequestInfo       string `gorm:"type:longtext"`
 	ResponseInfo      string `gorm:"type:longtext"`
+	Timings           string `gorm:"type:text"`
+	Decision          string `gorm:"type:text"`
+	BlockReason       string `gorm:"type:varchar(64);default:''"`
 	RouteID           uint64
 	ProviderID        uint64
 	InputTokenCount   uint64 `gorm:"default:0"`
@@ -313,6 +421,18 @@ type Cooldown struct {
 
 func (Cooldown) TableName() string { return "cooldowns" }
 
+// CooldownEvent model
+type CooldownEvent struct {
+	BaseModel
+	ProviderID uint64 `gorm:"not null;index"`
+	ClientType string `gorm:"type:varchar(255);not null;default:''"`
+	Reason     string `gorm:"type:varchar(64);not null;default:'unknown'"`
+	StartTime  int64  `gorm:"not null;index"`
+	UntilTime  int64  `gorm:"not null"`
+}
+
+func (CooldownEvent) TableName() string { return "cooldown_events" }
+
 // FailureCount model
 type FailureCount struct {
 	BaseModel
@@ -381,13 +501,19 @@ func AllModels() []any {
 		&Route{},
 		&RetryConfig{},
 		&RoutingStrategy{},
+		&RoutingProfile{},
+		&CostAlert{},
+		&LatencySLO{},
 		&APIToken{},
 		&ModelMapping{},
+		&ModelCapability{},
 		&AntigravityQuota{},
+		&OAuthAccessToken{},
 		&ProxyRequest{},
 		&ProxyUpstreamAttempt{},
 		&SystemSetting{},
 		&Cooldown{},
+		&CooldownEvent{},
 		&FailureCount{},
 		&UsageStats{},
 		&ResponseModel{},
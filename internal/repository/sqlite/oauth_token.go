@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OAuthTokenRepository struct {
+	db *DB
+}
+
+func NewOAuthTokenRepository(db *DB) *OAuthTokenRepository {
+	return &OAuthTokenRepository{db: db}
+}
+
+func (r *OAuthTokenRepository) Get(tokenKey string) (*domain.OAuthAccessToken, error) {
+	now := time.Now().UnixMilli()
+	var model OAuthAccessToken
+	err := r.db.gorm.Where("token_key = ? AND expires_at > ?", tokenKey, now).First(&model).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.toDomain(&model), nil
+}
+
+func (r *OAuthTokenRepository) Upsert(token *domain.OAuthAccessToken) error {
+	now := time.Now()
+	model := &OAuthAccessToken{
+		BaseModel: BaseModel{
+			CreatedAt: toTimestamp(now),
+			UpdatedAt: toTimestamp(now),
+		},
+		TokenKey:    token.TokenKey,
+		AccessToken: token.AccessToken,
+		ExpiresAt:   toTimestamp(token.ExpiresAt),
+	}
+
+	err := r.db.gorm.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "token_key"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"access_token": model.AccessToken,
+			"expires_at":   model.ExpiresAt,
+			"updated_at":   model.UpdatedAt,
+		}),
+	}).Create(model).Error
+	if err != nil {
+		return err
+	}
+
+	token.CreatedAt = now
+	token.UpdatedAt = now
+	return nil
+}
+
+func (r *OAuthTokenRepository) Delete(tokenKey string) error {
+	return r.db.gorm.Where("token_key = ?", tokenKey).Delete(&OAuthAccessToken{}).Error
+}
+
+func (r *OAuthTokenRepository) DeleteExpired() error {
+	now := time.Now().UnixMilli()
+	return r.db.gorm.Where("expires_at <= ?", now).Delete(&OAuthAccessToken{}).Error
+}
+
+func (r *OAuthTokenRepository) toDomain(m *OAuthAccessToken) *domain.OAuthAccessToken {
+	return &domain.OAuthAccessToken{
+		ID:          m.ID,
+		CreatedAt:   fromTimestamp(m.CreatedAt),
+		UpdatedAt:   fromTimestamp(m.UpdatedAt),
+		TokenKey:    m.TokenKey,
+		AccessToken: m.AccessToken,
+		ExpiresAt:   fromTimestamp(m.ExpiresAt),
+	}
+}
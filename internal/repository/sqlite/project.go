@@ -122,21 +122,23 @@ func (r *ProjectRepository) toModel(p *domain.Project) *Project {
 			},
 			DeletedAt: toTimestampPtr(p.DeletedAt),
 		},
-		Name:                p.Name,
-		Slug:                p.Slug,
-		EnabledCustomRoutes: toJSON(p.EnabledCustomRoutes),
+		Name:                     p.Name,
+		Slug:                     p.Slug,
+		EnabledCustomRoutes:      toJSON(p.EnabledCustomRoutes),
+		LocalBackgroundResponder: boolToInt(p.LocalBackgroundResponder),
 	}
 }
 
 func (r *ProjectRepository) toDomain(m *Project) *domain.Project {
 	return &domain.Project{
-		ID:                  m.ID,
-		CreatedAt:           fromTimestamp(m.CreatedAt),
-		UpdatedAt:           fromTimestamp(m.UpdatedAt),
-		DeletedAt:           fromTimestampPtr(m.DeletedAt),
-		Name:                m.Name,
-		Slug:                m.Slug,
-		EnabledCustomRoutes: fromJSON[[]domain.ClientType](m.EnabledCustomRoutes),
+		ID:                       m.ID,
+		CreatedAt:                fromTimestamp(m.CreatedAt),
+		UpdatedAt:                fromTimestamp(m.UpdatedAt),
+		DeletedAt:                fromTimestampPtr(m.DeletedAt),
+		Name:                     m.Name,
+		Slug:                     m.Slug,
+		EnabledCustomRoutes:      fromJSON[[]domain.ClientType](m.EnabledCustomRoutes),
+		LocalBackgroundResponder: m.LocalBackgroundResponder == 1,
 	}
 }
 
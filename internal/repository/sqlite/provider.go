@@ -69,6 +69,35 @@ func (r *ProviderRepository) List() ([]*domain.Provider, error) {
 	return providers, nil
 }
 
+func (r *ProviderRepository) ListDeleted() ([]*domain.Provider, error) {
+	var models []Provider
+	if err := r.db.gorm.Where("deleted_at > 0").Order("deleted_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	providers := make([]*domain.Provider, len(models))
+	for i, m := range models {
+		providers[i] = r.toDomain(&m)
+	}
+	return providers, nil
+}
+
+func (r *ProviderRepository) Restore(id uint64) error {
+	result := r.db.gorm.Model(&Provider{}).
+		Where("id = ? AND deleted_at > 0", id).
+		Updates(map[string]any{
+			"deleted_at": 0,
+			"updated_at": time.Now().UnixMilli(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 // toModel converts domain.Provider to sqlite.Provider
 func (r *ProviderRepository) toModel(p *domain.Provider) *Provider {
 	return &Provider{
@@ -85,6 +114,9 @@ func (r *ProviderRepository) toModel(p *domain.Provider) *Provider {
 		Config:               toJSON(p.Config),
 		SupportedClientTypes: toJSON(p.SupportedClientTypes),
 		SupportModels:        toJSON(p.SupportModels),
+		RetryConfigID:        p.RetryConfigID,
+		Disabled:             boolToInt(p.Disabled),
+		Labels:               toJSON(p.Labels),
 	}
 }
 
@@ -100,5 +132,8 @@ func (r *ProviderRepository) toDomain(m *Provider) *domain.Provider {
 		Config:               fromJSON[*domain.ProviderConfig](m.Config),
 		SupportedClientTypes: fromJSON[[]domain.ClientType](m.SupportedClientTypes),
 		SupportModels:        fromJSON[[]string](m.SupportModels),
+		RetryConfigID:        m.RetryConfigID,
+		Disabled:             m.Disabled == 1,
+		Labels:               fromJSON[[]string](m.Labels),
 	}
 }
@@ -2,10 +2,12 @@ package sqlite
 
 import (
 	"errors"
+	"strings"
 	"sync/atomic"
 	"time"
 
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
 	"gorm.io/gorm"
 )
 
@@ -78,7 +80,7 @@ func (r *ProxyRequestRepository) List(limit, offset int) ([]*domain.ProxyRequest
 func (r *ProxyRequestRepository) ListCursor(limit int, before, after uint64) ([]*domain.ProxyRequest, error) {
 	// 使用 Select 排除大字段
 	query := r.db.gorm.Model(&ProxyRequest{}).
-		Select("id, created_at, updated_at, instance_id, request_id, session_id, client_type, request_model, response_model, start_time, end_time, duration_ms, is_stream, status, status_code, error, proxy_upstream_attempt_count, final_proxy_upstream_attempt_id, route_id, provider_id, project_id, input_token_count, output_token_count, cache_read_count, cache_write_count, cache_5m_write_count, cache_1h_write_count, cost, api_token_id")
+		Select("id, created_at, updated_at, instance_id, request_id, session_id, client_type, request_model, response_model, start_time, end_time, duration_ms, is_stream, status, status_code, error, error_code, proxy_upstream_attempt_count, final_proxy_upstream_attempt_id, route_id, provider_id, project_id, input_token_count, output_token_count, cache_read_count, cache_write_count, cache_5m_write_count, cache_1h_write_count, cost, api_token_id, prompt_bytes, message_count, tool_count, has_image, agent_loop, local_response, experiment, experiment_arm, prefix_hash, prefix_bytes, cache_control, cache_control_stripped")
 
 	if after > 0 {
 		query = query.Where("id > ?", after)
@@ -97,16 +99,19 @@ func (r *ProxyRequestRepository) Count() (int64, error) {
 	return atomic.LoadInt64(&r.count), nil
 }
 
-// MarkStaleAsFailed marks all IN_PROGRESS/PENDING requests from other instances as FAILED
-// Also marks requests that have been IN_PROGRESS for too long (> 30 minutes) as timed out
-func (r *ProxyRequestRepository) MarkStaleAsFailed(currentInstanceID string) (int64, error) {
+// MarkStaleAsInterrupted marks all IN_PROGRESS/PENDING requests from other instances as INTERRUPTED
+// Also marks requests that have been IN_PROGRESS for too long (> 30 minutes) as timed out (FAILED)
+func (r *ProxyRequestRepository) MarkStaleAsInterrupted(currentInstanceID string) (int64, error) {
 	timeoutThreshold := time.Now().Add(-30 * time.Minute).UnixMilli()
 	now := time.Now().UnixMilli()
 
 	// Use raw SQL for complex CASE expression
 	result := r.db.gorm.Exec(`
 		UPDATE proxy_requests
-		SET status = 'FAILED',
+		SET status = CASE
+		        WHEN instance_id IS NULL OR instance_id != ? THEN 'INTERRUPTED'
+		        ELSE 'FAILED'
+		    END,
 		    error = CASE
 		        WHEN instance_id IS NULL OR instance_id != ? THEN 'Server restarted'
 		        ELSE 'Request timed out (stuck in progress)'
@@ -117,7 +122,7 @@ func (r *ProxyRequestRepository) MarkStaleAsFailed(currentInstanceID string) (in
 		      (instance_id IS NULL OR instance_id != ?)
 		      OR (start_time < ? AND start_time > 0)
 		  )`,
-		currentInstanceID, now, currentInstanceID, timeoutThreshold,
+		currentInstanceID, currentInstanceID, now, currentInstanceID, timeoutThreshold,
 	)
 	if result.Error != nil {
 		return 0, result.Error
@@ -151,6 +156,7 @@ func (r *ProxyRequestRepository) DeleteOlderThan(before time.Time) (int64, error
 	}
 
 	if len(requestIDs) == 0 {
+		r.db.sweepBlobs(before)
 		return 0, nil
 	}
 
@@ -171,9 +177,218 @@ func (r *ProxyRequestRepository) DeleteOlderThan(before time.Time) (int64, error
 		atomic.AddInt64(&r.count, -affected)
 	}
 
+	// 清理不再被保留记录引用的 blob
+	r.db.sweepBlobs(before)
+
 	return affected, nil
 }
 
+// GetShapeStats 按请求模型和供应商汇总请求形态
+// 只统计记录了请求形态的请求（prompt_bytes > 0），按成本降序
+func (r *ProxyRequestRepository) GetShapeStats(filter repository.RequestShapeFilter) ([]*domain.RequestShapeStats, error) {
+	conditions := []string{"prompt_bytes > 0"}
+	var args []any
+
+	if filter.StartTime != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, toTimestamp(*filter.StartTime))
+	}
+	if filter.EndTime != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, toTimestamp(*filter.EndTime))
+	}
+	if filter.ProviderID != nil {
+		conditions = append(conditions, "provider_id = ?")
+		args = append(args, *filter.ProviderID)
+	}
+	if filter.ProjectID != nil {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, *filter.ProjectID)
+	}
+	if filter.ClientType != nil {
+		conditions = append(conditions, "client_type = ?")
+		args = append(args, *filter.ClientType)
+	}
+
+	query := `
+		SELECT
+			request_model,
+			provider_id,
+			COUNT(*),
+			COALESCE(SUM(prompt_bytes), 0),
+			COALESCE(MAX(prompt_bytes), 0),
+			COALESCE(SUM(message_count), 0),
+			COALESCE(MAX(message_count), 0),
+			COALESCE(SUM(tool_count), 0),
+			COALESCE(SUM(CASE WHEN tool_count > 0 THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(has_image), 0),
+			COALESCE(SUM(input_token_count), 0),
+			COALESCE(SUM(cost), 0)
+		FROM proxy_requests
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY request_model, provider_id
+		ORDER BY SUM(cost) DESC
+	`
+
+	rows, err := r.db.gorm.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*domain.RequestShapeStats
+	for rows.Next() {
+		var s domain.RequestShapeStats
+		if err := rows.Scan(
+			&s.Model,
+			&s.ProviderID,
+			&s.TotalRequests,
+			&s.TotalPromptBytes,
+			&s.MaxPromptBytes,
+			&s.TotalMessages,
+			&s.MaxMessages,
+			&s.TotalTools,
+			&s.ToolRequests,
+			&s.ImageRequests,
+			&s.InputTokens,
+			&s.TotalCost,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, &s)
+	}
+	return results, rows.Err()
+}
+
+// GetExperimentStats 按路由实验和分组汇总已结束的请求
+func (r *ProxyRequestRepository) GetExperimentStats(filter repository.ExperimentStatsFilter) ([]*domain.ExperimentArmStats, error) {
+	conditions := []string{"experiment != ''", "status IN ('COMPLETED', 'FAILED')"}
+	var args []any
+
+	if filter.StartTime != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, toTimestamp(*filter.StartTime))
+	}
+	if filter.EndTime != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, toTimestamp(*filter.EndTime))
+	}
+	if filter.Experiment != nil {
+		conditions = append(conditions, "experiment = ?")
+		args = append(args, *filter.Experiment)
+	}
+
+	query := `
+		SELECT
+			experiment,
+			experiment_arm,
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN status = 'COMPLETED' THEN 1 ELSE 0 END), 0),
+			COALESCE(SUM(CASE WHEN status = 'COMPLETED' THEN duration_ms ELSE 0 END), 0),
+			COALESCE(SUM(input_token_count), 0),
+			COALESCE(SUM(output_token_count), 0),
+			COALESCE(SUM(cost), 0)
+		FROM proxy_requests
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY experiment, experiment_arm
+		ORDER BY experiment, experiment_arm
+	`
+
+	rows, err := r.db.gorm.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*domain.ExperimentArmStats
+	for rows.Next() {
+		var s domain.ExperimentArmStats
+		var durationMs uint64
+		if err := rows.Scan(
+			&s.Experiment,
+			&s.Arm,
+			&s.TotalRequests,
+			&s.SuccessfulRequests,
+			&durationMs,
+			&s.InputTokens,
+			&s.OutputTokens,
+			&s.TotalCost,
+		); err != nil {
+			return nil, err
+		}
+		s.FailedRequests = s.TotalRequests - s.SuccessfulRequests
+		s.TotalDuration = uint64(time.Duration(durationMs) * time.Millisecond)
+		results = append(results, &s)
+	}
+	return results, rows.Err()
+}
+
+// GetPromptPrefixStats 按路由、请求模型和可缓存前缀汇总已完成的 Claude 请求
+func (r *ProxyRequestRepository) GetPromptPrefixStats(filter repository.PromptPrefixFilter) ([]*domain.PromptPrefixStats, error) {
+	conditions := []string{"prefix_hash != ''", "status = 'COMPLETED'"}
+	var args []any
+
+	if filter.StartTime != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, toTimestamp(*filter.StartTime))
+	}
+	if filter.EndTime != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, toTimestamp(*filter.EndTime))
+	}
+	if filter.RouteID != nil {
+		conditions = append(conditions, "route_id = ?")
+		args = append(args, *filter.RouteID)
+	}
+
+	query := `
+		SELECT
+			route_id,
+			MAX(provider_id),
+			request_model,
+			prefix_hash,
+			MAX(prefix_bytes),
+			COUNT(*),
+			COALESCE(SUM(cache_control), 0),
+			COALESCE(SUM(cache_control_stripped), 0),
+			COALESCE(SUM(input_token_count), 0),
+			COALESCE(SUM(cache_read_count), 0),
+			COALESCE(SUM(cache_write_count), 0)
+		FROM proxy_requests
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		GROUP BY route_id, request_model, prefix_hash
+		ORDER BY route_id, COUNT(*) DESC
+	`
+
+	rows, err := r.db.gorm.Raw(query, args...).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []*domain.PromptPrefixStats
+	for rows.Next() {
+		var s domain.PromptPrefixStats
+		if err := rows.Scan(
+			&s.RouteID,
+			&s.ProviderID,
+			&s.Model,
+			&s.PrefixHash,
+			&s.PrefixBytes,
+			&s.Requests,
+			&s.CacheControlRequests,
+			&s.StrippedRequests,
+			&s.InputTokens,
+			&s.CacheReadTokens,
+			&s.CacheWriteTokens,
+		); err != nil {
+			return nil, err
+		}
+		results = append(results, &s)
+	}
+	return results, rows.Err()
+}
+
 func (r *ProxyRequestRepository) toModel(p *domain.ProxyRequest) *ProxyRequest {
 	return &ProxyRequest{
 		BaseModel: BaseModel{
@@ -193,9 +408,10 @@ func (r *ProxyRequestRepository) toModel(p *domain.ProxyRequest) *ProxyRequest {
 		IsStream:                   boolToInt(p.IsStream),
 		Status:                     p.Status,
 		StatusCode:                 p.StatusCode,
-		RequestInfo:                toJSON(p.RequestInfo),
-		ResponseInfo:               toJSON(p.ResponseInfo),
+		RequestInfo:                toJSON(r.db.externalizeRequestInfo(p.RequestInfo)),
+		ResponseInfo:               toJSON(r.db.externalizeResponseInfo(p.ResponseInfo)),
 		Error:                      p.Error,
+		ErrorCode:                  string(p.ErrorCode),
 		ProxyUpstreamAttemptCount:  p.ProxyUpstreamAttemptCount,
 		FinalProxyUpstreamAttemptID: p.FinalProxyUpstreamAttemptID,
 		RouteID:                    p.RouteID,
@@ -209,7 +425,27 @@ func (r *ProxyRequestRepository) toModel(p *domain.ProxyRequest) *ProxyRequest {
 		Cache1hWriteCount:          p.Cache1hWriteCount,
 		Cost:                       p.Cost,
 		APITokenID:                 p.APITokenID,
+		PromptBytes:                p.PromptBytes,
+		MessageCount:               p.MessageCount,
+		ToolCount:                  p.ToolCount,
+		HasImage:                   boolToInt(p.HasImage),
+		AgentLoop:                  boolToInt(p.AgentLoop),
+		LocalResponse:              boolToInt(p.LocalResponse),
+		Experiment:                 p.Experiment,
+		ExperimentArm:              p.ExperimentArm,
+		PrefixHash:                 p.PrefixHash,
+		PrefixBytes:                p.PrefixBytes,
+		CacheControl:               boolToInt(p.CacheControl),
+		CacheControlStripped:       boolToInt(p.CacheControlStripped),
+		RouterDecision:             routerDecisionToJSON(p.RouterDecision),
+	}
+}
+
+func routerDecisionToJSON(d *domain.RouterDecision) string {
+	if d == nil {
+		return ""
 	}
+	return toJSON(d)
 }
 
 func (r *ProxyRequestRepository) toDomain(m *ProxyRequest) *domain.ProxyRequest {
@@ -229,9 +465,10 @@ func (r *ProxyRequestRepository) toDomain(m *ProxyRequest) *domain.ProxyRequest
 		IsStream:                    m.IsStream == 1,
 		Status:                      m.Status,
 		StatusCode:                  m.StatusCode,
-		RequestInfo:                 fromJSON[*domain.RequestInfo](m.RequestInfo),
-		ResponseInfo:                fromJSON[*domain.ResponseInfo](m.ResponseInfo),
+		RequestInfo:                 r.db.loadRequestInfo(m.RequestInfo),
+		ResponseInfo:                r.db.loadResponseInfo(m.ResponseInfo),
 		Error:                       m.Error,
+		ErrorCode:                   domain.ErrorCode(m.ErrorCode),
 		ProxyUpstreamAttemptCount:   m.ProxyUpstreamAttemptCount,
 		FinalProxyUpstreamAttemptID: m.FinalProxyUpstreamAttemptID,
 		RouteID:                     m.RouteID,
@@ -245,6 +482,19 @@ func (r *ProxyRequestRepository) toDomain(m *ProxyRequest) *domain.ProxyRequest
 		Cache1hWriteCount:           m.Cache1hWriteCount,
 		Cost:                        m.Cost,
 		APITokenID:                  m.APITokenID,
+		PromptBytes:                 m.PromptBytes,
+		MessageCount:                m.MessageCount,
+		ToolCount:                   m.ToolCount,
+		HasImage:                    m.HasImage == 1,
+		AgentLoop:                   m.AgentLoop == 1,
+		LocalResponse:               m.LocalResponse == 1,
+		Experiment:                  m.Experiment,
+		ExperimentArm:               m.ExperimentArm,
+		PrefixHash:                  m.PrefixHash,
+		PrefixBytes:                 m.PrefixBytes,
+		CacheControl:                m.CacheControl == 1,
+		CacheControlStripped:        m.CacheControlStripped == 1,
+		RouterDecision:              fromJSON[*domain.RouterDecision](m.RouterDecision),
 	}
 }
 
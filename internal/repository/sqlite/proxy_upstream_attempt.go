@@ -41,6 +41,25 @@ func (r *ProxyUpstreamAttemptRepository) ListByProxyRequestID(proxyRequestID uin
 	return r.toDomainList(models), nil
 }
 
+// MarkStaleAsInterrupted marks IN_PROGRESS/PENDING attempts whose request belongs to another instance as INTERRUPTED
+func (r *ProxyUpstreamAttemptRepository) MarkStaleAsInterrupted(currentInstanceID string) (int64, error) {
+	result := r.db.gorm.Exec(`
+		UPDATE proxy_upstream_attempts
+		SET status = 'INTERRUPTED',
+		    updated_at = ?
+		WHERE status IN ('PENDING', 'IN_PROGRESS')
+		  AND proxy_request_id IN (
+		      SELECT id FROM proxy_requests
+		      WHERE instance_id IS NULL OR instance_id != ?
+		  )`,
+		time.Now().UnixMilli(), currentInstanceID,
+	)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
 func (r *ProxyUpstreamAttemptRepository) toModel(a *domain.ProxyUpstreamAttempt) *ProxyUpstreamAttempt {
 	return &ProxyUpstreamAttempt{
 		BaseModel: BaseModel{
@@ -52,13 +71,17 @@ func (r *ProxyUpstreamAttemptRepository) toModel(a *domain.ProxyUpstreamAttempt)
 		EndTime:           toTimestamp(a.EndTime),
 		DurationMs:        a.Duration.Milliseconds(),
 		Status:            a.Status,
+		ErrorCode:         string(a.ErrorCode),
 		ProxyRequestID:    a.ProxyRequestID,
 		IsStream:          boolToInt(a.IsStream),
 		RequestModel:      a.RequestModel,
 		MappedModel:       a.MappedModel,
 		ResponseModel:     a.ResponseModel,
-		RequestInfo:       toJSON(a.RequestInfo),
-		ResponseInfo:      toJSON(a.ResponseInfo),
+		RequestInfo:       toJSON(r.db.externalizeRequestInfo(a.RequestInfo)),
+		ResponseInfo:      toJSON(r.db.externalizeResponseInfo(a.ResponseInfo)),
+		Timings:           toJSON(a.Timings),
+		Decision:          toJSON(a.Decision),
+		BlockReason:       a.BlockReason,
 		RouteID:           a.RouteID,
 		ProviderID:        a.ProviderID,
 		InputTokenCount:   a.InputTokenCount,
@@ -80,13 +103,17 @@ func (r *ProxyUpstreamAttemptRepository) toDomain(m *ProxyUpstreamAttempt) *doma
 		EndTime:           fromTimestamp(m.EndTime),
 		Duration:          time.Duration(m.DurationMs) * time.Millisecond,
 		Status:            m.Status,
+		ErrorCode:         domain.ErrorCode(m.ErrorCode),
 		ProxyRequestID:    m.ProxyRequestID,
 		IsStream:          m.IsStream == 1,
 		RequestModel:      m.RequestModel,
 		MappedModel:       m.MappedModel,
 		ResponseModel:     m.ResponseModel,
-		RequestInfo:       fromJSON[*domain.RequestInfo](m.RequestInfo),
-		ResponseInfo:      fromJSON[*domain.ResponseInfo](m.ResponseInfo),
+		RequestInfo:       r.db.loadRequestInfo(m.RequestInfo),
+		ResponseInfo:      r.db.loadResponseInfo(m.ResponseInfo),
+		Timings:           fromJSON[*domain.UpstreamTimings](m.Timings),
+		Decision:          fromJSON[*domain.AttemptDecision](m.Decision),
+		BlockReason:       m.BlockReason,
 		RouteID:           m.RouteID,
 		ProviderID:        m.ProviderID,
 		InputTokenCount:   m.InputTokenCount,
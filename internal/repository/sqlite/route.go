@@ -26,6 +26,18 @@ func (r *RouteRepository) Create(route *domain.Route) error {
 		return err
 	}
 	route.ID = model.ID
+
+	// is_enabled/is_native 列默认为 1，GORM 插入时会忽略值为 0 的字段，需要显式写入
+	if model.IsEnabled == 0 || model.IsNative == 0 {
+		if err := r.db.gorm.Model(&Route{}).
+			Where("id = ?", model.ID).
+			Updates(map[string]any{
+				"is_enabled": model.IsEnabled,
+				"is_native":  model.IsNative,
+			}).Error; err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -101,6 +113,35 @@ func (r *RouteRepository) List() ([]*domain.Route, error) {
 	return routes, nil
 }
 
+func (r *RouteRepository) ListDeleted() ([]*domain.Route, error) {
+	var models []Route
+	if err := r.db.gorm.Where("deleted_at > 0").Order("deleted_at DESC").Find(&models).Error; err != nil {
+		return nil, err
+	}
+
+	routes := make([]*domain.Route, len(models))
+	for i, m := range models {
+		routes[i] = r.toDomain(&m)
+	}
+	return routes, nil
+}
+
+func (r *RouteRepository) Restore(id uint64) error {
+	result := r.db.gorm.Model(&Route{}).
+		Where("id = ? AND deleted_at > 0", id).
+		Updates(map[string]any{
+			"deleted_at": 0,
+			"updated_at": time.Now().UnixMilli(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
 func (r *RouteRepository) toModel(route *domain.Route) *Route {
 	isEnabled := 0
 	if route.IsEnabled {
@@ -119,28 +160,40 @@ func (r *RouteRepository) toModel(route *domain.Route) *Route {
 			},
 			DeletedAt: toTimestampPtr(route.DeletedAt),
 		},
-		IsEnabled:     isEnabled,
-		IsNative:      isNative,
-		ProjectID:     route.ProjectID,
-		ClientType:    string(route.ClientType),
-		ProviderID:    route.ProviderID,
-		Position:      route.Position,
-		RetryConfigID: route.RetryConfigID,
+		IsEnabled:            isEnabled,
+		IsNative:             isNative,
+		ProjectID:            route.ProjectID,
+		ClientType:           string(route.ClientType),
+		ProviderID:           route.ProviderID,
+		Position:             route.Position,
+		RetryConfigID:        route.RetryConfigID,
+		SystemPrompt:         toJSON(route.SystemPrompt),
+		IdentityPatch:        toJSON(route.IdentityPatch),
+		Experiment:           toJSON(route.Experiment),
+		ThoughtSignatureMode: string(route.ThoughtSignatureMode),
+		PingIntervalSeconds:  route.PingIntervalSeconds,
+		HideReasoning:        boolToInt(route.HideReasoning),
 	}
 }
 
 func (r *RouteRepository) toDomain(m *Route) *domain.Route {
 	return &domain.Route{
-		ID:            m.ID,
-		CreatedAt:     fromTimestamp(m.CreatedAt),
-		UpdatedAt:     fromTimestamp(m.UpdatedAt),
-		DeletedAt:     fromTimestampPtr(m.DeletedAt),
-		IsEnabled:     m.IsEnabled == 1,
-		IsNative:      m.IsNative == 1,
-		ProjectID:     m.ProjectID,
-		ClientType:    domain.ClientType(m.ClientType),
-		ProviderID:    m.ProviderID,
-		Position:      m.Position,
-		RetryConfigID: m.RetryConfigID,
+		ID:                   m.ID,
+		CreatedAt:            fromTimestamp(m.CreatedAt),
+		UpdatedAt:            fromTimestamp(m.UpdatedAt),
+		DeletedAt:            fromTimestampPtr(m.DeletedAt),
+		IsEnabled:            m.IsEnabled == 1,
+		IsNative:             m.IsNative == 1,
+		ProjectID:            m.ProjectID,
+		ClientType:           domain.ClientType(m.ClientType),
+		ProviderID:           m.ProviderID,
+		Position:             m.Position,
+		RetryConfigID:        m.RetryConfigID,
+		SystemPrompt:         fromJSON[*domain.RouteSystemPrompt](m.SystemPrompt),
+		IdentityPatch:        fromJSON[*domain.RouteIdentityPatch](m.IdentityPatch),
+		Experiment:           fromJSON[*domain.RouteExperiment](m.Experiment),
+		ThoughtSignatureMode: domain.ThoughtSignatureMode(m.ThoughtSignatureMode),
+		PingIntervalSeconds:  m.PingIntervalSeconds,
+		HideReasoning:        m.HideReasoning == 1,
 	}
 }
@@ -0,0 +1,99 @@
+package sqlite
+
+import (
+	"errors"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"gorm.io/gorm"
+)
+
+type RoutingProfileRepository struct {
+	db *DB
+}
+
+func NewRoutingProfileRepository(db *DB) *RoutingProfileRepository {
+	return &RoutingProfileRepository{db: db}
+}
+
+func (r *RoutingProfileRepository) Create(p *domain.RoutingProfile) error {
+	now := time.Now()
+	p.CreatedAt = now
+	p.UpdatedAt = now
+
+	model := r.toModel(p)
+	if err := r.db.gorm.Create(model).Error; err != nil {
+		return err
+	}
+	p.ID = model.ID
+	return nil
+}
+
+func (r *RoutingProfileRepository) Update(p *domain.RoutingProfile) error {
+	p.UpdatedAt = time.Now()
+	model := r.toModel(p)
+	return r.db.gorm.Save(model).Error
+}
+
+func (r *RoutingProfileRepository) Delete(id uint64) error {
+	now := time.Now().UnixMilli()
+	return r.db.gorm.Model(&RoutingProfile{}).
+		Where("id = ?", id).
+		Updates(map[string]any{
+			"deleted_at": now,
+			"updated_at": now,
+		}).Error
+}
+
+func (r *RoutingProfileRepository) GetByID(id uint64) (*domain.RoutingProfile, error) {
+	var model RoutingProfile
+	if err := r.db.gorm.Where("deleted_at = 0").First(&model, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return r.toDomain(&model), nil
+}
+
+func (r *RoutingProfileRepository) List() ([]*domain.RoutingProfile, error) {
+	var models []RoutingProfile
+	if err := r.db.gorm.Where("deleted_at = 0").Order("id").Find(&models).Error; err != nil {
+		return nil, err
+	}
+	profiles := make([]*domain.RoutingProfile, len(models))
+	for i, m := range models {
+		profiles[i] = r.toDomain(&m)
+	}
+	return profiles, nil
+}
+
+func (r *RoutingProfileRepository) toModel(p *domain.RoutingProfile) *RoutingProfile {
+	return &RoutingProfile{
+		SoftDeleteModel: SoftDeleteModel{
+			BaseModel: BaseModel{
+				ID:        p.ID,
+				CreatedAt: toTimestamp(p.CreatedAt),
+				UpdatedAt: toTimestamp(p.UpdatedAt),
+			},
+			DeletedAt: toTimestampPtr(p.DeletedAt),
+		},
+		Name:        p.Name,
+		Description: p.Description,
+		Routes:      toJSON(p.Routes),
+		Schedules:   toJSON(p.Schedules),
+	}
+}
+
+func (r *RoutingProfileRepository) toDomain(m *RoutingProfile) *domain.RoutingProfile {
+	return &domain.RoutingProfile{
+		ID:          m.ID,
+		CreatedAt:   fromTimestamp(m.CreatedAt),
+		UpdatedAt:   fromTimestamp(m.UpdatedAt),
+		DeletedAt:   fromTimestampPtr(m.DeletedAt),
+		Name:        m.Name,
+		Description: m.Description,
+		Routes:      fromJSON[[]domain.RoutingProfileRoute](m.Routes),
+		Schedules:   fromJSON[[]domain.RoutingProfileSchedule](m.Schedules),
+	}
+}
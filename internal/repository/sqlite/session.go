@@ -83,6 +83,12 @@ func (r *SessionRepository) toModel(s *domain.Session) *Session {
 		ClientType: string(s.ClientType),
 		ProjectID:  s.ProjectID,
 		RejectedAt: toTimestampPtr(s.RejectedAt),
+
+		PinnedRouteID:    s.PinnedRouteID,
+		PinnedProviderID: s.PinnedProviderID,
+
+		LastActiveAt: toTimestampPtr(s.LastActiveAt),
+		ArchivedAt:   toTimestampPtr(s.ArchivedAt),
 	}
 }
 
@@ -96,5 +102,11 @@ func (r *SessionRepository) toDomain(m *Session) *domain.Session {
 		ClientType: domain.ClientType(m.ClientType),
 		ProjectID:  m.ProjectID,
 		RejectedAt: fromTimestampPtr(m.RejectedAt),
+
+		PinnedRouteID:    m.PinnedRouteID,
+		PinnedProviderID: m.PinnedProviderID,
+
+		LastActiveAt: fromTimestampPtr(m.LastActiveAt),
+		ArchivedAt:   fromTimestampPtr(m.ArchivedAt),
 	}
 }
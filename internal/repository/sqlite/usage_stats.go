@@ -804,7 +804,8 @@ func (r *UsageStatsRepository) GetProviderStats(clientType string, projectID uin
 			COALESCE(SUM(output_tokens), 0),
 			COALESCE(SUM(cache_read), 0),
 			COALESCE(SUM(cache_write), 0),
-			COALESCE(SUM(cost), 0)
+			COALESCE(SUM(cost), 0),
+			COALESCE(SUM(total_duration_ms), 0)
 		FROM usage_stats
 		WHERE ` + strings.Join(conditions, " AND ") + `
 		GROUP BY provider_id
@@ -818,6 +819,7 @@ func (r *UsageStatsRepository) GetProviderStats(clientType string, projectID uin
 
 	for rows.Next() {
 		var s domain.ProviderStats
+		var totalDurationMs uint64
 		err := rows.Scan(
 			&s.ProviderID,
 			&s.TotalRequests,
@@ -828,12 +830,14 @@ func (r *UsageStatsRepository) GetProviderStats(clientType string, projectID uin
 			&s.TotalCacheRead,
 			&s.TotalCacheWrite,
 			&s.TotalCost,
+			&totalDurationMs,
 		)
 		if err != nil {
 			return nil, err
 		}
 		if s.TotalRequests > 0 {
 			s.SuccessRate = float64(s.SuccessfulRequests) / float64(s.TotalRequests) * 100
+			s.AvgDurationMs = totalDurationMs / s.TotalRequests
 		}
 		stats[s.ProviderID] = &s
 	}
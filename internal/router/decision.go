@@ -0,0 +1,120 @@
+package router
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/failback"
+)
+
+// Router decision recording. When MatchContext.Decision is set, Match records the path it
+// took, the strategy inputs, every candidate it considered and the final order into it.
+// All helpers are no-ops without a decision, so unsampled requests pay nothing.
+
+func (ctx *MatchContext) decide(mode string) {
+	if ctx.Decision != nil {
+		ctx.Decision.Mode = mode
+	}
+}
+
+func (ctx *MatchContext) note(format string, args ...any) {
+	if ctx.Decision != nil {
+		ctx.Decision.Notes = append(ctx.Decision.Notes, fmt.Sprintf(format, args...))
+	}
+}
+
+// candidate records a route buildMatched considers; nil without a decision
+func (ctx *MatchContext) candidate(route *domain.Route, providerID uint64, arm string) *domain.RouterDecisionCandidate {
+	if ctx.Decision == nil {
+		return nil
+	}
+	c := &domain.RouterDecisionCandidate{
+		RouteID:       route.ID,
+		ProviderID:    providerID,
+		Position:      route.Position,
+		ExperimentArm: arm,
+		Selected:      true,
+	}
+	ctx.Decision.Candidates = append(ctx.Decision.Candidates, c)
+	return c
+}
+
+func skipCandidate(c *domain.RouterDecisionCandidate, reason string) {
+	if c != nil {
+		c.Selected = false
+		c.SkipReason = reason
+	}
+}
+
+// recordStrategy records the strategy and what it based the order on. stats is nil if the
+// strategy did not read provider statistics.
+func recordStrategy(d *domain.RouterDecision, routes []*domain.Route, strategy *domain.RoutingStrategy, snap *Snapshot, stats map[uint64]*domain.ProviderStats) {
+	if d == nil {
+		return
+	}
+	d.Strategy = strategy.Type
+	d.StrategyConfig = strategy.Config
+	d.StrategyInputs = nil
+
+	weighted := strategy.Type == domain.RoutingStrategyWeightedRandom ||
+		(strategy.Config != nil && len(strategy.Config.Weights) > 0)
+	seen := make(map[uint64]bool, len(routes))
+	for _, route := range routes {
+		id := route.ProviderID
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		in := &domain.RouterStrategyInput{ProviderID: id, Demoted: failback.Default().IsDemoted(id)}
+		if weighted {
+			weight := 1
+			if strategy.Config != nil {
+				if w, ok := strategy.Config.Weights[id]; ok {
+					weight = w
+				}
+			}
+			in.Weight = &weight
+		}
+		if q := snap.Quota(id); q != nil {
+			left := remainingQuota(q)
+			in.QuotaRemaining = &left
+		}
+		if s := stats[id]; s != nil {
+			rate, active := s.SuccessRate, s.ActiveRequests
+			in.SuccessRate = &rate
+			in.ActiveRequests = &active
+		}
+		if strategy.Type == domain.RoutingStrategyCostAware {
+			if cost, ok := projectedCost(route, strategy.Config, snap); ok {
+				in.ProjectedCost = &cost
+			}
+			if s := stats[id]; s != nil && s.TotalRequests > 0 {
+				avg := s.AvgDurationMs
+				in.AvgDurationMs = &avg
+			}
+		}
+		d.StrategyInputs = append(d.StrategyInputs, in)
+	}
+}
+
+// recordOrder records the routes that will be tried, in order
+func recordOrder(d *domain.RouterDecision, matched []*MatchedRoute) {
+	if d == nil {
+		return
+	}
+	d.Order = make([]uint64, 0, len(matched))
+	for _, m := range matched {
+		d.Order = append(d.Order, m.Route.ID)
+	}
+}
+
+// cooldownUntil returns a pointer to the end of a provider's cooldown, or nil
+func (r *Router) cooldownUntil(providerID uint64, clientType domain.ClientType) *time.Time {
+	until := r.cooldownManager.GetCooldownUntil(providerID, string(clientType))
+	if until.IsZero() {
+		return nil
+	}
+	return &until
+}
@@ -0,0 +1,28 @@
+package router
+
+import (
+	"hash/fnv"
+	"math/rand"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// experimentArm assigns a request to an arm of a route experiment. Requests of a session
+// always land in the same arm, so a conversation (and its prompt cache) isn't split
+// across providers; requests without a session are assigned at random.
+func experimentArm(exp *domain.RouteExperiment, sessionID string) string {
+	var bucket int
+	if sessionID != "" {
+		h := fnv.New32a()
+		h.Write([]byte(exp.Name))
+		h.Write([]byte{0})
+		h.Write([]byte(sessionID))
+		bucket = int(h.Sum32() % 100)
+	} else {
+		bucket = rand.Intn(100)
+	}
+	if bucket < exp.SplitPercent {
+		return domain.ExperimentArmB
+	}
+	return domain.ExperimentArmA
+}
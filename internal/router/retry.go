@@ -0,0 +1,39 @@
+package router
+
+import (
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// RetryConfigLookup looks up retry configs
+type RetryConfigLookup interface {
+	GetByID(id uint64) (*domain.RetryConfig, error)
+	GetDefault() (*domain.RetryConfig, error)
+}
+
+// ResolveRetryConfig returns the retry config a route runs with: the route's own config,
+// else its provider's default, else the global default. References to configs that no
+// longer exist are skipped. prov may be nil.
+func ResolveRetryConfig(route *domain.Route, prov *domain.Provider, configs RetryConfigLookup) *domain.EffectiveRetryConfig {
+	eff := &domain.EffectiveRetryConfig{
+		RouteID:    route.ID,
+		ProviderID: route.ProviderID,
+		Source:     domain.RetryConfigSourceNone,
+	}
+	if def, err := configs.GetDefault(); err == nil && def != nil {
+		eff.DefaultRetryConfigID = def.ID
+		eff.Config, eff.Source = def, domain.RetryConfigSourceDefault
+	}
+	if prov != nil && prov.RetryConfigID != 0 {
+		if c, err := configs.GetByID(prov.RetryConfigID); err == nil && c != nil {
+			eff.ProviderRetryConfigID = c.ID
+			eff.Config, eff.Source = c, domain.RetryConfigSourceProvider
+		}
+	}
+	if route.RetryConfigID != 0 {
+		if c, err := configs.GetByID(route.RetryConfigID); err == nil && c != nil {
+			eff.RouteRetryConfigID = c.ID
+			eff.Config, eff.Source = c, domain.RetryConfigSourceRoute
+		}
+	}
+	return eff
+}
@@ -1,13 +1,19 @@
 package router
 
 import (
-	"math/rand"
+	"log"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/awsl-project/maxx/internal/adapter/provider"
 	"github.com/awsl-project/maxx/internal/cooldown"
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/failback"
+	"github.com/awsl-project/maxx/internal/quota"
+	"github.com/awsl-project/maxx/internal/repository"
 	"github.com/awsl-project/maxx/internal/repository/cached"
 )
 
@@ -17,6 +23,13 @@ type MatchedRoute struct {
 	Provider        *domain.Provider
 	ProviderAdapter provider.ProviderAdapter
 	RetryConfig     *domain.RetryConfig
+
+	// Arm of the route's A/B experiment the request was assigned to ("" if the route
+	// runs none). Provider is the arm's provider; ExperimentModel, if set, replaces
+	// model mapping.
+	Experiment      string
+	ExperimentArm   string
+	ExperimentModel string
 }
 
 // MatchContext contains all context needed for route matching
@@ -25,6 +38,31 @@ type MatchContext struct {
 	ProjectID    uint64
 	RequestModel string
 	APITokenID   uint64
+	SessionID    string // Keeps a session in one arm of route experiments
+
+	// PinnedProvider restricts matching to one provider (name or ID) and skips route ordering.
+	// Only routes the request could use anyway are eligible, so pinning never grants access.
+	PinnedProvider string
+
+	// Session pin set by an admin (route takes precedence over provider).
+	// Unlike PinnedProvider it may select any enabled route of the client type, and falls
+	// back to normal routing if the pinned upstream is gone or cooling down.
+	SessionPinnedRouteID    uint64
+	SessionPinnedProviderID uint64
+
+	// BackgroundProvider (name or ID) serves background requests such as Claude Code's
+	// small-fast-model calls, independent of the conversation's routes. Like a session pin
+	// it may use any enabled route of the client type and falls back to normal routing.
+	BackgroundProvider string
+
+	// Inputs of the cost_aware strategy, only called when it runs: the estimated prompt
+	// tokens, and the model a route would request upstream after model mapping (nil means
+	// the request model is sent as is)
+	EstimateInputTokens func() uint64
+	MapModel            func(route *domain.Route, provider *domain.Provider) string
+
+	// Decision, if set, receives how the routes were chosen (sampled router decision logging)
+	Decision *domain.RouterDecision
 }
 
 // Router handles route matching and selection
@@ -41,6 +79,11 @@ type Router struct {
 
 	// Cooldown manager
 	cooldownManager *cooldown.Manager
+
+	// Provider statistics for routing strategies
+	usageStatsRepo repository.UsageStatsRepository
+	statsCache     map[statsCacheKey]*statsCacheEntry
+	statsMu        sync.Mutex
 }
 
 // NewRouter creates a new router
@@ -50,6 +93,7 @@ func NewRouter(
 	routingStrategyRepo *cached.RoutingStrategyRepository,
 	retryConfigRepo *cached.RetryConfigRepository,
 	projectRepo *cached.ProjectRepository,
+	usageStatsRepo repository.UsageStatsRepository,
 ) *Router {
 	return &Router{
 		routeRepo:           routeRepo,
@@ -59,6 +103,8 @@ func NewRouter(
 		projectRepo:         projectRepo,
 		adapters:            make(map[uint64]provider.ProviderAdapter),
 		cooldownManager:     cooldown.Default(),
+		usageStatsRepo:      usageStatsRepo,
+		statsCache:          make(map[statsCacheKey]*statsCacheEntry),
 	}
 }
 
@@ -107,12 +153,97 @@ func (r *Router) RemoveAdapter(providerID uint64) {
 
 // Match returns matched routes for a client type and project
 func (r *Router) Match(ctx *MatchContext) ([]*MatchedRoute, error) {
+	matched, err := r.match(ctx)
+	recordOrder(ctx.Decision, matched)
+	return matched, err
+}
+
+func (r *Router) match(ctx *MatchContext) ([]*MatchedRoute, error) {
 	clientType := ctx.ClientType
 	projectID := ctx.ProjectID
-	requestModel := ctx.RequestModel
 
 	routes := r.routeRepo.GetAll()
+	filtered, projectRoutes := r.filterRoutes(routes, clientType, projectID)
+	if ctx.Decision != nil {
+		ctx.Decision.ProjectRoutes = projectRoutes
+	}
+
+	if len(filtered) == 0 && ctx.SessionPinnedRouteID == 0 && ctx.SessionPinnedProviderID == 0 {
+		if ctx.PinnedProvider != "" {
+			return nil, domain.ErrProviderNotAllowed
+		}
+		return nil, domain.ErrNoRoutes
+	}
+
+	if ctx.PinnedProvider != "" {
+		// Pinned provider: keep its routes in their configured position order
+		ctx.decide(domain.RouterDecisionPinned)
+		filtered = r.filterPinned(filtered, ctx.PinnedProvider)
+		if len(filtered) == 0 {
+			return nil, domain.ErrProviderNotAllowed
+		}
+		matched := r.buildMatched(filtered, ctx)
+		if len(matched) == 0 {
+			return nil, domain.ErrProviderNotAllowed
+		}
+		return matched, nil
+	}
+
+	if ctx.SessionPinnedRouteID != 0 || ctx.SessionPinnedProviderID != 0 {
+		ctx.decide(domain.RouterDecisionSessionPin)
+		pinned := r.filterSessionPin(routes, filtered, ctx)
+		if matched := r.buildMatched(pinned, ctx); len(matched) > 0 {
+			return matched, nil
+		}
+		ctx.note("session pin (route=%d, provider=%d) unavailable, using normal routing",
+			ctx.SessionPinnedRouteID, ctx.SessionPinnedProviderID)
+		log.Printf("[Router] Session pin (route=%d, provider=%d) unavailable, using normal routing",
+			ctx.SessionPinnedRouteID, ctx.SessionPinnedProviderID)
+	}
+
+	if ctx.BackgroundProvider != "" {
+		ctx.decide(domain.RouterDecisionBackground)
+		background := r.filterPinned(filtered, ctx.BackgroundProvider)
+		if len(background) == 0 {
+			background = r.filterPinned(enabledRoutes(routes, clientType), ctx.BackgroundProvider)
+		}
+		if matched := r.buildMatched(background, ctx); len(matched) > 0 {
+			return matched, nil
+		}
+		ctx.note("background provider %q unavailable, using normal routing", ctx.BackgroundProvider)
+		log.Printf("[Router] Background provider %q unavailable, using normal routing", ctx.BackgroundProvider)
+	}
+
+	// Order routes by the routing strategy
+	ctx.decide(domain.RouterDecisionStrategy)
+	strategy := r.getRoutingStrategy(projectID)
+	filtered = r.orderRoutes(filtered, strategy, ctx)
+
+	matched := r.buildMatched(filtered, ctx)
+	if len(matched) == 0 {
+		return nil, domain.ErrNoRoutes
+	}
+
+	return matched, nil
+}
+
+// MatchProvider returns the usable routes of one provider (name or ID) in position order,
+// preferring the request's own routes. Like a session pin it may use any enabled route of
+// the client type; unlike Match it never falls back to other providers.
+func (r *Router) MatchProvider(ctx *MatchContext, provider string) []*MatchedRoute {
+	routes := r.routeRepo.GetAll()
+	filtered, _ := r.filterRoutes(routes, ctx.ClientType, ctx.ProjectID)
+	selected := r.filterPinned(filtered, provider)
+	if len(selected) == 0 {
+		selected = r.filterPinned(enabledRoutes(routes, ctx.ClientType), provider)
+	}
+	return r.buildMatched(selected, ctx)
+}
 
+// filterRoutes returns the enabled routes of a client type a project uses: its own routes
+// if the client type has custom routes enabled for the project, otherwise the global routes.
+// The second result reports whether project routes were used.
+func (r *Router) filterRoutes(routes []*domain.Route, clientType domain.ClientType, projectID uint64) ([]*domain.Route, bool) {
 	// Check if ClientType has custom routes enabled for this project
 	useProjectRoutes := false
 	if projectID != 0 {
@@ -166,72 +297,178 @@ func (r *Router) Match(ctx *MatchContext) ([]*MatchedRoute, error) {
 		}
 	}
 
-	if len(filtered) == 0 {
-		return nil, domain.ErrNoRoutes
-	}
-
-	// Get routing strategy
-	strategy := r.getRoutingStrategy(projectID)
-
-	// Sort routes by strategy
-	r.sortRoutes(filtered, strategy)
-
-	// Get default retry config
-	defaultRetry, _ := r.retryConfigRepo.GetDefault()
+	return filtered, hasProjectRoutes
+}
 
-	// Build matched routes
+// buildMatched resolves providers, adapters and retry configs for routes in order,
+// skipping providers that are cooling down or don't support the request model.
+// Routes running an experiment use the provider of the request's arm.
+func (r *Router) buildMatched(routes []*domain.Route, ctx *MatchContext) []*MatchedRoute {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	var matched []*MatchedRoute
 	providers := r.providerRepo.GetAll()
+	if ctx.Decision != nil {
+		// Only the last set of routes considered is recorded
+		ctx.Decision.Candidates = nil
+	}
 
-	for _, route := range filtered {
-		prov, ok := providers[route.ProviderID]
-		if !ok {
+	for _, route := range routes {
+		target, skip := r.resolveRoute(route, ctx, providers)
+		c := ctx.candidate(route, target.providerID, target.arm)
+		if c != nil && target.provider != nil {
+			c.ProviderName = target.provider.Name
+		}
+		if skip != "" {
+			skipCandidate(c, skip)
+			if c != nil && skip == "cooldown" {
+				c.CooldownUntil = r.cooldownUntil(target.providerID, ctx.ClientType)
+			}
 			continue
 		}
 
-		// Skip providers in cooldown
-		if r.cooldownManager.IsInCooldown(route.ProviderID, string(clientType)) {
-			continue
+		var experiment string
+		if target.arm != "" {
+			experiment = route.Experiment.Name
 		}
+		matched = append(matched, &MatchedRoute{
+			Route:           route,
+			Provider:        target.provider,
+			ProviderAdapter: target.adapter,
+			RetryConfig:     ResolveRetryConfig(route, target.provider, r.retryConfigRepo).Config,
+			Experiment:      experiment,
+			ExperimentArm:   target.arm,
+			ExperimentModel: target.armTarget.Model,
+		})
+	}
 
-		adp, ok := r.adapters[route.ProviderID]
-		if !ok {
-			continue
+	return matched
+}
+
+// routeTarget is the provider a route sends a request to: the provider of the request's
+// experiment arm if the route runs an experiment, otherwise the route's own
+type routeTarget struct {
+	providerID uint64
+	provider   *domain.Provider // nil if not found
+	adapter    provider.ProviderAdapter
+	arm        string // "" if the route runs no experiment
+	armTarget  domain.ExperimentArm
+}
+
+// resolveRoute resolves the provider a route uses for ctx and returns why the route is
+// skipped, "" if it is usable: the provider is missing or disabled, cooling down, out of its
+// daily budget, has no adapter, or doesn't support the request model. Match and Simulate
+// share it so a simulation skips exactly what a request would. Callers hold r.mu.
+func (r *Router) resolveRoute(route *domain.Route, ctx *MatchContext, providers map[uint64]*domain.Provider) (*routeTarget, string) {
+	t := &routeTarget{providerID: route.ProviderID}
+	if route.Experiment.Active() {
+		t.arm = experimentArm(route.Experiment, ctx.SessionID)
+		t.armTarget = route.Experiment.Arm(t.arm)
+		if t.armTarget.ProviderID != 0 {
+			t.providerID = t.armTarget.ProviderID
 		}
+	}
 
-		// Check if provider supports the request model
-		// SupportModels check is done BEFORE mapping
-		// If SupportModels is configured, check if the request model is supported
-		if len(prov.SupportModels) > 0 && requestModel != "" {
-			if !r.isModelSupported(requestModel, prov.SupportModels) {
+	prov, ok := providers[t.providerID]
+	if !ok {
+		return t, "provider_not_found"
+	}
+	t.provider = prov
+	if prov.Disabled {
+		return t, "provider_disabled"
+	}
+	if r.cooldownManager.IsInCooldown(t.providerID, string(ctx.ClientType)) {
+		return t, "cooldown"
+	}
+	// Providers that used their local daily budget
+	if quota.Default().IsExhausted(prov) {
+		return t, "quota_exhausted"
+	}
+	adp, ok := r.adapters[t.providerID]
+	if !ok {
+		return t, "no_adapter"
+	}
+	t.adapter = adp
+
+	// SupportModels is checked against the request model, BEFORE mapping
+	if len(prov.SupportModels) > 0 && ctx.RequestModel != "" && !r.isModelSupported(ctx.RequestModel, prov.SupportModels) {
+		return t, "unsupported_model"
+	}
+	return t, ""
+}
+
+// filterSessionPin returns the routes selected by a session pin.
+// Routes of the request's own route set are preferred; otherwise any enabled route
+// of the same client type is used, since the pin was set by an admin.
+func (r *Router) filterSessionPin(all, filtered []*domain.Route, ctx *MatchContext) []*domain.Route {
+	pick := func(routes []*domain.Route) []*domain.Route {
+		var result []*domain.Route
+		for _, route := range routes {
+			if !route.IsEnabled || route.ClientType != ctx.ClientType {
 				continue
 			}
+			if ctx.SessionPinnedRouteID != 0 {
+				if route.ID == ctx.SessionPinnedRouteID {
+					result = append(result, route)
+				}
+			} else if route.ProviderID == ctx.SessionPinnedProviderID {
+				result = append(result, route)
+			}
 		}
+		sort.SliceStable(result, func(i, j int) bool { return result[i].Position < result[j].Position })
+		return result
+	}
 
-		var retryConfig *domain.RetryConfig
-		if route.RetryConfigID != 0 {
-			retryConfig, _ = r.retryConfigRepo.GetByID(route.RetryConfigID)
-		}
-		if retryConfig == nil {
-			retryConfig = defaultRetry
-		}
+	if result := pick(filtered); len(result) > 0 {
+		return result
+	}
+	return pick(all)
+}
 
-		matched = append(matched, &MatchedRoute{
-			Route:           route,
-			Provider:        prov,
-			ProviderAdapter: adp,
-			RetryConfig:     retryConfig,
-		})
+// enabledRoutes returns the enabled routes of a client type, of any project
+func enabledRoutes(routes []*domain.Route, clientType domain.ClientType) []*domain.Route {
+	var result []*domain.Route
+	for _, route := range routes {
+		if route.IsEnabled && route.ClientType == clientType {
+			result = append(result, route)
+		}
 	}
+	return result
+}
 
-	if len(matched) == 0 {
-		return nil, domain.ErrNoRoutes
+// filterPinned returns the routes whose provider matches pinned (name, case-insensitive, or ID)
+func (r *Router) filterPinned(routes []*domain.Route, pinned string) []*domain.Route {
+	providers := r.providerRepo.GetAll()
+	var result []*domain.Route
+	for _, route := range routes {
+		prov, ok := providers[route.ProviderID]
+		if !ok {
+			continue
+		}
+		if strings.EqualFold(prov.Name, pinned) || strconv.FormatUint(prov.ID, 10) == pinned {
+			result = append(result, route)
+		}
 	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Position < result[j].Position })
+	return result
+}
 
-	return matched, nil
+// SplitProviderSuffix splits a "model@provider-name" request model.
+// The suffix is only treated as a provider if a provider with that name exists,
+// so model names that contain "@" themselves (e.g. Vertex "claude-3-5-sonnet@20240620") are left alone.
+func (r *Router) SplitProviderSuffix(model string) (string, string, bool) {
+	idx := strings.LastIndex(model, "@")
+	if idx <= 0 || idx == len(model)-1 {
+		return model, "", false
+	}
+	name := model[idx+1:]
+	for _, p := range r.providerRepo.GetAll() {
+		if strings.EqualFold(p.Name, name) {
+			return model[:idx], name, true
+		}
+	}
+	return model, "", false
 }
 
 // isModelSupported checks if a model matches any pattern in the support list
@@ -259,18 +496,71 @@ func (r *Router) getRoutingStrategy(projectID uint64) *domain.RoutingStrategy {
 	return &domain.RoutingStrategy{Type: domain.RoutingStrategyPriority}
 }
 
-func (r *Router) sortRoutes(routes []*domain.Route, strategy *domain.RoutingStrategy) {
-	switch strategy.Type {
-	case domain.RoutingStrategyWeightedRandom:
-		// Shuffle with weights (simplified - just shuffle for now)
-		rand.Shuffle(len(routes), func(i, j int) {
-			routes[i], routes[j] = routes[j], routes[i]
-		})
-	default: // priority
-		sort.Slice(routes, func(i, j int) bool {
-			return routes[i].Position < routes[j].Position
-		})
+// orderRoutes orders routes with the strategy registered for the strategy type, falling
+// back to position order for unknown types
+func (r *Router) orderRoutes(routes []*domain.Route, strategy *domain.RoutingStrategy, ctx *MatchContext) []*domain.Route {
+	s, ok := GetStrategy(strategy.Type)
+	if !ok {
+		log.Printf("[Router] Unknown routing strategy %q, using priority", strategy.Type)
+		s = OrderedStrategy{}
+	}
+	var stats map[uint64]*domain.ProviderStats
+	snap := &Snapshot{
+		ClientType:   ctx.ClientType,
+		ProjectID:    ctx.ProjectID,
+		RequestModel: ctx.RequestModel,
+		Now:          time.Now(),
+		providers:    r.providerRepo.GetAll(),
+		mapModel:     ctx.MapModel,
+		inputTokens:  ctx.EstimateInputTokens,
+		cooldowns:    r.cooldownManager,
+		stats: func() map[uint64]*domain.ProviderStats {
+			stats = r.providerStats(ctx.ClientType, ctx.ProjectID)
+			return stats
+		},
+	}
+	// Providers that failed over stay behind their backups until they recover
+	ordered := failback.Default().Reorder(s.Order(routes, strategy.Config, snap))
+	recordStrategy(ctx.Decision, ordered, strategy, snap, stats)
+	return ordered
+}
+
+// providerStatsTTL bounds how stale the statistics given to strategies may be
+const providerStatsTTL = 30 * time.Second
+
+type statsCacheKey struct {
+	clientType domain.ClientType
+	projectID  uint64
+}
+
+type statsCacheEntry struct {
+	loadedAt time.Time
+	stats    map[uint64]*domain.ProviderStats
+}
+
+// providerStats returns the (briefly cached) provider statistics of a client type and project
+func (r *Router) providerStats(clientType domain.ClientType, projectID uint64) map[uint64]*domain.ProviderStats {
+	if r.usageStatsRepo == nil {
+		return nil
+	}
+	key := statsCacheKey{clientType: clientType, projectID: projectID}
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+	if e, ok := r.statsCache[key]; ok && time.Since(e.loadedAt) < providerStatsTTL {
+		return e.stats
 	}
+	stats, err := r.usageStatsRepo.GetProviderStats(string(clientType), projectID)
+	if err != nil {
+		log.Printf("[Router] Failed to load provider stats: %v", err)
+		return nil
+	}
+	r.statsCache[key] = &statsCacheEntry{loadedAt: time.Now(), stats: stats}
+	return stats
+}
+
+// GetProject returns a project from the router's project cache
+func (r *Router) GetProject(projectID uint64) (*domain.Project, error) {
+	return r.projectRepo.GetByID(projectID)
 }
 
 // GetCooldowns returns all active cooldowns
@@ -285,3 +575,9 @@ func (r *Router) ClearCooldown(providerID uint64) error {
 	return nil
 }
 
+// PauseProvider manually puts a provider into cooldown for the given duration
+// Applies to all client types; use ClearCooldown to resume early
+func (r *Router) PauseProvider(providerID uint64, duration time.Duration) error {
+	r.cooldownManager.SetManualCooldown(providerID, "", duration)
+	return nil
+}
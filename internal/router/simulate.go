@@ -0,0 +1,55 @@
+package router
+
+import "github.com/awsl-project/maxx/internal/domain"
+
+// Simulate is a dry run of Match: it returns every route the request would consider, in the
+// order they would be tried, and why skipped routes are skipped. Nothing is sent upstream and
+// no state changes. Provider pins are not simulated. Routes running an experiment use the
+// provider of the arm ctx.SessionID is assigned to (a random arm without a session).
+func (r *Router) Simulate(ctx *MatchContext) ([]*domain.RouteCandidate, domain.RoutingStrategyType, bool) {
+	routes, useProjectRoutes := r.filterRoutes(r.routeRepo.GetAll(), ctx.ClientType, ctx.ProjectID)
+
+	s := r.getRoutingStrategy(ctx.ProjectID)
+	routes = r.orderRoutes(routes, s, ctx)
+
+	var candidates []*domain.RouteCandidate
+	providers := r.providerRepo.GetAll()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, route := range routes {
+		target, skip := r.resolveRoute(route, ctx, providers)
+		c := &domain.RouteCandidate{
+			Route:           route,
+			ProviderID:      target.providerID,
+			ExperimentArm:   target.arm,
+			ExperimentModel: target.armTarget.Model,
+			Selected:        skip == "",
+			SkipReason:      skip,
+		}
+		candidates = append(candidates, c)
+
+		prov := target.provider
+		if prov == nil {
+			continue
+		}
+		c.ProviderName = prov.Name
+		c.ProviderType = prov.Type
+		if skip == "provider_disabled" {
+			continue
+		}
+
+		if skip == "cooldown" {
+			if info := r.cooldownManager.GetCooldownInfo(target.providerID, string(ctx.ClientType), prov.Name); info != nil {
+				until := info.Until
+				c.CooldownUntil = &until
+				c.CooldownReason = domain.CooldownReason(info.Reason)
+			}
+		}
+
+		retry := ResolveRetryConfig(route, prov, r.retryConfigRepo)
+		c.RetryConfig, c.RetryConfigSource = retry.Config, retry.Source
+	}
+	return candidates, s.Type, useProjectRoutes
+}
@@ -0,0 +1,265 @@
+package router
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/cooldown"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/pricing"
+	"github.com/awsl-project/maxx/internal/quota"
+	"github.com/awsl-project/maxx/internal/usage"
+)
+
+// Strategy orders the candidate routes of a request. It is selected by the Type of the
+// project's (or the global) RoutingStrategy. Routes are only ordered here: cooldowns,
+// exhausted quotas and unsupported models are filtered out afterwards, so a strategy
+// may consult the snapshot to rank routes but does not need to drop them.
+type Strategy interface {
+	Order(routes []*domain.Route, config *domain.RoutingStrategyConfig, snap *Snapshot) []*domain.Route
+}
+
+// StrategyFunc adapts a function to the Strategy interface
+type StrategyFunc func(routes []*domain.Route, config *domain.RoutingStrategyConfig, snap *Snapshot) []*domain.Route
+
+// Order calls f
+func (f StrategyFunc) Order(routes []*domain.Route, config *domain.RoutingStrategyConfig, snap *Snapshot) []*domain.Route {
+	return f(routes, config, snap)
+}
+
+var (
+	strategiesMu sync.RWMutex
+	strategies   = map[domain.RoutingStrategyType]Strategy{
+		domain.RoutingStrategyPriority:       OrderedStrategy{},
+		domain.RoutingStrategyWeightedRandom: WeightedStrategy{},
+		domain.RoutingStrategyQuotaAware:     QuotaAwareStrategy{},
+		domain.RoutingStrategyCostAware:      CostAwareStrategy{},
+	}
+)
+
+// RegisterStrategy registers (or replaces) the strategy used for a strategy type
+func RegisterStrategy(t domain.RoutingStrategyType, s Strategy) {
+	strategiesMu.Lock()
+	defer strategiesMu.Unlock()
+	strategies[t] = s
+}
+
+// GetStrategy returns the strategy registered for a type
+func GetStrategy(t domain.RoutingStrategyType) (Strategy, bool) {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	s, ok := strategies[t]
+	return s, ok
+}
+
+// StrategyTypes returns the registered strategy types
+func StrategyTypes() []domain.RoutingStrategyType {
+	strategiesMu.RLock()
+	defer strategiesMu.RUnlock()
+	types := make([]domain.RoutingStrategyType, 0, len(strategies))
+	for t := range strategies {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// Snapshot is the routing state a strategy may consult. Cooldowns and quotas are read
+// when asked for; provider statistics are loaded on first use and cached briefly.
+type Snapshot struct {
+	ClientType   domain.ClientType
+	ProjectID    uint64
+	RequestModel string
+	Now          time.Time
+
+	providers map[uint64]*domain.Provider
+	cooldowns *cooldown.Manager
+	stats     func() map[uint64]*domain.ProviderStats
+	mapModel  func(route *domain.Route, provider *domain.Provider) string
+
+	inputTokens    func() uint64
+	inputEstimated bool
+	inputEstimate  uint64
+}
+
+// Provider returns the provider of a route, or nil
+func (s *Snapshot) Provider(route *domain.Route) *domain.Provider {
+	return s.providers[route.ProviderID]
+}
+
+// CooldownUntil returns when the provider's cooldown for the request's client type ends
+// (zero if it is not cooling down)
+func (s *Snapshot) CooldownUntil(providerID uint64) time.Time {
+	until := s.cooldowns.GetCooldownUntil(providerID, string(s.ClientType))
+	if until.After(s.Now) {
+		return until
+	}
+	return time.Time{}
+}
+
+// MappedModel returns the model the route would request upstream
+func (s *Snapshot) MappedModel(route *domain.Route) string {
+	if s.mapModel != nil {
+		if provider := s.providers[route.ProviderID]; provider != nil {
+			return s.mapModel(route, provider)
+		}
+	}
+	return s.RequestModel
+}
+
+// InputTokens returns the estimated prompt tokens of the request (0 if unknown). The
+// estimate is made on first use.
+func (s *Snapshot) InputTokens() uint64 {
+	if !s.inputEstimated && s.inputTokens != nil {
+		s.inputEstimate = s.inputTokens()
+	}
+	s.inputEstimated = true
+	return s.inputEstimate
+}
+
+// Quota returns the provider's local quota usage, or nil if it has no budget
+func (s *Snapshot) Quota(providerID uint64) *domain.ProviderQuotaStatus {
+	return quota.Default().Status(s.providers[providerID])
+}
+
+// Stats returns the providers' request statistics for the request's client type and project
+func (s *Snapshot) Stats() map[uint64]*domain.ProviderStats {
+	if s.stats == nil {
+		return nil
+	}
+	return s.stats()
+}
+
+// OrderedStrategy tries routes by position
+type OrderedStrategy struct{}
+
+// Order sorts by position
+func (OrderedStrategy) Order(routes []*domain.Route, _ *domain.RoutingStrategyConfig, _ *Snapshot) []*domain.Route {
+	sort.SliceStable(routes, func(i, j int) bool {
+		return routes[i].Position < routes[j].Position
+	})
+	return routes
+}
+
+// WeightedStrategy orders routes randomly, a route's chance of going first being
+// proportional to its provider's weight (default 1, 0 puts it last)
+type WeightedStrategy struct{}
+
+// Order draws a weighted random permutation
+func (WeightedStrategy) Order(routes []*domain.Route, config *domain.RoutingStrategyConfig, _ *Snapshot) []*domain.Route {
+	keys := make(map[*domain.Route]float64, len(routes))
+	for _, route := range routes {
+		weight := 1
+		if config != nil {
+			if w, ok := config.Weights[route.ProviderID]; ok {
+				weight = w
+			}
+		}
+		// Weighted sampling without replacement: sort by u^(1/w) descending
+		key := -1.0
+		if weight > 0 {
+			key = math.Pow(rand.Float64(), 1/float64(weight))
+		}
+		keys[route] = key
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		return keys[routes[i]] > keys[routes[j]]
+	})
+	return routes
+}
+
+// QuotaAwareStrategy prefers the providers with the most local quota left, so budgets are
+// used up evenly. Providers without a budget count as having all of it left; ties keep
+// the position order.
+type QuotaAwareStrategy struct{}
+
+// Order sorts by remaining quota, then position
+func (QuotaAwareStrategy) Order(routes []*domain.Route, _ *domain.RoutingStrategyConfig, snap *Snapshot) []*domain.Route {
+	remaining := make(map[uint64]float64, len(routes))
+	for _, route := range routes {
+		if _, ok := remaining[route.ProviderID]; !ok {
+			remaining[route.ProviderID] = remainingQuota(snap.Quota(route.ProviderID))
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		ri, rj := remaining[routes[i].ProviderID], remaining[routes[j].ProviderID]
+		if ri != rj {
+			return ri > rj
+		}
+		return routes[i].Position < routes[j].Position
+	})
+	return routes
+}
+
+// remainingQuota is the fraction (0-1) of the tighter budget left
+func remainingQuota(s *domain.ProviderQuotaStatus) float64 {
+	if s == nil {
+		return 1
+	}
+	left := 1.0
+	if s.RequestsPerDay > 0 {
+		left = math.Min(left, 1-float64(s.Requests)/float64(s.RequestsPerDay))
+	}
+	if s.TokensPerDay > 0 {
+		left = math.Min(left, 1-float64(s.Tokens)/float64(s.TokensPerDay))
+	}
+	return math.Max(left, 0)
+}
+
+// defaultExpectedOutputTokens is the response size the cost_aware strategy assumes when the
+// config doesn't set one
+const defaultExpectedOutputTokens = 1000
+
+// CostAwareStrategy prefers the route whose mapped model is cheapest for the request, priced
+// for the estimated prompt and the config's expected output. With MaxLatencyMs set, providers
+// averaging slower go after those within it. Models without pricing go last; ties keep the
+// position order.
+type CostAwareStrategy struct{}
+
+// Order sorts by latency SLA, then projected cost, then position
+func (CostAwareStrategy) Order(routes []*domain.Route, config *domain.RoutingStrategyConfig, snap *Snapshot) []*domain.Route {
+	costs := make(map[*domain.Route]uint64, len(routes))
+	for _, route := range routes {
+		cost, ok := projectedCost(route, config, snap)
+		if !ok {
+			cost = math.MaxUint64
+		}
+		costs[route] = cost
+	}
+	slow := make(map[uint64]bool)
+	if config != nil && config.MaxLatencyMs > 0 {
+		for id, s := range snap.Stats() {
+			slow[id] = s.TotalRequests > 0 && s.AvgDurationMs > config.MaxLatencyMs
+		}
+	}
+	sort.SliceStable(routes, func(i, j int) bool {
+		si, sj := slow[routes[i].ProviderID], slow[routes[j].ProviderID]
+		if si != sj {
+			return sj
+		}
+		ci, cj := costs[routes[i]], costs[routes[j]]
+		if ci != cj {
+			return ci < cj
+		}
+		return routes[i].Position < routes[j].Position
+	})
+	return routes
+}
+
+// projectedCost prices a request on a route in micro-USD; ok is false if the mapped model
+// has no pricing
+func projectedCost(route *domain.Route, config *domain.RoutingStrategyConfig, snap *Snapshot) (uint64, bool) {
+	calc := pricing.GlobalCalculator()
+	p := calc.GetPricing(snap.MappedModel(route))
+	if p == nil {
+		return 0, false
+	}
+	output := uint64(defaultExpectedOutputTokens)
+	if config != nil && config.ExpectedOutputTokens > 0 {
+		output = config.ExpectedOutputTokens
+	}
+	return calc.CalculateWithPricing(p, &usage.Metrics{InputTokens: snap.InputTokens(), OutputTokens: output}), true
+}
@@ -0,0 +1,211 @@
+// Package routingprofile applies routing profiles on a schedule. A profile whose time
+// window starts is applied once, at the start of the window; switching profiles by hand
+// holds until the next window starts, rather than being reverted on the next tick.
+package routingprofile
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+const checkInterval = time.Minute
+
+// ListFunc returns all routing profiles
+type ListFunc func() ([]*domain.RoutingProfile, error)
+
+// ApplyFunc applies a routing profile
+type ApplyFunc func(id uint64) error
+
+// ActiveFunc returns the ID of the last applied profile, 0 for none
+type ActiveFunc func() uint64
+
+// Scheduler applies the scheduled profile whenever the matching profile changes
+type Scheduler struct {
+	mu        sync.Mutex
+	started   bool
+	scheduled uint64 // Profile matched at the last check
+	checked   bool
+	list      ListFunc
+	apply     ApplyFunc
+	active    ActiveFunc
+	now       func() time.Time
+}
+
+// NewScheduler creates a scheduler; nothing is applied until Start is called
+func NewScheduler() *Scheduler {
+	return &Scheduler{now: time.Now}
+}
+
+var defaultScheduler = NewScheduler()
+
+// Default returns the global scheduler
+func Default() *Scheduler {
+	return defaultScheduler
+}
+
+// Start checks the schedules every minute in the background. Calling it again only
+// replaces the callbacks.
+func (s *Scheduler) Start(list ListFunc, apply ApplyFunc, active ActiveFunc) {
+	s.mu.Lock()
+	s.list = list
+	s.apply = apply
+	s.active = active
+	started := s.started
+	s.started = true
+	s.mu.Unlock()
+	if started {
+		return
+	}
+
+	go func() {
+		s.Check()
+		ticker := time.NewTicker(checkInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.Check()
+		}
+	}()
+	log.Printf("[RoutingProfile] Scheduler started")
+}
+
+// Scheduled returns the profile whose schedule matched at the last check, 0 for none
+func (s *Scheduler) Scheduled() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.scheduled
+}
+
+// Check applies the scheduled profile if it changed since the last check. On the first
+// check after startup the scheduled profile is only applied if no profile is active, so
+// a restart does not undo a manual switch.
+func (s *Scheduler) Check() {
+	s.mu.Lock()
+	list, apply, active := s.list, s.apply, s.active
+	s.mu.Unlock()
+	if list == nil || apply == nil {
+		return
+	}
+
+	profiles, err := list()
+	if err != nil {
+		log.Printf("[RoutingProfile] Failed to list profiles: %v", err)
+		return
+	}
+	match := Match(profiles, s.now())
+
+	s.mu.Lock()
+	previous, checked := s.scheduled, s.checked
+	s.scheduled = match
+	s.checked = true
+	s.mu.Unlock()
+
+	if match == 0 || (checked && match == previous) {
+		return
+	}
+	if !checked && active != nil && active() != 0 {
+		return
+	}
+	if err := apply(match); err != nil {
+		log.Printf("[RoutingProfile] Failed to apply scheduled profile %d: %v", match, err)
+		return
+	}
+	log.Printf("[RoutingProfile] Applied scheduled profile %d", match)
+}
+
+// Match returns the first profile (by ID) with a schedule covering t, 0 for none
+func Match(profiles []*domain.RoutingProfile, t time.Time) uint64 {
+	var match uint64
+	for _, p := range profiles {
+		if p.DeletedAt != nil || (match != 0 && p.ID > match) {
+			continue
+		}
+		for _, sched := range p.Schedules {
+			if Covers(sched, t) {
+				match = p.ID
+				break
+			}
+		}
+	}
+	return match
+}
+
+// Covers reports whether a schedule covers t. Overnight windows (end before start)
+// belong to the day they start on.
+func Covers(sched domain.RoutingProfileSchedule, t time.Time) bool {
+	start, err := ParseClock(sched.Start)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(sched.End)
+	if err != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	day := int(t.Weekday())
+
+	switch {
+	case start == end:
+		// The whole day
+		return onDay(sched.Days, day)
+	case start < end:
+		return minute >= start && minute < end && onDay(sched.Days, day)
+	case minute >= start:
+		return onDay(sched.Days, day)
+	case minute < end:
+		return onDay(sched.Days, (day+6)%7)
+	}
+	return false
+}
+
+func onDay(days []int, day int) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, d := range days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseClock parses an HH:MM time of day into minutes since midnight
+func ParseClock(s string) (int, error) {
+	hh, mm, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	h, err := strconv.Atoi(hh)
+	if err != nil || h < 0 || h > 23 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	m, err := strconv.Atoi(mm)
+	if err != nil || m < 0 || m > 59 {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", s)
+	}
+	return h*60 + m, nil
+}
+
+// Validate checks the schedules of a profile
+func Validate(schedules []domain.RoutingProfileSchedule) error {
+	for i, sched := range schedules {
+		if _, err := ParseClock(sched.Start); err != nil {
+			return fmt.Errorf("schedules[%d].start: %w", i, err)
+		}
+		if _, err := ParseClock(sched.End); err != nil {
+			return fmt.Errorf("schedules[%d].end: %w", i, err)
+		}
+		for _, d := range sched.Days {
+			if d < 0 || d > 6 {
+				return fmt.Errorf("schedules[%d].days: %d is not a weekday (0 = Sunday ... 6 = Saturday)", i, d)
+			}
+		}
+	}
+	return nil
+}
@@ -3,15 +3,26 @@ package service
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/awsl-project/maxx/internal/audit"
+	"github.com/awsl-project/maxx/internal/capability"
 	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/event"
+	"github.com/awsl-project/maxx/internal/failback"
+	"github.com/awsl-project/maxx/internal/jsondiff"
+	"github.com/awsl-project/maxx/internal/mappinglearn"
+	"github.com/awsl-project/maxx/internal/quality"
+	"github.com/awsl-project/maxx/internal/quota"
 	"github.com/awsl-project/maxx/internal/repository"
+	"github.com/awsl-project/maxx/internal/router"
 	"github.com/awsl-project/maxx/internal/version"
 )
 
@@ -22,6 +33,12 @@ type ProviderAdapterRefresher interface {
 	RemoveAdapter(providerID uint64)
 }
 
+// RouteSimulator dry-runs route matching
+// Implemented by Router
+type RouteSimulator interface {
+	Simulate(ctx *router.MatchContext) ([]*domain.RouteCandidate, domain.RoutingStrategyType, bool)
+}
+
 // AdminService provides business logic for admin operations
 // Both HTTP handlers and Wails bindings call this service
 type AdminService struct {
@@ -31,15 +48,21 @@ type AdminService struct {
 	sessionRepo         repository.SessionRepository
 	retryConfigRepo     repository.RetryConfigRepository
 	routingStrategyRepo repository.RoutingStrategyRepository
+	routingProfileRepo  repository.RoutingProfileRepository
+	costAlertRepo       repository.CostAlertRepository
+	latencySLORepo      repository.LatencySLORepository
 	proxyRequestRepo    repository.ProxyRequestRepository
 	attemptRepo         repository.ProxyUpstreamAttemptRepository
 	settingRepo         repository.SystemSettingRepository
 	apiTokenRepo        repository.APITokenRepository
 	modelMappingRepo    repository.ModelMappingRepository
+	capabilityRepo      repository.ModelCapabilityRepository
 	usageStatsRepo      repository.UsageStatsRepository
 	responseModelRepo   repository.ResponseModelRepository
 	serverAddr          string
 	adapterRefresher    ProviderAdapterRefresher
+	routeSimulator      RouteSimulator
+	configCheck         configCheckState
 }
 
 // NewAdminService creates a new admin service
@@ -50,15 +73,20 @@ func NewAdminService(
 	sessionRepo repository.SessionRepository,
 	retryConfigRepo repository.RetryConfigRepository,
 	routingStrategyRepo repository.RoutingStrategyRepository,
+	routingProfileRepo repository.RoutingProfileRepository,
+	costAlertRepo repository.CostAlertRepository,
+	latencySLORepo repository.LatencySLORepository,
 	proxyRequestRepo repository.ProxyRequestRepository,
 	attemptRepo repository.ProxyUpstreamAttemptRepository,
 	settingRepo repository.SystemSettingRepository,
 	apiTokenRepo repository.APITokenRepository,
 	modelMappingRepo repository.ModelMappingRepository,
+	capabilityRepo repository.ModelCapabilityRepository,
 	usageStatsRepo repository.UsageStatsRepository,
 	responseModelRepo repository.ResponseModelRepository,
 	serverAddr string,
 	adapterRefresher ProviderAdapterRefresher,
+	routeSimulator RouteSimulator,
 ) *AdminService {
 	return &AdminService{
 		providerRepo:        providerRepo,
@@ -67,15 +95,20 @@ func NewAdminService(
 		sessionRepo:         sessionRepo,
 		retryConfigRepo:     retryConfigRepo,
 		routingStrategyRepo: routingStrategyRepo,
+		routingProfileRepo:  routingProfileRepo,
+		costAlertRepo:       costAlertRepo,
+		latencySLORepo:      latencySLORepo,
 		proxyRequestRepo:    proxyRequestRepo,
 		attemptRepo:         attemptRepo,
 		settingRepo:         settingRepo,
 		apiTokenRepo:        apiTokenRepo,
 		modelMappingRepo:    modelMappingRepo,
+		capabilityRepo:      capabilityRepo,
 		usageStatsRepo:      usageStatsRepo,
 		responseModelRepo:   responseModelRepo,
 		serverAddr:          serverAddr,
 		adapterRefresher:    adapterRefresher,
+		routeSimulator:      routeSimulator,
 	}
 }
 
@@ -118,18 +151,123 @@ func (s *AdminService) UpdateProvider(provider *domain.Provider) error {
 }
 
 func (s *AdminService) DeleteProvider(id uint64) error {
-	// Delete related routes first
+	// Remove adapter from cache
+	if s.adapterRefresher != nil {
+		s.adapterRefresher.RemoveAdapter(id)
+	}
+	if err := s.providerRepo.Delete(id); err != nil {
+		return err
+	}
+	// Soft delete related routes after the provider, so RestoreProvider can tell them
+	// from routes deleted earlier by their deletion time
 	routes, _ := s.routeRepo.List()
 	for _, route := range routes {
 		if route.ProviderID == id {
 			s.routeRepo.Delete(route.ID)
 		}
 	}
-	// Remove adapter from cache
+	return nil
+}
+
+// GetDeletedProviders 返回已软删除的 Provider，最近删除的在前
+func (s *AdminService) GetDeletedProviders() ([]*domain.Provider, error) {
+	return s.providerRepo.ListDeleted()
+}
+
+// ListProviders 分页获取 Provider，deleted 为 true 时列出已软删除的，label 非空时只含带该标签的
+func (s *AdminService) ListProviders(deleted bool, label string, q ListQuery) (*Page[*domain.Provider], error) {
+	list := s.providerRepo.List
+	if deleted {
+		list = s.providerRepo.ListDeleted
+	}
+	providers, err := list()
+	if err != nil {
+		return nil, err
+	}
+	if label != "" {
+		filtered := make([]*domain.Provider, 0, len(providers))
+		for _, p := range providers {
+			if p.HasLabel(label) {
+				filtered = append(filtered, p)
+			}
+		}
+		providers = filtered
+	}
+	return paginate(providers, q, func(p *domain.Provider) uint64 { return p.ID }, providerSortFields)
+}
+
+// RestoreProvider 恢复软删除的 Provider，连同删除它时一起删除的路由
+// 恢复前已有相同 (项目, Provider, 客户端类型) 的新路由时，旧路由保持删除
+func (s *AdminService) RestoreProvider(id uint64) (*domain.Provider, error) {
+	provider, err := s.providerRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if provider.DeletedAt == nil {
+		return nil, fmt.Errorf("%w: provider %d is not deleted", domain.ErrInvalidInput, id)
+	}
+	deletedAt := *provider.DeletedAt
+	if err := s.providerRepo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	routes, _ := s.routeRepo.ListDeleted()
+	for _, route := range routes {
+		if route.ProviderID != id || route.DeletedAt == nil || route.DeletedAt.Before(deletedAt) {
+			continue
+		}
+		if _, err := s.routeRepo.FindByKey(route.ProjectID, route.ProviderID, route.ClientType); err == nil {
+			continue
+		}
+		s.routeRepo.Restore(route.ID)
+	}
+
+	provider, err = s.providerRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
 	if s.adapterRefresher != nil {
-		s.adapterRefresher.RemoveAdapter(id)
+		s.adapterRefresher.RefreshAdapter(provider)
+	}
+	return provider, nil
+}
+
+// GetProviderQuotas 返回配置了本地配额的 Provider 的当前用量
+func (s *AdminService) GetProviderQuotas() ([]*domain.ProviderQuotaStatus, error) {
+	providers, err := s.providerRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	result := []*domain.ProviderQuotaStatus{}
+	for _, p := range providers {
+		if status := quota.Default().Status(p); status != nil {
+			result = append(result, status)
+		}
 	}
-	return s.providerRepo.Delete(id)
+	return result, nil
+}
+
+// ResetProviderQuota 清空 Provider 本地配额的当前用量，使其立即恢复可用
+func (s *AdminService) ResetProviderQuota(providerID uint64) {
+	quota.Default().Reset(providerID)
+}
+
+// GetFailbackStatus 返回故障切换过的 Provider 的恢复状态
+func (s *AdminService) GetFailbackStatus() ([]*domain.FailbackStatus, error) {
+	providers, err := s.providerRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint64]*domain.Provider, len(providers))
+	for _, p := range providers {
+		byID[p.ID] = p
+	}
+	return failback.Default().Status(byID), nil
+}
+
+// PromoteProvider 不等探测结果，立即切回 Provider 并清除其冷却
+func (s *AdminService) PromoteProvider(providerID uint64) {
+	failback.Default().Promote(providerID)
 }
 
 // ExportProviders exports all providers for backup/transfer
@@ -220,12 +358,121 @@ func (s *AdminService) DeleteRoute(id uint64) error {
 	return s.routeRepo.Delete(id)
 }
 
+// GetDeletedRoutes 返回已软删除的路由，最近删除的在前
+func (s *AdminService) GetDeletedRoutes() ([]*domain.Route, error) {
+	return s.routeRepo.ListDeleted()
+}
+
+// ListRoutes 分页获取路由，deleted 为 true 时列出已软删除的
+func (s *AdminService) ListRoutes(deleted bool, q ListQuery) (*Page[*domain.Route], error) {
+	list := s.routeRepo.List
+	if deleted {
+		list = s.routeRepo.ListDeleted
+	}
+	routes, err := list()
+	if err != nil {
+		return nil, err
+	}
+	return paginate(routes, q, func(r *domain.Route) uint64 { return r.ID }, routeSortFields)
+}
+
+// RestoreRoute 恢复软删除的路由
+// Provider 已删除，或已有相同 (项目, Provider, 客户端类型) 的路由时不能恢复
+func (s *AdminService) RestoreRoute(id uint64) (*domain.Route, error) {
+	route, err := s.routeRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if route.DeletedAt == nil {
+		return nil, fmt.Errorf("%w: route %d is not deleted", domain.ErrInvalidInput, id)
+	}
+	provider, err := s.providerRepo.GetByID(route.ProviderID)
+	if err != nil || provider.DeletedAt != nil {
+		return nil, fmt.Errorf("%w: provider %d of route %d is deleted, restore it first", domain.ErrInvalidInput, route.ProviderID, id)
+	}
+	if existing, err := s.routeRepo.FindByKey(route.ProjectID, route.ProviderID, route.ClientType); err == nil {
+		return nil, fmt.Errorf("%w: route %d already routes %s requests to provider %d", domain.ErrInvalidInput, existing.ID, route.ClientType, route.ProviderID)
+	}
+	if err := s.routeRepo.Restore(id); err != nil {
+		return nil, err
+	}
+	return s.routeRepo.GetByID(id)
+}
+
+// SimulateRoutes 模拟一次请求的路由过程（Router.Match + 模型映射），不发送任何请求
+func (s *AdminService) SimulateRoutes(req *domain.RouteSimulationRequest) (*domain.RouteSimulation, error) {
+	if req.ClientType == "" {
+		return nil, fmt.Errorf("%w: clientType is required", domain.ErrInvalidInput)
+	}
+
+	candidates, strategy, useProjectRoutes := s.routeSimulator.Simulate(&router.MatchContext{
+		ClientType:   req.ClientType,
+		ProjectID:    req.ProjectID,
+		RequestModel: req.Model,
+		APITokenID:   req.APITokenID,
+		SessionID:    req.SessionID,
+	})
+	result := &domain.RouteSimulation{
+		RouteSimulationRequest: *req,
+		Strategy:               strategy,
+		UseProjectRoutes:       useProjectRoutes,
+		Candidates:             candidates,
+	}
+	if result.Candidates == nil {
+		result.Candidates = []*domain.RouteCandidate{}
+	}
+
+	selected := 0
+	for _, c := range candidates {
+		if c.ExperimentModel != "" {
+			// 实验组指定的模型替代模型映射（同 Executor）
+			c.MappedModel = c.ExperimentModel
+		} else if c.ProviderType != "" && req.Model != "" {
+			c.MappedModel, c.ModelMapping = s.simulateModelMapping(req, c)
+		}
+		if c.Selected {
+			selected++
+		}
+	}
+	if selected == 0 {
+		result.Error = domain.ErrNoRoutes.Error()
+	}
+	return result, nil
+}
+
+// simulateModelMapping 与 Executor.mapModel 相同的映射查找，同时返回命中的规则
+func (s *AdminService) simulateModelMapping(req *domain.RouteSimulationRequest, c *domain.RouteCandidate) (string, *domain.ModelMapping) {
+	mappings, _ := s.modelMappingRepo.ListByQuery(&domain.ModelMappingQuery{
+		ClientType:   req.ClientType,
+		ProviderType: c.ProviderType,
+		ProviderID:   c.ProviderID,
+		ProjectID:    req.ProjectID,
+		RouteID:      c.Route.ID,
+		APITokenID:   req.APITokenID,
+	})
+	for _, m := range mappings {
+		if domain.MatchWildcard(m.Pattern, req.Model) {
+			return m.Target, m
+		}
+	}
+	return req.Model, nil
+}
+
 // ===== Project API =====
 
 func (s *AdminService) GetProjects() ([]*domain.Project, error) {
 	return s.projectRepo.List()
 }
 
+// ListProjects 分页获取项目
+func (s *AdminService) ListProjects(q ListQuery) (*Page[*domain.Project], error) {
+	projects, err := s.projectRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	return paginate(projects, q, func(p *domain.Project) uint64 { return p.ID }, projectSortFields)
+}
+
 func (s *AdminService) GetProject(id uint64) (*domain.Project, error) {
 	return s.projectRepo.GetByID(id)
 }
@@ -248,8 +495,28 @@ func (s *AdminService) DeleteProject(id uint64) error {
 
 // ===== Session API =====
 
-func (s *AdminService) GetSessions() ([]*domain.Session, error) {
-	return s.sessionRepo.List()
+// GetSessions 获取会话列表，includeArchived 为 false 时不包含因空闲被归档的会话
+func (s *AdminService) GetSessions(includeArchived bool) ([]*domain.Session, error) {
+	sessions, err := s.sessionRepo.List()
+	if err != nil || includeArchived {
+		return sessions, err
+	}
+	result := make([]*domain.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if session.ArchivedAt == nil {
+			result = append(result, session)
+		}
+	}
+	return result, nil
+}
+
+// ListSessions 分页获取会话，includeArchived 的含义同 GetSessions
+func (s *AdminService) ListSessions(includeArchived bool, q ListQuery) (*Page[*domain.Session], error) {
+	sessions, err := s.GetSessions(includeArchived)
+	if err != nil {
+		return nil, err
+	}
+	return paginate(sessions, q, func(session *domain.Session) uint64 { return session.ID }, sessionSortFields)
 }
 
 // UpdateSessionProjectResult holds the result of updating session project
@@ -302,6 +569,38 @@ func (s *AdminService) RejectSession(sessionID string) (*domain.Session, error)
 	return session, nil
 }
 
+// PinSession 将会话固定到指定路由或供应商，由 Router 强制执行
+// routeID 和 providerID 都为 0 时取消固定；指定 routeID 时 providerID 取该路由的供应商
+func (s *AdminService) PinSession(sessionID string, routeID, providerID uint64) (*domain.Session, error) {
+	session, err := s.sessionRepo.GetBySessionID(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if routeID != 0 {
+		route, err := s.routeRepo.GetByID(routeID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: route %d not found", domain.ErrInvalidInput, routeID)
+		}
+		if route.ClientType != session.ClientType {
+			return nil, fmt.Errorf("%w: route %d serves %s, session is %s", domain.ErrInvalidInput, routeID, route.ClientType, session.ClientType)
+		}
+		providerID = route.ProviderID
+	} else if providerID != 0 {
+		if _, err := s.providerRepo.GetByID(providerID); err != nil {
+			return nil, fmt.Errorf("%w: provider %d not found", domain.ErrInvalidInput, providerID)
+		}
+	}
+
+	session.PinnedRouteID = routeID
+	session.PinnedProviderID = providerID
+	if err := s.sessionRepo.Update(session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
 // ===== RetryConfig API =====
 
 func (s *AdminService) GetRetryConfigs() ([]*domain.RetryConfig, error) {
@@ -354,13 +653,20 @@ func (s *AdminService) GetProxyRequests(limit, offset int) ([]*domain.ProxyReque
 
 // CursorPaginationResult 游标分页结果
 type CursorPaginationResult struct {
-	Items   []*domain.ProxyRequest `json:"items"`
-	HasMore bool                   `json:"hasMore"`
-	FirstID uint64                 `json:"firstId,omitempty"`
-	LastID  uint64                 `json:"lastId,omitempty"`
+	Items      []*domain.ProxyRequest `json:"items"`
+	HasMore    bool                   `json:"hasMore"`
+	FirstID    uint64                 `json:"firstId,omitempty"`
+	LastID     uint64                 `json:"lastId,omitempty"`
+	NextCursor uint64                 `json:"nextCursor,omitempty"` // 有更多记录时等于 lastId，可作为下一页的 cursor
+	Total      int64                  `json:"total"`                // 请求记录总数（不受游标影响）
 }
 
 func (s *AdminService) GetProxyRequestsCursor(limit int, before, after uint64) (*CursorPaginationResult, error) {
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
 	items, err := s.proxyRequestRepo.ListCursor(limit+1, before, after)
 	if err != nil {
 		return nil, err
@@ -371,14 +677,23 @@ func (s *AdminService) GetProxyRequestsCursor(limit int, before, after uint64) (
 		items = items[:limit]
 	}
 
+	total, err := s.proxyRequestRepo.Count()
+	if err != nil {
+		return nil, err
+	}
+
 	result := &CursorPaginationResult{
 		Items:   items,
 		HasMore: hasMore,
+		Total:   total,
 	}
 
 	if len(items) > 0 {
 		result.FirstID = items[0].ID
 		result.LastID = items[len(items)-1].ID
+		if hasMore {
+			result.NextCursor = result.LastID
+		}
 	}
 
 	return result, nil
@@ -388,18 +703,202 @@ func (s *AdminService) GetProxyRequestsCount() (int64, error) {
 	return s.proxyRequestRepo.Count()
 }
 
+// GetEffectiveRetryConfig 返回路由实际生效的重试配置及其来源（路由 > 供应商 > 系统默认）
+func (s *AdminService) GetEffectiveRetryConfig(routeID uint64) (*domain.EffectiveRetryConfig, error) {
+	route, err := s.routeRepo.GetByID(routeID)
+	if err != nil {
+		return nil, err
+	}
+	prov, _ := s.providerRepo.GetByID(route.ProviderID)
+	return router.ResolveRetryConfig(route, prov, s.retryConfigRepo), nil
+}
+
 func (s *AdminService) GetProxyRequest(id uint64) (*domain.ProxyRequest, error) {
-	return s.proxyRequestRepo.GetByID(id)
+	req, err := s.proxyRequestRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	// 附带重试链路，便于还原执行器的每一步决定
+	if attempts, err := s.attemptRepo.ListByProxyRequestID(id); err == nil {
+		for _, a := range attempts {
+			req.RetryChain = append(req.RetryChain, &domain.RetryStep{
+				AttemptID:  a.ID,
+				RouteID:    a.RouteID,
+				ProviderID: a.ProviderID,
+				Model:      a.MappedModel,
+				Status:     a.Status,
+				ErrorCode:  a.ErrorCode,
+				Decision:   a.Decision,
+			})
+		}
+	}
+	return req, nil
 }
 
 func (s *AdminService) GetProxyUpstreamAttempts(proxyRequestID uint64) ([]*domain.ProxyUpstreamAttempt, error) {
 	return s.attemptRepo.ListByProxyRequestID(proxyRequestID)
 }
 
+// StoredBody 存储的原始请求体或响应体，用于下载
+type StoredBody struct {
+	ContentType string
+	Body        string
+}
+
+// GetProxyRequestBody 返回请求存储的原始请求体（response 为 false）或响应体
+// attemptID 为 0 时取客户端请求/响应，否则取该请求的这次上游尝试
+func (s *AdminService) GetProxyRequestBody(proxyRequestID, attemptID uint64, response bool) (*StoredBody, error) {
+	var reqInfo *domain.RequestInfo
+	var respInfo *domain.ResponseInfo
+	if attemptID == 0 {
+		req, err := s.proxyRequestRepo.GetByID(proxyRequestID)
+		if err != nil {
+			return nil, err
+		}
+		reqInfo, respInfo = req.RequestInfo, req.ResponseInfo
+	} else {
+		attempts, err := s.attemptRepo.ListByProxyRequestID(proxyRequestID)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for _, a := range attempts {
+			if a.ID == attemptID {
+				reqInfo, respInfo, found = a.RequestInfo, a.ResponseInfo, true
+				break
+			}
+		}
+		if !found {
+			return nil, domain.ErrNotFound
+		}
+	}
+
+	var headers map[string]string
+	var body string
+	if response && respInfo != nil {
+		headers, body = respInfo.Headers, respInfo.Body
+	} else if !response && reqInfo != nil {
+		headers, body = reqInfo.Headers, reqInfo.Body
+	}
+	if body == "" {
+		return nil, domain.ErrNotFound
+	}
+	return &StoredBody{ContentType: storedBodyContentType(headers, body), Body: body}, nil
+}
+
+// storedBodyContentType 推断存储体的 Content-Type：SSE 流和 JSON 按内容识别（客户端发送和转换后
+// 记录的头不一定准确），其余使用记录的头
+func storedBodyContentType(headers map[string]string, body string) string {
+	trimmed := strings.TrimLeft(body, " \t\r\n")
+	if strings.HasPrefix(trimmed, "event:") || strings.HasPrefix(trimmed, "data:") {
+		return "text/event-stream"
+	}
+	if json.Valid([]byte(body)) {
+		return "application/json"
+	}
+	for k, v := range headers {
+		if strings.EqualFold(k, "Content-Type") && v != "" {
+			return v
+		}
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// 单个请求/响应体差异最多返回的条数
+const maxBodyDiffChanges = 500
+
+// GetProxyRequestDiff 返回客户端请求与各次上游尝试的对照视图
+// 差异基于已记录的请求/响应体计算，不会重新执行转换
+func (s *AdminService) GetProxyRequestDiff(proxyRequestID uint64) (*domain.ProxyRequestDiff, error) {
+	req, err := s.proxyRequestRepo.GetByID(proxyRequestID)
+	if err != nil {
+		return nil, err
+	}
+	attempts, err := s.attemptRepo.ListByProxyRequestID(proxyRequestID)
+	if err != nil {
+		return nil, err
+	}
+
+	var clientBody, clientResponseBody string
+	if req.RequestInfo != nil {
+		clientBody = req.RequestInfo.Body
+	}
+	if req.ResponseInfo != nil {
+		clientResponseBody = req.ResponseInfo.Body
+	}
+
+	result := &domain.ProxyRequestDiff{
+		ProxyRequestID: req.ID,
+		ClientType:     req.ClientType,
+		Status:         req.Status,
+		ClientRequest:  req.RequestInfo,
+		ClientResponse: req.ResponseInfo,
+		Attempts:       make([]*domain.AttemptDiff, 0, len(attempts)),
+	}
+	for _, a := range attempts {
+		d := &domain.AttemptDiff{
+			AttemptID:        a.ID,
+			ProviderID:       a.ProviderID,
+			Status:           a.Status,
+			RequestModel:     a.RequestModel,
+			MappedModel:      a.MappedModel,
+			UpstreamRequest:  a.RequestInfo,
+			UpstreamResponse: a.ResponseInfo,
+		}
+		var upstreamBody string
+		if a.RequestInfo != nil {
+			upstreamBody = a.RequestInfo.Body
+		}
+		d.RequestDiff = jsondiff.Compare(clientBody, upstreamBody, maxBodyDiffChanges)
+		if a.ID == req.FinalProxyUpstreamAttemptID && a.ResponseInfo != nil && clientResponseBody != "" {
+			d.ResponseDiff = jsondiff.Compare(a.ResponseInfo.Body, clientResponseBody, maxBodyDiffChanges)
+		}
+		result.Attempts = append(result.Attempts, d)
+	}
+	return result, nil
+}
+
 func (s *AdminService) GetProviderStats(clientType string, projectID uint64) (map[uint64]*domain.ProviderStats, error) {
 	return s.usageStatsRepo.GetProviderStats(clientType, projectID)
 }
 
+// GetRequestShapeStats 按请求模型和供应商汇总请求形态，并计算平均值
+func (s *AdminService) GetRequestShapeStats(filter repository.RequestShapeFilter) ([]*domain.RequestShapeStats, error) {
+	stats, err := s.proxyRequestRepo.GetShapeStats(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		if st.TotalRequests == 0 {
+			continue
+		}
+		n := float64(st.TotalRequests)
+		st.AvgPromptBytes = st.TotalPromptBytes / st.TotalRequests
+		st.AvgMessages = float64(st.TotalMessages) / n
+		st.AvgTools = float64(st.TotalTools) / n
+	}
+	return stats, nil
+}
+
+// GetExperimentStats 汇总路由实验各组的请求，并计算错误率和平均值
+func (s *AdminService) GetExperimentStats(filter repository.ExperimentStatsFilter) ([]*domain.ExperimentArmStats, error) {
+	stats, err := s.proxyRequestRepo.GetExperimentStats(filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range stats {
+		if st.TotalRequests == 0 {
+			continue
+		}
+		st.ErrorRate = float64(st.FailedRequests) / float64(st.TotalRequests)
+		st.AvgCost = st.TotalCost / st.TotalRequests
+		if st.SuccessfulRequests > 0 {
+			st.AvgDurationMs = float64(st.TotalDuration) / float64(st.SuccessfulRequests) / float64(time.Millisecond)
+		}
+	}
+	return stats, nil
+}
+
 // ===== Settings API =====
 
 func (s *AdminService) GetSettings() (map[string]string, error) {
@@ -419,11 +918,19 @@ func (s *AdminService) GetSetting(key string) (string, error) {
 }
 
 func (s *AdminService) UpdateSetting(key, value string) error {
-	return s.settingRepo.Set(key, value)
+	if err := s.settingRepo.Set(key, value); err != nil {
+		return err
+	}
+	event.PublishConfigChange(event.ConfigSettings)
+	return nil
 }
 
 func (s *AdminService) DeleteSetting(key string) error {
-	return s.settingRepo.Delete(key)
+	if err := s.settingRepo.Delete(key); err != nil {
+		return err
+	}
+	event.PublishConfigChange(event.ConfigSettings)
+	return nil
 }
 
 // ===== Proxy Status API =====
@@ -532,7 +1039,7 @@ func (s *AdminService) GetAPIToken(id uint64) (*domain.APIToken, error) {
 }
 
 // CreateAPIToken creates a new API token and returns the plain token (only shown once)
-func (s *AdminService) CreateAPIToken(name, description string, projectID uint64, expiresAt *time.Time) (*domain.APITokenCreateResult, error) {
+func (s *AdminService) CreateAPIToken(name, description string, projectID uint64, allowedClientTypes []domain.ClientType, expiresAt *time.Time) (*domain.APITokenCreateResult, error) {
 	// Generate token
 	plain, prefix, err := generateAPIToken()
 	if err != nil {
@@ -547,6 +1054,8 @@ func (s *AdminService) CreateAPIToken(name, description string, projectID uint64
 		ProjectID:   projectID,
 		IsEnabled:   true,
 		ExpiresAt:   expiresAt,
+
+		AllowedClientTypes: allowedClientTypes,
 	}
 
 	if err := s.apiTokenRepo.Create(token); err != nil {
@@ -623,6 +1132,52 @@ func (s *AdminService) ClearAllModelMappings() error {
 	return s.modelMappingRepo.ClearAll()
 }
 
+// ===== Model Capability API =====
+
+// GetModelCapabilities 返回所有已配置的模型能力规则
+func (s *AdminService) GetModelCapabilities() ([]*domain.ModelCapability, error) {
+	return s.capabilityRepo.List()
+}
+
+// GetModelCapability 按 ID 返回模型能力规则
+func (s *AdminService) GetModelCapability(id uint64) (*domain.ModelCapability, error) {
+	return s.capabilityRepo.GetByID(id)
+}
+
+// CreateModelCapability 创建模型能力规则，并刷新能力注册表
+func (s *AdminService) CreateModelCapability(c *domain.ModelCapability) error {
+	if err := s.capabilityRepo.Create(c); err != nil {
+		return err
+	}
+	return capability.Default().Load()
+}
+
+// UpdateModelCapability 更新模型能力规则，并刷新能力注册表
+func (s *AdminService) UpdateModelCapability(c *domain.ModelCapability) error {
+	if err := s.capabilityRepo.Update(c); err != nil {
+		return err
+	}
+	return capability.Default().Load()
+}
+
+// DeleteModelCapability 删除模型能力规则，并刷新能力注册表
+func (s *AdminService) DeleteModelCapability(id uint64) error {
+	if err := s.capabilityRepo.Delete(id); err != nil {
+		return err
+	}
+	return capability.Default().Load()
+}
+
+// GetBuiltinModelCapabilities 返回内置的默认能力规则（已配置规则优先于它们）
+func (s *AdminService) GetBuiltinModelCapabilities() []domain.ModelCapability {
+	return capability.Builtin()
+}
+
+// ResolveModelCapabilities 返回某个供应商模型合并所有规则后的能力
+func (s *AdminService) ResolveModelCapabilities(providerID uint64, model string) domain.ModelCapabilities {
+	return capability.Default().Lookup(providerID, model)
+}
+
 // ===== Response Model API =====
 
 // GetResponseModelNames returns all unique response model names
@@ -635,6 +1190,37 @@ func (s *AdminService) ResetModelMappingsToDefaults() error {
 	return s.modelMappingRepo.SeedDefaults()
 }
 
+// GetModelMappingOutcomes returns the mapping outcomes recorded in learning mode
+func (s *AdminService) GetModelMappingOutcomes() []*domain.ModelMappingOutcome {
+	return mappinglearn.Default().Outcomes()
+}
+
+// GetModelMappingSuggestions suggests mapping rules for mappings that failed at least
+// failureRate (0-1) of at least minAttempts attempts
+func (s *AdminService) GetModelMappingSuggestions(minAttempts uint64, failureRate float64) []*domain.ModelMappingSuggestion {
+	return mappinglearn.Default().Suggest(minAttempts, failureRate)
+}
+
+// ResetModelMappingOutcomes forgets the recorded mapping outcomes
+func (s *AdminService) ResetModelMappingOutcomes() {
+	mappinglearn.Default().Reset()
+}
+
+// QueryAuditEvents returns audit log events matching the filter, newest first
+func (s *AdminService) QueryAuditEvents(filter audit.Filter) ([]*audit.Event, error) {
+	return audit.Default().Query(filter)
+}
+
+// QueryQualitySamples returns quality review samples matching the filter, newest first
+func (s *AdminService) QueryQualitySamples(filter quality.Filter) ([]*quality.Sample, error) {
+	return quality.Default().Query(filter)
+}
+
+// ExportQualitySamples writes quality review samples matching the filter to w as JSONL
+func (s *AdminService) ExportQualitySamples(w io.Writer, filter quality.Filter) (int, error) {
+	return quality.Default().Export(w, filter)
+}
+
 // GetAvailableClientTypes returns all available client types for model mapping
 func (s *AdminService) GetAvailableClientTypes() []domain.ClientType {
 	return []domain.ClientType{
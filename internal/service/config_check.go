@@ -0,0 +1,388 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/httpclient"
+	"github.com/awsl-project/maxx/internal/quota"
+)
+
+// 配置检查的网络探测超时（单个 Provider）
+const configProbeTimeout = 10 * time.Second
+
+// 同时进行的网络探测数量
+const configProbeConcurrency = 8
+
+// CredentialValidator 校验 Provider 的凭证（如 refresh token 是否已失效）
+// 由 core 注入，避免 service 依赖具体的 Provider 适配器
+type CredentialValidator func(ctx context.Context, p *domain.Provider) error
+
+// ProviderProber 向 Provider 发送一个最小的真实请求，确认其能正常响应
+// 由 core 注入，避免 service 依赖具体的 Provider 适配器
+type ProviderProber func(ctx context.Context, p *domain.Provider) error
+
+// configCheckState 最近一次配置检查的结果
+type configCheckState struct {
+	mu                  sync.RWMutex
+	report              *domain.ConfigReport
+	credentialValidator CredentialValidator
+	providerProber      ProviderProber
+}
+
+// SetCredentialValidator 设置凭证校验函数，未设置时跳过凭证检查
+func (s *AdminService) SetCredentialValidator(v CredentialValidator) {
+	s.configCheck.mu.Lock()
+	s.configCheck.credentialValidator = v
+	s.configCheck.mu.Unlock()
+}
+
+// SetProviderProber 设置故障恢复探测使用的请求函数，未设置时只校验凭证
+func (s *AdminService) SetProviderProber(p ProviderProber) {
+	s.configCheck.mu.Lock()
+	s.configCheck.providerProber = p
+	s.configCheck.mu.Unlock()
+}
+
+// GetConfigReport 返回最近一次配置检查的报告，尚未检查时立即检查
+func (s *AdminService) GetConfigReport(ctx context.Context) *domain.ConfigReport {
+	s.configCheck.mu.RLock()
+	report := s.configCheck.report
+	s.configCheck.mu.RUnlock()
+	if report != nil {
+		return report
+	}
+	return s.CheckConfig(ctx)
+}
+
+// CheckConfig 检查所有 Provider、Route 和模型映射的配置
+// 包括缺失的配置、指向已删除 Provider 的路由、无法访问的 Base URL 和失效的 refresh token
+func (s *AdminService) CheckConfig(ctx context.Context) *domain.ConfigReport {
+	// 探测属于诊断流量，不计入 Provider 的失败统计
+	ctx = ctxutil.WithDiagnostic(ctx, true)
+	c := &configChecker{}
+
+	providers, err := s.providerRepo.List()
+	if err != nil {
+		c.add(domain.ConfigIssueError, "provider", 0, "", "list_failed", fmt.Sprintf("failed to list providers: %v", err))
+	}
+	providerByID := make(map[uint64]*domain.Provider, len(providers))
+	for _, p := range providers {
+		providerByID[p.ID] = p
+		c.checkProvider(p)
+	}
+
+	s.configCheck.mu.RLock()
+	validator := s.configCheck.credentialValidator
+	s.configCheck.mu.RUnlock()
+	c.probeProviders(ctx, providers, validator)
+
+	projectIDs := make(map[uint64]bool)
+	if projects, err := s.projectRepo.List(); err == nil {
+		for _, p := range projects {
+			projectIDs[p.ID] = true
+		}
+	}
+	retryConfigIDs := make(map[uint64]bool)
+	if configs, err := s.retryConfigRepo.List(); err == nil {
+		for _, rc := range configs {
+			retryConfigIDs[rc.ID] = true
+		}
+	}
+
+	routes, err := s.routeRepo.List()
+	if err != nil {
+		c.add(domain.ConfigIssueError, "route", 0, "", "list_failed", fmt.Sprintf("failed to list routes: %v", err))
+	}
+	routeIDs := make(map[uint64]bool, len(routes))
+	for _, r := range routes {
+		routeIDs[r.ID] = true
+		name := fmt.Sprintf("%s route #%d", r.ClientType, r.ID)
+		if _, ok := providerByID[r.ProviderID]; !ok {
+			c.add(domain.ConfigIssueError, "route", r.ID, name, "orphaned_route",
+				fmt.Sprintf("route points to provider %d which does not exist or was deleted", r.ProviderID))
+		}
+		if r.ProjectID != 0 && !projectIDs[r.ProjectID] {
+			c.add(domain.ConfigIssueError, "route", r.ID, name, "missing_project",
+				fmt.Sprintf("route belongs to project %d which does not exist or was deleted", r.ProjectID))
+		}
+		if r.RetryConfigID != 0 && !retryConfigIDs[r.RetryConfigID] {
+			c.add(domain.ConfigIssueWarning, "route", r.ID, name, "missing_retry_config",
+				fmt.Sprintf("retry config %d does not exist, the default retry config is used", r.RetryConfigID))
+		}
+	}
+
+	tokenIDs := make(map[uint64]bool)
+	if tokens, err := s.apiTokenRepo.List(); err == nil {
+		for _, t := range tokens {
+			tokenIDs[t.ID] = true
+		}
+	}
+
+	mappings, err := s.modelMappingRepo.List()
+	if err != nil {
+		c.add(domain.ConfigIssueError, "model_mapping", 0, "", "list_failed", fmt.Sprintf("failed to list model mappings: %v", err))
+	}
+	for _, m := range mappings {
+		name := fmt.Sprintf("%s -> %s", m.Pattern, m.Target)
+		if m.Pattern == "" || m.Target == "" {
+			c.add(domain.ConfigIssueError, "model_mapping", m.ID, name, "incomplete_mapping", "model mapping needs both a pattern and a target")
+		}
+		if m.ProviderID != 0 && providerByID[m.ProviderID] == nil {
+			c.add(domain.ConfigIssueWarning, "model_mapping", m.ID, name, "missing_provider",
+				fmt.Sprintf("model mapping is scoped to provider %d which does not exist, it never applies", m.ProviderID))
+		}
+		if m.RouteID != 0 && !routeIDs[m.RouteID] {
+			c.add(domain.ConfigIssueWarning, "model_mapping", m.ID, name, "missing_route",
+				fmt.Sprintf("model mapping is scoped to route %d which does not exist, it never applies", m.RouteID))
+		}
+		if m.ProjectID != 0 && !projectIDs[m.ProjectID] {
+			c.add(domain.ConfigIssueWarning, "model_mapping", m.ID, name, "missing_project",
+				fmt.Sprintf("model mapping is scoped to project %d which does not exist, it never applies", m.ProjectID))
+		}
+		if m.APITokenID != 0 && !tokenIDs[m.APITokenID] {
+			c.add(domain.ConfigIssueWarning, "model_mapping", m.ID, name, "missing_api_token",
+				fmt.Sprintf("model mapping is scoped to API token %d which does not exist, it never applies", m.APITokenID))
+		}
+	}
+
+	report := c.report()
+	s.configCheck.mu.Lock()
+	s.configCheck.report = report
+	s.configCheck.mu.Unlock()
+	return report
+}
+
+// configChecker 收集配置问题，网络探测并发进行
+type configChecker struct {
+	mu     sync.Mutex
+	issues []*domain.ConfigIssue
+}
+
+func (c *configChecker) add(severity domain.ConfigIssueSeverity, kind string, id uint64, name, code, message string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.issues = append(c.issues, &domain.ConfigIssue{
+		Severity:     severity,
+		Kind:         kind,
+		ResourceID:   id,
+		ResourceName: name,
+		Code:         code,
+		Message:      message,
+	})
+}
+
+func (c *configChecker) report() *domain.ConfigReport {
+	report := &domain.ConfigReport{
+		CheckedAt: time.Now(),
+		Issues:    c.issues,
+	}
+	if report.Issues == nil {
+		report.Issues = []*domain.ConfigIssue{}
+	}
+	for _, issue := range report.Issues {
+		if issue.Severity == domain.ConfigIssueError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	report.OK = report.Errors == 0
+	return report
+}
+
+// checkProvider 检查 Provider 的静态配置
+func (c *configChecker) checkProvider(p *domain.Provider) {
+	providerError := func(code, message string) {
+		c.add(domain.ConfigIssueError, "provider", p.ID, p.Name, code, message)
+	}
+
+	if len(p.SupportedClientTypes) == 0 {
+		c.add(domain.ConfigIssueWarning, "provider", p.ID, p.Name, "no_client_types", "provider supports no client type, no request is routed to it")
+	}
+	if p.Config == nil {
+		providerError("missing_config", "provider has no config")
+		return
+	}
+	if p.Config.ResetSchedule != nil {
+		if _, err := quota.ParseSchedule(p.Config.ResetSchedule); err != nil {
+			c.add(domain.ConfigIssueWarning, "provider", p.ID, p.Name, "invalid_reset_schedule",
+				fmt.Sprintf("reset schedule: %v, quotas reset at midnight server time instead", err))
+		}
+	}
+
+	switch p.Type {
+	case "custom":
+		cfg := p.Config.Custom
+		if cfg == nil {
+			providerError("missing_config", "custom provider has no custom config")
+			return
+		}
+		if cfg.BaseURL == "" && len(cfg.ClientBaseURL) == 0 {
+			providerError("missing_base_url", "custom provider has no base URL")
+		}
+		for _, baseURL := range customBaseURLs(cfg) {
+			if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+				providerError("invalid_base_url", fmt.Sprintf("base URL %q is not a valid absolute URL", baseURL))
+			}
+		}
+		if cfg.APIKey == "" {
+			c.add(domain.ConfigIssueWarning, "provider", p.ID, p.Name, "missing_api_key", "custom provider has no API key")
+		}
+	case "antigravity":
+		cfg := p.Config.Antigravity
+		if cfg == nil {
+			providerError("missing_config", "antigravity provider has no antigravity config")
+			return
+		}
+		if cfg.RefreshToken == "" {
+			providerError("missing_refresh_token", "antigravity provider has no refresh token")
+		}
+	case "kiro":
+		cfg := p.Config.Kiro
+		if cfg == nil {
+			providerError("missing_config", "kiro provider has no kiro config")
+			return
+		}
+		if cfg.RefreshToken == "" {
+			providerError("missing_refresh_token", "kiro provider has no refresh token")
+		}
+		if cfg.AuthMethod == "idc" && (cfg.ClientID == "" || cfg.ClientSecret == "") {
+			providerError("missing_client_credentials", "kiro IdC provider needs a client ID and client secret")
+		}
+	}
+}
+
+// probeProviders 探测 custom Provider 的 Base URL 是否可访问，并校验 refresh token
+func (c *configChecker) probeProviders(ctx context.Context, providers []*domain.Provider, validator CredentialValidator) {
+	sem := make(chan struct{}, configProbeConcurrency)
+	var wg sync.WaitGroup
+	for _, p := range providers {
+		if p.Config == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(p *domain.Provider) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			probeCtx, cancel := context.WithTimeout(ctx, configProbeTimeout)
+			defer cancel()
+
+			if p.Type == "custom" && p.Config.Custom != nil {
+				for _, baseURL := range customBaseURLs(p.Config.Custom) {
+					if err := probeBaseURL(probeCtx, p, baseURL); err != nil {
+						c.add(domain.ConfigIssueWarning, "provider", p.ID, p.Name, "unreachable_base_url",
+							fmt.Sprintf("base URL %s is unreachable: %v", baseURL, err))
+					}
+				}
+				return
+			}
+			if validator == nil || !hasRefreshToken(p) {
+				return
+			}
+			if err := validator(probeCtx, p); err != nil {
+				c.add(domain.ConfigIssueError, "provider", p.ID, p.Name, "invalid_refresh_token",
+					fmt.Sprintf("refresh token is expired or revoked: %v", err))
+			}
+		}(p)
+	}
+	wg.Wait()
+}
+
+// customBaseURLs 返回 custom Provider 配置的所有 Base URL（去重）
+func customBaseURLs(cfg *domain.ProviderConfigCustom) []string {
+	seen := make(map[string]bool)
+	var urls []string
+	add := func(u string) {
+		if u != "" && !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	add(cfg.BaseURL)
+	for _, u := range cfg.ClientBaseURL {
+		add(u)
+	}
+	return urls
+}
+
+// probeBaseURL 请求 Base URL，只要收到任何 HTTP 响应就视为可访问
+func probeBaseURL(ctx context.Context, p *domain.Provider, baseURL string) error {
+	if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		// 已作为 invalid_base_url 报告
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, baseURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpclient.ForProvider(p, httpclient.DefaultOptions()).Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func hasRefreshToken(p *domain.Provider) bool {
+	switch p.Type {
+	case "antigravity":
+		return p.Config.Antigravity != nil && p.Config.Antigravity.RefreshToken != ""
+	case "kiro":
+		return p.Config.Kiro != nil && p.Config.Kiro.RefreshToken != ""
+	}
+	return false
+}
+
+// ProbeProvider 探测 Provider 是否恢复健康，用于故障切换后的自动切回
+// custom Provider 请求 Base URL（5xx 视为不健康），使用 refresh token 的 Provider 发送一个最小的真实请求，
+// 凭证有效但上游仍在出错时不会被判定为已恢复
+func (s *AdminService) ProbeProvider(ctx context.Context, p *domain.Provider) error {
+	if p == nil || p.Config == nil {
+		return nil
+	}
+	ctx = ctxutil.WithDiagnostic(ctx, true)
+	if p.Type == "custom" && p.Config.Custom != nil {
+		for _, baseURL := range customBaseURLs(p.Config.Custom) {
+			if u, err := url.Parse(baseURL); err != nil || u.Scheme == "" || u.Host == "" {
+				continue
+			}
+			req, err := http.NewRequestWithContext(ctx, http.MethodHead, baseURL, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := httpclient.ForProvider(p, httpclient.DefaultOptions()).Do(req)
+			if err != nil {
+				return err
+			}
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				return fmt.Errorf("%s returned %s", baseURL, resp.Status)
+			}
+		}
+		return nil
+	}
+
+	s.configCheck.mu.RLock()
+	validator := s.configCheck.credentialValidator
+	prober := s.configCheck.providerProber
+	s.configCheck.mu.RUnlock()
+	if !hasRefreshToken(p) {
+		return nil
+	}
+	if prober != nil {
+		return prober(ctx, p)
+	}
+	if validator == nil {
+		return nil
+	}
+	return validator(ctx, p)
+}
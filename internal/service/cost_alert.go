@@ -0,0 +1,300 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// CostAlertMessageType 费用告警和每日摘要的广播事件类型
+const CostAlertMessageType = "cost_alert"
+
+// 计算日均费用的天数
+const costAlertAverageDays = 7
+
+// ===== CostAlert API =====
+
+func (s *AdminService) GetCostAlerts() ([]*domain.CostAlert, error) {
+	return s.costAlertRepo.List()
+}
+
+func (s *AdminService) GetCostAlert(id uint64) (*domain.CostAlert, error) {
+	return s.costAlertRepo.GetByID(id)
+}
+
+func (s *AdminService) CreateCostAlert(alert *domain.CostAlert) error {
+	if err := s.validateCostAlert(alert); err != nil {
+		return err
+	}
+	// 第一份摘要在下一个 UTC 日期发送
+	now := time.Now()
+	alert.LastTriggeredAt = nil
+	alert.LastDigestAt = &now
+	return s.costAlertRepo.Create(alert)
+}
+
+// UpdateCostAlert 更新告警配置，保留发送记录，避免修改后当天重复通知
+func (s *AdminService) UpdateCostAlert(alert *domain.CostAlert) error {
+	if err := s.validateCostAlert(alert); err != nil {
+		return err
+	}
+	if existing, err := s.costAlertRepo.GetByID(alert.ID); err == nil {
+		alert.LastTriggeredAt = existing.LastTriggeredAt
+		alert.LastDigestAt = existing.LastDigestAt
+	}
+	if alert.LastDigestAt == nil {
+		now := time.Now()
+		alert.LastDigestAt = &now
+	}
+	return s.costAlertRepo.Update(alert)
+}
+
+func (s *AdminService) DeleteCostAlert(id uint64) error {
+	return s.costAlertRepo.Delete(id)
+}
+
+func (s *AdminService) validateCostAlert(alert *domain.CostAlert) error {
+	alert.Name = strings.TrimSpace(alert.Name)
+	if alert.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+	if alert.PercentAboveAverage < 0 {
+		return fmt.Errorf("%w: percentAboveAverage must not be negative", domain.ErrInvalidInput)
+	}
+	if alert.DailyThreshold == 0 && alert.PercentAboveAverage == 0 && !alert.DailyDigest {
+		return fmt.Errorf("%w: set a daily threshold, a percent above average or the daily digest", domain.ErrInvalidInput)
+	}
+
+	if alert.Scope == "" {
+		alert.Scope = domain.CostAlertScopeGlobal
+	}
+	if alert.Scope == domain.CostAlertScopeGlobal {
+		alert.ScopeID = 0
+		return nil
+	}
+	if alert.ScopeID == 0 {
+		return fmt.Errorf("%w: scopeID is required for scope %q", domain.ErrInvalidInput, alert.Scope)
+	}
+	var err error
+	switch alert.Scope {
+	case domain.CostAlertScopeProject:
+		_, err = s.projectRepo.GetByID(alert.ScopeID)
+	case domain.CostAlertScopeProvider:
+		_, err = s.providerRepo.GetByID(alert.ScopeID)
+	case domain.CostAlertScopeAPIToken:
+		_, err = s.apiTokenRepo.GetByID(alert.ScopeID)
+	default:
+		return fmt.Errorf("%w: unsupported scope %q", domain.ErrInvalidInput, alert.Scope)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s %d not found", domain.ErrInvalidInput, alert.Scope, alert.ScopeID)
+	}
+	return nil
+}
+
+// CostAlertWebhookURL 返回接收费用告警的 Webhook 地址，未配置时为空
+func (s *AdminService) CostAlertWebhookURL() string {
+	val, err := s.settingRepo.Get(domain.SettingKeyCostAlertWebhookURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(val)
+}
+
+// CheckCostAlerts 检查所有启用的费用告警，返回需要发送的通知，并记录发送时间
+// 阈值告警（达到每日阈值或高于过去 7 天日均）每个告警每天最多一次；
+// 每日摘要在 UTC 日期变更后的第一次检查时发送前一天的费用
+func (s *AdminService) CheckCostAlerts(now time.Time) ([]*domain.CostAlertNotification, error) {
+	alerts, err := s.costAlertRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	var enabled []*domain.CostAlert
+	for _, alert := range alerts {
+		if alert.IsEnabled {
+			enabled = append(enabled, alert)
+		}
+	}
+	if len(enabled) == 0 {
+		return nil, nil
+	}
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	start := today.AddDate(0, 0, -costAlertAverageDays)
+	stats, err := s.usageStatsRepo.QueryWithRealtime(repository.UsageStatsFilter{
+		Granularity: domain.GranularityDay,
+		StartTime:   &start,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []*domain.CostAlertNotification
+	for _, alert := range enabled {
+		days := costAlertDailyUsage(alert, stats, start)
+		scopeName := s.costAlertScopeName(alert)
+		var fired []*domain.CostAlertNotification
+
+		if !sameUTCDay(alert.LastTriggeredAt, today) {
+			if n := costAlertTrigger(alert, scopeName, days, today); n != nil {
+				alert.LastTriggeredAt = &now
+				fired = append(fired, n)
+			}
+		}
+		if alert.DailyDigest && !sameUTCDay(alert.LastDigestAt, today) {
+			yesterday := today.AddDate(0, 0, -1)
+			n := newCostAlertNotification(alert, scopeName, domain.CostAlertKindDigest, yesterday, days[costAlertAverageDays-1])
+			n.Message = fmt.Sprintf("%s spent %s on %s (%d requests)",
+				costAlertSubject(n), formatMicroUSD(n.Cost), n.Date, n.Requests)
+			alert.LastDigestAt = &now
+			fired = append(fired, n)
+		}
+		if len(fired) == 0 {
+			continue
+		}
+
+		if err := s.costAlertRepo.Update(alert); err != nil {
+			log.Printf("[CostAlert] Failed to record alert %d: %v", alert.ID, err)
+		}
+		for _, n := range fired {
+			n.TriggeredAt = now
+			log.Printf("[CostAlert] %s", n.Message)
+		}
+		notifications = append(notifications, fired...)
+	}
+	return notifications, nil
+}
+
+// costAlertTrigger 返回当日费用触发的阈值告警，未触发时返回 nil
+func costAlertTrigger(alert *domain.CostAlert, scopeName string, days []*domain.UsageStatsSummary, today time.Time) *domain.CostAlertNotification {
+	usage := days[costAlertAverageDays]
+	if alert.DailyThreshold > 0 && usage.TotalCost >= alert.DailyThreshold {
+		n := newCostAlertNotification(alert, scopeName, domain.CostAlertKindThreshold, today, usage)
+		n.Threshold = alert.DailyThreshold
+		n.Message = fmt.Sprintf("%s spent %s today, reaching the daily threshold of %s",
+			costAlertSubject(n), formatMicroUSD(n.Cost), formatMicroUSD(n.Threshold))
+		return n
+	}
+
+	if alert.PercentAboveAverage <= 0 {
+		return nil
+	}
+	var total uint64
+	for _, day := range days[:costAlertAverageDays] {
+		total += day.TotalCost
+	}
+	average := total / costAlertAverageDays
+	if average == 0 {
+		return nil
+	}
+	percentAbove := (float64(usage.TotalCost)/float64(average) - 1) * 100
+	if percentAbove < alert.PercentAboveAverage {
+		return nil
+	}
+	n := newCostAlertNotification(alert, scopeName, domain.CostAlertKindAboveAverage, today, usage)
+	n.AverageCost = average
+	n.PercentAbove = percentAbove
+	n.Message = fmt.Sprintf("%s spent %s today, %.0f%% above the %d-day average of %s",
+		costAlertSubject(n), formatMicroUSD(n.Cost), percentAbove, costAlertAverageDays, formatMicroUSD(average))
+	return n
+}
+
+func newCostAlertNotification(alert *domain.CostAlert, scopeName string, kind domain.CostAlertKind, day time.Time, usage *domain.UsageStatsSummary) *domain.CostAlertNotification {
+	return &domain.CostAlertNotification{
+		AlertID:      alert.ID,
+		AlertName:    alert.Name,
+		Kind:         kind,
+		Scope:        alert.Scope,
+		ScopeID:      alert.ScopeID,
+		ScopeName:    scopeName,
+		Date:         day.Format("2006-01-02"),
+		Cost:         usage.TotalCost,
+		Requests:     usage.TotalRequests,
+		InputTokens:  usage.TotalInputTokens,
+		OutputTokens: usage.TotalOutputTokens,
+	}
+}
+
+// costAlertDailyUsage 按天汇总告警范围内的用量，下标 0-6 为过去 7 天，7 为当天
+func costAlertDailyUsage(alert *domain.CostAlert, stats []*domain.UsageStats, start time.Time) []*domain.UsageStatsSummary {
+	days := make([]*domain.UsageStatsSummary, costAlertAverageDays+1)
+	for i := range days {
+		days[i] = &domain.UsageStatsSummary{}
+	}
+	for _, st := range stats {
+		if !costAlertCovers(alert, st) {
+			continue
+		}
+		idx := int(st.TimeBucket.UTC().Sub(start) / (24 * time.Hour))
+		if idx < 0 || idx >= len(days) {
+			continue
+		}
+		day := days[idx]
+		day.TotalRequests += st.TotalRequests
+		day.SuccessfulRequests += st.SuccessfulRequests
+		day.FailedRequests += st.FailedRequests
+		day.TotalInputTokens += st.InputTokens
+		day.TotalOutputTokens += st.OutputTokens
+		day.TotalCacheRead += st.CacheRead
+		day.TotalCacheWrite += st.CacheWrite
+		day.TotalCost += st.Cost
+	}
+	return days
+}
+
+func costAlertCovers(alert *domain.CostAlert, st *domain.UsageStats) bool {
+	switch alert.Scope {
+	case domain.CostAlertScopeProject:
+		return st.ProjectID == alert.ScopeID
+	case domain.CostAlertScopeProvider:
+		return st.ProviderID == alert.ScopeID
+	case domain.CostAlertScopeAPIToken:
+		return st.APITokenID == alert.ScopeID
+	}
+	return true
+}
+
+// costAlertScopeName 返回告警范围的名称，已删除或全局时为空
+func (s *AdminService) costAlertScopeName(alert *domain.CostAlert) string {
+	switch alert.Scope {
+	case domain.CostAlertScopeProject:
+		if p, err := s.projectRepo.GetByID(alert.ScopeID); err == nil {
+			return p.Name
+		}
+	case domain.CostAlertScopeProvider:
+		if p, err := s.providerRepo.GetByID(alert.ScopeID); err == nil {
+			return p.Name
+		}
+	case domain.CostAlertScopeAPIToken:
+		if t, err := s.apiTokenRepo.GetByID(alert.ScopeID); err == nil {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+// costAlertSubject 返回通知文本的主语，如 `Cost alert "daily" (project "web")`
+func costAlertSubject(n *domain.CostAlertNotification) string {
+	subject := fmt.Sprintf("Cost alert %q", n.AlertName)
+	switch {
+	case n.Scope == domain.CostAlertScopeGlobal:
+		return subject
+	case n.ScopeName != "":
+		return fmt.Sprintf("%s (%s %q)", subject, n.Scope, n.ScopeName)
+	}
+	return fmt.Sprintf("%s (%s #%d)", subject, n.Scope, n.ScopeID)
+}
+
+func sameUTCDay(t *time.Time, day time.Time) bool {
+	return t != nil && !t.UTC().Before(day)
+}
+
+// formatMicroUSD 将微美元格式化为美元金额，如 $1.23
+func formatMicroUSD(micro uint64) string {
+	return fmt.Sprintf("$%.2f", float64(micro)/1e6)
+}
@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// ===== Dashboard API =====
+
+// GetDashboardTimeSeries 返回仪表盘图表用的时间序列数据
+// 数据来自预聚合的 usage_stats（含当前时间桶的实时补全），前端无需再聚合原始请求
+func (s *AdminService) GetDashboardTimeSeries(rangeKey domain.DashboardRange, groupBy domain.DashboardGroupBy) (*domain.DashboardTimeSeries, error) {
+	if rangeKey == "" {
+		rangeKey = domain.DashboardRange24h
+	}
+
+	var (
+		granularity domain.Granularity
+		step        time.Duration
+		buckets     int
+	)
+	now := time.Now().UTC()
+	switch rangeKey {
+	case domain.DashboardRange24h:
+		granularity, step, buckets = domain.GranularityHour, time.Hour, 24
+	case domain.DashboardRange7d:
+		granularity, step, buckets = domain.GranularityDay, 24*time.Hour, 7
+	case domain.DashboardRange30d:
+		granularity, step, buckets = domain.GranularityDay, 24*time.Hour, 30
+	default:
+		return nil, fmt.Errorf("%w: unsupported range %q", domain.ErrInvalidInput, rangeKey)
+	}
+
+	switch groupBy {
+	case domain.DashboardGroupByNone, domain.DashboardGroupByProvider, domain.DashboardGroupByProject:
+	default:
+		return nil, fmt.Errorf("%w: unsupported groupBy %q", domain.ErrInvalidInput, groupBy)
+	}
+
+	// 计算连续的时间桶（UTC），最后一个桶为当前桶
+	var lastBucket time.Time
+	if granularity == domain.GranularityHour {
+		lastBucket = now.Truncate(time.Hour)
+	} else {
+		lastBucket = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	startTime := lastBucket.Add(-time.Duration(buckets-1) * step)
+
+	stats, err := s.usageStatsRepo.QueryWithRealtime(repository.UsageStatsFilter{
+		Granularity: granularity,
+		StartTime:   &startTime,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.DashboardTimeSeries{
+		Range:       rangeKey,
+		Granularity: granularity,
+		GroupBy:     groupBy,
+		StartTime:   startTime,
+		EndTime:     lastBucket.Add(step),
+		Total:       newTimeSeries(0, "total", startTime, step, buckets),
+		Series:      []*domain.TimeSeries{},
+	}
+
+	names := s.dashboardSeriesNames(groupBy)
+	seriesByID := make(map[uint64]*domain.TimeSeries)
+
+	for _, st := range stats {
+		idx := int(st.TimeBucket.UTC().Sub(startTime) / step)
+		if idx < 0 || idx >= buckets {
+			continue
+		}
+		addStatsToPoint(result.Total.Points[idx], st)
+
+		if groupBy == domain.DashboardGroupByNone {
+			continue
+		}
+		id := st.ProviderID
+		if groupBy == domain.DashboardGroupByProject {
+			id = st.ProjectID
+		}
+		series, ok := seriesByID[id]
+		if !ok {
+			name, found := names[id]
+			if !found {
+				name = fmt.Sprintf("#%d", id)
+			}
+			series = newTimeSeries(id, name, startTime, step, buckets)
+			seriesByID[id] = series
+			result.Series = append(result.Series, series)
+		}
+		addStatsToPoint(series.Points[idx], st)
+	}
+
+	finalizeTimeSeries(result.Total)
+	for _, series := range result.Series {
+		finalizeTimeSeries(series)
+	}
+	sort.Slice(result.Series, func(i, j int) bool {
+		return result.Series[i].ID < result.Series[j].ID
+	})
+
+	return result, nil
+}
+
+// dashboardSeriesNames 返回分组维度 ID → 名称的映射
+func (s *AdminService) dashboardSeriesNames(groupBy domain.DashboardGroupBy) map[uint64]string {
+	names := make(map[uint64]string)
+	switch groupBy {
+	case domain.DashboardGroupByProvider:
+		// 已删除的 Provider 保留名称，历史数据仍可归属
+		if deleted, err := s.providerRepo.ListDeleted(); err == nil {
+			for _, p := range deleted {
+				names[p.ID] = p.Name
+			}
+		}
+		if providers, err := s.providerRepo.List(); err == nil {
+			for _, p := range providers {
+				names[p.ID] = p.Name
+			}
+		}
+	case domain.DashboardGroupByProject:
+		names[0] = "global"
+		if projects, err := s.projectRepo.List(); err == nil {
+			for _, p := range projects {
+				names[p.ID] = p.Name
+			}
+		}
+	}
+	return names
+}
+
+// newTimeSeries 创建以 0 填充的连续时间序列
+func newTimeSeries(id uint64, name string, start time.Time, step time.Duration, buckets int) *domain.TimeSeries {
+	points := make([]*domain.TimeSeriesPoint, buckets)
+	for i := range points {
+		points[i] = &domain.TimeSeriesPoint{TimeBucket: start.Add(time.Duration(i) * step)}
+	}
+	return &domain.TimeSeries{ID: id, Name: name, Points: points}
+}
+
+func addStatsToPoint(p *domain.TimeSeriesPoint, st *domain.UsageStats) {
+	p.TotalRequests += st.TotalRequests
+	p.SuccessfulRequests += st.SuccessfulRequests
+	p.FailedRequests += st.FailedRequests
+	p.InputTokens += st.InputTokens
+	p.OutputTokens += st.OutputTokens
+	p.CacheRead += st.CacheRead
+	p.CacheWrite += st.CacheWrite
+	p.Cost += st.Cost
+}
+
+// finalizeTimeSeries 计算每个时间桶的错误率
+func finalizeTimeSeries(series *domain.TimeSeries) {
+	for _, p := range series.Points {
+		if p.TotalRequests > 0 {
+			p.ErrorRate = float64(p.FailedRequests) / float64(p.TotalRequests) * 100
+		}
+	}
+}
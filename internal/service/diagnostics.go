@@ -0,0 +1,229 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"io"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/diagnostics"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/version"
+)
+
+// 查找失败请求时最多扫描的最近请求数
+const diagnosticScanLimit = 2000
+
+const diagnosticReadme = `maxx diagnostic bundle
+
+This archive is meant to be attached to bug reports. Before it was written:
+- credential fields (API keys, tokens, secrets, passwords, cookies, authorization headers)
+  were replaced with [REDACTED], and known credential formats were scrubbed from all text;
+- request and response bodies keep their JSON structure and structural fields (model, type,
+  role, stop reasons, error messages), all other text was replaced with "[N chars]".
+
+Please still review the files before sharing them.
+
+version.json            version and platform
+config/*.json           providers, routes, projects, retry configs, routing strategies,
+                        model mappings, API tokens, settings and the config health report
+logs/maxx.log           recent log lines
+failed-requests.json    the most recent failed requests with their upstream attempts
+`
+
+// DiagnosticBundleOptions 诊断包内容选项
+type DiagnosticBundleOptions struct {
+	LogLines       []string // 最近的日志行（由调用方读取）
+	FailedRequests int      // 包含的最近失败请求数
+}
+
+// DiagnosticVersion 诊断包中的版本信息
+type DiagnosticVersion struct {
+	Version     string    `json:"version"`
+	Commit      string    `json:"commit"`
+	BuildTime   string    `json:"buildTime"`
+	GoVersion   string    `json:"goVersion"`
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	GeneratedAt time.Time `json:"generatedAt"`
+}
+
+// DiagnosticFailedRequest 诊断包中的失败请求及其上游尝试（已脱敏）
+type DiagnosticFailedRequest struct {
+	Request  *domain.ProxyRequest           `json:"request"`
+	Attempts []*domain.ProxyUpstreamAttempt `json:"attempts"`
+}
+
+// WriteDiagnosticBundle 将脱敏后的配置、日志、版本信息和最近的失败请求打包为 zip 写入 w
+func (s *AdminService) WriteDiagnosticBundle(ctx context.Context, w io.Writer, opts DiagnosticBundleOptions) error {
+	zw := zip.NewWriter(w)
+
+	if err := writeZipFile(zw, "README.txt", []byte(diagnosticReadme)); err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, "version.json", &DiagnosticVersion{
+		Version:     version.Version,
+		Commit:      version.Commit,
+		BuildTime:   version.BuildTime,
+		GoVersion:   runtime.Version(),
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		GeneratedAt: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	if err := s.writeDiagnosticConfig(ctx, zw); err != nil {
+		return err
+	}
+
+	logs := make([]string, len(opts.LogLines))
+	for i, line := range opts.LogLines {
+		logs[i] = diagnostics.ScrubText(line)
+	}
+	if err := writeZipFile(zw, "logs/maxx.log", []byte(strings.Join(logs, "\n"))); err != nil {
+		return err
+	}
+
+	failed, err := s.recentFailedRequests(opts.FailedRequests)
+	if err != nil {
+		return err
+	}
+	if err := writeZipJSON(zw, "failed-requests.json", failed); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// writeDiagnosticConfig 写入脱敏后的配置；某项读取失败时写入错误信息而不是中断打包
+func (s *AdminService) writeDiagnosticConfig(ctx context.Context, zw *zip.Writer) error {
+	sections := []struct {
+		name string
+		load func() (interface{}, error)
+	}{
+		{"providers", func() (interface{}, error) { return s.providerRepo.List() }},
+		{"routes", func() (interface{}, error) { return s.routeRepo.List() }},
+		{"projects", func() (interface{}, error) { return s.projectRepo.List() }},
+		{"retry-configs", func() (interface{}, error) { return s.retryConfigRepo.List() }},
+		{"routing-strategies", func() (interface{}, error) { return s.routingStrategyRepo.List() }},
+		{"model-mappings", func() (interface{}, error) { return s.modelMappingRepo.List() }},
+		{"api-tokens", func() (interface{}, error) { return s.apiTokenRepo.List() }},
+		{"settings", func() (interface{}, error) { return s.GetSettings() }},
+		{"health", func() (interface{}, error) { return s.GetConfigReport(ctx), nil }},
+	}
+
+	for _, section := range sections {
+		var data interface{}
+		v, err := section.load()
+		if err != nil {
+			data = map[string]string{"error": err.Error()}
+		} else if data, err = diagnostics.RedactConfig(v); err != nil {
+			data = map[string]string{"error": err.Error()}
+		}
+		if err := writeZipJSON(zw, "config/"+section.name+".json", data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recentFailedRequests 返回最近 limit 个失败请求及其上游尝试，请求头和请求/响应体均已脱敏
+func (s *AdminService) recentFailedRequests(limit int) ([]*DiagnosticFailedRequest, error) {
+	result := []*DiagnosticFailedRequest{}
+	if limit <= 0 {
+		return result, nil
+	}
+
+	var before uint64
+	for scanned := 0; scanned < diagnosticScanLimit && len(result) < limit; {
+		items, err := s.proxyRequestRepo.ListCursor(100, before, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 0 {
+			break
+		}
+		scanned += len(items)
+		before = items[len(items)-1].ID
+
+		for _, item := range items {
+			if item.Status != "FAILED" {
+				continue
+			}
+			// 列表不含请求/响应体，按 ID 读取完整记录
+			req, err := s.proxyRequestRepo.GetByID(item.ID)
+			if err != nil {
+				continue
+			}
+			attempts, err := s.attemptRepo.ListByProxyRequestID(item.ID)
+			if err != nil {
+				attempts = nil
+			}
+			result = append(result, redactFailedRequest(req, attempts))
+			if len(result) >= limit {
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+func redactFailedRequest(req *domain.ProxyRequest, attempts []*domain.ProxyUpstreamAttempt) *DiagnosticFailedRequest {
+	r := *req
+	r.Error = diagnostics.ScrubText(r.Error)
+	r.RequestInfo = redactRequestInfo(r.RequestInfo)
+	r.ResponseInfo = redactResponseInfo(r.ResponseInfo)
+
+	redacted := make([]*domain.ProxyUpstreamAttempt, len(attempts))
+	for i, a := range attempts {
+		c := *a
+		c.RequestInfo = redactRequestInfo(c.RequestInfo)
+		c.ResponseInfo = redactResponseInfo(c.ResponseInfo)
+		redacted[i] = &c
+	}
+	return &DiagnosticFailedRequest{Request: &r, Attempts: redacted}
+}
+
+func redactRequestInfo(info *domain.RequestInfo) *domain.RequestInfo {
+	if info == nil {
+		return nil
+	}
+	return &domain.RequestInfo{
+		Method:  info.Method,
+		Headers: diagnostics.RedactHeaders(info.Headers),
+		URL:     diagnostics.ScrubText(info.URL),
+		Body:    diagnostics.RedactBody(info.Body),
+	}
+}
+
+func redactResponseInfo(info *domain.ResponseInfo) *domain.ResponseInfo {
+	if info == nil {
+		return nil
+	}
+	return &domain.ResponseInfo{
+		Status:  info.Status,
+		Headers: diagnostics.RedactHeaders(info.Headers),
+		Body:    diagnostics.RedactBody(info.Body),
+	}
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	return err
+}
+
+func writeZipJSON(zw *zip.Writer, name string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeZipFile(zw, name, data)
+}
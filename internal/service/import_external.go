@@ -0,0 +1,154 @@
+package service
+
+import (
+	"log"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/importer"
+)
+
+// ===== External Import API =====
+
+// 外部导入中每个 Provider 的状态
+const (
+	ExternalImportNew     = "new"     // 预览：将会创建
+	ExternalImportCreated = "created" // 已创建
+	ExternalImportSkipped = "skipped" // 已存在同名 Provider，跳过
+	ExternalImportFailed  = "failed"  // 创建失败
+)
+
+// ExternalImportProvider 外部导入中的一个 Provider
+type ExternalImportProvider struct {
+	Name          string              `json:"name"`
+	Type          string              `json:"type"`
+	ClientTypes   []domain.ClientType `json:"clientTypes"`
+	ModelMappings int                 `json:"modelMappings"`
+	Origin        string              `json:"origin"`
+	Status        string              `json:"status"`
+	Error         string              `json:"error,omitempty"`
+}
+
+// ExternalImportResult 从 CLIProxyAPI / Antigravity-Manager 配置导入的结果
+type ExternalImportResult struct {
+	DryRun bool `json:"dryRun"`
+	// 每个文件识别出的格式
+	Formats       map[string]string         `json:"formats"`
+	Providers     []*ExternalImportProvider `json:"providers"`
+	Routes        int                       `json:"routes"`
+	ModelMappings int                       `json:"modelMappings"`
+	Warnings      []string                  `json:"warnings"`
+}
+
+// ImportExternalProviders 从 CLIProxyAPI 或 Antigravity-Manager 的配置 / Token 文件创建 Provider，
+// 并为每个 Provider 支持的客户端类型创建全局路由（排在现有路由之后），以及作用于该 Provider 的模型映射
+// dryRun 为 true 时只返回将要创建的内容；已存在同名 Provider 时跳过
+func (s *AdminService) ImportExternalProviders(files []importer.File, dryRun bool) (*ExternalImportResult, error) {
+	plan, err := importer.Parse(files)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.providerRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	existingNames := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		existingNames[p.Name] = true
+	}
+
+	routes, err := s.routeRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	nextPosition := make(map[domain.ClientType]int)
+	for _, r := range routes {
+		if r.ProjectID == 0 && r.Position >= nextPosition[r.ClientType] {
+			nextPosition[r.ClientType] = r.Position + 1
+		}
+	}
+
+	result := &ExternalImportResult{
+		DryRun:    dryRun,
+		Formats:   plan.Formats,
+		Providers: make([]*ExternalImportProvider, 0, len(plan.Providers)),
+		Warnings:  append([]string{}, plan.Warnings...),
+	}
+	for _, pp := range plan.Providers {
+		provider := pp.Provider
+		s.autoSetSupportedClientTypes(provider)
+		item := &ExternalImportProvider{
+			Name:          provider.Name,
+			Type:          provider.Type,
+			ClientTypes:   provider.SupportedClientTypes,
+			ModelMappings: len(pp.ModelMappings),
+			Origin:        pp.Origin,
+			Status:        ExternalImportNew,
+		}
+		result.Providers = append(result.Providers, item)
+
+		if existingNames[provider.Name] {
+			item.Status = ExternalImportSkipped
+			continue
+		}
+		existingNames[provider.Name] = true
+		if dryRun {
+			result.Routes += len(provider.SupportedClientTypes)
+			result.ModelMappings += len(pp.ModelMappings)
+			continue
+		}
+
+		if err := s.CreateProvider(provider); err != nil {
+			item.Status = ExternalImportFailed
+			item.Error = err.Error()
+			continue
+		}
+		item.Status = ExternalImportCreated
+
+		for _, clientType := range provider.SupportedClientTypes {
+			route := &domain.Route{
+				IsEnabled:  true,
+				IsNative:   true,
+				ClientType: clientType,
+				ProviderID: provider.ID,
+				Position:   nextPosition[clientType],
+			}
+			if err := s.routeRepo.Create(route); err != nil {
+				result.Warnings = append(result.Warnings, provider.Name+": failed to create "+string(clientType)+" route: "+err.Error())
+				continue
+			}
+			nextPosition[clientType]++
+			result.Routes++
+		}
+
+		for i, rule := range pp.ModelMappings {
+			mapping := &domain.ModelMapping{
+				Scope:      domain.ModelMappingScopeProvider,
+				ProviderID: provider.ID,
+				Pattern:    rule.Pattern,
+				Target:     rule.Target,
+				Priority:   i * 10,
+			}
+			if err := s.modelMappingRepo.Create(mapping); err != nil {
+				result.Warnings = append(result.Warnings, provider.Name+": failed to create model mapping "+rule.Pattern+": "+err.Error())
+				continue
+			}
+			result.ModelMappings++
+		}
+	}
+
+	if !dryRun {
+		log.Printf("[Import] Imported %d providers, %d routes, %d model mappings from external config", countCreated(result.Providers), result.Routes, result.ModelMappings)
+	}
+	return result, nil
+}
+
+func countCreated(providers []*ExternalImportProvider) int {
+	n := 0
+	for _, p := range providers {
+		if p.Status == ExternalImportCreated {
+			n++
+		}
+	}
+	return n
+}
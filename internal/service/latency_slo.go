@@ -0,0 +1,167 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/slo"
+)
+
+// LatencySLOMessageType 延迟 SLO 违反和恢复事件的广播类型
+const LatencySLOMessageType = "latency_slo"
+
+// 延迟 SLO 的默认值
+const (
+	defaultLatencySLOPercentile = 95
+	defaultLatencySLOWindow     = 15 * time.Minute
+	defaultLatencySLOMinSamples = 20
+)
+
+// ===== LatencySLO API =====
+
+func (s *AdminService) GetLatencySLOs() ([]*domain.LatencySLO, error) {
+	return s.latencySLORepo.List()
+}
+
+func (s *AdminService) GetLatencySLO(id uint64) (*domain.LatencySLO, error) {
+	return s.latencySLORepo.GetByID(id)
+}
+
+func (s *AdminService) CreateLatencySLO(objective *domain.LatencySLO) error {
+	if err := s.validateLatencySLO(objective); err != nil {
+		return err
+	}
+	return s.latencySLORepo.Create(objective)
+}
+
+func (s *AdminService) UpdateLatencySLO(objective *domain.LatencySLO) error {
+	if err := s.validateLatencySLO(objective); err != nil {
+		return err
+	}
+	return s.latencySLORepo.Update(objective)
+}
+
+func (s *AdminService) DeleteLatencySLO(id uint64) error {
+	return s.latencySLORepo.Delete(id)
+}
+
+// validateLatencySLO 校验并补全默认值；时长在数据库中按毫秒（目标）和秒（窗口、持续时间）保存
+func (s *AdminService) validateLatencySLO(objective *domain.LatencySLO) error {
+	objective.Name = strings.TrimSpace(objective.Name)
+	objective.Model = strings.TrimSpace(objective.Model)
+	if objective.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+
+	switch objective.Metric {
+	case "":
+		objective.Metric = domain.LatencySLOMetricTTFB
+	case domain.LatencySLOMetricTTFB, domain.LatencySLOMetricDuration:
+	default:
+		return fmt.Errorf("%w: unsupported metric %q", domain.ErrInvalidInput, objective.Metric)
+	}
+	if objective.Percentile == 0 {
+		objective.Percentile = defaultLatencySLOPercentile
+	}
+	if objective.Percentile < 0 || objective.Percentile > 100 {
+		return fmt.Errorf("%w: percentile must be between 0 and 100", domain.ErrInvalidInput)
+	}
+	if objective.Target < time.Millisecond {
+		return fmt.Errorf("%w: target must be at least 1ms", domain.ErrInvalidInput)
+	}
+
+	if objective.Window == 0 {
+		objective.Window = defaultLatencySLOWindow
+	}
+	if objective.Window < time.Minute || objective.Window > slo.MaxWindow {
+		return fmt.Errorf("%w: window must be between 1m and %s", domain.ErrInvalidInput, slo.MaxWindow)
+	}
+	if objective.MinSamples == 0 {
+		objective.MinSamples = defaultLatencySLOMinSamples
+	}
+	if objective.MinSamples < 0 || objective.Persistence < 0 {
+		return fmt.Errorf("%w: minSamples and persistence must not be negative", domain.ErrInvalidInput)
+	}
+	objective.Target = objective.Target.Truncate(time.Millisecond)
+	objective.Window = objective.Window.Truncate(time.Second)
+	objective.Persistence = objective.Persistence.Truncate(time.Second)
+
+	if objective.ProviderID != 0 {
+		if _, err := s.providerRepo.GetByID(objective.ProviderID); err != nil {
+			return fmt.Errorf("%w: provider %d not found", domain.ErrInvalidInput, objective.ProviderID)
+		}
+	}
+	return nil
+}
+
+// GetLatencySLOStats 返回每个启用的延迟 SLO 在各 Provider 上的当前达标情况
+func (s *AdminService) GetLatencySLOStats() ([]*domain.LatencySLOStatus, error) {
+	objectives, err := s.enabledLatencySLOs()
+	if err != nil {
+		return nil, err
+	}
+	statuses := slo.Default().Evaluate(objectives)
+	if statuses == nil {
+		statuses = []*domain.LatencySLOStatus{}
+	}
+	s.fillLatencySLOProviderNames(statuses)
+	return statuses, nil
+}
+
+// CheckLatencySLOs 检查延迟 SLO，返回持续违反和恢复达标的事件，每次违反只返回一次
+func (s *AdminService) CheckLatencySLOs() ([]*domain.LatencySLOEvent, error) {
+	objectives, err := s.enabledLatencySLOs()
+	if err != nil {
+		return nil, err
+	}
+	events := slo.Default().Check(objectives)
+	for _, e := range events {
+		s.fillLatencySLOProviderNames([]*domain.LatencySLOStatus{e.LatencySLOStatus})
+		e.Message = latencySLOMessage(e)
+		log.Printf("[LatencySLO] %s", e.Message)
+	}
+	return events, nil
+}
+
+func (s *AdminService) enabledLatencySLOs() ([]*domain.LatencySLO, error) {
+	objectives, err := s.latencySLORepo.List()
+	if err != nil {
+		return nil, err
+	}
+	enabled := make([]*domain.LatencySLO, 0, len(objectives))
+	for _, objective := range objectives {
+		if objective.IsEnabled {
+			enabled = append(enabled, objective)
+		}
+	}
+	return enabled, nil
+}
+
+func (s *AdminService) fillLatencySLOProviderNames(statuses []*domain.LatencySLOStatus) {
+	for _, status := range statuses {
+		if p, err := s.providerRepo.GetByID(status.ProviderID); err == nil {
+			status.ProviderName = p.Name
+		}
+	}
+}
+
+// latencySLOMessage 返回事件的通知文本，如 `Provider "a" violates SLO "fast" for claude-*: ttfb p95 3.2s > 2s`
+func latencySLOMessage(e *domain.LatencySLOEvent) string {
+	provider := fmt.Sprintf("%q", e.ProviderName)
+	if e.ProviderName == "" {
+		provider = fmt.Sprintf("#%d", e.ProviderID)
+	}
+	subject := fmt.Sprintf("SLO %q", e.SLOName)
+	if e.Model != "" {
+		subject += " for " + e.Model
+	}
+	measured := fmt.Sprintf("%s p%g %s", e.Metric, e.Percentile, e.Value.Round(time.Millisecond))
+	if e.Kind == domain.LatencySLOEventRecovered {
+		return fmt.Sprintf("Provider %s no longer violates %s: %s, target %s", provider, subject, measured, e.Target)
+	}
+	return fmt.Sprintf("Provider %s violates %s since %s: %s > %s (%d samples, %.0f%% within target)",
+		provider, subject, e.ViolatingSince.Format(time.RFC3339), measured, e.Target, e.Samples, e.Compliance*100)
+}
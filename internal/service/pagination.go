@@ -0,0 +1,158 @@
+package service
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+const (
+	// DefaultPageLimit 未指定 limit 时每页的条数
+	DefaultPageLimit = 50
+	// MaxPageLimit 每页条数上限
+	MaxPageLimit = 500
+)
+
+// ListQuery 列表接口统一的分页与排序参数
+// 游标为上一页最后一条记录的 ID，翻页时排序参数需保持不变
+type ListQuery struct {
+	Limit  int    `json:"limit"`  // 每页条数，0 表示 DefaultPageLimit
+	Cursor uint64 `json:"cursor"` // 上一页的 nextCursor，0 表示第一页
+	Sort   string `json:"sort"`   // 排序字段，空表示列表的默认顺序
+	Order  string `json:"order"`  // asc / desc，空表示 asc
+}
+
+// Page 分页结果
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"` // 符合条件的记录总数（不受分页影响）
+	HasMore    bool   `json:"hasMore"`
+	NextCursor uint64 `json:"nextCursor,omitempty"` // 下一页的游标，没有更多记录时为 0
+}
+
+// sortFields 列表可用的排序字段，比较函数返回 a 与 b 的升序比较结果
+type sortFields[T any] map[string]func(a, b T) int
+
+// paginate 对完整列表排序后按游标取一页
+// 相同排序值的记录按 ID 升序排列，保证翻页顺序稳定
+func paginate[T any](items []T, q ListQuery, id func(T) uint64, fields sortFields[T]) (*Page[T], error) {
+	limit := q.Limit
+	if limit <= 0 {
+		limit = DefaultPageLimit
+	}
+	limit = min(limit, MaxPageLimit)
+
+	desc := false
+	switch strings.ToLower(q.Order) {
+	case "", "asc":
+	case "desc":
+		desc = true
+	default:
+		return nil, fmt.Errorf("%w: order must be asc or desc", domain.ErrInvalidInput)
+	}
+
+	if q.Sort != "" {
+		compare, ok := fields[q.Sort]
+		if !ok {
+			names := make([]string, 0, len(fields))
+			for name := range fields {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+			return nil, fmt.Errorf("%w: unknown sort field %q, expected one of %s", domain.ErrInvalidInput, q.Sort, strings.Join(names, ", "))
+		}
+		items = slices.Clone(items)
+		slices.SortStableFunc(items, func(a, b T) int {
+			c := compare(a, b)
+			if desc {
+				c = -c
+			}
+			if c == 0 {
+				c = cmp.Compare(id(a), id(b))
+			}
+			return c
+		})
+	} else if desc {
+		items = slices.Clone(items)
+		slices.Reverse(items)
+	}
+
+	start := 0
+	if q.Cursor > 0 {
+		i := slices.IndexFunc(items, func(item T) bool { return id(item) == q.Cursor })
+		if i < 0 {
+			return nil, fmt.Errorf("%w: cursor %d not found, restart from the first page", domain.ErrInvalidInput, q.Cursor)
+		}
+		start = i + 1
+	}
+
+	end := min(start+limit, len(items))
+	page := &Page[T]{
+		Items:   items[start:end],
+		Total:   len(items),
+		HasMore: end < len(items),
+	}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if page.HasMore {
+		page.NextCursor = id(items[end-1])
+	}
+	return page, nil
+}
+
+// compareName 不区分大小写比较名称
+func compareName(a, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// compareTime 比较时间，nil 排在最前
+func compareTime(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return a.Compare(*b)
+}
+
+var providerSortFields = sortFields[*domain.Provider]{
+	"id":        func(a, b *domain.Provider) int { return cmp.Compare(a.ID, b.ID) },
+	"name":      func(a, b *domain.Provider) int { return compareName(a.Name, b.Name) },
+	"type":      func(a, b *domain.Provider) int { return strings.Compare(a.Type, b.Type) },
+	"createdAt": func(a, b *domain.Provider) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updatedAt": func(a, b *domain.Provider) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+var routeSortFields = sortFields[*domain.Route]{
+	"id":         func(a, b *domain.Route) int { return cmp.Compare(a.ID, b.ID) },
+	"position":   func(a, b *domain.Route) int { return cmp.Compare(a.Position, b.Position) },
+	"clientType": func(a, b *domain.Route) int { return strings.Compare(string(a.ClientType), string(b.ClientType)) },
+	"providerID": func(a, b *domain.Route) int { return cmp.Compare(a.ProviderID, b.ProviderID) },
+	"projectID":  func(a, b *domain.Route) int { return cmp.Compare(a.ProjectID, b.ProjectID) },
+	"createdAt":  func(a, b *domain.Route) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updatedAt":  func(a, b *domain.Route) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+var projectSortFields = sortFields[*domain.Project]{
+	"id":        func(a, b *domain.Project) int { return cmp.Compare(a.ID, b.ID) },
+	"name":      func(a, b *domain.Project) int { return compareName(a.Name, b.Name) },
+	"createdAt": func(a, b *domain.Project) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updatedAt": func(a, b *domain.Project) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+var sessionSortFields = sortFields[*domain.Session]{
+	"id":           func(a, b *domain.Session) int { return cmp.Compare(a.ID, b.ID) },
+	"clientType":   func(a, b *domain.Session) int { return strings.Compare(string(a.ClientType), string(b.ClientType)) },
+	"projectID":    func(a, b *domain.Session) int { return cmp.Compare(a.ProjectID, b.ProjectID) },
+	"createdAt":    func(a, b *domain.Session) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updatedAt":    func(a, b *domain.Session) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+	"lastActiveAt": func(a, b *domain.Session) int { return compareTime(a.LastActiveAt, b.LastActiveAt) },
+}
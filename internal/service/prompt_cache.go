@@ -0,0 +1,116 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/pricing"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// ===== Prompt Cache Advisor API =====
+
+const (
+	// 估算 token 数时每个 token 的平均字节数
+	promptCacheBytesPerToken = 4
+	// Claude prompt 缓存的最小可缓存长度（tokens）
+	promptCacheMinTokens = 1024
+	// 判断前缀不稳定所需的最少请求数
+	promptCacheMinUniqueRequests = 5
+)
+
+// GetPromptCacheAdvice 分析 Claude 请求中重复出现的 system prompt 和工具定义，
+// 按路由估算 prompt 缓存可节省的 tokens 和成本并给出建议，按可节省成本降序
+func (s *AdminService) GetPromptCacheAdvice(filter repository.PromptPrefixFilter) ([]*domain.PromptCacheAdvice, error) {
+	stats, err := s.proxyRequestRepo.GetPromptPrefixStats(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	byRoute := make(map[uint64]*domain.PromptCacheAdvice)
+	prefixes := make(map[uint64]map[string]bool)
+	uniqueLarge := make(map[uint64]uint64)
+	var results []*domain.PromptCacheAdvice
+	for _, st := range stats {
+		advice, ok := byRoute[st.RouteID]
+		if !ok {
+			advice = &domain.PromptCacheAdvice{RouteID: st.RouteID, ProviderID: st.ProviderID}
+			byRoute[st.RouteID] = advice
+			prefixes[st.RouteID] = make(map[string]bool)
+			results = append(results, advice)
+		}
+		prefixes[st.RouteID][st.PrefixHash] = true
+
+		advice.Requests += st.Requests
+		advice.CacheControlRequests += st.CacheControlRequests
+		advice.StrippedRequests += st.StrippedRequests
+		advice.CacheReadTokens += st.CacheReadTokens
+		advice.CacheWriteTokens += st.CacheWriteTokens
+
+		tokens := st.PrefixBytes / promptCacheBytesPerToken
+		if tokens < promptCacheMinTokens {
+			continue
+		}
+		if st.Requests < 2 {
+			uniqueLarge[st.RouteID]++
+			continue
+		}
+		// 第一次请求写入缓存，之后的请求都可以读取
+		repeated := st.Requests - 1
+		cacheable := repeated * tokens
+		advice.RepeatedRequests += repeated
+		advice.CacheableTokens += cacheable
+		if cacheable > st.CacheReadTokens {
+			missed := cacheable - st.CacheReadTokens
+			advice.MissedTokens += missed
+			advice.EstimatedSavings += promptCacheSavings(st.Model, missed)
+		}
+	}
+
+	for _, advice := range results {
+		advice.DistinctPrefixes = uint64(len(prefixes[advice.RouteID]))
+		if advice.RepeatedRequests > 0 {
+			advice.AvgPrefixTokens = advice.CacheableTokens / advice.RepeatedRequests
+		}
+		advice.Recommendations = promptCacheRecommendations(advice, uniqueLarge[advice.RouteID])
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].EstimatedSavings != results[j].EstimatedSavings {
+			return results[i].EstimatedSavings > results[j].EstimatedSavings
+		}
+		return results[i].MissedTokens > results[j].MissedTokens
+	})
+	return results, nil
+}
+
+// promptCacheSavings 估算 tokens 从缓存读取而非按输入计费可节省的成本（微美元）
+func promptCacheSavings(model string, tokens uint64) uint64 {
+	p := pricing.GlobalCalculator().GetPricing(model)
+	if p == nil {
+		return 0
+	}
+	cacheRead := p.GetEffectiveCacheReadPriceMicro()
+	if p.InputPriceMicro <= cacheRead {
+		return 0
+	}
+	return tokens * (p.InputPriceMicro - cacheRead) / 1_000_000
+}
+
+func promptCacheRecommendations(advice *domain.PromptCacheAdvice, uniqueLarge uint64) []domain.PromptCacheRecommendation {
+	recs := []domain.PromptCacheRecommendation{}
+	if advice.StrippedRequests > 0 {
+		recs = append(recs, domain.PromptCacheRecommendNativeProvider)
+	}
+	if advice.RepeatedRequests > 0 && advice.CacheControlRequests*2 < advice.Requests {
+		recs = append(recs, domain.PromptCacheRecommendClientCacheControl)
+	}
+	forwarded := advice.CacheControlRequests - advice.StrippedRequests
+	if forwarded > 0 && advice.RepeatedRequests > 0 && advice.CacheReadTokens == 0 {
+		recs = append(recs, domain.PromptCacheRecommendProviderNoCache)
+	}
+	if uniqueLarge >= promptCacheMinUniqueRequests && uniqueLarge*2 > advice.Requests {
+		recs = append(recs, domain.PromptCacheRecommendStablePrefix)
+	}
+	return recs
+}
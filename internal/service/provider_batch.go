@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	ctxutil "github.com/awsl-project/maxx/internal/context"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// ===== Provider Batch API =====
+
+// 批量操作类型
+const (
+	ProviderBatchEnable   = "enable"   // 启用
+	ProviderBatchDisable  = "disable"  // 停用
+	ProviderBatchDelete   = "delete"   // 删除（同时删除相关路由）
+	ProviderBatchValidate = "validate" // 校验 refresh token
+	ProviderBatchPatch    = "patch"    // 应用 JSON Merge Patch (RFC 7386)
+)
+
+// 批量操作中每个 Provider 的状态
+const (
+	ProviderBatchOK      = "ok"      // 成功
+	ProviderBatchFailed  = "failed"  // 失败
+	ProviderBatchSkipped = "skipped" // 无需操作（如已是目标状态、无 refresh token）
+)
+
+// errBatchNoop 表示 Provider 已是目标状态
+var errBatchNoop = errors.New("no change")
+
+// Patch 中不允许修改的字段
+var providerBatchProtectedFields = []string{"id", "createdAt", "updatedAt", "deletedAt", "type"}
+
+// ProviderBatchRequest 批量操作请求
+// IDs 和 Label 至少指定一个；同时指定时取交集
+type ProviderBatchRequest struct {
+	IDs    []uint64        `json:"ids,omitempty"`
+	Label  string          `json:"label,omitempty"`
+	Action string          `json:"action"`
+	Patch  json.RawMessage `json:"patch,omitempty"`
+}
+
+// ProviderBatchItem 批量操作中一个 Provider 的结果
+type ProviderBatchItem struct {
+	ID     uint64 `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ProviderBatchResult 批量操作结果
+type ProviderBatchResult struct {
+	Action    string               `json:"action"`
+	Matched   int                  `json:"matched"`
+	Succeeded int                  `json:"succeeded"`
+	Failed    int                  `json:"failed"`
+	Skipped   int                  `json:"skipped"`
+	Items     []*ProviderBatchItem `json:"items"`
+}
+
+// BatchProviders 对按 ID 或标签选中的多个 Provider 执行同一操作
+// 单个 Provider 失败不影响其他 Provider，结果逐个返回
+func (s *AdminService) BatchProviders(ctx context.Context, req *ProviderBatchRequest) (*ProviderBatchResult, error) {
+	if len(req.IDs) == 0 && req.Label == "" {
+		return nil, fmt.Errorf("%w: ids or label is required", domain.ErrInvalidInput)
+	}
+	var patch map[string]any
+	switch req.Action {
+	case ProviderBatchEnable, ProviderBatchDisable, ProviderBatchDelete, ProviderBatchValidate:
+	case ProviderBatchPatch:
+		if err := json.Unmarshal(req.Patch, &patch); err != nil || patch == nil {
+			return nil, fmt.Errorf("%w: patch must be a JSON object", domain.ErrInvalidInput)
+		}
+		for _, field := range providerBatchProtectedFields {
+			if _, ok := patch[field]; ok {
+				return nil, fmt.Errorf("%w: patch cannot change %s", domain.ErrInvalidInput, field)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown action %q", domain.ErrInvalidInput, req.Action)
+	}
+
+	targets, missing, err := s.selectBatchProviders(req)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ProviderBatchResult{Action: req.Action, Matched: len(targets)}
+	items := make([]*ProviderBatchItem, 0, len(targets)+len(missing))
+	if req.Action == ProviderBatchValidate {
+		items = s.validateBatchProviders(ctx, targets)
+	} else {
+		for _, p := range targets {
+			item := &ProviderBatchItem{ID: p.ID, Name: p.Name, Status: ProviderBatchOK}
+			if err := s.applyBatchAction(p, req.Action, patch); err == errBatchNoop {
+				item.Status = ProviderBatchSkipped
+			} else if err != nil {
+				item.Status = ProviderBatchFailed
+				item.Error = err.Error()
+			}
+			items = append(items, item)
+		}
+	}
+	items = append(items, missing...)
+
+	for _, item := range items {
+		switch item.Status {
+		case ProviderBatchOK:
+			result.Succeeded++
+		case ProviderBatchFailed:
+			result.Failed++
+		case ProviderBatchSkipped:
+			result.Skipped++
+		}
+	}
+	result.Items = items
+	log.Printf("[Provider] Batch %s: %d matched, %d succeeded, %d failed, %d skipped",
+		req.Action, result.Matched, result.Succeeded, result.Failed, result.Skipped)
+	return result, nil
+}
+
+// selectBatchProviders 返回选中的 Provider，以及不存在的 ID 对应的失败结果
+func (s *AdminService) selectBatchProviders(req *ProviderBatchRequest) ([]*domain.Provider, []*ProviderBatchItem, error) {
+	providers, err := s.providerRepo.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var targets []*domain.Provider
+	var missing []*ProviderBatchItem
+	if len(req.IDs) == 0 {
+		for _, p := range providers {
+			if p.HasLabel(req.Label) {
+				targets = append(targets, p)
+			}
+		}
+		return targets, missing, nil
+	}
+
+	byID := make(map[uint64]*domain.Provider, len(providers))
+	for _, p := range providers {
+		byID[p.ID] = p
+	}
+	seen := make(map[uint64]bool, len(req.IDs))
+	for _, id := range req.IDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		p, ok := byID[id]
+		if !ok {
+			missing = append(missing, &ProviderBatchItem{ID: id, Status: ProviderBatchFailed, Error: "provider not found"})
+			continue
+		}
+		if req.Label != "" && !p.HasLabel(req.Label) {
+			continue
+		}
+		targets = append(targets, p)
+	}
+	return targets, missing, nil
+}
+
+// applyBatchAction 对单个 Provider 执行启用 / 停用 / 删除 / Patch
+func (s *AdminService) applyBatchAction(p *domain.Provider, action string, patch map[string]any) error {
+	switch action {
+	case ProviderBatchEnable, ProviderBatchDisable:
+		disabled := action == ProviderBatchDisable
+		if p.Disabled == disabled {
+			return errBatchNoop
+		}
+		updated := *p
+		updated.Disabled = disabled
+		return s.UpdateProvider(&updated)
+	case ProviderBatchDelete:
+		return s.DeleteProvider(p.ID)
+	case ProviderBatchPatch:
+		updated, err := mergePatchProvider(p, patch)
+		if err != nil {
+			return err
+		}
+		return s.UpdateProvider(updated)
+	}
+	return nil
+}
+
+// validateBatchProviders 并发校验 refresh token，没有 refresh token 的 Provider 跳过
+func (s *AdminService) validateBatchProviders(ctx context.Context, providers []*domain.Provider) []*ProviderBatchItem {
+	s.configCheck.mu.RLock()
+	validator := s.configCheck.credentialValidator
+	s.configCheck.mu.RUnlock()
+
+	items := make([]*ProviderBatchItem, len(providers))
+	sem := make(chan struct{}, configProbeConcurrency)
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		item := &ProviderBatchItem{ID: p.ID, Name: p.Name, Status: ProviderBatchSkipped}
+		items[i] = item
+		if validator == nil || p.Config == nil || !hasRefreshToken(p) {
+			continue
+		}
+		wg.Add(1)
+		go func(p *domain.Provider, item *ProviderBatchItem) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			probeCtx, cancel := context.WithTimeout(ctxutil.WithDiagnostic(ctx, true), configProbeTimeout)
+			defer cancel()
+			if err := validator(probeCtx, p); err != nil {
+				item.Status = ProviderBatchFailed
+				item.Error = err.Error()
+				return
+			}
+			item.Status = ProviderBatchOK
+		}(p, item)
+	}
+	wg.Wait()
+	return items
+}
+
+// mergePatchProvider 按 RFC 7386 将 patch 合并到 Provider，保留 ID、类型和时间戳
+func mergePatchProvider(p *domain.Provider, patch map[string]any) (*domain.Provider, error) {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	merged, err := json.Marshal(mergePatch(doc, patch))
+	if err != nil {
+		return nil, err
+	}
+	var updated domain.Provider
+	if err := json.Unmarshal(merged, &updated); err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+	updated.ID = p.ID
+	updated.Type = p.Type
+	updated.CreatedAt = p.CreatedAt
+	updated.DeletedAt = p.DeletedAt
+	return &updated, nil
+}
+
+func mergePatch(target any, patch any) any {
+	patchObj, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	targetObj, ok := target.(map[string]any)
+	if !ok {
+		targetObj = make(map[string]any)
+	}
+	for k, v := range patchObj {
+		if v == nil {
+			delete(targetObj, k)
+			continue
+		}
+		targetObj[k] = mergePatch(targetObj[k], v)
+	}
+	return targetObj
+}
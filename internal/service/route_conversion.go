@@ -0,0 +1,125 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/awsl-project/maxx/internal/converter"
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// routeClientTypes 可以建立路由的客户端类型
+var routeClientTypes = []domain.ClientType{
+	domain.ClientTypeClaude,
+	domain.ClientTypeOpenAI,
+	domain.ClientTypeCodex,
+	domain.ClientTypeGemini,
+}
+
+// GetRouteConversions 列出项目中每个供应商与客户端类型的组合：是否原生、能否转换、是否已有路由
+// providerID 为 0 时列出所有供应商
+func (s *AdminService) GetRouteConversions(projectID, providerID uint64) ([]*domain.RouteConversion, error) {
+	providers, err := s.providerRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	routes, err := s.routeRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*domain.RouteConversion{}
+	for _, p := range providers {
+		if providerID != 0 && p.ID != providerID {
+			continue
+		}
+		for _, ct := range routeClientTypes {
+			c := routeConversion(p, ct)
+			for _, r := range routes {
+				if r.ProjectID == projectID && r.ProviderID == p.ID && r.ClientType == ct {
+					c.RouteID = r.ID
+					c.RouteEnabled = r.IsEnabled
+					break
+				}
+			}
+			result = append(result, c)
+		}
+	}
+	return result, nil
+}
+
+// routeConversion 与 Executor 相同的目标格式选择
+func routeConversion(p *domain.Provider, ct domain.ClientType) *domain.RouteConversion {
+	target := converter.PreferredTargetType(p.SupportedClientTypes, ct)
+	native := slices.Contains(p.SupportedClientTypes, ct)
+	return &domain.RouteConversion{
+		ProviderID:   p.ID,
+		ProviderName: p.Name,
+		ClientType:   ct,
+		Native:       native,
+		TargetType:   target,
+		Convertible:  native || (target != ct && converter.GetGlobalRegistry().CanConvert(ct, target)),
+	}
+}
+
+// CreateConvertedRoutes 为供应商创建转换路由；已有的路由只更新启用状态
+// 原生支持的客户端类型请使用普通路由，无法转换的类型会被拒绝
+func (s *AdminService) CreateConvertedRoutes(req *domain.ConvertedRoutesRequest) ([]*domain.Route, error) {
+	if len(req.ClientTypes) == 0 {
+		return nil, fmt.Errorf("%w: clientTypes is required", domain.ErrInvalidInput)
+	}
+	provider, err := s.providerRepo.GetByID(req.ProviderID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: provider %d not found", domain.ErrInvalidInput, req.ProviderID)
+	}
+	for _, ct := range req.ClientTypes {
+		c := routeConversion(provider, ct)
+		switch {
+		case !slices.Contains(routeClientTypes, ct):
+			return nil, fmt.Errorf("%w: unknown client type %q", domain.ErrInvalidInput, ct)
+		case c.Native:
+			return nil, fmt.Errorf("%w: %s supports %s natively, no conversion needed", domain.ErrInvalidInput, provider.Name, ct)
+		case !c.Convertible:
+			return nil, fmt.Errorf("%w: %s requests can't be converted to %s for %s", domain.ErrInvalidInput, ct, c.TargetType, provider.Name)
+		}
+	}
+
+	routes, err := s.routeRepo.List()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*domain.Route, 0, len(req.ClientTypes))
+	for _, ct := range req.ClientTypes {
+		route, _ := s.routeRepo.FindByKey(req.ProjectID, req.ProviderID, ct)
+		if route != nil {
+			route.IsEnabled = req.IsEnabled
+			if err := s.routeRepo.Update(route); err != nil {
+				return nil, err
+			}
+			result = append(result, route)
+			continue
+		}
+
+		// 排在该客户端类型已有路由之后
+		position := 1
+		for _, r := range routes {
+			if r.ProjectID == req.ProjectID && r.ClientType == ct && r.Position >= position {
+				position = r.Position + 1
+			}
+		}
+		route = &domain.Route{
+			IsEnabled:  req.IsEnabled,
+			IsNative:   false,
+			ProjectID:  req.ProjectID,
+			ClientType: ct,
+			ProviderID: req.ProviderID,
+			Position:   position,
+		}
+		if err := s.routeRepo.Create(route); err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+		result = append(result, route)
+	}
+	return result, nil
+}
@@ -0,0 +1,144 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/routingprofile"
+)
+
+// ===== RoutingProfile API =====
+
+func (s *AdminService) GetRoutingProfiles() ([]*domain.RoutingProfile, error) {
+	return s.routingProfileRepo.List()
+}
+
+func (s *AdminService) GetRoutingProfile(id uint64) (*domain.RoutingProfile, error) {
+	return s.routingProfileRepo.GetByID(id)
+}
+
+func (s *AdminService) CreateRoutingProfile(profile *domain.RoutingProfile) error {
+	if err := validateRoutingProfile(profile); err != nil {
+		return err
+	}
+	return s.routingProfileRepo.Create(profile)
+}
+
+func (s *AdminService) UpdateRoutingProfile(profile *domain.RoutingProfile) error {
+	if err := validateRoutingProfile(profile); err != nil {
+		return err
+	}
+	return s.routingProfileRepo.Update(profile)
+}
+
+// DeleteRoutingProfile 删除路由方案；删除的是当前方案时清除当前方案记录，路由状态保持不变
+func (s *AdminService) DeleteRoutingProfile(id uint64) error {
+	if err := s.routingProfileRepo.Delete(id); err != nil {
+		return err
+	}
+	if s.ActiveRoutingProfileID() == id {
+		return s.DeleteSetting(domain.SettingKeyActiveRoutingProfile)
+	}
+	return nil
+}
+
+func validateRoutingProfile(profile *domain.RoutingProfile) error {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if profile.Name == "" {
+		return fmt.Errorf("%w: name is required", domain.ErrInvalidInput)
+	}
+	if err := routingprofile.Validate(profile.Schedules); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrInvalidInput, err)
+	}
+	seen := make(map[uint64]bool, len(profile.Routes))
+	for _, r := range profile.Routes {
+		if seen[r.RouteID] {
+			return fmt.Errorf("%w: route %d is listed more than once", domain.ErrInvalidInput, r.RouteID)
+		}
+		seen[r.RouteID] = true
+	}
+	return nil
+}
+
+// ApplyRoutingProfile 将路由方案中的启用状态和位置写入路由，并记为当前方案
+// 方案中已删除的路由会被跳过
+func (s *AdminService) ApplyRoutingProfile(id uint64) (*domain.RoutingProfile, error) {
+	profile, err := s.routingProfileRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := 0
+	for _, pr := range profile.Routes {
+		route, err := s.routeRepo.GetByID(pr.RouteID)
+		if err != nil || route.DeletedAt != nil {
+			continue
+		}
+		if route.IsEnabled == pr.IsEnabled && route.Position == pr.Position {
+			continue
+		}
+		updated := *route
+		updated.IsEnabled = pr.IsEnabled
+		updated.Position = pr.Position
+		if err := s.routeRepo.Update(&updated); err != nil {
+			return nil, fmt.Errorf("update route %d: %w", pr.RouteID, err)
+		}
+		changed++
+	}
+
+	if err := s.UpdateSetting(domain.SettingKeyActiveRoutingProfile, strconv.FormatUint(id, 10)); err != nil {
+		return nil, err
+	}
+	log.Printf("[RoutingProfile] Applied profile %d (%s), %d routes changed", profile.ID, profile.Name, changed)
+	return profile, nil
+}
+
+// CaptureRoutingProfile 用当前所有路由的启用状态和位置覆盖方案中的路由列表
+func (s *AdminService) CaptureRoutingProfile(id uint64) (*domain.RoutingProfile, error) {
+	profile, err := s.routingProfileRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	routes, err := s.routeRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	captured := *profile
+	captured.Routes = make([]domain.RoutingProfileRoute, 0, len(routes))
+	for _, route := range routes {
+		if route.DeletedAt != nil {
+			continue
+		}
+		captured.Routes = append(captured.Routes, domain.RoutingProfileRoute{
+			RouteID:   route.ID,
+			IsEnabled: route.IsEnabled,
+			Position:  route.Position,
+		})
+	}
+	if err := s.routingProfileRepo.Update(&captured); err != nil {
+		return nil, err
+	}
+	return &captured, nil
+}
+
+// ActiveRoutingProfileID 返回最近一次应用的路由方案 ID，0 表示未应用
+func (s *AdminService) ActiveRoutingProfileID() uint64 {
+	val, err := s.settingRepo.Get(domain.SettingKeyActiveRoutingProfile)
+	if err != nil {
+		return 0
+	}
+	id, _ := strconv.ParseUint(val, 10, 64)
+	return id
+}
+
+// GetActiveRoutingProfile 返回当前方案和时间表匹配的方案
+func (s *AdminService) GetActiveRoutingProfile() *domain.ActiveRoutingProfile {
+	return &domain.ActiveRoutingProfile{
+		ProfileID:          s.ActiveRoutingProfileID(),
+		ScheduledProfileID: routingprofile.Default().Scheduled(),
+	}
+}
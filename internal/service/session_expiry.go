@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+// ===== Session Expiry API =====
+
+// SessionsExpiredMessageType 空闲会话被解除绑定或归档后广播的事件类型
+const SessionsExpiredMessageType = "sessions_expired"
+
+// SessionIdleConfig 返回空闲会话配置：空闲小时数（0 表示关闭）和处理方式
+func (s *AdminService) SessionIdleConfig() (int, string) {
+	hours := 0
+	if val, err := s.settingRepo.Get(domain.SettingKeySessionIdleHours); err == nil {
+		if n, err := strconv.Atoi(val); err == nil && n > 0 {
+			hours = n
+		}
+	}
+	action := domain.SessionIdleActionUnbind
+	if val, err := s.settingRepo.Get(domain.SettingKeySessionIdleAction); err == nil && val == domain.SessionIdleActionArchive {
+		action = domain.SessionIdleActionArchive
+	}
+	return hours, action
+}
+
+// ExpireIdleSessions 处理超过 idleHours 小时没有请求的会话
+// unbind 清除项目绑定和固定路由；archive 还会标记归档，会话列表默认不再显示
+// 已拒绝的会话和已归档的会话会被跳过
+func (s *AdminService) ExpireIdleSessions(idleHours int, action string) (*domain.SessionExpiryResult, error) {
+	if idleHours <= 0 {
+		return nil, fmt.Errorf("%w: idleHours must be positive", domain.ErrInvalidInput)
+	}
+	if action != domain.SessionIdleActionUnbind && action != domain.SessionIdleActionArchive {
+		return nil, fmt.Errorf("%w: unknown action %q", domain.ErrInvalidInput, action)
+	}
+
+	sessions, err := s.sessionRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(idleHours) * time.Hour)
+	result := &domain.SessionExpiryResult{
+		Action:     action,
+		IdleHours:  idleHours,
+		SessionIDs: []string{},
+	}
+	for _, session := range sessions {
+		if session.RejectedAt != nil || session.ArchivedAt != nil || !session.LastActivity().Before(cutoff) {
+			continue
+		}
+		bound := session.ProjectID != 0 || session.PinnedRouteID != 0 || session.PinnedProviderID != 0
+		if action == domain.SessionIdleActionUnbind && !bound {
+			continue
+		}
+
+		session.ProjectID = 0
+		session.PinnedRouteID = 0
+		session.PinnedProviderID = 0
+		if action == domain.SessionIdleActionArchive {
+			archivedAt := now
+			session.ArchivedAt = &archivedAt
+		}
+		if err := s.sessionRepo.Update(session); err != nil {
+			return nil, err
+		}
+		result.SessionIDs = append(result.SessionIDs, session.SessionID)
+	}
+
+	if len(result.SessionIDs) > 0 {
+		log.Printf("[Session] Expired %d sessions idle for more than %dh (action=%s)", len(result.SessionIDs), idleHours, action)
+	}
+	return result, nil
+}
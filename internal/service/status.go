@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/cooldown"
+	"github.com/awsl-project/maxx/internal/domain"
+	"github.com/awsl-project/maxx/internal/failback"
+	"github.com/awsl-project/maxx/internal/repository"
+)
+
+// ===== Public Status API =====
+
+// StatusPageEnabled 公开状态页是否开启，默认关闭，需显式设为 true
+func (s *AdminService) StatusPageEnabled() bool {
+	val, err := s.settingRepo.Get(domain.SettingKeyStatusPage)
+	return err == nil && val == "true"
+}
+
+// GetPublicStatus 汇总公开状态页的数据：Provider 健康状态、冷却和最近 1 小时 / 24 小时的吞吐量
+// 只使用预聚合的统计数据，不读取请求记录；Provider 名称（常为账号邮箱）不公开，按 ID 顺序编号
+func (s *AdminService) GetPublicStatus() (*domain.PublicStatus, error) {
+	providers, err := s.providerRepo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	status := &domain.PublicStatus{
+		GeneratedAt: now,
+		Providers:   make([]*domain.PublicProviderStatus, 0, len(providers)),
+	}
+
+	// 每个 provider 取最晚结束的有效冷却
+	cooldowns := make(map[uint64]*domain.Cooldown)
+	if list, err := cooldown.Default().GetAllCooldownsFromDB(); err == nil {
+		for _, cd := range list {
+			if !cd.UntilTime.After(now) {
+				continue
+			}
+			if existing, ok := cooldowns[cd.ProviderID]; !ok || cd.UntilTime.After(existing.UntilTime) {
+				cooldowns[cd.ProviderID] = cd
+			}
+		}
+	}
+
+	dayStart := now.Add(-24 * time.Hour)
+	byProvider, err := s.usageStatsRepo.GetSummaryByProvider(repository.UsageStatsFilter{
+		Granularity: domain.GranularityHour,
+		StartTime:   &dayStart,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	providers = append([]*domain.Provider(nil), providers...)
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].ID < providers[j].ID
+	})
+
+	var cooling int
+	for i, p := range providers {
+		ps := &domain.PublicProviderStatus{
+			Name:   fmt.Sprintf("Provider %d", i+1),
+			Type:   p.Type,
+			Status: domain.PublicProviderActive,
+		}
+		if cd, ok := cooldowns[p.ID]; ok {
+			until := cd.UntilTime
+			ps.Status = domain.PublicProviderCooldown
+			ps.CooldownUntil = &until
+			ps.CooldownReason = cd.Reason
+			cooling++
+		} else if failback.Default().IsDemoted(p.ID) {
+			ps.Status = domain.PublicProviderRecovering
+		}
+		if summary := byProvider[p.ID]; summary != nil {
+			ps.Requests24h = summary.TotalRequests
+			ps.SuccessRate24h = successRate(summary)
+		}
+		status.Providers = append(status.Providers, ps)
+	}
+
+	switch {
+	case len(providers) == 0:
+		status.Status = domain.PublicStatusUnknown
+	case cooling == len(providers):
+		status.Status = domain.PublicStatusDown
+	default:
+		status.Status = domain.PublicStatusOperational
+		for _, ps := range status.Providers {
+			if ps.Status != domain.PublicProviderActive {
+				status.Status = domain.PublicStatusDegraded
+				break
+			}
+		}
+	}
+
+	hourStart := now.Add(-time.Hour)
+	if status.LastHour, err = s.publicThroughput(domain.GranularityMinute, hourStart, time.Hour); err != nil {
+		return nil, err
+	}
+	if status.Last24h, err = s.publicThroughput(domain.GranularityHour, dayStart, 24*time.Hour); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (s *AdminService) publicThroughput(granularity domain.Granularity, start time.Time, window time.Duration) (domain.PublicThroughput, error) {
+	summary, err := s.usageStatsRepo.GetSummary(repository.UsageStatsFilter{
+		Granularity: granularity,
+		StartTime:   &start,
+	})
+	if err != nil || summary == nil {
+		return domain.PublicThroughput{}, err
+	}
+	return domain.PublicThroughput{
+		Requests:          summary.TotalRequests,
+		SuccessRate:       successRate(summary),
+		RequestsPerMinute: float64(summary.TotalRequests) / window.Minutes(),
+		InputTokens:       summary.TotalInputTokens,
+		OutputTokens:      summary.TotalOutputTokens,
+	}, nil
+}
+
+func successRate(summary *domain.UsageStatsSummary) float64 {
+	if summary.TotalRequests == 0 {
+		return 0
+	}
+	return float64(summary.SuccessfulRequests) / float64(summary.TotalRequests) * 100
+}
@@ -0,0 +1,233 @@
+// Package slo tracks upstream latency against user-defined latency SLOs. Completed
+// attempts are recorded with their TTFB and duration; each SLO is evaluated per provider
+// over its window, and a violation that persists is reported once, as is its recovery.
+package slo
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/domain"
+)
+
+const (
+	// MaxWindow is the longest SLO window; older samples are dropped
+	MaxWindow  = 24 * time.Hour
+	maxSamples = 200000
+)
+
+type sample struct {
+	at           time.Time
+	providerID   uint64
+	requestModel string
+	mappedModel  string
+	ttfb         time.Duration
+	duration     time.Duration
+}
+
+type stateKey struct {
+	sloID      uint64
+	providerID uint64
+}
+
+type state struct {
+	since      time.Time
+	persistent bool
+}
+
+// Tracker keeps the recent latency samples of all providers
+type Tracker struct {
+	mu      sync.Mutex
+	samples []sample // In time order
+	states  map[stateKey]*state
+	now     func() time.Time
+}
+
+// NewTracker creates an empty tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		states: make(map[stateKey]*state),
+		now:    time.Now,
+	}
+}
+
+var (
+	defaultTracker     *Tracker
+	defaultTrackerOnce sync.Once
+)
+
+// Default returns the process-wide tracker
+func Default() *Tracker {
+	defaultTrackerOnce.Do(func() {
+		defaultTracker = NewTracker()
+	})
+	return defaultTracker
+}
+
+// Record adds the latency of a finished attempt, successful or failed. ttfb is 0 when it
+// wasn't measured and is then left out of TTFB SLOs; failed attempts that never got a
+// first byte are recorded with their duration as ttfb.
+func (t *Tracker) Record(providerID uint64, requestModel, mappedModel string, ttfb, duration time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.samples = append(t.samples, sample{
+		at:           now,
+		providerID:   providerID,
+		requestModel: requestModel,
+		mappedModel:  mappedModel,
+		ttfb:         ttfb,
+		duration:     duration,
+	})
+
+	cutoff := now.Add(-MaxWindow)
+	drop := 0
+	for drop < len(t.samples) && (t.samples[drop].at.Before(cutoff) || len(t.samples)-drop > maxSamples) {
+		drop++
+	}
+	if drop > 0 {
+		t.samples = append(t.samples[:0:0], t.samples[drop:]...)
+	}
+}
+
+// Evaluate returns the current status of each SLO: one per provider with samples in the
+// window, or a single one for an SLO bound to a provider. It doesn't change any state.
+func (t *Tracker) Evaluate(slos []*domain.LatencySLO) []*domain.LatencySLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.evaluate(slos, t.now())
+}
+
+// Check evaluates the SLOs and returns an event for each provider whose violation has now
+// lasted longer than the SLO's persistence, and for each persistent violation that ended
+func (t *Tracker) Check(slos []*domain.LatencySLO) []*domain.LatencySLOEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	var events []*domain.LatencySLOEvent
+	seen := make(map[stateKey]bool)
+	persistence := make(map[uint64]time.Duration, len(slos))
+	for _, s := range slos {
+		persistence[s.ID] = s.Persistence
+	}
+
+	for _, status := range t.evaluate(slos, now) {
+		key := stateKey{sloID: status.SLOID, providerID: status.ProviderID}
+		seen[key] = true
+		st := t.states[key]
+		if !status.Violating {
+			if st != nil && st.persistent {
+				status.ViolatingSince = &st.since
+				events = append(events, &domain.LatencySLOEvent{LatencySLOStatus: status, Kind: domain.LatencySLOEventRecovered, TriggeredAt: now})
+			}
+			delete(t.states, key)
+			continue
+		}
+		if st == nil {
+			st = &state{since: now}
+			t.states[key] = st
+		}
+		since := st.since
+		status.ViolatingSince = &since
+		if !st.persistent && now.Sub(st.since) >= persistence[status.SLOID] {
+			st.persistent = true
+			status.Persistent = true
+			events = append(events, &domain.LatencySLOEvent{LatencySLOStatus: status, Kind: domain.LatencySLOEventViolation, TriggeredAt: now})
+		}
+	}
+
+	// SLOs that were deleted or disabled, and providers without traffic in the window
+	for key := range t.states {
+		if !seen[key] {
+			delete(t.states, key)
+		}
+	}
+	return events
+}
+
+// evaluate computes the statuses at now. Caller holds t.mu.
+func (t *Tracker) evaluate(slos []*domain.LatencySLO, now time.Time) []*domain.LatencySLOStatus {
+	var statuses []*domain.LatencySLOStatus
+	for _, s := range slos {
+		values := make(map[uint64][]time.Duration)
+		if s.ProviderID != 0 {
+			values[s.ProviderID] = nil
+		}
+		cutoff := now.Add(-s.Window)
+		for i := len(t.samples) - 1; i >= 0 && t.samples[i].at.After(cutoff); i-- {
+			sm := &t.samples[i]
+			if s.ProviderID != 0 && sm.providerID != s.ProviderID {
+				continue
+			}
+			if s.Model != "" && !domain.MatchWildcard(s.Model, sm.requestModel) && !domain.MatchWildcard(s.Model, sm.mappedModel) {
+				continue
+			}
+			value := sm.duration
+			if s.Metric == domain.LatencySLOMetricTTFB {
+				if sm.ttfb <= 0 {
+					continue
+				}
+				value = sm.ttfb
+			}
+			values[sm.providerID] = append(values[sm.providerID], value)
+		}
+
+		providerIDs := make([]uint64, 0, len(values))
+		for id := range values {
+			providerIDs = append(providerIDs, id)
+		}
+		sort.Slice(providerIDs, func(i, j int) bool { return providerIDs[i] < providerIDs[j] })
+
+		for _, providerID := range providerIDs {
+			statuses = append(statuses, newStatus(s, providerID, values[providerID], t.states[stateKey{sloID: s.ID, providerID: providerID}]))
+		}
+	}
+	return statuses
+}
+
+func newStatus(s *domain.LatencySLO, providerID uint64, values []time.Duration, st *state) *domain.LatencySLOStatus {
+	status := &domain.LatencySLOStatus{
+		SLOID:      s.ID,
+		SLOName:    s.Name,
+		ProviderID: providerID,
+		Model:      s.Model,
+		Metric:     s.Metric,
+		Percentile: s.Percentile,
+		Target:     s.Target,
+		Samples:    len(values),
+	}
+	if len(values) == 0 {
+		return status
+	}
+
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	status.Value = Percentile(values, s.Percentile)
+	within := sort.Search(len(values), func(i int) bool { return values[i] > s.Target })
+	status.Compliance = float64(within) / float64(len(values))
+	status.Violating = len(values) >= s.MinSamples && status.Value > s.Target
+	if status.Violating && st != nil {
+		since := st.since
+		status.ViolatingSince = &since
+		status.Persistent = st.persistent
+	}
+	return status
+}
+
+// Percentile returns the nearest-rank percentile p (0-100] of sorted values
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
@@ -1,7 +1,6 @@
-package antigravity
+package thinking
 
 import (
-	"strings"
 	"sync"
 	"time"
 )
@@ -28,15 +27,6 @@ const (
 	// SignatureCacheTTL follows Antigravity-Manager (2 hours)
 	SignatureCacheTTL = 2 * time.Hour
 
-	// MinSignatureLength is the minimum length for a valid thought signature
-	// [Aligned with Antigravity-Manager/src-tauri/src/proxy/signature_cache.rs]
-	MinSignatureLength = 50
-
-	// MinThinkingSignatureLength is the minimum length treated as a "valid" thinking signature
-	// when filtering/cleaning Claude history.
-	// [Aligned with Antigravity-Manager/src-tauri/src/proxy/handlers/claude.rs]
-	MinThinkingSignatureLength = 10
-
 	// signatureCacheMaxEntries matches Antigravity-Manager's simple cleanup strategy.
 	signatureCacheMaxEntries = 1000
 )
@@ -59,15 +49,9 @@ func (e signatureCacheEntry) expired(now time.Time) bool {
 	return now.Sub(e.timestamp) > SignatureCacheTTL
 }
 
-// HasValidSignature checks if a signature is valid (non-empty and long enough)
-// (like CLIProxyAPI's HasValidSignature)
-func HasValidSignature(signature string) bool {
-	return signature != "" && len(signature) >= MinSignatureLength
-}
-
 // CacheToolSignature stores a signature for a specific tool call ID (Layer 1).
 func (c *SignatureCache) CacheToolSignature(toolID, signature string) {
-	if signature == "" || len(signature) < MinSignatureLength {
+	if !HasValidSignature(signature) {
 		return
 	}
 
@@ -105,7 +89,7 @@ func (c *SignatureCache) GetToolSignature(toolID string) string {
 
 // CacheThinkingFamily stores model family for a signature (Layer 2).
 func (c *SignatureCache) CacheThinkingFamily(signature, family string) {
-	if signature == "" || len(signature) < MinSignatureLength {
+	if !HasValidSignature(signature) {
 		return
 	}
 
@@ -148,30 +132,3 @@ func (c *SignatureCache) Clear() {
 	c.toolSignatures = make(map[string]signatureCacheEntry)
 	c.thinkingFamilies = make(map[string]signatureCacheEntry)
 }
-
-// IsModelCompatible checks if two models are compatible (same family)
-func IsModelCompatible(cached, target string) bool {
-	c := strings.ToLower(cached)
-	t := strings.ToLower(target)
-
-	if c == t {
-		return true
-	}
-
-	// Check specific families
-	if strings.Contains(c, "gemini-1.5") && strings.Contains(t, "gemini-1.5") {
-		return true
-	}
-	if strings.Contains(c, "gemini-2.0") && strings.Contains(t, "gemini-2.0") {
-		return true
-	}
-	if strings.Contains(c, "claude-3-5") && strings.Contains(t, "claude-3-5") {
-		return true
-	}
-	if strings.Contains(c, "claude-3-7") && strings.Contains(t, "claude-3-7") {
-		return true
-	}
-
-	// Fallback: strict match required
-	return false
-}
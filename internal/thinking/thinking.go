@@ -0,0 +1,246 @@
+// Package thinking normalizes Claude thinking blocks in conversation history before it is sent
+// to a Gemini upstream. It is shared by the format converter and the Antigravity adapter, which
+// follow Antigravity-Manager's rules for invalid, unsigned and incompatible thinking history.
+package thinking
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/awsl-project/maxx/internal/capability"
+)
+
+const (
+	// MinSignatureLength is the minimum length of a thought signature that can back
+	// function calls or be cached.
+	// [Aligned with Antigravity-Manager/src-tauri/src/proxy/signature_cache.rs]
+	MinSignatureLength = 50
+
+	// MinBlockSignatureLength is the minimum signature length for a thinking block with content
+	// to be kept in history.
+	// [Aligned with Antigravity-Manager/src-tauri/src/proxy/handlers/claude.rs]
+	MinBlockSignatureLength = 10
+)
+
+// Message is a Claude message as seen by the history checks
+type Message struct {
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"` // string or content blocks
+}
+
+// HasValidSignature reports whether signature is long enough to back function calls
+func HasValidSignature(signature string) bool {
+	return len(signature) >= MinSignatureLength
+}
+
+// HasValidBlockSignature reports whether a thinking block can be kept in history.
+// An empty thinking block with any signature is valid (trailing signature case).
+func HasValidBlockSignature(thinking, signature string) bool {
+	if thinking == "" && signature != "" {
+		return true
+	}
+	return len(signature) >= MinBlockSignatureLength
+}
+
+// FilterInvalidBlocks fixes thinking blocks with invalid signatures in assistant/model messages
+// (like Antigravity-Manager's filter_invalid_thinking_blocks):
+// invalid blocks with content are downgraded to text, empty ones are dropped, and a message left
+// without content gets an empty text block. Returns the number of blocks dropped.
+func FilterInvalidBlocks(messages []Message) int {
+	totalFiltered := 0
+
+	for i := range messages {
+		msg := &messages[i]
+		if !isAssistant(msg.Role) {
+			continue
+		}
+
+		blocks := contentBlocks(msg.Content)
+		if blocks == nil {
+			continue
+		}
+
+		newBlocks := make([]interface{}, 0, len(blocks))
+		for _, block := range blocks {
+			m, ok := block.(map[string]interface{})
+			if !ok || m["type"] != "thinking" {
+				newBlocks = append(newBlocks, block)
+				continue
+			}
+
+			thinking, _ := m["thinking"].(string)
+			signature, _ := m["signature"].(string)
+			if HasValidBlockSignature(thinking, signature) {
+				// cache_control must not be forwarded on thinking blocks
+				delete(m, "cache_control")
+				newBlocks = append(newBlocks, m)
+				continue
+			}
+
+			// Invalid signature: preserve the content as text
+			if strings.TrimSpace(thinking) != "" {
+				newBlocks = append(newBlocks, map[string]interface{}{
+					"type": "text",
+					"text": thinking,
+				})
+			}
+		}
+
+		totalFiltered += len(blocks) - len(newBlocks)
+
+		if len(newBlocks) == 0 {
+			newBlocks = append(newBlocks, map[string]interface{}{
+				"type": "text",
+				"text": "",
+			})
+		}
+		msg.Content = newBlocks
+	}
+
+	return totalFiltered
+}
+
+// RemoveTrailingUnsigned removes thinking blocks without a valid signature from the end of
+// assistant/model messages (like Antigravity-Manager's remove_trailing_unsigned_thinking)
+func RemoveTrailingUnsigned(messages []Message) {
+	for i := range messages {
+		msg := &messages[i]
+		if !isAssistant(msg.Role) {
+			continue
+		}
+
+		blocks := contentBlocks(msg.Content)
+		if len(blocks) == 0 {
+			continue
+		}
+
+		endIndex := len(blocks)
+		for j := len(blocks) - 1; j >= 0; j-- {
+			m, ok := blocks[j].(map[string]interface{})
+			if !ok || m["type"] != "thinking" {
+				break
+			}
+			thinking, _ := m["thinking"].(string)
+			signature, _ := m["signature"].(string)
+			if HasValidBlockSignature(thinking, signature) {
+				break
+			}
+			endIndex = j
+		}
+
+		if endIndex < len(blocks) {
+			msg.Content = blocks[:endIndex]
+		}
+	}
+}
+
+// ShouldDisableDueToHistory reports whether thinking must be disabled because the last
+// assistant message has tool calls but no thinking block
+// (like Antigravity-Manager's should_disable_thinking_due_to_history)
+func ShouldDisableDueToHistory(messages []Message) bool {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "assistant" {
+			continue
+		}
+		// Only the last assistant message is checked
+		return hasBlock(messages[i].Content, "tool_use") && !hasBlock(messages[i].Content, "thinking")
+	}
+	return false
+}
+
+// HasValidSignatureForFunctionCalls reports whether a signature is available for function calls,
+// either globalSig or one from a thinking block in history.
+// [FIX #295] Prevents Gemini 3 Pro from rejecting requests due to missing thought_signature
+func HasValidSignatureForFunctionCalls(messages []Message, globalSig string) bool {
+	if HasValidSignature(globalSig) {
+		return true
+	}
+
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role != "assistant" {
+			continue
+		}
+		for _, block := range contentBlocks(messages[i].Content) {
+			m, ok := block.(map[string]interface{})
+			if !ok || m["type"] != "thinking" {
+				continue
+			}
+			if signature, _ := m["signature"].(string); HasValidSignature(signature) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasHistory reports whether any assistant message contains a thinking block
+func HasHistory(messages []Message) bool {
+	for _, msg := range messages {
+		if msg.Role == "assistant" && hasBlock(msg.Content, "thinking") {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFunctionCalls reports whether any message contains a tool_use block
+func HasFunctionCalls(messages []Message) bool {
+	for _, msg := range messages {
+		if hasBlock(msg.Content, "tool_use") {
+			return true
+		}
+	}
+	return false
+}
+
+// EnabledByDefault reports whether a model has thinking on without an explicit thinking config.
+// Claude Code v2.0.67+ enables thinking by default for Opus 4.5 models.
+func EnabledByDefault(model string) bool {
+	modelLower := strings.ToLower(model)
+	if strings.Contains(modelLower, "opus-4-5") || strings.Contains(modelLower, "opus-4.5") {
+		return true
+	}
+	return strings.Contains(modelLower, "-thinking")
+}
+
+// TargetModelSupports reports whether the upstream model supports thinking on a provider
+// (like Antigravity-Manager's target_model_supports_thinking), per the capability registry,
+// so provider-specific capability rules apply. Regular Gemini models need an explicit
+// "-thinking" suffix.
+func TargetModelSupports(providerID uint64, mappedModel string) bool {
+	return capability.Supported(capability.Default().Lookup(providerID, mappedModel).SupportsThinking)
+}
+
+func isAssistant(role string) bool {
+	return role == "assistant" || role == "model"
+}
+
+func hasBlock(content interface{}, blockType string) bool {
+	for _, block := range contentBlocks(content) {
+		if m, ok := block.(map[string]interface{}); ok && m["type"] == blockType {
+			return true
+		}
+	}
+	return false
+}
+
+// contentBlocks returns the content blocks of a message, or nil for string content.
+// Typed block slices are converted to their JSON form.
+func contentBlocks(content interface{}) []interface{} {
+	switch c := content.(type) {
+	case []interface{}:
+		return c
+	case nil, string:
+		return nil
+	default:
+		raw, err := json.Marshal(c)
+		if err != nil {
+			return nil
+		}
+		var blocks []interface{}
+		if err := json.Unmarshal(raw, &blocks); err != nil {
+			return nil
+		}
+		return blocks
+	}
+}
@@ -0,0 +1,20 @@
+//go:build darwin
+
+package updater
+
+import (
+	"fmt"
+	"os/exec"
+)
+
+// Install 打开下载的 dmg，由用户拖拽到 Applications 完成安装
+// 返回 false 表示当前进程无需退出
+func Install(path string) (bool, error) {
+	if err := exec.Command("open", path).Start(); err != nil {
+		return false, fmt.Errorf("failed to open installer: %w", err)
+	}
+	return false, nil
+}
+
+// CleanupOldBinary macOS 无需清理
+func CleanupOldBinary() {}
@@ -0,0 +1,13 @@
+//go:build !windows && !darwin
+
+package updater
+
+import "fmt"
+
+// Install 当前平台不支持自动安装，请手动替换二进制文件
+func Install(path string) (bool, error) {
+	return false, fmt.Errorf("automatic install is not supported on this platform, installer downloaded to %s", path)
+}
+
+// CleanupOldBinary 当前平台无需清理
+func CleanupOldBinary() {}
@@ -0,0 +1,70 @@
+//go:build windows
+
+package updater
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Install 替换当前可执行文件并安排重新启动
+// 运行中的 exe 无法覆盖但可以重命名，旧文件保留为 .old 并在下次启动时清理
+// 新进程延迟几秒启动，避免被当前实例的单实例锁拦截
+// 返回 true 表示已安排重启，调用方应尽快退出当前进程
+func Install(path string) (bool, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return false, fmt.Errorf("failed to locate executable: %w", err)
+	}
+
+	oldPath := exe + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(exe, oldPath); err != nil {
+		return false, fmt.Errorf("failed to move current executable: %w", err)
+	}
+
+	if err := copyFile(path, exe); err != nil {
+		// 回滚
+		os.Rename(oldPath, exe)
+		return false, fmt.Errorf("failed to install update: %w", err)
+	}
+
+	// cmd 的引号规则与 Go 的参数转义不兼容，直接指定完整命令行
+	relaunch := exec.Command("cmd")
+	relaunch.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine:    `cmd /C ping -n 4 127.0.0.1 >nul & start "" "` + exe + `"`,
+		HideWindow: true,
+	}
+	if err := relaunch.Start(); err != nil {
+		return false, fmt.Errorf("update installed but failed to restart: %w", err)
+	}
+	return true, nil
+}
+
+// CleanupOldBinary 删除上次更新遗留的旧可执行文件
+func CleanupOldBinary() {
+	if exe, err := os.Executable(); err == nil {
+		os.Remove(exe + ".old")
+	}
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,286 @@
+package updater
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/awsl-project/maxx/internal/version"
+)
+
+// LatestReleaseURL GitHub 最新 release 接口
+const LatestReleaseURL = "https://api.github.com/repos/awsl-project/maxx/releases/latest"
+
+// Asset release 附件
+type Asset struct {
+	Name        string `json:"name"`
+	DownloadURL string `json:"downloadURL"`
+	Size        int64  `json:"size"`
+}
+
+// Release release 信息
+type Release struct {
+	Version     string    `json:"version"`
+	Name        string    `json:"name"`
+	Changelog   string    `json:"changelog"` // release 正文（Markdown）
+	URL         string    `json:"url"`       // release 页面地址
+	PublishedAt time.Time `json:"publishedAt"`
+	Assets      []Asset   `json:"assets"`
+}
+
+// UpdateInfo 更新检查结果
+type UpdateInfo struct {
+	CurrentVersion string   `json:"currentVersion"`
+	LatestVersion  string   `json:"latestVersion"`
+	HasUpdate      bool     `json:"hasUpdate"`
+	Release        *Release `json:"release,omitempty"`
+	Asset          *Asset   `json:"asset,omitempty"` // 当前平台对应的安装包，nil 表示不支持自动安装（包括没有校验文件）
+}
+
+// githubRelease GitHub API 返回结构
+type githubRelease struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	Body        string    `json:"body"`
+	HTMLURL     string    `json:"html_url"`
+	Draft       bool      `json:"draft"`
+	Prerelease  bool      `json:"prerelease"`
+	PublishedAt time.Time `json:"published_at"`
+	Assets      []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+		Size               int64  `json:"size"`
+	} `json:"assets"`
+}
+
+var httpClient = &http.Client{Timeout: 5 * time.Minute}
+
+// CheckForUpdates 查询 GitHub 最新 release 并与当前版本比较
+func CheckForUpdates(ctx context.Context) (*UpdateInfo, error) {
+	release, err := FetchLatestRelease(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &UpdateInfo{
+		CurrentVersion: version.Version,
+		LatestVersion:  release.Version,
+		Release:        release,
+		Asset:          release.AssetFor(runtime.GOOS, runtime.GOARCH),
+	}
+	// 开发版本无法比较，不提示更新
+	if version.Version != "dev" {
+		info.HasUpdate = CompareVersions(release.Version, version.Version) > 0
+	}
+	return info, nil
+}
+
+// FetchLatestRelease 获取最新 release
+func FetchLatestRelease(ctx context.Context) (*Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, LatestReleaseURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "maxx/"+version.Version)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("failed to fetch latest release: HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var gr githubRelease
+	if err := json.NewDecoder(resp.Body).Decode(&gr); err != nil {
+		return nil, fmt.Errorf("failed to parse release: %w", err)
+	}
+
+	release := &Release{
+		Version:     strings.TrimPrefix(gr.TagName, "v"),
+		Name:        gr.Name,
+		Changelog:   gr.Body,
+		URL:         gr.HTMLURL,
+		PublishedAt: gr.PublishedAt,
+	}
+	for _, a := range gr.Assets {
+		release.Assets = append(release.Assets, Asset{
+			Name:        a.Name,
+			DownloadURL: a.BrowserDownloadURL,
+			Size:        a.Size,
+		})
+	}
+	return release, nil
+}
+
+// AssetName 返回指定平台的安装包文件名（与 CI 构建产物一致）
+func AssetName(goos, goarch string) string {
+	switch goos {
+	case "windows":
+		return "maxx.exe"
+	case "darwin":
+		return fmt.Sprintf("maxx-macOS-%s.dmg", goarch)
+	case "linux":
+		return "maxx"
+	}
+	return ""
+}
+
+// AssetFor 返回指定平台的安装包；没有同名 .sha256 校验文件的安装包无法校验，不能自动安装，返回 nil
+func (r *Release) AssetFor(goos, goarch string) *Asset {
+	asset := r.findAsset(AssetName(goos, goarch))
+	if asset == nil || r.findAsset(asset.Name+".sha256") == nil {
+		return nil
+	}
+	return asset
+}
+
+func (r *Release) findAsset(name string) *Asset {
+	if name == "" {
+		return nil
+	}
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// Download 下载安装包到 destDir，并使用同名 .sha256 附件校验；没有校验文件时拒绝下载
+func (r *Release) Download(ctx context.Context, asset *Asset, destDir string) (string, error) {
+	if asset == nil {
+		return "", fmt.Errorf("no installer available for this platform")
+	}
+	checksum := r.findAsset(asset.Name + ".sha256")
+	if checksum == nil {
+		return "", fmt.Errorf("no checksum published for %s, refusing to install an unverified update", asset.Name)
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	destPath := filepath.Join(destDir, asset.Name)
+	tmpPath := destPath + ".download"
+	sum, err := downloadFile(ctx, asset.DownloadURL, tmpPath)
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+
+	expected, err := fetchChecksum(ctx, checksum.DownloadURL)
+	if err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if !strings.EqualFold(expected, sum) {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.Name, expected, sum)
+	}
+
+	if err := os.Rename(tmpPath, destPath); err != nil {
+		os.Remove(tmpPath)
+		return "", fmt.Errorf("failed to save installer: %w", err)
+	}
+	return destPath, nil
+}
+
+// downloadFile 下载文件并返回 sha256
+func downloadFile(ctx context.Context, url, path string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "maxx/"+version.Version)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: HTTP %d", url, resp.StatusCode)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", url, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// fetchChecksum 读取 .sha256 文件（格式："<hash>  <filename>" 或仅 hash）
+func fetchChecksum(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "maxx/"+version.Version)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksum: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download checksum: HTTP %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("empty checksum file")
+	}
+	return fields[0], nil
+}
+
+// CompareVersions 比较两个语义化版本号（忽略 "v" 前缀和预发布后缀）
+// a > b 返回 1，a < b 返回 -1，相等返回 0
+func CompareVersions(a, b string) int {
+	pa, pb := parseVersion(a), parseVersion(b)
+	for i := range 3 {
+		if pa[i] > pb[i] {
+			return 1
+		}
+		if pa[i] < pb[i] {
+			return -1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) [3]int {
+	var parts [3]int
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if idx := strings.IndexAny(v, "-+"); idx >= 0 {
+		v = v[:idx]
+	}
+	for i, s := range strings.SplitN(v, ".", 3) {
+		n, _ := strconv.Atoi(s)
+		parts[i] = n
+	}
+	return parts
+}
@@ -52,6 +52,16 @@ func ExtractFromResponse(body string) *Metrics {
 func extractFromJSON(body string) *Metrics {
 	var data map[string]interface{}
 	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		// Gemini streams without alt=sse: a JSON array of responses, usage in the last one
+		var chunks []map[string]interface{}
+		if json.Unmarshal([]byte(body), &chunks) != nil {
+			return nil
+		}
+		for i := len(chunks) - 1; i >= 0; i-- {
+			if m := extractUsageFromMap(chunks[i]); m != nil && !m.IsEmpty() {
+				return m
+			}
+		}
 		return nil
 	}
 
@@ -65,58 +75,70 @@ func extractFromSSE(body string) *Metrics {
 	var lastMetrics *Metrics
 
 	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// Skip non-data lines
-		if !strings.HasPrefix(line, "data:") {
-			continue
+		if metrics := extractFromSSELine(line); metrics != nil {
+			lastMetrics = metrics
 		}
+	}
 
-		// Extract JSON from data: prefix
-		jsonStr := strings.TrimPrefix(line, "data:")
-		jsonStr = strings.TrimSpace(jsonStr)
+	return lastMetrics
+}
 
-		// Skip [DONE] marker
-		if jsonStr == "[DONE]" {
-			continue
-		}
+// extractFromSSELine extracts usage from a single SSE line.
+// Returns nil for non-data lines and events without usage.
+func extractFromSSELine(line string) *Metrics {
+	line = strings.TrimSpace(line)
 
-		var data map[string]interface{}
-		if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
-			continue
-		}
+	// Skip non-data lines
+	if !strings.HasPrefix(line, "data:") {
+		return nil
+	}
 
-		// Try to extract metrics from this event
-		metrics := extractUsageFromMap(data)
-		if metrics != nil && !metrics.IsEmpty() {
-			lastMetrics = metrics
-		}
+	// Extract JSON from data: prefix
+	jsonStr := strings.TrimPrefix(line, "data:")
+	jsonStr = strings.TrimSpace(jsonStr)
 
-		// Claude SSE: Check for message_delta type which contains final usage
-		if eventType, ok := data["type"].(string); ok {
-			if eventType == "message_delta" {
-				if usage, ok := data["usage"].(map[string]interface{}); ok {
-					m := extractClaudeUsage(usage)
-					if m != nil && !m.IsEmpty() {
-						lastMetrics = m
-					}
+	// Skip [DONE] marker
+	if jsonStr == "[DONE]" {
+		return nil
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal([]byte(jsonStr), &data); err != nil {
+		return nil
+	}
+
+	var result *Metrics
+
+	// Try to extract metrics from this event
+	metrics := extractUsageFromMap(data)
+	if metrics != nil && !metrics.IsEmpty() {
+		result = metrics
+	}
+
+	// Claude SSE: Check for message_delta type which contains final usage
+	if eventType, ok := data["type"].(string); ok {
+		if eventType == "message_delta" {
+			if usage, ok := data["usage"].(map[string]interface{}); ok {
+				m := extractClaudeUsage(usage)
+				if m != nil && !m.IsEmpty() {
+					result = m
 				}
 			}
-			// Codex SSE: Check for response.completed type which contains final usage
-			if eventType == "response.completed" {
-				if response, ok := data["response"].(map[string]interface{}); ok {
-					if usage, ok := response["usage"].(map[string]interface{}); ok {
-						m := extractOpenAIUsage(usage)
-						if m != nil && !m.IsEmpty() {
-							lastMetrics = m
-						}
+		}
+		// Codex SSE: Check for response.completed type which contains final usage
+		if eventType == "response.completed" {
+			if response, ok := data["response"].(map[string]interface{}); ok {
+				if usage, ok := response["usage"].(map[string]interface{}); ok {
+					m := extractOpenAIUsage(usage)
+					if m != nil && !m.IsEmpty() {
+						result = m
 					}
 				}
 			}
 		}
 	}
 
-	return lastMetrics
+	return result
 }
 
 // extractUsageFromMap extracts usage metrics from a parsed JSON map.
@@ -295,6 +317,18 @@ func ExtractFromStreamContent(content string) *Metrics {
 	return extractFromSSE(content)
 }
 
+// ToAdapterMetrics converts metrics to the form adapters report to the executor
+func (m *Metrics) ToAdapterMetrics() *domain.AdapterMetrics {
+	return &domain.AdapterMetrics{
+		InputTokens:          m.InputTokens,
+		OutputTokens:         m.OutputTokens,
+		CacheReadCount:       m.CacheReadCount,
+		CacheCreationCount:   m.CacheCreationCount,
+		Cache5mCreationCount: m.Cache5mCreationCount,
+		Cache1hCreationCount: m.Cache1hCreationCount,
+	}
+}
+
 // AdjustForClientType adjusts metrics based on client type specific quirks.
 // For Codex: input_tokens includes cached_tokens, so we subtract to avoid double counting.
 // For other clients: returns metrics unchanged.
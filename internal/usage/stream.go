@@ -0,0 +1,154 @@
+package usage
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const (
+	// maxLineSize bounds the buffered partial line. Longer lines (a non-streaming JSON body
+	// or a huge event) are not parsed as SSE events.
+	maxLineSize = 1 << 20
+	// tailSize is how much of the end of the body is kept for extracting usage from
+	// non-streaming JSON responses.
+	tailSize = 64 << 10
+)
+
+// StreamExtractor extracts usage metrics from a response body as it is written, without
+// keeping the body. SSE events are parsed as their lines complete, so the metrics are
+// current while the stream is running; for JSON bodies the usage object is looked up at
+// the end of the body, where all supported APIs put it.
+type StreamExtractor struct {
+	line     []byte
+	skipping bool // Discarding the rest of an over-long line
+	tail     []byte
+	total    int64
+	last     *Metrics
+	changed  bool // last was updated since the previous Poll
+}
+
+// NewStreamExtractor creates a StreamExtractor
+func NewStreamExtractor() *StreamExtractor {
+	return &StreamExtractor{}
+}
+
+// Write feeds the next chunk of the body. It never fails.
+func (s *StreamExtractor) Write(p []byte) (int, error) {
+	s.total += int64(len(p))
+	s.appendTail(p)
+
+	data := p
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			s.appendLine(data)
+			break
+		}
+		s.appendLine(data[:i])
+		if !s.skipping {
+			if m := extractFromSSELine(string(s.line)); m != nil {
+				s.last = m
+				s.changed = true
+			}
+		}
+		s.line = s.line[:0]
+		s.skipping = false
+		data = data[i+1:]
+	}
+	return len(p), nil
+}
+
+func (s *StreamExtractor) appendLine(p []byte) {
+	if s.skipping {
+		return
+	}
+	if len(s.line)+len(p) > maxLineSize {
+		s.line = s.line[:0]
+		s.skipping = true
+		return
+	}
+	s.line = append(s.line, p...)
+}
+
+func (s *StreamExtractor) appendTail(p []byte) {
+	if len(p) >= tailSize {
+		s.tail = append(s.tail[:0], p[len(p)-tailSize:]...)
+		return
+	}
+	if over := len(s.tail) + len(p) - tailSize; over > 0 {
+		s.tail = append(s.tail[:0], s.tail[over:]...)
+	}
+	s.tail = append(s.tail, p...)
+}
+
+// Poll returns the usage of the SSE events seen so far if it changed since the last call
+func (s *StreamExtractor) Poll() (*Metrics, bool) {
+	if !s.changed {
+		return nil, false
+	}
+	s.changed = false
+	m := *s.last
+	return &m, true
+}
+
+// Metrics returns the usage seen so far: the last SSE event carrying usage, or for a
+// JSON body the usage at its end. Returns nil if none was found. The result is a copy
+// the caller may modify.
+func (s *StreamExtractor) Metrics() *Metrics {
+	if !s.skipping && len(s.line) > 0 {
+		// Final line without a trailing newline
+		if m := extractFromSSELine(string(s.line)); m != nil {
+			return m
+		}
+	}
+	if s.last != nil {
+		m := *s.last
+		return &m
+	}
+	if s.total <= int64(len(s.tail)) {
+		// The whole body is in the tail
+		if m := extractFromJSON(string(s.tail)); m != nil && !m.IsEmpty() {
+			return m
+		}
+		return nil
+	}
+	return extractFromJSONTail(s.tail)
+}
+
+// usageKeys are the keys a usage object appears under, with the extractor for each
+var usageKeys = []struct {
+	key     string
+	extract func(usage map[string]interface{}) *Metrics
+}{
+	{`"usageMetadata":`, extractGeminiUsage},
+	{`"usage":`, func(u map[string]interface{}) *Metrics {
+		// Claude and OpenAI use the same key with different field names
+		if m := extractClaudeUsage(u); !m.IsEmpty() {
+			return m
+		}
+		return extractOpenAIUsage(u)
+	}},
+}
+
+// extractFromJSONTail extracts usage from the end of a JSON body too large to keep: the
+// last usage object in the tail is decoded on its own.
+func extractFromJSONTail(tail []byte) *Metrics {
+	// Not a JSON body (e.g. an SSE stream without usage)
+	if t := bytes.TrimSpace(tail); len(t) == 0 || (t[len(t)-1] != '}' && t[len(t)-1] != ']') {
+		return nil
+	}
+	for _, k := range usageKeys {
+		i := bytes.LastIndex(tail, []byte(k.key))
+		if i < 0 {
+			continue
+		}
+		var u map[string]interface{}
+		if err := json.NewDecoder(bytes.NewReader(tail[i+len(k.key):])).Decode(&u); err != nil {
+			continue
+		}
+		if m := k.extract(u); m != nil && !m.IsEmpty() {
+			return m
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+// Package webhook delivers maxx notifications, such as cost alerts, to user-configured
+// HTTP endpoints as JSON POST requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/awsl-project/maxx/internal/httpclient"
+	"github.com/awsl-project/maxx/internal/version"
+)
+
+// EventHeader names the kind of notification in a delivery (e.g. "cost_alert"), so one
+// endpoint can receive several kinds
+const EventHeader = "X-Maxx-Event"
+
+// Post sends payload to target as JSON. Any status other than 2xx is an error.
+func Post(ctx context.Context, target, event string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "maxx/"+version.Version)
+	req.Header.Set(EventHeader, event)
+
+	resp, err := httpclient.Get("webhook", httpclient.APIOptions()).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
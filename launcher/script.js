@@ -26,6 +26,7 @@
         errorContainer: document.getElementById('error-container'),
         errorMessage: document.getElementById('error-message'),
         retryButton: document.getElementById('retry-button'),
+        usePortButton: document.getElementById('use-port-button'),
         quitButton: document.getElementById('quit-button'),
         versionText: document.getElementById('version-text')
     };
@@ -33,6 +34,10 @@
     // Settings elements
     const settings = {
         portInput: document.getElementById('port-input'),
+        bindInput: document.getElementById('bind-input'),
+        fallbackInput: document.getElementById('fallback-input'),
+        updateInput: document.getElementById('update-input'),
+        checkUpdateButton: document.getElementById('check-update-button'),
         datadirInput: document.getElementById('datadir-input'),
         saveButton: document.getElementById('save-button'),
         backButton: document.getElementById('back-button')
@@ -85,14 +90,23 @@
         launcher.statusText.textContent = text;
     }
 
-    function showError(message) {
+    function showError(message, portConflict) {
         launcher.statusContainer.style.display = 'none';
         launcher.errorContainer.classList.remove('hidden');
         launcher.errorMessage.textContent = message;
+
+        // Offer a free port when the configured one is taken
+        if (portConflict && portConflict.SuggestedPort) {
+            launcher.usePortButton.textContent = `Use port ${portConflict.SuggestedPort}`;
+            launcher.usePortButton.classList.remove('hidden');
+        } else {
+            launcher.usePortButton.classList.add('hidden');
+        }
     }
 
     function hideError() {
         launcher.errorContainer.classList.add('hidden');
+        launcher.usePortButton.classList.add('hidden');
         launcher.statusContainer.style.display = 'flex';
         launcher.statusContainer.classList.remove('success');
     }
@@ -143,7 +157,7 @@
 
             if (status.Error) {
                 clearInterval(checkTimer);
-                showError(status.Error);
+                showError(status.Error, status.PortConflict);
                 return;
             }
         } catch (err) {
@@ -170,6 +184,25 @@
         checkServer();
     }
 
+    async function useSuggestedPort() {
+        hideError();
+        startTime = Date.now();
+        updateStatus('Switching port...');
+
+        try {
+            if (window.go && window.go.desktop && window.go.desktop.LauncherApp) {
+                await window.go.desktop.LauncherApp.UseSuggestedPort();
+            }
+        } catch (err) {
+            console.error('[Launcher] Switch port failed:', err);
+            showError('Failed to switch port: ' + (err.message || err));
+            return;
+        }
+
+        checkTimer = setInterval(checkServer, CONFIG.checkInterval);
+        checkServer();
+    }
+
     function quit() {
         if (window.go && window.go.desktop && window.go.desktop.LauncherApp) {
             window.go.desktop.LauncherApp.Quit();
@@ -213,6 +246,9 @@
             const dataDir = await window.go.desktop.LauncherApp.GetDataDir();
 
             settings.portInput.value = config.port || 9880;
+            settings.bindInput.value = config.bindAddress || '';
+            settings.fallbackInput.checked = !!config.autoFallbackPort;
+            settings.updateInput.checked = !!config.autoCheckUpdate;
             settings.datadirInput.value = dataDir || '';
 
             console.log('[Settings] Config loaded:', config, 'dataDir:', dataDir);
@@ -239,7 +275,12 @@
             settings.saveButton.disabled = true;
             settings.saveButton.textContent = 'Saving...';
 
-            await window.go.desktop.LauncherApp.SaveConfig({ port: port });
+            await window.go.desktop.LauncherApp.SaveConfig({
+                port: port,
+                bindAddress: settings.bindInput.value.trim(),
+                autoFallbackPort: settings.fallbackInput.checked,
+                autoCheckUpdate: settings.updateInput.checked
+            });
 
             showToast('Config saved, restarting service...');
 
@@ -262,6 +303,50 @@
         }
     }
 
+    // ==================== Update Functions ====================
+
+    async function promptUpdate(info) {
+        if (!info || !info.hasUpdate) {
+            return;
+        }
+
+        const changelog = (info.release && info.release.changelog) || '';
+        const message = `New version ${info.latestVersion} is available (current: ${info.currentVersion}).\n\n${changelog}\n\nDownload and install now?`;
+        if (!window.confirm(message)) {
+            return;
+        }
+
+        try {
+            showToast('Downloading update...');
+            await window.go.desktop.LauncherApp.DownloadAndInstallUpdate();
+        } catch (err) {
+            console.error('[Update] Install failed:', err);
+            showToast('Update failed: ' + (err.message || err), 'error');
+        }
+    }
+
+    async function checkForUpdates() {
+        if (!window.go || !window.go.desktop || !window.go.desktop.LauncherApp) {
+            showToast('Wails runtime not ready', 'error');
+            return;
+        }
+
+        settings.checkUpdateButton.disabled = true;
+        try {
+            const info = await window.go.desktop.LauncherApp.CheckForUpdates();
+            if (info.hasUpdate) {
+                await promptUpdate(info);
+            } else {
+                showToast('You are using the latest version');
+            }
+        } catch (err) {
+            console.error('[Update] Check failed:', err);
+            showToast('Update check failed: ' + (err.message || err), 'error');
+        } finally {
+            settings.checkUpdateButton.disabled = false;
+        }
+    }
+
     function goBack() {
         window.location.href = 'wails://wails/index.html';
     }
@@ -274,10 +359,17 @@
         // Launcher events
         launcher.retryButton.addEventListener('click', retry);
         launcher.quitButton.addEventListener('click', quit);
+        launcher.usePortButton.addEventListener('click', useSuggestedPort);
 
         // Settings events
         settings.saveButton.addEventListener('click', saveSettings);
         settings.backButton.addEventListener('click', goBack);
+        settings.checkUpdateButton.addEventListener('click', checkForUpdates);
+
+        // Update notification pushed from the backend
+        if (window.runtime && window.runtime.EventsOn) {
+            window.runtime.EventsOn('update_available', promptUpdate);
+        }
 
         // Load version
         loadVersion();
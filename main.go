@@ -104,6 +104,11 @@ func main() {
 			app,
 		},
 		Menu: appMenu,
+		// 单实例：再次启动时聚焦已有窗口，避免第二个服务器因端口冲突启动失败
+		SingleInstanceLock: &options.SingleInstanceLock{
+			UniqueId:               app.SingleInstanceID(),
+			OnSecondInstanceLaunch: app.OnSecondInstanceLaunch,
+		},
 		// 启用 DevTools 方便调试
 		Debug: options.Debug{
 			OpenInspectorOnStartup: false,
@@ -14,6 +14,9 @@ import { ProjectDetailPage } from '@/pages/projects/detail';
 import { SessionsPage } from '@/pages/sessions';
 import { RetryConfigsPage } from '@/pages/retry-configs';
 import { RoutingStrategiesPage } from '@/pages/routing-strategies';
+import { RoutingProfilesPage } from '@/pages/routing-profiles';
+import { CostAlertsPage } from '@/pages/cost-alerts';
+import { LatencySLOsPage } from '@/pages/latency-slos';
 import { ConsolePage } from '@/pages/console';
 import { SettingsPage } from '@/pages/settings';
 import { LoginPage } from '@/pages/login';
@@ -58,6 +61,9 @@ function AppRoutes() {
           <Route path="model-mappings" element={<ModelMappingsPage />} />
           <Route path="retry-configs" element={<RetryConfigsPage />} />
           <Route path="routing-strategies" element={<RoutingStrategiesPage />} />
+          <Route path="routing-profiles" element={<RoutingProfilesPage />} />
+          <Route path="cost-alerts" element={<CostAlertsPage />} />
+          <Route path="latency-slos" element={<LatencySLOsPage />} />
           <Route path="stats" element={<StatsPage />} />
           <Route path="settings" element={<SettingsPage />} />
         </Route>
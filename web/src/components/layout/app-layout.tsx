@@ -1,13 +1,25 @@
+import { useEffect } from 'react';
 import { Outlet } from 'react-router-dom';
+import { useTranslation } from 'react-i18next';
 import { AppSidebar } from './app-sidebar';
 import { SidebarProvider, SidebarInset, SidebarTrigger } from '@/components/ui/sidebar';
 import { ForceProjectDialog } from '@/components/force-project-dialog';
 import { usePendingSession } from '@/hooks/use-pending-session';
-import { useSettings } from '@/hooks/queries';
+import { useSettings, useCostAlertNotifications } from '@/hooks/queries';
 
 export function AppLayout() {
   const { pendingSession, clearPendingSession } = usePendingSession();
   const { data: settings } = useSettings();
+  const { i18n } = useTranslation();
+  useCostAlertNotifications();
+
+  // 设置中保存的语言优先于本地记录的语言（在其他设备上修改后同步）
+  const savedLanguage = settings?.language;
+  useEffect(() => {
+    if ((savedLanguage === 'en' || savedLanguage === 'zh') && savedLanguage !== i18n.language) {
+      i18n.changeLanguage(savedLanguage);
+    }
+  }, [savedLanguage, i18n]);
 
   const forceProjectEnabled = settings?.force_project_binding === 'true';
   const timeoutSeconds = parseInt(settings?.force_project_timeout || '30', 10);
@@ -9,6 +9,9 @@ import {
   Key,
   Zap,
   BarChart3,
+  CalendarClock,
+  BellRing,
+  Timer,
 } from 'lucide-react';
 import type { SidebarConfig } from '@/types/sidebar';
 import { RequestsNavItem } from './requests-nav-item';
@@ -115,6 +118,27 @@ export const sidebarConfig: SidebarConfig = {
           icon: RefreshCw,
           labelKey: 'nav.retryConfigs',
         },
+        {
+          type: 'standard',
+          key: 'routing-profiles',
+          to: '/routing-profiles',
+          icon: CalendarClock,
+          labelKey: 'nav.routingProfiles',
+        },
+        {
+          type: 'standard',
+          key: 'cost-alerts',
+          to: '/cost-alerts',
+          icon: BellRing,
+          labelKey: 'nav.costAlerts',
+        },
+        {
+          type: 'standard',
+          key: 'latency-slos',
+          to: '/latency-slos',
+          icon: Timer,
+          labelKey: 'nav.latencySLOs',
+        },
         {
           type: 'standard',
           key: 'settings',
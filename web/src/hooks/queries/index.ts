@@ -10,10 +10,14 @@ export {
   useCreateProvider,
   useUpdateProvider,
   useDeleteProvider,
+  useDeletedProviders,
+  useRestoreProvider,
+  useBatchProviders,
   useProviderStats,
   useAllProviderStats,
   useAntigravityQuota,
   useAntigravityBatchQuotas,
+  useReplayAntigravityAttempt,
   useKiroQuota,
 } from './use-providers';
 
@@ -33,11 +37,16 @@ export {
   routeKeys,
   useRoutes,
   useRoute,
+  useEffectiveRetryConfig,
   useCreateRoute,
   useUpdateRoute,
   useDeleteRoute,
+  useDeletedRoutes,
+  useRestoreRoute,
   useToggleRoute,
   useUpdateRoutePositions,
+  useRouteConversions,
+  useCreateConvertedRoutes,
 } from './use-routes';
 
 // Session hooks
@@ -45,7 +54,10 @@ export {
   sessionKeys,
   useSessions,
   useUpdateSessionProject,
+  usePinSession,
   useRejectSession,
+  usePurgeExpiredSessions,
+  useSessionExpiryUpdates,
 } from './use-sessions';
 
 // RetryConfig hooks
@@ -68,6 +80,39 @@ export {
   useDeleteRoutingStrategy,
 } from './use-routing-strategies';
 
+// RoutingProfile hooks
+export {
+  routingProfileKeys,
+  useRoutingProfiles,
+  useActiveRoutingProfile,
+  useCreateRoutingProfile,
+  useUpdateRoutingProfile,
+  useDeleteRoutingProfile,
+  useApplyRoutingProfile,
+  useCaptureRoutingProfile,
+} from './use-routing-profiles';
+
+// CostAlert hooks
+export {
+  costAlertKeys,
+  useCostAlerts,
+  useCreateCostAlert,
+  useUpdateCostAlert,
+  useDeleteCostAlert,
+  useCostAlertNotifications,
+} from './use-cost-alerts';
+
+// LatencySLO hooks
+export {
+  latencySLOKeys,
+  useLatencySLOs,
+  useLatencySLOStats,
+  useCreateLatencySLO,
+  useUpdateLatencySLO,
+  useDeleteLatencySLO,
+  useLatencySLOEvents,
+} from './use-latency-slos';
+
 // ProxyRequest hooks
 export {
   requestKeys,
@@ -76,6 +121,7 @@ export {
   useProxyRequest,
   useProxyUpstreamAttempts,
   useProxyRequestUpdates,
+  useRunBatch,
 } from './use-requests';
 
 // Proxy hooks
@@ -112,6 +158,9 @@ export {
   useUsageStats,
   useUsageStatsWithPreset,
   useRecalculateUsageStats,
+  useRequestShapeStats,
+  useExperimentStats,
+  usePromptCacheAdvice,
   selectGranularity,
   getTimeRange,
   type TimeRangePreset,
@@ -0,0 +1,86 @@
+/**
+ * CostAlert React Query Hooks
+ */
+
+import { useEffect } from 'react';
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
+import {
+  getTransport,
+  type CostAlert,
+  type CostAlertNotification,
+  type CreateCostAlertData,
+} from '@/lib/transport';
+
+// Query Keys
+export const costAlertKeys = {
+  all: ['costAlerts'] as const,
+  lists: () => [...costAlertKeys.all, 'list'] as const,
+  list: () => [...costAlertKeys.lists()] as const,
+  details: () => [...costAlertKeys.all, 'detail'] as const,
+  detail: (id: number) => [...costAlertKeys.details(), id] as const,
+};
+
+// 获取所有 CostAlerts
+export function useCostAlerts() {
+  return useQuery({
+    queryKey: costAlertKeys.list(),
+    queryFn: () => getTransport().getCostAlerts(),
+  });
+}
+
+// 创建 CostAlert
+export function useCreateCostAlert() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (data: CreateCostAlertData) => getTransport().createCostAlert(data),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: costAlertKeys.lists() });
+    },
+  });
+}
+
+// 更新 CostAlert
+export function useUpdateCostAlert() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: ({ id, data }: { id: number; data: Partial<CostAlert> }) =>
+      getTransport().updateCostAlert(id, data),
+    onSuccess: (_, { id }) => {
+      queryClient.invalidateQueries({ queryKey: costAlertKeys.detail(id) });
+      queryClient.invalidateQueries({ queryKey: costAlertKeys.lists() });
+    },
+  });
+}
+
+// 删除 CostAlert
+export function useDeleteCostAlert() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (id: number) => getTransport().deleteCostAlert(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: costAlertKeys.all });
+    },
+  });
+}
+
+// 订阅费用告警事件，在已授权时显示桌面通知，并刷新告警的触发时间
+export function useCostAlertNotifications() {
+  const queryClient = useQueryClient();
+
+  useEffect(() => {
+    const transport = getTransport();
+    return transport.subscribe<CostAlertNotification>('cost_alert', (notification) => {
+      queryClient.invalidateQueries({ queryKey: costAlertKeys.lists() });
+      if (typeof Notification === 'undefined' || Notification.permission !== 'granted') {
+        return;
+      }
+      new Notification(notification.alertName, {
+        body: notification.message,
+        tag: `cost-alert-${notification.alertID}-${notification.kind}`,
+      });
+    });
+  }, [queryClient]);
+}
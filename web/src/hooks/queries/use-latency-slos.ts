@@ -0,0 +1,86 @@
+/**
+ * LatencySLO React Query Hooks
+ */
+
+import { useEffect } from 'react';
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
+import {
+  getTransport,
+  type LatencySLO,
+  type LatencySLOEvent,
+  type CreateLatencySLOData,
+} from '@/lib/transport';
+
+// Query Keys
+export const latencySLOKeys = {
+  all: ['latencySLOs'] as const,
+  lists: () => [...latencySLOKeys.all, 'list'] as const,
+  list: () => [...latencySLOKeys.lists()] as const,
+  stats: () => [...latencySLOKeys.all, 'stats'] as const,
+};
+
+// 获取所有 LatencySLOs
+export function useLatencySLOs() {
+  return useQuery({
+    queryKey: latencySLOKeys.list(),
+    queryFn: () => getTransport().getLatencySLOs(),
+  });
+}
+
+// 获取各 Provider 的 SLO 达标情况（样本在内存中，定期刷新）
+export function useLatencySLOStats() {
+  return useQuery({
+    queryKey: latencySLOKeys.stats(),
+    queryFn: () => getTransport().getLatencySLOStats(),
+    refetchInterval: 30000,
+  });
+}
+
+// 创建 LatencySLO
+export function useCreateLatencySLO() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (data: CreateLatencySLOData) => getTransport().createLatencySLO(data),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: latencySLOKeys.all });
+    },
+  });
+}
+
+// 更新 LatencySLO
+export function useUpdateLatencySLO() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: ({ id, data }: { id: number; data: Partial<LatencySLO> }) =>
+      getTransport().updateLatencySLO(id, data),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: latencySLOKeys.all });
+    },
+  });
+}
+
+// 删除 LatencySLO
+export function useDeleteLatencySLO() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (id: number) => getTransport().deleteLatencySLO(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: latencySLOKeys.all });
+    },
+  });
+}
+
+// 订阅 SLO 违反和恢复事件，刷新达标情况
+export function useLatencySLOEvents() {
+  const queryClient = useQueryClient();
+
+  useEffect(() => {
+    const transport = getTransport();
+    return transport.subscribe<LatencySLOEvent>('latency_slo', () => {
+      queryClient.invalidateQueries({ queryKey: latencySLOKeys.stats() });
+    });
+  }, [queryClient]);
+}
@@ -3,7 +3,12 @@
  */
 
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
-import { getTransport, type Provider, type CreateProviderData } from '@/lib/transport';
+import {
+  getTransport,
+  type Provider,
+  type CreateProviderData,
+  type ProviderBatchRequest,
+} from '@/lib/transport';
 import { routeKeys } from './use-routes';
 
 // Query Keys
@@ -11,6 +16,7 @@ export const providerKeys = {
   all: ['providers'] as const,
   lists: () => [...providerKeys.all, 'list'] as const,
   list: () => [...providerKeys.lists()] as const,
+  deleted: () => [...providerKeys.lists(), 'deleted'] as const,
   details: () => [...providerKeys.all, 'detail'] as const,
   detail: (id: number) => [...providerKeys.details(), id] as const,
   stats: () => [...providerKeys.all, 'stats'] as const,
@@ -24,6 +30,14 @@ export function useProviders() {
   });
 }
 
+// 获取已软删除的 Providers（用于恢复，以及显示历史请求的 Provider 名称）
+export function useDeletedProviders() {
+  return useQuery({
+    queryKey: providerKeys.deleted(),
+    queryFn: () => getTransport().getDeletedProviders(),
+  });
+}
+
 // 获取单个 Provider
 export function useProvider(id: number) {
   return useQuery({
@@ -57,6 +71,7 @@ export function useUpdateProvider() {
       queryClient.invalidateQueries({ queryKey: providerKeys.detail(id) });
       queryClient.invalidateQueries({ queryKey: providerKeys.lists() });
       queryClient.invalidateQueries({ queryKey: routeKeys.lists() });
+      queryClient.invalidateQueries({ queryKey: routeKeys.details() });
     },
   });
 }
@@ -74,6 +89,32 @@ export function useDeleteProvider() {
   });
 }
 
+// 恢复软删除的 Provider（连同删除它时一起删除的路由）
+export function useRestoreProvider() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (id: number) => getTransport().restoreProvider(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: providerKeys.lists() });
+      queryClient.invalidateQueries({ queryKey: routeKeys.lists() });
+    },
+  });
+}
+
+// 批量操作 Providers（启用 / 停用 / 删除 / 校验 / Patch）
+export function useBatchProviders() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (req: ProviderBatchRequest) => getTransport().batchProviders(req),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: providerKeys.lists() });
+      queryClient.invalidateQueries({ queryKey: routeKeys.lists() });
+    },
+  });
+}
+
 // 获取 Provider 统计信息
 export function useProviderStats(clientType?: string, projectId?: number) {
   return useQuery({
@@ -119,6 +160,21 @@ export function useAntigravityBatchQuotas(enabled = true) {
   });
 }
 
+// 用另一个 Antigravity 账号重放上游尝试
+export function useReplayAntigravityAttempt() {
+  return useMutation({
+    mutationFn: ({
+      proxyRequestID,
+      attemptID,
+      providerID,
+    }: {
+      proxyRequestID: number;
+      attemptID: number;
+      providerID: number;
+    }) => getTransport().replayAntigravityAttempt(proxyRequestID, attemptID, providerID),
+  });
+}
+
 // 获取 Kiro Provider 额度
 export function useKiroQuota(providerId: number, enabled = true) {
   return useQuery({
@@ -2,7 +2,7 @@
  * ProxyRequest React Query Hooks
  */
 
-import { useQuery, useQueryClient } from '@tanstack/react-query';
+import { useMutation, useQuery, useQueryClient } from '@tanstack/react-query';
 import { useEffect } from 'react';
 import {
   getTransport,
@@ -10,6 +10,7 @@ import {
   type ProxyUpstreamAttempt,
   type CursorPaginationParams,
   type CursorPaginationResult,
+  type BatchRunRequest,
 } from '@/lib/transport';
 
 // Query Keys
@@ -128,3 +129,15 @@ export function useProxyRequestUpdates() {
     };
   }, [queryClient]);
 }
+
+// 批量执行 prompt（用于对比 Provider 的质量和成本），执行的请求会出现在请求列表中
+export function useRunBatch() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (req: BatchRunRequest) => getTransport().runBatch(req),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: requestKeys.lists() });
+    },
+  });
+}
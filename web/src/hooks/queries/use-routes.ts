@@ -3,15 +3,24 @@
  */
 
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
-import { getTransport, type Route, type CreateRouteData } from '@/lib/transport';
+import {
+  getTransport,
+  type Route,
+  type CreateRouteData,
+  type ConvertedRoutesRequest,
+} from '@/lib/transport';
 
 // Query Keys
 export const routeKeys = {
   all: ['routes'] as const,
   lists: () => [...routeKeys.all, 'list'] as const,
   list: () => [...routeKeys.lists()] as const,
+  deleted: () => [...routeKeys.lists(), 'deleted'] as const,
   details: () => [...routeKeys.all, 'detail'] as const,
   detail: (id: number) => [...routeKeys.details(), id] as const,
+  retryConfig: (id: number) => [...routeKeys.detail(id), 'retry-config'] as const,
+  conversions: (projectID: number, providerID: number) =>
+    [...routeKeys.all, 'conversions', projectID, providerID] as const,
 };
 
 // 获取所有 Routes
@@ -22,6 +31,14 @@ export function useRoutes() {
   });
 }
 
+// 获取已软删除的 Routes
+export function useDeletedRoutes() {
+  return useQuery({
+    queryKey: routeKeys.deleted(),
+    queryFn: () => getTransport().getDeletedRoutes(),
+  });
+}
+
 // 获取单个 Route
 export function useRoute(id: number) {
   return useQuery({
@@ -31,6 +48,36 @@ export function useRoute(id: number) {
   });
 }
 
+// 获取 Route 实际生效的重试配置（路由 > 供应商 > 系统默认）
+export function useEffectiveRetryConfig(id: number) {
+  return useQuery({
+    queryKey: routeKeys.retryConfig(id),
+    queryFn: () => getTransport().getEffectiveRetryConfig(id),
+    enabled: id > 0,
+  });
+}
+
+// 获取供应商可以提供的客户端类型（原生或转换）及已有的路由
+export function useRouteConversions(projectID: number, providerID: number) {
+  return useQuery({
+    queryKey: routeKeys.conversions(projectID, providerID),
+    queryFn: () => getTransport().getRouteConversions(projectID, providerID),
+    enabled: providerID > 0,
+  });
+}
+
+// 创建（或启用/停用）转换路由
+export function useCreateConvertedRoutes() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (req: ConvertedRoutesRequest) => getTransport().createConvertedRoutes(req),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: routeKeys.all });
+    },
+  });
+}
+
 // 创建 Route
 export function useCreateRoute() {
   const queryClient = useQueryClient();
@@ -69,6 +116,18 @@ export function useDeleteRoute() {
   });
 }
 
+// 恢复软删除的 Route
+export function useRestoreRoute() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (id: number) => getTransport().restoreRoute(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: routeKeys.lists() });
+    },
+  });
+}
+
 // 切换 Route 启用状态
 export function useToggleRoute() {
   const queryClient = useQueryClient();
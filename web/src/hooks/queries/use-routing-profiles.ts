@@ -0,0 +1,102 @@
+/**
+ * RoutingProfile React Query Hooks
+ */
+
+import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
+import {
+  getTransport,
+  type RoutingProfile,
+  type CreateRoutingProfileData,
+} from '@/lib/transport';
+import { routeKeys } from './use-routes';
+
+// Query Keys
+export const routingProfileKeys = {
+  all: ['routingProfiles'] as const,
+  lists: () => [...routingProfileKeys.all, 'list'] as const,
+  list: () => [...routingProfileKeys.lists()] as const,
+  details: () => [...routingProfileKeys.all, 'detail'] as const,
+  detail: (id: number) => [...routingProfileKeys.details(), id] as const,
+  active: () => [...routingProfileKeys.all, 'active'] as const,
+};
+
+// 获取所有 RoutingProfiles
+export function useRoutingProfiles() {
+  return useQuery({
+    queryKey: routingProfileKeys.list(),
+    queryFn: () => getTransport().getRoutingProfiles(),
+  });
+}
+
+// 获取当前方案（定时切换可能随时发生，定期刷新）
+export function useActiveRoutingProfile() {
+  return useQuery({
+    queryKey: routingProfileKeys.active(),
+    queryFn: () => getTransport().getActiveRoutingProfile(),
+    refetchInterval: 60000,
+  });
+}
+
+// 创建 RoutingProfile
+export function useCreateRoutingProfile() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (data: CreateRoutingProfileData) => getTransport().createRoutingProfile(data),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: routingProfileKeys.lists() });
+    },
+  });
+}
+
+// 更新 RoutingProfile
+export function useUpdateRoutingProfile() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: ({ id, data }: { id: number; data: Partial<RoutingProfile> }) =>
+      getTransport().updateRoutingProfile(id, data),
+    onSuccess: (_, { id }) => {
+      queryClient.invalidateQueries({ queryKey: routingProfileKeys.detail(id) });
+      queryClient.invalidateQueries({ queryKey: routingProfileKeys.lists() });
+    },
+  });
+}
+
+// 删除 RoutingProfile
+export function useDeleteRoutingProfile() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (id: number) => getTransport().deleteRoutingProfile(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: routingProfileKeys.all });
+    },
+  });
+}
+
+// 应用 RoutingProfile（会修改路由的启用状态和位置）
+export function useApplyRoutingProfile() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (id: number) => getTransport().applyRoutingProfile(id),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: routingProfileKeys.active() });
+      queryClient.invalidateQueries({ queryKey: routeKeys.all });
+    },
+  });
+}
+
+// 用当前路由状态覆盖 RoutingProfile 中的路由列表
+export function useCaptureRoutingProfile() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (id: number) => getTransport().captureRoutingProfile(id),
+    onSuccess: (_, id) => {
+      queryClient.invalidateQueries({ queryKey: routingProfileKeys.detail(id) });
+      queryClient.invalidateQueries({ queryKey: routingProfileKeys.lists() });
+    },
+  });
+}
@@ -2,8 +2,10 @@
  * Session React Query Hooks
  */
 
+import { useEffect } from 'react';
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
 import { getTransport } from '@/lib/transport';
+import type { SessionExpiryResult, SessionIdleAction } from '@/lib/transport';
 
 // Query Keys
 export const sessionKeys = {
@@ -36,6 +38,46 @@ export function useUpdateSessionProject() {
   });
 }
 
+// 固定 Session 到指定供应商/路由（providerID 为 0 时取消固定）
+export function usePinSession() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: ({ sessionID, providerID }: { sessionID: string; providerID: number }) =>
+      providerID === 0
+        ? getTransport().unpinSession(sessionID)
+        : getTransport().pinSession(sessionID, { providerID }),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: sessionKeys.all });
+    },
+  });
+}
+
+// 立即清理空闲 Session（不传参数时使用系统设置中的空闲时长和处理方式）
+export function usePurgeExpiredSessions() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: (req?: { idleHours?: number; action?: SessionIdleAction }) =>
+      getTransport().purgeExpiredSessions(req),
+    onSuccess: () => {
+      queryClient.invalidateQueries({ queryKey: sessionKeys.all });
+    },
+  });
+}
+
+// 订阅空闲 Session 被解除绑定或归档的事件，刷新会话列表
+export function useSessionExpiryUpdates() {
+  const queryClient = useQueryClient();
+
+  useEffect(() => {
+    const transport = getTransport();
+    return transport.subscribe<SessionExpiryResult>('sessions_expired', () => {
+      queryClient.invalidateQueries({ queryKey: sessionKeys.all });
+    });
+  }, [queryClient]);
+}
+
 // 拒绝 Session
 export function useRejectSession() {
   const queryClient = useQueryClient();
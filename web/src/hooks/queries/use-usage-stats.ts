@@ -4,12 +4,24 @@
  */
 
 import { useQuery, useMutation, useQueryClient } from '@tanstack/react-query';
-import { getTransport, type UsageStatsFilter, type StatsGranularity } from '@/lib/transport';
+import {
+  getTransport,
+  type UsageStatsFilter,
+  type StatsGranularity,
+  type RequestShapeFilter,
+  type ExperimentStatsFilter,
+  type PromptCacheAdvisorFilter,
+} from '@/lib/transport';
 
 // Query Keys
 export const usageStatsKeys = {
   all: ['usageStats'] as const,
   list: (filter?: UsageStatsFilter) => [...usageStatsKeys.all, filter] as const,
+  shapes: (filter?: RequestShapeFilter) => [...usageStatsKeys.all, 'shapes', filter] as const,
+  experiments: (filter?: ExperimentStatsFilter) =>
+    [...usageStatsKeys.all, 'experiments', filter] as const,
+  promptCache: (filter?: PromptCacheAdvisorFilter) =>
+    [...usageStatsKeys.all, 'promptCache', filter] as const,
 };
 
 /**
@@ -120,6 +132,38 @@ export function useUsageStats(filter?: UsageStatsFilter) {
   });
 }
 
+/**
+ * 按请求模型和供应商获取请求形态统计（大小、消息数、工具、图片）
+ */
+export function useRequestShapeStats(filter?: RequestShapeFilter) {
+  return useQuery({
+    queryKey: usageStatsKeys.shapes(filter),
+    queryFn: () => getTransport().getRequestShapeStats(filter),
+  });
+}
+
+/**
+ * 获取路由实验各组的对比指标
+ */
+export function useExperimentStats(filter?: ExperimentStatsFilter, enabled = true) {
+  return useQuery({
+    queryKey: usageStatsKeys.experiments(filter),
+    queryFn: () => getTransport().getExperimentStats(filter),
+    enabled,
+  });
+}
+
+/**
+ * 获取各路由的 prompt 缓存分析和建议
+ */
+export function usePromptCacheAdvice(filter?: PromptCacheAdvisorFilter, enabled = true) {
+  return useQuery({
+    queryKey: usageStatsKeys.promptCache(filter),
+    queryFn: () => getTransport().getPromptCacheAdvice(filter),
+    enabled,
+  });
+}
+
 /**
  * 使用预设时间范围获取统计数据
  */
@@ -0,0 +1,30 @@
+/**
+ * Attempt Progress Hook
+ * 订阅流式请求的实时进度（已报告的 token 用量和估算成本）
+ */
+
+import { useState, useEffect } from 'react';
+import { getTransport, type AttemptProgress } from '@/lib/transport';
+
+/**
+ * 返回请求最近一次广播的进度；active 为 false 时不订阅并返回 null
+ */
+export function useAttemptProgress(proxyRequestID: number, active: boolean): AttemptProgress | null {
+  const [progress, setProgress] = useState<AttemptProgress | null>(null);
+
+  useEffect(() => {
+    if (!active) {
+      setProgress(null);
+      return;
+    }
+
+    const transport = getTransport();
+    return transport.subscribe<AttemptProgress>('attempt_progress', (event) => {
+      if (event.proxyRequestID === proxyRequestID) {
+        setProgress(event);
+      }
+    });
+  }, [proxyRequestID, active]);
+
+  return progress;
+}
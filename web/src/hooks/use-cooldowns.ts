@@ -1,8 +1,17 @@
 import { useQuery, useQueryClient, useMutation } from '@tanstack/react-query';
 import { getTransport } from '@/lib/transport';
-import type { Cooldown } from '@/lib/transport';
+import type { Cooldown, CooldownStatsParams } from '@/lib/transport';
 import { useEffect } from 'react';
 
+// 冷却原因统计：按供应商、原因聚合冷却次数与时长
+export function useCooldownStats(params?: CooldownStatsParams) {
+  return useQuery({
+    queryKey: ['cooldowns', 'stats', params],
+    queryFn: () => getTransport().getCooldownStats(params),
+    staleTime: 60000,
+  });
+}
+
 export function useCooldowns() {
   const queryClient = useQueryClient();
 
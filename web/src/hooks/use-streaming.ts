@@ -39,7 +39,8 @@ export function useStreamingRequests(): StreamingState {
         request.status === 'COMPLETED' ||
         request.status === 'FAILED' ||
         request.status === 'CANCELLED' ||
-        request.status === 'REJECTED'
+        request.status === 'REJECTED' ||
+        request.status === 'INTERRUPTED'
       ) {
         next.delete(request.requestID);
       } else {
@@ -11,18 +11,34 @@ import type {
   Project,
   CreateProjectData,
   Session,
+  SessionIdleAction,
+  SessionExpiryResult,
   Route,
   CreateRouteData,
   RetryConfig,
   CreateRetryConfigData,
   RoutingStrategy,
   CreateRoutingStrategyData,
+  RoutingProfile,
+  CreateRoutingProfileData,
+  ActiveRoutingProfile,
+  CostAlert,
+  CreateCostAlertData,
+  LatencySLO,
+  CreateLatencySLOData,
+  LatencySLOStatus,
   ProxyRequest,
   ProxyUpstreamAttempt,
+  ProxyRequestDiff,
   ProxyStatus,
+  RecoverySummary,
   ProviderStats,
+  ProviderQuotaStatus,
+  FailbackStatus,
   CursorPaginationParams,
   CursorPaginationResult,
+  ListQuery,
+  Page,
   WSMessageType,
   WSMessage,
   EventCallback,
@@ -30,10 +46,29 @@ import type {
   AntigravityTokenValidationResult,
   AntigravityBatchValidationResult,
   AntigravityQuotaData,
+  AntigravityReplayResult,
   ModelMapping,
   ModelMappingInput,
+  ModelMappingOutcome,
+  ModelMappingSuggestion,
+  AuditEvent,
+  AuditQuery,
+  QualitySample,
+  QualitySampleQuery,
+  ModelCapability,
+  ModelCapabilityInput,
+  ModelCapabilities,
+  ConfigReport,
   ImportResult,
+  ExternalImportFile,
+  ExternalImportResult,
+  ProviderBatchRequest,
+  ProviderBatchResult,
+  BatchRunRequest,
+  BatchRunResult,
   Cooldown,
+  CooldownStats,
+  CooldownStatsParams,
   KiroTokenValidationResult,
   KiroQuotaData,
   AuthStatus,
@@ -42,12 +77,24 @@ import type {
   APITokenCreateResult,
   CreateAPITokenData,
   RoutePositionUpdate,
+  RouteSimulationRequest,
+  RouteSimulation,
+  RouteConversion,
+  ConvertedRoutesRequest,
+  EffectiveRetryConfig,
   UsageStats,
   UsageStatsFilter,
+  RequestShapeFilter,
+  RequestShapeStats,
+  ExperimentStatsFilter,
+  ExperimentArmStats,
+  PromptCacheAdvisorFilter,
+  PromptCacheAdvice,
 } from './types';
 
 export class HttpTransport implements Transport {
   private client: AxiosInstance;
+  private apiClient: AxiosInstance; // Provider account APIs outside /api/admin
   private ws: WebSocket | null = null;
   private config: Required<TransportConfig>;
   private eventListeners: Map<WSMessageType, Set<EventCallback>> = new Map();
@@ -71,14 +118,22 @@ export class HttpTransport implements Transport {
         'Content-Type': 'application/json',
       },
     });
+    this.apiClient = axios.create({
+      baseURL: '/api',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+    });
 
     // Add request interceptor to include auth header
-    this.client.interceptors.request.use((config) => {
-      if (this.authToken) {
-        config.headers['Authorization'] = `Bearer ${this.authToken}`;
-      }
-      return config;
-    });
+    for (const client of [this.client, this.apiClient]) {
+      client.interceptors.request.use((config) => {
+        if (this.authToken) {
+          config.headers['Authorization'] = `Bearer ${this.authToken}`;
+        }
+        return config;
+      });
+    }
   }
 
   // ===== Provider API =====
@@ -88,6 +143,16 @@ export class HttpTransport implements Transport {
     return data ?? [];
   }
 
+  async listProviders(
+    query: ListQuery,
+    options?: { deleted?: boolean; label?: string },
+  ): Promise<Page<Provider>> {
+    const { data } = await this.client.get<Page<Provider>>('/providers', {
+      params: { ...query, deleted: options?.deleted || undefined, label: options?.label || undefined },
+    });
+    return data;
+  }
+
   async getProvider(id: number): Promise<Provider> {
     const { data } = await this.client.get<Provider>(`/providers/${id}`);
     return data;
@@ -107,6 +172,16 @@ export class HttpTransport implements Transport {
     await this.client.delete(`/providers/${id}`);
   }
 
+  async getDeletedProviders(): Promise<Provider[]> {
+    const { data } = await this.client.get<Provider[]>('/providers', { params: { deleted: true } });
+    return data ?? [];
+  }
+
+  async restoreProvider(id: number): Promise<Provider> {
+    const { data } = await this.client.post<Provider>(`/providers/${id}/restore`);
+    return data;
+  }
+
   async exportProviders(): Promise<Provider[]> {
     const { data } = await this.client.get<Provider[]>('/providers/export');
     return data ?? [];
@@ -117,6 +192,22 @@ export class HttpTransport implements Transport {
     return data;
   }
 
+  async importExternalProviders(
+    files: ExternalImportFile[],
+    dryRun: boolean,
+  ): Promise<ExternalImportResult> {
+    const { data } = await this.client.post<ExternalImportResult>(
+      '/providers/import-external',
+      { files, dryRun },
+    );
+    return data;
+  }
+
+  async batchProviders(req: ProviderBatchRequest): Promise<ProviderBatchResult> {
+    const { data } = await this.client.post<ProviderBatchResult>('/providers/batch', req);
+    return data;
+  }
+
   // ===== Project API =====
 
   async getProjects(): Promise<Project[]> {
@@ -124,6 +215,11 @@ export class HttpTransport implements Transport {
     return data ?? [];
   }
 
+  async listProjects(query: ListQuery): Promise<Page<Project>> {
+    const { data } = await this.client.get<Page<Project>>('/projects', { params: query });
+    return data;
+  }
+
   async getProject(id: number): Promise<Project> {
     const { data } = await this.client.get<Project>(`/projects/${id}`);
     return data;
@@ -155,6 +251,13 @@ export class HttpTransport implements Transport {
     return data ?? [];
   }
 
+  async listRoutes(query: ListQuery, options?: { deleted?: boolean }): Promise<Page<Route>> {
+    const { data } = await this.client.get<Page<Route>>('/routes', {
+      params: { ...query, deleted: options?.deleted || undefined },
+    });
+    return data;
+  }
+
   async getRoute(id: number): Promise<Route> {
     const { data } = await this.client.get<Route>(`/routes/${id}`);
     return data;
@@ -174,17 +277,62 @@ export class HttpTransport implements Transport {
     await this.client.delete(`/routes/${id}`);
   }
 
+  async getDeletedRoutes(): Promise<Route[]> {
+    const { data } = await this.client.get<Route[]>('/routes', { params: { deleted: true } });
+    return data ?? [];
+  }
+
+  async restoreRoute(id: number): Promise<Route> {
+    const { data } = await this.client.post<Route>(`/routes/${id}/restore`);
+    return data;
+  }
+
   async batchUpdateRoutePositions(updates: RoutePositionUpdate[]): Promise<void> {
     await this.client.put('/routes/batch-positions', updates);
   }
 
+  async simulateRoutes(req: RouteSimulationRequest): Promise<RouteSimulation> {
+    const { data } = await this.client.post<RouteSimulation>('/routes/simulate', req);
+    return data;
+  }
+
+  async getRouteConversions(projectID?: number, providerID?: number): Promise<RouteConversion[]> {
+    const params: Record<string, number> = {};
+    if (projectID !== undefined) params.projectID = projectID;
+    if (providerID !== undefined) params.providerID = providerID;
+    const { data } = await this.client.get<RouteConversion[]>('/routes/conversions', { params });
+    return data ?? [];
+  }
+
+  async createConvertedRoutes(req: ConvertedRoutesRequest): Promise<Route[]> {
+    const { data } = await this.client.post<Route[]>('/routes/conversions', req);
+    return data ?? [];
+  }
+
+  async getEffectiveRetryConfig(routeId: number): Promise<EffectiveRetryConfig> {
+    const { data } = await this.client.get<EffectiveRetryConfig>(`/routes/${routeId}/retry-config`);
+    return data;
+  }
+
   // ===== Session API =====
 
-  async getSessions(): Promise<Session[]> {
-    const { data } = await this.client.get<Session[]>('/sessions');
+  async getSessions(includeArchived = false): Promise<Session[]> {
+    const { data } = await this.client.get<Session[]>('/sessions', {
+      params: includeArchived ? { include_archived: 'true' } : undefined,
+    });
     return data ?? [];
   }
 
+  async listSessions(
+    query: ListQuery,
+    options?: { includeArchived?: boolean },
+  ): Promise<Page<Session>> {
+    const { data } = await this.client.get<Page<Session>>('/sessions', {
+      params: { ...query, include_archived: options?.includeArchived ? 'true' : undefined },
+    });
+    return data;
+  }
+
   async updateSessionProject(
     sessionID: string,
     projectID: number,
@@ -203,6 +351,31 @@ export class HttpTransport implements Transport {
     return data;
   }
 
+  async pinSession(
+    sessionID: string,
+    pin: { routeID?: number; providerID?: number },
+  ): Promise<Session> {
+    const { data } = await this.client.put<Session>(
+      `/sessions/${encodeURIComponent(sessionID)}/pin`,
+      pin,
+    );
+    return data;
+  }
+
+  async unpinSession(sessionID: string): Promise<Session> {
+    const { data } = await this.client.delete<Session>(
+      `/sessions/${encodeURIComponent(sessionID)}/pin`,
+    );
+    return data;
+  }
+
+  async purgeExpiredSessions(
+    req: { idleHours?: number; action?: SessionIdleAction } = {},
+  ): Promise<SessionExpiryResult> {
+    const { data } = await this.client.post<SessionExpiryResult>('/sessions/purge-expired', req);
+    return data;
+  }
+
   // ===== RetryConfig API =====
 
   async getRetryConfigs(): Promise<RetryConfig[]> {
@@ -258,6 +431,107 @@ export class HttpTransport implements Transport {
     await this.client.delete(`/routing-strategies/${id}`);
   }
 
+  // ===== RoutingProfile API =====
+
+  async getRoutingProfiles(): Promise<RoutingProfile[]> {
+    const { data } = await this.client.get<RoutingProfile[]>('/routing-profiles');
+    return data ?? [];
+  }
+
+  async getRoutingProfile(id: number): Promise<RoutingProfile> {
+    const { data } = await this.client.get<RoutingProfile>(`/routing-profiles/${id}`);
+    return data;
+  }
+
+  async createRoutingProfile(payload: CreateRoutingProfileData): Promise<RoutingProfile> {
+    const { data } = await this.client.post<RoutingProfile>('/routing-profiles', payload);
+    return data;
+  }
+
+  async updateRoutingProfile(
+    id: number,
+    payload: Partial<RoutingProfile>,
+  ): Promise<RoutingProfile> {
+    const { data } = await this.client.put<RoutingProfile>(`/routing-profiles/${id}`, payload);
+    return data;
+  }
+
+  async deleteRoutingProfile(id: number): Promise<void> {
+    await this.client.delete(`/routing-profiles/${id}`);
+  }
+
+  async applyRoutingProfile(id: number): Promise<RoutingProfile> {
+    const { data } = await this.client.post<RoutingProfile>(`/routing-profiles/${id}/apply`);
+    return data;
+  }
+
+  async captureRoutingProfile(id: number): Promise<RoutingProfile> {
+    const { data } = await this.client.post<RoutingProfile>(`/routing-profiles/${id}/capture`);
+    return data;
+  }
+
+  async getActiveRoutingProfile(): Promise<ActiveRoutingProfile> {
+    const { data } = await this.client.get<ActiveRoutingProfile>('/routing-profiles/active');
+    return data;
+  }
+
+  // ===== CostAlert API =====
+
+  async getCostAlerts(): Promise<CostAlert[]> {
+    const { data } = await this.client.get<CostAlert[]>('/cost-alerts');
+    return data ?? [];
+  }
+
+  async getCostAlert(id: number): Promise<CostAlert> {
+    const { data } = await this.client.get<CostAlert>(`/cost-alerts/${id}`);
+    return data;
+  }
+
+  async createCostAlert(payload: CreateCostAlertData): Promise<CostAlert> {
+    const { data } = await this.client.post<CostAlert>('/cost-alerts', payload);
+    return data;
+  }
+
+  async updateCostAlert(id: number, payload: Partial<CostAlert>): Promise<CostAlert> {
+    const { data } = await this.client.put<CostAlert>(`/cost-alerts/${id}`, payload);
+    return data;
+  }
+
+  async deleteCostAlert(id: number): Promise<void> {
+    await this.client.delete(`/cost-alerts/${id}`);
+  }
+
+  // ===== LatencySLO API =====
+
+  async getLatencySLOs(): Promise<LatencySLO[]> {
+    const { data } = await this.client.get<LatencySLO[]>('/latency-slos');
+    return data ?? [];
+  }
+
+  async getLatencySLO(id: number): Promise<LatencySLO> {
+    const { data } = await this.client.get<LatencySLO>(`/latency-slos/${id}`);
+    return data;
+  }
+
+  async createLatencySLO(payload: CreateLatencySLOData): Promise<LatencySLO> {
+    const { data } = await this.client.post<LatencySLO>('/latency-slos', payload);
+    return data;
+  }
+
+  async updateLatencySLO(id: number, payload: Partial<LatencySLO>): Promise<LatencySLO> {
+    const { data } = await this.client.put<LatencySLO>(`/latency-slos/${id}`, payload);
+    return data;
+  }
+
+  async deleteLatencySLO(id: number): Promise<void> {
+    await this.client.delete(`/latency-slos/${id}`);
+  }
+
+  async getLatencySLOStats(): Promise<LatencySLOStatus[]> {
+    const { data } = await this.client.get<LatencySLOStatus[]>('/latency-slo-stats');
+    return data ?? [];
+  }
+
   // ===== ProxyRequest API =====
 
   async getProxyRequests(
@@ -286,6 +560,25 @@ export class HttpTransport implements Transport {
     return data ?? [];
   }
 
+  async downloadProxyRequestBody(
+    proxyRequestId: number,
+    part: 'request' | 'response',
+    attemptId?: number,
+  ): Promise<Blob> {
+    const base = attemptId
+      ? `/requests/${proxyRequestId}/attempts/${attemptId}`
+      : `/requests/${proxyRequestId}`;
+    const { data } = await this.client.get<Blob>(`${base}/${part}-body`, {
+      responseType: 'blob',
+    });
+    return data;
+  }
+
+  async getProxyRequestDiff(proxyRequestId: number): Promise<ProxyRequestDiff> {
+    const { data } = await this.client.get<ProxyRequestDiff>(`/requests/${proxyRequestId}/diff`);
+    return data;
+  }
+
   // ===== Proxy Status API =====
 
   async getProxyStatus(): Promise<ProxyStatus> {
@@ -293,6 +586,11 @@ export class HttpTransport implements Transport {
     return data;
   }
 
+  async getRecoverySummary(): Promise<RecoverySummary | null> {
+    const { data } = await this.client.get<RecoverySummary | null>('/recovery-summary');
+    return data;
+  }
+
   // ===== Provider Stats API =====
 
   async getProviderStats(
@@ -308,6 +606,24 @@ export class HttpTransport implements Transport {
     return data ?? {};
   }
 
+  async getProviderQuotas(): Promise<ProviderQuotaStatus[]> {
+    const { data } = await this.client.get<ProviderQuotaStatus[]>('/provider-quotas');
+    return data ?? [];
+  }
+
+  async resetProviderQuota(providerId: number): Promise<void> {
+    await this.client.delete(`/provider-quotas/${providerId}`);
+  }
+
+  async getFailbackStatus(): Promise<FailbackStatus[]> {
+    const { data } = await this.client.get<FailbackStatus[]>('/failback');
+    return data ?? [];
+  }
+
+  async promoteProvider(providerId: number): Promise<void> {
+    await this.client.delete(`/failback/${providerId}`);
+  }
+
   // ===== Settings API =====
 
   async getSettings(): Promise<Record<string, string>> {
@@ -340,27 +656,84 @@ export class HttpTransport implements Transport {
     return data ?? { lines: [], count: 0 };
   }
 
+  async queryAuditEvents(query: AuditQuery = {}): Promise<{ events: AuditEvent[]; count: number }> {
+    const { data } = await this.client.get<{ events: AuditEvent[]; count: number }>('/audit', {
+      params: {
+        event: query.event,
+        session_id: query.sessionId,
+        request_id: query.requestId,
+        since: query.since,
+        limit: query.limit,
+      },
+    });
+    return data ?? { events: [], count: 0 };
+  }
+
+  async downloadDiagnosticBundle(failed?: number, logLines?: number): Promise<Blob> {
+    const { data } = await this.client.get<Blob>('/diagnostics/bundle', {
+      params: { failed, log_lines: logLines },
+      responseType: 'blob',
+    });
+    return data;
+  }
+
+  async queryQualitySamples(
+    query: QualitySampleQuery = {},
+  ): Promise<{ samples: QualitySample[]; count: number }> {
+    const { data } = await this.client.get<{ samples: QualitySample[]; count: number }>(
+      '/quality-samples',
+      { params: this.qualitySampleParams(query) },
+    );
+    return data ?? { samples: [], count: 0 };
+  }
+
+  async exportQualitySamples(query: QualitySampleQuery = {}): Promise<Blob> {
+    const { data } = await this.client.get<Blob>('/quality-samples/export', {
+      params: this.qualitySampleParams(query),
+      responseType: 'blob',
+    });
+    return data;
+  }
+
+  private qualitySampleParams(query: QualitySampleQuery) {
+    return {
+      provider_id: query.providerId,
+      model: query.model,
+      tag: query.tag,
+      since: query.since,
+      until: query.until,
+      limit: query.limit,
+    };
+  }
+
+  // ===== Batch Run API =====
+
+  async runBatch(req: BatchRunRequest): Promise<BatchRunResult> {
+    const { data } = await this.client.post<BatchRunResult>('/batch-runs', req);
+    return data;
+  }
+
   // ===== Antigravity API =====
 
   async validateAntigravityToken(refreshToken: string): Promise<AntigravityTokenValidationResult> {
-    const { data } = await axios.post<AntigravityTokenValidationResult>(
-      '/api/antigravity/validate-token',
+    const { data } = await this.apiClient.post<AntigravityTokenValidationResult>(
+      '/antigravity/validate-token',
       { refreshToken },
     );
     return data;
   }
 
   async validateAntigravityTokens(tokens: string[]): Promise<AntigravityBatchValidationResult> {
-    const { data } = await axios.post<AntigravityBatchValidationResult>(
-      '/api/antigravity/validate-tokens',
+    const { data } = await this.apiClient.post<AntigravityBatchValidationResult>(
+      '/antigravity/validate-tokens',
       { tokens },
     );
     return data;
   }
 
   async validateAntigravityTokenText(tokenText: string): Promise<AntigravityBatchValidationResult> {
-    const { data } = await axios.post<AntigravityBatchValidationResult>(
-      '/api/antigravity/validate-tokens',
+    const { data } = await this.apiClient.post<AntigravityBatchValidationResult>(
+      '/antigravity/validate-tokens',
       { tokenText },
     );
     return data;
@@ -371,23 +744,35 @@ export class HttpTransport implements Transport {
     forceRefresh?: boolean,
   ): Promise<AntigravityQuotaData> {
     const params = forceRefresh ? { refresh: 'true' } : undefined;
-    const { data } = await axios.get<AntigravityQuotaData>(
-      `/api/antigravity/providers/${providerId}/quota`,
+    const { data } = await this.apiClient.get<AntigravityQuotaData>(
+      `/antigravity/providers/${providerId}/quota`,
       { params },
     );
     return data;
   }
 
   async getAntigravityBatchQuotas(): Promise<Record<number, AntigravityQuotaData>> {
-    const { data } = await axios.get<{ quotas: Record<number, AntigravityQuotaData> }>(
-      '/api/antigravity/providers/quotas',
+    const { data } = await this.apiClient.get<{ quotas: Record<number, AntigravityQuotaData> }>(
+      '/antigravity/providers/quotas',
     );
     return data.quotas;
   }
 
+  async replayAntigravityAttempt(
+    proxyRequestID: number,
+    attemptID: number,
+    providerID: number,
+  ): Promise<AntigravityReplayResult> {
+    const { data } = await this.client.post<AntigravityReplayResult>(
+      `/requests/${proxyRequestID}/attempts/${attemptID}/replay`,
+      { providerID },
+    );
+    return data;
+  }
+
   async startAntigravityOAuth(): Promise<{ authURL: string; state: string }> {
-    const { data } = await axios.post<{ authURL: string; state: string }>(
-      '/api/antigravity/oauth/start',
+    const { data } = await this.apiClient.post<{ authURL: string; state: string }>(
+      '/antigravity/oauth/start',
     );
     return data;
   }
@@ -421,18 +806,79 @@ export class HttpTransport implements Transport {
     await this.client.post('/model-mappings/reset-defaults');
   }
 
+  async getModelMappingOutcomes(): Promise<ModelMappingOutcome[]> {
+    const { data } = await this.client.get<ModelMappingOutcome[]>('/model-mappings/outcomes');
+    return data ?? [];
+  }
+
+  async clearModelMappingOutcomes(): Promise<void> {
+    await this.client.delete('/model-mappings/outcomes');
+  }
+
+  async getModelMappingSuggestions(
+    minAttempts?: number,
+    failureRate?: number,
+  ): Promise<ModelMappingSuggestion[]> {
+    const { data } = await this.client.get<ModelMappingSuggestion[]>('/model-mappings/suggestions', {
+      params: { min_attempts: minAttempts, failure_rate: failureRate },
+    });
+    return data ?? [];
+  }
+
+  // ===== Model Capability API =====
+
+  async getModelCapabilities(): Promise<ModelCapability[]> {
+    const { data } = await this.client.get<ModelCapability[]>('/model-capabilities');
+    return data ?? [];
+  }
+
+  async getBuiltinModelCapabilities(): Promise<ModelCapability[]> {
+    const { data } = await this.client.get<ModelCapability[]>('/model-capabilities/builtin');
+    return data ?? [];
+  }
+
+  async resolveModelCapabilities(model: string, providerId?: number): Promise<ModelCapabilities> {
+    const { data } = await this.client.get<ModelCapabilities>('/model-capabilities/resolve', {
+      params: { model, provider_id: providerId },
+    });
+    return data;
+  }
+
+  async createModelCapability(input: ModelCapabilityInput): Promise<ModelCapability> {
+    const { data } = await this.client.post<ModelCapability>('/model-capabilities', input);
+    return data;
+  }
+
+  async updateModelCapability(id: number, input: ModelCapabilityInput): Promise<ModelCapability> {
+    const { data } = await this.client.put<ModelCapability>(`/model-capabilities/${id}`, input);
+    return data;
+  }
+
+  async deleteModelCapability(id: number): Promise<void> {
+    await this.client.delete(`/model-capabilities/${id}`);
+  }
+
+  // ===== Config Health API =====
+
+  async getConfigReport(refresh = false): Promise<ConfigReport> {
+    const { data } = refresh
+      ? await this.client.post<ConfigReport>('/health/config')
+      : await this.client.get<ConfigReport>('/health/config');
+    return data;
+  }
+
   // ===== Kiro API =====
 
   async validateKiroSocialToken(refreshToken: string): Promise<KiroTokenValidationResult> {
-    const { data } = await axios.post<KiroTokenValidationResult>(
-      '/api/kiro/validate-social-token',
+    const { data } = await this.apiClient.post<KiroTokenValidationResult>(
+      '/kiro/validate-social-token',
       { refreshToken },
     );
     return data;
   }
 
   async getKiroProviderQuota(providerId: number): Promise<KiroQuotaData> {
-    const { data } = await axios.get<KiroQuotaData>(`/api/kiro/providers/${providerId}/quota`);
+    const { data } = await this.apiClient.get<KiroQuotaData>(`/kiro/providers/${providerId}/quota`);
     return data;
   }
 
@@ -443,6 +889,11 @@ export class HttpTransport implements Transport {
     return data ?? [];
   }
 
+  async getCooldownStats(params?: CooldownStatsParams): Promise<CooldownStats> {
+    const { data } = await this.client.get<CooldownStats>('/cooldowns/stats', { params });
+    return data;
+  }
+
   async clearCooldown(providerId: number): Promise<void> {
     await this.client.delete(`/cooldowns/${providerId}`);
   }
@@ -461,6 +912,13 @@ export class HttpTransport implements Transport {
 
   setAuthToken(token: string): void {
     this.authToken = token;
+    // The WebSocket authenticates on the handshake, so reconnect with the new token
+    if (this.ws) {
+      this.ws.onclose = null;
+      this.reconnectAttempts = 0;
+      this.disconnect();
+      this.connect().catch(console.error);
+    }
   }
 
   clearAuthToken(): void {
@@ -512,6 +970,44 @@ export class HttpTransport implements Transport {
     return data ?? [];
   }
 
+  async getRequestShapeStats(filter?: RequestShapeFilter): Promise<RequestShapeStats[]> {
+    const params = new URLSearchParams();
+    if (filter?.start) params.set('start', filter.start);
+    if (filter?.end) params.set('end', filter.end);
+    if (filter?.providerId) params.set('providerId', String(filter.providerId));
+    if (filter?.projectId) params.set('projectId', String(filter.projectId));
+    if (filter?.clientType) params.set('clientType', filter.clientType);
+
+    const query = params.toString();
+    const url = query ? `/request-shape-stats?${query}` : '/request-shape-stats';
+    const { data } = await this.client.get<RequestShapeStats[]>(url);
+    return data ?? [];
+  }
+
+  async getExperimentStats(filter?: ExperimentStatsFilter): Promise<ExperimentArmStats[]> {
+    const params = new URLSearchParams();
+    if (filter?.start) params.set('start', filter.start);
+    if (filter?.end) params.set('end', filter.end);
+    if (filter?.experiment) params.set('experiment', filter.experiment);
+
+    const query = params.toString();
+    const url = query ? `/experiment-stats?${query}` : '/experiment-stats';
+    const { data } = await this.client.get<ExperimentArmStats[]>(url);
+    return data ?? [];
+  }
+
+  async getPromptCacheAdvice(filter?: PromptCacheAdvisorFilter): Promise<PromptCacheAdvice[]> {
+    const params = new URLSearchParams();
+    if (filter?.start) params.set('start', filter.start);
+    if (filter?.end) params.set('end', filter.end);
+    if (filter?.routeId) params.set('routeId', String(filter.routeId));
+
+    const query = params.toString();
+    const url = query ? `/prompt-cache-advisor?${query}` : '/prompt-cache-advisor';
+    const { data } = await this.client.get<PromptCacheAdvice[]>(url);
+    return data ?? [];
+  }
+
   async recalculateUsageStats(): Promise<void> {
     await this.client.post('/usage-stats/recalculate');
   }
@@ -550,7 +1046,11 @@ export class HttpTransport implements Transport {
     }
 
     this.connectPromise = new Promise((resolve, reject) => {
-      this.ws = new WebSocket(this.config.wsURL);
+      // Browsers cannot set headers on the handshake, so the token goes in the query
+      const wsURL = this.authToken
+        ? `${this.config.wsURL}?token=${encodeURIComponent(this.authToken)}`
+        : this.config.wsURL;
+      this.ws = new WebSocket(wsURL);
 
       this.ws.onopen = () => {
         const isReconnect = this.reconnectAttempts > 0;
@@ -571,8 +1071,12 @@ export class HttpTransport implements Transport {
         reject(error);
       };
 
-      this.ws.onclose = () => {
+      this.ws.onclose = (event) => {
         this.connectPromise = null;
+        // 1008: not logged in; setAuthToken reconnects after login
+        if (event.code === 1008) {
+          return;
+        }
         this.scheduleReconnect();
       };
 
@@ -10,33 +10,80 @@ export type {
   ProviderConfig,
   ProviderConfigCustom,
   ProviderConfigAntigravity,
+  ThoughtSignatureMode,
   CreateProviderData,
   Project,
   CreateProjectData,
   Session,
+  SessionIdleAction,
+  SessionExpiryResult,
   Route,
+  RouteSystemPrompt,
+  RouteIdentityPatch,
+  RouteExperiment,
+  ExperimentArm,
+  SystemPromptMode,
   CreateRouteData,
   RoutePositionUpdate,
+  RouteSimulationRequest,
+  RouteCandidate,
+  RouteSimulation,
+  RouteConversion,
+  ConvertedRoutesRequest,
   RetryConfig,
   CreateRetryConfigData,
+  RetryConfigSource,
+  EffectiveRetryConfig,
   RoutingStrategy,
   RoutingStrategyType,
   RoutingStrategyConfig,
   CreateRoutingStrategyData,
+  RoutingProfile,
+  RoutingProfileRoute,
+  RoutingProfileSchedule,
+  CreateRoutingProfileData,
+  ActiveRoutingProfile,
+  CostAlertScope,
+  CostAlert,
+  CreateCostAlertData,
+  CostAlertNotification,
+  LatencySLOMetric,
+  LatencySLO,
+  CreateLatencySLOData,
+  LatencySLOStatus,
+  LatencySLOEvent,
   ProxyRequest,
   ProxyRequestStatus,
+  ErrorCode,
+  AttemptAction,
+  AttemptDecisionReason,
+  AttemptDecision,
+  RetryStep,
+  RouterDecisionMode,
+  RouterStrategyInput,
+  RouterDecisionCandidate,
+  RouterDecision,
   ProxyUpstreamAttempt,
+  JSONChange,
+  BodyDiff,
+  AttemptDiff,
+  ProxyRequestDiff,
   ProxyUpstreamAttemptStatus,
   RequestInfo,
   ResponseInfo,
   ProviderStats,
+  ProviderQuotaStatus,
+  FailbackStatus,
   // 分页
   PaginationParams,
   CursorPaginationParams,
   CursorPaginationResult,
+  ListQuery,
+  Page,
   // WebSocket
   WSMessageType,
   WSMessage,
+  AttemptProgress,
   // 回调
   EventCallback,
   UnsubscribeFn,
@@ -44,6 +91,7 @@ export type {
   AntigravityUserInfo,
   AntigravityModelQuota,
   AntigravityQuotaData,
+  AntigravityReplayResult,
   AntigravityBatchQuotaResult,
   AntigravityTokenValidationResult,
   AntigravityBatchValidationResult,
@@ -51,13 +99,47 @@ export type {
   // Model Mapping
   ModelMapping,
   ModelMappingInput,
+  ModelMappingOutcome,
+  ModelMappingSuggestion,
+  AuditEventName,
+  AuditEvent,
+  AuditQuery,
+  QualitySampleTag,
+  QualitySample,
+  QualitySampleQuery,
+  ModelCapability,
+  ModelCapabilityInput,
+  ModelCapabilities,
+  // Config Health
+  ConfigIssueSeverity,
+  ConfigIssue,
+  ConfigReport,
   // Kiro
   KiroTokenValidationResult,
   KiroQuotaData,
   // Import
   ImportResult,
+  ExternalImportFile,
+  ExternalImportStatus,
+  ExternalImportProvider,
+  ExternalImportResult,
+  ProviderBatchAction,
+  ProviderBatchStatus,
+  ProviderBatchRequest,
+  ProviderBatchItem,
+  ProviderBatchResult,
+  // Batch Run
+  BatchRunRequest,
+  BatchRunItem,
+  BatchRunProviderSummary,
+  BatchRunResult,
   // Cooldown
   Cooldown,
+  CooldownStats,
+  CooldownProviderStats,
+  CooldownReasonStats,
+  CooldownStatsBucket,
+  CooldownStatsParams,
   // API Token
   APIToken,
   APITokenCreateResult,
@@ -66,6 +148,14 @@ export type {
   UsageStats,
   UsageStatsFilter,
   StatsGranularity,
+  RequestShapeFilter,
+  RequestShapeStats,
+  ExperimentStatsFilter,
+  ExperimentArmStats,
+  PromptCacheAdvisorFilter,
+  PromptCacheRecommendation,
+  PromptCacheAdvice,
+  AgentLoopWarning,
 } from './types';
 
 export type { Transport, TransportType, TransportConfig } from './interface';
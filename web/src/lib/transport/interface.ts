@@ -9,28 +9,63 @@ import type {
   Project,
   CreateProjectData,
   Session,
+  SessionIdleAction,
+  SessionExpiryResult,
   Route,
   CreateRouteData,
   RetryConfig,
   CreateRetryConfigData,
   RoutingStrategy,
   CreateRoutingStrategyData,
+  RoutingProfile,
+  CreateRoutingProfileData,
+  ActiveRoutingProfile,
+  CostAlert,
+  CreateCostAlertData,
+  LatencySLO,
+  CreateLatencySLOData,
+  LatencySLOStatus,
   ProxyRequest,
   ProxyUpstreamAttempt,
+  ProxyRequestDiff,
   CursorPaginationParams,
   CursorPaginationResult,
+  ListQuery,
+  Page,
   ProxyStatus,
+  RecoverySummary,
   ProviderStats,
+  ProviderQuotaStatus,
+  FailbackStatus,
   WSMessageType,
   EventCallback,
   UnsubscribeFn,
   AntigravityTokenValidationResult,
   AntigravityBatchValidationResult,
   AntigravityQuotaData,
+  AntigravityReplayResult,
   ModelMapping,
   ModelMappingInput,
+  ModelMappingOutcome,
+  ModelMappingSuggestion,
+  AuditEvent,
+  AuditQuery,
+  QualitySample,
+  QualitySampleQuery,
+  ModelCapability,
+  ModelCapabilityInput,
+  ModelCapabilities,
+  ConfigReport,
   ImportResult,
+  ExternalImportFile,
+  ExternalImportResult,
+  ProviderBatchRequest,
+  ProviderBatchResult,
+  BatchRunRequest,
+  BatchRunResult,
   Cooldown,
+  CooldownStats,
+  CooldownStatsParams,
   KiroTokenValidationResult,
   KiroQuotaData,
   AuthStatus,
@@ -39,8 +74,19 @@ import type {
   APITokenCreateResult,
   CreateAPITokenData,
   RoutePositionUpdate,
+  RouteSimulationRequest,
+  RouteSimulation,
+  RouteConversion,
+  ConvertedRoutesRequest,
+  EffectiveRetryConfig,
   UsageStats,
   UsageStatsFilter,
+  RequestShapeFilter,
+  RequestShapeStats,
+  ExperimentStatsFilter,
+  ExperimentArmStats,
+  PromptCacheAdvisorFilter,
+  PromptCacheAdvice,
 } from './types';
 
 /**
@@ -49,15 +95,24 @@ import type {
 export interface Transport {
   // ===== Provider API =====
   getProviders(): Promise<Provider[]>;
+  listProviders(query: ListQuery, options?: { deleted?: boolean; label?: string }): Promise<Page<Provider>>;
   getProvider(id: number): Promise<Provider>;
   createProvider(data: CreateProviderData): Promise<Provider>;
   updateProvider(id: number, data: Partial<Provider>): Promise<Provider>;
   deleteProvider(id: number): Promise<void>;
+  getDeletedProviders(): Promise<Provider[]>;
+  restoreProvider(id: number): Promise<Provider>;
   exportProviders(): Promise<Provider[]>;
   importProviders(providers: Provider[]): Promise<ImportResult>;
+  importExternalProviders(
+    files: ExternalImportFile[],
+    dryRun: boolean,
+  ): Promise<ExternalImportResult>;
+  batchProviders(req: ProviderBatchRequest): Promise<ProviderBatchResult>;
 
   // ===== Project API =====
   getProjects(): Promise<Project[]>;
+  listProjects(query: ListQuery): Promise<Page<Project>>;
   getProject(id: number): Promise<Project>;
   getProjectBySlug(slug: string): Promise<Project>;
   createProject(data: CreateProjectData): Promise<Project>;
@@ -66,19 +121,33 @@ export interface Transport {
 
   // ===== Route API =====
   getRoutes(): Promise<Route[]>;
+  listRoutes(query: ListQuery, options?: { deleted?: boolean }): Promise<Page<Route>>;
   getRoute(id: number): Promise<Route>;
   createRoute(data: CreateRouteData): Promise<Route>;
   updateRoute(id: number, data: Partial<Route>): Promise<Route>;
   deleteRoute(id: number): Promise<void>;
+  getDeletedRoutes(): Promise<Route[]>;
+  restoreRoute(id: number): Promise<Route>;
   batchUpdateRoutePositions(updates: RoutePositionUpdate[]): Promise<void>;
+  simulateRoutes(req: RouteSimulationRequest): Promise<RouteSimulation>;
+  getRouteConversions(projectID?: number, providerID?: number): Promise<RouteConversion[]>;
+  createConvertedRoutes(req: ConvertedRoutesRequest): Promise<Route[]>;
+  getEffectiveRetryConfig(routeId: number): Promise<EffectiveRetryConfig>;
 
   // ===== Session API =====
-  getSessions(): Promise<Session[]>;
+  getSessions(includeArchived?: boolean): Promise<Session[]>;
+  listSessions(query: ListQuery, options?: { includeArchived?: boolean }): Promise<Page<Session>>;
   updateSessionProject(
     sessionID: string,
     projectID: number,
   ): Promise<{ session: Session; updatedRequests: number }>;
   rejectSession(sessionID: string): Promise<Session>;
+  pinSession(sessionID: string, pin: { routeID?: number; providerID?: number }): Promise<Session>;
+  unpinSession(sessionID: string): Promise<Session>;
+  purgeExpiredSessions(req?: {
+    idleHours?: number;
+    action?: SessionIdleAction;
+  }): Promise<SessionExpiryResult>;
 
   // ===== RetryConfig API =====
   getRetryConfigs(): Promise<RetryConfig[]>;
@@ -94,17 +163,54 @@ export interface Transport {
   updateRoutingStrategy(id: number, data: Partial<RoutingStrategy>): Promise<RoutingStrategy>;
   deleteRoutingStrategy(id: number): Promise<void>;
 
+  // ===== RoutingProfile API =====
+  getRoutingProfiles(): Promise<RoutingProfile[]>;
+  getRoutingProfile(id: number): Promise<RoutingProfile>;
+  createRoutingProfile(data: CreateRoutingProfileData): Promise<RoutingProfile>;
+  updateRoutingProfile(id: number, data: Partial<RoutingProfile>): Promise<RoutingProfile>;
+  deleteRoutingProfile(id: number): Promise<void>;
+  applyRoutingProfile(id: number): Promise<RoutingProfile>;
+  captureRoutingProfile(id: number): Promise<RoutingProfile>;
+  getActiveRoutingProfile(): Promise<ActiveRoutingProfile>;
+
+  // ===== CostAlert API =====
+  getCostAlerts(): Promise<CostAlert[]>;
+  getCostAlert(id: number): Promise<CostAlert>;
+  createCostAlert(data: CreateCostAlertData): Promise<CostAlert>;
+  updateCostAlert(id: number, data: Partial<CostAlert>): Promise<CostAlert>;
+  deleteCostAlert(id: number): Promise<void>;
+
+  // ===== LatencySLO API =====
+  getLatencySLOs(): Promise<LatencySLO[]>;
+  getLatencySLO(id: number): Promise<LatencySLO>;
+  createLatencySLO(data: CreateLatencySLOData): Promise<LatencySLO>;
+  updateLatencySLO(id: number, data: Partial<LatencySLO>): Promise<LatencySLO>;
+  deleteLatencySLO(id: number): Promise<void>;
+  getLatencySLOStats(): Promise<LatencySLOStatus[]>;
+
   // ===== ProxyRequest API (只读) =====
   getProxyRequests(params?: CursorPaginationParams): Promise<CursorPaginationResult<ProxyRequest>>;
   getProxyRequestsCount(): Promise<number>;
   getProxyRequest(id: number): Promise<ProxyRequest>;
   getProxyUpstreamAttempts(proxyRequestId: number): Promise<ProxyUpstreamAttempt[]>;
+  getProxyRequestDiff(proxyRequestId: number): Promise<ProxyRequestDiff>;
+  // 下载存储的原始请求/响应体，attemptId 为空时为客户端请求/响应
+  downloadProxyRequestBody(
+    proxyRequestId: number,
+    part: 'request' | 'response',
+    attemptId?: number,
+  ): Promise<Blob>;
 
   // ===== Proxy Status API =====
   getProxyStatus(): Promise<ProxyStatus>;
+  getRecoverySummary(): Promise<RecoverySummary | null>;
 
   // ===== Provider Stats API =====
   getProviderStats(clientType?: string, projectId?: number): Promise<Record<number, ProviderStats>>;
+  getProviderQuotas(): Promise<ProviderQuotaStatus[]>;
+  resetProviderQuota(providerId: number): Promise<void>;
+  getFailbackStatus(): Promise<FailbackStatus[]>;
+  promoteProvider(providerId: number): Promise<void>;
 
   // ===== Settings API =====
   getSettings(): Promise<Record<string, string>>;
@@ -114,6 +220,13 @@ export interface Transport {
 
   // ===== Logs API =====
   getLogs(limit?: number): Promise<{ lines: string[]; count: number }>;
+  queryAuditEvents(query?: AuditQuery): Promise<{ events: AuditEvent[]; count: number }>;
+  downloadDiagnosticBundle(failed?: number, logLines?: number): Promise<Blob>;
+  queryQualitySamples(query?: QualitySampleQuery): Promise<{ samples: QualitySample[]; count: number }>;
+  exportQualitySamples(query?: QualitySampleQuery): Promise<Blob>;
+
+  // ===== Batch Run API =====
+  runBatch(req: BatchRunRequest): Promise<BatchRunResult>;
 
   // ===== Antigravity API =====
   validateAntigravityToken(refreshToken: string): Promise<AntigravityTokenValidationResult>;
@@ -124,6 +237,11 @@ export interface Transport {
     forceRefresh?: boolean,
   ): Promise<AntigravityQuotaData>;
   getAntigravityBatchQuotas(): Promise<Record<number, AntigravityQuotaData>>;
+  replayAntigravityAttempt(
+    proxyRequestID: number,
+    attemptID: number,
+    providerID: number,
+  ): Promise<AntigravityReplayResult>;
   startAntigravityOAuth(): Promise<{ authURL: string; state: string }>;
 
   // ===== Model Mapping API =====
@@ -133,6 +251,20 @@ export interface Transport {
   deleteModelMapping(id: number): Promise<void>;
   clearAllModelMappings(): Promise<void>;
   resetModelMappingsToDefaults(): Promise<void>;
+  getModelMappingOutcomes(): Promise<ModelMappingOutcome[]>;
+  clearModelMappingOutcomes(): Promise<void>;
+  getModelMappingSuggestions(minAttempts?: number, failureRate?: number): Promise<ModelMappingSuggestion[]>;
+
+  // ===== Model Capability API =====
+  getModelCapabilities(): Promise<ModelCapability[]>;
+  getBuiltinModelCapabilities(): Promise<ModelCapability[]>;
+  resolveModelCapabilities(model: string, providerId?: number): Promise<ModelCapabilities>;
+  createModelCapability(data: ModelCapabilityInput): Promise<ModelCapability>;
+  updateModelCapability(id: number, data: ModelCapabilityInput): Promise<ModelCapability>;
+  deleteModelCapability(id: number): Promise<void>;
+
+  // ===== Config Health API =====
+  getConfigReport(refresh?: boolean): Promise<ConfigReport>;
 
   // ===== Kiro API =====
   validateKiroSocialToken(refreshToken: string): Promise<KiroTokenValidationResult>;
@@ -140,6 +272,7 @@ export interface Transport {
 
   // ===== Cooldown API =====
   getCooldowns(): Promise<Cooldown[]>;
+  getCooldownStats(params?: CooldownStatsParams): Promise<CooldownStats>;
   clearCooldown(providerId: number): Promise<void>;
 
   // ===== Auth API =====
@@ -157,6 +290,9 @@ export interface Transport {
 
   // ===== Usage Stats API =====
   getUsageStats(filter?: UsageStatsFilter): Promise<UsageStats[]>;
+  getRequestShapeStats(filter?: RequestShapeFilter): Promise<RequestShapeStats[]>;
+  getExperimentStats(filter?: ExperimentStatsFilter): Promise<ExperimentArmStats[]>;
+  getPromptCacheAdvice(filter?: PromptCacheAdvisorFilter): Promise<PromptCacheAdvice[]>;
   recalculateUsageStats(): Promise<void>;
 
   // ===== Response Model API =====
@@ -14,6 +14,8 @@ export interface ProviderConfigCustom {
   apiKey: string;
   clientBaseURL?: Partial<Record<ClientType, string>>;
   modelMapping?: Record<string, string>;
+  openaiOrganization?: string;
+  openaiProject?: string;
 }
 
 export interface ProviderConfigAntigravity {
@@ -22,8 +24,12 @@ export interface ProviderConfigAntigravity {
   projectID: string;
   endpoint: string;
   modelMapping?: Record<string, string>;
+  thoughtSignatureMode?: ThoughtSignatureMode;
 }
 
+// 工具调用 thoughtSignature 的处理方式，'' 表示使用上一级设置（路由 → Provider → auto）
+export type ThoughtSignatureMode = '' | 'auto' | 'strict' | 'always_skip';
+
 export interface ProviderConfigKiro {
   authMethod: 'social' | 'idc';
   email?: string;
@@ -34,10 +40,40 @@ export interface ProviderConfigKiro {
   modelMapping?: Record<string, string>;
 }
 
+// 出站 HTTP 设置
+export interface ProviderHTTPConfig {
+  proxyURL?: string;
+  connectTimeoutSeconds?: number;
+}
+
+// 本地配额，用完后跳过该供应商直到下一个重置时间
+export interface ProviderQuotaConfig {
+  requestsPerDay?: number; // 每个重置周期，0 或空表示不限制
+  tokensPerDay?: number; // 输入 + 输出
+}
+
+// 配额重置时间，未配置时每天 00:00（服务器本地时间）
+export interface ProviderResetSchedule {
+  time?: string; // "HH:MM"
+  cron?: string; // 分 时 日 月 周，设置后忽略 time
+  timezone?: string; // IANA 时区，如 "America/Los_Angeles"
+}
+
+// 响应返回给客户端前的后处理，错误响应不处理
+export interface ProviderResponseRules {
+  stripText?: string[]; // 从文本内容中删除的水印/前缀
+  rewriteModel?: boolean; // 响应中的模型名改写为客户端请求的模型名
+  removeFields?: string[]; // 删除的 JSON 字段，点号路径，如 "usage.provider_meta"
+}
+
 export interface ProviderConfig {
   custom?: ProviderConfigCustom;
   antigravity?: ProviderConfigAntigravity;
   kiro?: ProviderConfigKiro;
+  http?: ProviderHTTPConfig;
+  quota?: ProviderQuotaConfig;
+  resetSchedule?: ProviderResetSchedule;
+  responseRules?: ProviderResponseRules;
 }
 
 export interface Provider {
@@ -50,6 +86,10 @@ export interface Provider {
   config: ProviderConfig | null;
   supportedClientTypes: ClientType[];
   supportModels?: string[]; // 支持的模型列表（通配符模式），空数组表示支持所有模型
+  retryConfigID?: number; // 该供应商下路由的默认重试配置，0 表示使用系统默认
+  disabled?: boolean; // 停用后不参与路由
+  labels?: string[]; // 标签，用于筛选和批量操作
+  deletedAt?: string; // 软删除时间，已删除的 Provider 可恢复
 }
 
 // supportedClientTypes 可选，后端会根据 provider type 自动设置
@@ -70,10 +110,16 @@ export interface Project {
   name: string;
   slug: string;
   enabledCustomRoutes: ClientType[];
+  // Claude 后台小模型请求由本地应答器直接返回，不消耗上游配额
+  localBackgroundResponder: boolean;
 }
 
-export type CreateProjectData = Omit<Project, 'id' | 'createdAt' | 'updatedAt' | 'slug'> & {
+export type CreateProjectData = Omit<
+  Project,
+  'id' | 'createdAt' | 'updatedAt' | 'slug' | 'localBackgroundResponder'
+> & {
   slug?: string;
+  localBackgroundResponder?: boolean;
 };
 
 // ===== Session =====
@@ -85,6 +131,21 @@ export interface Session {
   sessionID: string;
   clientType: ClientType;
   projectID: number;
+  // 会话固定路由，0 表示不固定（pinnedRouteID 优先）
+  pinnedRouteID: number;
+  pinnedProviderID: number;
+  lastActiveAt?: string; // 最近一次请求的时间
+  archivedAt?: string; // 因空闲被归档的时间
+}
+
+// 空闲会话处理方式
+export type SessionIdleAction = 'unbind' | 'archive';
+
+// 空闲会话清理结果（sessions_expired 事件和清理接口的返回）
+export interface SessionExpiryResult {
+  action: SessionIdleAction;
+  idleHours: number;
+  sessionIDs: string[];
 }
 
 // ===== Route =====
@@ -101,6 +162,44 @@ export interface Route {
   position: number;
   retryConfigID: number;
   modelMapping?: Record<string, string>;
+  systemPrompt?: RouteSystemPrompt;
+  thoughtSignatureMode?: ThoughtSignatureMode; // 仅对 Antigravity Provider 生效
+  pingIntervalSeconds?: number; // 上游无输出时发送 Claude ping 事件的间隔（秒），0 为默认 15 秒，负数不发送；仅 Antigravity
+  identityPatch?: RouteIdentityPatch; // 未设置时使用默认身份补丁
+  hideReasoning?: boolean; // OpenAI 客户端经 Gemini 上游时不返回 reasoning_content
+  experiment?: RouteExperiment;
+  deletedAt?: string; // 软删除时间，已删除的路由可恢复
+}
+
+// 路由级 A/B 实验：同一会话的请求始终分到同一组
+export interface RouteExperiment {
+  enabled: boolean;
+  name: string;
+  a: ExperimentArm; // 对照组
+  b: ExperimentArm;
+  splitPercent: number; // 分给 B 组的流量百分比
+}
+
+export interface ExperimentArm {
+  providerID?: number; // 未设置时使用路由自身的供应商
+  model?: string; // 未设置时按正常的模型映射
+}
+
+// Claude / OpenAI 请求转换为 Gemini 格式时加在 system prompt 前的身份补丁
+// 模板支持变量 {{model}}、{{clientType}}、{{provider}}
+export interface RouteIdentityPatch {
+  disabled?: boolean;
+  template?: string;
+}
+
+// 路由对客户端 system prompt 的处理方式，'' 表示原样转发
+export type SystemPromptMode = '' | 'strip' | 'truncate' | 'hash' | 'replace';
+
+export interface RouteSystemPrompt {
+  mode: SystemPromptMode;
+  maxChars?: number;
+  replacement?: string;
+  recordOriginal?: boolean; // 在请求记录中保留原始 system prompt
 }
 
 export type CreateRouteData = Omit<Route, 'id' | 'createdAt' | 'updatedAt'>;
@@ -110,6 +209,64 @@ export interface RoutePositionUpdate {
   position: number;
 }
 
+// 路由模拟（dry-run Router.Match + 模型映射）
+export interface RouteSimulationRequest {
+  clientType: ClientType;
+  projectID: number;
+  model: string;
+  apiTokenID?: number;
+  sessionID?: string; // 决定路由实验的分组，为空时随机
+}
+
+export interface RouteCandidate {
+  route: Route;
+  providerID: number; // 实际使用的 Provider（路由实验时为实验组的 Provider）
+  providerName?: string;
+  providerType?: string;
+  experimentArm?: string;
+  experimentModel?: string; // 实验组指定的模型，替代模型映射
+  selected: boolean; // 是否会被尝试
+  skipReason?:
+    | 'provider_not_found'
+    | 'provider_disabled'
+    | 'cooldown'
+    | 'quota_exhausted'
+    | 'no_adapter'
+    | 'unsupported_model';
+  cooldownUntil?: string;
+  cooldownReason?: CooldownReason;
+  retryConfig?: RetryConfig;
+  retryConfigSource?: RetryConfigSource;
+  mappedModel?: string;
+  modelMapping?: ModelMapping; // 命中的映射规则
+}
+
+export interface RouteSimulation extends RouteSimulationRequest {
+  strategy?: RoutingStrategyType;
+  useProjectRoutes: boolean;
+  candidates: RouteCandidate[]; // 按尝试顺序
+  error?: string;
+}
+
+// 供应商与客户端类型的组合：原生支持，或通过格式转换提供
+export interface RouteConversion {
+  providerID: number;
+  providerName: string;
+  clientType: ClientType;
+  native: boolean; // 原生支持，不需要转换路由
+  targetType: ClientType; // 请求发往供应商时使用的格式
+  convertible: boolean; // 请求和响应方向都有转换器
+  routeID?: number; // 该项目下已有的路由
+  routeEnabled?: boolean;
+}
+
+export interface ConvertedRoutesRequest {
+  projectID: number;
+  providerID: number;
+  clientTypes: ClientType[];
+  isEnabled: boolean;
+}
+
 // ===== RetryConfig =====
 
 export interface RetryConfig {
@@ -126,12 +283,29 @@ export interface RetryConfig {
 
 export type CreateRetryConfigData = Omit<RetryConfig, 'id' | 'createdAt' | 'updatedAt'>;
 
+// 生效重试配置的来源：路由 > 供应商 > 系统默认
+export type RetryConfigSource = 'route' | 'provider' | 'default' | 'none';
+
+// 路由实际生效的重试配置，各层级 ID 为 0 表示未配置
+export interface EffectiveRetryConfig {
+  routeID: number;
+  providerID: number;
+  source: RetryConfigSource;
+  config?: RetryConfig;
+  routeRetryConfigID: number;
+  providerRetryConfigID: number;
+  defaultRetryConfigID: number;
+}
+
 // ===== RoutingStrategy =====
 
-export type RoutingStrategyType = 'priority' | 'weighted_random';
+export type RoutingStrategyType = 'priority' | 'weighted_random' | 'quota_aware' | 'cost_aware';
 
 export interface RoutingStrategyConfig {
-  // 扩展字段
+  weights?: Record<number, number>; // 加权随机：Provider ID -> 权重，未配置为 1，0 表示排在最后
+  expectedOutputTokens?: number; // 成本优先：预估成本时假定的输出 Token 数，默认 1000
+  maxLatencyMs?: number; // 成本优先：平均延迟上限（毫秒），超过的 Provider 排在后面
+  params?: Record<string, string>; // 自定义策略参数
 }
 
 export interface RoutingStrategy {
@@ -145,6 +319,136 @@ export interface RoutingStrategy {
 
 export type CreateRoutingStrategyData = Omit<RoutingStrategy, 'id' | 'createdAt' | 'updatedAt'>;
 
+// ===== RoutingProfile =====
+
+// 路由方案中单个路由的状态
+export interface RoutingProfileRoute {
+  routeID: number;
+  isEnabled: boolean;
+  position: number;
+}
+
+// 路由方案的生效时间段（服务器本地时间）
+export interface RoutingProfileSchedule {
+  days?: number[]; // 0 表示周日，为空表示每天
+  start: string; // HH:MM
+  end: string; // HH:MM，早于 start 表示跨夜
+}
+
+// 路由方案：一组路由的启用状态和位置，可手动切换或按时间段自动应用
+export interface RoutingProfile {
+  id: number;
+  createdAt: string;
+  updatedAt: string;
+  name: string;
+  description: string;
+  routes: RoutingProfileRoute[] | null;
+  schedules: RoutingProfileSchedule[] | null;
+}
+
+export type CreateRoutingProfileData = Omit<RoutingProfile, 'id' | 'createdAt' | 'updatedAt'>;
+
+// 当前生效的路由方案
+export interface ActiveRoutingProfile {
+  profileID: number; // 0 表示未应用任何方案
+  scheduledProfileID: number; // 按时间表匹配的方案，0 表示没有
+}
+
+// ===== CostAlert =====
+
+// 费用告警的统计范围
+export type CostAlertScope = 'global' | 'project' | 'provider' | 'api_token';
+
+// 费用告警：每日费用阈值、高于过去 7 天日均的百分比和每日摘要
+export interface CostAlert {
+  id: number;
+  createdAt: string;
+  updatedAt: string;
+  name: string;
+  isEnabled: boolean;
+  scope: CostAlertScope;
+  scopeID: number; // global 时为 0
+  dailyThreshold: number; // 每日费用阈值（微美元），0 表示不检查
+  percentAboveAverage: number; // 当日费用高于 7 天日均的百分比，0 表示不检查
+  dailyDigest: boolean; // 每天发送前一天的费用摘要
+  lastTriggeredAt?: string;
+  lastDigestAt?: string;
+}
+
+export type CreateCostAlertData = Omit<
+  CostAlert,
+  'id' | 'createdAt' | 'updatedAt' | 'lastTriggeredAt' | 'lastDigestAt'
+>;
+
+// 费用告警通知（cost_alert 事件和 Webhook 的内容）
+export interface CostAlertNotification {
+  alertID: number;
+  alertName: string;
+  kind: 'threshold' | 'above_average' | 'digest';
+  scope: CostAlertScope;
+  scopeID: number;
+  scopeName?: string;
+  date: string; // YYYY-MM-DD（UTC）
+  cost: number; // 微美元
+  requests: number;
+  inputTokens: number;
+  outputTokens: number;
+  threshold?: number;
+  averageCost?: number;
+  percentAbove?: number;
+  message: string;
+  triggeredAt: string;
+}
+
+// ===== LatencySLO =====
+
+export type LatencySLOMetric = 'ttfb' | 'duration';
+
+// 延迟 SLO：Provider 处理匹配模型的成功请求时，统计窗口内指定分位的延迟不超过目标值
+// 时长字段单位为纳秒
+export interface LatencySLO {
+  id: number;
+  createdAt: string;
+  updatedAt: string;
+  name: string;
+  isEnabled: boolean;
+  providerID: number; // 0 表示所有 Provider（每个 Provider 单独计算）
+  model: string; // 支持通配符 *，空表示所有模型
+  metric: LatencySLOMetric;
+  percentile: number; // 如 95 表示 p95
+  target: number;
+  window: number;
+  minSamples: number; // 样本少于该值时不判定
+  persistence: number; // 持续违反超过该时长才发出事件
+}
+
+export type CreateLatencySLOData = Omit<LatencySLO, 'id' | 'createdAt' | 'updatedAt'>;
+
+// 某个 Provider 在一个延迟 SLO 下的达标情况
+export interface LatencySLOStatus {
+  sloID: number;
+  sloName: string;
+  providerID: number;
+  providerName: string;
+  model: string;
+  metric: LatencySLOMetric;
+  percentile: number;
+  target: number;
+  samples: number;
+  value: number; // 窗口内的分位延迟（纳秒）
+  compliance: number; // 不超过目标的样本比例 0-1
+  violating: boolean;
+  violatingSince?: string;
+  persistent: boolean; // 持续违反，已发出事件
+}
+
+// 延迟 SLO 违反和恢复事件
+export interface LatencySLOEvent extends LatencySLOStatus {
+  kind: 'violation' | 'recovered';
+  message: string;
+  triggeredAt: string;
+}
+
 // ===== ProxyRequest =====
 
 export interface RequestInfo {
@@ -152,12 +456,19 @@ export interface RequestInfo {
   headers: Record<string, string>;
   url: string;
   body: string;
+  /** 请求体存放在 blob store 且加载失败时的引用 */
+  bodyRef?: string;
 }
 
 export interface ResponseInfo {
   status: number;
   headers: Record<string, string>;
   body: string;
+  /** 响应体存放在 blob store 且加载失败时的引用 */
+  bodyRef?: string;
+  /** 响应体超过记录上限，body 只保留开头部分 */
+  bodyTruncated?: boolean;
+  bodySize?: number;
 }
 
 export type ProxyRequestStatus =
@@ -166,7 +477,25 @@ export type ProxyRequestStatus =
   | 'COMPLETED'
   | 'FAILED'
   | 'CANCELLED'
-  | 'REJECTED';
+  | 'REJECTED'
+  | 'INTERRUPTED';
+
+// 稳定的错误码，用于程序化处理失败请求
+export type ErrorCode =
+  | 'NO_ROUTES'
+  | 'AUTH_FAILED'
+  | 'QUOTA_EXHAUSTED'
+  | 'RATE_LIMITED'
+  | 'CONVERSION_FAILED'
+  | 'INVALID_REQUEST'
+  | 'UPSTREAM_4XX'
+  | 'UPSTREAM_5XX'
+  | 'UPSTREAM_UNREACHABLE'
+  | 'TIMEOUT'
+  | 'CLIENT_ABORT'
+  | 'CONTENT_BLOCKED'
+  | 'EMPTY_RESPONSE'
+  | 'INTERNAL';
 
 export interface ProxyRequest {
   id: number;
@@ -187,6 +516,7 @@ export interface ProxyRequest {
   requestInfo: RequestInfo | null;
   responseInfo: ResponseInfo | null;
   error: string;
+  errorCode?: ErrorCode;
   proxyUpstreamAttemptCount: number;
   finalProxyUpstreamAttemptID: number;
   // 当前使用的 Route 和 Provider (用于实时追踪)
@@ -202,6 +532,95 @@ export interface ProxyRequest {
   cost: number;
   // API Token ID
   apiTokenID: number;
+  // 请求形态（客户端原始请求体）
+  promptBytes: number;
+  messageCount: number;
+  toolCount: number;
+  hasImage: boolean;
+  // Claude 请求的可缓存前缀（system + tools）指纹
+  prefixHash?: string;
+  prefixBytes?: number;
+  cacheControl?: boolean; // 客户端请求带有 cache_control
+  cacheControlStripped?: boolean; // cache_control 未传给上游
+  // 会话在短时间内重复发送相似请求（代理循环）
+  agentLoop?: boolean;
+  // 后台请求由本地应答器直接返回，未发送到上游
+  localResponse?: boolean;
+  // 请求所属的路由实验及分组
+  experiment?: string;
+  experimentArm?: 'A' | 'B';
+  // 路由决策过程（按采样率记录，仅请求详情接口返回）
+  routerDecision?: RouterDecision;
+  // 重试链路（仅请求详情接口返回）
+  retryChain?: RetryStep[];
+}
+
+// 路由决策的选择路径
+export type RouterDecisionMode = 'strategy' | 'pinned' | 'session_pin' | 'background';
+
+// 路由策略排序时一个 Provider 的输入
+export interface RouterStrategyInput {
+  providerID: number;
+  weight?: number;
+  quotaRemaining?: number; // 0-1
+  successRate?: number; // 0-100
+  activeRequests?: number;
+  projectedCost?: number; // 成本优先：预估请求成本（微美元）
+  avgDurationMs?: number; // 成本优先：平均请求耗时（毫秒）
+  demoted?: boolean; // 故障切换后被降级
+}
+
+export interface RouterDecisionCandidate {
+  routeID: number;
+  providerID: number;
+  providerName?: string;
+  position: number;
+  experimentArm?: 'A' | 'B';
+  selected: boolean;
+  skipReason?: RouteCandidate['skipReason'];
+  cooldownUntil?: string;
+}
+
+export interface RouterDecision {
+  mode: RouterDecisionMode;
+  projectRoutes: boolean;
+  strategy?: RoutingStrategyType;
+  strategyConfig?: RoutingStrategyConfig;
+  strategyInputs?: RouterStrategyInput[];
+  candidates: RouterDecisionCandidate[];
+  order: number[]; // 最终尝试顺序（路由 ID）
+  notes?: string[];
+}
+
+// 尝试失败后执行器的动作与原因
+export type AttemptAction = 'retry' | 'fallback_model' | 'next_route' | 'stop';
+
+export type AttemptDecisionReason =
+  | 'retryable_error'
+  | 'non_retryable_error'
+  | 'retries_exhausted'
+  | 'model_unsupported'
+  | 'context_cancelled'
+  | 'unknown_error'
+  | 'content_blocked';
+
+export interface AttemptDecision {
+  action: AttemptAction;
+  reason: AttemptDecisionReason;
+  retryWait?: number; // nanoseconds
+  cooldownUntil?: string;
+  fallbackModel?: string;
+}
+
+// 重试链路中的一步
+export interface RetryStep {
+  attemptID: number;
+  routeID: number;
+  providerID: number;
+  model: string;
+  status: string;
+  errorCode?: ErrorCode;
+  decision?: AttemptDecision;
 }
 
 // ===== ProxyUpstreamAttempt =====
@@ -211,7 +630,21 @@ export type ProxyUpstreamAttemptStatus =
   | 'IN_PROGRESS'
   | 'COMPLETED'
   | 'FAILED'
-  | 'CANCELLED';
+  | 'CANCELLED'
+  | 'INTERRUPTED';
+
+// 尝试各阶段耗时 (nanoseconds)
+export interface UpstreamTimings {
+  wait: number;
+  conversion: number;
+  dns: number;
+  connect: number;
+  tls: number;
+  ttfb: number;
+  stream: number;
+  clientWrite: number;
+  connReused: boolean;
+}
 
 export interface ProxyUpstreamAttempt {
   id: number;
@@ -221,6 +654,9 @@ export interface ProxyUpstreamAttempt {
   endTime: string;
   duration: number; // nanoseconds
   status: ProxyUpstreamAttemptStatus;
+  errorCode?: ErrorCode; // 失败原因的错误码（成功时为空，内容被拦截时为 CONTENT_BLOCKED）
+  blockReason?: string; // 上游内容过滤拦截的类别，如 SAFETY
+  decision?: AttemptDecision; // 失败后执行器的决定
   proxyRequestID: number;
   isStream: boolean; // 是否为 SSE 流式请求
   // 模型信息
@@ -229,6 +665,7 @@ export interface ProxyUpstreamAttempt {
   responseModel: string; // 上游响应中返回的模型名称
   requestInfo: RequestInfo | null;
   responseInfo: ResponseInfo | null;
+  timings?: UpstreamTimings; // 上游连接耗时
   routeID: number;
   providerID: number;
   inputTokenCount: number;
@@ -240,6 +677,42 @@ export interface ProxyUpstreamAttempt {
   cost: number;
 }
 
+// 两个 JSON 文档之间的一处差异
+export interface JSONChange {
+  op: 'add' | 'remove' | 'replace';
+  path: string; // JSON Pointer (RFC 6901)
+  from: unknown; // add 时为 null
+  to: unknown; // remove 时为 null
+}
+
+export interface BodyDiff {
+  comparable: boolean; // 两者都是 JSON 文档时才可比较（SSE 流等不可比较）
+  changes: JSONChange[];
+  truncated: boolean;
+}
+
+export interface AttemptDiff {
+  attemptID: number;
+  providerID: number;
+  status: ProxyUpstreamAttemptStatus;
+  requestModel: string;
+  mappedModel: string;
+  upstreamRequest: RequestInfo | null;
+  upstreamResponse: ResponseInfo | null;
+  requestDiff: BodyDiff; // 客户端请求体 -> 上游请求体
+  responseDiff?: BodyDiff; // 上游响应体 -> 客户端响应体，仅最终尝试有
+}
+
+// 客户端请求与各次上游尝试的对照视图
+export interface ProxyRequestDiff {
+  proxyRequestID: number;
+  clientType: ClientType;
+  status: ProxyRequestStatus;
+  clientRequest: RequestInfo | null;
+  clientResponse: ResponseInfo | null;
+  attempts: AttemptDiff[];
+}
+
 // ===== 分页 =====
 
 export interface PaginationParams {
@@ -254,6 +727,8 @@ export interface CursorPaginationParams {
   before?: number;
   /** 获取 id 大于此值的记录 (向前翻页/获取新数据) */
   after?: number;
+  /** 上一页的 nextCursor，等同于 before */
+  cursor?: number;
 }
 
 /** 游标分页响应 */
@@ -264,6 +739,31 @@ export interface CursorPaginationResult<T> {
   firstId?: number;
   /** 当前页最后一条记录的 id */
   lastId?: number;
+  /** 有更多记录时等于 lastId，可作为下一页的 cursor */
+  nextCursor?: number;
+  /** 记录总数（不受游标影响） */
+  total?: number;
+}
+
+/** 列表接口统一的分页与排序参数，翻页时 sort/order 需保持不变 */
+export interface ListQuery {
+  /** 每页条数，默认 50，最多 500 */
+  limit?: number;
+  /** 上一页的 nextCursor，为空表示第一页 */
+  cursor?: number;
+  /** 排序字段，如 id / name / createdAt，为空表示默认顺序 */
+  sort?: string;
+  order?: 'asc' | 'desc';
+}
+
+/** 分页列表响应 */
+export interface Page<T> {
+  items: T[];
+  /** 符合条件的记录总数 */
+  total: number;
+  hasMore: boolean;
+  /** 下一页的游标，没有更多记录时为空 */
+  nextCursor?: number;
 }
 
 // ===== WebSocket 消息 =====
@@ -276,8 +776,39 @@ export type WSMessageType =
   | 'antigravity_oauth_result'
   | 'new_session_pending'
   | 'session_pending_cancelled'
+  | 'config_report' // 启动配置检查发现问题
+  | 'attempt_progress' // 流式请求的实时进度
+  | 'agent_loop_detected' // 会话在短时间内重复发送相同请求
+  | 'sessions_expired' // 空闲会话被解除绑定或归档
+  | 'cost_alert' // 费用告警或每日摘要
+  | 'latency_slo' // Provider 持续违反延迟 SLO 或恢复达标
   | '_ws_reconnected'; // 内部事件：WebSocket 重连成功
 
+// 代理循环告警（会话开始循环时广播一次）
+export interface AgentLoopWarning {
+  sessionID: string;
+  proxyRequestID: number;
+  clientType: ClientType;
+  model: string;
+  count: number; // 窗口内的相似请求数
+  windowSeconds: number;
+  throttled: boolean;
+}
+
+// 流式上游请求的实时进度（请求进行中每秒广播）
+export interface AttemptProgress {
+  proxyRequestID: number;
+  attemptID: number;
+  providerID: number;
+  model: string;
+  elapsedMs: number;
+  inputTokens: number; // 上游目前报告的用量
+  outputTokens: number;
+  cacheReadCount: number;
+  cacheWriteCount: number;
+  estimatedCost: number; // 微美元
+}
+
 export interface WSMessage<T = unknown> {
   type: WSMessageType;
   data: T;
@@ -305,14 +836,49 @@ export interface ProxyStatus {
   commit: string;
 }
 
+// 启动时崩溃恢复的结果：上一个实例遗留的请求和尝试被标记为 INTERRUPTED
+export interface RecoverySummary {
+  instanceID: string;
+  recoveredAt: string;
+  interruptedRequests: number;
+  interruptedAttempts: number;
+}
+
 // ===== Provider Stats =====
 
+// 供应商本地配额的当前用量
+export interface ProviderQuotaStatus {
+  providerID: number;
+  providerName: string;
+  requestsPerDay: number;
+  tokensPerDay: number;
+  requests: number;
+  tokens: number;
+  windowStart: string;
+  resetAt: string;
+  exhausted: boolean;
+}
+
+// Failback 状态：故障切换后的 Provider 在探测成功足够次数后自动切回
+export interface FailbackStatus {
+  providerID: number;
+  providerName: string;
+  demoted: boolean;
+  demotedAt: string;
+  consecutiveSuccesses: number;
+  requiredSuccesses: number;
+  lastProbeAt?: string;
+  lastError?: string;
+  recoveredAt?: string;
+}
+
 export interface ProviderStats {
   providerID: number;
   totalRequests: number;
   successfulRequests: number;
   failedRequests: number;
   successRate: number; // 0-100
+  avgDurationMs: number; // 平均请求耗时（毫秒）
   activeRequests: number;
   totalInputTokens: number;
   totalOutputTokens: number;
@@ -342,6 +908,20 @@ export interface AntigravityQuotaData {
   subscriptionTier: string; // FREE/PRO/ULTRA
 }
 
+// 用另一个账号重放上游尝试的结果 - 与 Go antigravity.ReplayResult 同步
+export interface AntigravityReplayResult {
+  providerID: number;
+  providerName: string;
+  url: string;
+  requestBody: string;
+  status: number; // 连接失败时为 0
+  headers?: Record<string, string>;
+  body: string;
+  truncated?: boolean;
+  durationMs: number;
+  error?: string;
+}
+
 // 批量配额查询结果
 export interface AntigravityBatchQuotaResult {
   quotas: Record<number, AntigravityQuotaData>; // providerId -> quota
@@ -410,6 +990,149 @@ export interface ModelMappingInput {
   isEnabled?: boolean;
 }
 
+// 学习模式下记录的映射结果
+export interface ModelMappingOutcome {
+  providerID: number;
+  providerName: string;
+  requestModel: string;
+  mappedModel: string;
+  attempts: number;
+  failures: number;
+  failureRate: number; // 0-1
+  lastSeen: string;
+}
+
+// 根据映射结果生成的映射规则建议
+export interface ModelMappingSuggestion {
+  providerID: number;
+  providerName: string;
+  pattern: string; // 建议规则的源模式
+  currentTarget: string;
+  attempts: number;
+  failures: number;
+  failureRate: number;
+  suggestedTarget?: string; // 同一供应商上表现更好的目标模型
+  suggestedTargetFailureRate?: number;
+  alternativeProviderID?: number; // 能成功处理该模式的其他供应商
+  alternativeProviderName?: string;
+  reason: string;
+}
+
+// 审计事件，字段与 Claude Code hooks 的输入保持一致（hook_event_name、session_id、tool_name 等）
+export type AuditEventName = 'RequestStarted' | 'RequestCompleted' | 'ModelMapped' | 'PreToolUse';
+
+export interface AuditEvent {
+  timestamp: string;
+  hook_event_name: AuditEventName;
+  session_id?: string;
+  request_id?: string;
+  client_type?: string;
+  project_id?: number;
+  api_token_id?: number;
+  provider_id?: number;
+  model?: string;
+  mapped_model?: string;
+  status?: string;
+  status_code?: number;
+  duration_ms?: number;
+  input_tokens?: number;
+  output_tokens?: number;
+  error?: string;
+  tool_name?: string; // PreToolUse：模型请求调用的工具
+  tool_use_id?: string;
+  tool_input?: unknown;
+}
+
+export interface AuditQuery {
+  event?: AuditEventName;
+  sessionId?: string;
+  requestId?: string;
+  since?: string; // ISO8601
+  limit?: number; // 默认 100，最大 1000
+}
+
+// 质量评估样本：按 quality_sample_rates 设置采样的完整请求/响应
+export type QualitySampleTag = 'quality_review' | 'stream' | 'converted' | 'content_blocked';
+
+export interface QualitySample {
+  timestamp: string;
+  tags: QualitySampleTag[];
+  proxy_request_id: number;
+  request_id?: string;
+  session_id?: string;
+  client_type: string;
+  provider_id: number;
+  provider_name?: string;
+  provider_type?: string;
+  model: string;
+  response_model?: string;
+  status_code: number;
+  duration_ms: number;
+  input_tokens: number;
+  output_tokens: number;
+  cost: number; // 微美元
+  request: unknown;
+  response: unknown; // JSON 响应体，流式响应为 SSE 文本
+}
+
+export interface QualitySampleQuery {
+  providerId?: number;
+  model?: string;
+  tag?: QualitySampleTag;
+  since?: string; // ISO8601
+  until?: string; // ISO8601
+  limit?: number; // 列表默认 100，最大 1000；导出默认全部
+}
+
+// 模型能力规则，未设置的字段表示未知（由更低优先级规则或内置默认值决定）
+export interface ModelCapability {
+  id: number;
+  createdAt: string;
+  updatedAt: string;
+  providerID?: number; // 供应商 ID，0 或空表示所有
+  pattern: string; // 上游模型模式，支持 * 通配符
+  supportsTools?: boolean;
+  supportsVision?: boolean;
+  supportsThinking?: boolean;
+  supportsWebSearch?: boolean;
+  maxContext?: number; // 上下文窗口（token）
+  maxOutput?: number; // 最大输出（token）
+  priority: number; // 优先级，数字越小优先级越高
+}
+
+export type ModelCapabilityInput = Omit<ModelCapability, 'id' | 'createdAt' | 'updatedAt'>;
+
+// 合并所有规则后的模型能力
+export interface ModelCapabilities {
+  supportsTools?: boolean;
+  supportsVision?: boolean;
+  supportsThinking?: boolean;
+  supportsWebSearch?: boolean;
+  maxContext?: number;
+  maxOutput?: number;
+}
+
+// ===== 配置检查 =====
+
+export type ConfigIssueSeverity = 'error' | 'warning';
+
+export interface ConfigIssue {
+  severity: ConfigIssueSeverity;
+  kind: 'provider' | 'route' | 'model_mapping';
+  resourceID: number;
+  resourceName?: string;
+  code: string; // 如 missing_config, orphaned_route, unreachable_base_url, invalid_refresh_token
+  message: string;
+}
+
+export interface ConfigReport {
+  checkedAt: string;
+  ok: boolean; // 没有 error 级别的问题
+  errors: number;
+  warnings: number;
+  issues: ConfigIssue[];
+}
+
 // ===== Kiro 类型 =====
 
 export interface KiroTokenValidationResult {
@@ -454,6 +1177,121 @@ export interface ImportResult {
   errors: string[];
 }
 
+// 从 CLIProxyAPI / Antigravity-Manager 导入的文件
+export interface ExternalImportFile {
+  name: string;
+  content: string;
+}
+
+// new: 预览中将会创建, created: 已创建, skipped: 已存在同名 Provider, failed: 创建失败
+export type ExternalImportStatus = 'new' | 'created' | 'skipped' | 'failed';
+
+export interface ExternalImportProvider {
+  name: string;
+  type: string;
+  clientTypes: ClientType[];
+  modelMappings: number;
+  origin: string; // 来源文件和条目
+  status: ExternalImportStatus;
+  error?: string;
+}
+
+export interface ExternalImportResult {
+  dryRun: boolean;
+  formats: Record<string, string>; // 每个文件识别出的格式
+  providers: ExternalImportProvider[];
+  routes: number;
+  modelMappings: number;
+  warnings: string[];
+}
+
+// ===== Provider Batch =====
+
+export type ProviderBatchAction = 'enable' | 'disable' | 'delete' | 'validate' | 'patch';
+
+export type ProviderBatchStatus = 'ok' | 'failed' | 'skipped';
+
+// ids 和 label 至少指定一个；同时指定时取交集
+export interface ProviderBatchRequest {
+  ids?: number[];
+  label?: string;
+  action: ProviderBatchAction;
+  patch?: Record<string, unknown>; // JSON Merge Patch，仅 action 为 patch 时使用
+}
+
+export interface ProviderBatchItem {
+  id: number;
+  name: string;
+  status: ProviderBatchStatus;
+  error?: string;
+}
+
+export interface ProviderBatchResult {
+  action: ProviderBatchAction;
+  matched: number;
+  succeeded: number;
+  failed: number;
+  skipped: number;
+  items: ProviderBatchItem[];
+}
+
+// ===== Batch Run =====
+
+// 通过正常路由批量执行 prompt；指定 providers 时每个 prompt 在每个 Provider 上各执行一次
+export interface BatchRunRequest {
+  model: string;
+  clientType?: 'claude' | 'openai'; // 默认 claude
+  prompts: string[];
+  system?: string;
+  maxTokens?: number; // 默认 1024
+  providers?: string[]; // Provider 名称或 ID
+  concurrency?: number; // 默认 4，最大 16
+  projectID?: number;
+}
+
+export interface BatchRunItem {
+  prompt: number; // prompts 中的下标
+  pinnedProvider?: string;
+  proxyRequestID?: number;
+  providerID?: number;
+  responseModel?: string;
+  status: 'COMPLETED' | 'FAILED';
+  statusCode?: number;
+  durationMs: number;
+  inputTokens: number;
+  outputTokens: number;
+  cost: number; // 微美元
+  output?: string;
+  error?: string;
+  errorCode?: string;
+}
+
+export interface BatchRunProviderSummary {
+  providerID: number;
+  requests: number;
+  succeeded: number;
+  failed: number;
+  avgDurationMs: number;
+  outputPerSecond: number; // 成功请求的平均输出 tokens/秒
+  inputTokens: number;
+  outputTokens: number;
+  cost: number;
+}
+
+export interface BatchRunResult {
+  model: string;
+  clientType: string;
+  total: number;
+  succeeded: number;
+  failed: number;
+  durationMs: number;
+  inputTokens: number;
+  outputTokens: number;
+  cost: number;
+  providers: BatchRunProviderSummary[];
+  items: BatchRunItem[];
+}
+
 // ===== Cooldown =====
 
 export type CooldownReason =
@@ -462,7 +1300,8 @@ export type CooldownReason =
   | 'quota_exhausted'
   | 'rate_limit_exceeded'
   | 'concurrent_limit'
-  | 'unknown';
+  | 'unknown'
+  | 'manual';
 
 /**
  * Cooldown 类型 - 与 Go domain.Cooldown 同步
@@ -478,6 +1317,47 @@ export interface Cooldown {
   reason: CooldownReason;
 }
 
+/** 某个原因的冷却统计 */
+export interface CooldownReasonStats {
+  reason: CooldownReason;
+  count: number;
+  totalDurationMs: number;
+  avgDurationMs: number;
+  maxDurationMs: number;
+}
+
+/** 一个时间桶内开始的冷却次数（按原因） */
+export interface CooldownStatsBucket {
+  time: string;
+  counts: Partial<Record<CooldownReason, number>>;
+  durationMs: number;
+}
+
+/** 单个供应商的冷却统计 */
+export interface CooldownProviderStats {
+  providerID: number;
+  providerName?: string;
+  count: number;
+  totalDurationMs: number;
+  reasons: CooldownReasonStats[]; // 次数多的在前
+  timeline: CooldownStatsBucket[] | null; // 时间升序，省略空桶
+}
+
+/** 冷却原因统计 - 与 Go domain.CooldownStats 同步 */
+export interface CooldownStats {
+  from: string;
+  to: string;
+  bucket: 'hour' | 'day';
+  providers: CooldownProviderStats[];
+}
+
+/** 冷却统计查询参数，默认最近 7 天、按小时 */
+export interface CooldownStatsParams {
+  from?: string; // RFC3339
+  to?: string; // RFC3339
+  bucket?: 'hour' | 'day';
+}
+
 // ===== Auth 相关 =====
 
 export interface AuthStatus {
@@ -501,6 +1381,7 @@ export interface APIToken {
   name: string;
   description: string;
   projectID: number;
+  allowedClientTypes?: ClientType[]; // 允许的客户端类型，空表示不限制
   isEnabled: boolean;
   expiresAt?: string;
   lastUsedAt?: string;
@@ -516,6 +1397,7 @@ export interface CreateAPITokenData {
   name: string;
   description?: string;
   projectID?: number;
+  allowedClientTypes?: ClientType[];
   expiresAt?: string;
 }
 
@@ -571,6 +1453,88 @@ export interface UsageStatsFilter {
   model?: string; // 模型名称
 }
 
+/** 请求形态统计过滤条件 */
+export interface RequestShapeFilter {
+  start?: string; // 开始时间 ISO8601
+  end?: string; // 结束时间 ISO8601
+  providerId?: number;
+  projectId?: number;
+  clientType?: string;
+}
+
+/** 某个请求模型在某个供应商上的请求形态统计 */
+export interface RequestShapeStats {
+  model: string;
+  providerID: number;
+  totalRequests: number;
+  totalPromptBytes: number;
+  avgPromptBytes: number;
+  maxPromptBytes: number;
+  totalMessages: number;
+  avgMessages: number;
+  maxMessages: number;
+  totalTools: number;
+  avgTools: number;
+  toolRequests: number; // 带工具定义的请求数
+  imageRequests: number; // 含图片的请求数
+  inputTokens: number;
+  totalCost: number; // 微美元
+}
+
+/** 路由实验统计过滤条件 */
+export interface ExperimentStatsFilter {
+  start?: string; // 开始时间 ISO8601
+  end?: string; // 结束时间 ISO8601
+  experiment?: string;
+}
+
+/** 路由实验某一组的对比指标 */
+export interface ExperimentArmStats {
+  experiment: string;
+  arm: 'A' | 'B';
+  totalRequests: number;
+  successfulRequests: number;
+  failedRequests: number;
+  errorRate: number; // 0-1
+  avgDurationMs: number; // 成功请求的平均耗时
+  inputTokens: number;
+  outputTokens: number;
+  totalCost: number; // 微美元
+  avgCost: number; // 微美元
+}
+
+/** Prompt 缓存分析过滤条件 */
+export interface PromptCacheAdvisorFilter {
+  start?: string; // 开始时间 ISO8601
+  end?: string; // 结束时间 ISO8601
+  routeId?: number;
+}
+
+/** Prompt 缓存建议 */
+export type PromptCacheRecommendation =
+  | 'native_provider' // cache_control 被格式转换丢弃，应路由到原生 Claude 供应商
+  | 'client_cache_control' // 前缀重复但客户端未标记 cache_control
+  | 'provider_no_cache' // 已转发 cache_control 但上游没有缓存命中
+  | 'stable_prefix'; // system prompt 或工具定义在请求间变化
+
+/** 某个路由的 prompt 缓存分析（token 数按前缀大小估算） */
+export interface PromptCacheAdvice {
+  routeID: number;
+  providerID: number;
+  requests: number;
+  distinctPrefixes: number;
+  repeatedRequests: number; // 前缀重复且可缓存的请求数
+  avgPrefixTokens: number;
+  cacheControlRequests: number;
+  strippedRequests: number;
+  cacheableTokens: number;
+  cacheReadTokens: number;
+  cacheWriteTokens: number;
+  missedTokens: number; // 可缓存但未从缓存读取的 tokens
+  estimatedSavings: number; // 微美元
+  recommendations: PromptCacheRecommendation[];
+}
+
 /** Response Model - 记录所有出现过的 response model */
 export interface ResponseModel {
   id: number;
@@ -44,7 +44,40 @@ import {
   Shield,
 } from 'lucide-react';
 import { PageHeader } from '@/components/layout';
-import type { APIToken } from '@/lib/transport';
+import type { APIToken, ClientType } from '@/lib/transport';
+import { getClientName } from '@/components/icons/client-icons';
+import { cn } from '@/lib/utils';
+
+const TOKEN_CLIENT_TYPES: ClientType[] = ['claude', 'openai', 'codex', 'gemini'];
+
+// Toggles for the client types a token may be used with (none selected = all)
+function ClientTypesPicker({
+  value,
+  onChange,
+}: {
+  value: ClientType[];
+  onChange: (value: ClientType[]) => void;
+}) {
+  const toggle = (ct: ClientType) =>
+    onChange(value.includes(ct) ? value.filter((v) => v !== ct) : [...value, ct]);
+
+  return (
+    <div className="flex flex-wrap gap-2">
+      {TOKEN_CLIENT_TYPES.map((ct) => (
+        <Button
+          key={ct}
+          type="button"
+          variant="outline"
+          size="sm"
+          className={cn(value.includes(ct) && 'border-primary bg-primary/10 text-primary')}
+          onClick={() => toggle(ct)}
+        >
+          {getClientName(ct)}
+        </Button>
+      ))}
+    </div>
+  );
+}
 
 export function APITokensPage() {
   const { t, i18n } = useTranslation();
@@ -57,6 +90,7 @@ export function APITokensPage() {
   const deleteToken = useDeleteAPIToken();
 
   const apiTokenAuthEnabled = settings?.api_token_auth_enabled === 'true';
+  const loopbackBypass = settings?.api_token_loopback_bypass === 'true';
 
   const handleToggleAuth = (checked: boolean) => {
     updateSetting.mutate({
@@ -65,6 +99,13 @@ export function APITokensPage() {
     });
   };
 
+  const handleToggleLoopbackBypass = (checked: boolean) => {
+    updateSetting.mutate({
+      key: 'api_token_loopback_bypass',
+      value: checked ? 'true' : 'false',
+    });
+  };
+
   const [showForm, setShowForm] = useState(false);
   const [editingToken, setEditingToken] = useState<APIToken | null>(null);
   const [deletingToken, setDeletingToken] = useState<APIToken | null>(null);
@@ -79,6 +120,7 @@ export function APITokensPage() {
   const [description, setDescription] = useState('');
   const [projectID, setProjectID] = useState<string>('0');
   const [expiresAt, setExpiresAt] = useState('');
+  const [allowedClientTypes, setAllowedClientTypes] = useState<ClientType[]>([]);
   const [showProjectPicker, setShowProjectPicker] = useState(false);
 
   const resetForm = () => {
@@ -86,6 +128,7 @@ export function APITokensPage() {
     setDescription('');
     setProjectID('0');
     setExpiresAt('');
+    setAllowedClientTypes([]);
     setShowProjectPicker(false);
   };
 
@@ -96,6 +139,7 @@ export function APITokensPage() {
         name,
         description,
         projectID: parseInt(projectID) || 0,
+        allowedClientTypes,
         expiresAt: expiresAt ? new Date(expiresAt).toISOString() : undefined,
       },
       {
@@ -120,6 +164,7 @@ export function APITokensPage() {
           name,
           description,
           projectID: parseInt(projectID) || 0,
+          allowedClientTypes,
           expiresAt: expiresAt ? new Date(expiresAt).toISOString() : undefined,
         },
       },
@@ -151,6 +196,7 @@ export function APITokensPage() {
     setName(token.name);
     setDescription(token.description);
     setProjectID(token.projectID.toString());
+    setAllowedClientTypes(token.allowedClientTypes ?? []);
     setExpiresAt(token.expiresAt ? token.expiresAt.split('T')[0] : '');
   };
 
@@ -244,6 +290,17 @@ export function APITokensPage() {
                     </Button>
                   </div>
                 </div>
+                <div className="flex items-center justify-between mt-4 pt-4 border-t border-border">
+                  <div>
+                    <p className="text-sm font-medium">{t('apiTokens.loopbackBypass')}</p>
+                    <p className="text-xs text-text-muted">{t('apiTokens.loopbackBypassDesc')}</p>
+                  </div>
+                  <Switch
+                    checked={loopbackBypass}
+                    onCheckedChange={handleToggleLoopbackBypass}
+                    disabled={updateSetting.isPending}
+                  />
+                </div>
               </CardContent>
             </Card>
 
@@ -436,6 +493,13 @@ export function APITokensPage() {
                 )}
               </div>
             </div>
+            <div className="space-y-2">
+              <label className="text-xs font-medium text-text-secondary uppercase tracking-wider">
+                {t('apiTokens.allowedClientTypes')}
+              </label>
+              <ClientTypesPicker value={allowedClientTypes} onChange={setAllowedClientTypes} />
+              <p className="text-xs text-text-muted">{t('apiTokens.allowedClientTypesHint')}</p>
+            </div>
             <div className="space-y-2">
               <label className="text-xs font-medium text-text-secondary uppercase tracking-wider">
                 {t('common.description')}
@@ -527,6 +591,13 @@ export function APITokensPage() {
                 )}
               </div>
             </div>
+            <div className="space-y-2">
+              <label className="text-xs font-medium text-text-secondary uppercase tracking-wider">
+                {t('apiTokens.allowedClientTypes')}
+              </label>
+              <ClientTypesPicker value={allowedClientTypes} onChange={setAllowedClientTypes} />
+              <p className="text-xs text-text-muted">{t('apiTokens.allowedClientTypesHint')}</p>
+            </div>
             <div className="space-y-2">
               <label className="text-xs font-medium text-text-secondary uppercase tracking-wider">
                 {t('apiTokens.createDialog.expiresAt')}
@@ -0,0 +1,402 @@
+import { useEffect, useState } from 'react';
+import {
+  Button,
+  Card,
+  CardContent,
+  CardHeader,
+  CardTitle,
+  Input,
+  Switch,
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+  Badge,
+} from '@/components/ui';
+import {
+  useCostAlerts,
+  useCreateCostAlert,
+  useUpdateCostAlert,
+  useDeleteCostAlert,
+  useProjects,
+  useProviders,
+  useAPITokens,
+  useSettings,
+  useUpdateSetting,
+} from '@/hooks/queries';
+import { Plus, Trash2, Pencil, Bell } from 'lucide-react';
+import { useTranslation } from 'react-i18next';
+import type { CostAlert, CostAlertScope } from '@/lib/transport';
+
+const SCOPES: CostAlertScope[] = ['global', 'project', 'provider', 'api_token'];
+const WEBHOOK_SETTING_KEY = 'cost_alert_webhook_url';
+
+const selectClassName =
+  'w-full rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs focus:border-ring focus:ring-2 focus:ring-ring/50 outline-none';
+
+// 微美元 -> 美元
+const formatUSD = (micro: number) => `$${(micro / 1_000_000).toFixed(2)}`;
+
+export function CostAlertsPage() {
+  const { t } = useTranslation();
+  const { data: alerts, isLoading } = useCostAlerts();
+  const { data: projects } = useProjects();
+  const { data: providers } = useProviders();
+  const { data: tokens } = useAPITokens();
+  const { data: settings } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const createAlert = useCreateCostAlert();
+  const updateAlert = useUpdateCostAlert();
+  const deleteAlert = useDeleteCostAlert();
+  const [showForm, setShowForm] = useState(false);
+  const [editingAlert, setEditingAlert] = useState<CostAlert | undefined>();
+  const [webhookURL, setWebhookURL] = useState('');
+  const [notificationPermission, setNotificationPermission] = useState(
+    typeof Notification === 'undefined' ? 'denied' : Notification.permission,
+  );
+
+  const [name, setName] = useState('');
+  const [isEnabled, setIsEnabled] = useState(true);
+  const [scope, setScope] = useState<CostAlertScope>('global');
+  const [scopeID, setScopeID] = useState('0');
+  const [threshold, setThreshold] = useState('');
+  const [percentAbove, setPercentAbove] = useState('');
+  const [dailyDigest, setDailyDigest] = useState(false);
+
+  useEffect(() => {
+    setWebhookURL(settings?.[WEBHOOK_SETTING_KEY] ?? '');
+  }, [settings]);
+
+  const scopeOptions = (s: CostAlertScope): { id: number; name: string }[] => {
+    switch (s) {
+      case 'project':
+        return projects ?? [];
+      case 'provider':
+        return providers ?? [];
+      case 'api_token':
+        return tokens ?? [];
+      default:
+        return [];
+    }
+  };
+
+  const scopeLabel = (alert: CostAlert) => {
+    if (alert.scope === 'global') {
+      return t('costAlerts.scopes.global');
+    }
+    const target = scopeOptions(alert.scope).find((o) => o.id === alert.scopeID);
+    return `${t(`costAlerts.scopes.${alert.scope}`)}: ${target?.name ?? `#${alert.scopeID}`}`;
+  };
+
+  const resetForm = () => {
+    setName('');
+    setIsEnabled(true);
+    setScope('global');
+    setScopeID('0');
+    setThreshold('');
+    setPercentAbove('');
+    setDailyDigest(false);
+  };
+
+  const handleEdit = (alert: CostAlert) => {
+    setEditingAlert(alert);
+    setName(alert.name);
+    setIsEnabled(alert.isEnabled);
+    setScope(alert.scope);
+    setScopeID(String(alert.scopeID));
+    setThreshold(alert.dailyThreshold > 0 ? String(alert.dailyThreshold / 1_000_000) : '');
+    setPercentAbove(alert.percentAboveAverage > 0 ? String(alert.percentAboveAverage) : '');
+    setDailyDigest(alert.dailyDigest);
+    setShowForm(true);
+  };
+
+  const handleCloseForm = () => {
+    setShowForm(false);
+    setEditingAlert(undefined);
+    resetForm();
+  };
+
+  const handleSubmit = (e: React.FormEvent) => {
+    e.preventDefault();
+    const data = {
+      name,
+      isEnabled,
+      scope,
+      scopeID: scope === 'global' ? 0 : Number(scopeID),
+      dailyThreshold: Math.round((parseFloat(threshold) || 0) * 1_000_000),
+      percentAboveAverage: parseFloat(percentAbove) || 0,
+      dailyDigest,
+    };
+
+    if (editingAlert) {
+      updateAlert.mutate({ id: editingAlert.id, data }, { onSuccess: handleCloseForm });
+    } else {
+      createAlert.mutate(data, { onSuccess: handleCloseForm });
+    }
+  };
+
+  const handleDelete = (id: number) => {
+    if (confirm(t('costAlerts.deleteConfirm'))) {
+      deleteAlert.mutate(id);
+    }
+  };
+
+  const handleRequestPermission = () => {
+    Notification.requestPermission().then(setNotificationPermission);
+  };
+
+  const describeConditions = (alert: CostAlert) => {
+    const conditions: string[] = [];
+    if (alert.dailyThreshold > 0) {
+      conditions.push(t('costAlerts.thresholdCondition', { amount: formatUSD(alert.dailyThreshold) }));
+    }
+    if (alert.percentAboveAverage > 0) {
+      conditions.push(t('costAlerts.aboveAverageCondition', { percent: alert.percentAboveAverage }));
+    }
+    if (alert.dailyDigest) {
+      conditions.push(t('costAlerts.digestCondition'));
+    }
+    return conditions;
+  };
+
+  const isPending = createAlert.isPending || updateAlert.isPending;
+  const errorMessage = (createAlert.error ?? updateAlert.error)?.message;
+
+  return (
+    <div className="space-y-6">
+      <div className="flex items-center justify-between">
+        <div>
+          <h2 className="text-2xl font-bold">{t('costAlerts.title')}</h2>
+          <p className="text-sm text-muted-foreground">{t('costAlerts.description')}</p>
+        </div>
+        <Button onClick={() => setShowForm(true)}>
+          <Plus className="mr-2 h-4 w-4" />
+          {t('costAlerts.addAlert')}
+        </Button>
+      </div>
+
+      <Card>
+        <CardHeader>
+          <CardTitle>{t('costAlerts.delivery')}</CardTitle>
+        </CardHeader>
+        <CardContent className="space-y-4">
+          <div>
+            <label className="mb-1 block text-sm font-medium">{t('costAlerts.webhookURL')}</label>
+            <div className="flex gap-2">
+              <Input
+                value={webhookURL}
+                onChange={(e) => setWebhookURL(e.target.value)}
+                placeholder="https://example.com/hooks/maxx"
+              />
+              <Button
+                variant="outline"
+                onClick={() => updateSetting.mutate({ key: WEBHOOK_SETTING_KEY, value: webhookURL.trim() })}
+                disabled={updateSetting.isPending}
+              >
+                {t('common.save')}
+              </Button>
+            </div>
+            <p className="mt-1 text-xs text-muted-foreground">{t('costAlerts.webhookHint')}</p>
+          </div>
+          <div className="flex items-center justify-between">
+            <div>
+              <p className="text-sm font-medium">{t('costAlerts.desktopNotifications')}</p>
+              <p className="text-xs text-muted-foreground">
+                {t(`costAlerts.permission.${notificationPermission}`)}
+              </p>
+            </div>
+            {notificationPermission === 'default' && (
+              <Button variant="outline" size="sm" onClick={handleRequestPermission}>
+                <Bell className="mr-2 h-4 w-4" />
+                {t('costAlerts.enableNotifications')}
+              </Button>
+            )}
+          </div>
+        </CardContent>
+      </Card>
+
+      {showForm && (
+        <Card>
+          <CardHeader>
+            <CardTitle>
+              {editingAlert ? t('costAlerts.editAlert') : t('costAlerts.newAlert')}
+            </CardTitle>
+          </CardHeader>
+          <CardContent>
+            <form onSubmit={handleSubmit} className="space-y-4">
+              <div className="grid gap-4 md:grid-cols-2">
+                <div>
+                  <label className="mb-1 block text-sm font-medium">{t('costAlerts.name')}</label>
+                  <Input value={name} onChange={(e) => setName(e.target.value)} required />
+                </div>
+                <div className="flex items-end gap-2 pb-2">
+                  <Switch checked={isEnabled} onCheckedChange={setIsEnabled} />
+                  <span className="text-sm">{t('costAlerts.enabled')}</span>
+                </div>
+                <div>
+                  <label className="mb-1 block text-sm font-medium">{t('costAlerts.scope')}</label>
+                  <select
+                    value={scope}
+                    onChange={(e) => {
+                      setScope(e.target.value as CostAlertScope);
+                      setScopeID('0');
+                    }}
+                    className={selectClassName}
+                  >
+                    {SCOPES.map((s) => (
+                      <option key={s} value={s}>
+                        {t(`costAlerts.scopes.${s}`)}
+                      </option>
+                    ))}
+                  </select>
+                </div>
+                {scope !== 'global' && (
+                  <div>
+                    <label className="mb-1 block text-sm font-medium">
+                      {t(`costAlerts.scopes.${scope}`)}
+                    </label>
+                    <select
+                      value={scopeID}
+                      onChange={(e) => setScopeID(e.target.value)}
+                      className={selectClassName}
+                      required
+                    >
+                      <option value="0" disabled>
+                        {t('costAlerts.selectTarget')}
+                      </option>
+                      {scopeOptions(scope).map((o) => (
+                        <option key={o.id} value={o.id}>
+                          {o.name}
+                        </option>
+                      ))}
+                    </select>
+                  </div>
+                )}
+                <div>
+                  <label className="mb-1 block text-sm font-medium">
+                    {t('costAlerts.dailyThreshold')}
+                  </label>
+                  <Input
+                    type="number"
+                    min="0"
+                    step="0.01"
+                    value={threshold}
+                    onChange={(e) => setThreshold(e.target.value)}
+                    placeholder="0"
+                  />
+                </div>
+                <div>
+                  <label className="mb-1 block text-sm font-medium">
+                    {t('costAlerts.percentAboveAverage')}
+                  </label>
+                  <Input
+                    type="number"
+                    min="0"
+                    step="1"
+                    value={percentAbove}
+                    onChange={(e) => setPercentAbove(e.target.value)}
+                    placeholder="0"
+                  />
+                </div>
+                <div className="flex items-center gap-2">
+                  <Switch checked={dailyDigest} onCheckedChange={setDailyDigest} />
+                  <span className="text-sm">{t('costAlerts.dailyDigest')}</span>
+                </div>
+              </div>
+              <p className="text-xs text-muted-foreground">{t('costAlerts.conditionsHint')}</p>
+
+              {errorMessage && <p className="text-sm text-red-500">{errorMessage}</p>}
+
+              <div className="flex justify-end gap-2">
+                <Button type="button" variant="outline" onClick={handleCloseForm}>
+                  {t('common.cancel')}
+                </Button>
+                <Button type="submit" disabled={isPending}>
+                  {isPending
+                    ? t('common.saving')
+                    : editingAlert
+                      ? t('routes.update')
+                      : t('routes.create')}
+                </Button>
+              </div>
+            </form>
+          </CardContent>
+        </Card>
+      )}
+
+      <Card>
+        <CardHeader>
+          <CardTitle>{t('costAlerts.allAlerts')}</CardTitle>
+        </CardHeader>
+        <CardContent>
+          {isLoading ? (
+            <p className="text-gray-500">{t('common.loading')}</p>
+          ) : (
+            <Table>
+              <TableHeader>
+                <TableRow>
+                  <TableHead>{t('costAlerts.name')}</TableHead>
+                  <TableHead>{t('costAlerts.scope')}</TableHead>
+                  <TableHead>{t('costAlerts.conditions')}</TableHead>
+                  <TableHead>{t('costAlerts.lastTriggered')}</TableHead>
+                  <TableHead>{t('common.actions')}</TableHead>
+                </TableRow>
+              </TableHeader>
+              <TableBody>
+                {alerts?.map((alert) => (
+                  <TableRow key={alert.id}>
+                    <TableCell>
+                      <div className="flex items-center gap-2">
+                        <span className="font-medium">{alert.name}</span>
+                        {!alert.isEnabled && (
+                          <Badge variant="outline">{t('costAlerts.disabled')}</Badge>
+                        )}
+                      </div>
+                    </TableCell>
+                    <TableCell className="text-sm">{scopeLabel(alert)}</TableCell>
+                    <TableCell className="text-sm">
+                      {describeConditions(alert).map((c, i) => (
+                        <div key={i}>{c}</div>
+                      ))}
+                    </TableCell>
+                    <TableCell className="text-sm">
+                      {alert.lastTriggeredAt ? (
+                        new Date(alert.lastTriggeredAt).toLocaleString()
+                      ) : (
+                        <span className="text-gray-400">{t('costAlerts.never')}</span>
+                      )}
+                    </TableCell>
+                    <TableCell>
+                      <div className="flex gap-1">
+                        <Button variant="ghost" size="sm" onClick={() => handleEdit(alert)}>
+                          <Pencil className="h-4 w-4" />
+                        </Button>
+                        <Button
+                          variant="ghost"
+                          size="sm"
+                          onClick={() => handleDelete(alert.id)}
+                          disabled={deleteAlert.isPending}
+                        >
+                          <Trash2 className="h-4 w-4 text-red-500" />
+                        </Button>
+                      </div>
+                    </TableCell>
+                  </TableRow>
+                ))}
+                {(!alerts || alerts.length === 0) && (
+                  <TableRow>
+                    <TableCell colSpan={5} className="text-center text-gray-500">
+                      {t('costAlerts.noAlerts')}
+                    </TableCell>
+                  </TableRow>
+                )}
+              </TableBody>
+            </Table>
+          )}
+        </CardContent>
+      </Card>
+    </div>
+  );
+}
@@ -0,0 +1,421 @@
+import { useState } from 'react';
+import {
+  Button,
+  Card,
+  CardContent,
+  CardHeader,
+  CardTitle,
+  Input,
+  Switch,
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+  Badge,
+} from '@/components/ui';
+import {
+  useLatencySLOs,
+  useLatencySLOStats,
+  useCreateLatencySLO,
+  useUpdateLatencySLO,
+  useDeleteLatencySLO,
+  useLatencySLOEvents,
+  useProviders,
+} from '@/hooks/queries';
+import { Plus, Trash2, Pencil } from 'lucide-react';
+import { useTranslation } from 'react-i18next';
+import { formatDuration } from '@/lib/utils';
+import type { LatencySLO, LatencySLOMetric, LatencySLOStatus } from '@/lib/transport';
+
+const MS = 1_000_000;
+const SECOND = 1000 * MS;
+const MINUTE = 60 * SECOND;
+
+const selectClassName =
+  'w-full rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs focus:border-ring focus:ring-2 focus:ring-ring/50 outline-none';
+
+export function LatencySLOsPage() {
+  const { t } = useTranslation();
+  const { data: slos, isLoading } = useLatencySLOs();
+  const { data: stats } = useLatencySLOStats();
+  const { data: providers } = useProviders();
+  const createSLO = useCreateLatencySLO();
+  const updateSLO = useUpdateLatencySLO();
+  const deleteSLO = useDeleteLatencySLO();
+  const [showForm, setShowForm] = useState(false);
+  const [editingSLO, setEditingSLO] = useState<LatencySLO | undefined>();
+  useLatencySLOEvents();
+
+  const [name, setName] = useState('');
+  const [isEnabled, setIsEnabled] = useState(true);
+  const [providerID, setProviderID] = useState('0');
+  const [model, setModel] = useState('');
+  const [metric, setMetric] = useState<LatencySLOMetric>('ttfb');
+  const [percentile, setPercentile] = useState('95');
+  const [targetMs, setTargetMs] = useState('2000');
+  const [windowMinutes, setWindowMinutes] = useState('15');
+  const [minSamples, setMinSamples] = useState('20');
+  const [persistenceMinutes, setPersistenceMinutes] = useState('5');
+
+  const providerName = (id: number) =>
+    id === 0 ? t('latencySLOs.allProviders') : (providers?.find((p) => p.id === id)?.name ?? `#${id}`);
+
+  const resetForm = () => {
+    setName('');
+    setIsEnabled(true);
+    setProviderID('0');
+    setModel('');
+    setMetric('ttfb');
+    setPercentile('95');
+    setTargetMs('2000');
+    setWindowMinutes('15');
+    setMinSamples('20');
+    setPersistenceMinutes('5');
+  };
+
+  const handleEdit = (slo: LatencySLO) => {
+    setEditingSLO(slo);
+    setName(slo.name);
+    setIsEnabled(slo.isEnabled);
+    setProviderID(String(slo.providerID));
+    setModel(slo.model);
+    setMetric(slo.metric);
+    setPercentile(String(slo.percentile));
+    setTargetMs(String(slo.target / MS));
+    setWindowMinutes(String(slo.window / MINUTE));
+    setMinSamples(String(slo.minSamples));
+    setPersistenceMinutes(String(slo.persistence / MINUTE));
+    setShowForm(true);
+  };
+
+  const handleCloseForm = () => {
+    setShowForm(false);
+    setEditingSLO(undefined);
+    resetForm();
+  };
+
+  const handleSubmit = (e: React.FormEvent) => {
+    e.preventDefault();
+    const data = {
+      name,
+      isEnabled,
+      providerID: Number(providerID),
+      model,
+      metric,
+      percentile: parseFloat(percentile) || 0,
+      target: Math.round((parseFloat(targetMs) || 0) * MS),
+      window: Math.round((parseFloat(windowMinutes) || 0) * MINUTE),
+      minSamples: parseInt(minSamples, 10) || 0,
+      persistence: Math.round((parseFloat(persistenceMinutes) || 0) * MINUTE),
+    };
+
+    if (editingSLO) {
+      updateSLO.mutate({ id: editingSLO.id, data }, { onSuccess: handleCloseForm });
+    } else {
+      createSLO.mutate(data, { onSuccess: handleCloseForm });
+    }
+  };
+
+  const handleDelete = (id: number) => {
+    if (confirm(t('latencySLOs.deleteConfirm'))) {
+      deleteSLO.mutate(id);
+    }
+  };
+
+  const describeObjective = (s: { metric: LatencySLOMetric; percentile: number; target: number }) =>
+    `${t(`latencySLOs.metrics.${s.metric}`)} p${s.percentile} < ${formatDuration(s.target)}`;
+
+  const statusBadge = (status: LatencySLOStatus) => {
+    if (status.persistent) {
+      return <Badge variant="danger">{t('latencySLOs.persistentViolation')}</Badge>;
+    }
+    if (status.violating) {
+      return <Badge variant="warning">{t('latencySLOs.violating')}</Badge>;
+    }
+    if (status.samples === 0) {
+      return <Badge variant="outline">{t('latencySLOs.noData')}</Badge>;
+    }
+    return <Badge variant="success">{t('latencySLOs.meeting')}</Badge>;
+  };
+
+  const isPending = createSLO.isPending || updateSLO.isPending;
+  const errorMessage = (createSLO.error ?? updateSLO.error)?.message;
+
+  return (
+    <div className="space-y-6">
+      <div className="flex items-center justify-between">
+        <div>
+          <h2 className="text-2xl font-bold">{t('latencySLOs.title')}</h2>
+          <p className="text-sm text-muted-foreground">{t('latencySLOs.description')}</p>
+        </div>
+        <Button onClick={() => setShowForm(true)}>
+          <Plus className="mr-2 h-4 w-4" />
+          {t('latencySLOs.addSLO')}
+        </Button>
+      </div>
+
+      <Card>
+        <CardHeader>
+          <CardTitle>{t('latencySLOs.compliance')}</CardTitle>
+        </CardHeader>
+        <CardContent>
+          <Table>
+            <TableHeader>
+              <TableRow>
+                <TableHead>{t('latencySLOs.name')}</TableHead>
+                <TableHead>{t('latencySLOs.provider')}</TableHead>
+                <TableHead>{t('latencySLOs.objective')}</TableHead>
+                <TableHead>{t('latencySLOs.current')}</TableHead>
+                <TableHead>{t('latencySLOs.withinTarget')}</TableHead>
+                <TableHead>{t('latencySLOs.samples')}</TableHead>
+                <TableHead>{t('latencySLOs.status')}</TableHead>
+              </TableRow>
+            </TableHeader>
+            <TableBody>
+              {stats?.map((status) => (
+                <TableRow key={`${status.sloID}-${status.providerID}`}>
+                  <TableCell>
+                    <span className="font-medium">{status.sloName}</span>
+                    {status.model && (
+                      <div className="text-xs text-muted-foreground">{status.model}</div>
+                    )}
+                  </TableCell>
+                  <TableCell>{status.providerName || `#${status.providerID}`}</TableCell>
+                  <TableCell className="text-sm">{describeObjective(status)}</TableCell>
+                  <TableCell className="font-mono text-sm">
+                    {status.samples > 0 ? formatDuration(status.value) : '-'}
+                  </TableCell>
+                  <TableCell className="font-mono text-sm">
+                    {status.samples > 0 ? `${(status.compliance * 100).toFixed(1)}%` : '-'}
+                  </TableCell>
+                  <TableCell>{status.samples}</TableCell>
+                  <TableCell>
+                    {statusBadge(status)}
+                    {status.violatingSince && (
+                      <div className="text-xs text-muted-foreground">
+                        {t('latencySLOs.since', {
+                          time: new Date(status.violatingSince).toLocaleTimeString(),
+                        })}
+                      </div>
+                    )}
+                  </TableCell>
+                </TableRow>
+              ))}
+              {(!stats || stats.length === 0) && (
+                <TableRow>
+                  <TableCell colSpan={7} className="text-center text-gray-500">
+                    {t('latencySLOs.noStats')}
+                  </TableCell>
+                </TableRow>
+              )}
+            </TableBody>
+          </Table>
+        </CardContent>
+      </Card>
+
+      {showForm && (
+        <Card>
+          <CardHeader>
+            <CardTitle>{editingSLO ? t('latencySLOs.editSLO') : t('latencySLOs.newSLO')}</CardTitle>
+          </CardHeader>
+          <CardContent>
+            <form onSubmit={handleSubmit} className="space-y-4">
+              <div className="grid gap-4 md:grid-cols-2">
+                <div>
+                  <label className="mb-1 block text-sm font-medium">{t('latencySLOs.name')}</label>
+                  <Input value={name} onChange={(e) => setName(e.target.value)} required />
+                </div>
+                <div className="flex items-end gap-2 pb-2">
+                  <Switch checked={isEnabled} onCheckedChange={setIsEnabled} />
+                  <span className="text-sm">{t('latencySLOs.enabled')}</span>
+                </div>
+                <div>
+                  <label className="mb-1 block text-sm font-medium">
+                    {t('latencySLOs.provider')}
+                  </label>
+                  <select
+                    value={providerID}
+                    onChange={(e) => setProviderID(e.target.value)}
+                    className={selectClassName}
+                  >
+                    <option value="0">{t('latencySLOs.allProviders')}</option>
+                    {providers?.map((p) => (
+                      <option key={p.id} value={p.id}>
+                        {p.name}
+                      </option>
+                    ))}
+                  </select>
+                </div>
+                <div>
+                  <label className="mb-1 block text-sm font-medium">{t('latencySLOs.model')}</label>
+                  <Input
+                    value={model}
+                    onChange={(e) => setModel(e.target.value)}
+                    placeholder="claude-sonnet-*"
+                  />
+                </div>
+                <div>
+                  <label className="mb-1 block text-sm font-medium">{t('latencySLOs.metric')}</label>
+                  <select
+                    value={metric}
+                    onChange={(e) => setMetric(e.target.value as LatencySLOMetric)}
+                    className={selectClassName}
+                  >
+                    <option value="ttfb">{t('latencySLOs.metrics.ttfb')}</option>
+                    <option value="duration">{t('latencySLOs.metrics.duration')}</option>
+                  </select>
+                </div>
+                <div className="grid grid-cols-2 gap-2">
+                  <div>
+                    <label className="mb-1 block text-sm font-medium">
+                      {t('latencySLOs.percentile')}
+                    </label>
+                    <Input
+                      type="number"
+                      min="1"
+                      max="100"
+                      step="0.1"
+                      value={percentile}
+                      onChange={(e) => setPercentile(e.target.value)}
+                    />
+                  </div>
+                  <div>
+                    <label className="mb-1 block text-sm font-medium">
+                      {t('latencySLOs.targetMs')}
+                    </label>
+                    <Input
+                      type="number"
+                      min="1"
+                      value={targetMs}
+                      onChange={(e) => setTargetMs(e.target.value)}
+                      required
+                    />
+                  </div>
+                </div>
+                <div>
+                  <label className="mb-1 block text-sm font-medium">
+                    {t('latencySLOs.windowMinutes')}
+                  </label>
+                  <Input
+                    type="number"
+                    min="1"
+                    max="1440"
+                    value={windowMinutes}
+                    onChange={(e) => setWindowMinutes(e.target.value)}
+                  />
+                </div>
+                <div className="grid grid-cols-2 gap-2">
+                  <div>
+                    <label className="mb-1 block text-sm font-medium">
+                      {t('latencySLOs.minSamples')}
+                    </label>
+                    <Input
+                      type="number"
+                      min="1"
+                      value={minSamples}
+                      onChange={(e) => setMinSamples(e.target.value)}
+                    />
+                  </div>
+                  <div>
+                    <label className="mb-1 block text-sm font-medium">
+                      {t('latencySLOs.persistenceMinutes')}
+                    </label>
+                    <Input
+                      type="number"
+                      min="0"
+                      value={persistenceMinutes}
+                      onChange={(e) => setPersistenceMinutes(e.target.value)}
+                    />
+                  </div>
+                </div>
+              </div>
+              <p className="text-xs text-muted-foreground">{t('latencySLOs.formHint')}</p>
+
+              {errorMessage && <p className="text-sm text-red-500">{errorMessage}</p>}
+
+              <div className="flex justify-end gap-2">
+                <Button type="button" variant="outline" onClick={handleCloseForm}>
+                  {t('common.cancel')}
+                </Button>
+                <Button type="submit" disabled={isPending}>
+                  {isPending
+                    ? t('common.saving')
+                    : editingSLO
+                      ? t('routes.update')
+                      : t('routes.create')}
+                </Button>
+              </div>
+            </form>
+          </CardContent>
+        </Card>
+      )}
+
+      <Card>
+        <CardHeader>
+          <CardTitle>{t('latencySLOs.allSLOs')}</CardTitle>
+        </CardHeader>
+        <CardContent>
+          {isLoading ? (
+            <p className="text-gray-500">{t('common.loading')}</p>
+          ) : (
+            <Table>
+              <TableHeader>
+                <TableRow>
+                  <TableHead>{t('latencySLOs.name')}</TableHead>
+                  <TableHead>{t('latencySLOs.provider')}</TableHead>
+                  <TableHead>{t('latencySLOs.objective')}</TableHead>
+                  <TableHead>{t('latencySLOs.window')}</TableHead>
+                  <TableHead>{t('common.actions')}</TableHead>
+                </TableRow>
+              </TableHeader>
+              <TableBody>
+                {slos?.map((slo) => (
+                  <TableRow key={slo.id}>
+                    <TableCell>
+                      <div className="flex items-center gap-2">
+                        <span className="font-medium">{slo.name}</span>
+                        {!slo.isEnabled && (
+                          <Badge variant="outline">{t('latencySLOs.disabled')}</Badge>
+                        )}
+                      </div>
+                      {slo.model && (
+                        <div className="text-xs text-muted-foreground">{slo.model}</div>
+                      )}
+                    </TableCell>
+                    <TableCell>{providerName(slo.providerID)}</TableCell>
+                    <TableCell className="text-sm">{describeObjective(slo)}</TableCell>
+                    <TableCell className="text-sm">{formatDuration(slo.window)}</TableCell>
+                    <TableCell>
+                      <div className="flex gap-1">
+                        <Button variant="ghost" size="sm" onClick={() => handleEdit(slo)}>
+                          <Pencil className="h-4 w-4" />
+                        </Button>
+                        <Button
+                          variant="ghost"
+                          size="sm"
+                          onClick={() => handleDelete(slo.id)}
+                          disabled={deleteSLO.isPending}
+                        >
+                          <Trash2 className="h-4 w-4 text-red-500" />
+                        </Button>
+                      </div>
+                    </TableCell>
+                  </TableRow>
+                ))}
+                {(!slos || slos.length === 0) && (
+                  <TableRow>
+                    <TableCell colSpan={5} className="text-center text-gray-500">
+                      {t('latencySLOs.noSLOs')}
+                    </TableCell>
+                  </TableRow>
+                )}
+              </TableBody>
+            </Table>
+          )}
+        </CardContent>
+      </Card>
+    </div>
+  );
+}
@@ -1,5 +1,5 @@
 import { useState } from 'react';
-import { Card, CardContent, CardHeader, CardTitle, Input, Button } from '@/components/ui';
+import { Card, CardContent, CardHeader, CardTitle, Input, Button, Switch } from '@/components/ui';
 import { useUpdateProject, projectKeys } from '@/hooks/queries';
 import { useQueryClient } from '@tanstack/react-query';
 import { useNavigate } from 'react-router-dom';
@@ -26,7 +26,12 @@ export function OverviewTab({ project }: OverviewTabProps) {
     updateProject.mutate(
       {
         id: project.id,
-        data: { name, slug, enabledCustomRoutes: project.enabledCustomRoutes },
+        data: {
+          name,
+          slug,
+          enabledCustomRoutes: project.enabledCustomRoutes,
+          localBackgroundResponder: project.localBackgroundResponder,
+        },
       },
       {
         onSuccess: (updatedProject) => {
@@ -44,6 +49,28 @@ export function OverviewTab({ project }: OverviewTabProps) {
     );
   };
 
+  const handleLocalResponderToggle = (checked: boolean) => {
+    updateProject.mutate(
+      {
+        id: project.id,
+        data: {
+          name: project.name,
+          slug: project.slug,
+          enabledCustomRoutes: project.enabledCustomRoutes,
+          localBackgroundResponder: checked,
+        },
+      },
+      {
+        onSuccess: () => {
+          queryClient.invalidateQueries({ queryKey: projectKeys.lists() });
+          queryClient.invalidateQueries({
+            queryKey: projectKeys.slug(project.slug),
+          });
+        },
+      },
+    );
+  };
+
   const copyToClipboard = (key: string, text: string) => {
     navigator.clipboard.writeText(text);
     setCopied(key);
@@ -149,6 +176,25 @@ export function OverviewTab({ project }: OverviewTabProps) {
           </div>
         </CardContent>
       </Card>
+
+      {/* Background Requests */}
+      <Card className="border-border bg-card">
+        <CardContent className="flex items-center justify-between gap-6 py-4">
+          <div>
+            <label className="text-sm font-medium text-text-primary">
+              {t('projects.localBackgroundResponder')}
+            </label>
+            <p className="text-xs text-text-muted mt-1">
+              {t('projects.localBackgroundResponderDesc')}
+            </p>
+          </div>
+          <Switch
+            checked={project.localBackgroundResponder}
+            onCheckedChange={handleLocalResponderToggle}
+            disabled={updateProject.isPending}
+          />
+        </CardContent>
+      </Card>
     </div>
   );
 }
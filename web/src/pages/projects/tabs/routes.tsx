@@ -144,6 +144,7 @@ export function RoutesTab({ project }: RoutesTabProps) {
           name: project.name,
           slug: project.slug,
           enabledCustomRoutes: newEnabled,
+          localBackgroundResponder: project.localBackgroundResponder,
         },
       },
       {
@@ -19,6 +19,7 @@ import type {
   AntigravityModelQuota,
   ModelMapping,
   ModelMappingInput,
+  ThoughtSignatureMode,
 } from '@/lib/transport';
 import { getTransport } from '@/lib/transport';
 import {
@@ -26,6 +27,7 @@ import {
   useCreateModelMapping,
   useUpdateModelMapping,
   useDeleteModelMapping,
+  useUpdateProvider,
 } from '@/hooks/queries';
 import { Button } from '@/components/ui';
 import { ModelInput } from '@/components/ui/model-input';
@@ -127,6 +129,47 @@ function ModelQuotaCard({ model }: { model: AntigravityModelQuota }) {
 }
 
 // Provider Model Mappings Section
+// 工具调用 thoughtSignature 的处理方式（路由可单独覆盖）
+function ThoughtSignatureSetting({ provider }: { provider: Provider }) {
+  const { t } = useTranslation();
+  const updateProvider = useUpdateProvider();
+  const antigravity = provider.config?.antigravity;
+  const mode = antigravity?.thoughtSignatureMode || 'auto';
+
+  const handleChange = (value: ThoughtSignatureMode) => {
+    if (!antigravity) return;
+    updateProvider.mutate({
+      id: provider.id,
+      data: {
+        ...provider,
+        config: {
+          ...provider.config,
+          antigravity: { ...antigravity, thoughtSignatureMode: value === 'auto' ? '' : value },
+        },
+      },
+    });
+  };
+
+  return (
+    <div>
+      <h4 className="text-lg font-semibold text-foreground mb-4 border-b border-border pb-2">
+        {t('providers.thoughtSignature.title')}
+      </h4>
+      <p className="text-xs text-muted-foreground mb-4">{t('providers.thoughtSignature.desc')}</p>
+      <select
+        value={mode}
+        onChange={(e) => handleChange(e.target.value as ThoughtSignatureMode)}
+        disabled={updateProvider.isPending}
+        className="flex h-9 w-full max-w-md rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs transition-colors focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring disabled:cursor-not-allowed disabled:opacity-50"
+      >
+        <option value="auto">{t('providers.thoughtSignature.auto')}</option>
+        <option value="strict">{t('providers.thoughtSignature.strict')}</option>
+        <option value="always_skip">{t('providers.thoughtSignature.alwaysSkip')}</option>
+      </select>
+    </div>
+  );
+}
+
 function ProviderModelMappings({ provider }: { provider: Provider }) {
   const { t } = useTranslation();
   const { data: allMappings } = useModelMappings();
@@ -423,6 +466,9 @@ export function AntigravityProviderView({
           {/* Provider Model Mappings */}
           <ProviderModelMappings provider={provider} />
 
+          {/* Thought Signature Mode */}
+          <ThoughtSignatureSetting provider={provider} />
+
           {/* Supported Clients */}
           <div>
             <h4 className="text-lg font-semibold text-foreground mb-4 border-b border-border pb-2">
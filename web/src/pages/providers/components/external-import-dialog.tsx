@@ -0,0 +1,188 @@
+import { useRef, useState } from 'react';
+import { useTranslation } from 'react-i18next';
+import { useQueryClient } from '@tanstack/react-query';
+import { FileUp, Loader2 } from 'lucide-react';
+import {
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+} from '@/components/ui/dialog';
+import { Badge } from '@/components/ui/badge';
+import { Button } from '@/components/ui/button';
+import { getTransport } from '@/lib/transport';
+import type {
+  ExternalImportFile,
+  ExternalImportResult,
+  ExternalImportStatus,
+} from '@/lib/transport';
+
+const STATUS_VARIANTS: Record<ExternalImportStatus, 'success' | 'info' | 'warning' | 'danger'> = {
+  new: 'info',
+  created: 'success',
+  skipped: 'warning',
+  failed: 'danger',
+};
+
+interface ExternalImportDialogProps {
+  open: boolean;
+  onOpenChange: (open: boolean) => void;
+}
+
+/**
+ * 从 CLIProxyAPI / Antigravity-Manager 的配置和 Token 文件导入 Provider
+ * 选择文件后先预览（dryRun），确认后再创建
+ */
+export function ExternalImportDialog({ open, onOpenChange }: ExternalImportDialogProps) {
+  const { t } = useTranslation();
+  const queryClient = useQueryClient();
+  const fileInputRef = useRef<HTMLInputElement>(null);
+  const [files, setFiles] = useState<ExternalImportFile[]>([]);
+  const [result, setResult] = useState<ExternalImportResult | null>(null);
+  const [error, setError] = useState<string | null>(null);
+  const [loading, setLoading] = useState(false);
+
+  const reset = () => {
+    setFiles([]);
+    setResult(null);
+    setError(null);
+    if (fileInputRef.current) {
+      fileInputRef.current.value = '';
+    }
+  };
+
+  const run = async (selected: ExternalImportFile[], dryRun: boolean) => {
+    setLoading(true);
+    setError(null);
+    try {
+      const data = await getTransport().importExternalProviders(selected, dryRun);
+      setResult(data);
+      if (!dryRun) {
+        queryClient.invalidateQueries({ queryKey: ['providers'] });
+        queryClient.invalidateQueries({ queryKey: ['routes'] });
+        queryClient.invalidateQueries({ queryKey: ['model-mappings'] });
+      }
+    } catch (err) {
+      setError(err instanceof Error ? err.message : String(err));
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  const handleFiles = async (event: React.ChangeEvent<HTMLInputElement>) => {
+    const list = Array.from(event.target.files ?? []);
+    if (list.length === 0) return;
+    const selected = await Promise.all(
+      list.map(async (file) => ({ name: file.name, content: await file.text() })),
+    );
+    setFiles(selected);
+    await run(selected, true);
+  };
+
+  const pending = result?.dryRun ? result.providers.filter((p) => p.status === 'new').length : 0;
+
+  return (
+    <Dialog
+      open={open}
+      onOpenChange={(next: boolean) => {
+        onOpenChange(next);
+        if (!next) reset();
+      }}
+    >
+      <DialogContent className="max-w-3xl">
+        <DialogHeader>
+          <DialogTitle>{t('providers.externalImport.title')}</DialogTitle>
+          <DialogDescription>{t('providers.externalImport.description')}</DialogDescription>
+        </DialogHeader>
+
+        <div className="space-y-4 min-w-0">
+          <input
+            type="file"
+            ref={fileInputRef}
+            onChange={handleFiles}
+            accept=".yaml,.yml,.json"
+            multiple
+            className="hidden"
+          />
+          <Button
+            variant="outline"
+            onClick={() => fileInputRef.current?.click()}
+            disabled={loading}
+            className="flex items-center gap-2"
+          >
+            <FileUp size={14} />
+            {files.length > 0
+              ? t('providers.externalImport.filesSelected', { count: files.length })
+              : t('providers.externalImport.selectFiles')}
+          </Button>
+          <p className="text-xs text-muted-foreground">{t('providers.externalImport.hint')}</p>
+
+          {error && <p className="text-sm text-red-400">{error}</p>}
+
+          {result && (
+            <div className="space-y-3">
+              <div className="text-sm text-text-primary">
+                {result.dryRun
+                  ? t('providers.externalImport.preview', {
+                      providers: pending,
+                      routes: result.routes,
+                      modelMappings: result.modelMappings,
+                    })
+                  : t('providers.externalImport.completed', {
+                      providers: result.providers.filter((p) => p.status === 'created').length,
+                      routes: result.routes,
+                      modelMappings: result.modelMappings,
+                    })}
+              </div>
+
+              {result.providers.length > 0 && (
+                <div className="max-h-64 overflow-y-auto rounded-md border border-border divide-y divide-border">
+                  {result.providers.map((p, i) => (
+                    <div key={i} className="flex items-center justify-between gap-3 px-3 py-2">
+                      <div className="min-w-0">
+                        <div className="text-sm font-medium truncate">{p.name}</div>
+                        <div className="text-xs text-muted-foreground truncate">
+                          {p.type} · {p.clientTypes.join(', ')}
+                          {p.modelMappings > 0 &&
+                            ` · ${t('providers.externalImport.mappings', { count: p.modelMappings })}`}
+                          {' · '}
+                          {p.origin}
+                        </div>
+                        {p.error && <div className="text-xs text-red-400">{p.error}</div>}
+                      </div>
+                      <Badge variant={STATUS_VARIANTS[p.status]}>
+                        {t(`providers.externalImport.status.${p.status}`)}
+                      </Badge>
+                    </div>
+                  ))}
+                </div>
+              )}
+
+              {result.warnings.length > 0 && (
+                <div className="text-xs text-amber-500 space-y-1">
+                  {result.warnings.map((warning, i) => (
+                    <div key={i}>• {warning}</div>
+                  ))}
+                </div>
+              )}
+            </div>
+          )}
+        </div>
+
+        <DialogFooter>
+          <Button variant="outline" onClick={() => onOpenChange(false)}>
+            {result && !result.dryRun ? t('common.close') : t('common.cancel')}
+          </Button>
+          {(!result || result.dryRun) && (
+            <Button onClick={() => run(files, false)} disabled={loading || pending === 0}>
+              {loading && <Loader2 className="h-4 w-4 animate-spin mr-2" />}
+              {t('common.import')}
+            </Button>
+          )}
+        </DialogFooter>
+      </DialogContent>
+    </Dialog>
+  );
+}
@@ -26,6 +26,7 @@ import {
   useCreateModelMapping,
   useUpdateModelMapping,
   useDeleteModelMapping,
+  useRetryConfigs,
 } from '@/hooks/queries';
 import type {
   Provider,
@@ -276,10 +277,51 @@ type EditFormData = {
   name: string;
   baseURL: string;
   apiKey: string;
+  openaiOrganization: string;
+  openaiProject: string;
   clients: ClientConfig[];
   supportModels: string[];
+  retryConfigID: number;
 };
 
+// Default retry config of the provider's routes; routes with their own config override it
+function ProviderRetryConfig({
+  value,
+  onChange,
+}: {
+  value: number;
+  onChange: (id: number) => void;
+}) {
+  const { t } = useTranslation();
+  const { data: retryConfigs } = useRetryConfigs();
+
+  return (
+    <div className="space-y-4">
+      <h3 className="text-lg font-semibold text-foreground border-b border-border pb-2">
+        {t('providers.retryConfig.title', 'Default Retry Config')}
+      </h3>
+      <p className="text-sm text-muted-foreground">
+        {t(
+          'providers.retryConfig.desc',
+          "Used by this provider's routes that don't set their own retry config. Falls back to the global default.",
+        )}
+      </p>
+      <select
+        value={value}
+        onChange={(e) => onChange(Number(e.target.value))}
+        className="flex h-9 w-full max-w-md rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs transition-colors focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring"
+      >
+        <option value={0}>{t('providers.retryConfig.inherit', 'Global default')}</option>
+        {retryConfigs?.map((c) => (
+          <option key={c.id} value={c.id}>
+            {c.name}
+          </option>
+        ))}
+      </select>
+    </div>
+  );
+}
+
 export function ProviderEditFlow({ provider, onClose }: ProviderEditFlowProps) {
   const { t } = useTranslation();
   const [saving, setSaving] = useState(false);
@@ -302,8 +344,11 @@ export function ProviderEditFlow({ provider, onClose }: ProviderEditFlowProps) {
     name: provider.name,
     baseURL: provider.config?.custom?.baseURL || '',
     apiKey: provider.config?.custom?.apiKey || '',
+    openaiOrganization: provider.config?.custom?.openaiOrganization || '',
+    openaiProject: provider.config?.custom?.openaiProject || '',
     clients: initClients(),
     supportModels: provider.supportModels || [],
+    retryConfigID: provider.retryConfigID ?? 0,
   });
 
   const updateClient = (clientId: ClientType, updates: Partial<ClientConfig>) => {
@@ -344,10 +389,13 @@ export function ProviderEditFlow({ provider, onClose }: ProviderEditFlowProps) {
             baseURL: formData.baseURL,
             apiKey: formData.apiKey || provider.config?.custom?.apiKey || '',
             clientBaseURL: Object.keys(clientBaseURL).length > 0 ? clientBaseURL : undefined,
+            openaiOrganization: formData.openaiOrganization.trim() || undefined,
+            openaiProject: formData.openaiProject.trim() || undefined,
           },
         },
         supportedClientTypes,
         supportModels: formData.supportModels.length > 0 ? formData.supportModels : undefined,
+        retryConfigID: formData.retryConfigID,
       };
 
       await updateProvider.mutateAsync({ id: Number(provider.id), data });
@@ -512,6 +560,40 @@ export function ProviderEditFlow({ provider, onClose }: ProviderEditFlowProps) {
                     className="w-full"
                   />
                 </div>
+
+                <div className="grid grid-cols-2 gap-4">
+                  <div>
+                    <label className="text-sm font-medium text-foreground block mb-2">
+                      OpenAI Organization
+                    </label>
+                    <Input
+                      type="text"
+                      value={formData.openaiOrganization}
+                      onChange={(e) =>
+                        setFormData((prev) => ({ ...prev, openaiOrganization: e.target.value }))
+                      }
+                      placeholder="org-..."
+                      className="w-full"
+                    />
+                  </div>
+                  <div>
+                    <label className="text-sm font-medium text-foreground block mb-2">
+                      OpenAI Project
+                    </label>
+                    <Input
+                      type="text"
+                      value={formData.openaiProject}
+                      onChange={(e) =>
+                        setFormData((prev) => ({ ...prev, openaiProject: e.target.value }))
+                      }
+                      placeholder="proj_..."
+                      className="w-full"
+                    />
+                  </div>
+                </div>
+                <p className="text-xs text-muted-foreground -mt-2">
+                  Sent as OpenAI-Organization / OpenAI-Project headers, for upstreams that require them.
+                </p>
               </div>
             </div>
           </div>
@@ -529,6 +611,12 @@ export function ProviderEditFlow({ provider, onClose }: ProviderEditFlowProps) {
             onChange={(models) => setFormData((prev) => ({ ...prev, supportModels: models }))}
           />
 
+          {/* Provider default retry config */}
+          <ProviderRetryConfig
+            value={formData.retryConfigID}
+            onChange={(id) => setFormData((prev) => ({ ...prev, retryConfigID: id }))}
+          />
+
           {/* Provider Model Mappings */}
           <ProviderModelMappings provider={provider} />
 
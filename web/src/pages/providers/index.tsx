@@ -1,5 +1,5 @@
 import { useMemo, useRef, useState } from 'react';
-import { Plus, Layers, Download, Upload, Search } from 'lucide-react';
+import { Plus, Layers, Download, Upload, Search, FileInput } from 'lucide-react';
 import { useTranslation } from 'react-i18next';
 import { useNavigate } from 'react-router-dom';
 import { useProviders, useAllProviderStats } from '@/hooks/queries';
@@ -7,6 +7,7 @@ import { useStreamingRequests } from '@/hooks/use-streaming';
 import type { Provider, ImportResult } from '@/lib/transport';
 import { getTransport } from '@/lib/transport';
 import { ProviderRow } from './components/provider-row';
+import { ExternalImportDialog } from './components/external-import-dialog';
 import { useQueryClient } from '@tanstack/react-query';
 import { Button } from '@/components/ui/button';
 import { Input } from '@/components/ui/input';
@@ -22,6 +23,7 @@ export function ProvidersPage() {
   const { countsByProvider } = useStreamingRequests();
   const [importStatus, setImportStatus] = useState<ImportResult | null>(null);
   const [searchQuery, setSearchQuery] = useState('');
+  const [externalImportOpen, setExternalImportOpen] = useState(false);
   const fileInputRef = useRef<HTMLInputElement>(null);
   const queryClient = useQueryClient();
 
@@ -145,6 +147,15 @@ export function ProvidersPage() {
           <Upload size={14} />
           <span>{t('common.import')}</span>
         </Button>
+        <Button
+          onClick={() => setExternalImportOpen(true)}
+          className="flex items-center gap-2"
+          title={t('providers.externalImport.description')}
+          variant={'outline'}
+        >
+          <FileInput size={14} />
+          <span>{t('providers.externalImport.button')}</span>
+        </Button>
         <Button
           onClick={handleExport}
           className="flex items-center gap-2"
@@ -220,6 +231,8 @@ export function ProvidersPage() {
         </div>
       </div>
 
+      <ExternalImportDialog open={externalImportOpen} onOpenChange={setExternalImportOpen} />
+
       {/* Import Status Toast */}
       {importStatus && (
         <div className="fixed bottom-6 right-6 bg-card border border-border rounded-lg shadow-lg p-4">
@@ -6,6 +6,7 @@ import {
   useProxyUpstreamAttempts,
   useProxyRequestUpdates,
   useProviders,
+  useDeletedProviders,
   useProjects,
   useSessions,
   useRoutes,
@@ -25,6 +26,7 @@ export function RequestDetailPage() {
   const { data: request, isLoading, error } = useProxyRequest(Number(id));
   const { data: attempts } = useProxyUpstreamAttempts(Number(id));
   const { data: providers } = useProviders();
+  const { data: deletedProviders } = useDeletedProviders();
   const { data: projects } = useProjects();
   const { data: sessions } = useSessions();
   const { data: routes } = useRoutes();
@@ -47,14 +49,17 @@ export function RequestDetailPage() {
     return () => window.removeEventListener('keydown', handleKeyDown);
   }, [navigate]);
 
-  // Create lookup map for provider names
+  // Create lookup map for provider names (deleted providers keep their name in history)
   const providerMap = useMemo(() => {
     const map = new Map<number, string>();
+    deletedProviders?.forEach((p) => {
+      map.set(p.id, p.name);
+    });
     providers?.forEach((p) => {
       map.set(p.id, p.name);
     });
     return map;
-  }, [providers]);
+  }, [providers, deletedProviders]);
 
   // Create lookup map for project names
   const projectMap = useMemo(() => {
@@ -127,7 +132,12 @@ export function RequestDetailPage() {
         <div className="shrink-0 bg-red-400/10 border-b border-red-400/20 px-6 py-3 flex items-start gap-3">
           <AlertCircle className="mt-0.5 h-4 w-4 shrink-0 text-red-400" />
           <div className="flex-1">
-            <h4 className="text-sm font-medium text-red-400 mb-1">Request Failed</h4>
+            <h4 className="text-sm font-medium text-red-400 mb-1">
+              Request Failed
+              {request.errorCode && (
+                <span className="ml-2 font-mono text-xs text-red-400/70">{request.errorCode}</span>
+              )}
+            </h4>
             <pre className="whitespace-pre-wrap wrap-break-words font-mono text-xs text-red-400/90 max-h-24 overflow-auto">
               {request.error}
             </pre>
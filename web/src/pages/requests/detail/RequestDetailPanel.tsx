@@ -13,7 +13,14 @@ import { Server, Code, Database, Info, Zap } from 'lucide-react';
 import { useTranslation } from 'react-i18next';
 import type { ProxyUpstreamAttempt, ProxyRequest } from '@/lib/transport';
 import { cn } from '@/lib/utils';
-import { CopyButton, CopyAsCurlButton, DiffButton, EmptyState } from './components';
+import {
+  CopyButton,
+  CopyAsCurlButton,
+  DiffButton,
+  DownloadBodyButton,
+  EmptyState,
+  ReplayButton,
+} from './components';
 import { RequestDetailView } from './RequestDetailView';
 
 // Selection type: either the main request or an attempt
@@ -157,6 +164,7 @@ export function RequestDetailPanel({
               <code className="flex-1 font-mono text-xs text-foreground break-all">
                 {selectedAttempt.requestInfo.url}
               </code>
+              <ReplayButton attempt={selectedAttempt} />
               <CopyAsCurlButton requestInfo={selectedAttempt.requestInfo} />
             </div>
 
@@ -211,6 +219,11 @@ export function RequestDetailPanel({
                         })()}
                         title={t('requests.compareBody')}
                       />
+                      <DownloadBodyButton
+                        proxyRequestId={request.id}
+                        attemptId={selectedAttempt.id}
+                        part="request"
+                      />
                       <CopyButton
                         content={(() => {
                           try {
@@ -290,15 +303,22 @@ export function RequestDetailPanel({
                     <h5 className="text-xs font-semibold text-muted-foreground uppercase tracking-wider flex items-center gap-2">
                       <Database size={14} /> Body
                     </h5>
-                    <CopyButton
-                      content={(() => {
-                        try {
-                          return formatJSON(JSON.parse(selectedAttempt.responseInfo.body));
-                        } catch {
-                          return selectedAttempt.responseInfo.body;
-                        }
-                      })()}
-                    />
+                    <div className="flex items-center gap-2">
+                      <DownloadBodyButton
+                        proxyRequestId={request.id}
+                        attemptId={selectedAttempt.id}
+                        part="response"
+                      />
+                      <CopyButton
+                        content={(() => {
+                          try {
+                            return formatJSON(JSON.parse(selectedAttempt.responseInfo.body));
+                          } catch {
+                            return selectedAttempt.responseInfo.body;
+                          }
+                        })()}
+                      />
+                    </div>
                   </div>
                   <div className="flex-1 rounded-lg border border-border bg-muted/50 dark:bg-muted/30 p-4 overflow-auto shadow-inner relative group min-h-0">
                     <div className="absolute top-2 right-2 opacity-0 group-hover:opacity-100 transition-opacity">
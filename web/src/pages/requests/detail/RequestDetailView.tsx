@@ -14,7 +14,7 @@ import { useTranslation } from 'react-i18next';
 import type { ProxyRequest, ClientType } from '@/lib/transport';
 import { cn } from '@/lib/utils';
 import { ClientIcon, getClientName, getClientColor } from '@/components/icons/client-icons';
-import { CopyButton, CopyAsCurlButton, EmptyState } from './components';
+import { CopyButton, CopyAsCurlButton, DownloadBodyButton, EmptyState } from './components';
 
 interface RequestDetailViewProps {
   request: ProxyRequest;
@@ -131,16 +131,19 @@ export function RequestDetailView({
                     <h5 className="text-xs font-semibold text-muted-foreground uppercase tracking-wider flex items-center gap-2">
                       <Database size={14} /> Body
                     </h5>
-                    <CopyButton
-                      content={(() => {
-                        try {
-                          return formatJSON(JSON.parse(request.requestInfo.body));
-                        } catch {
-                          return request.requestInfo.body;
-                        }
-                      })()}
-                      label={t('common.copy')}
-                    />
+                    <div className="flex items-center gap-2">
+                      <DownloadBodyButton proxyRequestId={request.id} part="request" />
+                      <CopyButton
+                        content={(() => {
+                          try {
+                            return formatJSON(JSON.parse(request.requestInfo.body));
+                          } catch {
+                            return request.requestInfo.body;
+                          }
+                        })()}
+                        label={t('common.copy')}
+                      />
+                    </div>
                   </div>
                   <div className="flex-1 rounded-lg border border-border bg-muted/50 dark:bg-muted/30 p-4 overflow-auto shadow-inner relative group min-h-0">
                     <div className="absolute top-2 right-2 opacity-0 group-hover:opacity-100 transition-opacity">
@@ -212,17 +215,25 @@ export function RequestDetailView({
                   <div className="flex items-center justify-between shrink-0">
                     <h5 className="text-xs font-semibold text-muted-foreground uppercase tracking-wider flex items-center gap-2">
                       <Database size={14} /> Body
+                      {request.responseInfo.bodyTruncated && (
+                        <span className="text-amber-500 normal-case tracking-normal font-normal">
+                          {t('requests.bodyTruncated', { size: request.responseInfo.bodySize })}
+                        </span>
+                      )}
                     </h5>
-                    <CopyButton
-                      content={(() => {
-                        try {
-                          return formatJSON(JSON.parse(request.responseInfo.body));
-                        } catch {
-                          return request.responseInfo.body;
-                        }
-                      })()}
-                      label={t('common.copy')}
-                    />
+                    <div className="flex items-center gap-2">
+                      <DownloadBodyButton proxyRequestId={request.id} part="response" />
+                      <CopyButton
+                        content={(() => {
+                          try {
+                            return formatJSON(JSON.parse(request.responseInfo.body));
+                          } catch {
+                            return request.responseInfo.body;
+                          }
+                        })()}
+                        label={t('common.copy')}
+                      />
+                    </div>
                   </div>
                   <div className="flex-1 rounded-lg border border-border bg-muted/50 dark:bg-muted/30 p-4 overflow-auto shadow-inner relative group min-h-0">
                     <div className="absolute top-2 right-2 opacity-0 group-hover:opacity-100 transition-opacity">
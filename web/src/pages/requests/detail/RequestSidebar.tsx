@@ -15,6 +15,7 @@ function getStatusIcon(status: string) {
     case 'FAILED':
       return <XCircle className="h-4 w-4 text-red-400" />;
     case 'CANCELLED':
+    case 'INTERRUPTED':
       return <Ban className="h-4 w-4 text-warning" />;
     case 'IN_PROGRESS':
       return <Loader2 className="h-4 w-4 text-info animate-spin" />;
@@ -185,6 +186,16 @@ export function RequestSidebar({
                     {attempt.duration > 0 ? formatDuration(attempt.duration) : '-'}
                   </span>
                 </div>
+                {attempt.decision && (
+                  <div
+                    className="mt-1 text-[10px] font-mono text-muted-foreground truncate"
+                    title={attempt.errorCode}
+                  >
+                    → {attempt.decision.action} · {attempt.decision.reason}
+                    {attempt.decision.fallbackModel && ` (${attempt.decision.fallbackModel})`}
+                    {attempt.decision.cooldownUntil && ' · cooldown'}
+                  </div>
+                )}
               </button>
             ))}
           </div>
@@ -0,0 +1,54 @@
+import { useState } from 'react';
+import { Button } from '@/components/ui';
+import { Download, Loader2 } from 'lucide-react';
+import { useTranslation } from 'react-i18next';
+import { getTransport } from '@/lib/transport';
+
+interface DownloadBodyButtonProps {
+  proxyRequestId: number;
+  part: 'request' | 'response';
+  attemptId?: number;
+}
+
+// 下载存储的原始请求/响应体（SSE 流保持原样），避免在页面中渲染超大的正文
+export function DownloadBodyButton({ proxyRequestId, part, attemptId }: DownloadBodyButtonProps) {
+  const { t } = useTranslation();
+  const [downloading, setDownloading] = useState(false);
+
+  const handleDownload = async () => {
+    setDownloading(true);
+    try {
+      const blob = await getTransport().downloadProxyRequestBody(proxyRequestId, part, attemptId);
+      const ext = blob.type.startsWith('text/event-stream')
+        ? 'sse'
+        : blob.type.includes('json')
+          ? 'json'
+          : 'txt';
+      const url = URL.createObjectURL(blob);
+      const a = document.createElement('a');
+      a.href = url;
+      a.download = `request-${proxyRequestId}${attemptId ? `-attempt-${attemptId}` : ''}-${part}.${ext}`;
+      document.body.appendChild(a);
+      a.click();
+      document.body.removeChild(a);
+      URL.revokeObjectURL(url);
+    } catch (err) {
+      console.error('Failed to download body:', err);
+    } finally {
+      setDownloading(false);
+    }
+  };
+
+  return (
+    <Button
+      variant="outline"
+      size="sm"
+      onClick={handleDownload}
+      disabled={downloading}
+      className="h-6 px-2 text-[10px] gap-1"
+    >
+      {downloading ? <Loader2 className="h-3 w-3 animate-spin" /> : <Download className="h-3 w-3" />}
+      {t('common.download')}
+    </Button>
+  );
+}
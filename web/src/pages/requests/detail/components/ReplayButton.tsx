@@ -0,0 +1,155 @@
+import { useState } from 'react';
+import { useTranslation } from 'react-i18next';
+import { Loader2, RotateCcw } from 'lucide-react';
+import {
+  Button,
+  Dialog,
+  DialogContent,
+  DialogDescription,
+  DialogFooter,
+  DialogHeader,
+  DialogTitle,
+  Select,
+  SelectContent,
+  SelectItem,
+  SelectTrigger,
+  SelectValue,
+} from '@/components/ui';
+import { useProviders, useReplayAntigravityAttempt } from '@/hooks/queries';
+import type { ProxyUpstreamAttempt } from '@/lib/transport';
+import { cn } from '@/lib/utils';
+
+interface ReplayButtonProps {
+  attempt: ProxyUpstreamAttempt;
+}
+
+function formatBody(body: string): string {
+  try {
+    return JSON.stringify(JSON.parse(body), null, 2);
+  } catch {
+    return body;
+  }
+}
+
+// ReplayButton re-sends a failed Antigravity attempt with another account
+export function ReplayButton({ attempt }: ReplayButtonProps) {
+  const { t } = useTranslation();
+  const [isOpen, setIsOpen] = useState(false);
+  const [providerID, setProviderID] = useState('');
+  const { data: providers } = useProviders();
+  const replay = useReplayAntigravityAttempt();
+
+  const original = providers?.find((p) => p.id === attempt.providerID);
+  if (original?.type !== 'antigravity' || attempt.status !== 'FAILED') {
+    return null;
+  }
+  const candidates = providers?.filter((p) => p.type === 'antigravity' && p.id !== original.id) ?? [];
+  const result = replay.data;
+
+  const handleReplay = () => {
+    replay.mutate({
+      proxyRequestID: attempt.proxyRequestID,
+      attemptID: attempt.id,
+      providerID: Number(providerID),
+    });
+  };
+
+  return (
+    <>
+      <Button
+        variant="outline"
+        size="sm"
+        onClick={() => setIsOpen(true)}
+        className="h-6 px-2 text-[10px] gap-1"
+      >
+        <RotateCcw className="h-3 w-3" />
+        {t('requests.replay.button')}
+      </Button>
+      <Dialog
+        open={isOpen}
+        onOpenChange={(open: boolean) => {
+          setIsOpen(open);
+          if (!open) replay.reset();
+        }}
+      >
+        <DialogContent className="max-w-3xl">
+          <DialogHeader>
+            <DialogTitle>{t('requests.replay.title')}</DialogTitle>
+            <DialogDescription>{t('requests.replay.description')}</DialogDescription>
+          </DialogHeader>
+          <div className="space-y-4 min-w-0">
+            <div className="space-y-2">
+              <label className="text-xs font-medium text-text-secondary uppercase tracking-wider">
+                {t('requests.replay.provider')}
+              </label>
+              {candidates.length === 0 ? (
+                <p className="text-sm text-muted-foreground">{t('requests.replay.noProviders')}</p>
+              ) : (
+                <Select value={providerID} onValueChange={(v) => v && setProviderID(v)}>
+                  <SelectTrigger className="w-full">
+                    <SelectValue placeholder={t('requests.replay.selectProvider')}>
+                      {candidates.find((p) => String(p.id) === providerID)?.name}
+                    </SelectValue>
+                  </SelectTrigger>
+                  <SelectContent>
+                    {candidates.map((p) => (
+                      <SelectItem key={p.id} value={String(p.id)}>
+                        {p.name}
+                      </SelectItem>
+                    ))}
+                  </SelectContent>
+                </Select>
+              )}
+            </div>
+
+            {replay.error && (
+              <p className="text-sm text-red-400">{(replay.error as Error).message}</p>
+            )}
+
+            {result && (
+              <div className="space-y-2 min-w-0">
+                <div className="flex items-center gap-3 text-xs">
+                  {result.error ? (
+                    <span className="text-red-400">
+                      {t('requests.replay.connectionError', { error: result.error })}
+                    </span>
+                  ) : (
+                    <span
+                      className={cn(
+                        'px-2 py-1 rounded font-bold font-mono',
+                        result.status >= 400
+                          ? 'bg-red-400/10 text-red-400'
+                          : 'bg-blue-400/10 text-blue-400',
+                      )}
+                    >
+                      {result.status}
+                    </span>
+                  )}
+                  <span className="text-muted-foreground">{result.providerName}</span>
+                  <span className="text-muted-foreground">{result.durationMs}ms</span>
+                  {result.truncated && (
+                    <span className="text-amber-500">{t('requests.replay.truncated')}</span>
+                  )}
+                </div>
+                {result.body && (
+                  <pre className="max-h-80 overflow-auto rounded-lg border border-border bg-muted/50 p-4 text-xs font-mono whitespace-pre">
+                    {formatBody(result.body)}
+                  </pre>
+                )}
+              </div>
+            )}
+          </div>
+          <DialogFooter>
+            <Button variant="outline" onClick={() => setIsOpen(false)}>
+              {t('common.cancel')}
+            </Button>
+            <Button onClick={handleReplay} disabled={!providerID || replay.isPending}>
+              {replay.isPending && <Loader2 className="h-4 w-4 animate-spin mr-2" />}
+              {t('requests.replay.run')}
+            </Button>
+          </DialogFooter>
+        </DialogContent>
+      </Dialog>
+    </>
+  );
+}
@@ -2,4 +2,6 @@ export { CopyButton } from './CopyButton';
 export { CopyAsCurlButton } from './CopyAsCurlButton';
 export { DiffModal } from './DiffModal';
 export { DiffButton } from './DiffButton';
+export { DownloadBodyButton } from './DownloadBodyButton';
 export { EmptyState } from './EmptyState';
+export { ReplayButton } from './ReplayButton';
@@ -6,6 +6,7 @@ import {
   useProxyRequestUpdates,
   useProxyRequestsCount,
   useProviders,
+  useDeletedProviders,
   useProjects,
   useAPITokens,
 } from '@/hooks/queries';
@@ -20,6 +21,7 @@ import {
   Ban,
 } from 'lucide-react';
 import type { ProxyRequest, ProxyRequestStatus } from '@/lib/transport';
+import { useAttemptProgress } from '@/hooks/use-attempt-progress';
 import { ClientIcon } from '@/components/icons/client-icons';
 import {
   Table,
@@ -46,6 +48,7 @@ export const statusVariant: Record<
   FAILED: 'danger',
   CANCELLED: 'warning',
   REJECTED: 'danger',
+  INTERRUPTED: 'warning',
 };
 
 export function RequestsPage() {
@@ -62,6 +65,7 @@ export function RequestsPage() {
   });
   const { data: totalCount, refetch: refetchCount } = useProxyRequestsCount();
   const { data: providers = [] } = useProviders();
+  const { data: deletedProviders = [] } = useDeletedProviders();
   const { data: projects = [] } = useProjects();
   const { data: apiTokens = [] } = useAPITokens();
 
@@ -71,8 +75,8 @@ export function RequestsPage() {
   const requests = data?.items ?? [];
   const hasMore = data?.hasMore ?? false;
 
-  // Create provider ID to name mapping
-  const providerMap = new Map(providers.map((p) => [p.id, p.name]));
+  // Create provider ID to name mapping (deleted providers keep their name in history)
+  const providerMap = new Map([...deletedProviders, ...providers].map((p) => [p.id, p.name]));
   // Create project ID to name mapping
   const projectMap = new Map(projects.map((p) => [p.id, p.name]));
   // Create API Token ID to name mapping
@@ -272,6 +276,12 @@ function RequestStatusBadge({ status }: { status: ProxyRequestStatus }) {
           label: t('requests.status.rejected'),
           icon: <Ban size={10} className="mr-1 flex-shrink-0" />,
         };
+      case 'INTERRUPTED':
+        return {
+          variant: 'warning' as const,
+          label: t('requests.status.interrupted'),
+          icon: <Ban size={10} className="mr-1 shrink-0" />,
+        };
     }
   };
 
@@ -351,6 +361,10 @@ function LogRow({
   const isFailed = request.status === 'FAILED';
   const [isRecent, setIsRecent] = useState(false);
 
+  // Live usage of running streams (tokens reported so far and the cost estimated from them)
+  const progress = useAttemptProgress(request.id, isPending);
+  const live = isPending && progress ? progress : null;
+
   // Live duration calculation for pending requests
   const [liveDuration, setLiveDuration] = useState<number | null>(null);
 
@@ -531,7 +545,7 @@ function LogRow({
 
       {/* Cost */}
       <TableCell className="py-1 text-right">
-        <CostCell cost={request.cost} />
+        <CostCell cost={live ? live.estimatedCost : request.cost} />
       </TableCell>
 
       {/* Attempts */}
@@ -549,22 +563,31 @@ function LogRow({
 
       {/* Input Tokens - sky blue */}
       <TableCell className="py-1 text-right">
-        <TokenCell count={request.inputTokenCount} color="text-sky-400" />
+        <TokenCell count={live ? live.inputTokens : request.inputTokenCount} color="text-sky-400" />
       </TableCell>
 
       {/* Output Tokens - emerald green */}
       <TableCell className="py-1 text-right">
-        <TokenCell count={request.outputTokenCount} color="text-emerald-400" />
+        <TokenCell
+          count={live ? live.outputTokens : request.outputTokenCount}
+          color="text-emerald-400"
+        />
       </TableCell>
 
       {/* Cache Read - violet */}
       <TableCell className="py-1 text-right">
-        <TokenCell count={request.cacheReadCount} color="text-violet-400" />
+        <TokenCell
+          count={live ? live.cacheReadCount : request.cacheReadCount}
+          color="text-violet-400"
+        />
       </TableCell>
 
       {/* Cache Write - amber */}
       <TableCell className="py-1 text-right">
-        <TokenCell count={request.cacheWriteCount} color="text-amber-400" />
+        <TokenCell
+          count={live ? live.cacheWriteCount : request.cacheWriteCount}
+          color="text-amber-400"
+        />
       </TableCell>
     </TableRow>
   );
@@ -1,8 +1,23 @@
 import { useState, useEffect } from 'react';
 import { useTranslation } from 'react-i18next';
 import { Button, Input } from '@/components/ui';
-import { useCreateRoute, useUpdateRoute, useProviders, useProjects } from '@/hooks/queries';
-import type { ClientType, Route } from '@/lib/transport';
+import { Textarea } from '@/components/ui/textarea';
+import {
+  useCreateRoute,
+  useUpdateRoute,
+  useProviders,
+  useProjects,
+  useRetryConfigs,
+  useEffectiveRetryConfig,
+  useExperimentStats,
+} from '@/hooks/queries';
+import type {
+  ClientType,
+  ExperimentArm,
+  Route,
+  SystemPromptMode,
+  ThoughtSignatureMode,
+} from '@/lib/transport';
 import { ModelMappingEditor } from '@/pages/providers/components/model-mapping-editor';
 
 interface RouteFormProps {
@@ -18,6 +33,8 @@ export function RouteForm({ route, onClose, isGlobal, projectId }: RouteFormProp
   const updateRoute = useUpdateRoute();
   const { data: providers } = useProviders();
   const { data: projects } = useProjects();
+  const { data: retryConfigs } = useRetryConfigs();
+  const { data: effectiveRetry } = useEffectiveRetryConfig(route?.id ?? 0);
   const isEditing = !!route;
 
   const [clientType, setClientType] = useState<ClientType>('openai');
@@ -25,7 +42,26 @@ export function RouteForm({ route, onClose, isGlobal, projectId }: RouteFormProp
   const [projectID, setProjectID] = useState(projectId !== undefined ? String(projectId) : '0');
   const [position, setPosition] = useState('1');
   const [isEnabled, setIsEnabled] = useState(true);
+  const [retryConfigID, setRetryConfigID] = useState('0');
   const [modelMapping, setModelMapping] = useState<Record<string, string>>({});
+  const [systemPromptMode, setSystemPromptMode] = useState<SystemPromptMode>('');
+  const [systemPromptMaxChars, setSystemPromptMaxChars] = useState('2000');
+  const [systemPromptReplacement, setSystemPromptReplacement] = useState('');
+  const [systemPromptRecordOriginal, setSystemPromptRecordOriginal] = useState(false);
+  const [thoughtSignatureMode, setThoughtSignatureMode] = useState<ThoughtSignatureMode>('');
+  const [pingIntervalSeconds, setPingIntervalSeconds] = useState('');
+  const [hideReasoning, setHideReasoning] = useState(false);
+  const [identityPatchMode, setIdentityPatchMode] = useState<'' | 'custom' | 'disabled'>('');
+  const [identityPatchTemplate, setIdentityPatchTemplate] = useState('');
+  const [experimentEnabled, setExperimentEnabled] = useState(false);
+  const [experimentName, setExperimentName] = useState('');
+  const [experimentSplit, setExperimentSplit] = useState('50');
+  const [experimentArmA, setExperimentArmA] = useState<ExperimentArm>({});
+  const [experimentArmB, setExperimentArmB] = useState<ExperimentArm>({});
+  const { data: experimentStats } = useExperimentStats(
+    { experiment: route?.experiment?.name },
+    !!route?.experiment?.name,
+  );
 
   useEffect(() => {
     if (route) {
@@ -34,7 +70,24 @@ export function RouteForm({ route, onClose, isGlobal, projectId }: RouteFormProp
       setProjectID(String(route.projectID));
       setPosition(String(route.position));
       setIsEnabled(route.isEnabled);
+      setRetryConfigID(String(route.retryConfigID));
       setModelMapping(route.modelMapping || {});
+      setSystemPromptMode(route.systemPrompt?.mode ?? '');
+      setSystemPromptMaxChars(String(route.systemPrompt?.maxChars || 2000));
+      setSystemPromptReplacement(route.systemPrompt?.replacement ?? '');
+      setSystemPromptRecordOriginal(route.systemPrompt?.recordOriginal ?? false);
+      setThoughtSignatureMode(route.thoughtSignatureMode ?? '');
+      setPingIntervalSeconds(route.pingIntervalSeconds ? String(route.pingIntervalSeconds) : '');
+      setHideReasoning(route.hideReasoning ?? false);
+      setIdentityPatchMode(
+        route.identityPatch?.disabled ? 'disabled' : route.identityPatch?.template ? 'custom' : '',
+      );
+      setIdentityPatchTemplate(route.identityPatch?.template ?? '');
+      setExperimentEnabled(route.experiment?.enabled ?? false);
+      setExperimentName(route.experiment?.name ?? '');
+      setExperimentSplit(String(route.experiment?.splitPercent ?? 50));
+      setExperimentArmA(route.experiment?.a ?? {});
+      setExperimentArmB(route.experiment?.b ?? {});
     }
   }, [route]);
 
@@ -57,8 +110,36 @@ export function RouteForm({ route, onClose, isGlobal, projectId }: RouteFormProp
       position: Number(position),
       isEnabled,
       isNative: route?.isNative ?? false, // 手动创建的 Route 默认为转换路由
-      retryConfigID: route?.retryConfigID ?? 0,
+      retryConfigID: Number(retryConfigID),
       modelMapping: Object.keys(modelMapping).length > 0 ? modelMapping : undefined,
+      systemPrompt: systemPromptMode
+        ? {
+            mode: systemPromptMode,
+            maxChars: systemPromptMode === 'truncate' ? Number(systemPromptMaxChars) : undefined,
+            replacement: systemPromptMode === 'replace' ? systemPromptReplacement : undefined,
+            recordOriginal: systemPromptRecordOriginal,
+          }
+        : undefined,
+      thoughtSignatureMode,
+      pingIntervalSeconds: Number(pingIntervalSeconds) || 0,
+      hideReasoning,
+      identityPatch:
+        identityPatchMode === 'disabled'
+          ? { disabled: true }
+          : identityPatchMode === 'custom' && identityPatchTemplate.trim()
+            ? { template: identityPatchTemplate }
+            : undefined,
+      // 关闭实验时保留配置，便于之后重新开启
+      experiment:
+        experimentEnabled || experimentName.trim()
+          ? {
+              enabled: experimentEnabled,
+              name: experimentName.trim(),
+              a: experimentArmA,
+              b: experimentArmB,
+              splitPercent: Number(experimentSplit),
+            }
+          : undefined,
     };
 
     if (isEditing) {
@@ -70,6 +151,7 @@ export function RouteForm({ route, onClose, isGlobal, projectId }: RouteFormProp
 
   const isPending = createRoute.isPending || updateRoute.isPending;
   const showProjectSelector = !isGlobal && projectId === undefined;
+  const isAntigravity = providers?.find((p) => String(p.id) === providerID)?.type === 'antigravity';
 
   return (
     <form onSubmit={handleSubmit} className="space-y-4">
@@ -135,6 +217,33 @@ export function RouteForm({ route, onClose, isGlobal, projectId }: RouteFormProp
         </div>
       </div>
 
+      {/* Retry config: route > provider > global default */}
+      <div>
+        <label className="mb-1 block text-sm font-medium">{t('routes.form.retryConfig')}</label>
+        <select
+          value={retryConfigID}
+          onChange={(e) => setRetryConfigID(e.target.value)}
+          className="flex h-9 w-full rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs transition-colors focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring disabled:cursor-not-allowed disabled:opacity-50"
+        >
+          <option value="0">{t('routes.form.retryConfigInherit')}</option>
+          {retryConfigs?.map((c) => (
+            <option key={c.id} value={c.id}>
+              {c.name}
+            </option>
+          ))}
+        </select>
+        {effectiveRetry && (
+          <p className="mt-1 text-xs text-text-secondary">
+            {effectiveRetry.config
+              ? t('routes.form.retryConfigEffective', {
+                  name: effectiveRetry.config.name,
+                  source: t(`routes.form.retryConfigSource.${effectiveRetry.source}`),
+                })
+              : t('routes.form.retryConfigNone')}
+          </p>
+        )}
+      </div>
+
       {/* Model Mapping (route-level override) */}
       <div>
         <label className="mb-1 block text-sm font-medium">{t('routes.form.modelMapping')}</label>
@@ -142,6 +251,228 @@ export function RouteForm({ route, onClose, isGlobal, projectId }: RouteFormProp
         <ModelMappingEditor value={modelMapping} onChange={setModelMapping} disabled={isPending} />
       </div>
 
+      {/* System prompt rewrite (applied before format conversion) */}
+      <div className="space-y-2">
+        <label className="mb-1 block text-sm font-medium">{t('routes.form.systemPrompt')}</label>
+        <p className="mb-2 text-xs text-text-secondary">{t('routes.form.systemPromptHelp')}</p>
+        <select
+          value={systemPromptMode}
+          onChange={(e) => setSystemPromptMode(e.target.value as SystemPromptMode)}
+          className="flex h-9 w-full rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs transition-colors focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring disabled:cursor-not-allowed disabled:opacity-50"
+        >
+          <option value="">{t('routes.form.systemPromptKeep')}</option>
+          <option value="strip">{t('routes.form.systemPromptStrip')}</option>
+          <option value="truncate">{t('routes.form.systemPromptTruncate')}</option>
+          <option value="hash">{t('routes.form.systemPromptHash')}</option>
+          <option value="replace">{t('routes.form.systemPromptReplace')}</option>
+        </select>
+        {systemPromptMode === 'truncate' && (
+          <Input
+            type="number"
+            value={systemPromptMaxChars}
+            onChange={(e) => setSystemPromptMaxChars(e.target.value)}
+            min="1"
+            placeholder={t('routes.form.systemPromptMaxChars')}
+          />
+        )}
+        {systemPromptMode === 'replace' && (
+          <Textarea
+            value={systemPromptReplacement}
+            onChange={(e) => setSystemPromptReplacement(e.target.value)}
+            rows={4}
+            placeholder={t('routes.form.systemPromptReplacement')}
+          />
+        )}
+        {systemPromptMode && (
+          <div className="flex items-center gap-2">
+            <input
+              type="checkbox"
+              id="systemPromptRecordOriginal"
+              checked={systemPromptRecordOriginal}
+              onChange={(e) => setSystemPromptRecordOriginal(e.target.checked)}
+              className="h-4 w-4 rounded border-gray-300"
+            />
+            <label htmlFor="systemPromptRecordOriginal" className="text-sm">
+              {t('routes.form.systemPromptRecordOriginal')}
+            </label>
+          </div>
+        )}
+      </div>
+
+      {/* Identity patch of Claude and OpenAI requests converted to Gemini */}
+      <div className="space-y-2">
+        <label className="mb-1 block text-sm font-medium">{t('routes.form.identityPatch')}</label>
+        <p className="mb-2 text-xs text-text-secondary">{t('routes.form.identityPatchHelp')}</p>
+        <select
+          value={identityPatchMode}
+          onChange={(e) => setIdentityPatchMode(e.target.value as '' | 'custom' | 'disabled')}
+          className="flex h-9 w-full rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs transition-colors focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring disabled:cursor-not-allowed disabled:opacity-50"
+        >
+          <option value="">{t('routes.form.identityPatchDefault')}</option>
+          <option value="custom">{t('routes.form.identityPatchCustom')}</option>
+          <option value="disabled">{t('routes.form.identityPatchDisabled')}</option>
+        </select>
+        {identityPatchMode === 'custom' && (
+          <Textarea
+            value={identityPatchTemplate}
+            onChange={(e) => setIdentityPatchTemplate(e.target.value)}
+            rows={5}
+            placeholder={`${t('routes.form.identityPatchTemplate')} {{model}} {{clientType}} {{provider}}`}
+          />
+        )}
+      </div>
+
+      {/* A/B experiment: split the route's traffic between two providers/models */}
+      <div className="space-y-2">
+        <div className="flex items-center gap-2">
+          <input
+            type="checkbox"
+            id="experimentEnabled"
+            checked={experimentEnabled}
+            onChange={(e) => setExperimentEnabled(e.target.checked)}
+            className="h-4 w-4 rounded border-gray-300"
+          />
+          <label htmlFor="experimentEnabled" className="text-sm font-medium">
+            {t('routes.form.experiment')}
+          </label>
+        </div>
+        <p className="text-xs text-text-secondary">{t('routes.form.experimentHelp')}</p>
+        {experimentEnabled && (
+          <>
+            <div className="grid gap-4 md:grid-cols-2">
+              <Input
+                value={experimentName}
+                onChange={(e) => setExperimentName(e.target.value)}
+                placeholder={t('routes.form.experimentName')}
+                required
+              />
+              <div className="flex items-center gap-2">
+                <Input
+                  type="number"
+                  value={experimentSplit}
+                  onChange={(e) => setExperimentSplit(e.target.value)}
+                  min="0"
+                  max="100"
+                />
+                <span className="whitespace-nowrap text-xs text-text-secondary">
+                  {t('routes.form.experimentSplit')}
+                </span>
+              </div>
+            </div>
+            {(
+              [
+                ['A', experimentArmA, setExperimentArmA],
+                ['B', experimentArmB, setExperimentArmB],
+              ] as const
+            ).map(([arm, value, setValue]) => (
+              <div key={arm} className="grid gap-4 md:grid-cols-2">
+                <select
+                  value={value.providerID ? String(value.providerID) : ''}
+                  onChange={(e) =>
+                    setValue({ ...value, providerID: Number(e.target.value) || undefined })
+                  }
+                  className="flex h-9 w-full rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs transition-colors focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring disabled:cursor-not-allowed disabled:opacity-50"
+                >
+                  <option value="">
+                    {t('routes.form.experimentArmProvider', { arm })}
+                  </option>
+                  {providers?.map((p) => (
+                    <option key={p.id} value={p.id}>
+                      {arm}: {p.name}
+                    </option>
+                  ))}
+                </select>
+                <Input
+                  value={value.model ?? ''}
+                  onChange={(e) => setValue({ ...value, model: e.target.value || undefined })}
+                  placeholder={t('routes.form.experimentArmModel', { arm })}
+                />
+              </div>
+            ))}
+          </>
+        )}
+        {experimentStats && experimentStats.length > 0 && (
+          <table className="w-full text-xs">
+            <thead className="text-text-secondary">
+              <tr>
+                <th className="text-left font-medium">{t('routes.form.experimentArm')}</th>
+                <th className="text-right font-medium">{t('routes.form.experimentRequests')}</th>
+                <th className="text-right font-medium">{t('routes.form.experimentErrorRate')}</th>
+                <th className="text-right font-medium">{t('routes.form.experimentLatency')}</th>
+                <th className="text-right font-medium">{t('routes.form.experimentAvgCost')}</th>
+              </tr>
+            </thead>
+            <tbody>
+              {experimentStats.map((s) => (
+                <tr key={s.arm}>
+                  <td>{s.arm}</td>
+                  <td className="text-right">{s.totalRequests}</td>
+                  <td className="text-right">{(s.errorRate * 100).toFixed(1)}%</td>
+                  <td className="text-right">{Math.round(s.avgDurationMs)} ms</td>
+                  <td className="text-right">${(s.avgCost / 1_000_000).toFixed(4)}</td>
+                </tr>
+              ))}
+            </tbody>
+          </table>
+        )}
+      </div>
+
+      {/* Thought signature handling of tool calls (Antigravity only) */}
+      {isAntigravity && (
+        <div className="space-y-2">
+          <label className="mb-1 block text-sm font-medium">
+            {t('routes.form.thoughtSignatureMode')}
+          </label>
+          <p className="mb-2 text-xs text-text-secondary">
+            {t('routes.form.thoughtSignatureModeHelp')}
+          </p>
+          <select
+            value={thoughtSignatureMode}
+            onChange={(e) => setThoughtSignatureMode(e.target.value as ThoughtSignatureMode)}
+            className="flex h-9 w-full rounded-md border border-input bg-transparent px-3 py-2 text-sm shadow-xs transition-colors focus-visible:outline-none focus-visible:ring-1 focus-visible:ring-ring disabled:cursor-not-allowed disabled:opacity-50"
+          >
+            <option value="">{t('routes.form.thoughtSignatureInherit')}</option>
+            <option value="auto">{t('providers.thoughtSignature.auto')}</option>
+            <option value="strict">{t('providers.thoughtSignature.strict')}</option>
+            <option value="always_skip">{t('providers.thoughtSignature.alwaysSkip')}</option>
+          </select>
+        </div>
+      )}
+
+      {/* Claude ping events while Gemini is silent (Antigravity only) */}
+      {isAntigravity && clientType === 'claude' && (
+        <div className="space-y-2">
+          <label className="mb-1 block text-sm font-medium">{t('routes.form.pingInterval')}</label>
+          <p className="mb-2 text-xs text-text-secondary">{t('routes.form.pingIntervalHelp')}</p>
+          <Input
+            type="number"
+            min={-1}
+            value={pingIntervalSeconds}
+            onChange={(e) => setPingIntervalSeconds(e.target.value)}
+            placeholder="15"
+          />
+        </div>
+      )}
+
+      {/* Gemini thoughts as OpenAI reasoning_content */}
+      {clientType === 'openai' && (
+        <div className="space-y-1">
+          <div className="flex items-center gap-2">
+            <input
+              type="checkbox"
+              id="hideReasoning"
+              checked={hideReasoning}
+              onChange={(e) => setHideReasoning(e.target.checked)}
+              className="h-4 w-4 rounded border-gray-300"
+            />
+            <label htmlFor="hideReasoning" className="text-sm font-medium">
+              {t('routes.form.hideReasoning')}
+            </label>
+          </div>
+          <p className="text-xs text-text-secondary">{t('routes.form.hideReasoningHelp')}</p>
+        </div>
+      )}
+
       <div className="flex items-center gap-2">
         <input
           type="checkbox"
@@ -0,0 +1,320 @@
+import { useState } from 'react';
+import {
+  Button,
+  Card,
+  CardContent,
+  CardHeader,
+  CardTitle,
+  Input,
+  Table,
+  TableBody,
+  TableCell,
+  TableHead,
+  TableHeader,
+  TableRow,
+  Badge,
+} from '@/components/ui';
+import {
+  useRoutingProfiles,
+  useActiveRoutingProfile,
+  useCreateRoutingProfile,
+  useUpdateRoutingProfile,
+  useDeleteRoutingProfile,
+  useApplyRoutingProfile,
+  useCaptureRoutingProfile,
+} from '@/hooks/queries';
+import { Plus, Trash2, Pencil, Play, Camera, X } from 'lucide-react';
+import { useTranslation } from 'react-i18next';
+import type { RoutingProfile, RoutingProfileSchedule } from '@/lib/transport';
+
+const WEEKDAYS = [0, 1, 2, 3, 4, 5, 6];
+
+export function RoutingProfilesPage() {
+  const { t } = useTranslation();
+  const { data: profiles, isLoading } = useRoutingProfiles();
+  const { data: active } = useActiveRoutingProfile();
+  const createProfile = useCreateRoutingProfile();
+  const updateProfile = useUpdateRoutingProfile();
+  const deleteProfile = useDeleteRoutingProfile();
+  const applyProfile = useApplyRoutingProfile();
+  const captureProfile = useCaptureRoutingProfile();
+  const [showForm, setShowForm] = useState(false);
+  const [editingProfile, setEditingProfile] = useState<RoutingProfile | undefined>();
+
+  const [name, setName] = useState('');
+  const [description, setDescription] = useState('');
+  const [schedules, setSchedules] = useState<RoutingProfileSchedule[]>([]);
+
+  const resetForm = () => {
+    setName('');
+    setDescription('');
+    setSchedules([]);
+  };
+
+  const handleEdit = (profile: RoutingProfile) => {
+    setEditingProfile(profile);
+    setName(profile.name);
+    setDescription(profile.description);
+    setSchedules(profile.schedules ?? []);
+    setShowForm(true);
+  };
+
+  const handleCloseForm = () => {
+    setShowForm(false);
+    setEditingProfile(undefined);
+    resetForm();
+  };
+
+  const handleSubmit = (e: React.FormEvent) => {
+    e.preventDefault();
+    const data = {
+      name,
+      description,
+      schedules,
+      routes: editingProfile?.routes ?? [],
+    };
+
+    if (editingProfile) {
+      updateProfile.mutate({ id: editingProfile.id, data }, { onSuccess: handleCloseForm });
+    } else {
+      createProfile.mutate(data, { onSuccess: handleCloseForm });
+    }
+  };
+
+  const handleDelete = (id: number) => {
+    if (confirm(t('routingProfiles.deleteConfirm'))) {
+      deleteProfile.mutate(id);
+    }
+  };
+
+  const handleCapture = (id: number) => {
+    if (confirm(t('routingProfiles.captureConfirm'))) {
+      captureProfile.mutate(id);
+    }
+  };
+
+  const updateSchedule = (index: number, patch: Partial<RoutingProfileSchedule>) => {
+    setSchedules(schedules.map((s, i) => (i === index ? { ...s, ...patch } : s)));
+  };
+
+  const toggleDay = (index: number, day: number) => {
+    const days = schedules[index].days ?? [];
+    const next = days.includes(day) ? days.filter((d) => d !== day) : [...days, day].sort();
+    updateSchedule(index, { days: next });
+  };
+
+  const formatSchedule = (s: RoutingProfileSchedule) => {
+    const days =
+      s.days && s.days.length > 0
+        ? s.days.map((d) => t(`routingProfiles.weekdays.${d}`)).join(' ')
+        : t('routingProfiles.everyDay');
+    return `${days} ${s.start}-${s.end}`;
+  };
+
+  const isPending = createProfile.isPending || updateProfile.isPending;
+  const errorMessage = (createProfile.error ?? updateProfile.error)?.message;
+
+  return (
+    <div className="space-y-6">
+      <div className="flex items-center justify-between">
+        <div>
+          <h2 className="text-2xl font-bold">{t('routingProfiles.title')}</h2>
+          <p className="text-sm text-muted-foreground">{t('routingProfiles.description')}</p>
+        </div>
+        <Button onClick={() => setShowForm(true)}>
+          <Plus className="mr-2 h-4 w-4" />
+          {t('routingProfiles.addProfile')}
+        </Button>
+      </div>
+
+      {showForm && (
+        <Card>
+          <CardHeader>
+            <CardTitle>
+              {editingProfile
+                ? t('routingProfiles.editProfile')
+                : t('routingProfiles.newProfile')}
+            </CardTitle>
+          </CardHeader>
+          <CardContent>
+            <form onSubmit={handleSubmit} className="space-y-4">
+              <div className="grid gap-4 md:grid-cols-2">
+                <div>
+                  <label className="mb-1 block text-sm font-medium">
+                    {t('routingProfiles.name')}
+                  </label>
+                  <Input value={name} onChange={(e) => setName(e.target.value)} required />
+                </div>
+                <div>
+                  <label className="mb-1 block text-sm font-medium">
+                    {t('routingProfiles.profileDescription')}
+                  </label>
+                  <Input value={description} onChange={(e) => setDescription(e.target.value)} />
+                </div>
+              </div>
+
+              <div className="space-y-2">
+                <div className="flex items-center justify-between">
+                  <label className="block text-sm font-medium">
+                    {t('routingProfiles.schedules')}
+                  </label>
+                  <Button
+                    type="button"
+                    variant="outline"
+                    size="sm"
+                    onClick={() => setSchedules([...schedules, { start: '09:00', end: '18:00' }])}
+                  >
+                    <Plus className="mr-1 h-3 w-3" />
+                    {t('routingProfiles.addSchedule')}
+                  </Button>
+                </div>
+                <p className="text-xs text-muted-foreground">{t('routingProfiles.schedulesHint')}</p>
+                {schedules.map((s, index) => (
+                  <div key={index} className="flex flex-wrap items-center gap-2">
+                    {WEEKDAYS.map((day) => (
+                      <Button
+                        key={day}
+                        type="button"
+                        size="sm"
+                        variant={s.days?.includes(day) ? 'default' : 'outline'}
+                        onClick={() => toggleDay(index, day)}
+                      >
+                        {t(`routingProfiles.weekdays.${day}`)}
+                      </Button>
+                    ))}
+                    <Input
+                      type="time"
+                      className="w-32"
+                      value={s.start}
+                      onChange={(e) => updateSchedule(index, { start: e.target.value })}
+                    />
+                    <span>-</span>
+                    <Input
+                      type="time"
+                      className="w-32"
+                      value={s.end}
+                      onChange={(e) => updateSchedule(index, { end: e.target.value })}
+                    />
+                    <Button
+                      type="button"
+                      variant="ghost"
+                      size="sm"
+                      onClick={() => setSchedules(schedules.filter((_, i) => i !== index))}
+                    >
+                      <X className="h-4 w-4" />
+                    </Button>
+                  </div>
+                ))}
+              </div>
+
+              {errorMessage && <p className="text-sm text-red-500">{errorMessage}</p>}
+
+              <div className="flex justify-end gap-2">
+                <Button type="button" variant="outline" onClick={handleCloseForm}>
+                  {t('common.cancel')}
+                </Button>
+                <Button type="submit" disabled={isPending}>
+                  {isPending
+                    ? t('common.saving')
+                    : editingProfile
+                      ? t('routes.update')
+                      : t('routes.create')}
+                </Button>
+              </div>
+            </form>
+          </CardContent>
+        </Card>
+      )}
+
+      <Card>
+        <CardHeader>
+          <CardTitle>{t('routingProfiles.allProfiles')}</CardTitle>
+        </CardHeader>
+        <CardContent>
+          {isLoading ? (
+            <p className="text-gray-500">{t('common.loading')}</p>
+          ) : (
+            <Table>
+              <TableHeader>
+                <TableRow>
+                  <TableHead>{t('routingProfiles.name')}</TableHead>
+                  <TableHead>{t('routingProfiles.routes')}</TableHead>
+                  <TableHead>{t('routingProfiles.schedules')}</TableHead>
+                  <TableHead>{t('common.actions')}</TableHead>
+                </TableRow>
+              </TableHeader>
+              <TableBody>
+                {profiles?.map((profile) => (
+                  <TableRow key={profile.id}>
+                    <TableCell>
+                      <div className="flex items-center gap-2">
+                        <span className="font-medium">{profile.name}</span>
+                        {active?.profileID === profile.id && (
+                          <Badge variant="success">{t('routingProfiles.active')}</Badge>
+                        )}
+                        {active?.scheduledProfileID === profile.id && (
+                          <Badge variant="info">{t('routingProfiles.scheduled')}</Badge>
+                        )}
+                      </div>
+                      {profile.description && (
+                        <div className="text-xs text-muted-foreground">{profile.description}</div>
+                      )}
+                    </TableCell>
+                    <TableCell>{profile.routes?.length ?? 0}</TableCell>
+                    <TableCell className="text-sm">
+                      {profile.schedules && profile.schedules.length > 0 ? (
+                        profile.schedules.map((s, i) => <div key={i}>{formatSchedule(s)}</div>)
+                      ) : (
+                        <span className="text-gray-400">{t('routingProfiles.manualOnly')}</span>
+                      )}
+                    </TableCell>
+                    <TableCell>
+                      <div className="flex gap-1">
+                        <Button
+                          variant="ghost"
+                          size="sm"
+                          title={t('routingProfiles.apply')}
+                          onClick={() => applyProfile.mutate(profile.id)}
+                          disabled={applyProfile.isPending || !profile.routes?.length}
+                        >
+                          <Play className="h-4 w-4" />
+                        </Button>
+                        <Button
+                          variant="ghost"
+                          size="sm"
+                          title={t('routingProfiles.capture')}
+                          onClick={() => handleCapture(profile.id)}
+                          disabled={captureProfile.isPending}
+                        >
+                          <Camera className="h-4 w-4" />
+                        </Button>
+                        <Button variant="ghost" size="sm" onClick={() => handleEdit(profile)}>
+                          <Pencil className="h-4 w-4" />
+                        </Button>
+                        <Button
+                          variant="ghost"
+                          size="sm"
+                          onClick={() => handleDelete(profile.id)}
+                          disabled={deleteProfile.isPending}
+                        >
+                          <Trash2 className="h-4 w-4 text-red-500" />
+                        </Button>
+                      </div>
+                    </TableCell>
+                  </TableRow>
+                ))}
+                {(!profiles || profiles.length === 0) && (
+                  <TableRow>
+                    <TableCell colSpan={4} className="text-center text-gray-500">
+                      {t('routingProfiles.noProfiles')}
+                    </TableCell>
+                  </TableRow>
+                )}
+              </TableBody>
+            </Table>
+          )}
+        </CardContent>
+      </Card>
+    </div>
+  );
+}
@@ -12,6 +12,7 @@ import {
   TableHeader,
   TableRow,
   Badge,
+  Input,
 } from '@/components/ui';
 import {
   useRoutingStrategies,
@@ -36,16 +37,22 @@ export function RoutingStrategiesPage() {
 
   const [projectID, setProjectID] = useState('0');
   const [type, setType] = useState<RoutingStrategyType>('priority');
+  const [expectedOutputTokens, setExpectedOutputTokens] = useState('');
+  const [maxLatencyMs, setMaxLatencyMs] = useState('');
 
   const resetForm = () => {
     setProjectID('0');
     setType('priority');
+    setExpectedOutputTokens('');
+    setMaxLatencyMs('');
   };
 
   const handleEdit = (strategy: RoutingStrategy) => {
     setEditingStrategy(strategy);
     setProjectID(String(strategy.projectID));
     setType(strategy.type);
+    setExpectedOutputTokens(strategy.config?.expectedOutputTokens ? String(strategy.config.expectedOutputTokens) : '');
+    setMaxLatencyMs(strategy.config?.maxLatencyMs ? String(strategy.config.maxLatencyMs) : '');
     setShowForm(true);
   };
 
@@ -60,7 +67,14 @@ export function RoutingStrategiesPage() {
     const data = {
       projectID: Number(projectID),
       type,
-      config: null,
+      config:
+        type === 'cost_aware'
+          ? {
+              ...editingStrategy?.config,
+              expectedOutputTokens: Number(expectedOutputTokens) || undefined,
+              maxLatencyMs: Number(maxLatencyMs) || undefined,
+            }
+          : (editingStrategy?.config ?? null),
     };
 
     if (editingStrategy) {
@@ -127,8 +141,38 @@ export function RoutingStrategiesPage() {
                   >
                     <option value="priority">Priority (by position)</option>
                     <option value="weighted_random">Weighted Random</option>
+                    <option value="quota_aware">Quota Aware (most quota left first)</option>
+                    <option value="cost_aware">Cost Aware (cheapest mapped model first)</option>
                   </select>
                 </div>
+                {type === 'cost_aware' && (
+                  <>
+                    <div>
+                      <label className="mb-1 block text-sm font-medium">
+                        {t('routingStrategies.expectedOutputTokens')}
+                      </label>
+                      <Input
+                        type="number"
+                        min={0}
+                        value={expectedOutputTokens}
+                        onChange={(e) => setExpectedOutputTokens(e.target.value)}
+                        placeholder="1000"
+                      />
+                    </div>
+                    <div>
+                      <label className="mb-1 block text-sm font-medium">
+                        {t('routingStrategies.maxLatencyMs')}
+                      </label>
+                      <Input
+                        type="number"
+                        min={0}
+                        value={maxLatencyMs}
+                        onChange={(e) => setMaxLatencyMs(e.target.value)}
+                        placeholder={t('routingStrategies.noLimit')}
+                      />
+                    </div>
+                  </>
+                )}
               </div>
               <div className="flex justify-end gap-2">
                 <Button type="button" variant="outline" onClick={handleCloseForm}>
@@ -177,7 +221,11 @@ export function RoutingStrategiesPage() {
                       <Badge variant={strategy.type === 'priority' ? 'info' : 'warning'}>
                         {strategy.type === 'priority'
                           ? t('routingStrategies.priority')
-                          : t('routingStrategies.weightedRandom')}
+                          : strategy.type === 'quota_aware'
+                            ? t('routingStrategies.quotaAware')
+                            : strategy.type === 'cost_aware'
+                              ? t('routingStrategies.costAware')
+                              : t('routingStrategies.weightedRandom')}
                       </Badge>
                     </TableCell>
                     <TableCell>
@@ -13,7 +13,17 @@ import {
   TableRow,
 } from '@/components/ui';
 import { Dialog, DialogContent } from '@/components/ui/dialog';
-import { useSessions, useProjects, useUpdateSessionProject } from '@/hooks/queries';
+import {
+  useSessions,
+  useProjects,
+  useProviders,
+  useRoutes,
+  useUpdateSessionProject,
+  usePinSession,
+  usePurgeExpiredSessions,
+  useSessionExpiryUpdates,
+  useSettings,
+} from '@/hooks/queries';
 import {
   LayoutDashboard,
   Loader2,
@@ -23,6 +33,9 @@ import {
   Check,
   AlertCircle,
   FolderOpen,
+  Pin,
+  Server,
+  Eraser,
 } from 'lucide-react';
 import type { Session } from '@/lib/transport';
 import { cn } from '@/lib/utils';
@@ -32,7 +45,19 @@ export function SessionsPage() {
   const { t } = useTranslation();
   const { data: sessions, isLoading } = useSessions();
   const { data: projects } = useProjects();
+  const { data: settings } = useSettings();
+  const purgeExpired = usePurgeExpiredSessions();
   const [selectedSession, setSelectedSession] = useState<Session | null>(null);
+  useSessionExpiryUpdates();
+
+  // 空闲时长为 0 表示未开启空闲清理
+  const idleHours = parseInt(settings?.session_idle_hours || '0', 10) || 0;
+
+  const handlePurge = () => {
+    if (confirm(t('sessions.purgeExpiredConfirm', { hours: idleHours }))) {
+      purgeExpired.mutate(undefined);
+    }
+  };
 
   // Create project ID to name mapping
   const projectMap = new Map(projects?.map((p) => [p.id, p.name]) ?? []);
@@ -54,6 +79,25 @@ export function SessionsPage() {
             </p>
           </div>
         </div>
+        {idleHours > 0 && (
+          <div className="flex items-center gap-3">
+            {purgeExpired.data && (
+              <span className="text-xs text-text-secondary">
+                {t('sessions.purgedCount', { count: purgeExpired.data.sessionIDs.length })}
+              </span>
+            )}
+            <Button
+              variant="outline"
+              size="sm"
+              onClick={handlePurge}
+              disabled={purgeExpired.isPending}
+              title={t('sessions.purgeExpiredHint', { hours: idleHours })}
+            >
+              <Eraser className="mr-2 h-4 w-4" />
+              {t('sessions.purgeExpired')}
+            </Button>
+          </div>
+        )}
       </div>
 
       <div className="flex-1 overflow-auto p-6">
@@ -74,6 +118,9 @@ export function SessionsPage() {
                     <TableHead className="w-[150px] text-text-secondary">
                       {t('sessions.project')}
                     </TableHead>
+                    <TableHead className="w-[180px] text-right text-text-secondary">
+                      {t('sessions.lastActive')}
+                    </TableHead>
                     <TableHead className="w-[180px] text-right text-text-secondary">
                       {t('common.created')}
                     </TableHead>
@@ -94,9 +141,14 @@ export function SessionsPage() {
                         </div>
                       </TableCell>
                       <TableCell className="font-mono text-xs text-foreground">
-                        <span className="truncate max-w-[300px] block" title={session.sessionID}>
-                          {session.sessionID}
-                        </span>
+                        <div className="flex items-center gap-1.5">
+                          <span className="truncate max-w-[300px] block" title={session.sessionID}>
+                            {session.sessionID}
+                          </span>
+                          {(session.pinnedRouteID > 0 || session.pinnedProviderID > 0) && (
+                            <Pin size={12} className="text-accent shrink-0" />
+                          )}
+                        </div>
                       </TableCell>
                       <TableCell>
                         {session.projectID === 0 ? (
@@ -109,6 +161,9 @@ export function SessionsPage() {
                           </Badge>
                         )}
                       </TableCell>
+                      <TableCell className="text-right text-xs text-muted-foreground font-mono">
+                        {new Date(session.lastActiveAt ?? session.createdAt).toLocaleString()}
+                      </TableCell>
                       <TableCell className="text-right text-xs text-muted-foreground font-mono">
                         {new Date(session.createdAt).toLocaleString()}
                       </TableCell>
@@ -116,7 +171,7 @@ export function SessionsPage() {
                   ))}
                   {(!sessions || sessions.length === 0) && (
                     <TableRow>
-                      <TableCell colSpan={4} className="h-32 text-center text-muted-foreground">
+                      <TableCell colSpan={5} className="h-32 text-center text-muted-foreground">
                         <div className="flex flex-col items-center justify-center gap-2">
                           <Calendar className="h-8 w-8 opacity-20" />
                           <p>{t('sessions.noSessions')}</p>
@@ -150,30 +205,55 @@ interface SessionDetailModalProps {
 function SessionDetailModal({ session, projects, onClose }: SessionDetailModalProps) {
   const { t } = useTranslation();
   const [selectedProjectId, setSelectedProjectId] = useState<number>(0);
+  const [selectedProviderId, setSelectedProviderId] = useState<number>(0);
   const updateSessionProject = useUpdateSessionProject();
+  const pinSession = usePinSession();
+  const { data: providers } = useProviders();
+  const { data: routes } = useRoutes();
 
-  // Reset selected project when session changes
+  // Reset selections when session changes
   useEffect(() => {
     if (session) {
       setSelectedProjectId(session.projectID);
+      setSelectedProviderId(session.pinnedProviderID);
     }
   }, [session]);
 
+  // Only providers with an enabled route for this client type can serve the session
+  const pinnableProviders = session
+    ? (providers ?? []).filter((p) =>
+        (routes ?? []).some(
+          (r) => r.providerID === p.id && r.isEnabled && r.clientType === session.clientType,
+        ),
+      )
+    : [];
+
+  const projectChanged = session ? selectedProjectId !== session.projectID : false;
+  const pinChanged = session ? selectedProviderId !== session.pinnedProviderID : false;
+  const hasChanges = projectChanged || pinChanged;
+  const isSaving = updateSessionProject.isPending || pinSession.isPending;
+
   const handleSave = async () => {
     if (!session) return;
     try {
-      await updateSessionProject.mutateAsync({
-        sessionID: session.sessionID,
-        projectID: selectedProjectId,
-      });
+      if (projectChanged) {
+        await updateSessionProject.mutateAsync({
+          sessionID: session.sessionID,
+          projectID: selectedProjectId,
+        });
+      }
+      if (pinChanged) {
+        await pinSession.mutateAsync({
+          sessionID: session.sessionID,
+          providerID: selectedProviderId,
+        });
+      }
       onClose();
     } catch (error) {
-      console.error('Failed to update session project:', error);
+      console.error('Failed to update session:', error);
     }
   };
 
-  const hasChanges = session ? selectedProjectId !== session.projectID : false;
-
   if (!session) return null;
 
   return (
@@ -268,6 +348,45 @@ function SessionDetailModal({ session, projects, onClose }: SessionDetailModalPr
             <p className="text-[10px] text-text-muted mt-2">{t('sessions.projectBindingHint')}</p>
           </div>
 
+          {/* Provider Pinning */}
+          <div>
+            <label className="text-xs font-medium text-text-secondary uppercase tracking-wider flex items-center gap-2 mb-2">
+              <Pin size={12} /> {t('sessions.pinnedProvider')}
+            </label>
+            <div className="flex flex-wrap gap-2">
+              <button
+                type="button"
+                onClick={() => setSelectedProviderId(0)}
+                className={cn(
+                  'flex items-center gap-2 px-3 py-2 rounded-lg border text-sm font-medium transition-all',
+                  selectedProviderId === 0
+                    ? 'border-primary bg-primary text-primary-foreground shadow-lg shadow-primary/25'
+                    : 'border-border bg-muted text-text-secondary hover:bg-accent',
+                )}
+              >
+                <X size={14} />
+                <span>{t('sessions.notPinned')}</span>
+              </button>
+              {pinnableProviders.map((provider) => (
+                <button
+                  key={provider.id}
+                  type="button"
+                  onClick={() => setSelectedProviderId(provider.id)}
+                  className={cn(
+                    'flex items-center gap-2 px-3 py-2 rounded-lg border text-sm font-medium transition-all',
+                    selectedProviderId === provider.id
+                      ? 'border-primary bg-primary text-primary-foreground shadow-lg shadow-primary/25'
+                      : 'border-border bg-muted text-text-primary hover:bg-accent',
+                  )}
+                >
+                  <Server size={14} />
+                  <span>{provider.name}</span>
+                </button>
+              ))}
+            </div>
+            <p className="text-[10px] text-text-muted mt-2">{t('sessions.pinnedProviderHint')}</p>
+          </div>
+
           {/* Update result info */}
           {updateSessionProject.isSuccess && updateSessionProject.data && (
             <div className="flex items-center gap-2 text-xs text-emerald-400 bg-emerald-400/10 px-3 py-2 rounded-md">
@@ -280,7 +399,7 @@ function SessionDetailModal({ session, projects, onClose }: SessionDetailModalPr
             </div>
           )}
 
-          {updateSessionProject.isError && (
+          {(updateSessionProject.isError || pinSession.isError) && (
             <div className="flex items-center gap-2 text-xs text-red-400 bg-red-400/10 px-3 py-2 rounded-md">
               <AlertCircle size={14} />
               <span>{t('sessions.updateFailed')}</span>
@@ -295,10 +414,10 @@ function SessionDetailModal({ session, projects, onClose }: SessionDetailModalPr
           </Button>
           <Button
             onClick={handleSave}
-            disabled={!hasChanges || updateSessionProject.isPending}
+            disabled={!hasChanges || isSaving}
             className={cn('min-w-[100px]', hasChanges && 'bg-accent hover:bg-accent-hover')}
           >
-            {updateSessionProject.isPending ? (
+            {isSaving ? (
               <Loader2 size={14} className="animate-spin" />
             ) : (
               t('common.save')
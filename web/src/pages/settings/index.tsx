@@ -1,8 +1,25 @@
 import { useState, useEffect } from 'react';
-import { Settings, Moon, Sun, Monitor, Laptop, FolderOpen, Database } from 'lucide-react';
+import {
+  Settings,
+  Moon,
+  Sun,
+  Monitor,
+  Laptop,
+  FolderOpen,
+  Database,
+  Zap,
+  GitBranch,
+  Ruler,
+  ScrollText,
+  Feather,
+  Repeat,
+  Activity,
+  Hourglass,
+} from 'lucide-react';
 import { useTranslation } from 'react-i18next';
 import { useTheme } from '@/components/theme-provider';
 import { Card, CardContent, CardHeader, CardTitle, Button, Input, Switch } from '@/components/ui';
+import { Textarea } from '@/components/ui/textarea';
 import { PageHeader } from '@/components/layout/page-header';
 import { useSettings, useUpdateSetting } from '@/hooks/queries';
 
@@ -24,7 +41,15 @@ export function SettingsPage() {
         <div className="space-y-6">
           <GeneralSection />
           <DataRetentionSection />
+          <StreamingSection />
+          <ModelFallbackSection />
+          <ContextGuardSection />
+          <AgentLoopSection />
+          <HistorySummarySection />
+          <BackgroundRoutingSection />
           <ForceProjectSection />
+          <SessionExpirySection />
+          <StatusPageSection />
         </div>
       </div>
     </div>
@@ -34,6 +59,13 @@ export function SettingsPage() {
 function GeneralSection() {
   const { theme, setTheme } = useTheme();
   const { t, i18n } = useTranslation();
+  const updateSetting = useUpdateSetting();
+
+  // 语言同时保存到设置中，返回给客户端的错误信息和 OAuth 页面也使用该语言
+  const handleLanguageChange = (value: string) => {
+    i18n.changeLanguage(value);
+    updateSetting.mutate({ key: 'language', value });
+  };
 
   const themes: { value: Theme; label: string; icon: typeof Sun }[] = [
     { value: 'light', label: t('settings.theme.light'), icon: Sun },
@@ -76,16 +108,22 @@ function GeneralSection() {
           <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
             {t('settings.languagePreference')}
           </label>
-          <div className="flex flex-wrap gap-3">
-            {languages.map(({ value, label }) => (
-              <Button
-                key={value}
-                onClick={() => i18n.changeLanguage(value)}
-                variant={i18n.language === value ? 'default' : 'outline'}
-              >
-                <span className="text-sm font-medium">{label}</span>
-              </Button>
-            ))}
+          <div>
+            <div className="flex flex-wrap gap-3">
+              {languages.map(({ value, label }) => (
+                <Button
+                  key={value}
+                  onClick={() => handleLanguageChange(value)}
+                  variant={i18n.language === value ? 'default' : 'outline'}
+                  disabled={updateSetting.isPending}
+                >
+                  <span className="text-sm font-medium">{label}</span>
+                </Button>
+              ))}
+            </div>
+            <p className="text-xs text-muted-foreground mt-2">
+              {t('settings.languagePreferenceDesc')}
+            </p>
           </div>
         </div>
       </CardContent>
@@ -167,75 +205,872 @@ function DataRetentionSection() {
   );
 }
 
-function ForceProjectSection() {
+function StreamingSection() {
   const { data: settings, isLoading } = useSettings();
   const updateSetting = useUpdateSetting();
   const { t } = useTranslation();
 
-  const forceProjectEnabled = settings?.force_project_binding === 'true';
-  const timeout = settings?.force_project_timeout || '30';
+  const readBufferKB = settings?.stream_read_buffer_kb ?? '32';
+  const flushIntervalMs = settings?.stream_flush_interval_ms ?? '0';
+  const passthroughEnabled = settings?.stream_passthrough === 'true';
 
-  const handleToggle = async (checked: boolean) => {
+  const [bufferDraft, setBufferDraft] = useState('');
+  const [intervalDraft, setIntervalDraft] = useState('');
+  const [initialized, setInitialized] = useState(false);
+
+  useEffect(() => {
+    if (!isLoading) {
+      setBufferDraft(readBufferKB);
+      setIntervalDraft(flushIntervalMs);
+      setInitialized(true);
+    }
+  }, [isLoading, readBufferKB, flushIntervalMs]);
+
+  const hasChanges =
+    initialized && (bufferDraft !== readBufferKB || intervalDraft !== flushIntervalMs);
+
+  const handleSave = async () => {
+    const bufferNum = parseInt(bufferDraft, 10);
+    if (!isNaN(bufferNum) && bufferNum > 0 && bufferDraft !== readBufferKB) {
+      await updateSetting.mutateAsync({ key: 'stream_read_buffer_kb', value: bufferDraft });
+    }
+    const intervalNum = parseInt(intervalDraft, 10);
+    if (!isNaN(intervalNum) && intervalNum >= 0 && intervalDraft !== flushIntervalMs) {
+      await updateSetting.mutateAsync({ key: 'stream_flush_interval_ms', value: intervalDraft });
+    }
+  };
+
+  const handlePassthroughToggle = async (checked: boolean) => {
     await updateSetting.mutateAsync({
-      key: 'force_project_binding',
+      key: 'stream_passthrough',
       value: checked ? 'true' : 'false',
     });
   };
 
-  const handleTimeoutChange = async (value: string) => {
-    const numValue = parseInt(value, 10);
-    if (numValue >= 5 && numValue <= 300) {
-      await updateSetting.mutateAsync({
-        key: 'force_project_timeout',
-        value: value,
-      });
-    }
-  };
-
-  if (isLoading) return null;
+  if (isLoading || !initialized) return null;
 
   return (
     <Card className="border-border bg-card">
       <CardHeader className="border-b border-border py-4">
-        <CardTitle className="text-base font-medium flex items-center gap-2">
-          <FolderOpen className="h-4 w-4 text-muted-foreground" />
-          {t('settings.forceProjectBinding')}
-        </CardTitle>
+        <div className="flex items-center justify-between">
+          <div>
+            <CardTitle className="text-base font-medium flex items-center gap-2">
+              <Zap className="h-4 w-4 text-muted-foreground" />
+              {t('settings.streaming')}
+            </CardTitle>
+            <p className="text-xs text-muted-foreground mt-1">{t('settings.streamHint')}</p>
+          </div>
+          <Button onClick={handleSave} disabled={!hasChanges || updateSetting.isPending} size="sm">
+            {updateSetting.isPending ? t('common.saving') : t('common.save')}
+          </Button>
+        </div>
       </CardHeader>
       <CardContent className="p-6 space-y-4">
-        <div className="flex items-center justify-between">
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-32 shrink-0">
+            {t('settings.streamReadBuffer')}
+          </label>
+          <Input
+            type="number"
+            value={bufferDraft}
+            onChange={(e) => setBufferDraft(e.target.value)}
+            className="w-24"
+            min={4}
+            disabled={updateSetting.isPending}
+          />
+          <span className="text-xs text-muted-foreground">KB</span>
+        </div>
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-32 shrink-0">
+            {t('settings.streamFlushInterval')}
+          </label>
+          <Input
+            type="number"
+            value={intervalDraft}
+            onChange={(e) => setIntervalDraft(e.target.value)}
+            className="w-24"
+            min={0}
+            disabled={updateSetting.isPending}
+          />
+          <span className="text-xs text-muted-foreground">ms</span>
+        </div>
+        <div className="flex items-center justify-between pt-4 border-t border-border">
           <div>
             <label className="text-sm font-medium text-foreground">
-              {t('settings.enableForceProjectBinding')}
+              {t('settings.streamPassthrough')}
             </label>
             <p className="text-xs text-muted-foreground mt-1">
-              {t('settings.forceProjectBindingDesc')}
+              {t('settings.streamPassthroughDesc')}
             </p>
           </div>
           <Switch
-            checked={forceProjectEnabled}
-            onCheckedChange={handleToggle}
+            checked={passthroughEnabled}
+            onCheckedChange={handlePassthroughToggle}
             disabled={updateSetting.isPending}
           />
         </div>
+      </CardContent>
+    </Card>
+  );
+}
 
-        {forceProjectEnabled && (
-          <div className="flex items-center gap-6 pt-4 border-t border-border">
-            <label className="text-sm font-medium text-muted-foreground w-32 shrink-0">
-              {t('settings.waitTimeout')}
-            </label>
-            <Input
-              type="number"
-              value={timeout}
-              onChange={(e) => handleTimeoutChange(e.target.value)}
-              className="w-24"
-              min={5}
-              max={300}
-              disabled={updateSetting.isPending}
-            />
-            <span className="text-xs text-muted-foreground">{t('settings.waitTimeoutRange')}</span>
+function ModelFallbackSection() {
+  const { data: settings, isLoading } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const { t } = useTranslation();
+
+  const chains = settings?.model_fallback_chains ?? '';
+
+  const [draft, setDraft] = useState('');
+  const [initialized, setInitialized] = useState(false);
+
+  useEffect(() => {
+    if (!isLoading && !initialized) {
+      setDraft(chains);
+      setInitialized(true);
+    }
+  }, [isLoading, initialized, chains]);
+
+  useEffect(() => {
+    if (initialized) {
+      setDraft(chains);
+    }
+  }, [chains, initialized]);
+
+  const hasChanges = initialized && draft !== chains;
+
+  const handleSave = async () => {
+    await updateSetting.mutateAsync({ key: 'model_fallback_chains', value: draft });
+  };
+
+  if (isLoading || !initialized) return null;
+
+  return (
+    <Card className="border-border bg-card">
+      <CardHeader className="border-b border-border py-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <CardTitle className="text-base font-medium flex items-center gap-2">
+              <GitBranch className="h-4 w-4 text-muted-foreground" />
+              {t('settings.modelFallback')}
+            </CardTitle>
+            <p className="text-xs text-muted-foreground mt-1">{t('settings.modelFallbackHint')}</p>
           </div>
-        )}
+          <Button onClick={handleSave} disabled={!hasChanges || updateSetting.isPending} size="sm">
+            {updateSetting.isPending ? t('common.saving') : t('common.save')}
+          </Button>
+        </div>
+      </CardHeader>
+      <CardContent className="p-6">
+        <Textarea
+          value={draft}
+          onChange={(e) => setDraft(e.target.value)}
+          placeholder="gemini-3-pro -> gemini-2.5-pro -> claude-sonnet-4"
+          className="font-mono text-xs min-h-[96px]"
+          disabled={updateSetting.isPending}
+        />
+      </CardContent>
+    </Card>
+  );
+}
+
+function ContextGuardSection() {
+  const { data: settings, isLoading } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const { t } = useTranslation();
+
+  const mode = settings?.context_guard_mode ?? '';
+  const windows = settings?.model_context_windows ?? '';
+
+  const [windowsDraft, setWindowsDraft] = useState('');
+  const [initialized, setInitialized] = useState(false);
+
+  useEffect(() => {
+    if (!isLoading && !initialized) {
+      setWindowsDraft(windows);
+      setInitialized(true);
+    }
+  }, [isLoading, initialized, windows]);
+
+  useEffect(() => {
+    if (initialized) {
+      setWindowsDraft(windows);
+    }
+  }, [windows, initialized]);
+
+  const modes = [
+    { value: '', label: t('settings.contextGuardModes.off') },
+    { value: 'reject', label: t('settings.contextGuardModes.reject') },
+    { value: 'drop_oldest', label: t('settings.contextGuardModes.dropOldest') },
+  ];
+
+  const hasChanges = initialized && windowsDraft !== windows;
+
+  const handleModeChange = async (value: string) => {
+    await updateSetting.mutateAsync({ key: 'context_guard_mode', value });
+  };
+
+  const handleSave = async () => {
+    await updateSetting.mutateAsync({ key: 'model_context_windows', value: windowsDraft });
+  };
+
+  if (isLoading || !initialized) return null;
+
+  return (
+    <Card className="border-border bg-card">
+      <CardHeader className="border-b border-border py-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <CardTitle className="text-base font-medium flex items-center gap-2">
+              <Ruler className="h-4 w-4 text-muted-foreground" />
+              {t('settings.contextGuard')}
+            </CardTitle>
+            <p className="text-xs text-muted-foreground mt-1">{t('settings.contextGuardHint')}</p>
+          </div>
+          <Button onClick={handleSave} disabled={!hasChanges || updateSetting.isPending} size="sm">
+            {updateSetting.isPending ? t('common.saving') : t('common.save')}
+          </Button>
+        </div>
+      </CardHeader>
+      <CardContent className="p-6 space-y-4">
+        <div className="flex items-center gap-6">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.contextGuardMode')}
+          </label>
+          <div className="flex flex-wrap gap-3">
+            {modes.map(({ value, label }) => (
+              <Button
+                key={value}
+                onClick={() => handleModeChange(value)}
+                variant={mode === value ? 'default' : 'outline'}
+                disabled={updateSetting.isPending}
+              >
+                <span className="text-sm font-medium">{label}</span>
+              </Button>
+            ))}
+          </div>
+        </div>
+        <div className="space-y-2">
+          <label className="text-sm font-medium text-muted-foreground">
+            {t('settings.modelContextWindows')}
+          </label>
+          <Textarea
+            value={windowsDraft}
+            onChange={(e) => setWindowsDraft(e.target.value)}
+            placeholder="my-model-*: 128000"
+            className="font-mono text-xs min-h-[72px]"
+            disabled={updateSetting.isPending}
+          />
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
+
+function HistorySummarySection() {
+  const { data: settings, isLoading } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const { t } = useTranslation();
+
+  const threshold = settings?.history_summary_threshold || '0';
+  const model = settings?.history_summary_model ?? '';
+  const keepTurns = settings?.history_summary_keep_turns || '4';
+
+  const [thresholdDraft, setThresholdDraft] = useState('');
+  const [modelDraft, setModelDraft] = useState('');
+  const [keepTurnsDraft, setKeepTurnsDraft] = useState('');
+  const [initialized, setInitialized] = useState(false);
+
+  useEffect(() => {
+    if (!isLoading) {
+      setThresholdDraft(threshold);
+      setModelDraft(model);
+      setKeepTurnsDraft(keepTurns);
+      setInitialized(true);
+    }
+  }, [isLoading, threshold, model, keepTurns]);
+
+  const hasChanges =
+    initialized &&
+    (thresholdDraft !== threshold || modelDraft !== model || keepTurnsDraft !== keepTurns);
+
+  const handleSave = async () => {
+    const thresholdNum = parseInt(thresholdDraft, 10);
+    if (!isNaN(thresholdNum) && thresholdNum >= 0 && thresholdDraft !== threshold) {
+      await updateSetting.mutateAsync({ key: 'history_summary_threshold', value: thresholdDraft });
+    }
+    if (modelDraft !== model) {
+      await updateSetting.mutateAsync({ key: 'history_summary_model', value: modelDraft.trim() });
+    }
+    const keepTurnsNum = parseInt(keepTurnsDraft, 10);
+    if (!isNaN(keepTurnsNum) && keepTurnsNum > 0 && keepTurnsDraft !== keepTurns) {
+      await updateSetting.mutateAsync({ key: 'history_summary_keep_turns', value: keepTurnsDraft });
+    }
+  };
+
+  if (isLoading || !initialized) return null;
+
+  return (
+    <Card className="border-border bg-card">
+      <CardHeader className="border-b border-border py-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <CardTitle className="text-base font-medium flex items-center gap-2">
+              <ScrollText className="h-4 w-4 text-muted-foreground" />
+              {t('settings.historySummary')}
+            </CardTitle>
+            <p className="text-xs text-muted-foreground mt-1">{t('settings.historySummaryHint')}</p>
+          </div>
+          <Button onClick={handleSave} disabled={!hasChanges || updateSetting.isPending} size="sm">
+            {updateSetting.isPending ? t('common.saving') : t('common.save')}
+          </Button>
+        </div>
+      </CardHeader>
+      <CardContent className="p-6 space-y-4">
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.historySummaryThreshold')}
+          </label>
+          <Input
+            type="number"
+            value={thresholdDraft}
+            onChange={(e) => setThresholdDraft(e.target.value)}
+            className="w-32"
+            min={0}
+            disabled={updateSetting.isPending}
+          />
+          <span className="text-xs text-muted-foreground">tokens</span>
+        </div>
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.historySummaryModel')}
+          </label>
+          <Input
+            value={modelDraft}
+            onChange={(e) => setModelDraft(e.target.value)}
+            placeholder="claude-haiku-4-5"
+            className="w-64 font-mono text-xs"
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.historySummaryKeepTurns')}
+          </label>
+          <Input
+            type="number"
+            value={keepTurnsDraft}
+            onChange={(e) => setKeepTurnsDraft(e.target.value)}
+            className="w-24"
+            min={1}
+            disabled={updateSetting.isPending}
+          />
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
+
+function AgentLoopSection() {
+  const { data: settings, isLoading } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const { t } = useTranslation();
+
+  const threshold = settings?.agent_loop_threshold || '0';
+  const windowSecs = settings?.agent_loop_window_secs || '60';
+  const action = settings?.agent_loop_action ?? '';
+
+  const [thresholdDraft, setThresholdDraft] = useState('');
+  const [windowDraft, setWindowDraft] = useState('');
+  const [initialized, setInitialized] = useState(false);
+
+  useEffect(() => {
+    if (!isLoading) {
+      setThresholdDraft(threshold);
+      setWindowDraft(windowSecs);
+      setInitialized(true);
+    }
+  }, [isLoading, threshold, windowSecs]);
+
+  const actions = [
+    { value: '', label: t('settings.agentLoopActions.warn') },
+    { value: 'throttle', label: t('settings.agentLoopActions.throttle') },
+  ];
+
+  const hasChanges = initialized && (thresholdDraft !== threshold || windowDraft !== windowSecs);
+
+  const handleActionChange = async (value: string) => {
+    await updateSetting.mutateAsync({ key: 'agent_loop_action', value });
+  };
+
+  const handleSave = async () => {
+    const thresholdNum = parseInt(thresholdDraft, 10);
+    if (!isNaN(thresholdNum) && thresholdNum >= 0 && thresholdDraft !== threshold) {
+      await updateSetting.mutateAsync({ key: 'agent_loop_threshold', value: thresholdDraft });
+    }
+    const windowNum = parseInt(windowDraft, 10);
+    if (!isNaN(windowNum) && windowNum > 0 && windowDraft !== windowSecs) {
+      await updateSetting.mutateAsync({ key: 'agent_loop_window_secs', value: windowDraft });
+    }
+  };
+
+  if (isLoading || !initialized) return null;
+
+  return (
+    <Card className="border-border bg-card">
+      <CardHeader className="border-b border-border py-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <CardTitle className="text-base font-medium flex items-center gap-2">
+              <Repeat className="h-4 w-4 text-muted-foreground" />
+              {t('settings.agentLoop')}
+            </CardTitle>
+            <p className="text-xs text-muted-foreground mt-1">{t('settings.agentLoopHint')}</p>
+          </div>
+          <Button onClick={handleSave} disabled={!hasChanges || updateSetting.isPending} size="sm">
+            {updateSetting.isPending ? t('common.saving') : t('common.save')}
+          </Button>
+        </div>
+      </CardHeader>
+      <CardContent className="p-6 space-y-4">
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.agentLoopThreshold')}
+          </label>
+          <Input
+            type="number"
+            value={thresholdDraft}
+            onChange={(e) => setThresholdDraft(e.target.value)}
+            className="w-32"
+            min={0}
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.agentLoopWindow')}
+          </label>
+          <Input
+            type="number"
+            value={windowDraft}
+            onChange={(e) => setWindowDraft(e.target.value)}
+            className="w-32"
+            min={1}
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="flex items-center gap-6">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.agentLoopAction')}
+          </label>
+          <div className="flex flex-wrap gap-3">
+            {actions.map(({ value, label }) => (
+              <Button
+                key={value}
+                onClick={() => handleActionChange(value)}
+                variant={action === value ? 'default' : 'outline'}
+                disabled={updateSetting.isPending}
+              >
+                <span className="text-sm font-medium">{label}</span>
+              </Button>
+            ))}
+          </div>
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
+
+function BackgroundRoutingSection() {
+  const { data: settings, isLoading } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const { t } = useTranslation();
+
+  const provider = settings?.background_provider ?? '';
+  const patterns = settings?.background_model_patterns ?? '';
+  const maxTokens = settings?.background_max_tokens || '1024';
+  const localResponder = settings?.background_local_responder === 'true';
+  const localMaxInput = settings?.background_local_max_input || '2048';
+  const localResponses = settings?.background_local_responses ?? '';
+
+  const [providerDraft, setProviderDraft] = useState('');
+  const [patternsDraft, setPatternsDraft] = useState('');
+  const [maxTokensDraft, setMaxTokensDraft] = useState('');
+  const [localMaxInputDraft, setLocalMaxInputDraft] = useState('');
+  const [localResponsesDraft, setLocalResponsesDraft] = useState('');
+  const [initialized, setInitialized] = useState(false);
+
+  useEffect(() => {
+    if (!isLoading) {
+      setProviderDraft(provider);
+      setPatternsDraft(patterns);
+      setMaxTokensDraft(maxTokens);
+      setLocalMaxInputDraft(localMaxInput);
+      setLocalResponsesDraft(localResponses);
+      setInitialized(true);
+    }
+  }, [isLoading, provider, patterns, maxTokens, localMaxInput, localResponses]);
+
+  const hasChanges =
+    initialized &&
+    (providerDraft !== provider ||
+      patternsDraft !== patterns ||
+      maxTokensDraft !== maxTokens ||
+      localMaxInputDraft !== localMaxInput ||
+      localResponsesDraft !== localResponses);
+
+  const handleLocalResponderToggle = async (checked: boolean) => {
+    await updateSetting.mutateAsync({
+      key: 'background_local_responder',
+      value: checked ? 'true' : 'false',
+    });
+  };
+
+  const handleSave = async () => {
+    if (providerDraft !== provider) {
+      await updateSetting.mutateAsync({ key: 'background_provider', value: providerDraft.trim() });
+    }
+    if (patternsDraft !== patterns) {
+      await updateSetting.mutateAsync({
+        key: 'background_model_patterns',
+        value: patternsDraft.trim(),
+      });
+    }
+    const maxTokensNum = parseInt(maxTokensDraft, 10);
+    if (!isNaN(maxTokensNum) && maxTokensNum > 0 && maxTokensDraft !== maxTokens) {
+      await updateSetting.mutateAsync({ key: 'background_max_tokens', value: maxTokensDraft });
+    }
+    const localMaxInputNum = parseInt(localMaxInputDraft, 10);
+    if (!isNaN(localMaxInputNum) && localMaxInputNum > 0 && localMaxInputDraft !== localMaxInput) {
+      await updateSetting.mutateAsync({
+        key: 'background_local_max_input',
+        value: localMaxInputDraft,
+      });
+    }
+    if (localResponsesDraft !== localResponses) {
+      await updateSetting.mutateAsync({
+        key: 'background_local_responses',
+        value: localResponsesDraft.trim(),
+      });
+    }
+  };
+
+  if (isLoading || !initialized) return null;
+
+  return (
+    <Card className="border-border bg-card">
+      <CardHeader className="border-b border-border py-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <CardTitle className="text-base font-medium flex items-center gap-2">
+              <Feather className="h-4 w-4 text-muted-foreground" />
+              {t('settings.backgroundRouting')}
+            </CardTitle>
+            <p className="text-xs text-muted-foreground mt-1">
+              {t('settings.backgroundRoutingHint')}
+            </p>
+          </div>
+          <Button onClick={handleSave} disabled={!hasChanges || updateSetting.isPending} size="sm">
+            {updateSetting.isPending ? t('common.saving') : t('common.save')}
+          </Button>
+        </div>
+      </CardHeader>
+      <CardContent className="p-6 space-y-4">
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.backgroundProvider')}
+          </label>
+          <Input
+            value={providerDraft}
+            onChange={(e) => setProviderDraft(e.target.value)}
+            placeholder={t('settings.backgroundProviderPlaceholder')}
+            className="w-64"
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.backgroundModelPatterns')}
+          </label>
+          <Input
+            value={patternsDraft}
+            onChange={(e) => setPatternsDraft(e.target.value)}
+            placeholder="*haiku*"
+            className="w-64 font-mono text-xs"
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.backgroundMaxTokens')}
+          </label>
+          <Input
+            type="number"
+            value={maxTokensDraft}
+            onChange={(e) => setMaxTokensDraft(e.target.value)}
+            className="w-32"
+            min={1}
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="flex items-center justify-between pt-4 border-t border-border">
+          <div>
+            <label className="text-sm font-medium text-foreground">
+              {t('settings.backgroundLocalResponder')}
+            </label>
+            <p className="text-xs text-muted-foreground mt-1">
+              {t('settings.backgroundLocalResponderDesc')}
+            </p>
+          </div>
+          <Switch
+            checked={localResponder}
+            onCheckedChange={handleLocalResponderToggle}
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.backgroundLocalMaxInput')}
+          </label>
+          <Input
+            type="number"
+            value={localMaxInputDraft}
+            onChange={(e) => setLocalMaxInputDraft(e.target.value)}
+            className="w-32"
+            min={1}
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="space-y-2">
+          <label className="text-sm font-medium text-muted-foreground">
+            {t('settings.backgroundLocalResponses')}
+          </label>
+          <Textarea
+            value={localResponsesDraft}
+            onChange={(e) => setLocalResponsesDraft(e.target.value)}
+            placeholder={'isNewTopic => {"isNewTopic": false, "title": null}\nquota => OK'}
+            className="font-mono text-xs min-h-[72px]"
+            disabled={updateSetting.isPending}
+          />
+          <p className="text-xs text-muted-foreground">
+            {t('settings.backgroundLocalResponsesHint')}
+          </p>
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
+
+function ForceProjectSection() {
+  const { data: settings, isLoading } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const { t } = useTranslation();
+
+  const forceProjectEnabled = settings?.force_project_binding === 'true';
+  const timeout = settings?.force_project_timeout || '30';
+
+  const handleToggle = async (checked: boolean) => {
+    await updateSetting.mutateAsync({
+      key: 'force_project_binding',
+      value: checked ? 'true' : 'false',
+    });
+  };
+
+  const handleTimeoutChange = async (value: string) => {
+    const numValue = parseInt(value, 10);
+    if (numValue >= 5 && numValue <= 300) {
+      await updateSetting.mutateAsync({
+        key: 'force_project_timeout',
+        value: value,
+      });
+    }
+  };
+
+  if (isLoading) return null;
+
+  return (
+    <Card className="border-border bg-card">
+      <CardHeader className="border-b border-border py-4">
+        <CardTitle className="text-base font-medium flex items-center gap-2">
+          <FolderOpen className="h-4 w-4 text-muted-foreground" />
+          {t('settings.forceProjectBinding')}
+        </CardTitle>
+      </CardHeader>
+      <CardContent className="p-6 space-y-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <label className="text-sm font-medium text-foreground">
+              {t('settings.enableForceProjectBinding')}
+            </label>
+            <p className="text-xs text-muted-foreground mt-1">
+              {t('settings.forceProjectBindingDesc')}
+            </p>
+          </div>
+          <Switch
+            checked={forceProjectEnabled}
+            onCheckedChange={handleToggle}
+            disabled={updateSetting.isPending}
+          />
+        </div>
+
+        {forceProjectEnabled && (
+          <div className="flex items-center gap-6 pt-4 border-t border-border">
+            <label className="text-sm font-medium text-muted-foreground w-32 shrink-0">
+              {t('settings.waitTimeout')}
+            </label>
+            <Input
+              type="number"
+              value={timeout}
+              onChange={(e) => handleTimeoutChange(e.target.value)}
+              className="w-24"
+              min={5}
+              max={300}
+              disabled={updateSetting.isPending}
+            />
+            <span className="text-xs text-muted-foreground">{t('settings.waitTimeoutRange')}</span>
+          </div>
+        )}
+      </CardContent>
+    </Card>
+  );
+}
+
+function SessionExpirySection() {
+  const { data: settings, isLoading } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const { t } = useTranslation();
+
+  const idleHours = settings?.session_idle_hours || '0';
+  const action = settings?.session_idle_action || 'unbind';
+
+  const [hoursDraft, setHoursDraft] = useState('');
+  const [initialized, setInitialized] = useState(false);
+
+  useEffect(() => {
+    if (!isLoading) {
+      setHoursDraft(idleHours);
+      setInitialized(true);
+    }
+  }, [isLoading, idleHours]);
+
+  const actions = [
+    { value: 'unbind', label: t('settings.sessionIdleActions.unbind') },
+    { value: 'archive', label: t('settings.sessionIdleActions.archive') },
+  ];
+
+  const hasChanges = initialized && hoursDraft !== idleHours;
+
+  const handleActionChange = async (value: string) => {
+    await updateSetting.mutateAsync({ key: 'session_idle_action', value });
+  };
+
+  const handleSave = async () => {
+    const hoursNum = parseInt(hoursDraft, 10);
+    if (!isNaN(hoursNum) && hoursNum >= 0) {
+      await updateSetting.mutateAsync({ key: 'session_idle_hours', value: String(hoursNum) });
+    }
+  };
+
+  if (isLoading || !initialized) return null;
+
+  return (
+    <Card className="border-border bg-card">
+      <CardHeader className="border-b border-border py-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <CardTitle className="text-base font-medium flex items-center gap-2">
+              <Hourglass className="h-4 w-4 text-muted-foreground" />
+              {t('settings.sessionExpiry')}
+            </CardTitle>
+            <p className="text-xs text-muted-foreground mt-1">{t('settings.sessionExpiryHint')}</p>
+          </div>
+          <Button onClick={handleSave} disabled={!hasChanges || updateSetting.isPending} size="sm">
+            {updateSetting.isPending ? t('common.saving') : t('common.save')}
+          </Button>
+        </div>
+      </CardHeader>
+      <CardContent className="p-6 space-y-4">
+        <div className="flex items-center gap-3">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.sessionIdleHours')}
+          </label>
+          <Input
+            type="number"
+            value={hoursDraft}
+            onChange={(e) => setHoursDraft(e.target.value)}
+            className="w-32"
+            min={0}
+            disabled={updateSetting.isPending}
+          />
+        </div>
+        <div className="flex items-center gap-6">
+          <label className="text-sm font-medium text-muted-foreground w-40 shrink-0">
+            {t('settings.sessionIdleAction')}
+          </label>
+          <div className="flex flex-wrap gap-3">
+            {actions.map(({ value, label }) => (
+              <Button
+                key={value}
+                onClick={() => handleActionChange(value)}
+                variant={action === value ? 'default' : 'outline'}
+                disabled={updateSetting.isPending}
+              >
+                <span className="text-sm font-medium">{label}</span>
+              </Button>
+            ))}
+          </div>
+        </div>
+      </CardContent>
+    </Card>
+  );
+}
+
+function StatusPageSection() {
+  const { data: settings, isLoading } = useSettings();
+  const updateSetting = useUpdateSetting();
+  const { t } = useTranslation();
+
+  // 默认关闭，只有显式设为 true 才开启
+  const enabled = settings?.status_page === 'true';
+
+  const handleToggle = async (checked: boolean) => {
+    await updateSetting.mutateAsync({
+      key: 'status_page',
+      value: checked ? 'true' : 'false',
+    });
+  };
+
+  if (isLoading) return null;
+
+  return (
+    <Card className="border-border bg-card">
+      <CardHeader className="border-b border-border py-4">
+        <CardTitle className="text-base font-medium flex items-center gap-2">
+          <Activity className="h-4 w-4 text-muted-foreground" />
+          {t('settings.statusPage')}
+        </CardTitle>
+      </CardHeader>
+      <CardContent className="p-6 space-y-4">
+        <div className="flex items-center justify-between">
+          <div>
+            <label className="text-sm font-medium text-foreground">
+              {t('settings.enableStatusPage')}
+            </label>
+            <p className="text-xs text-muted-foreground mt-1">
+              {t('settings.statusPageDesc')}{' '}
+              {enabled && (
+                <a href="/status" target="_blank" rel="noreferrer" className="underline">
+                  /status
+                </a>
+              )}
+            </p>
+          </div>
+          <Switch
+            checked={enabled}
+            onCheckedChange={handleToggle}
+            disabled={updateSetting.isPending}
+          />
+        </div>
       </CardContent>
     </Card>
   );